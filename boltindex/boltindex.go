@@ -0,0 +1,402 @@
+package boltindex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// FormatVersion is the on-disk format version written by this package.
+const FormatVersion = "1"
+
+// DefaultLockTimeout bounds how long Open waits for the database file lock.
+const DefaultLockTimeout = time.Second
+
+var (
+	// ErrClosed is returned by mutations after Close.
+	ErrClosed = errors.New("boltindex: index closed")
+
+	// ErrInvalidStore is returned when the database file is corrupt or was
+	// written in an unsupported format.
+	ErrInvalidStore = errors.New("boltindex: invalid store")
+)
+
+var (
+	toolsBucket      = []byte("tools")
+	versionsBucket   = []byte("versions")
+	categoriesBucket = []byte("categories")
+	metaBucket       = []byte("meta")
+	formatKey        = []byte("format")
+)
+
+// Options configures a BoltDB-backed index.
+type Options struct {
+	// Index configures the in-memory index that serves reads.
+	Index index.IndexOptions
+
+	// LockTimeout bounds how long Open waits for the file lock held by
+	// another process. Default: DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	// NoSync skips fsync after each write. Faster, but a crash may lose
+	// recent registrations. Intended for tests and bulk loads.
+	NoSync bool
+}
+
+// Index is an index.Index that persists registrations to a BoltDB file.
+//
+// Reads (lookup, search, pagination, rollouts, maintenance windows) are
+// served by an embedded index.InMemoryIndex that is rebuilt from disk on
+// Open. Registration and unregistration update memory first and then write
+// the affected tools through to disk; if the write fails the error is
+// returned and the in-memory change is kept but not persisted.
+//
+// Tools, their backends, custom metadata, disabled state, and version
+// history are persisted, along with registered categories. Rollouts and
+// maintenance windows are runtime state.
+type Index struct {
+	*index.InMemoryIndex
+
+	path string
+	opts Options
+
+	// mu serializes mutations so disk writes follow memory order, and guards db.
+	mu sync.Mutex
+	db *bolt.DB
+}
+
+// Open opens or creates the index at path and loads its tools into memory.
+func Open(path string, opts ...Options) (*Index, error) {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.LockTimeout <= 0 {
+		opt.LockTimeout = DefaultLockTimeout
+	}
+
+	db, err := openDB(path, opt)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{
+		InMemoryIndex: index.NewInMemoryIndex(opt.Index),
+		path:          path,
+		opts:          opt,
+		db:            db,
+	}
+	if err := idx.load(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+func openDB(path string, opt Options) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: opt.LockTimeout, NoSync: opt.NoSync})
+	if err != nil {
+		return nil, fmt.Errorf("boltindex: open %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if format := meta.Get(formatKey); format == nil {
+			if err := meta.Put(formatKey, []byte(FormatVersion)); err != nil {
+				return err
+			}
+		} else if string(format) != FormatVersion {
+			return fmt.Errorf("%w: unsupported format %q", ErrInvalidStore, format)
+		}
+		for _, name := range [][]byte{toolsBucket, versionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		_, err = tx.CreateBucketIfNotExists(categoriesBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// load replays persisted tools, their versions, and categories into the
+// in-memory index.
+func (b *Index) load() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		versions := tx.Bucket(versionsBucket)
+		err := tx.Bucket(toolsBucket).ForEach(func(k, v []byte) error {
+			var state index.ToolState
+			if err := json.Unmarshal(v, &state); err != nil {
+				return fmt.Errorf("%w: tool %s: %v", ErrInvalidStore, k, err)
+			}
+			if err := b.InMemoryIndex.SetToolState(string(k), &state); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidStore, err)
+			}
+			raw := versions.Get(k)
+			if raw == nil {
+				return nil
+			}
+			var history []index.ToolVersion
+			if err := json.Unmarshal(raw, &history); err != nil {
+				return fmt.Errorf("%w: tool %s versions: %v", ErrInvalidStore, k, err)
+			}
+			if err := b.InMemoryIndex.RestoreToolVersions(string(k), history); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidStore, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(categoriesBucket).ForEach(func(k, _ []byte) error {
+			if err := b.InMemoryIndex.RegisterCategory(string(k)); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidStore, err)
+			}
+			return nil
+		})
+	})
+}
+
+// RegisterTool registers a tool and persists it.
+func (b *Index) RegisterTool(tool model.Tool, backend model.ToolBackend) error {
+	return b.mutate(func() error {
+		return b.InMemoryIndex.RegisterTool(tool, backend)
+	}, tool.ToolID())
+}
+
+// RegisterToolWithMetadata registers a tool, replaces its metadata, and
+// persists it.
+func (b *Index) RegisterToolWithMetadata(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error {
+	return b.mutate(func() error {
+		return b.InMemoryIndex.RegisterToolWithMetadata(tool, backend, metadata)
+	}, tool.ToolID())
+}
+
+// RegisterTools registers tools in batch and persists them in a single
+// transaction. Tools registered before a failing entry are still persisted.
+func (b *Index) RegisterTools(regs []index.ToolRegistration) error {
+	ids := make([]string, len(regs))
+	for i, reg := range regs {
+		ids[i] = reg.Tool.ToolID()
+	}
+	return b.mutate(func() error {
+		return b.InMemoryIndex.RegisterTools(regs)
+	}, ids...)
+}
+
+// RegisterToolsFromMCP registers tools from an MCP server and persists them
+// in a single transaction.
+func (b *Index) RegisterToolsFromMCP(serverName string, tools []model.Tool) error {
+	ids := make([]string, len(tools))
+	for i, tool := range tools {
+		ids[i] = tool.ToolID()
+	}
+	return b.mutate(func() error {
+		return b.InMemoryIndex.RegisterToolsFromMCP(serverName, tools)
+	}, ids...)
+}
+
+// ReplaceToolsFromMCP atomically replaces the tools of an MCP server (see
+// index.InMemoryIndex.ReplaceToolsFromMCP) and persists the affected tools
+// in a single transaction.
+func (b *Index) ReplaceToolsFromMCP(serverName string, tools []model.Tool) error {
+	return b.mutateFunc(func() []string {
+		ids := b.InMemoryIndex.MCPServerToolIDs(serverName)
+		for _, tool := range tools {
+			ids = append(ids, tool.ToolID())
+		}
+		return ids
+	}, func() error {
+		return b.InMemoryIndex.ReplaceToolsFromMCP(serverName, tools)
+	})
+}
+
+// UnregisterBackend removes a backend from a tool and persists the result,
+// deleting the tool from disk when its last backend is removed.
+func (b *Index) UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error {
+	return b.mutate(func() error {
+		return b.InMemoryIndex.UnregisterBackend(toolID, kind, backendID)
+	}, toolID)
+}
+
+// DisableTool hides a tool and persists its disabled state.
+func (b *Index) DisableTool(id string) error {
+	return b.mutate(func() error {
+		return b.InMemoryIndex.DisableTool(id)
+	}, id)
+}
+
+// EnableTool restores a tool hidden with DisableTool and persists it.
+func (b *Index) EnableTool(id string) error {
+	return b.mutate(func() error {
+		return b.InMemoryIndex.EnableTool(id)
+	}, id)
+}
+
+// RegisterCategory adds a category to the taxonomy and persists it.
+func (b *Index) RegisterCategory(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return ErrClosed
+	}
+	if err := b.InMemoryIndex.RegisterCategory(path); err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(categoriesBucket)
+		for _, category := range b.InMemoryIndex.RegisteredCategories() {
+			if err := bucket.Put([]byte(category), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mutate applies fn to memory and writes the resulting state of ids to disk.
+// The write happens even if fn fails, since batch operations may have
+// applied some entries before the error.
+func (b *Index) mutate(fn func() error, ids ...string) error {
+	return b.mutateFunc(func() []string { return ids }, fn)
+}
+
+// mutateFunc is mutate with the IDs computed by idsFn under b.mu before fn
+// runs, so they cannot go stale between the two.
+func (b *Index) mutateFunc(idsFn func() []string, fn func() error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return ErrClosed
+	}
+
+	ids := idsFn()
+	applyErr := fn()
+	if err := b.persistLocked(ids); err != nil {
+		return errors.Join(applyErr, err)
+	}
+	return applyErr
+}
+
+func (b *Index) persistLocked(ids []string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, versions := tx.Bucket(toolsBucket), tx.Bucket(versionsBucket)
+		for _, id := range ids {
+			if id == "" {
+				continue
+			}
+			state, err := b.InMemoryIndex.GetToolState(id)
+			if errors.Is(err, index.ErrNotFound) {
+				if err := bucket.Delete([]byte(id)); err != nil {
+					return err
+				}
+				if err := versions.Delete([]byte(id)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			encoded, err := json.Marshal(state)
+			if err != nil {
+				return fmt.Errorf("boltindex: encode tool %s: %w", id, err)
+			}
+			if err := bucket.Put([]byte(id), encoded); err != nil {
+				return err
+			}
+			history, err := b.InMemoryIndex.ListToolVersions(id)
+			if err != nil {
+				return err
+			}
+			encoded, err = json.Marshal(history)
+			if err != nil {
+				return fmt.Errorf("boltindex: encode tool %s versions: %w", id, err)
+			}
+			if err := versions.Put([]byte(id), encoded); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Path returns the database file path.
+func (b *Index) Path() string {
+	return b.path
+}
+
+// Compact rewrites the database file without free pages, reclaiming space
+// left by removed tools. Mutations block while it runs.
+func (b *Index) Compact() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return ErrClosed
+	}
+
+	tmpPath := b.path + ".compact"
+	_ = os.Remove(tmpPath)
+	dst, err := bolt.Open(tmpPath, 0o600, &bolt.Options{Timeout: b.opts.LockTimeout, NoSync: b.opts.NoSync})
+	if err != nil {
+		return fmt.Errorf("boltindex: compact: %w", err)
+	}
+	if err := bolt.Compact(dst, b.db, 0); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("boltindex: compact: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("boltindex: compact: %w", err)
+	}
+
+	if err := b.db.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	b.db = nil
+	renameErr := os.Rename(tmpPath, b.path)
+	if renameErr != nil {
+		_ = os.Remove(tmpPath)
+		renameErr = fmt.Errorf("boltindex: compact: %w", renameErr)
+	}
+	// Reopen whichever file is now in place so the index stays usable.
+	db, err := openDB(b.path, b.opts)
+	if err != nil {
+		return errors.Join(renameErr, err)
+	}
+	b.db = db
+	return renameErr
+}
+
+// Close releases the database file. The in-memory index keeps serving
+// reads; mutations return ErrClosed. Close is idempotent.
+func (b *Index) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return nil
+	}
+	err := b.db.Close()
+	b.db = nil
+	return err
+}
+
+var (
+	_ index.Index       = (*Index)(nil)
+	_ index.MCPReplacer = (*Index)(nil)
+)
@@ -0,0 +1,285 @@
+package boltindex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/indextest"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func testTool(namespace, name, desc string) model.Tool {
+	return model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: desc,
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"count": map[string]any{"type": "integer", "minimum": 1}},
+			},
+		},
+		Namespace: namespace,
+		Tags:      []string{"test"},
+	}
+}
+
+func mcpBackend(server string) model.ToolBackend {
+	return model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: server}}
+}
+
+func localBackend(name string) model.ToolBackend {
+	return model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: name}}
+}
+
+func openTemp(t *testing.T) (*Index, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "index.db")
+	idx, err := Open(path, Options{NoSync: true})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx, path
+}
+
+func reopen(t *testing.T, idx *Index, path string) *Index {
+	t.Helper()
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	reopened, err := Open(path, Options{NoSync: true})
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	t.Cleanup(func() { _ = reopened.Close() })
+	return reopened
+}
+
+func TestIndex_PersistsAcrossReopen(t *testing.T) {
+	idx, path := openTemp(t)
+
+	issue := testTool("github", "create_issue", "Create a GitHub issue")
+	if err := idx.RegisterToolWithMetadata(issue, mcpBackend("github"), map[string]string{"owner": "platform"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata failed: %v", err)
+	}
+	if err := idx.RegisterTool(issue, localBackend("issue-handler")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := idx.RegisterToolsFromMCP("fs", []model.Tool{
+		testTool("fs", "read_file", "Read a file"),
+		testTool("fs", "write_file", "Write a file"),
+	}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+
+	idx = reopen(t, idx, path)
+
+	tool, backend, err := idx.GetTool("github:create_issue")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if tool.Description != issue.Description || !reflect.DeepEqual(tool.Tags, issue.Tags) {
+		t.Fatalf("tool = %+v", tool)
+	}
+	if backend.Kind != model.BackendKindLocal {
+		t.Fatalf("default backend = %s, want local", backend.Kind)
+	}
+	backends, _ := idx.GetAllBackends("github:create_issue")
+	if len(backends) != 2 {
+		t.Fatalf("backends = %d, want 2", len(backends))
+	}
+	metadata, _ := idx.GetMetadata("github:create_issue")
+	if metadata["owner"] != "platform" {
+		t.Fatalf("metadata = %v", metadata)
+	}
+
+	namespaces, _ := idx.ListNamespaces()
+	if !reflect.DeepEqual(namespaces, []string{"fs", "github"}) {
+		t.Fatalf("namespaces = %v", namespaces)
+	}
+	results, _ := idx.Search("file", 10)
+	if len(results) != 2 {
+		t.Fatalf("search results = %d, want 2", len(results))
+	}
+	page, next, err := idx.SearchPage("", 2, "")
+	if err != nil || len(page) != 2 || next == "" {
+		t.Fatalf("SearchPage = %d results, next %q, err %v", len(page), next, err)
+	}
+
+	// Re-registering the same tool from MCP after a restart must not conflict
+	// with the JSON-decoded schema.
+	if err := idx.RegisterToolsFromMCP("fs", []model.Tool{testTool("fs", "read_file", "Read a file")}); err != nil {
+		t.Fatalf("re-register after reopen failed: %v", err)
+	}
+}
+
+func TestIndex_StoreContract(t *testing.T) {
+	indextest.TestStore(t, func(t *testing.T) (indextest.Store, indextest.Reload) {
+		idx, path := openTemp(t)
+		return idx, func(t *testing.T, prev indextest.Store) indextest.Store {
+			return reopen(t, prev.(*Index), path)
+		}
+	})
+}
+
+func TestIndex_UnregisterPersists(t *testing.T) {
+	idx, path := openTemp(t)
+
+	tool := testTool("github", "create_issue", "Create a GitHub issue")
+	_ = idx.RegisterTool(tool, mcpBackend("a"))
+	_ = idx.RegisterTool(tool, mcpBackend("b"))
+	_ = idx.RegisterTool(testTool("fs", "read_file", "Read"), mcpBackend("fs"))
+
+	if err := idx.UnregisterBackend("github:create_issue", model.BackendKindMCP, "a"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if err := idx.UnregisterBackend("fs:read_file", model.BackendKindMCP, "fs"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+
+	idx = reopen(t, idx, path)
+	backends, err := idx.GetAllBackends("github:create_issue")
+	if err != nil || len(backends) != 1 || backends[0].MCP.ServerName != "b" {
+		t.Fatalf("backends = %+v, err %v", backends, err)
+	}
+	if _, _, err := idx.GetTool("fs:read_file"); !errors.Is(err, index.ErrNotFound) {
+		t.Fatalf("removed tool err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestIndex_ReplaceToolsFromMCPPersists(t *testing.T) {
+	idx, path := openTemp(t)
+
+	read := testTool("fs", "read_file", "Read a file")
+	if err := idx.RegisterToolsFromMCP("fs", []model.Tool{read, testTool("fs", "write_file", "Write a file")}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+	if err := idx.ReplaceToolsFromMCP("fs", []model.Tool{read, testTool("fs", "list_dir", "List a directory")}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP failed: %v", err)
+	}
+
+	idx = reopen(t, idx, path)
+	if got := idx.MCPServerToolIDs("fs"); !reflect.DeepEqual(got, []string{"fs:list_dir", "fs:read_file"}) {
+		t.Fatalf("tools = %v, want fs:list_dir and fs:read_file", got)
+	}
+}
+
+func TestIndex_ToolVersionsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	opts := Options{NoSync: true, Index: index.IndexOptions{TrackToolVersions: true}}
+	idx, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	_ = idx.RegisterTool(testTool("fs", "read_file", "v1"), mcpBackend("fs"))
+	_ = idx.RegisterTool(testTool("fs", "read_file", "v2"), mcpBackend("fs"))
+	want, _ := idx.ListToolVersions("fs:read_file")
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	t.Cleanup(func() { _ = reopened.Close() })
+	got, err := reopened.ListToolVersions("fs:read_file")
+	if err != nil || len(got) != 2 || got[0].Tool.Description != "v1" || !got[0].RegisteredAt.Equal(want[0].RegisteredAt) {
+		t.Fatalf("versions after reopen = %+v, %v; want %+v", got, err, want)
+	}
+	if tool, err := reopened.GetToolVersion("fs:read_file", 1); err != nil || tool.Description != "v1" {
+		t.Errorf("GetToolVersion(1) = %q, %v; want v1", tool.Description, err)
+	}
+}
+
+func TestIndex_InvalidRegistrationNotPersisted(t *testing.T) {
+	idx, path := openTemp(t)
+
+	if err := idx.RegisterTool(model.Tool{}, mcpBackend("a")); !errors.Is(err, index.ErrInvalidTool) {
+		t.Fatalf("err = %v, want ErrInvalidTool", err)
+	}
+	idx = reopen(t, idx, path)
+	if results, _ := idx.Search("", 10); len(results) != 0 {
+		t.Fatalf("results = %v, want none", results)
+	}
+}
+
+func TestIndex_CompactAndClose(t *testing.T) {
+	idx, path := openTemp(t)
+
+	for i := range 200 {
+		name := "tool_" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		_ = idx.RegisterTool(testTool("bulk", name, "Bulk tool"), mcpBackend("bulk"))
+	}
+	_ = idx.RegisterTool(testTool("keep", "tool", "Kept"), mcpBackend("keep"))
+	results, _ := idx.Search("bulk", 1000)
+	for _, r := range results {
+		_ = idx.UnregisterBackend(r.ID, model.BackendKindMCP, "bulk")
+	}
+
+	before, _ := os.Stat(path)
+	if err := idx.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() > before.Size() {
+		t.Fatalf("size after compact = %d, before = %d", after.Size(), before.Size())
+	}
+	if _, err := os.Stat(path + ".compact"); !os.IsNotExist(err) {
+		t.Fatalf("temporary compact file left behind: %v", err)
+	}
+
+	// Still writable after compaction.
+	if err := idx.RegisterTool(testTool("keep", "other", "Other"), mcpBackend("keep")); err != nil {
+		t.Fatalf("RegisterTool after Compact failed: %v", err)
+	}
+
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	if err := idx.RegisterTool(testTool("keep", "late", "Late"), mcpBackend("keep")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("RegisterTool after Close err = %v, want ErrClosed", err)
+	}
+	if err := idx.Compact(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Compact after Close err = %v, want ErrClosed", err)
+	}
+	if _, _, err := idx.GetTool("keep:tool"); err != nil {
+		t.Fatalf("reads after Close failed: %v", err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	if results, _ := reopened.Search("", 10); len(results) != 2 {
+		t.Fatalf("results after compact and reopen = %d, want 2", len(results))
+	}
+}
+
+func TestOpen_RejectsUnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatalf("bolt.Open failed: %v", err)
+	}
+	_ = db.Update(func(tx *bolt.Tx) error {
+		meta, _ := tx.CreateBucketIfNotExists(metaBucket)
+		return meta.Put(formatKey, []byte("99"))
+	})
+	_ = db.Close()
+
+	if _, err := Open(path); !errors.Is(err, ErrInvalidStore) {
+		t.Fatalf("Open err = %v, want ErrInvalidStore", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package boltindex provides a BoltDB-backed index.Index so large tool
+// catalogs survive process restarts without re-registering every tool from
+// MCP backends on boot.
+//
+// It lives outside the index package to keep index dependency-light.
+//
+// # Usage
+//
+//	idx, err := boltindex.Open("/var/lib/tools/index.db", boltindex.Options{
+//	    Index: index.IndexOptions{Searcher: search.NewBM25Searcher(search.BM25Config{})},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer idx.Close()
+//
+//	_ = idx.RegisterToolsFromMCP("github", tools) // written through to disk
+//	results, _ := idx.Search("create issue", 10)  // served from memory
+//
+// # Lifecycle
+//
+// Open loads every persisted tool into an in-memory index, which serves all
+// reads. Registrations are written through to disk, one transaction per
+// call. Compact rewrites the file to reclaim space after many removals.
+// Close releases the file lock; the in-memory index keeps serving reads but
+// mutations return [ErrClosed].
+//
+// Only one process may open a database file at a time; Open waits up to
+// Options.LockTimeout for the lock.
+//
+// # What Is Persisted
+//
+// Tools, their backends, their custom metadata, whether they are disabled,
+// and their version history (see index.IndexOptions.TrackToolVersions),
+// plus the categories added with RegisterCategory. Rollouts, maintenance
+// windows, and change listeners are runtime state and must be reapplied
+// after Open.
+package boltindex
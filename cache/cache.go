@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Error values for cache operations.
+var (
+	ErrInvalidCache  = errors.New("cache: cache is required")
+	ErrInvalidClient = errors.New("cache: redis client is required")
+	ErrInvalidReply  = errors.New("cache: unexpected redis reply")
+)
+
+// Cache stores byte values by key with an optional time-to-live.
+// Subsystems such as the discovery result cache and the semantic embedding
+// cache accept a Cache, so operators can point every replica at one shared
+// backend.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Context: must honor cancellation/deadlines.
+//   - Misses: Get reports a missing or expired key as (nil, false, nil);
+//     errors are reserved for backend failures.
+//   - TTL: a ttl of zero or less stores the value without expiry.
+//   - Ownership: implementations must not retain value after Set returns or
+//     expect callers to leave the slice returned by Get unmodified.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,31 @@
+// Package cache defines the Cache interface shared by caching subsystems and
+// provides in-memory and Redis implementations.
+//
+// It exists so operators running several replicas can centralize caching in
+// one backend instead of warming a private cache per process.
+//
+// # Implementations
+//
+// [LRU] is an in-memory cache with least-recently-used eviction and lazy TTL
+// expiry:
+//
+//	c := cache.NewLRU(cache.LRUOptions{MaxEntries: 10000})
+//
+// [Redis] stores entries in a Redis server. It adapts any client that can
+// run a raw command, without adding a Redis dependency to this module:
+//
+//	c, err := cache.NewRedis(cache.RedisClientFunc(func(ctx context.Context, args ...any) (any, error) {
+//	    return rdb.Do(ctx, args...).Result()
+//	}), cache.RedisOptions{KeyPrefix: "tooldiscovery:"})
+//
+// # Consumers
+//
+// semantic.NewCachedEmbedder caches embeddings, and discovery.Options
+// ResultCache caches search results, both keyed so entries from different
+// models, index versions, and principals never collide.
+//
+// # Thread Safety
+//
+// LRU and Redis are safe for concurrent use; Redis is as safe as the client
+// it wraps.
+package cache
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultLRUMaxEntries is the LRU capacity used when LRUOptions.MaxEntries
+// is zero.
+const DefaultLRUMaxEntries = 1024
+
+// LRUOptions configures an LRU cache.
+type LRUOptions struct {
+	// MaxEntries bounds the number of entries; the least recently used
+	// entry is evicted beyond it. Default: DefaultLRUMaxEntries.
+	MaxEntries int
+
+	// Now returns the current time for TTL expiry. Default: time.Now.
+	Now func() time.Time
+}
+
+// LRU is an in-memory Cache with least-recently-used eviction. Expired
+// entries are dropped when read or evicted.
+//
+// LRU is safe for concurrent use.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	now        func() time.Time
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+// NewLRU creates an in-memory LRU cache.
+func NewLRU(opts ...LRUOptions) *LRU {
+	var opt LRUOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxEntries <= 0 {
+		opt.MaxEntries = DefaultLRUMaxEntries
+	}
+	if opt.Now == nil {
+		opt.Now = time.Now
+	}
+	return &LRU{
+		maxEntries: opt.MaxEntries,
+		now:        opt.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns a copy of the value stored under key.
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.removeLocked(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return slices.Clone(entry.value), true, nil
+}
+
+// Set stores a copy of value under key, evicting the least recently used
+// entry when the cache is full.
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entry := &lruEntry{key: key, value: slices.Clone(value)}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeLocked(c.order.Back())
+	}
+	return nil
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (c *LRU) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+	return nil
+}
+
+// Len returns the number of stored entries, including expired entries not
+// yet dropped.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
+
+var _ Cache = (*LRU)(nil)
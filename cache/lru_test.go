@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRU_GetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU()
+	if _, ok, err := c.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("Get on empty cache = %v, %v", ok, err)
+	}
+
+	value := []byte("one")
+	if err := c.Set(ctx, "a", value, 0); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	value[0] = 'X'
+	got, ok, _ := c.Get(ctx, "a")
+	if !ok || string(got) != "one" {
+		t.Fatalf("Get = %q, %v; want stored copy", got, ok)
+	}
+	got[0] = 'Y'
+	if again, _, _ := c.Get(ctx, "a"); string(again) != "one" {
+		t.Errorf("Get returned an alias of the stored value: %q", again)
+	}
+
+	_ = c.Delete(ctx, "a")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("expected key to be deleted")
+	}
+	if err := c.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete missing key error: %v", err)
+	}
+}
+
+func TestLRU_Eviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(LRUOptions{MaxEntries: 2})
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	_, _, _ = c.Get(ctx, "a") // b is now least recently used
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len = %d, want 2", c.Len())
+	}
+}
+
+func TestLRU_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	c := NewLRU(LRUOptions{Now: func() time.Time { return now }})
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected a before expiry")
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("expected a to expire")
+	}
+	if _, ok, _ := c.Get(ctx, "b"); !ok {
+		t.Error("expected b without TTL to be kept")
+	}
+}
+
+func TestLRU_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := NewLRU()
+	if err := c.Set(ctx, "a", nil, 0); err == nil {
+		t.Error("expected Set to honor cancellation")
+	}
+	if _, _, err := c.Get(ctx, "a"); err == nil {
+		t.Error("expected Get to honor cancellation")
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient runs a raw Redis command and returns its decoded reply. It is
+// the subset of a Redis client used by Redis, so this module does not depend
+// on a particular driver. With github.com/redis/go-redis:
+//
+//	client := cache.RedisClientFunc(func(ctx context.Context, args ...any) (any, error) {
+//	    return rdb.Do(ctx, args...).Result()
+//	})
+//
+// Bulk string replies may be returned as string or []byte.
+type RedisClient interface {
+	Do(ctx context.Context, args ...any) (any, error)
+}
+
+// RedisClientFunc adapts a function to RedisClient.
+type RedisClientFunc func(ctx context.Context, args ...any) (any, error)
+
+// Do calls f.
+func (f RedisClientFunc) Do(ctx context.Context, args ...any) (any, error) {
+	return f(ctx, args...)
+}
+
+// RedisOptions configures a Redis cache.
+type RedisOptions struct {
+	// KeyPrefix is prepended to every key, so several deployments can share
+	// one Redis database.
+	KeyPrefix string
+}
+
+// Redis is a Cache backed by a Redis server, shared by every replica that
+// points at it. TTLs are enforced by Redis with millisecond precision.
+type Redis struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedis creates a Redis cache over an established client.
+func NewRedis(client RedisClient, opts ...RedisOptions) (*Redis, error) {
+	if client == nil {
+		return nil, ErrInvalidClient
+	}
+	var opt RedisOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &Redis{client: client, prefix: opt.KeyPrefix}, nil
+}
+
+// Get returns the value stored under key. It issues MGET rather than GET so
+// a miss is a nil element instead of a driver-specific error.
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.client.Do(ctx, "MGET", c.prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	values, ok := reply.([]any)
+	if !ok || len(values) != 1 {
+		return nil, false, fmt.Errorf("%w: MGET returned %T", ErrInvalidReply, reply)
+	}
+	switch v := values[0].(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return []byte(v), true, nil
+	case []byte:
+		return v, true, nil
+	default:
+		return nil, false, fmt.Errorf("%w: MGET element %T", ErrInvalidReply, v)
+	}
+}
+
+// Set stores value under key, expiring it after ttl when ttl is positive.
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", c.prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := c.client.Do(ctx, args...)
+	return err
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (c *Redis) Delete(ctx context.Context, key string) error {
+	_, err := c.client.Do(ctx, "DEL", c.prefix+key)
+	return err
+}
+
+var _ Cache = (*Redis)(nil)
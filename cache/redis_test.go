@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeRedis records commands and serves MGET/SET/DEL from a map, replying
+// like go-redis does.
+type fakeRedis struct {
+	data     map[string]string
+	commands [][]any
+}
+
+func (f *fakeRedis) Do(_ context.Context, args ...any) (any, error) {
+	f.commands = append(f.commands, args)
+	key := args[1].(string)
+	switch args[0] {
+	case "MGET":
+		if v, ok := f.data[key]; ok {
+			return []any{v}, nil
+		}
+		return []any{nil}, nil
+	case "SET":
+		f.data[key] = string(args[2].([]byte))
+		return "OK", nil
+	case "DEL":
+		delete(f.data, key)
+		return int64(1), nil
+	}
+	return nil, fmt.Errorf("unknown command %v", args[0])
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeRedis{data: map[string]string{}}
+	c, err := NewRedis(fake, RedisOptions{KeyPrefix: "td:"})
+	if err != nil {
+		t.Fatalf("NewRedis error: %v", err)
+	}
+
+	if _, ok, err := c.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("Get miss = %v, %v", ok, err)
+	}
+	if err := c.Set(ctx, "a", []byte("1"), 1500*time.Millisecond); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	if got, ok, _ := c.Get(ctx, "a"); !ok || string(got) != "1" {
+		t.Errorf("Get = %q, %v", got, ok)
+	}
+	_ = c.Delete(ctx, "a")
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("expected a to be deleted")
+	}
+
+	set := fake.commands[1]
+	if len(set) != 5 || set[1] != "td:a" || set[3] != "PX" || set[4] != "1500" {
+		t.Errorf("SET with TTL = %v", set)
+	}
+	if len(fake.commands[2]) != 3 {
+		t.Errorf("SET without TTL = %v", fake.commands[2])
+	}
+}
+
+func TestRedis_Errors(t *testing.T) {
+	if _, err := NewRedis(nil); !errors.Is(err, ErrInvalidClient) {
+		t.Errorf("NewRedis(nil) error = %v", err)
+	}
+	c, _ := NewRedis(RedisClientFunc(func(context.Context, ...any) (any, error) {
+		return "OK", nil
+	}))
+	if _, _, err := c.Get(context.Background(), "a"); !errors.Is(err, ErrInvalidReply) {
+		t.Errorf("Get with bad reply error = %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// AdminOptions configures ServeAdmin.
+type AdminOptions struct {
+	// Authorize is called for every request and rejects it with 403 when it
+	// returns an error. Required: without it every request is rejected.
+	// With the registry HTTP server, use registry.RequireRole(registry.RoleAdmin)
+	// behind registry.RequireAPIKey.
+	Authorize func(*http.Request) error
+
+	// RequireIfMatch rejects mutations without an If-Match header with 428,
+	// so every admin UI must read before it writes.
+	RequireIfMatch bool
+}
+
+// ServeAdmin returns an http.Handler with admin endpoints for mutating d's
+// catalog. Paths are relative to the handler; mount it with
+// http.StripPrefix.
+//
+//	GET    /tools/{id}                          tool, backends, and metadata
+//	PUT    /tools/{id}                          register: {tool, backend, metadata, doc}
+//	DELETE /tools/{id}                          unregister every backend
+//	DELETE /tools/{id}/backends/{kind}/{backend} unregister one backend
+//	GET|PUT|PATCH|DELETE /docs/{id}             documentation (PATCH takes a DocPatch)
+//	GET|PUT /aliases/{id}                       aliases as a JSON array
+//	GET|PUT|DELETE /rollouts/{id}               rollouts (DELETE finalizes)
+//
+// Every response carries an ETag, and mutations honor If-Match, so
+// concurrent admin UIs get 412 Precondition Failed instead of overwriting
+// each other. Tools and rollouts are tagged with the index version, which
+// requires an index implementing index.Versioner; docs and aliases, which
+// do not change the index version, are tagged with a hash of their content.
+// Mutations through the handler are serialized, so the check and the write
+// are atomic with respect to each other. Changing the definition of a
+// registered tool requires index.IndexOptions.TrackToolVersions.
+func ServeAdmin(d *Discovery, opts AdminOptions) http.Handler {
+	a := &adminHandler{d: d, opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tools/{id}", a.getTool)
+	mux.HandleFunc("PUT /tools/{id}", a.putTool)
+	mux.HandleFunc("DELETE /tools/{id}", a.deleteTool)
+	mux.HandleFunc("DELETE /tools/{id}/backends/{kind}/{backend}", a.deleteBackend)
+	mux.HandleFunc("GET /docs/{id}", a.getDoc)
+	mux.HandleFunc("PUT /docs/{id}", a.putDoc)
+	mux.HandleFunc("PATCH /docs/{id}", a.patchDoc)
+	mux.HandleFunc("DELETE /docs/{id}", a.deleteDoc)
+	mux.HandleFunc("GET /aliases/{id}", a.getAliases)
+	mux.HandleFunc("PUT /aliases/{id}", a.putAliases)
+	mux.HandleFunc("GET /rollouts/{id}", a.getRollout)
+	mux.HandleFunc("PUT /rollouts/{id}", a.putRollout)
+	mux.HandleFunc("DELETE /rollouts/{id}", a.deleteRollout)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Authorize == nil {
+			http.Error(w, "admin authorization is not configured", http.StatusForbidden)
+			return
+		}
+		if err := opts.Authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+type adminHandler struct {
+	d    *Discovery
+	opts AdminOptions
+	mu   sync.Mutex // serializes precondition checks with mutations
+}
+
+// AdminTool is the representation of a tool served by ServeAdmin.
+type AdminTool struct {
+	Tool     model.Tool          `json:"tool"`
+	Backends []model.ToolBackend `json:"backends"`
+	Metadata map[string]string   `json:"metadata,omitempty"`
+}
+
+// AdminRegistration is the body of PUT /tools/{id}.
+type AdminRegistration struct {
+	Tool     model.Tool        `json:"tool"`
+	Backend  model.ToolBackend `json:"backend"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Doc      *AdminDoc         `json:"doc,omitempty"`
+}
+
+// AdminDoc is the JSON form of tooldoc.DocEntry used by ServeAdmin.
+type AdminDoc struct {
+	Summary      string                `json:"summary,omitempty"`
+	Notes        string                `json:"notes,omitempty"`
+	Examples     []tooldoc.ToolExample `json:"examples,omitempty"`
+	ExternalRefs []string              `json:"externalRefs,omitempty"`
+	SeeAlso      []string              `json:"seeAlso,omitempty"`
+	Owner        *tooldoc.Owner        `json:"owner,omitempty"`
+}
+
+func (doc AdminDoc) entry() tooldoc.DocEntry {
+	return tooldoc.DocEntry{
+		Summary:      doc.Summary,
+		Notes:        doc.Notes,
+		Examples:     doc.Examples,
+		ExternalRefs: doc.ExternalRefs,
+		SeeAlso:      doc.SeeAlso,
+		Owner:        doc.Owner,
+	}
+}
+
+func adminDoc(entry tooldoc.DocEntry) AdminDoc {
+	return AdminDoc{
+		Summary:      entry.Summary,
+		Notes:        entry.Notes,
+		Examples:     entry.Examples,
+		ExternalRefs: entry.ExternalRefs,
+		SeeAlso:      entry.SeeAlso,
+		Owner:        entry.Owner,
+	}
+}
+
+// AdminDocPatch is the JSON form of tooldoc.DocPatch used by ServeAdmin.
+type AdminDocPatch struct {
+	Summary         *string               `json:"summary,omitempty"`
+	Notes           *string               `json:"notes,omitempty"`
+	AppendExamples  []tooldoc.ToolExample `json:"appendExamples,omitempty"`
+	AddExternalRefs []string              `json:"addExternalRefs,omitempty"`
+	AddSeeAlso      []string              `json:"addSeeAlso,omitempty"`
+	Owner           *tooldoc.Owner        `json:"owner,omitempty"`
+}
+
+// rolloutIndex is implemented by indexes that support rollouts, such as
+// index.InMemoryIndex.
+type rolloutIndex interface {
+	SetRollout(r index.Rollout) error
+	FinalizeRollout(toolID string) error
+	Rollouts() []index.Rollout
+}
+
+func (a *adminHandler) versionTag() string {
+	return `"v` + strconv.FormatUint(a.d.Version(), 10) + `"`
+}
+
+func contentTag(v any) string {
+	b, _ := json.Marshal(v)
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// precondition reports whether the request's If-Match header matches the
+// current ETag, writing the error response when it does not. current is
+// empty when the resource does not exist.
+func (a *adminHandler) precondition(w http.ResponseWriter, r *http.Request, current string) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		if a.opts.RequireIfMatch {
+			http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+			return false
+		}
+		return true
+	}
+	for _, tag := range strings.Split(match, ",") {
+		tag = strings.TrimSpace(tag)
+		if current != "" && (tag == "*" || tag == current) {
+			return true
+		}
+	}
+	if current != "" {
+		w.Header().Set("ETag", current)
+	}
+	http.Error(w, "resource has changed", http.StatusPreconditionFailed)
+	return false
+}
+
+func (a *adminHandler) getTool(w http.ResponseWriter, r *http.Request) {
+	tool, ok := a.lookupTool(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, a.versionTag(), tool)
+}
+
+func (a *adminHandler) lookupTool(w http.ResponseWriter, id string) (AdminTool, bool) {
+	tool, _, err := a.d.GetTool(id)
+	if err != nil {
+		writeAdminError(w, err)
+		return AdminTool{}, false
+	}
+	backends, err := a.d.GetAllBackends(id)
+	if err != nil {
+		writeAdminError(w, err)
+		return AdminTool{}, false
+	}
+	out := AdminTool{Tool: tool, Backends: backends}
+	if m, ok := a.d.idx.(interface {
+		GetMetadata(id string) (map[string]string, error)
+	}); ok {
+		out.Metadata, _ = m.GetMetadata(id)
+	}
+	return out, true
+}
+
+func (a *adminHandler) toolTag(id string) string {
+	if _, _, err := a.d.GetTool(id); err != nil {
+		return ""
+	}
+	return a.versionTag()
+}
+
+func (a *adminHandler) putTool(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var body AdminRegistration
+	if !decodeAdminJSON(w, r, &body) {
+		return
+	}
+	if body.Tool.ToolID() != id {
+		http.Error(w, fmt.Sprintf("tool ID %q does not match path %q", body.Tool.ToolID(), id), http.StatusBadRequest)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.precondition(w, r, a.toolTag(id)) {
+		return
+	}
+	reg := index.ToolRegistration{Tool: body.Tool, Backend: body.Backend, Metadata: body.Metadata}
+	if err := a.d.RegisterTools([]index.ToolRegistration{reg}); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	if body.Doc != nil {
+		if err := a.d.RegisterDoc(id, body.Doc.entry()); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+	}
+	if tool, ok := a.lookupTool(w, id); ok {
+		writeAdminJSON(w, http.StatusOK, a.versionTag(), tool)
+	}
+}
+
+func (a *adminHandler) deleteTool(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.precondition(w, r, a.toolTag(id)) {
+		return
+	}
+	backends, err := a.d.GetAllBackends(id)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	for _, backend := range backends {
+		if err := a.d.UnregisterBackend(id, backend.Kind, index.BackendID(backend)); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+	}
+	writeAdminJSON(w, http.StatusNoContent, a.versionTag(), nil)
+}
+
+func (a *adminHandler) deleteBackend(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.precondition(w, r, a.toolTag(id)) {
+		return
+	}
+	kind := model.BackendKind(r.PathValue("kind"))
+	if err := a.d.UnregisterBackend(id, kind, r.PathValue("backend")); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, http.StatusNoContent, a.versionTag(), nil)
+}
+
+// docTag returns the current doc and its ETag, or an empty tag when the
+// tool has no documentation.
+func (a *adminHandler) docTag(id string) (AdminDoc, string) {
+	entry, err := a.d.docs.GetDoc(id)
+	if err != nil {
+		return AdminDoc{}, ""
+	}
+	doc := adminDoc(entry)
+	return doc, contentTag(doc)
+}
+
+func (a *adminHandler) getDoc(w http.ResponseWriter, r *http.Request) {
+	doc, tag := a.docTag(r.PathValue("id"))
+	if tag == "" {
+		writeAdminError(w, fmt.Errorf("%w: %s", tooldoc.ErrNotFound, r.PathValue("id")))
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, tag, doc)
+}
+
+func (a *adminHandler) putDoc(w http.ResponseWriter, r *http.Request) {
+	var body AdminDoc
+	if !decodeAdminJSON(w, r, &body) {
+		return
+	}
+	a.mutateDoc(w, r, func(id string) error { return a.d.RegisterDoc(id, body.entry()) })
+}
+
+func (a *adminHandler) patchDoc(w http.ResponseWriter, r *http.Request) {
+	var body AdminDocPatch
+	if !decodeAdminJSON(w, r, &body) {
+		return
+	}
+	a.mutateDoc(w, r, func(id string) error {
+		return a.d.PatchDoc(id, tooldoc.DocPatch{
+			Summary:         body.Summary,
+			Notes:           body.Notes,
+			AppendExamples:  body.AppendExamples,
+			AddExternalRefs: body.AddExternalRefs,
+			AddSeeAlso:      body.AddSeeAlso,
+			Owner:           body.Owner,
+		})
+	})
+}
+
+func (a *adminHandler) deleteDoc(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, tag := a.docTag(id); !a.precondition(w, r, tag) {
+		return
+	}
+	if err := a.d.RemoveDoc(id); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, http.StatusNoContent, "", nil)
+}
+
+func (a *adminHandler) mutateDoc(w http.ResponseWriter, r *http.Request, fn func(id string) error) {
+	id := r.PathValue("id")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, tag := a.docTag(id); !a.precondition(w, r, tag) {
+		return
+	}
+	if err := fn(id); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	doc, tag := a.docTag(id)
+	writeAdminJSON(w, http.StatusOK, tag, doc)
+}
+
+func (a *adminHandler) getAliases(w http.ResponseWriter, r *http.Request) {
+	aliases := a.aliases(r.PathValue("id"))
+	writeAdminJSON(w, http.StatusOK, contentTag(aliases), aliases)
+}
+
+// aliases returns the tool's aliases, never nil so that "no aliases" has a
+// stable ETag.
+func (a *adminHandler) aliases(id string) []string {
+	aliases := a.d.Aliases(id)
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return aliases
+}
+
+func (a *adminHandler) putAliases(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var body []string
+	if !decodeAdminJSON(w, r, &body) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.precondition(w, r, contentTag(a.aliases(id))) {
+		return
+	}
+	if err := a.d.RegisterAliases(id, body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	aliases := a.aliases(id)
+	writeAdminJSON(w, http.StatusOK, contentTag(aliases), aliases)
+}
+
+func (a *adminHandler) rollouts(w http.ResponseWriter) (rolloutIndex, bool) {
+	ri, ok := a.d.idx.(rolloutIndex)
+	if !ok {
+		http.Error(w, "index does not support rollouts", http.StatusNotImplemented)
+	}
+	return ri, ok
+}
+
+func (a *adminHandler) findRollout(ri rolloutIndex, id string) (index.Rollout, bool) {
+	for _, r := range ri.Rollouts() {
+		if r.ToolID == id {
+			return r, true
+		}
+	}
+	return index.Rollout{}, false
+}
+
+func (a *adminHandler) getRollout(w http.ResponseWriter, r *http.Request) {
+	ri, ok := a.rollouts(w)
+	if !ok {
+		return
+	}
+	rollout, ok := a.findRollout(ri, r.PathValue("id"))
+	if !ok {
+		writeAdminError(w, fmt.Errorf("%w: rollout %s", index.ErrNotFound, r.PathValue("id")))
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, a.versionTag(), rollout)
+}
+
+func (a *adminHandler) putRollout(w http.ResponseWriter, r *http.Request) {
+	ri, ok := a.rollouts(w)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	var body index.Rollout
+	if !decodeAdminJSON(w, r, &body) {
+		return
+	}
+	body.ToolID = id
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	current := ""
+	if _, exists := a.findRollout(ri, id); exists {
+		current = a.versionTag()
+	}
+	if !a.precondition(w, r, current) {
+		return
+	}
+	if err := ri.SetRollout(body); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	rollout, _ := a.findRollout(ri, id)
+	writeAdminJSON(w, http.StatusOK, a.versionTag(), rollout)
+}
+
+func (a *adminHandler) deleteRollout(w http.ResponseWriter, r *http.Request) {
+	ri, ok := a.rollouts(w)
+	if !ok {
+		return
+	}
+	id := r.PathValue("id")
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	current := ""
+	if _, exists := a.findRollout(ri, id); exists {
+		current = a.versionTag()
+	}
+	if !a.precondition(w, r, current) {
+		return
+	}
+	if err := ri.FinalizeRollout(id); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, http.StatusNoContent, a.versionTag(), nil)
+}
+
+func decodeAdminJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, etag string, v any) {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeAdminError maps catalog errors to HTTP statuses: missing tools and
+// docs are 404, everything else is a rejected request.
+func writeAdminError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, index.ErrNotFound) || errors.Is(err, tooldoc.ErrNotFound) || errors.Is(err, tooldoc.ErrExampleNotFound) {
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
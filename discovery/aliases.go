@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"slices"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/search"
+)
+
+// RegisterAliases sets alternate names for a tool, such as abbreviations an
+// LLM might use ("gh issue", "new ticket"). A query containing an alias is
+// expanded with the tool's namespace and name, for both BM25 and hybrid
+// search. Aliases replace any previously registered for toolID; an empty
+// list removes them. The tool need not be registered yet. An alias that is
+// another tool's ID, such as a tool's old name, marks the two as duplicates
+// (see MarkDuplicates).
+func (d *Discovery) RegisterAliases(toolID string, aliases []string) error {
+	id, err := index.ParseToolID(toolID)
+	if err != nil {
+		return err
+	}
+	target := id.Name
+	if id.Namespace != "" {
+		target = id.Namespace + " " + id.Name
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(aliases) == 0 {
+		delete(d.aliases, toolID)
+	} else {
+		if d.aliases == nil {
+			d.aliases = make(map[string]toolAliases)
+		}
+		d.aliases[toolID] = toolAliases{names: slices.Clone(aliases), target: target}
+	}
+	d.rebuildAliasSynonymsLocked()
+	d.rebuildDuplicatesLocked()
+	return nil
+}
+
+// Aliases returns the aliases registered for toolID.
+func (d *Discovery) Aliases(toolID string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Clone(d.aliases[toolID].names)
+}
+
+// toolAliases holds a tool's aliases and the query text they expand to.
+type toolAliases struct {
+	names  []string
+	target string
+}
+
+func (d *Discovery) rebuildAliasSynonymsLocked() {
+	ids := make([]string, 0, len(d.aliases))
+	for id := range d.aliases {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	synonyms := make(search.Synonyms)
+	for _, id := range ids {
+		a := d.aliases[id]
+		for _, alias := range a.names {
+			synonyms[alias] = append(synonyms[alias], a.target)
+		}
+	}
+	d.aliasSynonyms = synonyms.Normalized()
+}
+
+// removeAliases drops the aliases and duplicate marks of a tool that left
+// the index.
+func (d *Discovery) removeAliases(toolID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.aliases[toolID]; ok {
+		delete(d.aliases, toolID)
+		d.rebuildAliasSynonymsLocked()
+		d.rebuildDuplicatesLocked()
+	}
+	d.unmarkDuplicateLocked(toolID)
+}
+
+// expandQuery applies tool aliases and, for hybrid search, the configured
+// BM25 synonyms. BM25Searcher applies synonyms itself.
+func (d *Discovery) expandQuery(query string, hybrid bool) string {
+	d.mu.RLock()
+	query = search.ExpandQuery(query, d.aliasSynonyms)
+	d.mu.RUnlock()
+	if hybrid {
+		query = search.ExpandQuery(query, d.synonyms)
+	}
+	return query
+}
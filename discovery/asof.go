@@ -0,0 +1,236 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/search"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrHistoryUnavailable is returned by AsOf and AsOfVersion when the change
+// journal cannot reconstruct the requested state: the index does not
+// implement index.ChangeNotifier, the state predates the journal, or changes
+// since then were evicted (see Options.ChangeJournalSize).
+var ErrHistoryUnavailable = errcode.New(errcode.ChangesTruncated, "discovery: history unavailable")
+
+// HistoricalView is a read-only view of the catalog as it was at a past
+// point in the change journal, for reproducing what a client saw. It ranks
+// with the same configuration, aliases, duplicate marks, ranking model, and
+// interceptors as the Discovery it came from, as they are now, using a fresh
+// searcher: a search.BM25Searcher given as Options.Searcher is copied with
+// its configuration, and other custom searchers are replaced by the default
+// one. Tools, backends, custom metadata, disabled state, and documentation
+// are journaled, so metadata filters and DescribeTool at every detail level
+// answer as they did then. A documentation change belongs to the index
+// version current when it was made; SeeAlso references to tools absent from
+// the view are dropped. Index rollouts are not applied. Searches on the view
+// are not traced or counted in metrics.
+type HistoricalView struct {
+	disc    *Discovery
+	version uint64
+}
+
+// AsOf returns a view of the catalog as it was at t.
+func (d *Discovery) AsOf(t time.Time) (*HistoricalView, error) {
+	if d.journal == nil {
+		return nil, fmt.Errorf("%w: index does not journal changes", ErrHistoryUnavailable)
+	}
+	if t.Before(d.journal.startedAt) {
+		return nil, fmt.Errorf("%w: %s predates the change journal", ErrHistoryUnavailable, t.Format(time.RFC3339))
+	}
+	return d.historicalView(func(e journalEntry) bool { return e.at.After(t) })
+}
+
+// AsOfVersion returns a view of the catalog as it was at index version
+// version, after every change with a version up to and including it.
+func (d *Discovery) AsOfVersion(version uint64) (*HistoricalView, error) {
+	if d.journal == nil {
+		return nil, fmt.Errorf("%w: index does not journal changes", ErrHistoryUnavailable)
+	}
+	if version < d.journal.startVersion {
+		return nil, fmt.Errorf("%w: version %d predates the change journal", ErrHistoryUnavailable, version)
+	}
+	return d.historicalView(func(e journalEntry) bool { return e.version > version })
+}
+
+// historicalView builds a view with the entries matched by later undone.
+func (d *Discovery) historicalView(later func(journalEntry) bool) (*HistoricalView, error) {
+	state, docs, version, ok := d.journal.stateBefore(later)
+	if !ok {
+		return nil, fmt.Errorf("%w: changes were evicted from the journal", ErrHistoryUnavailable)
+	}
+
+	opts := d.opts
+	opts.Index = nil
+	if opts.VectorIndex != nil {
+		// Historical documents must not enter the live vector index.
+		if opts.Embedder == nil {
+			opts.Embedder = opts.VectorIndex.Embedder()
+		}
+		opts.VectorIndex = nil
+	}
+	// The view ranks with its own searcher, so historical documents never
+	// enter the caches of the live one.
+	if bm25, ok := opts.Searcher.(*search.BM25Searcher); ok {
+		opts.Searcher = search.NewBM25Searcher(bm25.Config())
+	} else {
+		opts.Searcher = nil
+	}
+	opts.MaxTools, opts.MaxToolsPerNamespace, opts.MaxTagsPerTool, opts.MaxDescriptionLen = 0, 0, 0, 0
+	opts.ResultCache, opts.QueryCache = nil, nil
+	opts.FeedbackStore = d.feedback
+	opts.RankingModel = d.rankingModel()
+	opts.TracerProvider, opts.MeterProvider = nil, nil
+	hist, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.RLock()
+	hist.aliases = maps.Clone(d.aliases)
+	hist.dupMarks = maps.Clone(d.dupMarks)
+	hist.searchInterceptors = slices.Clone(d.searchInterceptors)
+	hist.describeInterceptors = slices.Clone(d.describeInterceptors)
+	d.mu.RUnlock()
+	hist.rebuildAliasSynonymsLocked()
+	hist.rebuildDuplicatesLocked()
+
+	for _, id := range slices.Sorted(maps.Keys(state)) {
+		snap := state[id]
+		if snap.disabled {
+			continue
+		}
+		regs := make([]index.ToolRegistration, len(snap.backends))
+		for i, backend := range snap.backends {
+			regs[i] = index.ToolRegistration{Tool: snap.tool, Backend: backend, Metadata: snap.metadata}
+		}
+		if err := hist.idx.RegisterTools(regs); err != nil {
+			return nil, fmt.Errorf("restore %s: %w", id, err)
+		}
+	}
+
+	// SeeAlso may name tools documented later in the loop, so references
+	// are restored once every entry exists.
+	docIDs := slices.Sorted(maps.Keys(docs))
+	for _, id := range docIDs {
+		entry := *docs[id]
+		entry.SeeAlso = nil
+		if err := hist.docs.RegisterDoc(id, entry); err != nil {
+			return nil, fmt.Errorf("restore doc %s: %w", id, err)
+		}
+	}
+	for _, id := range docIDs {
+		entry := *docs[id]
+		if len(entry.SeeAlso) == 0 {
+			continue
+		}
+		entry.SeeAlso = slices.DeleteFunc(slices.Clone(entry.SeeAlso), func(ref string) bool {
+			_, _, err := hist.idx.GetTool(ref)
+			return err != nil && docs[ref] == nil
+		})
+		if err := hist.docs.RegisterDoc(id, entry); err != nil {
+			return nil, fmt.Errorf("restore doc %s: %w", id, err)
+		}
+	}
+	return &HistoricalView{disc: hist, version: version}, nil
+}
+
+// Version returns the index version of the view: that of the last change
+// it includes.
+func (v *HistoricalView) Version() uint64 {
+	return v.version
+}
+
+// Search searches the view like Discovery.Search.
+func (v *HistoricalView) Search(ctx context.Context, query string, limit int) (Results, error) {
+	return v.disc.Search(ctx, query, limit)
+}
+
+// SearchFor searches the view like Discovery.SearchFor.
+func (v *HistoricalView) SearchFor(ctx context.Context, principal, query string, limit int) (Results, error) {
+	return v.disc.SearchFor(ctx, principal, query, limit)
+}
+
+// SearchWithOptions searches the view like Discovery.SearchWithOptions.
+func (v *HistoricalView) SearchWithOptions(ctx context.Context, query string, limit int, opts ...SearchOption) (Results, error) {
+	return v.disc.SearchWithOptions(ctx, query, limit, opts...)
+}
+
+// SearchForWithOptions searches the view like
+// Discovery.SearchForWithOptions.
+func (v *HistoricalView) SearchForWithOptions(ctx context.Context, principal, query string, limit int, opts ...SearchOption) (Results, error) {
+	return v.disc.SearchForWithOptions(ctx, principal, query, limit, opts...)
+}
+
+// GetTool returns a tool as it was in the view.
+func (v *HistoricalView) GetTool(id string) (model.Tool, model.ToolBackend, error) {
+	return v.disc.GetTool(id)
+}
+
+// GetAllBackends returns a tool's backends as they were in the view.
+func (v *HistoricalView) GetAllBackends(id string) ([]model.ToolBackend, error) {
+	return v.disc.GetAllBackends(id)
+}
+
+// DescribeTool describes a tool as it was in the view.
+func (v *HistoricalView) DescribeTool(id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+	return v.disc.DescribeTool(id, level)
+}
+
+// ListNamespaces returns the namespaces in the view.
+func (v *HistoricalView) ListNamespaces() ([]string, error) {
+	return v.disc.ListNamespaces()
+}
+
+// stateBefore undoes the entries matched by later, newest first, and
+// returns the resulting tool states and documentation with the version of
+// the last tool change kept. It reports false when an evicted entry
+// matches, since its change can no longer be undone.
+func (j *changeJournal) stateBefore(later func(journalEntry) bool) (map[string]*toolSnapshot, map[string]*tooldoc.DocEntry, uint64, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.dropped != nil && later(*j.dropped) {
+		return nil, nil, 0, false
+	}
+	if j.docDropped != nil && later(j.docDropped.mark()) {
+		return nil, nil, 0, false
+	}
+	docs := maps.Clone(j.docCurrent)
+	for i := len(j.docEntries) - 1; i >= 0; i-- {
+		e := j.docEntries[i]
+		if !later(e.mark()) {
+			continue
+		}
+		if e.before == nil {
+			delete(docs, e.toolID)
+		} else {
+			docs[e.toolID] = e.before
+		}
+	}
+
+	state := maps.Clone(j.current)
+	version := j.startVersion
+	if j.dropped != nil {
+		version = max(version, j.dropped.version)
+	}
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		e := j.entries[i]
+		if !later(e) {
+			version = max(version, e.version)
+			continue
+		}
+		if e.before == nil {
+			delete(state, e.toolID)
+		} else {
+			state[e.toolID] = e.before
+		}
+	}
+	return state, docs, version, true
+}
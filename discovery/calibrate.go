@@ -0,0 +1,252 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidCalibration is returned when a calibrator cannot be fit from the
+// given samples.
+var ErrInvalidCalibration = errcode.New(errcode.InvalidArgument, "discovery: invalid calibration")
+
+// CalibrationMethod selects how raw scores are mapped to confidences.
+type CalibrationMethod string
+
+const (
+	// CalibrationIsotonic fits a non-decreasing step function to the samples
+	// (pool adjacent violators), interpolating linearly between steps. It
+	// makes no assumption about the score distribution but needs more
+	// samples to be smooth.
+	CalibrationIsotonic CalibrationMethod = "isotonic"
+
+	// CalibrationPlatt fits a sigmoid 1/(1+exp(A*score+B)). It works well
+	// with few samples when confidence rises smoothly with score.
+	CalibrationPlatt CalibrationMethod = "platt"
+)
+
+// ScoreSample is a labeled raw score: the score a search assigned to a result
+// and whether that result was actually relevant to the query.
+type ScoreSample struct {
+	Score    float64
+	Relevant bool
+}
+
+// Calibrator maps raw scores of one ScoreType to a 0–1 confidence, the
+// estimated probability that a result with that score is relevant. Raw BM25,
+// embedding, and hybrid scores are on different scales, so fit one calibrator
+// per search configuration.
+//
+// A Calibrator is immutable and safe for concurrent use.
+type Calibrator struct {
+	method CalibrationMethod
+
+	// Platt sigmoid parameters.
+	a, b float64
+
+	// Isotonic steps, ordered by score.
+	steps []calibrationStep
+}
+
+type calibrationStep struct {
+	lo, hi float64 // score range covered by the step
+	value  float64
+}
+
+// FitCalibrator fits a calibrator to labeled samples. The samples must
+// contain at least one relevant and one irrelevant result, and no NaN or
+// infinite scores.
+func FitCalibrator(samples []ScoreSample, method CalibrationMethod) (*Calibrator, error) {
+	var pos, neg int
+	for _, s := range samples {
+		if math.IsNaN(s.Score) || math.IsInf(s.Score, 0) {
+			return nil, fmt.Errorf("%w: non-finite score", ErrInvalidCalibration)
+		}
+		if s.Relevant {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	if pos == 0 || neg == 0 {
+		return nil, fmt.Errorf("%w: need both relevant and irrelevant samples", ErrInvalidCalibration)
+	}
+
+	switch method {
+	case CalibrationIsotonic:
+		return &Calibrator{method: method, steps: fitIsotonic(samples)}, nil
+	case CalibrationPlatt:
+		a, b := fitPlatt(samples, pos, neg)
+		return &Calibrator{method: method, a: a, b: b}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidCalibration, method)
+	}
+}
+
+// Method returns the calibration method used.
+func (c *Calibrator) Method() CalibrationMethod {
+	return c.method
+}
+
+// Confidence maps a raw score to a confidence in [0, 1]. The mapping is
+// non-decreasing in score.
+func (c *Calibrator) Confidence(score float64) float64 {
+	if c.method == CalibrationPlatt {
+		return 1 / (1 + math.Exp(c.a*score+c.b))
+	}
+
+	steps := c.steps
+	if score <= steps[0].hi {
+		return steps[0].value
+	}
+	last := steps[len(steps)-1]
+	if score >= last.lo {
+		return last.value
+	}
+	// First step whose range ends at or above score.
+	i := sort.Search(len(steps), func(i int) bool { return steps[i].hi >= score })
+	if score >= steps[i].lo {
+		return steps[i].value
+	}
+	prev := steps[i-1]
+	t := (score - prev.hi) / (steps[i].lo - prev.hi)
+	return prev.value + t*(steps[i].value-prev.value)
+}
+
+// FilterByConfidence returns results whose calibrated confidence is at least
+// minConfidence.
+func (r Results) FilterByConfidence(c *Calibrator, minConfidence float64) Results {
+	var filtered Results
+	for _, result := range r {
+		if c.Confidence(result.Score) >= minConfidence {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// fitIsotonic runs pool adjacent violators over samples sorted by score.
+// Samples sharing a score start in the same block so ties get one value.
+func fitIsotonic(samples []ScoreSample) []calibrationStep {
+	sorted := make([]ScoreSample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Score < sorted[j].Score })
+
+	type block struct {
+		lo, hi float64
+		sum    float64
+		count  float64
+	}
+	var blocks []block
+	for _, s := range sorted {
+		label := 0.0
+		if s.Relevant {
+			label = 1
+		}
+		if n := len(blocks); n > 0 && blocks[n-1].hi == s.Score {
+			blocks[n-1].sum += label
+			blocks[n-1].count++
+		} else {
+			blocks = append(blocks, block{lo: s.Score, hi: s.Score, sum: label, count: 1})
+		}
+		for n := len(blocks); n > 1 && blocks[n-2].sum/blocks[n-2].count >= blocks[n-1].sum/blocks[n-1].count; n-- {
+			blocks[n-2].hi = blocks[n-1].hi
+			blocks[n-2].sum += blocks[n-1].sum
+			blocks[n-2].count += blocks[n-1].count
+			blocks = blocks[:n-1]
+		}
+	}
+
+	steps := make([]calibrationStep, len(blocks))
+	for i, b := range blocks {
+		steps[i] = calibrationStep{lo: b.lo, hi: b.hi, value: b.sum / b.count}
+	}
+	return steps
+}
+
+// fitPlatt fits sigmoid parameters by Newton's method with backtracking line
+// search, using Platt's smoothed targets to avoid overfitting separable
+// samples (Lin, Lin and Weng, 2007).
+func fitPlatt(samples []ScoreSample, pos, neg int) (float64, float64) {
+	const (
+		maxIter = 100
+		minStep = 1e-10
+		sigma   = 1e-12
+		eps     = 1e-5
+	)
+
+	hiTarget := (float64(pos) + 1) / (float64(pos) + 2)
+	loTarget := 1 / (float64(neg) + 2)
+	targets := make([]float64, len(samples))
+	for i, s := range samples {
+		if s.Relevant {
+			targets[i] = hiTarget
+		} else {
+			targets[i] = loTarget
+		}
+	}
+
+	objective := func(a, b float64) float64 {
+		var f float64
+		for i, s := range samples {
+			fApB := s.Score*a + b
+			if fApB >= 0 {
+				f += targets[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				f += (targets[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return f
+	}
+
+	a, b := 0.0, math.Log((float64(neg)+1)/(float64(pos)+1))
+	fval := objective(a, b)
+	for range maxIter {
+		// Gradient and Hessian (with a small ridge for stability).
+		h11, h22, h21 := sigma, sigma, 0.0
+		g1, g2 := 0.0, 0.0
+		for i, s := range samples {
+			fApB := s.Score*a + b
+			var p, q float64
+			if fApB >= 0 {
+				e := math.Exp(-fApB)
+				p, q = e/(1+e), 1/(1+e)
+			} else {
+				e := math.Exp(fApB)
+				p, q = 1/(1+e), e/(1+e)
+			}
+			d2 := p * q
+			h11 += s.Score * s.Score * d2
+			h22 += d2
+			h21 += s.Score * d2
+			d1 := targets[i] - p
+			g1 += s.Score * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < eps && math.Abs(g2) < eps {
+			break
+		}
+
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		step := 1.0
+		for step >= minStep {
+			newA, newB := a+step*dA, b+step*dB
+			newF := objective(newA, newB)
+			if newF < fval+0.0001*step*gd {
+				a, b, fval = newA, newB, newF
+				break
+			}
+			step /= 2
+		}
+		if step < minStep {
+			break
+		}
+	}
+	return a, b
+}
@@ -0,0 +1,394 @@
+package discovery
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// DefaultChangeJournalSize is the number of change entries retained when
+// Options.ChangeJournalSize is zero.
+const DefaultChangeJournalSize = 10000
+
+// ChangeKind classifies a tool in a changelog.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "updated"
+)
+
+// Changelog field names used in FieldDiff.Field.
+const (
+	FieldDescription  = "description"
+	FieldInputSchema  = "inputSchema"
+	FieldOutputSchema = "outputSchema"
+	FieldTags         = "tags"
+)
+
+// FieldDiff describes a changed tool field.
+type FieldDiff struct {
+	Field  string `json:"field"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// ToolChange summarizes how a single tool changed over a changelog period.
+type ToolChange struct {
+	ToolID    string      `json:"toolId"`
+	Kind      ChangeKind  `json:"kind"`
+	Version   uint64      `json:"version"`
+	Timestamp time.Time   `json:"timestamp"`
+	Diffs     []FieldDiff `json:"diffs,omitempty"`
+}
+
+// Changelog is a structured digest of catalog changes.
+type Changelog struct {
+	// Added, Removed, and Updated are sorted by tool ID.
+	Added   []ToolChange `json:"added,omitempty"`
+	Removed []ToolChange `json:"removed,omitempty"`
+	Updated []ToolChange `json:"updated,omitempty"`
+
+	// FromVersion and ToVersion bound the index versions covered.
+	FromVersion uint64 `json:"fromVersion"`
+	ToVersion   uint64 `json:"toVersion"`
+
+	// Truncated reports that the journal no longer holds every change in the
+	// requested period, so the digest may be incomplete.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Empty reports whether the changelog contains no changes.
+func (c Changelog) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Updated) == 0
+}
+
+// Changes returns a changelog of tool changes recorded after since.
+func (d *Discovery) Changes(since time.Time) Changelog {
+	if d.journal == nil {
+		return Changelog{}
+	}
+	return d.journal.changelog(func(e journalEntry) bool { return e.at.After(since) })
+}
+
+// ChangesSince returns a changelog of tool changes with an index version
+// greater than version.
+func (d *Discovery) ChangesSince(version uint64) Changelog {
+	if d.journal == nil {
+		return Changelog{}
+	}
+	return d.journal.changelog(func(e journalEntry) bool { return e.version > version })
+}
+
+// toolSnapshot captures a tool's state for changelogs and AsOf views.
+type toolSnapshot struct {
+	tool     model.Tool
+	backends []model.ToolBackend
+	tags     []string // normalized, for diffing
+	metadata map[string]string
+	disabled bool
+}
+
+// snapshotTool captures tool, normalizing its tags with the tag policy of
+// idx.
+func snapshotTool(idx index.Index, tool model.Tool, backends []model.ToolBackend) *toolSnapshot {
+	return &toolSnapshot{
+		tool:     tool,
+		backends: backends,
+		tags:     index.NormalizeTags(idx, tool.Tags),
+	}
+}
+
+type journalEntry struct {
+	toolID  string
+	version uint64
+	at      time.Time
+	before  *toolSnapshot
+	after   *toolSnapshot
+}
+
+// docJournalEntry records a documentation change. Its version is the index
+// version current when the change was made.
+type docJournalEntry struct {
+	toolID  string
+	version uint64
+	at      time.Time
+	before  *tooldoc.DocEntry
+	after   *tooldoc.DocEntry
+}
+
+// mark returns e as a journalEntry for the predicates shared with tool
+// entries.
+func (e docJournalEntry) mark() journalEntry {
+	return journalEntry{toolID: e.toolID, version: e.version, at: e.at}
+}
+
+// changeJournal records tool snapshots for every index change and
+// documentation entries for every doc store change.
+type changeJournal struct {
+	mu      sync.Mutex
+	idx     index.Index
+	docs    *tooldoc.InMemoryStore
+	size    int
+	entries []journalEntry
+	dropped *journalEntry // newest entry evicted from the journal
+	current map[string]*toolSnapshot
+	changed map[string]time.Time // last change per tool, for recency ranking
+	now     func() time.Time
+
+	docEntries []docJournalEntry
+	docDropped *docJournalEntry // newest doc entry evicted from the journal
+	docCurrent map[string]*tooldoc.DocEntry
+
+	// startVersion and startedAt bound the history AsOf can reconstruct.
+	startVersion uint64
+	startedAt    time.Time
+}
+
+func newChangeJournal(idx index.Index, notifier index.ChangeNotifier, docs *tooldoc.InMemoryStore, size int, now func() time.Time) *changeJournal {
+	if size <= 0 {
+		size = DefaultChangeJournalSize
+	}
+	if now == nil {
+		now = time.Now
+	}
+	j := &changeJournal{
+		idx:        idx,
+		docs:       docs,
+		size:       size,
+		current:    make(map[string]*toolSnapshot),
+		changed:    make(map[string]time.Time),
+		now:        now,
+		docCurrent: make(map[string]*tooldoc.DocEntry),
+	}
+	j.startVersion = index.Version(idx)
+	j.startedAt = now()
+	if summaries, err := idx.Search("", 1000000); err == nil {
+		for _, s := range summaries {
+			if snap, err := j.snapshot(s.ID); err == nil {
+				j.current[s.ID] = snap
+			}
+		}
+	}
+	notifier.OnChange(j.record)
+	if docs != nil {
+		for _, id := range docs.DocIDs() {
+			if entry, err := docs.GetDoc(id); err == nil {
+				j.docCurrent[id] = &entry
+			}
+		}
+		docs.OnChange(j.recordDoc)
+	}
+	return j
+}
+
+func (j *changeJournal) record(ev index.ChangeEvent) {
+	var after *toolSnapshot
+	disabled := false
+	switch ev.Type {
+	case index.ChangeRegistered, index.ChangeUpdated, index.ChangeBackendRemoved, index.ChangeEnabled:
+		snap, err := j.snapshot(ev.ToolID)
+		if err != nil {
+			return
+		}
+		after = snap
+	case index.ChangeToolRemoved:
+	case index.ChangeDisabled:
+		disabled = true
+	default:
+		return
+	}
+
+	at := j.now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	before := j.current[ev.ToolID]
+	switch {
+	case disabled:
+		if before == nil {
+			return
+		}
+		hidden := *before
+		hidden.disabled = true
+		after = &hidden
+		j.current[ev.ToolID] = after
+	case after == nil:
+		delete(j.current, ev.ToolID)
+		delete(j.changed, ev.ToolID)
+	default:
+		j.current[ev.ToolID] = after
+		if ev.Type != index.ChangeEnabled {
+			j.changed[ev.ToolID] = at
+		}
+	}
+	j.entries = append(j.entries, journalEntry{
+		toolID:  ev.ToolID,
+		version: ev.Version,
+		at:      at,
+		before:  before,
+		after:   after,
+	})
+	if len(j.entries) > j.size {
+		evicted := j.entries[len(j.entries)-j.size-1]
+		j.dropped = &evicted
+		j.entries = slices.Delete(j.entries, 0, len(j.entries)-j.size)
+	}
+}
+
+// recordDoc journals the documentation of ev.ToolID after a doc store change.
+func (j *changeJournal) recordDoc(ev tooldoc.ChangeEvent) {
+	var after *tooldoc.DocEntry
+	if ev.Type != tooldoc.ChangeRemoved {
+		entry, err := j.docs.GetDoc(ev.ToolID)
+		if err != nil {
+			return
+		}
+		after = &entry
+	}
+
+	version := index.Version(j.idx)
+	at := j.now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	before := j.docCurrent[ev.ToolID]
+	if after == nil {
+		delete(j.docCurrent, ev.ToolID)
+	} else {
+		j.docCurrent[ev.ToolID] = after
+	}
+	j.docEntries = append(j.docEntries, docJournalEntry{
+		toolID:  ev.ToolID,
+		version: version,
+		at:      at,
+		before:  before,
+		after:   after,
+	})
+	if len(j.docEntries) > j.size {
+		evicted := j.docEntries[len(j.docEntries)-j.size-1]
+		j.docDropped = &evicted
+		j.docEntries = slices.Delete(j.docEntries, 0, len(j.docEntries)-j.size)
+	}
+}
+
+// snapshot captures the indexed state of toolID, including its custom
+// metadata when the index keeps any.
+func (j *changeJournal) snapshot(toolID string) (*toolSnapshot, error) {
+	tool, _, err := j.idx.GetTool(toolID)
+	if err != nil {
+		return nil, err
+	}
+	backends, err := j.idx.GetAllBackends(toolID)
+	if err != nil {
+		return nil, err
+	}
+	snap := snapshotTool(j.idx, tool, backends)
+	if m, ok := j.idx.(interface {
+		GetMetadata(id string) (map[string]string, error)
+	}); ok {
+		if snap.metadata, err = m.GetMetadata(toolID); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+// lastChanged returns when toolID was last registered or updated since the
+// journal started.
+func (j *changeJournal) lastChanged(toolID string) (time.Time, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	at, ok := j.changed[toolID]
+	return at, ok
+}
+
+// changelog collapses matching entries into one change per tool: the state
+// before the first matching entry is compared with the state after the last.
+func (j *changeJournal) changelog(match func(journalEntry) bool) Changelog {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var log Changelog
+	log.Truncated = j.dropped != nil && match(*j.dropped)
+
+	type span struct {
+		first, last journalEntry
+	}
+	spans := make(map[string]*span)
+	for _, e := range j.entries {
+		if !match(e) {
+			continue
+		}
+		if log.FromVersion == 0 || e.version < log.FromVersion {
+			log.FromVersion = e.version
+		}
+		log.ToVersion = max(log.ToVersion, e.version)
+		if sp, ok := spans[e.toolID]; ok {
+			sp.last = e
+		} else {
+			spans[e.toolID] = &span{first: e, last: e}
+		}
+	}
+
+	for id, sp := range spans {
+		change := ToolChange{ToolID: id, Version: sp.last.version, Timestamp: sp.last.at}
+		before, after := sp.first.before, sp.last.after
+		switch {
+		case before == nil && after == nil:
+			continue
+		case before == nil:
+			change.Kind = ChangeAdded
+			log.Added = append(log.Added, change)
+		case after == nil:
+			change.Kind = ChangeRemoved
+			log.Removed = append(log.Removed, change)
+		default:
+			change.Diffs = diffSnapshots(before, after)
+			if len(change.Diffs) == 0 {
+				continue
+			}
+			change.Kind = ChangeModified
+			log.Updated = append(log.Updated, change)
+		}
+	}
+
+	for _, list := range [][]ToolChange{log.Added, log.Removed, log.Updated} {
+		sort.Slice(list, func(a, b int) bool { return list[a].ToolID < list[b].ToolID })
+	}
+	return log
+}
+
+func diffSnapshots(before, after *toolSnapshot) []FieldDiff {
+	var diffs []FieldDiff
+	if before.tool.Description != after.tool.Description {
+		diffs = append(diffs, FieldDiff{Field: FieldDescription, Before: before.tool.Description, After: after.tool.Description})
+	}
+	if !jsonEqual(before.tool.InputSchema, after.tool.InputSchema) {
+		diffs = append(diffs, FieldDiff{Field: FieldInputSchema, Before: before.tool.InputSchema, After: after.tool.InputSchema})
+	}
+	if !jsonEqual(before.tool.OutputSchema, after.tool.OutputSchema) {
+		diffs = append(diffs, FieldDiff{Field: FieldOutputSchema, Before: before.tool.OutputSchema, After: after.tool.OutputSchema})
+	}
+	if !slices.Equal(before.tags, after.tags) {
+		diffs = append(diffs, FieldDiff{Field: FieldTags, Before: before.tags, After: after.tags})
+	}
+	return diffs
+}
+
+func jsonEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return string(aj) == string(bj)
+}
@@ -1,7 +1,13 @@
 package discovery
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/semantic"
@@ -19,11 +25,74 @@ type CompositeSearcher interface {
 	GetScoreType() ScoreType
 }
 
+// deadlineSearcher is implemented by composite searchers that support
+// Options.SearchDeadline.
+type deadlineSearcher interface {
+	SearchWithDeadline(ctx context.Context, query string, limit int, docs []index.SearchDoc, deadline time.Time) (Results, bool, error)
+}
+
+var _ deadlineSearcher = (*HybridSearcher)(nil)
+
 // HybridSearcher combines BM25 and semantic search with configurable weighting.
 type HybridSearcher struct {
 	bm25Strategy      semantic.Strategy
 	embeddingStrategy semantic.Strategy
+	routes            []embeddingRoute
+	vectors           *semantic.VectorIndex
 	alpha             float64 // BM25 weight (1-alpha for semantic)
+	fusion            FusionMode
+	rrfK              float64
+	boolean           bool
+}
+
+// FusionMode selects how HybridSearcher combines BM25 and embedding scores.
+type FusionMode string
+
+const (
+	// FusionWeighted sums Alpha*BM25 + (1-Alpha)*embedding. It is the default.
+	FusionWeighted FusionMode = "weighted"
+
+	// FusionRRF merges the BM25 and embedding rankings by reciprocal rank:
+	// Alpha/(K+lexicalRank) + (1-Alpha)/(K+semanticRank). Raw scores only
+	// determine each list's order, so their scales need not be comparable.
+	FusionRRF FusionMode = "rrf"
+)
+
+// DefaultRRFK is the reciprocal rank fusion constant used when
+// HybridOptions.RRFK is zero.
+const DefaultRRFK = 60
+
+// EmbedderRoute sends documents in the given namespaces or with any of the
+// given tags to a dedicated embedder, such as a code-specialized model for
+// source control tools. The query is embedded with the same embedder so
+// document and query vectors share a space.
+type EmbedderRoute struct {
+	// Namespaces matches documents by exact namespace.
+	Namespaces []string
+
+	// Tags matches documents carrying any of these tags (case-insensitive).
+	Tags []string
+
+	// Embedder embeds matching documents and the query. Required.
+	Embedder semantic.Embedder
+}
+
+type embeddingRoute struct {
+	namespaces map[string]struct{}
+	tags       map[string]struct{}
+	strategy   semantic.Strategy
+}
+
+func (r embeddingRoute) matches(doc semantic.Document) bool {
+	if _, ok := r.namespaces[doc.Namespace]; ok {
+		return true
+	}
+	for _, tag := range doc.Tags {
+		if _, ok := r.tags[tag]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 // HybridOptions configures a HybridSearcher.
@@ -31,17 +100,47 @@ type HybridOptions struct {
 	// BM25Scorer is an optional custom BM25 scorer. If nil, uses default.
 	BM25Scorer semantic.BM25Scorer
 
-	// Embedder generates embeddings for semantic search. Required.
+	// Embedder generates embeddings for semantic search. Required unless
+	// VectorIndex is set.
 	Embedder semantic.Embedder
 
+	// VectorIndex caches document embeddings across queries. When set,
+	// documents not matched by EmbedderRoutes are scored against its stored
+	// vectors, using its embedder and metric, so a query costs one Embed call
+	// plus embeddings for new or changed documents.
+	VectorIndex *semantic.VectorIndex
+
 	// Alpha is the BM25 weight (0.0 to 1.0). Semantic weight is 1-Alpha.
 	// Default: 0.5 (equal weighting)
 	Alpha float64
+
+	// Metric compares embeddings. Default: semantic.MetricCosine.
+	Metric semantic.Metric
+
+	// Fusion selects how BM25 and embedding scores are combined.
+	// Default: FusionWeighted.
+	Fusion FusionMode
+
+	// RRFK dampens the weight of top ranks under FusionRRF; larger values
+	// flatten the contribution curve. Default: DefaultRRFK.
+	RRFK float64
+
+	// EmbedderRoutes overrides Embedder for matching documents. Routes are
+	// checked in order and the first match wins; unmatched documents use
+	// Embedder.
+	EmbedderRoutes []EmbedderRoute
+
+	// BooleanQueries parses queries with semantic.ParseQuery, so AND, OR,
+	// NOT, quoted phrases, and field prefixes filter the documents scored
+	// and the remaining words rank them. A query of filters only returns
+	// the matching documents in ID order with zero scores. Invalid queries
+	// return semantic.ErrInvalidQuery.
+	BooleanQueries bool
 }
 
 // NewHybridSearcher creates a new hybrid searcher combining BM25 and semantic search.
 func NewHybridSearcher(opts HybridOptions) (*HybridSearcher, error) {
-	if opts.Embedder == nil {
+	if opts.Embedder == nil && opts.VectorIndex == nil {
 		return nil, semantic.ErrInvalidEmbedder
 	}
 
@@ -50,16 +149,144 @@ func NewHybridSearcher(opts HybridOptions) (*HybridSearcher, error) {
 		return nil, semantic.ErrInvalidHybridConfig
 	}
 
+	fusion := opts.Fusion
+	switch fusion {
+	case "":
+		fusion = FusionWeighted
+	case FusionWeighted, FusionRRF:
+	default:
+		return nil, fmt.Errorf("%w: unknown fusion mode %q", semantic.ErrInvalidHybridConfig, fusion)
+	}
+	rrfK := opts.RRFK
+	if rrfK < 0 {
+		return nil, fmt.Errorf("%w: rrf k must be non-negative", semantic.ErrInvalidHybridConfig)
+	}
+	if rrfK == 0 {
+		rrfK = DefaultRRFK
+	}
+
 	bm25 := semantic.NewBM25Strategy(opts.BM25Scorer)
-	embedding := semantic.NewEmbeddingStrategy(opts.Embedder)
+	var embedding semantic.Strategy
+	if opts.VectorIndex == nil {
+		var err error
+		embedding, err = semantic.NewEmbeddingStrategyWithMetric(opts.Embedder, opts.Metric)
+		if err != nil {
+			return nil, err
+		}
+	} else if !opts.Metric.Valid() {
+		return nil, semantic.ErrInvalidMetric
+	} else {
+		embedding = semantic.NewVectorStrategy(opts.VectorIndex)
+	}
+
+	routes := make([]embeddingRoute, 0, len(opts.EmbedderRoutes))
+	for i, route := range opts.EmbedderRoutes {
+		if route.Embedder == nil {
+			return nil, fmt.Errorf("%w: embedder route %d", semantic.ErrInvalidEmbedder, i)
+		}
+		if len(route.Namespaces) == 0 && len(route.Tags) == 0 {
+			return nil, fmt.Errorf("%w: embedder route %d has no namespaces or tags", semantic.ErrInvalidHybridConfig, i)
+		}
+		strategy, err := semantic.NewEmbeddingStrategyWithMetric(route.Embedder, opts.Metric)
+		if err != nil {
+			return nil, err
+		}
+		r := embeddingRoute{
+			namespaces: make(map[string]struct{}, len(route.Namespaces)),
+			tags:       make(map[string]struct{}, len(route.Tags)),
+			strategy:   strategy,
+		}
+		for _, ns := range route.Namespaces {
+			r.namespaces[ns] = struct{}{}
+		}
+		for _, tag := range route.Tags {
+			r.tags[strings.ToLower(strings.TrimSpace(tag))] = struct{}{}
+		}
+		routes = append(routes, r)
+	}
 
 	return &HybridSearcher{
 		bm25Strategy:      bm25,
 		embeddingStrategy: embedding,
+		routes:            routes,
+		vectors:           opts.VectorIndex,
 		alpha:             alpha,
+		fusion:            fusion,
+		rrfK:              rrfK,
+		boolean:           opts.BooleanQueries,
 	}, nil
 }
 
+// routeFor returns the index of the first route matching doc, or -1 when doc
+// uses the default embedder.
+func (h *HybridSearcher) routeFor(doc semantic.Document) int {
+	for i, route := range h.routes {
+		if route.matches(doc) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Warm embeds docs into the vector index ahead of the first query, skipping
+// documents whose stored vector is current and documents routed to another
+// embedder. It is a no-op without a VectorIndex.
+func (h *HybridSearcher) Warm(ctx context.Context, docs []index.SearchDoc) error {
+	if h.vectors == nil {
+		return nil
+	}
+	semDocs := semantic.DocumentsFromSearchDocs(docs)
+	pending := make([]semantic.Document, 0, len(semDocs))
+	for _, doc := range semDocs {
+		normalized := doc.Normalized()
+		if h.routeFor(normalized) < 0 {
+			pending = append(pending, normalized)
+		}
+	}
+	return h.vectors.UpsertBatch(ctx, pending)
+}
+
+// embeddingScores scores normalized against query, grouping documents by
+// routed strategy so each group is scored in one batch. Batching embeds the
+// query once per group and uses BatchEmbedder or the vector index when
+// available.
+func (h *HybridSearcher) embeddingScores(ctx context.Context, query string, normalized []semantic.Document) ([]float64, error) {
+	type group struct {
+		strategy  semantic.Strategy
+		positions []int
+		docs      []semantic.Document
+	}
+	var groups []*group
+	byStrategy := make(map[int]*group)
+	for i, doc := range normalized {
+		key := h.routeFor(doc)
+		g, ok := byStrategy[key]
+		if !ok {
+			strategy := h.embeddingStrategy
+			if key >= 0 {
+				strategy = h.routes[key].strategy
+			}
+			g = &group{strategy: strategy}
+			byStrategy[key] = g
+			groups = append(groups, g)
+		}
+		g.positions = append(g.positions, i)
+		g.docs = append(g.docs, doc)
+	}
+
+	scores := make([]float64, len(normalized))
+	for _, g := range groups {
+		groupScores, err := semantic.ScoreDocuments(ctx, g.strategy, query, g.docs)
+		if err != nil {
+			return nil, err
+		}
+		for i, pos := range g.positions {
+			scores[pos] = groupScores[i]
+		}
+	}
+	return scores, nil
+}
+
 // Search implements index.Searcher using hybrid scoring.
 func (h *HybridSearcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
 	if limit <= 0 {
@@ -76,34 +303,89 @@ func (h *HybridSearcher) Search(query string, limit int, docs []index.SearchDoc)
 
 // SearchWithScores returns results with detailed hybrid scores.
 func (h *HybridSearcher) SearchWithScores(ctx context.Context, query string, limit int, docs []index.SearchDoc) (Results, error) {
+	results, _, err := h.SearchWithDeadline(ctx, query, limit, docs, time.Time{})
+	return results, err
+}
+
+// DeadlineChunkSize is the number of documents SearchWithDeadline scores
+// between deadline checks.
+const DeadlineChunkSize = 256
+
+// SearchWithDeadline is SearchWithScores with a soft deadline. Documents are
+// scored in chunks of DeadlineChunkSize; once deadline passes, no further
+// chunks are scored and the best results among the scored documents are
+// returned with partial set, marked Result.Partial. The first chunk is always
+// scored, and a chunk still scoring at the deadline is abandoned. A zero
+// deadline scores every document in one batch. Cancellation of ctx still
+// fails the search.
+func (h *HybridSearcher) SearchWithDeadline(ctx context.Context, query string, limit int, docs []index.SearchDoc, deadline time.Time) (results Results, partial bool, err error) {
 	if limit <= 0 {
-		return Results{}, nil
+		return Results{}, false, nil
 	}
 
 	// Convert SearchDocs to semantic Documents
 	semDocs := semantic.DocumentsFromSearchDocs(docs)
 
-	// Score all documents
-	scored := make([]scoredDoc, 0, len(docs))
-
+	normalized := make([]semantic.Document, len(semDocs))
 	for i, doc := range semDocs {
-		normalized := doc.Normalized()
+		normalized[i] = doc.Normalized()
+	}
 
-		bm25Score, err := h.bm25Strategy.Score(ctx, query, normalized)
+	if h.boolean {
+		parsed, err := semantic.ParseQuery(query)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+		if parsed.HasFilters() {
+			docs, normalized = filterQueryDocs(parsed, docs, normalized)
+		}
+		if query = parsed.Text(); query == "" {
+			return filterOnlyResults(docs, limit), false, nil
 		}
+	}
 
-		embScore, err := h.embeddingStrategy.Score(ctx, query, normalized)
+	chunk := len(normalized)
+	if !deadline.IsZero() {
+		chunk = DeadlineChunkSize
+	}
+	embScores := make([]float64, 0, len(normalized))
+	bm25Scores := make([]float64, 0, len(normalized))
+	for start := 0; start < len(normalized); start += chunk {
+		end := min(start+chunk, len(normalized))
+		chunkCtx, cancel := ctx, context.CancelFunc(func() {})
+		if start > 0 {
+			if !time.Now().Before(deadline) {
+				partial = true
+				break
+			}
+			chunkCtx, cancel = context.WithDeadline(ctx, deadline)
+		}
+		emb, bm25, err := h.scoreChunk(chunkCtx, query, normalized[start:end])
+		cancel()
 		if err != nil {
-			return nil, err
+			if start > 0 && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				partial = true
+				break
+			}
+			return nil, false, err
 		}
-
-		// Weighted combination
-		hybridScore := h.alpha*bm25Score + (1-h.alpha)*embScore
-
-		if hybridScore > 0 {
-			scored = append(scored, scoredDoc{idx: i, score: hybridScore})
+		embScores = append(embScores, emb...)
+		bm25Scores = append(bm25Scores, bm25...)
+	}
+	docs = docs[:len(bm25Scores)]
+	normalized = normalized[:len(bm25Scores)]
+
+	var scored []scoredDoc
+	if h.fusion == FusionRRF {
+		scored = h.fuseRRF(bm25Scores, embScores, docs)
+	} else {
+		scored = make([]scoredDoc, 0, len(docs))
+		for i := range normalized {
+			// Weighted combination
+			hybridScore := h.alpha*bm25Scores[i] + (1-h.alpha)*embScores[i]
+			if hybridScore > 0 {
+				scored = append(scored, scoredDoc{idx: i, score: hybridScore})
+			}
 		}
 	}
 
@@ -115,16 +397,90 @@ func (h *HybridSearcher) SearchWithScores(ctx context.Context, query string, lim
 		scored = scored[:limit]
 	}
 
-	results := make(Results, len(scored))
+	results = make(Results, len(scored))
 	for i, s := range scored {
 		results[i] = Result{
 			Summary:   docs[s.idx].Summary,
 			Score:     s.score,
 			ScoreType: ScoreHybrid,
+			Partial:   partial,
+			semantic:  &embScores[s.idx],
 		}
 	}
 
-	return results, nil
+	return results, partial, nil
+}
+
+// filterQueryDocs returns the documents matching parsed, with their
+// normalized forms.
+func filterQueryDocs(parsed *semantic.Query, docs []index.SearchDoc, normalized []semantic.Document) ([]index.SearchDoc, []semantic.Document) {
+	keptDocs := make([]index.SearchDoc, 0, len(docs))
+	kept := make([]semantic.Document, 0, len(normalized))
+	for i, doc := range normalized {
+		if parsed.Match(doc) {
+			keptDocs = append(keptDocs, docs[i])
+			kept = append(kept, doc)
+		}
+	}
+	return keptDocs, kept
+}
+
+// filterOnlyResults returns the first limit of docs, which matched a query
+// without words to rank by, in ID order.
+func filterOnlyResults(docs []index.SearchDoc, limit int) Results {
+	sorted := slices.SortedFunc(slices.Values(docs), func(a, b index.SearchDoc) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	results := make(Results, 0, min(limit, len(sorted)))
+	for _, doc := range sorted[:min(limit, len(sorted))] {
+		results = append(results, Result{Summary: doc.Summary, ScoreType: ScoreHybrid})
+	}
+	return results
+}
+
+// scoreChunk returns the embedding and BM25 scores of docs.
+func (h *HybridSearcher) scoreChunk(ctx context.Context, query string, docs []semantic.Document) (emb, bm25 []float64, err error) {
+	emb, err = h.embeddingScores(ctx, query, docs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bm25 = make([]float64, len(docs))
+	for i, doc := range docs {
+		bm25[i], err = h.bm25Strategy.Score(ctx, query, doc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return emb, bm25, nil
+}
+
+// fuseRRF ranks documents with a positive score in each list and sums their
+// weighted reciprocal ranks. Documents absent from both lists, or present only
+// in a list with zero weight, are dropped.
+func (h *HybridSearcher) fuseRRF(bm25Scores, embScores []float64, docs []index.SearchDoc) []scoredDoc {
+	fused := make([]float64, len(docs))
+	addRanks := func(scores []float64, weight float64) {
+		ranked := make([]scoredDoc, 0, len(scores))
+		for i, score := range scores {
+			if score > 0 {
+				ranked = append(ranked, scoredDoc{idx: i, score: score})
+			}
+		}
+		sortScoredDocs(ranked, docs)
+		for rank, s := range ranked {
+			fused[s.idx] += weight / (h.rrfK + float64(rank+1))
+		}
+	}
+	addRanks(bm25Scores, h.alpha)
+	addRanks(embScores, 1-h.alpha)
+
+	scored := make([]scoredDoc, 0, len(docs))
+	for i, score := range fused {
+		if score > 0 {
+			scored = append(scored, scoredDoc{idx: i, score: score})
+		}
+	}
+	return scored
 }
 
 // GetScoreType returns ScoreHybrid.
@@ -0,0 +1,232 @@
+package discovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// MarkDuplicates declares toolIDs to be the same logical tool, such as the
+// members of a DuplicateGroup reported by FindDuplicates or one tool served
+// under two namespaces. Search collapses the members it ranks into the
+// best-ranked one, listing the others in Result.AlternateIDs, so copies do
+// not crowd the top results. Marking a tool that is already in a group
+// merges the groups. The tools need not be registered yet.
+func (d *Discovery) MarkDuplicates(toolIDs ...string) error {
+	for _, id := range toolIDs {
+		if _, err := index.ParseToolID(id); err != nil {
+			return err
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group := make(map[string]struct{})
+	for _, id := range toolIDs {
+		group[id] = struct{}{}
+		if root, ok := d.dupMarks[id]; ok {
+			for member, r := range d.dupMarks {
+				if r == root {
+					group[member] = struct{}{}
+				}
+			}
+		}
+	}
+	if len(group) < 2 {
+		return nil
+	}
+	if d.dupMarks == nil {
+		d.dupMarks = make(map[string]string)
+	}
+	root := ""
+	for id := range group {
+		if root == "" || id < root {
+			root = id
+		}
+	}
+	for id := range group {
+		d.dupMarks[id] = root
+	}
+	d.rebuildDuplicatesLocked()
+	return nil
+}
+
+// UnmarkDuplicate removes toolID from the group MarkDuplicates placed it in.
+func (d *Discovery) UnmarkDuplicate(toolID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.unmarkDuplicateLocked(toolID)
+}
+
+// Duplicates returns the other tools marked as the same logical tool as
+// toolID, by MarkDuplicates or by an alias that is another tool's ID,
+// sorted.
+func (d *Discovery) Duplicates(toolID string) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	key, ok := d.dupKeys[toolID]
+	if !ok {
+		return nil
+	}
+	var out []string
+	for id, k := range d.dupKeys {
+		if k == key && id != toolID {
+			out = append(out, id)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (d *Discovery) unmarkDuplicateLocked(toolID string) {
+	root, ok := d.dupMarks[toolID]
+	if !ok {
+		return
+	}
+	delete(d.dupMarks, toolID)
+	var rest []string
+	for id, r := range d.dupMarks {
+		if r == root {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) == 1 {
+		delete(d.dupMarks, rest[0])
+	} else if len(rest) > 1 && root == toolID {
+		newRoot := slices.Min(rest)
+		for _, id := range rest {
+			d.dupMarks[id] = newRoot
+		}
+	}
+	d.rebuildDuplicatesLocked()
+}
+
+// rebuildDuplicatesLocked recomputes the duplicate groups from the marks
+// and from aliases that name another tool's ID. Must be called with d.mu
+// held.
+func (d *Discovery) rebuildDuplicatesLocked() {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(id string) string {
+		p, ok := parent[id]
+		if !ok {
+			parent[id] = id
+			return id
+		}
+		if p != id {
+			p = find(p)
+			parent[id] = p
+		}
+		return p
+	}
+	union := func(a, b string) {
+		ra, rb := find(a), find(b)
+		if ra < rb {
+			parent[rb] = ra
+		} else if rb < ra {
+			parent[ra] = rb
+		}
+	}
+	for id, root := range d.dupMarks {
+		union(id, root)
+	}
+	for id, a := range d.aliases {
+		for _, alias := range a.names {
+			if alias == id || !strings.Contains(alias, index.ToolIDSeparator) {
+				continue
+			}
+			if _, err := index.ParseToolID(alias); err == nil {
+				union(id, alias)
+			}
+		}
+	}
+
+	keys := make(map[string]string, len(parent))
+	groups := make(map[string][]string)
+	for id := range parent {
+		root := find(id)
+		keys[id] = root
+		groups[root] = append(groups[root], id)
+	}
+	roots := make([]string, 0, len(groups))
+	for root, members := range groups {
+		slices.Sort(members)
+		roots = append(roots, root)
+	}
+	slices.Sort(roots)
+	h := sha256.New()
+	for _, root := range roots {
+		h.Write([]byte(strings.Join(groups[root], "\x00") + "\x01"))
+	}
+	d.dupKeys = keys
+	d.dupSlack = len(keys) - len(groups)
+	d.dupFingerprint = ""
+	if len(keys) > 0 {
+		d.dupFingerprint = hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// duplicateSlack returns how many extra results to retrieve so that
+// collapsing duplicates can still fill limit: the number of results that
+// may collapse away, capped at limit so a catalog with many duplicates at
+// most doubles the retrieval window. A limit of zero or less retrieves
+// everything and needs none.
+func (d *Discovery) duplicateSlack(limit int) int {
+	if !d.dedup || limit <= 0 {
+		return 0
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return min(d.dupSlack, limit)
+}
+
+// collapseDuplicates keeps the best-ranked result of each duplicate group,
+// moving the IDs of the other members it ranked into AlternateIDs. results
+// is not modified.
+func (d *Discovery) collapseDuplicates(results Results) Results {
+	if !d.dedup {
+		return results
+	}
+	d.mu.RLock()
+	keys := d.dupKeys
+	d.mu.RUnlock()
+	if len(keys) == 0 {
+		return results
+	}
+
+	out := make(Results, 0, len(results))
+	first := make(map[string]int)
+	for _, r := range results {
+		key, ok := keys[r.Summary.ID]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+		if i, seen := first[key]; seen {
+			out[i].AlternateIDs = append(out[i].AlternateIDs, r.Summary.ID)
+			out[i].AlternateIDs = append(out[i].AlternateIDs, r.AlternateIDs...)
+			continue
+		}
+		first[key] = len(out)
+		r.AlternateIDs = slices.Clone(r.AlternateIDs)
+		out = append(out, r)
+	}
+	for _, i := range first {
+		slices.Sort(out[i].AlternateIDs)
+		out[i].AlternateIDs = slices.Compact(out[i].AlternateIDs)
+	}
+	return out
+}
+
+// duplicatesFingerprint identifies the current duplicate groups, so cache
+// keys change with them.
+func (d *Discovery) duplicatesFingerprint() string {
+	if !d.dedup {
+		return ""
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.dupFingerprint
+}
@@ -3,7 +3,15 @@ package discovery
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+	"github.com/jonwraymond/tooldiscovery/errcode"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/provider"
 	"github.com/jonwraymond/tooldiscovery/search"
@@ -15,7 +23,11 @@ import (
 
 // Error values for discovery operations.
 var (
-	ErrNotFound = errors.New("tool not found")
+	ErrNotFound = errcode.New(errcode.ToolNotFound, "tool not found")
+
+	// ErrInvalidOptions is returned by New for options that cannot take
+	// effect together.
+	ErrInvalidOptions = errcode.New(errcode.InvalidArgument, "discovery: invalid options")
 )
 
 // Options configures a Discovery instance.
@@ -25,6 +37,8 @@ type Options struct {
 
 	// Searcher is the search implementation. If nil, uses BM25Searcher.
 	// This is ignored if Embedder is provided (uses HybridSearcher instead).
+	// It ranks results only in the index New creates; a supplied Index ranks
+	// with its own searcher.
 	Searcher index.Searcher
 
 	// DocStore is the documentation store. If nil, creates a new InMemoryStore.
@@ -42,55 +56,240 @@ type Options struct {
 	// Default: 0.5 (equal weighting). Only used when Embedder is provided.
 	HybridAlpha float64
 
+	// VectorIndex caches document embeddings for hybrid search so queries
+	// embed only the query and new or changed documents. Setting it enables
+	// hybrid search even when Embedder is nil. When Index implements
+	// index.ChangeNotifier, vectors of removed tools are dropped.
+	VectorIndex *semantic.VectorIndex
+
+	// HybridFusion selects how hybrid search combines BM25 and embedding
+	// scores; FusionRRF merges them by reciprocal rank.
+	// Default: FusionWeighted. Only used when hybrid search is enabled.
+	HybridFusion FusionMode
+
+	// EmbeddingMetric compares embeddings in hybrid search.
+	// Default: semantic.MetricCosine. Only used when Embedder is provided.
+	EmbeddingMetric semantic.Metric
+
+	// EmbedderRoutes routes namespaces or tags to dedicated embedders, falling
+	// back to Embedder. Only used when Embedder is provided.
+	EmbedderRoutes []EmbedderRoute
+
 	// BM25Config configures the BM25 searcher.
-	// Only used when Searcher is nil and Embedder is nil.
+	// Only used when Index, Searcher, and Embedder are nil, except Synonyms
+	// and BooleanQueries, which also apply to hybrid search. New returns
+	// ErrInvalidOptions when either is set but none of that applies.
 	BM25Config search.BM25Config
 
 	// MaxExamples is the default maximum number of examples to return.
 	// Default: 10
 	MaxExamples int
+
+	// MaxArgsDepth and MaxArgsKeys override the example Args caps.
+	// See tooldoc.StoreOptions.
+	MaxArgsDepth int
+	MaxArgsKeys  int
+
+	// MaxTools, MaxToolsPerNamespace, MaxTagsPerTool, and MaxDescriptionLen
+	// cap the catalog of the created index; see index.IndexOptions. Ignored
+	// when Index is set, which is configured directly. 0 = unlimited.
+	MaxTools             int
+	MaxToolsPerNamespace int
+	MaxTagsPerTool       int
+	MaxDescriptionLen    int
+
+	// MaxDocBytes caps each tool's documentation.
+	// See tooldoc.StoreOptions. 0 = unlimited.
+	MaxDocBytes int
+
+	// ChangeJournalSize is the number of index changes, and separately of
+	// documentation changes, retained for Changes, ChangesSince, and AsOf
+	// views. The journal is only kept when Index implements
+	// index.ChangeNotifier.
+	// Default: DefaultChangeJournalSize
+	ChangeJournalSize int
+
+	// DisableSearchPushdown ranks in process even when Index implements
+	// index.SearchPushdown. Pushdown is also skipped with
+	// BM25Config.BooleanQueries, which stores do not parse.
+	DisableSearchPushdown bool
+
+	// SearchDeadline is a soft time budget for hybrid scoring in Search and
+	// SearchFor. When it expires mid-scoring, the best results among the
+	// documents scored so far are returned with Result.Partial set instead
+	// of an error; partial results are not cached. Zero means no deadline.
+	// BM25-only and pushed-down searches ignore it.
+	SearchDeadline time.Duration
+
+	// DisableExactMatchPinning ranks purely by score. By default, when a
+	// query's text is exactly a tool ID or tool name, Search returns the
+	// matching tools first regardless of their scores, since agents often
+	// search for the precise name they were given. Matching is
+	// case-sensitive and metadata filters still apply.
+	DisableExactMatchPinning bool
+
+	// DisableDeduplication returns every ranked tool, even those marked as
+	// the same logical tool by MarkDuplicates or aliases. By default they
+	// are collapsed into the best-ranked one, with Result.AlternateIDs
+	// listing the others.
+	DisableDeduplication bool
+
+	// ResultCache caches Search and SearchFor results, keyed by index
+	// fingerprint, ranking model, principal, query, and limit, so replicas
+	// sharing a cache answer repeated queries without re-ranking. Results
+	// are only cached when the index implements index.Fingerprinter.
+	// Optional.
+	ResultCache cache.Cache
+
+	// ResultCacheTTL expires cached results, bounding how stale
+	// time-dependent state such as maintenance windows and rollouts can be.
+	// Default: DefaultResultCacheTTL.
+	ResultCacheTTL time.Duration
+
+	// QueryCache caches Search and SearchFor results in process, keyed by
+	// principal, query, metadata filters, limit, and index version, and is
+	// invalidated on every index change event. It is consulted before
+	// ResultCache. Ignored unless Index implements index.ChangeNotifier or
+	// index.Versioner. Optional; see NewQueryCache.
+	QueryCache *QueryCache
+
+	// QueryRewriter transforms queries before Search, SearchFor, and
+	// SearchPage score them; ChainQueryRewriters combines several. Results
+	// report the rewritten query in Result.RewrittenQuery. Default: nil (no
+	// rewriting).
+	QueryRewriter QueryRewriter
+
+	// RankingModel re-scores the top RerankTopK results of every Search with
+	// a learning-to-rank model (see ParseRankingModel). Default: nil (no
+	// re-ranking). SetRankingModel replaces it at runtime.
+	RankingModel RankingModel
+
+	// RerankTopK is the number of results a RankingModel re-scores.
+	// Default: DefaultRerankTopK.
+	RerankTopK int
+
+	// Reranker reorders the top RerankerTopK results of every Search with a
+	// cross-encoder or LLM, after any RankingModel. WithRerank overrides
+	// the count per SearchWithOptions call. Default: nil (no reranking).
+	Reranker Reranker
+
+	// RerankerTopK is the number of results a Reranker reorders.
+	// Default: DefaultRerankerTopK.
+	RerankerTopK int
+
+	// NamespaceBoosts sets the namespace_boost ranking feature per
+	// namespace. Namespaces not listed have a boost of zero.
+	NamespaceBoosts map[string]float64
+
+	// FeedbackStore persists relevance feedback recorded with RecordFeedback
+	// and RecordImpressions. Default: an InMemoryFeedbackStore.
+	FeedbackStore FeedbackStore
+
+	// TracerProvider traces Search, SearchFor, DescribeTool,
+	// DescribeToolProfile, and RegisterTool. Default: no tracing.
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider records the discovery.search.duration,
+	// discovery.search.results, discovery.search.requests,
+	// discovery.search.zero_results, and discovery.registration.rejected
+	// metrics. Default: no metrics.
+	MeterProvider metric.MeterProvider
+
+	// Now returns the current time for change journal and feedback
+	// timestamps and, when Index is nil, maintenance window checks in the
+	// created index.
+	// Default: time.Now.
+	Now func() time.Time
 }
 
 // Discovery is the unified facade for tool discovery operations.
 // It combines index, search, and documentation functionality.
 type Discovery struct {
+	opts       Options // as given to New, for AsOf views
 	idx        index.Index
 	searcher   index.Searcher
 	compositeS CompositeSearcher // nil if using standard searcher
 	docs       *tooldoc.InMemoryStore
 	providers  provider.Store
 	scoreType  ScoreType
-	searchDocs func() []index.SearchDoc
+	journal    *changeJournal
+	vectors    *semantic.VectorIndex
+	synonyms   search.Synonyms // hybrid query expansion
+	embedders  []namedEmbedder // checked by SelfTest
+	pushdown   index.SearchPushdown
+	pinExact   bool
+	dedup      bool
+	deadline   time.Duration     // soft hybrid scoring budget
+	queryEmbed semantic.Embedder // embeds pushed-down hybrid queries
+	results    cache.Cache
+	resultsTTL time.Duration
+	queries    *QueryCache
+	feedback   FeedbackStore
+	rewriter   QueryRewriter
+	now        func() time.Time
+	telemetry  *telemetry
+
+	rerankTopK int
+	reranker   Reranker
+
+	rerankerTopK    int
+	namespaceBoosts map[string]float64
+
+	mu                   sync.RWMutex // guards aliases, duplicates, ranker, and interceptors
+	searchInterceptors   []SearchInterceptor
+	describeInterceptors []DescribeInterceptor
+	aliases              map[string]toolAliases
+	aliasSynonyms        search.Synonyms
+	dupMarks             map[string]string // MarkDuplicates groups: tool ID to group root
+	dupKeys              map[string]string // marks and alias links: tool ID to group key
+	dupSlack             int
+	dupFingerprint       string
+	ranker               RankingModel
+	rankerGeneration     uint64 // incremented by SetRankingModel
 }
 
 // New creates a new Discovery instance with the given options.
 func New(opts Options) (*Discovery, error) {
-	d := &Discovery{}
+	d := &Discovery{opts: opts}
 
-	// Setup index
-	if opts.Index != nil {
-		d.idx = opts.Index
-	} else {
-		d.idx = index.NewInMemoryIndex()
+	telemetry, err := newTelemetry(opts.TracerProvider, opts.MeterProvider)
+	if err != nil {
+		return nil, err
 	}
+	d.telemetry = telemetry
 
 	// Setup searcher
-	if opts.Embedder != nil {
+	if opts.Embedder != nil || opts.VectorIndex != nil {
 		// Use hybrid search
 		alpha := opts.HybridAlpha
 		if alpha == 0 {
 			alpha = 0.5 // Default to equal weighting
 		}
 		hybrid, err := NewHybridSearcher(HybridOptions{
-			Embedder: opts.Embedder,
-			Alpha:    alpha,
+			Embedder:       opts.Embedder,
+			VectorIndex:    opts.VectorIndex,
+			Alpha:          alpha,
+			Fusion:         opts.HybridFusion,
+			Metric:         opts.EmbeddingMetric,
+			EmbedderRoutes: opts.EmbedderRoutes,
+			BooleanQueries: opts.BM25Config.BooleanQueries,
 		})
 		if err != nil {
 			return nil, err
 		}
 		d.searcher = hybrid
 		d.compositeS = hybrid
+		d.embedders = selfTestEmbedders(opts.Embedder, opts.EmbedderRoutes)
+		d.queryEmbed = opts.Embedder
+		if opts.VectorIndex != nil {
+			d.queryEmbed = opts.VectorIndex.Embedder()
+		}
 		d.scoreType = ScoreHybrid
+		d.synonyms = opts.BM25Config.Synonyms.Normalized()
+	} else if len(opts.BM25Config.Synonyms) > 0 && (opts.Index != nil || opts.Searcher != nil) {
+		return nil, fmt.Errorf("%w: BM25Config.Synonyms need the default searcher or hybrid search", ErrInvalidOptions)
+	} else if opts.BM25Config.BooleanQueries && (opts.Index != nil || opts.Searcher != nil) {
+		return nil, fmt.Errorf("%w: BM25Config.BooleanQueries need the default searcher or hybrid search", ErrInvalidOptions)
 	} else if opts.Searcher != nil {
 		d.searcher = opts.Searcher
 		d.scoreType = ScoreBM25
@@ -99,29 +298,45 @@ func New(opts Options) (*Discovery, error) {
 		d.scoreType = ScoreBM25
 	}
 
+	// Setup index. A created index ranks with the configured searcher;
+	// hybrid search ranks outside the index.
+	if opts.Index != nil {
+		d.idx = opts.Index
+	} else {
+		indexOpts := index.IndexOptions{
+			Now:                  opts.Now,
+			MaxTools:             opts.MaxTools,
+			MaxToolsPerNamespace: opts.MaxToolsPerNamespace,
+			MaxTagsPerTool:       opts.MaxTagsPerTool,
+			MaxDescriptionLen:    opts.MaxDescriptionLen,
+		}
+		if d.compositeS == nil {
+			indexOpts.Searcher = d.searcher
+		}
+		d.idx = index.NewInMemoryIndex(indexOpts)
+	}
+	if p, ok := d.idx.(index.SearchPushdown); ok && !opts.DisableSearchPushdown && !opts.BM25Config.BooleanQueries {
+		d.pushdown = p
+	}
+	d.pinExact = !opts.DisableExactMatchPinning
+	d.dedup = !opts.DisableDeduplication
+	d.deadline = opts.SearchDeadline
+
 	// Setup doc store
 	maxExamples := opts.MaxExamples
 	if maxExamples == 0 {
 		maxExamples = 10
 	}
 
-	if inMemIdx, ok := d.idx.(*index.InMemoryIndex); ok {
-		d.docs = tooldoc.NewInMemoryStore(tooldoc.StoreOptions{
-			Index:       inMemIdx,
-			MaxExamples: maxExamples,
-		})
-	} else {
-		// Create store without index linkage - will need resolver
-		d.docs = tooldoc.NewInMemoryStore(tooldoc.StoreOptions{
-			MaxExamples: maxExamples,
-			ToolResolver: func(id string) (*model.Tool, error) {
-				tool, _, err := d.idx.GetTool(id)
-				if err != nil {
-					return nil, err
-				}
-				return &tool, nil
-			},
-		})
+	d.docs = tooldoc.NewInMemoryStore(tooldoc.StoreOptions{
+		Index:        d.idx,
+		MaxExamples:  maxExamples,
+		MaxArgsDepth: opts.MaxArgsDepth,
+		MaxArgsKeys:  opts.MaxArgsKeys,
+		MaxDocBytes:  opts.MaxDocBytes,
+	})
+	if err := d.telemetry.observeRejections(d.idx, d.docs); err != nil {
+		return nil, err
 	}
 
 	// Setup provider store
@@ -131,27 +346,69 @@ func New(opts Options) (*Discovery, error) {
 		d.providers = provider.NewInMemoryStore()
 	}
 
-	// Setup search doc accessor
-	if inMemIdx, ok := d.idx.(*index.InMemoryIndex); ok {
-		d.searchDocs = func() []index.SearchDoc {
-			docs, _ := inMemIdx.Search("", 1000000) // Get all
-			searchDocs := make([]index.SearchDoc, len(docs))
-			for i, s := range docs {
-				searchDocs[i] = index.SearchDoc{
-					ID:      s.ID,
-					Summary: s,
-				}
-			}
-			return searchDocs
+	// Setup result cache
+	d.results = opts.ResultCache
+	d.resultsTTL = opts.ResultCacheTTL
+	if d.resultsTTL == 0 {
+		d.resultsTTL = DefaultResultCacheTTL
+	}
+
+	// Setup feedback store
+	d.feedback = opts.FeedbackStore
+	if d.feedback == nil {
+		d.feedback = NewInMemoryFeedbackStore()
+	}
+	d.now = opts.Now
+	if d.now == nil {
+		d.now = time.Now
+	}
+
+	// Setup re-ranking
+	d.ranker = opts.RankingModel
+	d.rerankTopK = opts.RerankTopK
+	if d.rerankTopK <= 0 {
+		d.rerankTopK = DefaultRerankTopK
+	}
+	d.reranker = opts.Reranker
+	d.rerankerTopK = opts.RerankerTopK
+	if d.rerankerTopK == 0 {
+		d.rerankerTopK = DefaultRerankerTopK
+	}
+	d.namespaceBoosts = opts.NamespaceBoosts
+	d.rewriter = opts.QueryRewriter
+
+	// Setup change journal
+	if notifier, ok := d.idx.(index.ChangeNotifier); ok {
+		d.journal = newChangeJournal(d.idx, notifier, d.docs, opts.ChangeJournalSize, opts.Now)
+	}
+	if queries := opts.QueryCache; queries != nil {
+		_, notifies := d.idx.(index.ChangeNotifier)
+		_, versioned := d.idx.(index.Versioner)
+		if notifies || versioned {
+			d.queries = queries
+			index.OnChange(d.idx, func(index.ChangeEvent) { queries.Invalidate() })
 		}
 	}
+	if opts.VectorIndex != nil {
+		vectors := opts.VectorIndex
+		d.vectors = vectors
+		index.OnChange(d.idx, func(event index.ChangeEvent) {
+			if event.Type == index.ChangeToolRemoved {
+				_ = vectors.Remove(event.ToolID)
+			}
+		})
+	}
 
 	return d, nil
 }
 
 // RegisterTool registers a tool with its backend and optional documentation.
 // If doc is nil, the tool is registered without additional documentation.
-func (d *Discovery) RegisterTool(tool model.Tool, backend model.ToolBackend, doc *tooldoc.DocEntry) error {
+func (d *Discovery) RegisterTool(tool model.Tool, backend model.ToolBackend, doc *tooldoc.DocEntry) (err error) {
+	_, span := d.telemetry.tracer.Start(context.Background(), "discovery.RegisterTool",
+		trace.WithAttributes(AttrToolID.String(tool.ToolID())))
+	defer func() { endSpan(span, err) }()
+
 	if err := d.idx.RegisterTool(tool, backend); err != nil {
 		return err
 	}
@@ -178,18 +435,193 @@ func (d *Discovery) RegisterExamples(toolID string, examples []tooldoc.ToolExamp
 	return d.docs.RegisterExamples(toolID, examples)
 }
 
+// UnregisterBackend removes a backend from a tool. When the last backend is
+// removed the tool leaves the index, and its documentation and cached
+// embedding are removed with it.
+func (d *Discovery) UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error {
+	if err := d.idx.UnregisterBackend(toolID, kind, backendID); err != nil {
+		return err
+	}
+	if _, _, err := d.idx.GetTool(toolID); !errors.Is(err, index.ErrNotFound) {
+		return nil
+	}
+	d.removeAliases(toolID)
+	if err := d.docs.RemoveDoc(toolID); err != nil && !errors.Is(err, tooldoc.ErrNotFound) {
+		return err
+	}
+	if d.vectors != nil {
+		return d.vectors.Remove(toolID)
+	}
+	return nil
+}
+
+// RemoveDoc deletes the documentation registered for a tool. The tool stays
+// in the index. Returns tooldoc.ErrNotFound if no documentation exists.
+func (d *Discovery) RemoveDoc(toolID string) error {
+	return d.docs.RemoveDoc(toolID)
+}
+
+// PatchDoc applies a partial documentation update for a tool.
+// See tooldoc.InMemoryStore.PatchDoc.
+func (d *Discovery) PatchDoc(toolID string, patch tooldoc.DocPatch) error {
+	return d.docs.PatchDoc(toolID, patch)
+}
+
+// RemoveExamples deletes examples by ID from a tool's documentation.
+// Returns tooldoc.ErrNotFound if no documentation exists.
+func (d *Discovery) RemoveExamples(toolID string, exampleIDs []string) error {
+	return d.docs.RemoveExamples(toolID, exampleIDs)
+}
+
+// UpdateExample replaces one example in a tool's documentation, keeping its
+// ID. See tooldoc.InMemoryStore.UpdateExample.
+func (d *Discovery) UpdateExample(toolID, exampleID string, example tooldoc.ToolExample) error {
+	return d.docs.UpdateExample(toolID, exampleID, example)
+}
+
+// DeleteExample removes one example from a tool's documentation.
+// Returns tooldoc.ErrExampleNotFound if the example does not exist.
+func (d *Discovery) DeleteExample(toolID, exampleID string) error {
+	return d.docs.DeleteExample(toolID, exampleID)
+}
+
 // Search performs a search using the configured strategy.
-// Returns results ordered by relevance score.
+// Returns results ordered by relevance score. Tools under a partial rollout
+// are omitted; use SearchFor to search as a principal.
 func (d *Discovery) Search(ctx context.Context, query string, limit int) (Results, error) {
+	return d.SearchForWithOptions(ctx, "", query, limit)
+}
+
+// SearchFor performs Search as principal (an agent, session, or tenant
+// identifier), including tools whose rollout makes them visible to principal.
+func (d *Discovery) SearchFor(ctx context.Context, principal, query string, limit int) (Results, error) {
+	return d.SearchForWithOptions(ctx, principal, query, limit)
+}
+
+// SearchWithOptions performs Search configured by opts, such as WithRerank.
+func (d *Discovery) SearchWithOptions(ctx context.Context, query string, limit int, opts ...SearchOption) (Results, error) {
+	return d.SearchForWithOptions(ctx, "", query, limit, opts...)
+}
+
+// SearchForWithOptions performs SearchFor configured by opts.
+func (d *Discovery) SearchForWithOptions(ctx context.Context, principal, query string, limit int, opts ...SearchOption) (Results, error) {
+	start := time.Now()
+	ctx, span := d.telemetry.tracer.Start(ctx, "discovery.Search",
+		trace.WithAttributes(AttrLimit.Int(limit), AttrScoreType.String(string(d.scoreType))))
+	rerankK := d.rerankK(opts)
+	var cacheHit bool
+	search := d.wrapSearch(func(ctx context.Context, principal, query string, limit int) (Results, error) {
+		rewritten, err := d.rewriteQuery(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		var results Results
+		results, cacheHit, err = d.searchFor(ctx, principal, rewritten, limit, rerankK)
+		return markRewritten(results, query, rewritten), err
+	})
+	results, err := search(ctx, principal, query, limit)
+	d.telemetry.recordSearch(ctx, span, start, d.scoreType, results, cacheHit, err)
+	return results, err
+}
+
+// searchFor implements SearchFor, reporting whether a cache answered.
+// rerankK is the number of results the Reranker reorders.
+func (d *Discovery) searchFor(ctx context.Context, principal, query string, limit, rerankK int) (Results, bool, error) {
+	var queryKey string
+	var generation uint64
+	if d.queries != nil {
+		queryKey = d.queryCacheKey(principal, query, limit, rerankK)
+		results, gen, ok := d.queries.get(queryKey)
+		if ok {
+			return results, true, nil
+		}
+		generation = gen
+	}
+	key, cacheable := d.resultCacheKey(principal, query, limit, rerankK)
+	if cacheable {
+		if results, ok := d.cachedResults(ctx, key); ok {
+			if d.queries != nil {
+				d.queries.put(queryKey, generation, results)
+			}
+			return results, true, nil
+		}
+	}
+	var deadline time.Time
+	if d.deadline > 0 {
+		deadline = time.Now().Add(d.deadline)
+	}
+	slack := d.duplicateSlack(limit)
+	results, partial, err := d.searchBefore(ctx, principal, query, max(d.retrievalLimit(limit)+slack, rerankK), deadline)
+	if err == nil {
+		results, err = d.rerank(ctx, query, results, max(limit+slack, rerankK))
+	}
+	if err == nil {
+		results, err = d.applyReranker(ctx, query, results, rerankK)
+	}
+	if err == nil {
+		results = d.collapseDuplicates(results)
+	}
+	if err == nil && limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	if err == nil && d.pinExact {
+		results = d.collapseDuplicates(d.pinExactMatches(principal, query, results, limit))
+	}
+	if partial {
+		return results, false, err
+	}
+	if err == nil && cacheable {
+		d.cacheResults(ctx, key, results)
+	}
+	if err == nil && d.queries != nil {
+		d.queries.put(queryKey, generation, results)
+	}
+	return results, false, err
+}
+
+func (d *Discovery) search(ctx context.Context, principal, query string, limit int) (Results, error) {
+	results, _, err := d.searchBefore(ctx, principal, query, limit, time.Time{})
+	return results, err
+}
+
+// searchBefore ranks query, stopping hybrid scoring at a non-zero soft
+// deadline and reporting whether the results are partial.
+func (d *Discovery) searchBefore(ctx context.Context, principal, query string, limit int, deadline time.Time) (Results, bool, error) {
+	if d.pushdown != nil {
+		results, fallbackCtx, err := d.pushdownSearch(ctx, principal, query, limit)
+		if !errors.Is(err, index.ErrPushdownUnsupported) {
+			return results, false, err
+		}
+		ctx = fallbackCtx
+	}
 	if d.compositeS != nil {
-		docs := d.getSearchDocs()
-		return d.compositeS.SearchWithScores(ctx, query, limit, docs)
+		query, filters := index.ParseMetadataFilters(query)
+		query, category := index.ParseCategoryFilter(query)
+		query, hints := index.ParseHintFilters(query)
+		query = d.expandQuery(query, true)
+		docs := d.getSearchDocs(principal)
+		if len(filters) > 0 || category != "" || len(hints) > 0 {
+			filtered := docs[:0]
+			for _, doc := range docs {
+				if index.MatchesMetadata(doc.Summary.Metadata, filters) &&
+					(category == "" || index.InCategory(doc.Summary.Category, category)) &&
+					index.MatchesHints(doc.Summary.Hints, hints) {
+					filtered = append(filtered, doc)
+				}
+			}
+			docs = filtered
+		}
+		if ds, ok := d.compositeS.(deadlineSearcher); ok && !deadline.IsZero() {
+			return ds.SearchWithDeadline(ctx, query, limit, docs, deadline)
+		}
+		results, err := d.compositeS.SearchWithScores(ctx, query, limit, docs)
+		return results, false, err
 	}
 
 	// Fall back to standard search without scores
-	summaries, err := d.idx.Search(query, limit)
+	summaries, err := d.indexSearch(principal, d.expandQuery(query, false), limit)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	results := make(Results, len(summaries))
@@ -201,12 +633,39 @@ func (d *Discovery) Search(ctx context.Context, query string, limit int) (Result
 		}
 	}
 
-	return results, nil
+	return results, false, nil
 }
 
 // SearchPage performs paginated search.
 func (d *Discovery) SearchPage(ctx context.Context, query string, limit int, cursor string) (Results, string, error) {
-	summaries, nextCursor, err := d.idx.SearchPage(query, limit, cursor)
+	return d.SearchPageFor(ctx, "", query, limit, cursor)
+}
+
+// SearchPageFor performs SearchPage as principal.
+func (d *Discovery) SearchPageFor(ctx context.Context, principal, query string, limit int, cursor string) (Results, string, error) {
+	return d.searchPage(ctx, principal, query, limit, func(ctx context.Context, principal, query string, limit int) (Results, string, error) {
+		rewritten, err := d.rewriteQuery(ctx, query)
+		if err != nil {
+			return nil, "", err
+		}
+		return d.searchPageRewritten(principal, query, rewritten, limit, cursor)
+	})
+}
+
+// searchPageRewritten performs SearchPageFor for query, already rewritten
+// to rewritten.
+func (d *Discovery) searchPageRewritten(principal, query, rewritten string, limit int, cursor string) (Results, string, error) {
+	var (
+		summaries  []index.Summary
+		nextCursor string
+		err        error
+	)
+	expanded := d.expandQuery(rewritten, false)
+	if ps, ok := d.idx.(principalSearcher); ok {
+		summaries, nextCursor, err = ps.SearchPageFor(principal, expanded, limit, cursor)
+	} else {
+		summaries, nextCursor, err = d.idx.SearchPage(expanded, limit, cursor)
+	}
 	if err != nil {
 		return nil, "", err
 	}
@@ -220,7 +679,7 @@ func (d *Discovery) SearchPage(ctx context.Context, query string, limit int, cur
 		}
 	}
 
-	return results, nextCursor, nil
+	return markRewritten(results, query, rewritten), nextCursor, nil
 }
 
 // GetTool retrieves a tool by its canonical ID.
@@ -234,8 +693,48 @@ func (d *Discovery) GetAllBackends(id string) ([]model.ToolBackend, error) {
 }
 
 // DescribeTool returns documentation at the specified detail level.
-func (d *Discovery) DescribeTool(id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
-	return d.docs.DescribeTool(id, level)
+func (d *Discovery) DescribeTool(id string, level tooldoc.DetailLevel) (doc tooldoc.ToolDoc, err error) {
+	ctx, span := d.startDescribe(id, level)
+	defer func() { endSpan(span, err) }()
+	describe := d.wrapDescribe(func(_ context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+		return d.docs.DescribeTool(id, level)
+	})
+	return describe(ctx, id, level)
+}
+
+// DescribeToolProfile returns documentation like DescribeTool, trimmed with
+// the named tooldoc truncation profile ("compact", "standard", or
+// "verbose").
+func (d *Discovery) DescribeToolProfile(id string, level tooldoc.DetailLevel, profile string) (doc tooldoc.ToolDoc, err error) {
+	ctx, span := d.startDescribe(id, level)
+	span.SetAttributes(AttrProfile.String(profile))
+	defer func() { endSpan(span, err) }()
+	describe := d.wrapDescribe(func(_ context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+		return tooldoc.DescribeWithProfile(d.docs, id, level, profile)
+	})
+	return describe(ctx, id, level)
+}
+
+// DescribeToolBudget returns documentation like DescribeTool, trimmed with
+// tooldoc.ApplyBudget to at most maxBytes of JSON, and a report of what was
+// omitted so callers can fetch it explicitly. As with DescribeToolProfile,
+// the budget is applied inside the describe interceptor chain.
+func (d *Discovery) DescribeToolBudget(id string, level tooldoc.DetailLevel, maxBytes int) (doc tooldoc.ToolDoc, report tooldoc.TrimReport, err error) {
+	ctx, span := d.startDescribe(id, level)
+	defer func() { endSpan(span, err) }()
+	describe := d.wrapDescribe(func(_ context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+		var doc tooldoc.ToolDoc
+		var err error
+		doc, report, err = tooldoc.DescribeWithBudget(d.docs, id, level, maxBytes)
+		return doc, err
+	})
+	doc, err = describe(ctx, id, level)
+	return doc, report, err
+}
+
+func (d *Discovery) startDescribe(id string, level tooldoc.DetailLevel) (context.Context, trace.Span) {
+	return d.telemetry.tracer.Start(context.Background(), "discovery.DescribeTool",
+		trace.WithAttributes(AttrToolID.String(id), AttrDetail.String(string(level))))
 }
 
 // DescribeProvider returns provider metadata by ID.
@@ -246,6 +745,15 @@ func (d *Discovery) DescribeProvider(id string) (adapter.CanonicalProvider, erro
 	return d.providers.DescribeProvider(id)
 }
 
+// FindProvidersByCapability returns the registered providers that meet c.
+// See provider.Capability.
+func (d *Discovery) FindProvidersByCapability(c provider.Capability) ([]adapter.CanonicalProvider, error) {
+	if d.providers == nil {
+		return nil, provider.ErrNotFound
+	}
+	return provider.FindProvidersByCapability(d.providers, c)
+}
+
 // ListProviders returns all registered providers.
 func (d *Discovery) ListProviders() ([]adapter.CanonicalProvider, error) {
 	if d.providers == nil {
@@ -262,6 +770,16 @@ func (d *Discovery) RegisterProvider(id string, p adapter.CanonicalProvider) (st
 	return d.providers.RegisterProvider(id, p)
 }
 
+// RegisterNamespaceOwner sets the default owner for tools in a namespace.
+func (d *Discovery) RegisterNamespaceOwner(namespace string, owner tooldoc.Owner) error {
+	return d.docs.RegisterNamespaceOwner(namespace, owner)
+}
+
+// WhoOwns returns the tool's owner, falling back to its namespace owner.
+func (d *Discovery) WhoOwns(toolID string) (tooldoc.Owner, error) {
+	return d.docs.WhoOwns(toolID)
+}
+
 // ListExamples returns examples for a tool.
 func (d *Discovery) ListExamples(id string, maxExamples int) ([]tooldoc.ToolExample, error) {
 	return d.docs.ListExamples(id, maxExamples)
@@ -274,11 +792,64 @@ func (d *Discovery) ListNamespaces() ([]string, error) {
 
 // OnChange registers a listener for index changes.
 // Returns an unsubscribe function.
+// Indexes without change notification return a no-op unsubscribe.
 func (d *Discovery) OnChange(listener index.ChangeListener) func() {
-	if notifier, ok := d.idx.(index.ChangeNotifier); ok {
-		return notifier.OnChange(listener)
+	return index.OnChange(d.idx, listener)
+}
+
+// OnDocChange registers a listener for documentation changes.
+// Returns an unsubscribe function. See tooldoc.InMemoryStore.OnChange.
+func (d *Discovery) OnDocChange(listener tooldoc.ChangeListener) func() {
+	return d.docs.OnChange(listener)
+}
+
+// Version returns the index version, or 0 when the index does not implement
+// index.Versioner.
+func (d *Discovery) Version() uint64 {
+	return index.Version(d.idx)
+}
+
+// Refresh rebuilds cached search docs when the index implements
+// index.Refresher and returns the resulting version.
+func (d *Discovery) Refresh() uint64 {
+	return index.Refresh(d.idx)
+}
+
+// WarmEmbeddings embeds every tool into Options.VectorIndex, skipping tools
+// whose stored vector is current, so the first hybrid query does not pay for
+// them. It is a no-op without a VectorIndex.
+// It has the scheduler.JobFunc signature so it can run as a periodic job.
+func (d *Discovery) WarmEmbeddings(ctx context.Context) error {
+	hybrid, ok := d.compositeS.(*HybridSearcher)
+	if !ok {
+		return nil
 	}
-	return func() {}
+	return hybrid.Warm(ctx, d.getSearchDocs(""))
+}
+
+// PruneOrphanDocs removes documentation for tools no longer in the index.
+// It has the scheduler.JobFunc signature so it can run as a periodic job.
+func (d *Discovery) PruneOrphanDocs(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.docs.PruneOrphans()
+	return nil
+}
+
+// VerifyExamples checks every documented example against its tool's input
+// schema and returns the failures joined into one error.
+// It has the scheduler.JobFunc signature so it can run as a periodic job.
+func (d *Discovery) VerifyExamples(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	failures := d.docs.VerifyExamples()
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
 }
 
 // Index returns the underlying index for advanced operations.
@@ -296,14 +867,25 @@ func (d *Discovery) ProviderStore() provider.Store {
 	return d.providers
 }
 
-// getSearchDocs returns the current search documents.
-func (d *Discovery) getSearchDocs() []index.SearchDoc {
-	if d.searchDocs != nil {
-		return d.searchDocs()
+// principalSearcher is implemented by indexes that support rollouts, such as
+// index.InMemoryIndex.
+type principalSearcher interface {
+	SearchFor(principal, query string, limit int) ([]index.Summary, error)
+	SearchPageFor(principal, query string, limit int, cursor string) ([]index.Summary, string, error)
+}
+
+// indexSearch searches the index as principal when the index supports
+// rollouts. Other indexes have no rollouts, so a plain search is equivalent.
+func (d *Discovery) indexSearch(principal, query string, limit int) ([]index.Summary, error) {
+	if ps, ok := d.idx.(principalSearcher); ok {
+		return ps.SearchFor(principal, query, limit)
 	}
+	return d.idx.Search(query, limit)
+}
 
-	// Fallback: build from index search
-	summaries, _ := d.idx.Search("", 1000000)
+// getSearchDocs returns the current search documents visible to principal.
+func (d *Discovery) getSearchDocs(principal string) []index.SearchDoc {
+	summaries, _ := d.indexSearch(principal, "", 1000000)
 	docs := make([]index.SearchDoc, len(summaries))
 	for i, s := range summaries {
 		docs[i] = index.SearchDoc{
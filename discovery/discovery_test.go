@@ -2,13 +2,30 @@ package discovery
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
+	"github.com/jonwraymond/tooldiscovery/cache"
 	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/search"
+	"github.com/jonwraymond/tooldiscovery/semantic"
 	"github.com/jonwraymond/tooldiscovery/tooldoc"
 	"github.com/jonwraymond/toolfoundation/adapter"
 	"github.com/jonwraymond/toolfoundation/model"
@@ -499,6 +516,119 @@ func TestDiscovery_SearchPage(t *testing.T) {
 	}
 }
 
+func TestDiscovery_SearchPageWithScores(t *testing.T) {
+	disc, err := New(Options{Embedder: &mockEmbedder{dim: 8}, HybridAlpha: 0.7})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		tool := makeTool("tool"+string(rune('a'+i)), "ns", "Tool for deploys", []string{"deploy"})
+		_ = disc.RegisterTool(tool, makeBackend("server"), nil)
+	}
+
+	ctx := context.Background()
+	want, err := disc.Search(ctx, "deploy tool", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var got Results
+	cursor := ""
+	for {
+		page, next, err := disc.SearchPageWithScores(ctx, "deploy tool", 2, cursor)
+		if err != nil {
+			t.Fatalf("SearchPageWithScores() error = %v", err)
+		}
+		if len(page) > 2 {
+			t.Fatalf("page has %d results, want at most 2", len(page))
+		}
+		got = append(got, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(got) != len(want) {
+		t.Fatalf("paged %d results, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Summary.ID != want[i].Summary.ID || got[i].Score != want[i].Score {
+			t.Errorf("result %d = %s/%v, want %s/%v", i, got[i].Summary.ID, got[i].Score, want[i].Summary.ID, want[i].Score)
+		}
+		if got[i].Score == 0 || got[i].ScoreType != ScoreHybrid {
+			t.Errorf("result %d score = %v (%s), want a hybrid score", i, got[i].Score, got[i].ScoreType)
+		}
+	}
+
+	_, next, _ := disc.SearchPageWithScores(ctx, "deploy tool", 2, "")
+	if _, _, err := disc.SearchPageWithScores(ctx, "other query", 2, next); !errors.Is(err, index.ErrInvalidCursor) {
+		t.Errorf("cursor for another query: err = %v, want ErrInvalidCursor", err)
+	}
+	_ = disc.RegisterTool(makeTool("toolf", "ns", "Tool for deploys", nil), makeBackend("server"), nil)
+	if _, _, err := disc.SearchPageWithScores(ctx, "deploy tool", 2, next); !errors.Is(err, index.ErrInvalidCursor) {
+		t.Errorf("cursor after index change: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestDiscovery_SearchPageWithScores_BM25(t *testing.T) {
+	disc, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		desc := "Tool for deploys"
+		if i%2 == 0 {
+			desc = "Deploy tool for deploys"
+		}
+		_ = disc.RegisterTool(makeTool("tool"+string(rune('a'+i)), "ns", desc, []string{"deploy"}), makeBackend("server"), nil)
+	}
+
+	ctx := context.Background()
+	want, err := disc.Search(ctx, "deploy tool", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var got Results
+	cursor := ""
+	for {
+		page, next, err := disc.SearchPageWithScores(ctx, "deploy tool", 2, cursor)
+		if err != nil {
+			t.Fatalf("SearchPageWithScores() error = %v", err)
+		}
+		got = append(got, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(got) != len(want) {
+		t.Fatalf("paged %d results, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Summary.ID != want[i].Summary.ID {
+			t.Errorf("result %d = %s, want %s", i, got[i].Summary.ID, want[i].Summary.ID)
+		}
+		if got[i].Score <= 0 || got[i].ScoreType != ScoreBM25 {
+			t.Errorf("result %d score = %v (%s), want a BM25 score", i, got[i].Score, got[i].ScoreType)
+		}
+		if i > 0 && got[i].Score > got[i-1].Score {
+			t.Errorf("result %d score %v above result %d score %v", i, got[i].Score, i-1, got[i-1].Score)
+		}
+	}
+
+	// A searcher without scores pages like SearchPage.
+	disc, err = New(Options{Searcher: &mockSearcher{}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = disc.RegisterTool(makeTool("toola", "ns", "Tool for deploys", nil), makeBackend("server"), nil)
+	page, _, err := disc.SearchPageWithScores(ctx, "deploy", 2, "")
+	if err != nil || len(page) != 1 || page[0].Score != 0 {
+		t.Errorf("SearchPageWithScores() = %+v, %v; want one unscored result", page, err)
+	}
+}
+
 func TestDiscovery_GetAllBackends(t *testing.T) {
 	disc, _ := New(Options{})
 
@@ -731,6 +861,24 @@ func TestDiscovery_OnChange_NoNotifier(t *testing.T) {
 	unsubscribe() // Should not panic
 }
 
+func TestDiscovery_VersionAndRefresh(t *testing.T) {
+	disc, _ := New(Options{})
+	if err := disc.RegisterTool(makeTool("a", "ns", "A tool", nil), makeBackend("s1"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if disc.Version() == 0 {
+		t.Fatal("Version = 0, want non-zero for InMemoryIndex")
+	}
+	if got := disc.Refresh(); got != disc.Version() {
+		t.Fatalf("Refresh = %d, want %d", got, disc.Version())
+	}
+
+	plain, _ := New(Options{Index: &nonNotifyingIndex{}})
+	if plain.Version() != 0 || plain.Refresh() != 0 {
+		t.Fatalf("Version/Refresh = %d/%d, want 0 for index without capabilities", plain.Version(), plain.Refresh())
+	}
+}
+
 func TestDiscovery_Search_WithNonInMemoryIndex(t *testing.T) {
 	// Use a custom index that is not InMemoryIndex
 	customIdx := &searchableNonInMemoryIndex{
@@ -1008,3 +1156,2490 @@ func (s *searchableNonInMemoryIndex) ListNamespaces() ([]string, error) { return
 func (s *searchableNonInMemoryIndex) ListNamespacesPage(limit int, cursor string) ([]string, string, error) {
 	return nil, "", nil
 }
+
+func TestDiscovery_MaintenanceJobs(t *testing.T) {
+	disc, _ := New(Options{})
+	ctx := context.Background()
+
+	tool := makeTool("search", "docs", "Search docs", nil)
+	tool.InputSchema = map[string]any{
+		"type":     "object",
+		"required": []any{"query"},
+	}
+	_ = disc.RegisterTool(tool, makeBackend("server"), &tooldoc.DocEntry{
+		Examples: []tooldoc.ToolExample{{ID: "bad", Title: "No query", Args: map[string]any{}}},
+	})
+	_ = disc.RegisterDoc("docs:removed", tooldoc.DocEntry{Summary: "stale"})
+
+	if err := disc.PruneOrphanDocs(ctx); err != nil {
+		t.Fatalf("PruneOrphanDocs failed: %v", err)
+	}
+	if _, err := disc.ListExamples("docs:removed", 1); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Errorf("expected orphaned doc pruned, got %v", err)
+	}
+
+	err := disc.VerifyExamples(ctx)
+	var exErr tooldoc.ExampleError
+	if !errors.As(err, &exErr) || exErr.ExampleID != "bad" {
+		t.Fatalf("expected ExampleError for bad example, got %v", err)
+	}
+}
+
+func TestDiscovery_Changes(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	if err := idx.RegisterTool(makeTool("stale", "ops", "old tool", nil), makeBackend("srv")); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	disc, err := New(Options{Index: idx})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("grep", "fs", "search files", []string{"search"}), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	mark := time.Now()
+	start := idx.Version()
+	time.Sleep(time.Millisecond)
+
+	// MCP fields are immutable per registration, so a description change is a
+	// remove and re-register; the digest reports it as a single update.
+	if err := idx.UnregisterBackend("fs:grep", model.BackendKindMCP, "srv"); err != nil {
+		t.Fatalf("UnregisterBackend() error = %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("grep", "fs", "search file contents", []string{"search"}), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("grep", "fs", "search file contents", []string{"search", "text"}), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("cat", "fs", "print files", nil), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := idx.UnregisterBackend("ops:stale", model.BackendKindMCP, "srv"); err != nil {
+		t.Fatalf("UnregisterBackend() error = %v", err)
+	}
+	// Added and removed within the period; omitted from the digest.
+	if err := disc.RegisterTool(makeTool("tmp", "fs", "temporary", nil), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	if err := idx.UnregisterBackend("fs:tmp", model.BackendKindMCP, "srv"); err != nil {
+		t.Fatalf("UnregisterBackend() error = %v", err)
+	}
+
+	for name, log := range map[string]Changelog{
+		"time":    disc.Changes(mark),
+		"version": disc.ChangesSince(start),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if len(log.Added) != 1 || log.Added[0].ToolID != "fs:cat" || log.Added[0].Kind != ChangeAdded {
+				t.Errorf("Added = %+v, want [fs:cat]", log.Added)
+			}
+			if len(log.Removed) != 1 || log.Removed[0].ToolID != "ops:stale" {
+				t.Errorf("Removed = %+v, want [ops:stale]", log.Removed)
+			}
+			if len(log.Updated) != 1 || log.Updated[0].ToolID != "fs:grep" {
+				t.Fatalf("Updated = %+v, want [fs:grep]", log.Updated)
+			}
+			want := []FieldDiff{
+				{Field: FieldDescription, Before: "search files", After: "search file contents"},
+				{Field: FieldTags, Before: []string{"search"}, After: []string{"search", "text"}},
+			}
+			if got := log.Updated[0].Diffs; !reflect.DeepEqual(got, want) {
+				t.Errorf("Diffs = %+v, want %+v", got, want)
+			}
+			if log.FromVersion <= start || log.ToVersion != idx.Version() {
+				t.Errorf("versions = [%d, %d], want (%d, %d]", log.FromVersion, log.ToVersion, start, idx.Version())
+			}
+			if log.Truncated {
+				t.Error("Truncated = true, want false")
+			}
+		})
+	}
+
+	if log := disc.ChangesSince(idx.Version()); !log.Empty() {
+		t.Errorf("ChangesSince(current) = %+v, want empty", log)
+	}
+}
+
+func TestDiscovery_ChangesIndexTagPolicy(t *testing.T) {
+	idx := index.NewInMemoryIndex(index.IndexOptions{TagPolicy: index.TagPolicy{PreserveCase: true}})
+	disc, err := New(Options{Index: idx})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("deploy", "ops", "deploy", []string{"AWS"}), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	start := idx.Version()
+	if err := disc.RegisterTool(makeTool("deploy", "ops", "deploy", []string{"AWS", "K8s"}), makeBackend("srv"), nil); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+
+	log := disc.ChangesSince(start)
+	if len(log.Updated) != 1 {
+		t.Fatalf("Updated = %+v, want [ops:deploy]", log.Updated)
+	}
+	want := []FieldDiff{{Field: FieldTags, Before: []string{"AWS"}, After: []string{"AWS", "K8s"}}}
+	if got := log.Updated[0].Diffs; !reflect.DeepEqual(got, want) {
+		t.Errorf("Diffs = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiscovery_AsOfIsolated(t *testing.T) {
+	ctx := context.Background()
+	searcher := search.NewBM25Searcher(search.BM25Config{NameBoost: 5})
+	spans := tracetest.NewSpanRecorder()
+	disc, err := New(Options{
+		Searcher:       searcher,
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = disc.RegisterTool(makeTool("deploy", "ops", "deploy service", nil), makeBackend("srv"), nil)
+	then := disc.Version()
+	_ = disc.RegisterTool(makeTool("rollback", "ops", "rollback service", nil), makeBackend("srv"), nil)
+	if _, err := disc.Search(ctx, "service", 5); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	builds, ended := searcher.IndexBuildCount(), len(spans.Ended())
+
+	view, err := disc.AsOfVersion(then)
+	if err != nil {
+		t.Fatalf("AsOfVersion() error = %v", err)
+	}
+	results, err := view.Search(ctx, "service", 5)
+	if err != nil || len(results) != 1 || results[0].Summary.ID != "ops:deploy" {
+		t.Fatalf("view Search() = %v, %v", results.IDs(), err)
+	}
+	if got := searcher.IndexBuildCount(); got != builds {
+		t.Errorf("live searcher rebuilt %d times for the view", got-builds)
+	}
+	if got := len(spans.Ended()); got != ended {
+		t.Errorf("view recorded %d spans on the live tracer", got-ended)
+	}
+	if _, err := disc.Search(ctx, "service", 5); err != nil || searcher.IndexBuildCount() != builds {
+		t.Errorf("live Search() after the view rebuilt the index: err = %v", err)
+	}
+}
+
+func TestDiscovery_AsOfMetadataAndDocs(t *testing.T) {
+	ctx := context.Background()
+	disc, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	idx := disc.Index().(*index.InMemoryIndex)
+	deploy := makeTool("deploy", "ops", "deploy service", nil)
+	if err := idx.RegisterToolWithMetadata(deploy, makeBackend("srv"), map[string]string{"team": "platform"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata() error = %v", err)
+	}
+	if err := disc.RegisterDoc("ops:deploy", tooldoc.DocEntry{Notes: "requires approval"}); err != nil {
+		t.Fatalf("RegisterDoc() error = %v", err)
+	}
+	then := disc.Version()
+
+	if err := idx.RegisterToolWithMetadata(deploy, makeBackend("srv"), map[string]string{"team": "payments"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata() error = %v", err)
+	}
+	if err := disc.RegisterDoc("ops:deploy", tooldoc.DocEntry{Notes: "self-service"}); err != nil {
+		t.Fatalf("RegisterDoc() error = %v", err)
+	}
+
+	view, err := disc.AsOfVersion(then)
+	if err != nil {
+		t.Fatalf("AsOfVersion() error = %v", err)
+	}
+	for query, want := range map[string]int{"deploy metadata.team=platform": 1, "deploy metadata.team=payments": 0} {
+		results, err := view.Search(ctx, query, 5)
+		if err != nil || len(results) != want {
+			t.Errorf("view Search(%q) = %v, %v; want %d results", query, results.IDs(), err, want)
+		}
+	}
+	doc, err := view.DescribeTool("ops:deploy", tooldoc.DetailFull)
+	if err != nil || doc.Notes != "requires approval" {
+		t.Errorf("view DescribeTool() notes = %q, %v; want requires approval", doc.Notes, err)
+	}
+
+	if results, _ := disc.Search(ctx, "deploy metadata.team=payments", 5); len(results) != 1 {
+		t.Errorf("live Search(payments) = %v, want ops:deploy", results.IDs())
+	}
+	if doc, _ := disc.DescribeTool("ops:deploy", tooldoc.DetailFull); doc.Notes != "self-service" {
+		t.Errorf("live DescribeTool() notes = %q, want self-service", doc.Notes)
+	}
+}
+
+func TestDiscovery_ChangesTruncated(t *testing.T) {
+	disc, err := New(Options{ChangeJournalSize: 2})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := disc.RegisterTool(makeTool(name, "ns", "tool "+name, nil), makeBackend("srv"), nil); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+
+	log := disc.ChangesSince(0)
+	if !log.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	if len(log.Added) != 2 {
+		t.Errorf("Added = %+v, want 2 retained entries", log.Added)
+	}
+	if log := disc.ChangesSince(log.FromVersion); log.Truncated || len(log.Added) != 1 {
+		t.Errorf("ChangesSince(retained) = %+v, want 1 untruncated entry", log)
+	}
+}
+
+func TestDiscovery_AsOf(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	disc, err := New(Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	idx := disc.Index().(*index.InMemoryIndex)
+	register := func(name, desc string) {
+		t.Helper()
+		now = now.Add(time.Minute)
+		if err := disc.RegisterTool(makeTool(name, "ops", desc, nil), makeBackend("srv"), nil); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+	register("deploy", "deploy service")
+	register("rollback", "rollback service")
+	then, thenVersion := now, disc.Version()
+
+	unregister := func(id string) {
+		t.Helper()
+		now = now.Add(time.Minute)
+		if err := disc.UnregisterBackend(id, model.BackendKindMCP, "srv"); err != nil {
+			t.Fatalf("UnregisterBackend() error = %v", err)
+		}
+	}
+	unregister("ops:deploy")
+	register("deploy", "deploy service to kubernetes")
+	unregister("ops:rollback")
+	register("scale", "scale service")
+	now = now.Add(time.Minute)
+	if err := idx.DisableTool("ops:scale"); err != nil {
+		t.Fatalf("DisableTool() error = %v", err)
+	}
+
+	byTime, err := disc.AsOf(then)
+	if err != nil {
+		t.Fatalf("AsOf() error = %v", err)
+	}
+	byVersion, err := disc.AsOfVersion(thenVersion)
+	if err != nil {
+		t.Fatalf("AsOfVersion() error = %v", err)
+	}
+	for name, view := range map[string]*HistoricalView{"AsOf": byTime, "AsOfVersion": byVersion} {
+		if view.Version() != thenVersion {
+			t.Errorf("%s: Version() = %d, want %d", name, view.Version(), thenVersion)
+		}
+		tool, _, err := view.GetTool("ops:deploy")
+		if err != nil || tool.Description != "deploy service" {
+			t.Errorf("%s: GetTool(deploy) = %q, %v; want original description", name, tool.Description, err)
+		}
+		if _, _, err := view.GetTool("ops:rollback"); err != nil {
+			t.Errorf("%s: GetTool(rollback) error = %v, want removed tool restored", name, err)
+		}
+		if _, _, err := view.GetTool("ops:scale"); !errors.Is(err, index.ErrNotFound) {
+			t.Errorf("%s: GetTool(scale) error = %v, want ErrNotFound", name, err)
+		}
+		results, err := view.Search(context.Background(), "rollback", 5)
+		if err != nil || len(results) != 1 || results[0].Summary.ID != "ops:rollback" {
+			t.Errorf("%s: Search(rollback) = %+v, %v", name, results, err)
+		}
+		doc, err := view.DescribeTool("ops:deploy", tooldoc.DetailSummary)
+		if err != nil || doc.Summary != "deploy service" {
+			t.Errorf("%s: DescribeTool() = %+v, %v", name, doc, err)
+		}
+	}
+
+	current, err := disc.AsOfVersion(disc.Version())
+	if err != nil {
+		t.Fatalf("AsOfVersion(current) error = %v", err)
+	}
+	if _, _, err := current.GetTool("ops:scale"); !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("GetTool(disabled scale) error = %v, want ErrNotFound", err)
+	}
+	if results, _ := current.Search(context.Background(), "rollback", 5); len(results) != 0 {
+		t.Errorf("Search(rollback) = %+v, want removed tool absent", results)
+	}
+	if _, _, err := disc.GetTool("ops:deploy"); err != nil {
+		t.Errorf("live GetTool() error = %v", err)
+	}
+
+	if _, err := disc.AsOf(then.Add(-time.Hour)); !errors.Is(err, ErrHistoryUnavailable) {
+		t.Errorf("AsOf(before journal) error = %v, want ErrHistoryUnavailable", err)
+	}
+	pruned, err := New(Options{ChangeJournalSize: 1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if err := pruned.RegisterTool(makeTool(name, "ns", "tool "+name, nil), makeBackend("srv"), nil); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+	if _, err := pruned.AsOfVersion(0); !errors.Is(err, ErrHistoryUnavailable) {
+		t.Errorf("AsOfVersion(evicted) error = %v, want ErrHistoryUnavailable", err)
+	}
+	if _, err := pruned.AsOfVersion(1); err != nil {
+		t.Errorf("AsOfVersion(retained) error = %v", err)
+	}
+}
+
+func TestDiscovery_SearchForRollout(t *testing.T) {
+	for _, withEmbedder := range []bool{false, true} {
+		opts := Options{}
+		if withEmbedder {
+			opts.Embedder = &mockEmbedder{dim: 8}
+		}
+		disc, err := New(opts)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		_ = disc.RegisterTool(makeTool("deploy", "ops", "deploy service", nil), makeBackend("srv"), nil)
+		_ = disc.RegisterTool(makeTool("deploy-v2", "ops", "deploy service faster", nil), makeBackend("srv"), nil)
+
+		idx := disc.Index().(*index.InMemoryIndex)
+		if err := idx.SetRollout(index.Rollout{ToolID: "ops:deploy-v2", Principals: []string{"canary-agent"}}); err != nil {
+			t.Fatalf("SetRollout() error = %v", err)
+		}
+
+		results, err := disc.Search(context.Background(), "deploy", 10)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Summary.ID != "ops:deploy" {
+			t.Errorf("embedder=%v: Search() = %+v, want [ops:deploy]", withEmbedder, results)
+		}
+
+		results, err = disc.SearchFor(context.Background(), "canary-agent", "deploy", 10)
+		if err != nil {
+			t.Fatalf("SearchFor() error = %v", err)
+		}
+		if len(results) != 2 {
+			t.Errorf("embedder=%v: SearchFor() returned %d results, want 2", withEmbedder, len(results))
+		}
+
+		page, _, err := disc.SearchPageFor(context.Background(), "canary-agent", "deploy", 10, "")
+		if err != nil {
+			t.Fatalf("SearchPageFor() error = %v", err)
+		}
+		if len(page) != 2 {
+			t.Errorf("embedder=%v: SearchPageFor() returned %d results, want 2", withEmbedder, len(page))
+		}
+	}
+}
+
+func TestFitResults(t *testing.T) {
+	results := make(Results, 5)
+	for i := range results {
+		results[i] = Result{Summary: index.Summary{
+			ID:               fmt.Sprintf("ns:tool%d", i),
+			Name:             fmt.Sprintf("tool%d", i),
+			ShortDescription: strings.Repeat("s", 100),
+			Summary:          strings.Repeat("l", 300),
+		}}
+	}
+	size := func(v any) int {
+		b, _ := json.Marshal(v)
+		return len(b)
+	}
+	full := size(results)
+
+	if got, truncated := FitResults(results, full); truncated || len(got) != 5 {
+		t.Fatalf("FitResults(full) = %d results, truncated=%v", len(got), truncated)
+	}
+
+	got, truncated := FitResults(results, full-1)
+	if !truncated || len(got) != 5 || got[0].Summary.Summary != "" || got[0].Summary.ShortDescription == "" {
+		t.Fatalf("first tier: truncated=%v results=%+v", truncated, got[0].Summary)
+	}
+	if results[0].Summary.Summary == "" {
+		t.Fatal("FitResults modified its input")
+	}
+
+	got, truncated = FitResults(results, 300)
+	if !truncated || len(got) == 0 || len(got) == 5 || size(got) > 300 {
+		t.Fatalf("drop tier: truncated=%v len=%d size=%d", truncated, len(got), size(got))
+	}
+}
+
+func TestDescribeToolWithin(t *testing.T) {
+	disc, _ := New(Options{})
+	doc := &tooldoc.DocEntry{
+		Notes: strings.Repeat("n", 1000),
+		Examples: []tooldoc.ToolExample{
+			{Title: "one", Args: map[string]any{"q": "a"}},
+			{Title: "two", Args: map[string]any{"q": "b"}},
+		},
+	}
+	if err := disc.RegisterTool(makeTool("find", "fs", "find files", nil), makeBackend("srv"), doc); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	full, err := disc.DescribeTool("fs:find", tooldoc.DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool() error = %v", err)
+	}
+	fullSize := len(mustJSON(t, full))
+
+	got, truncated, err := disc.DescribeToolWithin("fs:find", tooldoc.DetailFull, fullSize-1)
+	if err != nil || !truncated {
+		t.Fatalf("DescribeToolWithin() truncated=%v err=%v", truncated, err)
+	}
+	if len(got.Examples) != 1 || got.Notes == "" {
+		t.Errorf("expected one example dropped first, got %d examples, notes=%d", len(got.Examples), len(got.Notes))
+	}
+
+	got, truncated, _ = disc.DescribeToolWithin("fs:find", tooldoc.DetailFull, 300)
+	if !truncated || got.Notes != "" || len(got.Examples) != 0 || got.Tool == nil {
+		t.Errorf("expected schema-level doc, got %+v", got)
+	}
+
+	got, _, _ = disc.DescribeToolWithin("fs:find", tooldoc.DetailFull, 10)
+	if got.Tool != nil || got.Summary == "" {
+		t.Errorf("expected summary-level doc, got %+v", got)
+	}
+}
+
+func TestDescribeToolBudget(t *testing.T) {
+	disc, _ := New(Options{})
+	doc := &tooldoc.DocEntry{
+		Notes: strings.Repeat("n", 1000),
+		Examples: []tooldoc.ToolExample{
+			{ID: "one", Title: "one", Args: map[string]any{"q": "a"}},
+			{ID: "two", Title: "two", Args: map[string]any{"q": "b"}},
+		},
+	}
+	if err := disc.RegisterTool(makeTool("find", "fs", "find files", nil), makeBackend("srv"), doc); err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	var described int
+	disc.UseDescribeInterceptor(func(next DescribeFunc) DescribeFunc {
+		return func(ctx context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+			described++
+			return next(ctx, id, level)
+		}
+	})
+
+	got, report, err := disc.DescribeToolBudget("fs:find", tooldoc.DetailFull, 600)
+	if err != nil {
+		t.Fatalf("DescribeToolBudget() error = %v", err)
+	}
+	if described != 1 {
+		t.Errorf("interceptor ran %d times, want 1", described)
+	}
+	if got.Notes == "" || len(got.Notes) >= 1000 || len(got.Examples) != 1 {
+		t.Errorf("got notes %d, %d examples; want partial notes and the first example", len(got.Notes), len(got.Examples))
+	}
+	if len(report.Omitted) != 2 || report.Omitted[0].IDs[0] != "two" || report.Omitted[1].Field != "notes" {
+		t.Errorf("report = %+v", report)
+	}
+	if len(mustJSON(t, got)) != report.Bytes || report.Bytes > 600 {
+		t.Errorf("encoded %d bytes, report %+v", len(mustJSON(t, got)), report)
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return b
+}
+
+// recordingEmbedder records every text it embeds.
+type recordingEmbedder struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (e *recordingEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	e.texts = append(e.texts, text)
+	e.mu.Unlock()
+	return []float32{1, float32(len(text))}, nil
+}
+
+func (e *recordingEmbedder) embedded(substr string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, text := range e.texts {
+		if strings.Contains(text, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHybridSearcher_EmbedderRoutes(t *testing.T) {
+	general := &recordingEmbedder{}
+	code := &recordingEmbedder{}
+	disc, err := New(Options{
+		Embedder: general,
+		EmbedderRoutes: []EmbedderRoute{
+			{Namespaces: []string{"git"}, Tags: []string{"Code"}, Embedder: code},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = disc.RegisterTool(makeTool("commit", "git", "record changes", nil), makeBackend("srv"), nil)
+	_ = disc.RegisterTool(makeTool("lint", "ci", "check style", []string{"code"}), makeBackend("srv"), nil)
+	_ = disc.RegisterTool(makeTool("weather", "misc", "forecast lookup", nil), makeBackend("srv"), nil)
+
+	if _, err := disc.Search(context.Background(), "changes", 10); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	for _, text := range []string{"record changes", "check style"} {
+		if !code.embedded(text) || general.embedded(text) {
+			t.Errorf("%q should be embedded only by the routed embedder", text)
+		}
+	}
+	if !general.embedded("forecast lookup") || code.embedded("forecast lookup") {
+		t.Error("unrouted tool should use the default embedder")
+	}
+	if !code.embedded("changes") || !general.embedded("changes") {
+		t.Error("query should be embedded by each embedder that scores a document")
+	}
+
+	if _, err := NewHybridSearcher(HybridOptions{Embedder: general, EmbedderRoutes: []EmbedderRoute{{Embedder: code}}}); !errors.Is(err, semantic.ErrInvalidHybridConfig) {
+		t.Errorf("route without matchers: expected ErrInvalidHybridConfig, got %v", err)
+	}
+	if _, err := NewHybridSearcher(HybridOptions{Embedder: general, EmbedderRoutes: []EmbedderRoute{{Namespaces: []string{"git"}}}}); !errors.Is(err, semantic.ErrInvalidEmbedder) {
+		t.Errorf("route without embedder: expected ErrInvalidEmbedder, got %v", err)
+	}
+}
+
+func calibrationSamples() []ScoreSample {
+	// Relevance rises with score, with some noise in the middle.
+	var samples []ScoreSample
+	for i := 0; i < 20; i++ {
+		score := float64(i) / 2
+		samples = append(samples, ScoreSample{Score: score, Relevant: i >= 12 || i == 8})
+	}
+	return samples
+}
+
+func TestFitCalibrator(t *testing.T) {
+	for _, method := range []CalibrationMethod{CalibrationIsotonic, CalibrationPlatt} {
+		t.Run(string(method), func(t *testing.T) {
+			c, err := FitCalibrator(calibrationSamples(), method)
+			if err != nil {
+				t.Fatalf("FitCalibrator failed: %v", err)
+			}
+			if c.Method() != method {
+				t.Fatalf("Method() = %q, want %q", c.Method(), method)
+			}
+
+			prev := -1.0
+			for score := -2.0; score <= 12; score += 0.25 {
+				conf := c.Confidence(score)
+				if conf < 0 || conf > 1 {
+					t.Fatalf("Confidence(%v) = %v, want within [0, 1]", score, conf)
+				}
+				if conf < prev-1e-12 {
+					t.Fatalf("Confidence(%v) = %v decreased from %v", score, conf, prev)
+				}
+				prev = conf
+			}
+			if low, high := c.Confidence(0), c.Confidence(9.5); low > 0.2 || high < 0.8 {
+				t.Fatalf("Confidence(0) = %v, Confidence(9.5) = %v; want low < 0.2, high > 0.8", low, high)
+			}
+		})
+	}
+}
+
+func TestFitCalibrator_Isotonic(t *testing.T) {
+	samples := []ScoreSample{
+		{Score: 1, Relevant: false},
+		{Score: 2, Relevant: true},
+		{Score: 3, Relevant: false},
+		{Score: 4, Relevant: true},
+	}
+	c, err := FitCalibrator(samples, CalibrationIsotonic)
+	if err != nil {
+		t.Fatalf("FitCalibrator failed: %v", err)
+	}
+	// Scores 2 and 3 violate monotonicity and are pooled to 0.5.
+	tests := []struct {
+		score float64
+		want  float64
+	}{
+		{0, 0}, {1, 0}, {1.5, 0.25}, {2, 0.5}, {2.5, 0.5}, {3, 0.5}, {3.5, 0.75}, {4, 1}, {10, 1},
+	}
+	for _, tt := range tests {
+		if got := c.Confidence(tt.score); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Confidence(%v) = %v, want %v", tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestFitCalibrator_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []ScoreSample
+		method  CalibrationMethod
+	}{
+		{name: "empty", samples: nil, method: CalibrationPlatt},
+		{name: "single class", samples: []ScoreSample{{Score: 1, Relevant: true}, {Score: 2, Relevant: true}}, method: CalibrationIsotonic},
+		{name: "nan", samples: []ScoreSample{{Score: math.NaN()}, {Score: 1, Relevant: true}}, method: CalibrationIsotonic},
+		{name: "unknown method", samples: calibrationSamples(), method: "spline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FitCalibrator(tt.samples, tt.method); !errors.Is(err, ErrInvalidCalibration) {
+				t.Fatalf("err = %v, want ErrInvalidCalibration", err)
+			}
+		})
+	}
+}
+
+func TestResults_FilterByConfidence(t *testing.T) {
+	c, err := FitCalibrator(calibrationSamples(), CalibrationIsotonic)
+	if err != nil {
+		t.Fatalf("FitCalibrator failed: %v", err)
+	}
+	results := Results{
+		{Summary: index.Summary{ID: "high"}, Score: 9},
+		{Summary: index.Summary{ID: "low"}, Score: 1},
+	}
+	got := results.FilterByConfidence(c, 0.8).IDs()
+	if !reflect.DeepEqual(got, []string{"high"}) {
+		t.Fatalf("FilterByConfidence = %v, want [high]", got)
+	}
+}
+
+func TestDiscovery_ChangesInjectedClock(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	disc, err := New(Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_ = disc.RegisterTool(makeTool("old", "fs", "old tool", nil), makeBackend("srv"), nil)
+	now = now.Add(time.Hour)
+	_ = disc.RegisterTool(makeTool("new", "fs", "new tool", nil), makeBackend("srv"), nil)
+
+	log := disc.Changes(now.Add(-time.Minute))
+	if len(log.Added) != 1 || log.Added[0].ToolID != "fs:new" || !log.Added[0].Timestamp.Equal(now) {
+		t.Fatalf("Changes() = %+v, want only fs:new at %v", log, now)
+	}
+}
+
+func (e *recordingEmbedder) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.texts)
+}
+
+func TestDiscovery_VectorIndexEmbedsOnlyQuery(t *testing.T) {
+	ctx := context.Background()
+	emb := &recordingEmbedder{}
+	vectors, err := semantic.NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	disc, err := New(Options{VectorIndex: vectors})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, name := range []string{"read_file", "write_file", "delete_file"} {
+		if err := disc.RegisterTool(makeTool(name, "fs", "File operation "+name, nil), makeBackend("fs"), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+
+	if err := disc.WarmEmbeddings(ctx); err != nil {
+		t.Fatalf("WarmEmbeddings failed: %v", err)
+	}
+	if got := emb.count(); got != 3 {
+		t.Fatalf("warm embedded %d texts, want 3", got)
+	}
+	if _, ok := vectors.Vector("fs:read_file"); !ok {
+		t.Fatal("expected read_file vector after warm")
+	}
+
+	results, err := disc.Search(ctx, "file", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 || results[0].ScoreType != ScoreHybrid {
+		t.Fatalf("results = %+v", results)
+	}
+	if got := emb.count(); got != 4 {
+		t.Fatalf("search embedded %d texts, want 1 (query only)", got-3)
+	}
+
+	if err := disc.RegisterTool(makeTool("stat_file", "fs", "File operation stat_file", nil), makeBackend("fs"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if _, err := disc.Search(ctx, "file", 10); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := emb.count(); got != 6 {
+		t.Fatalf("search after registration embedded %d texts, want 2 (query + new tool)", got-4)
+	}
+}
+
+func TestDiscovery_VectorIndexDropsRemovedTools(t *testing.T) {
+	vectors, _ := semantic.NewVectorIndex(&recordingEmbedder{}, "v1")
+	idx := index.NewInMemoryIndex()
+	disc, err := New(Options{Index: idx, VectorIndex: vectors})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("read_file", "fs", "Read a file", nil), makeBackend("fs"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := disc.WarmEmbeddings(context.Background()); err != nil {
+		t.Fatalf("WarmEmbeddings failed: %v", err)
+	}
+	if err := idx.UnregisterBackend("fs:read_file", model.BackendKindMCP, "fs"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if _, ok := vectors.Vector("fs:read_file"); ok {
+		t.Fatal("expected vector to be dropped when the tool was removed")
+	}
+}
+
+// batchingEmbedder implements semantic.BatchEmbedder and counts calls.
+type batchingEmbedder struct {
+	mu      sync.Mutex
+	single  int
+	batches int
+}
+
+func (e *batchingEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	e.single++
+	e.mu.Unlock()
+	return []float32{1, float32(len(text))}, nil
+}
+
+func (e *batchingEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.batches++
+	e.mu.Unlock()
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = []float32{1, float32(len(text))}
+	}
+	return out, nil
+}
+
+func TestHybridSearcher_UsesBatchEmbedder(t *testing.T) {
+	general := &batchingEmbedder{}
+	code := &batchingEmbedder{}
+	hybrid, err := NewHybridSearcher(HybridOptions{
+		Embedder:       general,
+		EmbedderRoutes: []EmbedderRoute{{Namespaces: []string{"git"}, Embedder: code}},
+	})
+	if err != nil {
+		t.Fatalf("NewHybridSearcher failed: %v", err)
+	}
+	docs := []index.SearchDoc{
+		{ID: "fs:read", Summary: index.Summary{ID: "fs:read", Name: "read", Namespace: "fs"}},
+		{ID: "fs:write", Summary: index.Summary{ID: "fs:write", Name: "write", Namespace: "fs"}},
+		{ID: "git:commit", Summary: index.Summary{ID: "git:commit", Name: "commit", Namespace: "git"}},
+	}
+	results, err := hybrid.SearchWithScores(context.Background(), "read", 10, docs)
+	if err != nil {
+		t.Fatalf("SearchWithScores failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	if general.batches != 1 || general.single != 0 {
+		t.Fatalf("general embedder: batches = %d, single = %d; want 1 batch", general.batches, general.single)
+	}
+	if code.batches != 1 || code.single != 0 {
+		t.Fatalf("routed embedder: batches = %d, single = %d; want 1 batch", code.batches, code.single)
+	}
+}
+
+func TestDiscovery_UnregisterBackendRemovesDocsAndVectors(t *testing.T) {
+	vectors, _ := semantic.NewVectorIndex(&recordingEmbedder{}, "v1")
+	disc, err := New(Options{VectorIndex: vectors})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	tool := makeTool("read_file", "fs", "Read a file", nil)
+	doc := &tooldoc.DocEntry{Summary: "Reads files"}
+	if err := disc.RegisterTool(tool, makeBackend("a"), doc); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := disc.RegisterTool(tool, makeBackend("b"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := disc.WarmEmbeddings(context.Background()); err != nil {
+		t.Fatalf("WarmEmbeddings failed: %v", err)
+	}
+
+	if err := disc.UnregisterBackend("fs:read_file", model.BackendKindMCP, "a"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if _, err := disc.DocStore().DescribeTool("fs:read_file", tooldoc.DetailSummary); err != nil {
+		t.Fatalf("docs removed while a backend remains: %v", err)
+	}
+
+	if err := disc.UnregisterBackend("fs:read_file", model.BackendKindMCP, "b"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if _, err := disc.DocStore().DescribeTool("fs:read_file", tooldoc.DetailSummary); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Fatalf("DescribeTool err = %v, want tooldoc.ErrNotFound", err)
+	}
+	if _, ok := vectors.Vector("fs:read_file"); ok {
+		t.Fatal("expected cached vector to be removed")
+	}
+	if err := disc.UnregisterBackend("fs:read_file", model.BackendKindMCP, "b"); !errors.Is(err, index.ErrNotFound) {
+		t.Fatalf("UnregisterBackend of removed tool err = %v, want index.ErrNotFound", err)
+	}
+}
+
+func TestDiscovery_RemoveDocAndExamples(t *testing.T) {
+	disc, _ := New(Options{})
+	tool := makeTool("read_file", "fs", "Read a file", nil)
+	doc := &tooldoc.DocEntry{
+		Summary:  "Reads files",
+		Examples: []tooldoc.ToolExample{{ID: "small", Title: "Small"}, {ID: "large", Title: "Large"}},
+	}
+	if err := disc.RegisterTool(tool, makeBackend("fs"), doc); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	if err := disc.RemoveExamples("fs:read_file", []string{"large"}); err != nil {
+		t.Fatalf("RemoveExamples failed: %v", err)
+	}
+	examples, _ := disc.ListExamples("fs:read_file", 10)
+	if len(examples) != 1 || examples[0].ID != "small" {
+		t.Fatalf("examples = %+v, want only small", examples)
+	}
+
+	if err := disc.UpdateExample("fs:read_file", "small", tooldoc.ToolExample{Title: "Tiny", Priority: 1}); err != nil {
+		t.Fatalf("UpdateExample failed: %v", err)
+	}
+	if examples, _ := disc.ListExamples("fs:read_file", 10); examples[0].Title != "Tiny" {
+		t.Fatalf("examples = %+v, want updated title", examples)
+	}
+	notes := "Follows symlinks"
+	if err := disc.PatchDoc("fs:read_file", tooldoc.DocPatch{Notes: &notes}); err != nil {
+		t.Fatalf("PatchDoc failed: %v", err)
+	}
+	if doc, _ := disc.DescribeTool("fs:read_file", tooldoc.DetailFull); doc.Summary != "Reads files" || doc.Notes != notes {
+		t.Fatalf("doc = %+v, want summary kept and notes patched", doc)
+	}
+	if err := disc.DeleteExample("fs:read_file", "small"); err != nil {
+		t.Fatalf("DeleteExample failed: %v", err)
+	}
+	if err := disc.DeleteExample("fs:read_file", "small"); !errors.Is(err, tooldoc.ErrExampleNotFound) {
+		t.Fatalf("second DeleteExample err = %v, want tooldoc.ErrExampleNotFound", err)
+	}
+
+	var removed []string
+	disc.OnDocChange(func(e tooldoc.ChangeEvent) {
+		if e.Type == tooldoc.ChangeRemoved {
+			removed = append(removed, e.ToolID)
+		}
+	})
+	if err := disc.RemoveDoc("fs:read_file"); err != nil {
+		t.Fatalf("RemoveDoc failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "fs:read_file" {
+		t.Fatalf("removed events = %v, want [fs:read_file]", removed)
+	}
+	if err := disc.RemoveDoc("fs:read_file"); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Fatalf("second RemoveDoc err = %v, want tooldoc.ErrNotFound", err)
+	}
+	if _, _, err := disc.GetTool("fs:read_file"); err != nil {
+		t.Fatalf("tool removed with its docs: %v", err)
+	}
+}
+
+// fixedBM25 returns a preset lexical score per document ID.
+type fixedBM25 map[string]float64
+
+func (f fixedBM25) Score(_ string, doc semantic.Document) float64 { return f[doc.ID] }
+
+// fixedEmbedder returns a preset vector per text.
+type fixedEmbedder map[string][]float32
+
+func (f fixedEmbedder) Embed(_ context.Context, text string) ([]float32, error) { return f[text], nil }
+
+func TestHybridSearcher_FusionRRF(t *testing.T) {
+	bm25 := fixedBM25{"a": 100, "b": 1}
+	emb := fixedEmbedder{"query": {1}, "a": {0.1}, "b": {0.9}, "c": {0.5}}
+	docs := []index.SearchDoc{
+		{ID: "a", Summary: index.Summary{ID: "a", Name: "a"}},
+		{ID: "b", Summary: index.Summary{ID: "b", Name: "b"}},
+		{ID: "c", Summary: index.Summary{ID: "c", Name: "c"}},
+	}
+	search := func(opts HybridOptions) Results {
+		t.Helper()
+		opts.BM25Scorer, opts.Embedder, opts.Metric, opts.Alpha = bm25, emb, semantic.MetricDot, 0.5
+		h, err := NewHybridSearcher(opts)
+		if err != nil {
+			t.Fatalf("NewHybridSearcher failed: %v", err)
+		}
+		results, err := h.SearchWithScores(context.Background(), "query", 10, docs)
+		if err != nil {
+			t.Fatalf("SearchWithScores failed: %v", err)
+		}
+		return results
+	}
+	ids := func(results Results) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.Summary.ID
+		}
+		return out
+	}
+
+	// The BM25 scale swamps the weighted sum.
+	if got := ids(search(HybridOptions{})); strings.Join(got, ",") != "a,b,c" {
+		t.Fatalf("weighted order = %v, want a,b,c", got)
+	}
+
+	results := search(HybridOptions{Fusion: FusionRRF})
+	if got := ids(results); strings.Join(got, ",") != "b,a,c" {
+		t.Fatalf("rrf order = %v, want b,a,c", got)
+	}
+	wantB := 0.5/float64(DefaultRRFK+2) + 0.5/float64(DefaultRRFK+1)
+	if math.Abs(results[0].Score-wantB) > 1e-12 || results[0].ScoreType != ScoreHybrid {
+		t.Fatalf("b score = %v (%s), want %v", results[0].Score, results[0].ScoreType, wantB)
+	}
+
+	results = search(HybridOptions{Fusion: FusionRRF, RRFK: 1})
+	if want := 0.5 / 3; math.Abs(results[2].Score-want) > 1e-12 {
+		t.Fatalf("c score with k=1 = %v, want %v", results[2].Score, want)
+	}
+
+	if _, err := NewHybridSearcher(HybridOptions{Embedder: emb, Fusion: "max"}); !errors.Is(err, semantic.ErrInvalidHybridConfig) {
+		t.Fatalf("unknown fusion err = %v, want ErrInvalidHybridConfig", err)
+	}
+	if _, err := NewHybridSearcher(HybridOptions{Embedder: emb, RRFK: -1}); !errors.Is(err, semantic.ErrInvalidHybridConfig) {
+		t.Fatalf("negative k err = %v, want ErrInvalidHybridConfig", err)
+	}
+}
+
+func connectMetatools(t *testing.T, disc *Discovery) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := ServeMCP(disc).Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server Connect failed: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client Connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func callMetatool(t *testing.T, session *mcp.ClientSession, name string, args map[string]any, out any) *mcp.CallToolResult {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	if out != nil && !res.IsError {
+		raw, _ := json.Marshal(res.StructuredContent)
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatalf("decode %s output: %v", name, err)
+		}
+	}
+	return res
+}
+
+func TestServeMCP(t *testing.T) {
+	disc, _ := New(Options{})
+	doc := &tooldoc.DocEntry{
+		Summary:  "Creates GitHub issues",
+		Notes:    "Requires repo scope",
+		Examples: []tooldoc.ToolExample{{ID: "bug", Title: "File a bug", Args: map[string]any{"title": "Crash"}}},
+	}
+	if err := disc.RegisterTool(makeTool("create_issue", "github", "Create an issue", []string{"issues"}), makeBackend("github"), doc); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("read_file", "fs", "Read a file", nil), makeBackend("fs"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	session := connectMetatools(t, disc)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	want := []string{MetatoolDescribeTool, MetatoolListNamespaces, MetatoolListToolExamples, MetatoolSearchTools}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("tools = %v, want %v", names, want)
+	}
+
+	var search searchToolsOutput
+	callMetatool(t, session, MetatoolSearchTools, map[string]any{"query": "issue"}, &search)
+	if len(search.Tools) == 0 || search.Tools[0].Summary.ID != "github:create_issue" {
+		t.Fatalf("search_tools = %+v, want github:create_issue first", search)
+	}
+
+	var page searchToolsOutput
+	callMetatool(t, session, MetatoolSearchTools, map[string]any{"query": "", "limit": 1}, &page)
+	if len(page.Tools) != 1 || page.NextCursor == "" {
+		t.Fatalf("first page = %+v, want one tool and a cursor", page)
+	}
+	callMetatool(t, session, MetatoolSearchTools, map[string]any{"query": "", "limit": 1, "cursor": page.NextCursor}, &page)
+	if len(page.Tools) != 1 || page.Tools[0].Summary.ID != "github:create_issue" {
+		t.Fatalf("second page = %+v, want github:create_issue", page)
+	}
+
+	var described tooldoc.ToolDoc
+	callMetatool(t, session, MetatoolDescribeTool, map[string]any{"id": "github:create_issue", "level": "full"}, &described)
+	if described.Notes != "Requires repo scope" || described.Tool == nil {
+		t.Fatalf("describe_tool full = %+v", described)
+	}
+	callMetatool(t, session, MetatoolDescribeTool, map[string]any{"id": "github:create_issue"}, &described)
+	if described.Summary != "Creates GitHub issues" {
+		t.Fatalf("describe_tool summary = %+v", described)
+	}
+	if res := callMetatool(t, session, MetatoolDescribeTool, map[string]any{"id": "github:missing"}, nil); !res.IsError {
+		t.Fatal("describe_tool for unknown tool should be a tool error")
+	}
+	var compact tooldoc.ToolDoc
+	callMetatool(t, session, MetatoolDescribeTool, map[string]any{"id": "github:create_issue", "level": "full", "profile": "compact"}, &compact)
+	if compact.Notes != "Requires repo scope" || len(compact.Examples) != 1 {
+		t.Fatalf("describe_tool compact = %+v", compact)
+	}
+	if res := callMetatool(t, session, MetatoolDescribeTool, map[string]any{"id": "github:create_issue", "profile": "tiny"}, nil); !res.IsError {
+		t.Fatal("describe_tool with unknown profile should be a tool error")
+	}
+
+	var examples listToolExamplesOutput
+	callMetatool(t, session, MetatoolListToolExamples, map[string]any{"id": "github:create_issue"}, &examples)
+	if len(examples.Examples) != 1 || examples.Examples[0].ID != "bug" {
+		t.Fatalf("list_tool_examples = %+v", examples)
+	}
+
+	var namespaces listNamespacesOutput
+	callMetatool(t, session, MetatoolListNamespaces, nil, &namespaces)
+	if !reflect.DeepEqual(namespaces.Namespaces, []string{"fs", "github"}) {
+		t.Fatalf("list_namespaces = %+v", namespaces)
+	}
+}
+
+func TestDiscovery_RegisterAliases(t *testing.T) {
+	disc, _ := New(Options{})
+	_ = disc.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil)
+	_ = disc.RegisterTool(makeTool("list_pods", "k8s", "List running pods", nil), makeBackend("k8s"), nil)
+
+	ctx := context.Background()
+	if results, _ := disc.Search(ctx, "ticket", 10); len(results) != 0 {
+		t.Fatalf("expected no results before aliasing, got %v", results)
+	}
+
+	if err := disc.RegisterAliases("github:create_issue", []string{"ticket", "New Bug"}); err != nil {
+		t.Fatalf("RegisterAliases failed: %v", err)
+	}
+	if err := disc.RegisterAliases("", []string{"x"}); err == nil {
+		t.Error("expected error for invalid tool ID")
+	}
+	if got := disc.Aliases("github:create_issue"); len(got) != 2 {
+		t.Errorf("Aliases = %v", got)
+	}
+
+	for _, query := range []string{"ticket", "file a new bug"} {
+		results, err := disc.Search(ctx, query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		if len(results) == 0 || results[0].Summary.ID != "github:create_issue" {
+			t.Errorf("Search(%q) = %v, want github:create_issue first", query, results)
+		}
+	}
+	page, _, err := disc.SearchPage(ctx, "ticket", 10, "")
+	if err != nil || len(page) == 0 || page[0].Summary.ID != "github:create_issue" {
+		t.Errorf("SearchPage(ticket) = %v, %v", page, err)
+	}
+
+	_ = disc.RegisterAliases("github:create_issue", nil)
+	if results, _ := disc.Search(ctx, "ticket", 10); len(results) != 0 {
+		t.Errorf("expected aliases to be removed, got %v", results)
+	}
+
+	_ = disc.RegisterAliases("k8s:list_pods", []string{"pods please"})
+	_ = disc.UnregisterBackend("k8s:list_pods", model.BackendKindMCP, "k8s")
+	if got := disc.Aliases("k8s:list_pods"); len(got) != 0 {
+		t.Errorf("expected aliases dropped with the tool, got %v", got)
+	}
+}
+
+func TestDiscovery_Synonyms(t *testing.T) {
+	synonyms := search.Synonyms{"k8s": {"kubernetes"}}
+	tool := makeTool("deploy", "apps", "Deploy a workload to Kubernetes", nil)
+	ctx := context.Background()
+
+	bm25, _ := New(Options{BM25Config: search.BM25Config{Synonyms: synonyms}})
+	_ = bm25.RegisterTool(tool, makeBackend("apps"), nil)
+	if results, _ := bm25.Search(ctx, "k8s", 10); len(results) != 1 {
+		t.Errorf("BM25 Search(k8s) = %v, want 1 result", results)
+	}
+
+	hybrid, _ := New(Options{
+		Embedder:    &mockEmbedder{dim: 8},
+		HybridAlpha: 1,
+		BM25Config:  search.BM25Config{Synonyms: synonyms},
+	})
+	_ = hybrid.RegisterTool(tool, makeBackend("apps"), nil)
+	results, err := hybrid.Search(ctx, "k8s", 10)
+	if err != nil {
+		t.Fatalf("hybrid Search error = %v", err)
+	}
+	if len(results) != 1 || results[0].Score <= 0 {
+		t.Errorf("hybrid Search(k8s) = %v, want 1 scored result", results)
+	}
+
+	// Synonyms cannot reach a searcher New does not build.
+	for name, opts := range map[string]Options{
+		"custom index":    {Index: index.NewInMemoryIndex()},
+		"custom searcher": {Searcher: search.NewBM25Searcher(search.BM25Config{})},
+	} {
+		opts.BM25Config.Synonyms = synonyms
+		if _, err := New(opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%s: New error = %v, want ErrInvalidOptions", name, err)
+		}
+	}
+}
+
+func TestDiscovery_SelfTest(t *testing.T) {
+	ctx := context.Background()
+
+	disc, _ := New(Options{})
+	report := disc.SelfTest(ctx)
+	if !report.Healthy || report.Err() != nil {
+		t.Fatalf("empty BM25 discovery should be healthy: %+v", report)
+	}
+	statuses := map[string]CheckStatus{}
+	for _, c := range report.Checks {
+		statuses[c.Name] = c.Status
+	}
+	if statuses[CheckEmbedder] != CheckSkipped || statuses[CheckSearcher] != CheckOK || statuses[CheckDocStore] != CheckSkipped {
+		t.Errorf("statuses = %v", statuses)
+	}
+
+	disc, _ = New(Options{
+		Embedder: &mockEmbedder{dim: 4},
+		EmbedderRoutes: []EmbedderRoute{
+			{Namespaces: []string{"code"}, Embedder: &errorEmbedder{}},
+		},
+	})
+	_ = disc.RegisterTool(makeTool("list_pods", "k8s", "List running pods", nil), makeBackend("k8s"), nil)
+	report = disc.SelfTest(ctx)
+	if report.Healthy {
+		t.Fatal("expected unhealthy report with a failing route embedder")
+	}
+	for _, c := range report.Checks {
+		switch c.Name {
+		case CheckEmbedder:
+			if c.Status != CheckOK || c.Detail != "4 dimensions" {
+				t.Errorf("default embedder check = %+v", c)
+			}
+		case CheckEmbedder + "/route-0":
+			if c.Status != CheckFailed || c.Error != "embedder error" {
+				t.Errorf("route embedder check = %+v", c)
+			}
+		case CheckDocStore:
+			if c.Status != CheckOK {
+				t.Errorf("doc store check = %+v", c)
+			}
+		}
+	}
+	if err := report.Err(); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("Err() = %v, want ErrSelfTestFailed", err)
+	}
+}
+
+// countingSearcher counts Search calls on a wrapped searcher.
+type countingSearcher struct {
+	index.Searcher
+	calls     int
+	lastLimit int
+}
+
+func (s *countingSearcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	s.calls++
+	s.lastLimit = limit
+	return s.Searcher.Search(query, limit, docs)
+}
+
+func TestDiscovery_ResultCache(t *testing.T) {
+	ctx := context.Background()
+	shared := cache.NewLRU()
+	searcher := &countingSearcher{Searcher: search.NewBM25Searcher(search.BM25Config{})}
+	disc, _ := New(Options{Searcher: searcher, ResultCache: shared})
+	_ = disc.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil)
+
+	first, err := disc.Search(ctx, "issue", 5)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("Search = %v, %v", first, err)
+	}
+	calls := searcher.calls
+	second, _ := disc.Search(ctx, "issue", 5)
+	if searcher.calls != calls || len(second) != 1 || second[0].Summary.ID != "github:create_issue" {
+		t.Fatalf("expected a cached result, got %v after %d searches", second, searcher.calls)
+	}
+
+	// Principals, limits, and aliases are part of the key.
+	_, _ = disc.SearchFor(ctx, "agent-1", "issue", 5)
+	if searcher.calls == calls {
+		t.Error("expected a principal to miss the cache")
+	}
+	calls = searcher.calls
+	_, _ = disc.Search(ctx, "issue", 1)
+	if searcher.calls == calls {
+		t.Error("expected a different limit to miss the cache")
+	}
+	_ = disc.RegisterAliases("github:create_issue", []string{"ticket"})
+	if results, _ := disc.Search(ctx, "ticket", 5); len(results) != 1 {
+		t.Errorf("expected alias to apply despite the cache, got %v", results)
+	}
+
+	// Index changes bump the version and miss the cache.
+	_ = disc.RegisterTool(makeTool("close_issue", "github", "Close an issue", nil), makeBackend("github"), nil)
+	if results, _ := disc.Search(ctx, "issue", 5); len(results) != 2 {
+		t.Errorf("expected fresh results after a change, got %v", results)
+	}
+}
+
+func TestDiscovery_ResultCache_SharedAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	shared := cache.NewLRU()
+	first, _ := New(Options{ResultCache: shared})
+	_ = first.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil)
+	if _, err := first.Search(ctx, "issue", 5); err != nil {
+		t.Fatalf("Search error = %v", err)
+	}
+
+	// The second replica reaches the same catalog through a different
+	// history, so its index version differs.
+	searcher := &countingSearcher{Searcher: search.NewBM25Searcher(search.BM25Config{})}
+	second, _ := New(Options{Searcher: searcher, ResultCache: shared})
+	_ = second.RegisterTool(makeTool("scratch", "tmp", "Scratch tool", nil), makeBackend("tmp"), nil)
+	_ = second.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil)
+	_ = second.UnregisterBackend("tmp:scratch", model.BackendKindMCP, "tmp")
+	if second.Version() == first.Version() {
+		t.Fatalf("versions both %d, want them to differ", first.Version())
+	}
+	calls := searcher.calls
+	results, err := second.Search(ctx, "issue", 5)
+	if err != nil || len(results) != 1 || searcher.calls != calls {
+		t.Fatalf("Search = %v, %v after %d searches; want a shared cache hit", results, err, searcher.calls)
+	}
+}
+
+func TestDiscovery_QueryCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	queries := NewQueryCache(QueryCacheOptions{MaxEntries: 2, TTL: time.Minute, Now: func() time.Time { return now }})
+	searcher := &countingSearcher{Searcher: search.NewBM25Searcher(search.BM25Config{})}
+	idx := index.NewInMemoryIndex(index.IndexOptions{Searcher: searcher})
+	disc, _ := New(Options{Index: idx, QueryCache: queries})
+	_ = idx.RegisterToolWithMetadata(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"),
+		map[string]string{"team": "dev", "tier": "1"})
+
+	first, err := disc.Search(ctx, "issue metadata.team=dev metadata.tier=1", 5)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("Search = %v, %v", first, err)
+	}
+	calls := searcher.calls
+	first[0].Summary.ID = "mutated"
+	second, _ := disc.Search(ctx, "issue metadata.tier=1 metadata.team=dev", 5)
+	if searcher.calls != calls || len(second) != 1 || second[0].Summary.ID != "github:create_issue" {
+		t.Fatalf("expected a cached copy regardless of filter order, got %v after %d searches", second, searcher.calls)
+	}
+	if stats := queries.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// Least recently used entries are evicted beyond MaxEntries.
+	_, _ = disc.Search(ctx, "open", 5)
+	_, _ = disc.Search(ctx, "new", 5)
+	if stats := queries.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("stats after eviction = %+v", stats)
+	}
+
+	// Entries expire after the TTL.
+	calls = searcher.calls
+	now = now.Add(time.Minute)
+	_, _ = disc.Search(ctx, "new", 5)
+	if searcher.calls == calls {
+		t.Error("expected an expired entry to miss the cache")
+	}
+
+	// Index changes and ranking model changes invalidate the cache.
+	invalidations := queries.Stats().Invalidations
+	_ = idx.RegisterTool(makeTool("close_issue", "github", "Close an issue", nil), makeBackend("github"))
+	if queries.Len() != 0 {
+		t.Fatalf("Len() after a change = %d, want 0", queries.Len())
+	}
+	if results, _ := disc.Search(ctx, "issue", 5); len(results) != 2 {
+		t.Errorf("expected fresh results after a change, got %v", results)
+	}
+	disc.SetRankingModel(nil)
+	if stats := queries.Stats(); stats.Invalidations != invalidations+2 || stats.Entries != 0 {
+		t.Errorf("stats after invalidation = %+v", stats)
+	}
+}
+
+// collectMetrics returns the metrics recorded by reader, by name.
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	out := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			out[m.Name] = m.Data
+		}
+	}
+	return out
+}
+
+func TestDiscovery_Telemetry(t *testing.T) {
+	ctx := context.Background()
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	disc, err := New(Options{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	_, _ = disc.Search(ctx, "issue", 5)
+	_, _ = disc.Search(ctx, "kubernetes", 5)
+	_, _ = disc.DescribeTool("github:missing", tooldoc.DetailSummary)
+
+	var names []string
+	for _, span := range spans.Ended() {
+		names = append(names, span.Name())
+	}
+	if got := strings.Join(names, ","); got != "discovery.RegisterTool,discovery.Search,discovery.Search,discovery.DescribeTool" {
+		t.Errorf("spans = %s", got)
+	}
+	if describe := spans.Ended()[3]; describe.Status().Code != codes.Error {
+		t.Errorf("DescribeTool span status = %v, want error", describe.Status())
+	}
+
+	metrics := collectMetrics(t, reader)
+	sum := func(name string) int64 {
+		var total int64
+		for _, dp := range metrics[name].(metricdata.Sum[int64]).DataPoints {
+			total += dp.Value
+		}
+		return total
+	}
+	if got := sum("discovery.search.requests"); got != 2 {
+		t.Errorf("discovery.search.requests = %d, want 2", got)
+	}
+	if got := sum("discovery.search.zero_results"); got != 1 {
+		t.Errorf("discovery.search.zero_results = %d, want 1", got)
+	}
+	if dps := metrics["discovery.search.duration"].(metricdata.Histogram[float64]).DataPoints; len(dps) != 1 || dps[0].Count != 2 {
+		t.Errorf("discovery.search.duration = %+v", dps)
+	}
+	if dps := metrics["discovery.search.results"].(metricdata.Histogram[int64]).DataPoints; len(dps) != 1 || dps[0].Sum != 1 {
+		t.Errorf("discovery.search.results = %+v", dps)
+	}
+}
+
+func TestDiscovery_CatalogLimits(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	disc, err := New(Options{
+		MaxToolsPerNamespace: 1,
+		MaxDocBytes:          64,
+		MeterProvider:        sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := disc.RegisterTool(makeTool("create_issue", "github", "Open a new issue", nil), makeBackend("github"), nil); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	err = disc.RegisterTool(makeTool("close_issue", "github", "Close an issue", nil), makeBackend("github"), nil)
+	if !errors.Is(err, index.ErrLimitExceeded) {
+		t.Fatalf("RegisterTool() error = %v, want ErrLimitExceeded", err)
+	}
+	err = disc.RegisterDoc("github:create_issue", tooldoc.DocEntry{Summary: strings.Repeat("x", 100)})
+	if !errors.Is(err, index.ErrLimitExceeded) {
+		t.Fatalf("RegisterDoc() error = %v, want ErrLimitExceeded", err)
+	}
+
+	got := make(map[string]int64)
+	for _, dp := range collectMetrics(t, reader)["discovery.registration.rejected"].(metricdata.Sum[int64]).DataPoints {
+		limit, _ := dp.Attributes.Value(AttrCatalogLimit)
+		got[limit.AsString()] = dp.Value
+	}
+	if got[index.LimitToolsPerNamespace] != 1 || got[index.LimitDocBytes] != 1 {
+		t.Errorf("discovery.registration.rejected = %v", got)
+	}
+}
+
+func TestDiscovery_Reranker(t *testing.T) {
+	ctx := context.Background()
+	var gotQuery string
+	var gotCandidates int
+	reverse := RerankerFunc(func(_ context.Context, query string, candidates []Result) ([]Result, error) {
+		gotQuery, gotCandidates = query, len(candidates)
+		out := []Result{{Summary: index.Summary{ID: "made:up"}, Score: 9}}
+		for i := len(candidates) - 1; i >= 0; i-- {
+			r := candidates[i]
+			r.Score = float64(len(candidates) - i)
+			out = append(out, r)
+		}
+		return out, nil
+	})
+	disc, err := New(Options{Reranker: reverse, RerankerTopK: 3})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, tool := range []model.Tool{
+		makeTool("create_issue", "github", "Create an issue issue issue", nil),
+		makeTool("close_issue", "github", "Close an issue issue", nil),
+		makeTool("list_issues", "github", "List issue", nil),
+		makeTool("comment", "github", "Comment on an issue", nil),
+	} {
+		if err := disc.RegisterTool(tool, makeBackend("github"), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+
+	plain, err := disc.SearchWithOptions(ctx, "issue", 4, WithRerank(0))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if gotCandidates != 0 || len(plain) != 4 || plain[0].ScoreType != ScoreBM25 {
+		t.Fatalf("WithRerank(0) reranked: candidates=%d results=%v", gotCandidates, plain.IDs())
+	}
+
+	results, err := disc.Search(ctx, "issue", 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if gotQuery != "issue" || gotCandidates != 3 {
+		t.Errorf("Rerank got query %q with %d candidates, want \"issue\" with 3", gotQuery, gotCandidates)
+	}
+	want := []string{plain[2].Summary.ID, plain[1].Summary.ID}
+	if strings.Join(results.IDs(), ",") != strings.Join(want, ",") {
+		t.Errorf("reranked IDs = %v, want %v", results.IDs(), want)
+	}
+	if results[0].ScoreType != ScoreRerank || results[0].Score != 1 {
+		t.Errorf("results[0] = %+v, want rerank score 1", results[0])
+	}
+
+	results, err = disc.SearchWithOptions(ctx, "issue", 4, WithRerank(2))
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	want = []string{plain[1].Summary.ID, plain[0].Summary.ID, plain[2].Summary.ID, plain[3].Summary.ID}
+	if strings.Join(results.IDs(), ",") != strings.Join(want, ",") || results[2].ScoreType != ScoreBM25 {
+		t.Errorf("WithRerank(2) IDs = %v, want %v", results.IDs(), want)
+	}
+
+	failing, _ := New(Options{Reranker: RerankerFunc(func(context.Context, string, []Result) ([]Result, error) {
+		return nil, errors.New("model unavailable")
+	})})
+	_ = failing.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+	if _, err := failing.Search(ctx, "issue", 5); err == nil || !strings.Contains(err.Error(), "model unavailable") {
+		t.Errorf("Search error = %v, want reranker error", err)
+	}
+}
+
+func TestDiscovery_FindDuplicates(t *testing.T) {
+	disc, _ := New(Options{})
+	for _, reg := range []struct {
+		tool   model.Tool
+		server string
+	}{
+		{makeTool("create_issue", "github", "Create a new issue in a repository", nil), "github"},
+		{makeTool("new_issue", "github", "Creates a new issue in the repository", nil), "github"},
+		{makeTool("create_issue", "gitea", "Create new issues in a repository", nil), "gitea"},
+		{makeTool("list_pods", "k8s", "List pods in a cluster namespace", nil), "k8s"},
+		{makeTool("get_weather", "weather", "Get the weather forecast", nil), "weather"},
+	} {
+		if err := disc.RegisterTool(reg.tool, makeBackend(reg.server), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+
+	groups, err := disc.FindDuplicates(0.8)
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+	// github:new_issue matches github:create_issue, but they share a server.
+	if len(groups) != 1 || strings.Join(groups[0].ToolIDs, ",") != "gitea:create_issue,github:create_issue,github:new_issue" {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if groups[0].Method != DuplicateJaccard || groups[0].Similarity != 1 {
+		t.Errorf("group = %+v, want jaccard similarity 1", groups[0])
+	}
+
+	for _, threshold := range []float64{0, -1, 1.5} {
+		if _, err := disc.FindDuplicates(threshold); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("FindDuplicates(%v) error = %v, want ErrInvalidThreshold", threshold, err)
+		}
+	}
+
+	hybrid, _ := New(Options{Embedder: &mockEmbedder{dim: 8}})
+	_ = hybrid.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+	_ = hybrid.RegisterTool(makeTool("create_issue", "gitea", "Create an issue", nil), makeBackend("gitea"), nil)
+	groups, err = hybrid.FindDuplicates(0.99)
+	if err != nil || len(groups) != 1 || groups[0].Method != DuplicateEmbedding {
+		t.Errorf("embedding FindDuplicates = %+v, %v", groups, err)
+	}
+}
+
+func TestDiscovery_MarkDuplicates(t *testing.T) {
+	ctx := context.Background()
+	disc, _ := New(Options{})
+	for _, reg := range []struct {
+		tool   model.Tool
+		server string
+	}{
+		{makeTool("create_issue", "github", "Create an issue in a repository", nil), "github"},
+		{makeTool("create_issue", "gitea", "Create an issue in a repository", nil), "gitea"},
+		{makeTool("create_issue", "mirror", "Create an issue in a repository", nil), "mirror"},
+		{makeTool("close_issue", "github", "Close an issue", nil), "github"},
+	} {
+		if err := disc.RegisterTool(reg.tool, makeBackend(reg.server), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+
+	groups, err := disc.FindDuplicates(0.9)
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("FindDuplicates = %+v, %v", groups, err)
+	}
+	if err := disc.MarkDuplicates(groups[0].ToolIDs...); err != nil {
+		t.Fatalf("MarkDuplicates failed: %v", err)
+	}
+	if got := disc.Duplicates("gitea:create_issue"); !slices.Equal(got, []string{"github:create_issue", "mirror:create_issue"}) {
+		t.Errorf("Duplicates = %v", got)
+	}
+
+	results, err := disc.Search(ctx, "issue", 2)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	i := slices.IndexFunc(results, func(r Result) bool { return r.Summary.Name == "create_issue" })
+	if len(results) != 2 || i < 0 || !slices.Contains(results.IDs(), "github:close_issue") {
+		t.Fatalf("results = %v, want one create_issue and github:close_issue", results.IDs())
+	}
+	want := disc.Duplicates(results[i].Summary.ID)
+	if !slices.Equal(results[i].AlternateIDs, want) {
+		t.Errorf("AlternateIDs = %v, want %v", results[i].AlternateIDs, want)
+	}
+
+	// Unmarking, or removing the tool, takes it out of the group.
+	disc.UnmarkDuplicate("mirror:create_issue")
+	results, _ = disc.Search(ctx, "create issue", 5)
+	if len(results) != 3 {
+		t.Errorf("after unmark: results = %v, want 3", results.IDs())
+	}
+	_ = disc.UnregisterBackend("gitea:create_issue", model.BackendKindMCP, "gitea")
+	if got := disc.Duplicates("github:create_issue"); got != nil {
+		t.Errorf("Duplicates after removal = %v, want none", got)
+	}
+
+	// An alias naming another tool's ID links them too.
+	_ = disc.RegisterAliases("github:create_issue", []string{"mirror:create_issue"})
+	results, _ = disc.Search(ctx, "create issue", 5)
+	if ids := results.IDs(); len(ids) != 2 || len(results[0].AlternateIDs) != 1 {
+		t.Errorf("alias-linked results = %v (alternates %v)", ids, results[0].AlternateIDs)
+	}
+
+	plain, _ := New(Options{DisableDeduplication: true})
+	_ = plain.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+	_ = plain.RegisterTool(makeTool("create_issue", "gitea", "Create an issue", nil), makeBackend("gitea"), nil)
+	_ = plain.MarkDuplicates("github:create_issue", "gitea:create_issue")
+	if results, _ := plain.Search(ctx, "issue", 5); len(results) != 2 {
+		t.Errorf("DisableDeduplication: results = %v, want both", results.IDs())
+	}
+}
+
+func TestDiscovery_DuplicateSlackCapped(t *testing.T) {
+	searcher := &countingSearcher{Searcher: search.NewBM25Searcher(search.BM25Config{})}
+	disc, _ := New(Options{Searcher: searcher})
+	var ids []string
+	for i := range 10 {
+		ns := fmt.Sprintf("mirror%d", i)
+		if err := disc.RegisterTool(makeTool("create_issue", ns, "Create an issue", nil), makeBackend(ns), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+		ids = append(ids, ns+":create_issue")
+	}
+	_ = disc.RegisterTool(makeTool("close_issue", "github", "Close an issue", nil), makeBackend("github"), nil)
+	if err := disc.MarkDuplicates(ids...); err != nil {
+		t.Fatalf("MarkDuplicates failed: %v", err)
+	}
+
+	results, err := disc.Search(context.Background(), "issue", 2)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Search = %v, %v", results.IDs(), err)
+	}
+	if searcher.lastLimit != 4 {
+		t.Errorf("retrieved %d results, want limit 2 plus slack capped at 2", searcher.lastLimit)
+	}
+}
+
+func TestDiscovery_Interceptors(t *testing.T) {
+	ctx := context.Background()
+	disc, _ := New(Options{})
+	_ = disc.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+	_ = disc.RegisterTool(makeTool("list_pods", "k8s", "List pods", nil), makeBackend("k8s"), nil)
+
+	var calls []string
+	disc.UseSearchInterceptor(
+		func(next SearchFunc) SearchFunc {
+			return func(ctx context.Context, principal, query string, limit int) (Results, error) {
+				calls = append(calls, "outer:"+query)
+				results, err := next(ctx, principal, query, limit)
+				calls = append(calls, fmt.Sprintf("outer:%d", len(results)))
+				return results, err
+			}
+		},
+		nil,
+		func(next SearchFunc) SearchFunc {
+			return func(ctx context.Context, principal, query string, limit int) (Results, error) {
+				calls = append(calls, "rewrite")
+				return next(ctx, principal, strings.ReplaceAll(query, "ticket", "issue"), limit)
+			}
+		},
+	)
+	results, err := disc.Search(ctx, "ticket", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Summary.ID != "github:create_issue" {
+		t.Errorf("Search(ticket) = %v, want rewritten query results", results.IDs())
+	}
+	if got := strings.Join(calls, ","); got != "outer:ticket,rewrite,outer:1" {
+		t.Errorf("interceptor calls = %s", got)
+	}
+
+	for name, page := range map[string]func(context.Context, string, int, string) (Results, string, error){
+		"SearchPage":           disc.SearchPage,
+		"SearchPageWithScores": disc.SearchPageWithScores,
+	} {
+		calls = nil
+		results, next, err := page(ctx, "ticket", 5, "")
+		if err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+		if len(results) != 1 || results[0].Summary.ID != "github:create_issue" || next != "" {
+			t.Errorf("%s(ticket) = %v, %q; want rewritten query results", name, results.IDs(), next)
+		}
+		if got := strings.Join(calls, ","); got != "outer:ticket,rewrite,outer:1" {
+			t.Errorf("%s interceptor calls = %s", name, got)
+		}
+	}
+
+	denied := errors.New("denied")
+	disc.UseDescribeInterceptor(func(next DescribeFunc) DescribeFunc {
+		return func(ctx context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error) {
+			if strings.HasPrefix(id, "k8s:") {
+				return tooldoc.ToolDoc{}, denied
+			}
+			return next(ctx, id, level)
+		}
+	})
+	if _, err := disc.DescribeTool("k8s:list_pods", tooldoc.DetailSummary); !errors.Is(err, denied) {
+		t.Errorf("DescribeTool(k8s) error = %v, want denied", err)
+	}
+	if _, err := disc.DescribeToolProfile("k8s:list_pods", tooldoc.DetailSchema, "compact"); !errors.Is(err, denied) {
+		t.Errorf("DescribeToolProfile(k8s) error = %v, want denied", err)
+	}
+	if doc, err := disc.DescribeTool("github:create_issue", tooldoc.DetailSummary); err != nil || doc.Summary == "" {
+		t.Errorf("DescribeTool(github) = %+v, %v", doc, err)
+	}
+}
+
+func TestDiscovery_QueryRewriter(t *testing.T) {
+	ctx := context.Background()
+	var seen []string
+	filler := QueryRewriterFunc(func(_ context.Context, query string) (string, error) {
+		seen = append(seen, query)
+		return strings.TrimSpace(strings.TrimPrefix(query, "please find")), nil
+	})
+	abbreviations := QueryRewriterFunc(func(_ context.Context, query string) (string, error) {
+		seen = append(seen, query)
+		return strings.ReplaceAll(query, "k8s", "kubernetes"), nil
+	})
+	disc, _ := New(Options{QueryRewriter: ChainQueryRewriters(filler, nil, abbreviations)})
+	_ = disc.RegisterTool(makeTool("list_pods", "cluster", "List kubernetes pods", nil), makeBackend("cluster"), nil)
+	_ = disc.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+
+	results, err := disc.Search(ctx, "please find k8s pods", 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Summary.ID != "cluster:list_pods" {
+		t.Fatalf("Search = %v, want cluster:list_pods", results.IDs())
+	}
+	if results[0].RewrittenQuery != "kubernetes pods" {
+		t.Errorf("RewrittenQuery = %q, want %q", results[0].RewrittenQuery, "kubernetes pods")
+	}
+	if got := strings.Join(seen, "|"); got != "please find k8s pods|k8s pods" {
+		t.Errorf("rewriter inputs = %s", got)
+	}
+
+	results, _ = disc.Search(ctx, "issue", 5)
+	if len(results) != 1 || results[0].RewrittenQuery != "" {
+		t.Errorf("unchanged query results = %+v, want no RewrittenQuery", results)
+	}
+
+	page, _, err := disc.SearchPage(ctx, "please find k8s", 5, "")
+	if err != nil {
+		t.Fatalf("SearchPage failed: %v", err)
+	}
+	if len(page) != 1 || page[0].RewrittenQuery != "kubernetes" {
+		t.Errorf("SearchPage = %+v, want rewritten kubernetes results", page)
+	}
+	page, _, err = disc.SearchPageWithScores(ctx, "please find k8s", 5, "")
+	if err != nil {
+		t.Fatalf("SearchPageWithScores failed: %v", err)
+	}
+	if len(page) != 1 || page[0].RewrittenQuery != "kubernetes" || page[0].Score == 0 {
+		t.Errorf("SearchPageWithScores = %+v, want scored rewritten kubernetes results", page)
+	}
+
+	failed := errors.New("translation unavailable")
+	disc, _ = New(Options{QueryRewriter: QueryRewriterFunc(func(context.Context, string) (string, error) {
+		return "", failed
+	})})
+	if _, err := disc.Search(ctx, "pods", 5); !errors.Is(err, failed) {
+		t.Errorf("Search error = %v, want rewriter error", err)
+	}
+	if _, _, err := disc.SearchPageWithScores(ctx, "pods", 5, ""); !errors.Is(err, failed) {
+		t.Errorf("SearchPageWithScores error = %v, want rewriter error", err)
+	}
+}
+
+func TestDiscovery_ExactMatchPinning(t *testing.T) {
+	ctx := context.Background()
+	register := func(disc *Discovery) {
+		t.Helper()
+		tools := []model.Tool{
+			makeTool("find", "web", "Search search search the web with a search engine search", []string{"search"}),
+			makeTool("lookup", "docs", "Search the docs search index", []string{"search"}),
+			makeTool("search", "github", "Query repositories", nil),
+			makeTool("search", "gitlab", "Query projects", nil),
+		}
+		for _, tool := range tools {
+			if err := disc.RegisterTool(tool, makeBackend(tool.Namespace), nil); err != nil {
+				t.Fatalf("RegisterTool failed: %v", err)
+			}
+		}
+	}
+	disc, _ := New(Options{})
+	register(disc)
+
+	results, err := disc.Search(ctx, "search", 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := strings.Join(results.IDs(), ","); !strings.HasPrefix(got, "github:search,gitlab:search,") {
+		t.Errorf("name query results = %s, want name matches first", got)
+	}
+	if !results[0].Pinned || !results[1].Pinned || results[2].Pinned {
+		t.Errorf("Pinned = %v %v %v", results[0].Pinned, results[1].Pinned, results[2].Pinned)
+	}
+
+	// An ID match comes first even when ranking cut it.
+	results, _ = disc.Search(ctx, "gitlab:search", 1)
+	if len(results) != 1 || results[0].Summary.ID != "gitlab:search" {
+		t.Errorf("ID query results = %v", results.IDs())
+	}
+
+	// Metadata filters still apply.
+	results, _ = disc.Search(ctx, "search metadata.team=none", 5)
+	if len(results) != 0 {
+		t.Errorf("filtered results = %v, want none", results.IDs())
+	}
+
+	unpinned, _ := New(Options{DisableExactMatchPinning: true})
+	register(unpinned)
+	results, _ = unpinned.Search(ctx, "search", 1)
+	if len(results) != 1 || results[0].Summary.ID != "web:find" || results[0].Pinned {
+		t.Errorf("unpinned results = %v", results)
+	}
+}
+
+// slowEmbedder delays every embedding, honoring cancellation.
+type slowEmbedder struct {
+	mockEmbedder
+	delay time.Duration
+}
+
+func (e *slowEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(e.delay):
+	}
+	return e.mockEmbedder.Embed(ctx, text)
+}
+
+func TestDiscovery_SearchDeadline(t *testing.T) {
+	ctx := context.Background()
+	embedder := &slowEmbedder{mockEmbedder: mockEmbedder{dim: 4}, delay: 200 * time.Microsecond}
+	disc, _ := New(Options{Embedder: embedder, SearchDeadline: 10 * time.Millisecond, QueryCache: NewQueryCache()})
+	for i := range 2*DeadlineChunkSize + 10 {
+		tool := makeTool(fmt.Sprintf("tool_%03d", i), "bulk", "Bulk tool", nil)
+		if err := disc.RegisterTool(tool, makeBackend("bulk"), nil); err != nil {
+			t.Fatalf("RegisterTool failed: %v", err)
+		}
+	}
+
+	start := time.Now()
+	results, err := disc.Search(ctx, "bulk tool", 1000)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if !results.Partial() || len(results) != DeadlineChunkSize {
+		t.Fatalf("results = %d partial=%v, want the first %d scored documents", len(results), results.Partial(), DeadlineChunkSize)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Search took %v", elapsed)
+	}
+	if again, _ := disc.Search(ctx, "bulk tool", 1000); !again.Partial() {
+		t.Error("partial results were cached and served as complete")
+	}
+
+	complete, _ := New(Options{Embedder: embedder})
+	for i := range 2*DeadlineChunkSize + 10 {
+		_ = complete.RegisterTool(makeTool(fmt.Sprintf("tool_%03d", i), "bulk", "Bulk tool", nil), makeBackend("bulk"), nil)
+	}
+	if results, _ := complete.Search(ctx, "bulk tool", 1000); results.Partial() || len(results) != 2*DeadlineChunkSize+10 {
+		t.Errorf("results without deadline = %d partial=%v", len(results), results.Partial())
+	}
+}
+
+// pushdownIndex records pushed-down queries and answers them from memory,
+// declining those marked unsupported.
+type pushdownIndex struct {
+	*index.InMemoryIndex
+	queries []index.PushdownQuery
+}
+
+func (p *pushdownIndex) PushdownSearch(_ context.Context, q index.PushdownQuery) ([]index.PushdownResult, error) {
+	p.queries = append(p.queries, q)
+	if q.Text == "unsupported" {
+		return nil, index.ErrPushdownUnsupported
+	}
+	var out []index.PushdownResult
+	for i, s := range p.SummariesFor(q.Principal, p.ToolIDs()) {
+		if len(out) < q.Limit && index.MatchesMetadata(s.Metadata, q.Filters) {
+			out = append(out, index.PushdownResult{Summary: s, Score: float64(10 - i)})
+		}
+	}
+	return out, nil
+}
+
+func TestDiscovery_SearchPushdown(t *testing.T) {
+	ctx := context.Background()
+	searcher := &countingSearcher{Searcher: search.NewBM25Searcher(search.BM25Config{})}
+	idx := &pushdownIndex{InMemoryIndex: index.NewInMemoryIndex(index.IndexOptions{Searcher: searcher})}
+	disc, _ := New(Options{Index: idx})
+	_ = idx.RegisterToolWithMetadata(makeTool("create_issue", "github", "Open an issue", nil), makeBackend("github"), map[string]string{"team": "dev"})
+	_ = idx.RegisterTool(makeTool("list_pods", "k8s", "List pods", nil), makeBackend("k8s"))
+	baseline := searcher.calls // the change journal snapshots the index once
+
+	results, err := disc.SearchFor(ctx, "agent-1", "issue metadata.team=dev", 5)
+	if err != nil || len(results) != 1 || results[0].ScoreType != ScorePushdown || results[0].Score != 10 {
+		t.Fatalf("pushdown results = %v, %v", results, err)
+	}
+	q := idx.queries[0]
+	if q.Principal != "agent-1" || q.Text != "issue" || q.Filters["team"] != "dev" || q.Limit != 5 || q.Vector != nil {
+		t.Errorf("pushed-down query = %+v", q)
+	}
+	if searcher.calls != baseline {
+		t.Errorf("in-process searcher ran %d times despite pushdown", searcher.calls-baseline)
+	}
+
+	// Unsupported queries fall back to in-process ranking.
+	if _, err := disc.Search(ctx, "unsupported", 5); err != nil || searcher.calls == baseline {
+		t.Errorf("fallback err = %v, searcher calls = %d", err, searcher.calls)
+	}
+
+	disabled, _ := New(Options{Index: idx, DisableSearchPushdown: true})
+	pushed := len(idx.queries)
+	_, _ = disabled.Search(ctx, "issue", 5)
+	if len(idx.queries) != pushed {
+		t.Error("DisableSearchPushdown still pushed the query down")
+	}
+
+	// Hybrid discovery sends the embedded query.
+	hybrid, _ := New(Options{Index: idx, Embedder: &mockEmbedder{dim: 8}})
+	_, _ = hybrid.Search(ctx, "pods", 5)
+	if q := idx.queries[len(idx.queries)-1]; len(q.Vector) != 8 {
+		t.Errorf("hybrid pushdown vector = %v", q.Vector)
+	}
+
+	// A declined hybrid query falls back without embedding it again.
+	for name, opts := range map[string]func(semantic.Embedder) Options{
+		"embedder": func(e semantic.Embedder) Options { return Options{Index: idx, Embedder: e} },
+		"vector index": func(e semantic.Embedder) Options {
+			vi, _ := semantic.NewVectorIndex(e, "v1")
+			return Options{Index: idx, VectorIndex: vi}
+		},
+	} {
+		embedder := &recordingEmbedder{}
+		fallback, _ := New(opts(embedder))
+		if _, err := fallback.Search(ctx, "unsupported", 5); err != nil {
+			t.Fatalf("%s: fallback err = %v", name, err)
+		}
+		n := 0
+		for _, text := range embedder.texts {
+			if text == "unsupported" {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%s: query embedded %d times, want once", name, n)
+		}
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	ctx := context.Background()
+	src, _ := New(Options{})
+	dst, _ := New(Options{})
+	_ = src.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), &tooldoc.DocEntry{
+		Summary:  "Creates issues",
+		Examples: []tooldoc.ToolExample{{Title: "bug", Args: map[string]any{"title": "crash"}}},
+	})
+	_ = src.Index().(*index.InMemoryIndex).RegisterToolWithMetadata(makeTool("list_pods", "k8s", "List pods", nil), makeBackend("k8s"), map[string]string{"team": "ops"})
+
+	var reported []string
+	replica, err := Replicate(ctx, src, dst, ReplicateOptions{OnError: func(id string, err error) { reported = append(reported, id) }})
+	if err != nil {
+		t.Fatalf("Replicate failed: %v", err)
+	}
+	defer func() { _ = replica.Close() }()
+
+	// The initial copy is applied before Replicate returns.
+	doc, err := dst.DocStore().GetDoc("github:create_issue")
+	if err != nil || doc.Summary != "Creates issues" || len(doc.Examples) != 1 {
+		t.Fatalf("replicated doc = %+v, err = %v", doc, err)
+	}
+	if metadata, _ := dst.Index().(*index.InMemoryIndex).GetMetadata("k8s:list_pods"); metadata["team"] != "ops" {
+		t.Errorf("replicated metadata = %v", metadata)
+	}
+
+	// Later changes stream to the target.
+	_ = src.RegisterExamples("github:create_issue", []tooldoc.ToolExample{{Title: "feature", Args: map[string]any{}}})
+	_ = src.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github-mirror"), nil)
+	_ = src.UnregisterBackend("github:create_issue", model.BackendKindMCP, "github")
+	_ = src.UnregisterBackend("k8s:list_pods", model.BackendKindMCP, "k8s")
+	if err := replica.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	backends, _ := dst.GetAllBackends("github:create_issue")
+	if len(backends) != 1 || backends[0].MCP.ServerName != "github-mirror" {
+		t.Errorf("replicated backends = %+v, want github-mirror only", backends)
+	}
+	want, _ := src.ListExamples("github:create_issue", 10)
+	if examples, _ := dst.ListExamples("github:create_issue", 10); !reflect.DeepEqual(examples, want) || examples[0].Title != "feature" {
+		t.Errorf("replicated examples = %+v, want %+v", examples, want)
+	}
+	if _, _, err := dst.GetTool("k8s:list_pods"); !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("removed tool still replicated: %v", err)
+	}
+	if len(reported) != 0 {
+		t.Errorf("unexpected replication errors for %v", reported)
+	}
+
+	// After Close the target stops following the source.
+	_ = replica.Close()
+	_ = src.RegisterTool(makeTool("get_pods", "k8s", "Get pods", nil), makeBackend("k8s"), nil)
+	time.Sleep(10 * time.Millisecond)
+	if _, _, err := dst.GetTool("k8s:get_pods"); !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("change replicated after Close: %v", err)
+	}
+
+	if _, err := Replicate(ctx, src, src); !errors.Is(err, ErrInvalidReplica) {
+		t.Errorf("self-replication err = %v, want ErrInvalidReplica", err)
+	}
+}
+
+// flakyTarget fails RegisterTools while failing is set.
+type flakyTarget struct {
+	*Discovery
+	failing bool
+}
+
+func (f *flakyTarget) RegisterTools(regs []index.ToolRegistration) error {
+	if f.failing {
+		return errors.New("target unavailable")
+	}
+	return f.Discovery.RegisterTools(regs)
+}
+
+func TestReplicate_RetriesFailedChanges(t *testing.T) {
+	ctx := context.Background()
+	src, _ := New(Options{})
+	dst, _ := New(Options{})
+	target := &flakyTarget{Discovery: dst}
+	replica, err := Replicate(ctx, src, target)
+	if err != nil {
+		t.Fatalf("Replicate failed: %v", err)
+	}
+	defer func() { _ = replica.Close() }()
+
+	replica.syncMu.Lock() // hold off the background flush
+	target.failing = true
+	_ = src.RegisterTool(makeTool("get_pods", "k8s", "Get pods", nil), makeBackend("k8s"), nil)
+	replica.syncMu.Unlock()
+	if err := replica.Flush(ctx); err == nil {
+		t.Fatal("Flush succeeded with a failing target")
+	}
+
+	replica.syncMu.Lock()
+	target.failing = false
+	replica.syncMu.Unlock()
+	if err := replica.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if _, _, err := dst.GetTool("k8s:get_pods"); err != nil {
+		t.Errorf("failed change not retried: %v", err)
+	}
+}
+
+func TestReplicate_Background(t *testing.T) {
+	src, _ := New(Options{})
+	dst, _ := New(Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	replica, err := Replicate(ctx, src, dst)
+	if err != nil {
+		t.Fatalf("Replicate failed: %v", err)
+	}
+	defer func() { _ = replica.Close() }()
+
+	_ = src.RegisterTool(makeTool("get_pods", "k8s", "Get pods", nil), makeBackend("k8s"), nil)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, _, err := dst.GetTool("k8s:get_pods"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("change not replicated in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServeAdmin(t *testing.T) {
+	disc, _ := New(Options{Index: index.NewInMemoryIndex(index.IndexOptions{TrackToolVersions: true})})
+	_ = disc.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("github"), nil)
+	denied := errors.New("not an admin")
+	server := httptest.NewServer(ServeAdmin(disc, AdminOptions{Authorize: func(r *http.Request) error {
+		if r.Header.Get("X-Admin") == "" {
+			return denied
+		}
+		return nil
+	}}))
+	defer server.Close()
+
+	do := func(method, path, ifMatch, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("X-Admin", "yes")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/tools/github:create_issue", nil)
+	if resp, _ := http.DefaultClient.Do(req); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unauthorized status = %d, want 403", resp.StatusCode)
+	}
+
+	// Two admins read the same version; the second write loses.
+	resp := do(http.MethodGet, "/tools/github:create_issue", "", "")
+	tag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || tag == "" {
+		t.Fatalf("GET tool status = %d, ETag = %q", resp.StatusCode, tag)
+	}
+	update := `{"tool":{"name":"create_issue","namespace":"github","description":"Open an issue","inputSchema":{"type":"object"}},` +
+		`"backend":{"kind":"mcp","mcp":{"serverName":"github"}},"metadata":{"team":"dev"},"doc":{"summary":"Opens issues"}}`
+	if resp := do(http.MethodPut, "/tools/github:create_issue", tag, update); resp.StatusCode != http.StatusOK {
+		t.Fatalf("first PUT status = %d", resp.StatusCode)
+	}
+	resp = do(http.MethodPut, "/tools/github:create_issue", tag, update)
+	if resp.StatusCode != http.StatusPreconditionFailed || resp.Header.Get("ETag") == tag {
+		t.Errorf("stale PUT status = %d, ETag = %q", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+	if tool, _, _ := disc.GetTool("github:create_issue"); tool.Description != "Open an issue" {
+		t.Errorf("description = %q", tool.Description)
+	}
+
+	// Docs are tagged by content.
+	resp = do(http.MethodGet, "/docs/github:create_issue", "", "")
+	docTag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET doc status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodPatch, "/docs/github:create_issue", docTag, `{"notes":"Requires repo scope"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH doc status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodPut, "/docs/github:create_issue", docTag, `{"summary":"stale"}`); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale doc PUT status = %d, want 412", resp.StatusCode)
+	}
+	if doc, _ := disc.DocStore().GetDoc("github:create_issue"); doc.Summary != "Opens issues" || doc.Notes != "Requires repo scope" {
+		t.Errorf("doc = %+v", doc)
+	}
+
+	if resp := do(http.MethodPut, "/aliases/github:create_issue", "", `["new ticket"]`); resp.StatusCode != http.StatusOK {
+		t.Errorf("PUT aliases status = %d", resp.StatusCode)
+	}
+	if aliases := disc.Aliases("github:create_issue"); len(aliases) != 1 {
+		t.Errorf("aliases = %v", aliases)
+	}
+	if resp := do(http.MethodPut, "/rollouts/github:create_issue", "", `{"percent":10}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("PUT rollout status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodDelete, "/rollouts/github:create_issue", `"v0"`, ""); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale rollout DELETE status = %d, want 412", resp.StatusCode)
+	}
+	if resp := do(http.MethodDelete, "/tools/github:create_issue/backends/mcp/github", "", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE backend status = %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodGet, "/tools/github:create_issue", "", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET removed tool status = %d, want 404", resp.StatusCode)
+	}
+
+	// Without Authorize every request is rejected.
+	closed := httptest.NewServer(ServeAdmin(disc, AdminOptions{}))
+	defer closed.Close()
+	if resp, _ := http.Get(closed.URL + "/aliases/github:create_issue"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unconfigured status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestDiscovery_RecordFeedback(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d, err := New(Options{Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := d.RecordImpressions("q1", "a:create", "a:list"); err != nil {
+		t.Fatalf("RecordImpressions failed: %v", err)
+	}
+	_ = d.RecordImpressions("q1", "a:create", "a:list")
+	_ = d.RecordImpressions("q2", "a:list")
+	for _, signal := range []FeedbackSignal{FeedbackClicked, FeedbackSelected, FeedbackExecuted} {
+		if err := d.RecordFeedback("q1", "a:create", signal); err != nil {
+			t.Fatalf("RecordFeedback(%s) failed: %v", signal, err)
+		}
+	}
+	_ = d.RecordFeedback("q1", "a:list", FeedbackRejected)
+
+	if err := d.RecordFeedback("q1", "a:create", FeedbackSignal("liked")); !errors.Is(err, ErrInvalidFeedback) {
+		t.Errorf("unknown signal err = %v", err)
+	}
+	if err := d.RecordFeedback("", "a:create", FeedbackClicked); !errors.Is(err, ErrInvalidFeedback) {
+		t.Errorf("missing query ID err = %v", err)
+	}
+
+	stats, err := d.FeedbackStats("q1")
+	if err != nil || len(stats) != 2 {
+		t.Fatalf("FeedbackStats(q1) = %+v, %v", stats, err)
+	}
+	create, list := stats[0], stats[1]
+	if create.ToolID != "a:create" || create.Impressions != 2 || create.Executed != 1 || !create.LastSeen.Equal(now) {
+		t.Errorf("create stats = %+v", create)
+	}
+	if create.ClickThroughRate() != 0.5 || create.SelectionRate() != 0.5 {
+		t.Errorf("create rates = %v, %v, want 0.5", create.ClickThroughRate(), create.SelectionRate())
+	}
+	if list.RejectionRate() != 0.5 || list.ClickThroughRate() != 0 {
+		t.Errorf("list stats = %+v", list)
+	}
+	if all, _ := d.FeedbackStats(""); len(all) != 3 {
+		t.Errorf("FeedbackStats(\"\") = %+v, want 3 pairs", all)
+	}
+}
+
+func TestDiscovery_RankingModel(t *testing.T) {
+	d, err := New(Options{
+		RankingModel:    LinearModel{Weights: map[string]float64{"bm25": 1, "namespace_boost": 10}},
+		NamespaceBoosts: map[string]float64{"slack": 1},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_ = d.RegisterTool(makeTool("send_message", "github", "Send a message to a pull request", nil), makeBackend("gh"), nil)
+	_ = d.RegisterTool(makeTool("send_message", "slack", "Send a message to a channel", nil), makeBackend("slack"), nil)
+
+	results, err := d.Search(context.Background(), "send message", 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Summary.ID != "slack:send_message" || results[0].ScoreType != ScoreLTR {
+		t.Fatalf("reranked results = %+v, want boosted slack tool", results)
+	}
+
+	d.SetRankingModel(nil)
+	results, _ = d.Search(context.Background(), "send message", 2)
+	if len(results) != 2 || results[0].ScoreType == ScoreLTR {
+		t.Errorf("results without model = %+v", results)
+	}
+}
+
+// feedbackOnly hides ToolFeedbackReader, so popularity falls back to a scan.
+type feedbackOnly struct{ FeedbackStore }
+
+func TestDiscovery_RankingFeatures_ReuseAndCache(t *testing.T) {
+	ctx := context.Background()
+	embedder := &recordingEmbedder{}
+	shared := cache.NewLRU()
+	feedback := NewInMemoryFeedbackStore()
+	d, err := New(Options{
+		Embedder:      embedder,
+		RankingModel:  LinearModel{Weights: map[string]float64{"semantic": 1, "popularity": 1}},
+		FeedbackStore: feedbackOnly{feedback},
+		ResultCache:   shared,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_ = d.RegisterTool(makeTool("send_message", "github", "Send a message to a pull request", nil), makeBackend("gh"), nil)
+	_ = d.RegisterTool(makeTool("send_message", "slack", "Send a message to a channel", nil), makeBackend("slack"), nil)
+	_ = d.RecordFeedback("q1", "slack:send_message", FeedbackExecuted)
+	_ = d.RecordFeedback("q2", "other:tool", FeedbackExecuted)
+
+	results, err := d.Search(ctx, "send message", 2)
+	if err != nil || len(results) != 2 || results[0].Summary.ID != "slack:send_message" {
+		t.Fatalf("Search = %+v, %v; want popular slack tool first", results, err)
+	}
+	queries := 0
+	for _, text := range embedder.texts {
+		if text == "send message" {
+			queries++
+		}
+	}
+	if queries != 1 {
+		t.Errorf("query embedded %d times, want once", queries)
+	}
+
+	// Only the results' feedback is read from a ToolFeedbackReader.
+	if stats, _ := feedback.ToolFeedbackStats([]string{"slack:send_message", "slack:send_message"}); len(stats) != 1 || stats[0].QueryID != "q1" {
+		t.Errorf("ToolFeedbackStats = %+v, want the q1 pair", stats)
+	}
+
+	// A new ranking model misses results cached under the old one.
+	d.SetRankingModel(LinearModel{Weights: map[string]float64{"semantic": -1}})
+	reranked, _ := d.Search(ctx, "send message", 2)
+	if len(reranked) != 2 || reranked[0].Score == results[0].Score {
+		t.Errorf("results after SetRankingModel = %+v, want re-scored", reranked)
+	}
+}
+
+func TestParseRankingModel(t *testing.T) {
+	model, err := ParseRankingModel([]byte(`{"type":"gbdt","baseScore":0.5,"trees":[{"nodes":[
+		{"feature":"popularity","threshold":1,"left":1,"right":2},
+		{"leaf":-1},
+		{"leaf":2}]}]}`))
+	if err != nil {
+		t.Fatalf("ParseRankingModel failed: %v", err)
+	}
+	if got := model.Score(RankingFeatures{Popularity: 0.5}); got != -0.5 {
+		t.Errorf("left score = %v, want -0.5", got)
+	}
+	if got := model.Score(RankingFeatures{Popularity: 3}); got != 2.5 {
+		t.Errorf("right score = %v, want 2.5", got)
+	}
+
+	linear, err := ParseRankingModel([]byte(`{"type":"linear","bias":1,"weights":{"recency":2}}`))
+	if err != nil || linear.Score(RankingFeatures{Recency: 0.5}) != 2 {
+		t.Errorf("linear model = %v, %v", linear, err)
+	}
+
+	for _, bad := range []string{
+		`{"type":"forest"}`,
+		`{"type":"linear","weights":{"clicks":1}}`,
+		`{"type":"gbdt","trees":[{"nodes":[{"feature":"bm25","left":0,"right":0}]}]}`,
+		`not json`,
+	} {
+		if _, err := ParseRankingModel([]byte(bad)); !errors.Is(err, ErrInvalidRankingModel) {
+			t.Errorf("ParseRankingModel(%s) err = %v", bad, err)
+		}
+	}
+}
+
+func TestDiscovery_ExportRankingData(t *testing.T) {
+	now := time.Unix(1000, 0)
+	d, _ := New(Options{Now: func() time.Time { return now }})
+	_ = d.RegisterTool(makeTool("create_issue", "github", "Create an issue", nil), makeBackend("gh"), nil)
+	_ = d.RegisterTool(makeTool("close_issue", "github", "Close an issue", nil), makeBackend("gh"), nil)
+	_ = d.RecordImpressions("q1", "github:create_issue", "github:close_issue")
+	_ = d.RecordFeedback("q1", "github:create_issue", FeedbackExecuted)
+	_ = d.RecordFeedback("q1", "github:close_issue", FeedbackRejected)
+
+	examples, err := d.ExportRankingData(context.Background(), map[string]string{"q1": "issue"}, 10)
+	if err != nil {
+		t.Fatalf("ExportRankingData failed: %v", err)
+	}
+	labels := map[string]int{}
+	for _, ex := range examples {
+		labels[ex.ToolID] = ex.Label
+	}
+	if len(examples) != 2 || labels["github:create_issue"] != 3 || labels["github:close_issue"] != 0 {
+		t.Fatalf("examples = %+v", examples)
+	}
+	for _, ex := range examples {
+		if ex.Features.Recency != 1 || ex.Features.BM25 <= 0 {
+			t.Errorf("%s features = %+v", ex.ToolID, ex.Features)
+		}
+		if ex.ToolID == "github:create_issue" && ex.Features.Popularity == 0 {
+			t.Errorf("executed tool has no popularity: %+v", ex.Features)
+		}
+	}
+
+	var out strings.Builder
+	if err := WriteRankingData(&out, examples); err != nil {
+		t.Fatalf("WriteRankingData failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], " qid:1 1:") || !strings.HasSuffix(lines[0], "# q1 "+examples[0].ToolID) {
+		t.Errorf("SVMlight output = %q", out.String())
+	}
+}
+
+func TestDiscovery_BooleanQueries(t *testing.T) {
+	ctx := context.Background()
+	bm25 := search.BM25Config{BooleanQueries: true}
+	for _, opts := range []Options{{BM25Config: bm25}, {BM25Config: bm25, Embedder: &mockEmbedder{dim: 8}}} {
+		disc, err := New(opts)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		_ = disc.RegisterTool(makeTool("commit", "git", "Record changes", []string{"vcs"}), makeBackend("git"), nil)
+		_ = disc.RegisterTool(makeTool("legacy_commit", "git", "Old commit helper", []string{"vcs", "deprecated"}), makeBackend("git"), nil)
+		_ = disc.RegisterTool(makeTool("commit", "svn", "Record changes", []string{"vcs"}), makeBackend("svn"), nil)
+
+		hybrid := opts.Embedder != nil
+		results, err := disc.Search(ctx, "namespace:git NOT tag:deprecated commit", 10)
+		if err != nil {
+			t.Fatalf("hybrid=%v: Search failed: %v", hybrid, err)
+		}
+		if got := results.IDs(); !slices.Equal(got, []string{"git:commit"}) {
+			t.Errorf("hybrid=%v: results = %v, want git:commit", hybrid, got)
+		}
+		if _, err := disc.Search(ctx, "commit AND", 10); !errors.Is(err, semantic.ErrInvalidQuery) {
+			t.Errorf("hybrid=%v: Search(invalid) error = %v, want ErrInvalidQuery", hybrid, err)
+		}
+	}
+
+	if _, err := New(Options{BM25Config: bm25, Searcher: search.NewBM25Searcher(search.BM25Config{})}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("New with BooleanQueries and a custom searcher error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestDiscovery_CategoryFilter(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range []Options{{}, {Embedder: &mockEmbedder{dim: 8}}} {
+		disc, err := New(opts)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		for name, category := range map[string]string{
+			"pods":    "devops/containers",
+			"alerts":  "devops/monitoring",
+			"billing": "finance",
+		} {
+			tool := makeTool(name, "ops", "Manage "+name, nil)
+			tool.Meta = mcp.Meta{"category": category}
+			if err := disc.RegisterTool(tool, makeBackend("ops"), nil); err != nil {
+				t.Fatalf("RegisterTool failed: %v", err)
+			}
+		}
+
+		results, err := disc.Search(ctx, "manage category=devops", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		ids := results.IDs()
+		slices.Sort(ids)
+		if got := strings.Join(ids, ","); got != "ops:alerts,ops:pods" {
+			t.Errorf("hybrid=%v: results = %s, want devops subtree", opts.Embedder != nil, got)
+		}
+
+		// Exact-match pinning respects the category.
+		results, _ = disc.Search(ctx, "ops:billing category=devops", 10)
+		for _, r := range results {
+			if r.Summary.ID == "ops:billing" {
+				t.Errorf("hybrid=%v: pinned ops:billing outside category", opts.Embedder != nil)
+			}
+		}
+	}
+}
+
+func TestDiscovery_HintFilter(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range []Options{{}, {Embedder: &mockEmbedder{dim: 8}}} {
+		disc, err := New(opts)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		readOnly := makeTool("list_branches", "git", "Manage branches", nil)
+		readOnly.Annotations = &mcp.ToolAnnotations{ReadOnlyHint: true}
+		destructive := makeTool("delete_branch", "git", "Manage branches", nil)
+		destructive.Annotations = &mcp.ToolAnnotations{}
+		for _, tool := range []model.Tool{readOnly, destructive, makeTool("merge_branch", "git", "Manage branches", nil)} {
+			if err := disc.RegisterTool(tool, makeBackend("git"), nil); err != nil {
+				t.Fatalf("RegisterTool failed: %v", err)
+			}
+		}
+
+		results, err := disc.Search(ctx, "branches hint.destructive=false", 10)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if got := strings.Join(results.IDs(), ","); got != "git:list_branches" {
+			t.Errorf("hybrid=%v: results = %s, want only the read-only tool", opts.Embedder != nil, got)
+		}
+		if len(results) == 1 && (results[0].Summary.Hints == nil || !results[0].Summary.Hints.ReadOnly) {
+			t.Errorf("hybrid=%v: Hints = %+v", opts.Embedder != nil, results[0].Summary.Hints)
+		}
+
+		// Exact-match pinning respects hint filters.
+		results, _ = disc.Search(ctx, "git:delete_branch hint.destructive=false", 10)
+		if slices.Contains(results.IDs(), "git:delete_branch") {
+			t.Errorf("hybrid=%v: pinned destructive git:delete_branch", opts.Embedder != nil)
+		}
+	}
+}
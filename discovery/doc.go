@@ -26,6 +26,13 @@
 //	// Get progressive documentation
 //	doc, err := disc.DescribeTool("github:create-issue", tooldoc.DetailFull)
 //
+// Removal mirrors registration. UnregisterBackend drops a backend and, with
+// the last one, the tool's documentation and cached embedding; RemoveDoc and
+// RemoveExamples edit documentation without touching the index:
+//
+//	err = disc.RemoveExamples("github:create-issue", []string{"create-bug"})
+//	err = disc.UnregisterBackend("github:create-issue", model.BackendKindMCP, "github")
+//
 // # Hybrid Search
 //
 // Enable hybrid search by providing an embedder:
@@ -35,6 +42,90 @@
 //	    HybridAlpha: 0.7,         // 70% BM25, 30% semantic
 //	})
 //
+// EmbedderRoutes sends selected namespaces or tags to a specialized embedder,
+// with Embedder as the fallback:
+//
+//	disc, err := discovery.New(discovery.Options{
+//	    Embedder: generalModel,
+//	    EmbedderRoutes: []discovery.EmbedderRoute{
+//	        {Namespaces: []string{"git", "github"}, Embedder: codeModel},
+//	    },
+//	})
+//
+// BM25 and embedding scores live on different scales, so a weighted sum can
+// be dominated by one signal. HybridFusion: FusionRRF merges the two rankings
+// by reciprocal rank instead, with HybridAlpha weighting each list:
+//
+//	disc, err := discovery.New(discovery.Options{
+//	    Embedder:     myEmbedder,
+//	    HybridFusion: discovery.FusionRRF,
+//	})
+//
+// Without a vector index, hybrid search embeds every document on every
+// query. VectorIndex caches document embeddings so a query costs one Embed
+// call; vectors of removed tools are dropped on index change events, and
+// WarmEmbeddings precomputes the rest:
+//
+//	vi, _ := semantic.NewVectorIndex(myEmbedder, "text-embed-v1")
+//	disc, err := discovery.New(discovery.Options{VectorIndex: vi})
+//	_ = disc.WarmEmbeddings(ctx)
+//
+// # Synonyms and Aliases
+//
+// BM25Config.Synonyms expands abbreviations in every query, for BM25 and
+// hybrid search alike. They need the BM25 searcher New creates or hybrid
+// search: with a custom Index or Searcher ranking results, New returns
+// ErrInvalidOptions. RegisterAliases maps tool-specific nicknames to a
+// tool, expanding matching queries with its namespace and name:
+//
+//	disc, _ := discovery.New(discovery.Options{
+//	    BM25Config: search.BM25Config{Synonyms: search.Synonyms{"k8s": {"kubernetes"}}},
+//	})
+//	_ = disc.RegisterAliases("github:create_issue", []string{"ticket", "new bug"})
+//
+// BM25Config.BooleanQueries likewise applies to BM25 and hybrid search,
+// parsing every query with semantic.ParseQuery so field prefixes and
+// operators filter results ("namespace:git NOT tag:deprecated commit").
+//
+// # Search Deadline
+//
+// Options.SearchDeadline bounds hybrid scoring time on large catalogs.
+// Documents are scored in chunks of DeadlineChunkSize; when the budget runs
+// out, Search returns the best results among the documents scored so far,
+// each marked Result.Partial (see Results.Partial), rather than failing or
+// waiting for slow embedders. Partial results are never cached.
+//
+//	disc, _ := discovery.New(discovery.Options{
+//	    Embedder:       embedder,
+//	    SearchDeadline: 200 * time.Millisecond,
+//	})
+//
+// # Scored Pagination
+//
+// SearchPage pages through the index's own searcher and reports Score 0.
+// SearchPageWithScores returns scores on every page, from the hybrid
+// searcher or from an index searcher that reports them (the default BM25
+// searcher does), with a cursor over the scored ordering that becomes
+// invalid (index.ErrInvalidCursor) when the index changes:
+//
+//	page, next, err := disc.SearchPageWithScores(ctx, "create issue", 20, "")
+//	// pass next as cursor for the following page
+//
+// # Exact-Match Pinning
+//
+// When a query's text is exactly a tool ID ("github:create_issue") or a tool
+// name ("create_issue"), Search returns the matching tools first, marked
+// Result.Pinned, even if fuzzier matches score higher or ranking cut them.
+// Set Options.DisableExactMatchPinning to rank purely by score.
+//
+// # Default Ranking
+//
+// When New creates the index, it ranks with Options.Searcher, or a
+// BM25Searcher built from Options.BM25Config, rather than the index's
+// built-in lexical searcher. Releases before this wiring ignored both, so
+// upgrading changes the order of default results; pass
+// index.NewInMemoryIndex() as Options.Index to keep the old ranking.
+//
 // # Components
 //
 // The Discovery facade integrates:
@@ -43,6 +134,240 @@
 //   - semantic.Strategy: Embedding-based semantic search (optional)
 //   - tooldoc.Store: Progressive documentation
 //
+// # Change History
+//
+// When the index implements index.ChangeNotifier, Discovery journals every
+// change (bounded by Options.ChangeJournalSize). Changes and ChangesSince
+// collapse the journal into a Changelog of tools added, removed, and updated
+// since a time or index version, with before/after diffs of description,
+// schemas, and tags:
+//
+//	log := disc.Changes(time.Now().Add(-24 * time.Hour))
+//	for _, c := range log.Updated {
+//	    fmt.Println(c.ToolID, c.Diffs)
+//	}
+//
+// AsOf and AsOfVersion replay the journal backwards into a read-only
+// HistoricalView that answers Search, GetTool, and DescribeTool against the
+// catalog, metadata, and documentation as they were, to reproduce what an
+// agent saw during incident review:
+//
+//	view, err := disc.AsOf(decisionTime)
+//	results, err := view.Search(ctx, "delete branch", 5)
+//
+// They return ErrHistoryUnavailable for states before the journal started or
+// whose later changes were evicted.
+//
+// # Replication
+//
+// Replicate mirrors a Discovery's tools, metadata, documentation, and
+// examples into a warm standby or read replica and keeps it in sync from
+// the source's change notifications:
+//
+//	replica, err := discovery.Replicate(ctx, primary, standby)
+//	defer replica.Close()
+//
+// The target is any ReplicaTarget; *Discovery is one, and a client for a
+// remote registration API can be another. Flush waits for pending changes
+// and Resync re-copies everything; ReplicateOptions.ResyncInterval does so
+// periodically to repair changes that failed to apply.
+//
+// # Admin API
+//
+// ServeAdmin exposes HTTP endpoints for registering and unregistering
+// tools, editing docs, and managing aliases and rollouts. Responses carry
+// ETags and mutations honor If-Match, so concurrent admin UIs get 412
+// instead of lost updates. AdminOptions.Authorize is required; with the
+// registry server, mount it behind API keys:
+//
+//	admin := discovery.ServeAdmin(disc, discovery.AdminOptions{
+//	    Authorize: registry.RequireRole(registry.RoleAdmin),
+//	})
+//	mux.Handle("/admin/catalog/", registry.RequireAPIKey(keys, http.StripPrefix("/admin/catalog", admin)))
+//
+// # Duplicate Detection
+//
+// Catalogs fed by many backends accumulate overlapping tools. FindDuplicates
+// groups tools with near-identical descriptions that share no backend,
+// comparing embeddings when an Embedder is configured and analyzed tokens
+// (Jaccard) otherwise. It compares every pair, so run it as an audit:
+//
+//	groups, err := disc.FindDuplicates(0.9)
+//	for _, g := range groups {
+//	    log.Printf("%.2f %s: %v", g.Similarity, g.Method, g.ToolIDs)
+//	}
+//
+// MarkDuplicates declares a group the same logical tool, as does an alias
+// naming another tool's ID. Search then collapses the members it ranks into
+// the best-ranked one, listing the others in Result.AlternateIDs, so copies
+// do not fill the top results; Options.DisableDeduplication turns this off:
+//
+//	for _, g := range groups {
+//	    _ = disc.MarkDuplicates(g.ToolIDs...)
+//	}
+//
+// # Response Size
+//
+// HTTP layers can bound response size with FitResults and DescribeToolWithin.
+// Both trim progressively (long text first, results or examples last) and
+// report whether anything was removed. DescribeToolProfile instead trims
+// to a named tooldoc truncation profile ("compact", "standard", "verbose"),
+// which the describe_tool metatool exposes as its profile argument.
+// DescribeToolBudget trims to a byte budget with tooldoc.ApplyBudget, which
+// keeps the highest-priority content and reports each omission.
+//
+// # Result Cache
+//
+// Options.ResultCache caches search results in any cache.Cache, keyed by
+// index fingerprint (index.Fingerprint), ranking model generation (bumped by
+// SetRankingModel), principal, query, and limit. The
+// fingerprint digests the catalog content, so replicas holding the same
+// tools share entries even when their index versions differ, and any change
+// to the catalog moves to new keys. Options.ResultCacheTTL bounds staleness
+// from time-dependent state such as maintenance windows.
+//
+// Options.QueryCache is an in-process LRU in front of it for agents that
+// repeat the same queries within a session. Entries are keyed by principal,
+// query text, metadata filters (in any order), limit, and index version,
+// expire after a TTL, and are dropped on every index change event:
+//
+//	disc, _ := discovery.New(discovery.Options{
+//	    QueryCache: discovery.NewQueryCache(discovery.QueryCacheOptions{MaxEntries: 512}),
+//	})
+//
+// # Search Pushdown
+//
+// When the index implements index.SearchPushdown, Discovery hands it the
+// parsed query (text, metadata filters, and in hybrid mode the embedded
+// query) instead of ranking in process, so database-backed indexes filter
+// and rank natively. Indexes that cannot serve a query return
+// index.ErrPushdownUnsupported and Discovery falls back to its searcher,
+// reusing the query embedding; Options.DisableSearchPushdown always ranks in
+// process. Pushed-down results carry ScorePushdown.
+//
+// # Score Calibration
+//
+// Raw scores are not comparable across search modes or catalogs. Fit a
+// Calibrator from labeled results to get a 0–1 confidence that supports
+// absolute thresholds:
+//
+//	cal, err := discovery.FitCalibrator(samples, discovery.CalibrationIsotonic)
+//	confident := results.FilterByConfidence(cal, 0.8)
+//
+// Use CalibrationPlatt (sigmoid) for small samples and CalibrationIsotonic
+// when enough labels exist to learn an arbitrary monotonic curve.
+//
+// # Relevance Feedback
+//
+// RecordImpressions and RecordFeedback capture how users and agents react to
+// results (clicked, selected, executed, rejected), keyed by a caller-chosen
+// query ID. FeedbackStats aggregates them per query-tool pair, with
+// click-through, selection, and rejection rates over impressions:
+//
+//	_ = disc.RecordImpressions(reqID, results.IDs()...)
+//	_ = disc.RecordFeedback(reqID, "github:create_issue", discovery.FeedbackSelected)
+//	stats, _ := disc.FeedbackStats(reqID)
+//
+// Options.FeedbackStore persists the counts; the default keeps them in memory.
+//
+// # Learning to Rank
+//
+// ExportRankingData turns feedback into training data. It records the
+// RankingFeatures (bm25, semantic, popularity, recency, namespace_boost) of
+// each query's top results, graded by feedback labels. WriteRankingData
+// writes them in SVMlight format for external trainers. ParseRankingModel
+// loads the trained linear or GBDT model back. As Options.RankingModel, the
+// model re-scores the top RerankTopK results of every Search:
+//
+//	examples, _ := disc.ExportRankingData(ctx, queryTexts, 50)
+//	_ = discovery.WriteRankingData(f, examples)
+//	// train offline, then:
+//	model, err := discovery.ParseRankingModel(modelJSON)
+//	disc.SetRankingModel(model)
+//
+// Re-ranked results have ScoreLTR. SearchPage is not re-ranked, while
+// SearchPageWithScores is, and cached
+// results keep the previous model's order until they expire.
+//
+// # Reranking
+//
+// Options.Reranker hands the top RerankerTopK results of every Search to a
+// cross-encoder or LLM, which returns them in a new order; it runs after any
+// RankingModel and before exact-match pinning. WithRerank sets the count for
+// one SearchWithOptions call, and WithRerank(0) skips it:
+//
+//	disc, _ := discovery.New(discovery.Options{Reranker: crossEncoder})
+//	results, err := disc.SearchWithOptions(ctx, "open a ticket", 5, discovery.WithRerank(30))
+//
+// Reranked results have ScoreRerank. The reranker cannot add tools: only the
+// candidates it was given are kept. SearchPage is not reranked.
+//
+// # Self-Test
+//
+// SelfTest embeds a short text with every configured embedder, runs a canned
+// search, and describes an indexed tool, returning a SelfTestReport with one
+// CheckResult per component. Run it at startup to fail fast on bad embedder
+// credentials:
+//
+//	if err := disc.SelfTest(ctx).Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// # Telemetry
+//
+// Options.TracerProvider and Options.MeterProvider enable OpenTelemetry
+// instrumentation. Search, DescribeTool, and RegisterTool emit
+// discovery.Search, discovery.DescribeTool, and discovery.RegisterTool spans;
+// searches also record the discovery.search.duration and
+// discovery.search.results histograms and the discovery.search.requests and
+// discovery.search.zero_results counters, from which dashboards derive the
+// zero-result rate. Registrations rejected by catalog limits (see
+// Options.MaxTools and MaxDocBytes) are reported by the
+// discovery.registration.rejected counter, keyed by AttrCatalogLimit. Query
+// text is not recorded.
+//
+//	disc, _ := discovery.New(discovery.Options{
+//	    TracerProvider: otel.GetTracerProvider(),
+//	    MeterProvider:  otel.GetMeterProvider(),
+//	})
+//
+// # Interceptors
+//
+// UseSearchInterceptor and UseDescribeInterceptor layer cross-cutting
+// behavior over Search (including the page searches, and so the MCP
+// search_tools metatool) and DescribeTool without modifying Discovery, like
+// registry.Registry.Use does for execution. An interceptor may rewrite the
+// request, short-circuit it, or post-process the response; the first one
+// registered is outermost:
+//
+//	disc.UseSearchInterceptor(func(next discovery.SearchFunc) discovery.SearchFunc {
+//	    return func(ctx context.Context, principal, query string, limit int) (discovery.Results, error) {
+//	        results, err := next(ctx, principal, query, limit)
+//	        analytics.Record(principal, query, len(results))
+//	        return results, err
+//	    }
+//	})
+//
+// # Query Rewriting
+//
+// Options.QueryRewriter transforms queries before Search, SearchFor, and
+// SearchPage score them, for example to strip filler words or expand domain
+// abbreviations. ChainQueryRewriters applies several in order, and each
+// result reports the scored query in Result.RewrittenQuery when it changed:
+//
+//	disc, _ := discovery.New(discovery.Options{
+//	    QueryRewriter: discovery.ChainQueryRewriters(stripFiller, translate, expandAbbreviations),
+//	})
+//
+// # MCP Metatools
+//
+// ServeMCP exposes a Discovery as an MCP server with the search_tools,
+// describe_tool, list_tool_examples, and list_namespaces metatools, so agent
+// hosts need not hand-roll them:
+//
+//	server := discovery.ServeMCP(disc)
+//	err := server.Run(ctx, &mcp.StdioTransport{})
+//
 // # Thread Safety
 //
 // All Discovery methods are safe for concurrent use.
@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/semantic"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrInvalidThreshold is returned by FindDuplicates for a threshold outside
+// (0, 1].
+var ErrInvalidThreshold = errcode.New(errcode.InvalidArgument, "discovery: invalid duplicate threshold")
+
+// DuplicateMethod names how FindDuplicates compared descriptions.
+type DuplicateMethod string
+
+const (
+	// DuplicateEmbedding compares description embeddings by cosine
+	// similarity.
+	DuplicateEmbedding DuplicateMethod = "embedding"
+
+	// DuplicateJaccard compares the analyzed description tokens by Jaccard
+	// similarity.
+	DuplicateJaccard DuplicateMethod = "jaccard"
+)
+
+// DuplicateGroup is a set of near-identical tools served by different
+// backends.
+type DuplicateGroup struct {
+	// ToolIDs are the grouped tools, sorted.
+	ToolIDs []string `json:"toolIds"`
+
+	// Similarity is the highest similarity between two tools in the group.
+	Similarity float64 `json:"similarity"`
+
+	// Method is how the descriptions were compared.
+	Method DuplicateMethod `json:"method"`
+}
+
+// FindDuplicates groups tools whose descriptions are at least threshold
+// similar (0 < threshold <= 1) but that share no backend, such as the same
+// tool registered from two MCP servers. Descriptions are compared by
+// embedding cosine similarity when an Embedder or VectorIndex is configured
+// and by token Jaccard similarity otherwise. Tools are grouped
+// transitively, so a group may contain pairs below threshold. Groups are
+// ordered by descending Similarity, then first ToolID.
+//
+// Every pair of tools is compared, so it is meant for periodic audits of
+// large catalogs rather than the request path.
+func (d *Discovery) FindDuplicates(threshold float64) ([]DuplicateGroup, error) {
+	if !(threshold > 0 && threshold <= 1) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidThreshold, threshold)
+	}
+
+	var ids, texts []string
+	var sources [][]string
+	for _, doc := range d.getSearchDocs("") {
+		tool, _, err := d.idx.GetTool(doc.ID)
+		if err != nil || strings.TrimSpace(tool.Description) == "" {
+			continue
+		}
+		backends, err := d.idx.GetAllBackends(doc.ID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, doc.ID)
+		texts = append(texts, tool.Description)
+		sources = append(sources, backendSources(backends))
+	}
+
+	similarity, method, err := d.duplicateSimilarity(texts)
+	if err != nil {
+		return nil, err
+	}
+
+	parent := make([]int, len(ids))
+	best := make([]float64, len(ids))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			if slices.ContainsFunc(sources[i], func(s string) bool { return slices.Contains(sources[j], s) }) {
+				continue
+			}
+			sim := similarity(i, j)
+			if sim < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				best[ri] = max(best[ri], best[rj])
+			}
+			best[ri] = max(best[ri], sim)
+		}
+	}
+
+	members := make(map[int][]string)
+	for i, id := range ids {
+		root := find(i)
+		members[root] = append(members[root], id)
+	}
+	var groups []DuplicateGroup
+	for root, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		slices.Sort(group)
+		groups = append(groups, DuplicateGroup{ToolIDs: group, Similarity: best[root], Method: method})
+	}
+	slices.SortFunc(groups, func(a, b DuplicateGroup) int {
+		return cmp.Or(cmp.Compare(b.Similarity, a.Similarity), strings.Compare(a.ToolIDs[0], b.ToolIDs[0]))
+	})
+	return groups, nil
+}
+
+// duplicateSimilarity returns a pairwise similarity function over texts.
+func (d *Discovery) duplicateSimilarity(texts []string) (func(i, j int) float64, DuplicateMethod, error) {
+	if d.queryEmbed != nil {
+		vectors, err := semantic.EmbedTexts(context.Background(), d.queryEmbed, texts)
+		if err != nil {
+			return nil, "", err
+		}
+		return func(i, j int) float64 {
+			return semantic.MetricCosine.Similarity(vectors[i], vectors[j])
+		}, DuplicateEmbedding, nil
+	}
+
+	analyzer := semantic.NewAnalyzer()
+	tokens := make([]map[string]struct{}, len(texts))
+	for i, text := range texts {
+		set := make(map[string]struct{})
+		for _, token := range analyzer.Tokenize(text) {
+			set[token] = struct{}{}
+		}
+		tokens[i] = set
+	}
+	return func(i, j int) float64 {
+		a, b := tokens[i], tokens[j]
+		if len(a) == 0 || len(b) == 0 {
+			return 0
+		}
+		shared := 0
+		for token := range a {
+			if _, ok := b[token]; ok {
+				shared++
+			}
+		}
+		return float64(shared) / float64(len(a)+len(b)-shared)
+	}, DuplicateJaccard, nil
+}
+
+// backendSources identifies the servers, providers, and handlers serving a
+// tool.
+func backendSources(backends []model.ToolBackend) []string {
+	sources := make([]string, 0, len(backends))
+	for _, b := range backends {
+		switch {
+		case b.MCP != nil:
+			sources = append(sources, "mcp:"+b.MCP.ServerName)
+		case b.Provider != nil:
+			sources = append(sources, "provider:"+b.Provider.ProviderID)
+		case b.Local != nil:
+			sources = append(sources, "local:"+b.Local.Name)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,270 @@
+package discovery
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidFeedback is returned when feedback is missing its query or tool
+// ID, or carries an unknown signal.
+var ErrInvalidFeedback = errcode.New(errcode.InvalidArgument, "discovery: invalid feedback")
+
+// FeedbackSignal is a user or agent reaction to a search result.
+type FeedbackSignal string
+
+const (
+	// FeedbackClicked records that a result was opened or inspected.
+	FeedbackClicked FeedbackSignal = "clicked"
+	// FeedbackSelected records that a result was chosen for the task.
+	FeedbackSelected FeedbackSignal = "selected"
+	// FeedbackExecuted records that the chosen tool was called.
+	FeedbackExecuted FeedbackSignal = "executed"
+	// FeedbackRejected records that a result was judged irrelevant.
+	FeedbackRejected FeedbackSignal = "rejected"
+)
+
+// Valid reports whether s is one of the defined signals.
+func (s FeedbackSignal) Valid() bool {
+	switch s {
+	case FeedbackClicked, FeedbackSelected, FeedbackExecuted, FeedbackRejected:
+		return true
+	}
+	return false
+}
+
+// FeedbackEvent is one signal for a tool returned by a query.
+type FeedbackEvent struct {
+	QueryID string         `json:"queryId"`
+	ToolID  string         `json:"toolId"`
+	Signal  FeedbackSignal `json:"signal"`
+	Time    time.Time      `json:"time"`
+}
+
+// FeedbackStats aggregates the feedback for one query-tool pair.
+type FeedbackStats struct {
+	QueryID     string    `json:"queryId"`
+	ToolID      string    `json:"toolId"`
+	Impressions int       `json:"impressions"`
+	Clicked     int       `json:"clicked"`
+	Selected    int       `json:"selected"`
+	Executed    int       `json:"executed"`
+	Rejected    int       `json:"rejected"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// ClickThroughRate returns Clicked / Impressions, or 0 without impressions.
+func (s FeedbackStats) ClickThroughRate() float64 {
+	return rate(s.Clicked, s.Impressions)
+}
+
+// SelectionRate returns Selected / Impressions, or 0 without impressions.
+func (s FeedbackStats) SelectionRate() float64 {
+	return rate(s.Selected, s.Impressions)
+}
+
+// RejectionRate returns Rejected / Impressions, or 0 without impressions.
+func (s FeedbackStats) RejectionRate() float64 {
+	return rate(s.Rejected, s.Impressions)
+}
+
+func rate(n, impressions int) float64 {
+	if impressions <= 0 {
+		return 0
+	}
+	return float64(n) / float64(impressions)
+}
+
+// FeedbackStore persists relevance feedback.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Aggregation: FeedbackStats reflects every recorded impression and
+//     event; rates are derived from the counts.
+//   - Ordering: FeedbackStats returns pairs sorted by QueryID, then ToolID.
+//     An empty queryID returns every pair.
+type FeedbackStore interface {
+	RecordImpressions(queryID string, toolIDs []string, at time.Time) error
+	RecordFeedback(event FeedbackEvent) error
+	FeedbackStats(queryID string) ([]FeedbackStats, error)
+}
+
+// ToolFeedbackReader is an optional FeedbackStore capability for reading
+// the feedback of specific tools without listing every pair. Ranking
+// features use it for tool popularity; stores without it are scanned with
+// FeedbackStats("").
+//
+// Contract:
+//   - ToolFeedbackStats returns the pairs of every query for the tools in
+//     toolIDs, in FeedbackStats order.
+type ToolFeedbackReader interface {
+	ToolFeedbackStats(toolIDs []string) ([]FeedbackStats, error)
+}
+
+type feedbackKey struct {
+	queryID string
+	toolID  string
+}
+
+// InMemoryFeedbackStore is a FeedbackStore that keeps aggregated counts in
+// memory. It is the default when Options.FeedbackStore is nil.
+type InMemoryFeedbackStore struct {
+	mu     sync.RWMutex
+	stats  map[feedbackKey]*FeedbackStats
+	byTool map[string][]*FeedbackStats
+}
+
+var _ ToolFeedbackReader = (*InMemoryFeedbackStore)(nil)
+
+// NewInMemoryFeedbackStore creates an empty InMemoryFeedbackStore.
+func NewInMemoryFeedbackStore() *InMemoryFeedbackStore {
+	return &InMemoryFeedbackStore{
+		stats:  make(map[feedbackKey]*FeedbackStats),
+		byTool: make(map[string][]*FeedbackStats),
+	}
+}
+
+// RecordImpressions counts one impression for each tool in toolIDs.
+func (s *InMemoryFeedbackStore) RecordImpressions(queryID string, toolIDs []string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range toolIDs {
+		stats := s.entryLocked(queryID, id)
+		stats.Impressions++
+		stats.LastSeen = latest(stats.LastSeen, at)
+	}
+	return nil
+}
+
+// RecordFeedback counts event.Signal for its query-tool pair.
+func (s *InMemoryFeedbackStore) RecordFeedback(event FeedbackEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.entryLocked(event.QueryID, event.ToolID)
+	switch event.Signal {
+	case FeedbackClicked:
+		stats.Clicked++
+	case FeedbackSelected:
+		stats.Selected++
+	case FeedbackExecuted:
+		stats.Executed++
+	case FeedbackRejected:
+		stats.Rejected++
+	default:
+		return fmt.Errorf("%w: unknown signal %q", ErrInvalidFeedback, event.Signal)
+	}
+	stats.LastSeen = latest(stats.LastSeen, event.Time)
+	return nil
+}
+
+// FeedbackStats returns the aggregated feedback for queryID, or for every
+// query when queryID is empty.
+func (s *InMemoryFeedbackStore) FeedbackStats(queryID string) ([]FeedbackStats, error) {
+	s.mu.RLock()
+	out := make([]FeedbackStats, 0, len(s.stats))
+	for key, stats := range s.stats {
+		if queryID == "" || key.queryID == queryID {
+			out = append(out, *stats)
+		}
+	}
+	s.mu.RUnlock()
+	sortFeedbackStats(out)
+	return out, nil
+}
+
+// ToolFeedbackStats returns the aggregated feedback for the tools in
+// toolIDs across every query.
+func (s *InMemoryFeedbackStore) ToolFeedbackStats(toolIDs []string) ([]FeedbackStats, error) {
+	s.mu.RLock()
+	var out []FeedbackStats
+	for _, id := range slices.Compact(slices.Sorted(slices.Values(toolIDs))) {
+		for _, stats := range s.byTool[id] {
+			out = append(out, *stats)
+		}
+	}
+	s.mu.RUnlock()
+	sortFeedbackStats(out)
+	return out, nil
+}
+
+func sortFeedbackStats(stats []FeedbackStats) {
+	slices.SortFunc(stats, func(a, b FeedbackStats) int {
+		return cmp.Or(cmp.Compare(a.QueryID, b.QueryID), cmp.Compare(a.ToolID, b.ToolID))
+	})
+}
+
+func (s *InMemoryFeedbackStore) entryLocked(queryID, toolID string) *FeedbackStats {
+	key := feedbackKey{queryID: queryID, toolID: toolID}
+	stats, ok := s.stats[key]
+	if !ok {
+		stats = &FeedbackStats{QueryID: queryID, ToolID: toolID}
+		s.stats[key] = stats
+		s.byTool[toolID] = append(s.byTool[toolID], stats)
+	}
+	return stats
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// RecordImpressions records that toolIDs were shown for queryID, providing
+// the denominator for click-through and selection rates. Call it with the
+// IDs of the results presented to the user or agent.
+func (d *Discovery) RecordImpressions(queryID string, toolIDs ...string) error {
+	if queryID == "" {
+		return fmt.Errorf("%w: query ID is required", ErrInvalidFeedback)
+	}
+	if slices.Contains(toolIDs, "") {
+		return fmt.Errorf("%w: tool ID is required", ErrInvalidFeedback)
+	}
+	return d.feedback.RecordImpressions(queryID, toolIDs, d.now())
+}
+
+// RecordFeedback records signal for toolID as a result of queryID. queryID
+// is chosen by the caller, such as a search request ID or the normalized
+// query text, and must match the ID passed to RecordImpressions.
+func (d *Discovery) RecordFeedback(queryID, toolID string, signal FeedbackSignal) error {
+	if queryID == "" || toolID == "" {
+		return fmt.Errorf("%w: query and tool IDs are required", ErrInvalidFeedback)
+	}
+	if !signal.Valid() {
+		return fmt.Errorf("%w: unknown signal %q", ErrInvalidFeedback, signal)
+	}
+	return d.feedback.RecordFeedback(FeedbackEvent{
+		QueryID: queryID,
+		ToolID:  toolID,
+		Signal:  signal,
+		Time:    d.now(),
+	})
+}
+
+// FeedbackStats returns aggregated feedback per tool for queryID, or for
+// every query-tool pair when queryID is empty.
+func (d *Discovery) FeedbackStats(queryID string) ([]FeedbackStats, error) {
+	return d.feedback.FeedbackStats(queryID)
+}
+
+// toolFeedbackStats returns the feedback for toolIDs across every query,
+// through ToolFeedbackReader when the store implements it.
+func (d *Discovery) toolFeedbackStats(toolIDs []string) ([]FeedbackStats, error) {
+	if r, ok := d.feedback.(ToolFeedbackReader); ok {
+		return r.ToolFeedbackStats(toolIDs)
+	}
+	stats, err := d.feedback.FeedbackStats("")
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(toolIDs))
+	for _, id := range toolIDs {
+		wanted[id] = true
+	}
+	return slices.DeleteFunc(stats, func(s FeedbackStats) bool { return !wanted[s.ToolID] }), nil
+}
@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"encoding/json"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// FitResults trims search results until their JSON encoding is at most
+// maxBytes, for HTTP layers that must bound response size. Truncation tiers
+// are tried in order, stopping at the first that fits: long summaries
+// removed, short descriptions and security summaries removed, then trailing
+// results dropped. Reports whether anything was trimmed. A maxBytes of zero
+// or less disables the guard.
+func FitResults(results Results, maxBytes int) (Results, bool) {
+	if maxBytes <= 0 || encodedSize(results) <= maxBytes {
+		return results, false
+	}
+
+	trimmed := make(Results, len(results))
+	copy(trimmed, results)
+	tiers := []func(*Result){
+		func(r *Result) { r.Summary.Summary = "" },
+		func(r *Result) {
+			r.Summary.ShortDescription = ""
+			r.Summary.SecuritySummary = ""
+		},
+	}
+	for _, tier := range tiers {
+		for i := range trimmed {
+			tier(&trimmed[i])
+		}
+		if encodedSize(trimmed) <= maxBytes {
+			return trimmed, true
+		}
+	}
+
+	// Drop trailing results. Each element adds its encoding plus a separator.
+	size := len("[]")
+	for i, r := range trimmed {
+		size += encodedSize(r)
+		if i > 0 {
+			size++
+		}
+		if size > maxBytes {
+			return trimmed[:i], true
+		}
+	}
+	return trimmed, true
+}
+
+// FitToolDoc trims a ToolDoc until its JSON encoding is at most maxBytes,
+// preferring the richest detail level that fits: examples are dropped from
+// the end first, then full-level fields (notes, external refs, related
+// tools, owner), then schema-level fields, leaving a summary-level doc.
+// Reports whether anything was trimmed. A maxBytes of zero or less disables the guard.
+func FitToolDoc(doc tooldoc.ToolDoc, maxBytes int) (tooldoc.ToolDoc, bool) {
+	if maxBytes <= 0 || encodedSize(doc) <= maxBytes {
+		return doc, false
+	}
+
+	for len(doc.Examples) > 0 {
+		doc.Examples = doc.Examples[:len(doc.Examples)-1]
+		if encodedSize(doc) <= maxBytes {
+			return doc, true
+		}
+	}
+	doc.Examples = nil
+
+	doc.Notes = ""
+	doc.ExternalRefs = nil
+	doc.SeeAlso = nil
+	doc.Owner = nil
+	if encodedSize(doc) <= maxBytes {
+		return doc, true
+	}
+
+	doc.Tool = nil
+	doc.SchemaInfo = nil
+	doc.Parameters = nil
+	doc.Annotations = nil
+	return doc, true
+}
+
+// DescribeToolWithin returns documentation like DescribeTool, trimmed with
+// FitToolDoc to at most maxBytes of JSON.
+func (d *Discovery) DescribeToolWithin(id string, level tooldoc.DetailLevel, maxBytes int) (tooldoc.ToolDoc, bool, error) {
+	doc, err := d.docs.DescribeTool(id, level)
+	if err != nil {
+		return tooldoc.ToolDoc{}, false, err
+	}
+	doc, truncated := FitToolDoc(doc, maxBytes)
+	return doc, truncated, nil
+}
+
+func encodedSize(v any) int {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
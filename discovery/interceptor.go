@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// SearchFunc performs a search as principal.
+type SearchFunc func(ctx context.Context, principal, query string, limit int) (Results, error)
+
+// SearchInterceptor wraps Search and SearchFor with cross-cutting behavior
+// such as policy, analytics, caching, or query rewriting. It receives the
+// next function in the chain and returns one that may rewrite the query,
+// short-circuit with its own results or an error, or post-process results.
+type SearchInterceptor func(next SearchFunc) SearchFunc
+
+// DescribeFunc returns documentation for a tool.
+type DescribeFunc func(ctx context.Context, id string, level tooldoc.DetailLevel) (tooldoc.ToolDoc, error)
+
+// DescribeInterceptor wraps DescribeTool and DescribeToolProfile, like
+// SearchInterceptor does for search.
+type DescribeInterceptor func(next DescribeFunc) DescribeFunc
+
+// UseSearchInterceptor appends interceptors to the search chain. The first
+// interceptor registered is outermost. Interceptors run inside the
+// discovery.Search span and outside the query and result caches, per-search
+// options, and exact-match pinning. SearchPage and SearchPageWithScores run
+// the same chain with the page size as limit, returning the page; the
+// cursor is handed past it. Nil interceptors are ignored.
+func (d *Discovery) UseSearchInterceptor(interceptors ...SearchInterceptor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ic := range interceptors {
+		if ic != nil {
+			d.searchInterceptors = append(d.searchInterceptors, ic)
+		}
+	}
+}
+
+// UseDescribeInterceptor appends interceptors to the describe chain. The
+// first interceptor registered is outermost. For DescribeToolProfile, the
+// profile is applied inside the chain. Nil interceptors are ignored.
+func (d *Discovery) UseDescribeInterceptor(interceptors ...DescribeInterceptor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ic := range interceptors {
+		if ic != nil {
+			d.describeInterceptors = append(d.describeInterceptors, ic)
+		}
+	}
+}
+
+// wrapSearch applies the registered search interceptors to search.
+func (d *Discovery) wrapSearch(search SearchFunc) SearchFunc {
+	d.mu.RLock()
+	interceptors := d.searchInterceptors
+	d.mu.RUnlock()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		search = interceptors[i](search)
+	}
+	return search
+}
+
+// searchPage runs a page search through the search interceptors. The
+// cursor of the following page is the one page returned for the last call
+// that reached it, or empty when an interceptor answered on its own.
+func (d *Discovery) searchPage(ctx context.Context, principal, query string, limit int,
+	page func(ctx context.Context, principal, query string, limit int) (Results, string, error)) (Results, string, error) {
+	var next string
+	search := d.wrapSearch(func(ctx context.Context, principal, query string, limit int) (Results, error) {
+		results, cursor, err := page(ctx, principal, query, limit)
+		next = cursor
+		return results, err
+	})
+	results, err := search(ctx, principal, query, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, next, nil
+}
+
+// wrapDescribe applies the registered describe interceptors to describe.
+func (d *Discovery) wrapDescribe(describe DescribeFunc) DescribeFunc {
+	d.mu.RLock()
+	interceptors := d.describeInterceptors
+	d.mu.RUnlock()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		describe = interceptors[i](describe)
+	}
+	return describe
+}
@@ -0,0 +1,440 @@
+package discovery
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/semantic"
+)
+
+// DefaultRerankTopK is the number of results re-scored by a RankingModel
+// when Options.RerankTopK is zero.
+const DefaultRerankTopK = 50
+
+// ErrInvalidRankingModel is returned by ParseRankingModel for malformed or
+// unsupported models.
+var ErrInvalidRankingModel = errcode.New(errcode.InvalidArgument, "discovery: invalid ranking model")
+
+// RankingFeatureNames lists the learning-to-rank features in vector order.
+// Serialized models refer to features by these names.
+var RankingFeatureNames = []string{"bm25", "semantic", "popularity", "recency", "namespace_boost"}
+
+// RankingFeatures describes one query-tool pair for learning to rank.
+type RankingFeatures struct {
+	// BM25 is the lexical score of the query against the tool text.
+	BM25 float64 `json:"bm25"`
+	// Semantic is the embedding similarity of the query and the tool text,
+	// as HybridSearcher scored it while ranking. Zero without hybrid search.
+	Semantic float64 `json:"semantic"`
+	// Popularity is log(1 + selections + executions) across all recorded
+	// feedback for the tool.
+	Popularity float64 `json:"popularity"`
+	// Recency is 1 / (1 + days since the tool last changed), or zero when
+	// the change time is unknown.
+	Recency float64 `json:"recency"`
+	// NamespaceBoost is Options.NamespaceBoosts for the tool's namespace.
+	NamespaceBoost float64 `json:"namespace_boost"`
+}
+
+// Vector returns the features in RankingFeatureNames order.
+func (f RankingFeatures) Vector() []float64 {
+	return []float64{f.BM25, f.Semantic, f.Popularity, f.Recency, f.NamespaceBoost}
+}
+
+// Feature returns the feature with the given name.
+func (f RankingFeatures) Feature(name string) (float64, bool) {
+	i := slices.Index(RankingFeatureNames, name)
+	if i < 0 {
+		return 0, false
+	}
+	return f.Vector()[i], true
+}
+
+// RankingModel scores a query-tool pair from its features; higher scores
+// rank first.
+//
+// Contract:
+//   - Concurrency: Score must be safe for concurrent use.
+//   - Determinism: identical features must yield identical scores.
+type RankingModel interface {
+	Score(features RankingFeatures) float64
+}
+
+// LinearModel scores features as Bias plus the weighted sum of features,
+// keyed by RankingFeatureNames.
+type LinearModel struct {
+	Bias    float64            `json:"bias"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+// Score implements RankingModel.
+func (m LinearModel) Score(features RankingFeatures) float64 {
+	score := m.Bias
+	for name, weight := range m.Weights {
+		if v, ok := features.Feature(name); ok {
+			score += weight * v
+		}
+	}
+	return score
+}
+
+// GBDTModel scores features as BaseScore plus the leaf value reached in
+// each tree, as produced by gradient-boosted decision tree trainers.
+type GBDTModel struct {
+	BaseScore float64    `json:"baseScore"`
+	Trees     []GBDTTree `json:"trees"`
+}
+
+// GBDTTree is a decision tree stored as a node array rooted at index 0.
+type GBDTTree struct {
+	Nodes []GBDTNode `json:"nodes"`
+}
+
+// GBDTNode is a split or, when Leaf is set, a leaf. A split follows Left
+// when the feature is less than Threshold and Right otherwise; Left and
+// Right index the tree's Nodes.
+type GBDTNode struct {
+	Feature   string   `json:"feature,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+	Left      int      `json:"left,omitempty"`
+	Right     int      `json:"right,omitempty"`
+	Leaf      *float64 `json:"leaf,omitempty"`
+}
+
+// Score implements RankingModel.
+func (m GBDTModel) Score(features RankingFeatures) float64 {
+	score := m.BaseScore
+	for _, tree := range m.Trees {
+		score += tree.eval(features)
+	}
+	return score
+}
+
+func (t GBDTTree) eval(features RankingFeatures) float64 {
+	node := 0
+	// Validated trees reach a leaf within len(Nodes) steps.
+	for range t.Nodes {
+		n := t.Nodes[node]
+		if n.Leaf != nil {
+			return *n.Leaf
+		}
+		v, _ := features.Feature(n.Feature)
+		if v < n.Threshold {
+			node = n.Left
+		} else {
+			node = n.Right
+		}
+	}
+	return 0
+}
+
+func (t GBDTTree) validate() error {
+	if len(t.Nodes) == 0 {
+		return errors.New("tree has no nodes")
+	}
+	for i, n := range t.Nodes {
+		if n.Leaf != nil {
+			continue
+		}
+		if !slices.Contains(RankingFeatureNames, n.Feature) {
+			return fmt.Errorf("node %d: unknown feature %q", i, n.Feature)
+		}
+		// Children must follow their parent, which rules out cycles.
+		for _, child := range []int{n.Left, n.Right} {
+			if child <= i || child >= len(t.Nodes) {
+				return fmt.Errorf("node %d: child %d out of range", i, child)
+			}
+		}
+	}
+	return nil
+}
+
+type serializedModel struct {
+	Type string `json:"type"`
+	LinearModel
+	GBDTModel
+}
+
+// ParseRankingModel decodes a serialized ranking model:
+//
+//	{"type": "linear", "bias": 0, "weights": {"bm25": 0.7, "popularity": 0.2}}
+//	{"type": "gbdt", "baseScore": 0, "trees": [{"nodes": [
+//	    {"feature": "semantic", "threshold": 0.5, "left": 1, "right": 2},
+//	    {"leaf": -0.25},
+//	    {"leaf": 0.75}]}]}
+//
+// Feature names are those in RankingFeatureNames.
+func ParseRankingModel(data []byte) (RankingModel, error) {
+	var m serializedModel
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRankingModel, err)
+	}
+	switch m.Type {
+	case "linear":
+		for name := range m.Weights {
+			if !slices.Contains(RankingFeatureNames, name) {
+				return nil, fmt.Errorf("%w: unknown feature %q", ErrInvalidRankingModel, name)
+			}
+		}
+		return m.LinearModel, nil
+	case "gbdt":
+		for i, tree := range m.Trees {
+			if err := tree.validate(); err != nil {
+				return nil, fmt.Errorf("%w: tree %d: %v", ErrInvalidRankingModel, i, err)
+			}
+		}
+		return m.GBDTModel, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidRankingModel, m.Type)
+	}
+}
+
+// SetRankingModel replaces the model used to re-score search results, for
+// example after retraining on new feedback. Nil disables re-ranking. The
+// QueryCache, if any, is invalidated.
+func (d *Discovery) SetRankingModel(model RankingModel) {
+	d.mu.Lock()
+	d.ranker = model
+	d.rankerGeneration++
+	d.mu.Unlock()
+	if d.queries != nil {
+		d.queries.Invalidate()
+	}
+}
+
+func (d *Discovery) rankingModel() RankingModel {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.ranker
+}
+
+// rankingGeneration counts SetRankingModel calls, so cache keys change with
+// the model.
+func (d *Discovery) rankingGeneration() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.rankerGeneration
+}
+
+// retrievalLimit widens limit so a ranking model can promote results from
+// the whole top-K.
+func (d *Discovery) retrievalLimit(limit int) int {
+	if d.rankingModel() == nil {
+		return limit
+	}
+	return max(limit, d.rerankTopK)
+}
+
+// rerank re-scores the top-K results with the ranking model and returns the
+// best limit results.
+func (d *Discovery) rerank(ctx context.Context, query string, results Results, limit int) (Results, error) {
+	model := d.rankingModel()
+	if model == nil || len(results) == 0 {
+		return results, nil
+	}
+	top := results[:min(len(results), d.rerankTopK)]
+	features, err := d.rankingFeatures(ctx, query, top)
+	if err != nil {
+		return nil, err
+	}
+	for i := range top {
+		top[i].Score = model.Score(features[i])
+		top[i].ScoreType = ScoreLTR
+	}
+	slices.SortStableFunc(top, func(a, b Result) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Summary.ID, b.Summary.ID))
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// rankingFeatures computes RankingFeatures for each result. Semantic
+// similarities recorded by HybridSearcher while ranking are reused, so the
+// query is only embedded again for results it did not score.
+func (d *Discovery) rankingFeatures(ctx context.Context, query string, results Results) ([]RankingFeatures, error) {
+	searchDocs := make([]index.SearchDoc, len(results))
+	for i, r := range results {
+		searchDocs[i] = index.SearchDoc{ID: r.Summary.ID, Summary: r.Summary}
+	}
+	docs := semantic.DocumentsFromSearchDocs(searchDocs)
+	for i := range docs {
+		docs[i] = docs[i].Normalized()
+	}
+
+	bm25 := semantic.NewBM25Strategy(nil)
+	var semanticScores []float64
+	if h, ok := d.compositeS.(*HybridSearcher); ok {
+		bm25 = h.bm25Strategy
+		semanticScores = make([]float64, len(results))
+		var missing []int
+		for i, r := range results {
+			if r.semantic != nil {
+				semanticScores[i] = *r.semantic
+			} else {
+				missing = append(missing, i)
+			}
+		}
+		if len(missing) > 0 {
+			pending := make([]semantic.Document, len(missing))
+			for j, i := range missing {
+				pending[j] = docs[i]
+			}
+			scores, err := h.embeddingScores(ctx, query, pending)
+			if err != nil {
+				return nil, err
+			}
+			for j, i := range missing {
+				semanticScores[i] = scores[j]
+			}
+		}
+	}
+	bm25Scores, err := semantic.ScoreDocuments(ctx, bm25, query, docs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Summary.ID
+	}
+	popularity := map[string]int{}
+	if stats, err := d.toolFeedbackStats(ids); err == nil {
+		for _, s := range stats {
+			popularity[s.ToolID] += s.Selected + s.Executed
+		}
+	}
+
+	now := d.now()
+	features := make([]RankingFeatures, len(results))
+	for i, r := range results {
+		f := RankingFeatures{
+			BM25:           bm25Scores[i],
+			Popularity:     math.Log1p(float64(popularity[r.Summary.ID])),
+			NamespaceBoost: d.namespaceBoosts[r.Summary.Namespace],
+		}
+		if semanticScores != nil {
+			f.Semantic = semanticScores[i]
+		}
+		if d.journal != nil {
+			if at, ok := d.journal.lastChanged(r.Summary.ID); ok {
+				f.Recency = 1 / (1 + max(0, now.Sub(at).Hours()/24))
+			}
+		}
+		features[i] = f
+	}
+	return features, nil
+}
+
+// RankingExample is one labeled query-tool pair for training a ranking
+// model.
+type RankingExample struct {
+	QueryID  string          `json:"queryId"`
+	Query    string          `json:"query"`
+	ToolID   string          `json:"toolId"`
+	Features RankingFeatures `json:"features"`
+	// Label grades relevance from feedback: 3 executed, 2 selected,
+	// 1 clicked, and 0 for rejected or no signal.
+	Label int `json:"label"`
+}
+
+// ExportRankingData builds training data from recorded feedback. queries
+// maps each feedback query ID to its query text; the top topK results of
+// each query (rewritten by any QueryRewriter, without re-ranking) are
+// labeled from the query's feedback.
+// Examples are ordered by query ID, then rank.
+func (d *Discovery) ExportRankingData(ctx context.Context, queries map[string]string, topK int) ([]RankingExample, error) {
+	if topK <= 0 {
+		topK = d.rerankTopK
+	}
+	ids := make([]string, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var examples []RankingExample
+	for _, queryID := range ids {
+		query, err := d.rewriteQuery(ctx, queries[queryID])
+		if err != nil {
+			return nil, err
+		}
+		results, err := d.search(ctx, "", query, topK)
+		if err != nil {
+			return nil, err
+		}
+		features, err := d.rankingFeatures(ctx, query, results)
+		if err != nil {
+			return nil, err
+		}
+		stats, err := d.feedback.FeedbackStats(queryID)
+		if err != nil {
+			return nil, err
+		}
+		labels := make(map[string]int, len(stats))
+		for _, s := range stats {
+			labels[s.ToolID] = feedbackLabel(s)
+		}
+		for i, r := range results {
+			examples = append(examples, RankingExample{
+				QueryID:  queryID,
+				Query:    query,
+				ToolID:   r.Summary.ID,
+				Features: features[i],
+				Label:    labels[r.Summary.ID],
+			})
+		}
+	}
+	return examples, nil
+}
+
+func feedbackLabel(s FeedbackStats) int {
+	switch {
+	case s.Rejected > 0:
+		return 0
+	case s.Executed > 0:
+		return 3
+	case s.Selected > 0:
+		return 2
+	case s.Clicked > 0:
+		return 1
+	}
+	return 0
+}
+
+// WriteRankingData writes examples in the SVMlight ranking format accepted
+// by common LTR trainers, one line per example:
+//
+//	<label> qid:<n> 1:<bm25> 2:<semantic> 3:<popularity> 4:<recency> 5:<namespace_boost> # <queryID> <toolID>
+//
+// Query IDs are numbered from 1 in order of first appearance.
+func WriteRankingData(w io.Writer, examples []RankingExample) error {
+	bw := bufio.NewWriter(w)
+	qids := map[string]int{}
+	for _, ex := range examples {
+		qid, ok := qids[ex.QueryID]
+		if !ok {
+			qid = len(qids) + 1
+			qids[ex.QueryID] = qid
+		}
+		line := strconv.Itoa(ex.Label) + " qid:" + strconv.Itoa(qid)
+		for i, v := range ex.Features.Vector() {
+			line += " " + strconv.Itoa(i+1) + ":" + strconv.FormatFloat(v, 'g', -1, 64)
+		}
+		if _, err := fmt.Fprintf(bw, "%s # %s %s\n", line, ex.QueryID, ex.ToolID); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
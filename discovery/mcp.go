@@ -0,0 +1,170 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// Metatool names registered by ServeMCP.
+const (
+	MetatoolSearchTools      = "search_tools"
+	MetatoolDescribeTool     = "describe_tool"
+	MetatoolListToolExamples = "list_tool_examples"
+	MetatoolListNamespaces   = "list_namespaces"
+)
+
+// MCPOptions configures the server returned by ServeMCP.
+type MCPOptions struct {
+	// Implementation identifies the server to clients.
+	// Default: &mcp.Implementation{Name: "tooldiscovery"}
+	Implementation *mcp.Implementation
+
+	// ServerOptions is passed to mcp.NewServer.
+	ServerOptions *mcp.ServerOptions
+
+	// DefaultLimit applies when search_tools or list_tool_examples is called
+	// without a limit.
+	// Default: 10
+	DefaultLimit int
+
+	// MaxLimit caps the limit a client may request.
+	// Default: 100
+	MaxLimit int
+}
+
+// ServeMCP returns an MCP server exposing disc as metatools, so agent hosts
+// can let a model discover tools progressively instead of listing them all:
+//
+//   - search_tools: search by query, with cursor pagination
+//   - describe_tool: documentation at summary, schema, or full detail
+//   - list_tool_examples: usage examples for a tool
+//   - list_namespaces: all registered namespaces
+//
+// Errors such as unknown tools are reported as tool results with IsError set.
+// Connect the server to a transport with mcp.Server.Run or Connect.
+func ServeMCP(disc *Discovery, opts ...MCPOptions) *mcp.Server {
+	var opt MCPOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Implementation == nil {
+		opt.Implementation = &mcp.Implementation{Name: "tooldiscovery"}
+	}
+	if opt.DefaultLimit <= 0 {
+		opt.DefaultLimit = 10
+	}
+	if opt.MaxLimit <= 0 {
+		opt.MaxLimit = 100
+	}
+	limitFor := func(requested int) int {
+		if requested <= 0 {
+			return opt.DefaultLimit
+		}
+		return min(requested, opt.MaxLimit)
+	}
+
+	server := mcp.NewServer(opt.Implementation, opt.ServerOptions)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        MetatoolSearchTools,
+		Description: "Search available tools by name, namespace, description, and tags. Returns tool summaries ranked by relevance.",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, in searchToolsInput) (*mcp.CallToolResult, searchToolsOutput, error) {
+		results, next, err := disc.SearchPage(ctx, in.Query, limitFor(in.Limit), in.Cursor)
+		if err != nil {
+			return nil, searchToolsOutput{}, err
+		}
+		out := searchToolsOutput{Tools: make([]searchToolsHit, len(results)), NextCursor: next}
+		for i, r := range results {
+			out.RewrittenQuery = r.RewrittenQuery
+			out.Tools[i] = searchToolsHit{Summary: r.Summary, Score: r.Score, ScoreType: string(r.ScoreType), Pinned: r.Pinned, AlternateIDs: r.AlternateIDs}
+		}
+		return nil, out, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        MetatoolDescribeTool,
+		Description: "Describe a tool by ID. Level \"summary\" returns a short description, \"schema\" adds the input schema, and \"full\" adds notes, examples, and ownership. Profile \"compact\" or \"standard\" trims notes, examples, and schema details to save context.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in describeToolInput) (*mcp.CallToolResult, tooldoc.ToolDoc, error) {
+		level := tooldoc.DetailLevel(in.Level)
+		if level == "" {
+			level = tooldoc.DetailSummary
+		}
+		doc, err := disc.DescribeToolProfile(in.ID, level, in.Profile)
+		return nil, doc, err
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        MetatoolListToolExamples,
+		Description: "List usage examples for a tool by ID, highest priority first.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in listToolExamplesInput) (*mcp.CallToolResult, listToolExamplesOutput, error) {
+		examples, err := disc.ListExamples(in.ID, limitFor(in.Limit))
+		if err != nil {
+			return nil, listToolExamplesOutput{}, err
+		}
+		if examples == nil {
+			examples = []tooldoc.ToolExample{}
+		}
+		return nil, listToolExamplesOutput{Examples: examples}, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        MetatoolListNamespaces,
+		Description: "List the namespaces that group available tools.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, listNamespacesOutput, error) {
+		namespaces, err := disc.ListNamespaces()
+		if err != nil {
+			return nil, listNamespacesOutput{}, err
+		}
+		if namespaces == nil {
+			namespaces = []string{}
+		}
+		return nil, listNamespacesOutput{Namespaces: namespaces}, nil
+	})
+
+	return server
+}
+
+type searchToolsInput struct {
+	Query  string `json:"query" jsonschema:"keywords describing the task; empty lists tools in ID order. Add hint.destructive=false or hint.readOnly=true to exclude tools with side effects"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"maximum number of tools to return"`
+	Cursor string `json:"cursor,omitempty" jsonschema:"nextCursor from a previous call, to fetch the next page"`
+}
+
+type searchToolsHit struct {
+	Summary   index.Summary `json:"summary"`
+	Score     float64       `json:"score"`
+	ScoreType string        `json:"scoreType"`
+	Pinned    bool          `json:"pinned,omitempty"`
+
+	AlternateIDs []string `json:"alternateIds,omitempty"`
+}
+
+type searchToolsOutput struct {
+	Tools      []searchToolsHit `json:"tools"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+
+	RewrittenQuery string `json:"rewrittenQuery,omitempty"`
+}
+
+type describeToolInput struct {
+	ID      string `json:"id" jsonschema:"tool ID, e.g. github:create_issue"`
+	Level   string `json:"level,omitempty" jsonschema:"summary, schema, or full (default summary)"`
+	Profile string `json:"profile,omitempty" jsonschema:"compact, standard, or verbose trimming for the context budget (default verbose)"`
+}
+
+type listToolExamplesInput struct {
+	ID    string `json:"id" jsonschema:"tool ID, e.g. github:create_issue"`
+	Limit int    `json:"limit,omitempty" jsonschema:"maximum number of examples to return"`
+}
+
+type listToolExamplesOutput struct {
+	Examples []tooldoc.ToolExample `json:"examples"`
+}
+
+type listNamespacesOutput struct {
+	Namespaces []string `json:"namespaces"`
+}
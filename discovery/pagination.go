@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// SearchPageWithScores performs paginated search through the configured
+// hybrid searcher, or the index's searcher when it reports scores
+// (index.ScoredSearcher, as the default BM25 searcher does), returning
+// scores on every page. SearchPage reports Score 0 instead.
+func (d *Discovery) SearchPageWithScores(ctx context.Context, query string, limit int, cursor string) (Results, string, error) {
+	return d.SearchPageWithScoresFor(ctx, "", query, limit, cursor)
+}
+
+// SearchPageWithScoresFor performs SearchPageWithScores as principal.
+//
+// Every page ranks the results up to the end of the page and slices them,
+// so pages are ordered like Search without a Reranker or exact-match
+// pinning, and carry RewrittenQuery like Search. Cursors are bound to the
+// rewritten query, principal, index version, and duplicate groups, and
+// become invalid (index.ErrInvalidCursor) when any of them changes. When neither a composite searcher nor the index reports
+// scores it behaves like SearchPageFor.
+func (d *Discovery) SearchPageWithScoresFor(ctx context.Context, principal, query string, limit int, cursor string) (Results, string, error) {
+	return d.searchPage(ctx, principal, query, limit, func(ctx context.Context, principal, query string, limit int) (Results, string, error) {
+		return d.searchPageWithScores(ctx, principal, query, limit, cursor)
+	})
+}
+
+// searchPageWithScores implements SearchPageWithScoresFor inside the
+// search interceptors.
+func (d *Discovery) searchPageWithScores(ctx context.Context, principal, query string, limit int, cursor string) (Results, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+	rewritten, err := d.rewriteQuery(ctx, query)
+	if err != nil {
+		return nil, "", err
+	}
+	checksum := d.pageChecksum(principal, rewritten)
+	offset, err := decodePageCursor(cursor, checksum)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Rank one result past the page to tell whether another page follows,
+	// plus the results duplicates may collapse away.
+	window := d.retrievalLimit(offset+limit+1) + d.duplicateSlack(offset+limit+1)
+	results, err := d.scoredSearch(ctx, principal, rewritten, window)
+	if errors.Is(err, index.ErrScoresUnsupported) {
+		return d.searchPageRewritten(principal, query, rewritten, limit, cursor)
+	}
+	if err == nil {
+		results, err = d.rerank(ctx, rewritten, results, len(results))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	results = d.collapseDuplicates(results)
+	if offset >= len(results) {
+		return Results{}, "", nil
+	}
+	end := min(offset+limit, len(results))
+	next := ""
+	if end < len(results) {
+		next = encodePageCursor(end, checksum)
+	}
+	return markRewritten(results[offset:end], query, rewritten), next, nil
+}
+
+// scoredIndex is implemented by indexes that report the scores of their
+// searcher, such as *index.InMemoryIndex.
+type scoredIndex interface {
+	SearchWithScoresFor(principal, query string, limit int) ([]index.ScoredSummary, error)
+}
+
+// scoredSearch ranks query like searchBefore, taking scores from the
+// index's searcher when there is no composite searcher. It returns
+// index.ErrScoresUnsupported when no scores are available.
+func (d *Discovery) scoredSearch(ctx context.Context, principal, query string, limit int) (Results, error) {
+	if d.compositeS != nil {
+		results, _, err := d.searchBefore(ctx, principal, query, limit, time.Time{})
+		return results, err
+	}
+	if d.pushdown != nil {
+		results, _, err := d.pushdownSearch(ctx, principal, query, limit)
+		if !errors.Is(err, index.ErrPushdownUnsupported) {
+			return results, err
+		}
+	}
+	si, ok := d.idx.(scoredIndex)
+	if !ok {
+		return nil, index.ErrScoresUnsupported
+	}
+	scored, err := si.SearchWithScoresFor(principal, d.expandQuery(query, false), limit)
+	if err != nil {
+		return nil, err
+	}
+	results := make(Results, len(scored))
+	for i, r := range scored {
+		results[i] = Result{Summary: r.Summary, Score: r.Score, ScoreType: d.scoreType}
+	}
+	return results, nil
+}
+
+// pageChecksum binds a scored-search cursor to the rewritten query,
+// principal, index version, and duplicate groups.
+func (d *Discovery) pageChecksum(principal, query string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(principal))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(query))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(d.duplicatesFingerprint()))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], d.Version())
+	_, _ = h.Write(buf[:])
+	return h.Sum64()
+}
+
+type pageCursor struct {
+	Offset   int    `json:"offset"`
+	Checksum uint64 `json:"checksum"`
+}
+
+func encodePageCursor(offset int, checksum uint64) string {
+	payload, _ := json.Marshal(pageCursor{Offset: offset, Checksum: checksum})
+	return base64.StdEncoding.EncodeToString(payload)
+}
+
+func decodePageCursor(cursor string, checksum uint64) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", index.ErrInvalidCursor, err)
+	}
+	var token pageCursor
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		return 0, fmt.Errorf("%w: %v", index.ErrInvalidCursor, err)
+	}
+	if token.Offset < 0 || token.Checksum != checksum {
+		return 0, index.ErrInvalidCursor
+	}
+	return token.Offset, nil
+}
@@ -0,0 +1,90 @@
+package discovery
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// summaryLookup is implemented by indexes that resolve IDs to the summaries
+// a principal may see, such as index.InMemoryIndex.
+type summaryLookup interface {
+	SummariesFor(principal string, ids []string) []index.Summary
+}
+
+// pinExactMatches moves tools whose ID or name equals the query text to the
+// front of results. ID matches come before name matches, and tools sharing
+// a name keep their ranked order. Matches that ranking left out are looked
+// up in the index when it implements summaryLookup, so a precise name is
+// found even when fuzzier tools fill the limit. Metadata, category, and
+// hint filters still apply.
+func (d *Discovery) pinExactMatches(principal, query string, results Results, limit int) Results {
+	text, filters := index.ParseMetadataFilters(query)
+	text, category := index.ParseCategoryFilter(text)
+	text, hints := index.ParseHintFilters(text)
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, " \t\n") {
+		return results
+	}
+	rank := func(s index.Summary) int {
+		switch {
+		case s.ID == text:
+			return 1
+		case s.Name == text:
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	var pinned Results
+	rest := make(Results, 0, len(results))
+	for _, r := range results {
+		if rank(r.Summary) != 0 {
+			pinned = append(pinned, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	for _, s := range d.exactMatchSummaries(principal, text) {
+		if !index.MatchesMetadata(s.Metadata, filters) ||
+			(category != "" && !index.InCategory(s.Category, category)) ||
+			!index.MatchesHints(s.Hints, hints) ||
+			slices.ContainsFunc(pinned, func(r Result) bool { return r.Summary.ID == s.ID }) {
+			continue
+		}
+		pinned = append(pinned, Result{Summary: s, ScoreType: d.scoreType})
+	}
+	if len(pinned) == 0 {
+		return results
+	}
+
+	slices.SortStableFunc(pinned, func(a, b Result) int { return rank(a.Summary) - rank(b.Summary) })
+	for i := range pinned {
+		pinned[i].Pinned = true
+	}
+	out := append(pinned, rest...)
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// exactMatchSummaries returns the visible tools whose ID is text or whose
+// name is text in some namespace, in ID order.
+func (d *Discovery) exactMatchSummaries(principal, text string) []index.Summary {
+	lookup, ok := d.idx.(summaryLookup)
+	if !ok {
+		return nil
+	}
+	ids := []string{text}
+	if _, err := index.ParseToolID(text); err == nil && !strings.Contains(text, index.ToolIDSeparator) {
+		namespaces, _ := d.idx.ListNamespaces()
+		for _, ns := range namespaces {
+			ids = append(ids, ns+index.ToolIDSeparator+text)
+		}
+	}
+	slices.Sort(ids)
+	return lookup.SummariesFor(principal, ids)
+}
@@ -0,0 +1,55 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/semantic"
+)
+
+// pushdownSearch runs a query through the index's native search. In hybrid
+// mode the query is embedded first, so the index can rank semantically or
+// decline with index.ErrPushdownUnsupported; the returned context then
+// carries the embedding (see semantic.WithQueryEmbedding) so the in-process
+// fallback does not embed the query again. Category and hint filters are
+// not pushed down.
+func (d *Discovery) pushdownSearch(ctx context.Context, principal, query string, limit int) (Results, context.Context, error) {
+	hybrid := d.compositeS != nil
+	text, filters := index.ParseMetadataFilters(query)
+	if _, category := index.ParseCategoryFilter(text); category != "" {
+		return nil, ctx, index.ErrPushdownUnsupported
+	}
+	if _, hints := index.ParseHintFilters(text); len(hints) > 0 {
+		return nil, ctx, index.ErrPushdownUnsupported
+	}
+	q := index.PushdownQuery{
+		Principal: principal,
+		Text:      d.expandQuery(text, hybrid),
+		Filters:   filters,
+		Limit:     limit,
+	}
+	if hybrid {
+		if d.queryEmbed == nil {
+			return nil, ctx, index.ErrPushdownUnsupported
+		}
+		vec, err := d.queryEmbed.Embed(ctx, q.Text)
+		if err != nil {
+			return nil, ctx, fmt.Errorf("embed query: %w", err)
+		}
+		q.Vector = vec
+	}
+
+	found, err := d.pushdown.PushdownSearch(ctx, q)
+	if err != nil {
+		if q.Vector != nil {
+			ctx = semantic.WithQueryEmbedding(ctx, d.queryEmbed, q.Text, q.Vector)
+		}
+		return nil, ctx, err
+	}
+	results := make(Results, len(found))
+	for i, r := range found {
+		results[i] = Result{Summary: r.Summary, Score: r.Score, ScoreType: ScorePushdown}
+	}
+	return results, ctx, nil
+}
@@ -0,0 +1,186 @@
+package discovery
+
+import (
+	"container/list"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Query cache defaults used when QueryCacheOptions fields are zero.
+const (
+	DefaultQueryCacheMaxEntries = 256
+	DefaultQueryCacheTTL        = time.Minute
+)
+
+// QueryCacheOptions configures a QueryCache.
+type QueryCacheOptions struct {
+	// MaxEntries bounds the number of cached queries; the least recently
+	// used entry is evicted beyond it. Default: DefaultQueryCacheMaxEntries.
+	MaxEntries int
+
+	// TTL expires cached results, bounding how stale time-dependent state
+	// such as maintenance windows and rollouts can be.
+	// Default: DefaultQueryCacheTTL.
+	TTL time.Duration
+
+	// Now returns the current time for TTL expiry. Default: time.Now.
+	Now func() time.Time
+}
+
+// QueryCacheStats reports QueryCache activity.
+type QueryCacheStats struct {
+	Entries       int    `json:"entries"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// QueryCache is an in-process LRU of Search results for Options.QueryCache.
+// Entries are keyed by principal, query text, metadata filters, limit, and
+// index version, and the whole cache is invalidated on every index change
+// event, so agents repeating the same queries skip ranking until the catalog
+// changes. Unlike Options.ResultCache, results are kept decoded and are
+// never shared between processes.
+//
+// A QueryCache belongs to one Discovery; do not share it between
+// instances. It is safe for concurrent use.
+type QueryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	generation uint64 // bumped by Invalidate
+	stats      QueryCacheStats
+}
+
+type queryCacheEntry struct {
+	key       string
+	results   Results
+	expiresAt time.Time
+}
+
+// NewQueryCache creates a query cache.
+func NewQueryCache(opts ...QueryCacheOptions) *QueryCache {
+	var opt QueryCacheOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxEntries <= 0 {
+		opt.MaxEntries = DefaultQueryCacheMaxEntries
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = DefaultQueryCacheTTL
+	}
+	if opt.Now == nil {
+		opt.Now = time.Now
+	}
+	return &QueryCache{
+		maxEntries: opt.MaxEntries,
+		ttl:        opt.TTL,
+		now:        opt.Now,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the results cached under key and the cache
+// generation to pass to put.
+func (c *QueryCache) get(key string) (Results, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if ok {
+		entry := elem.Value.(*queryCacheEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.order.MoveToFront(elem)
+			c.stats.Hits++
+			return slices.Clone(entry.results), c.generation, true
+		}
+		c.removeLocked(elem)
+	}
+	c.stats.Misses++
+	return nil, c.generation, false
+}
+
+// put caches results under key unless the cache was invalidated since the
+// get that returned generation, which would otherwise let a search racing
+// an index change store stale results.
+func (c *QueryCache) put(key string, generation uint64, results Results) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	entry := &queryCacheEntry{key: key, results: slices.Clone(results), expiresAt: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeLocked(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *QueryCache) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*queryCacheEntry).key)
+}
+
+// Invalidate drops every cached query. Discovery calls it on index change
+// events and when the ranking model changes.
+func (c *QueryCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	c.generation++
+	c.stats.Invalidations++
+}
+
+// Len returns the number of cached queries, including expired entries not
+// yet dropped.
+func (c *QueryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns cache counters.
+func (c *QueryCache) Stats() QueryCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// queryCacheKey returns the query cache key for a search. Metadata filters
+// are sorted so their order in the query does not matter.
+func (d *Discovery) queryCacheKey(principal, query string, limit, rerankK int) string {
+	text, filters := index.ParseMetadataFilters(query)
+	pairs := make([]string, 0, len(filters))
+	for key, value := range filters {
+		pairs = append(pairs, key+"="+value)
+	}
+	slices.Sort(pairs)
+	return strings.Join([]string{
+		strconv.FormatUint(index.Version(d.idx), 10),
+		strconv.Itoa(limit),
+		strconv.Itoa(rerankK),
+		principal,
+		d.expandQuery(text, d.compositeS != nil),
+		strings.Join(pairs, "\x01"),
+		d.duplicatesFingerprint(),
+	}, "\x00")
+}
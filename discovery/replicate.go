@@ -0,0 +1,332 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrInvalidReplica is returned by Replicate when the source or target is
+// missing, or the source replicates into itself.
+var ErrInvalidReplica = errcode.New(errcode.InvalidArgument, "discovery: invalid replica")
+
+// ReplicaTarget receives the changes mirrored by a Replica. *Discovery
+// implements it; to mirror into another process, implement it with a client
+// for that process's registration API.
+type ReplicaTarget interface {
+	RegisterTools(regs []index.ToolRegistration) error
+	UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error
+	GetAllBackends(id string) ([]model.ToolBackend, error)
+	RegisterDoc(toolID string, doc tooldoc.DocEntry) error
+	RemoveDoc(toolID string) error
+}
+
+var _ ReplicaTarget = (*Discovery)(nil)
+
+// ReplicateOptions configures a Replica.
+type ReplicateOptions struct {
+	// OnError is called when mirroring a tool or its documentation fails.
+	// The change is retried by the next Flush or Resync. Optional.
+	OnError func(toolID string, err error)
+
+	// ResyncInterval, when positive, re-copies the whole catalog
+	// periodically, repairing changes that failed to apply.
+	// Default: 0 (never).
+	ResyncInterval time.Duration
+}
+
+// Replica mirrors tool registrations, documentation, and examples from a
+// source Discovery into a ReplicaTarget, such as a warm standby or a read
+// replica. It subscribes to the source's change notifications and copies
+// the current state of each changed tool, so bursts of changes to one tool
+// coalesce and the target converges even if events are reordered.
+//
+// Replica is safe for concurrent use.
+type Replica struct {
+	src  *Discovery
+	dst  ReplicaTarget
+	opts ReplicateOptions
+
+	mu    sync.Mutex
+	tools map[string]struct{} // tool IDs awaiting sync
+	docs  map[string]struct{} // doc IDs awaiting sync
+	wake  chan struct{}
+
+	syncMu sync.Mutex // serializes applying changes to dst
+
+	unsubscribe []func()
+	cancel      context.CancelFunc
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+// Replicate copies src's catalog into dst and keeps dst in sync until ctx
+// is done or the Replica is closed. It returns once the initial copy has
+// been applied, or with its error.
+//
+// dst should start empty or hold an older copy of src: tools that exist
+// only in dst are left in place.
+func Replicate(ctx context.Context, src *Discovery, dst ReplicaTarget, opts ...ReplicateOptions) (*Replica, error) {
+	if src == nil || dst == nil {
+		return nil, ErrInvalidReplica
+	}
+	if d, ok := dst.(*Discovery); ok && d == src {
+		return nil, ErrInvalidReplica
+	}
+	var opt ReplicateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	r := &Replica{
+		src:   src,
+		dst:   dst,
+		opts:  opt,
+		tools: make(map[string]struct{}),
+		docs:  make(map[string]struct{}),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	// Subscribe before copying so no change falls between the two.
+	r.unsubscribe = []func(){
+		src.OnChange(r.handleToolChange),
+		src.OnDocChange(r.handleDocChange),
+	}
+	r.markAll()
+	if err := r.Flush(ctx); err != nil {
+		r.stopListening()
+		close(r.done)
+		return nil, err
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r.run(ctx)
+	return r, nil
+}
+
+// Flush applies every change observed so far to the target and returns the
+// errors encountered, joined. Changes that fail are reported to OnError and
+// kept pending, so the next Flush or Resync retries them.
+func (r *Replica) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+
+	r.mu.Lock()
+	tools := sortedSet(r.tools)
+	docs := sortedSet(r.docs)
+	clear(r.tools)
+	clear(r.docs)
+	r.mu.Unlock()
+
+	// Tools first, so docs referencing them (SeeAlso) resolve in the target.
+	var errs []error
+	var failedTools, failedDocs []string
+	for _, id := range tools {
+		if err := r.syncTool(id); err != nil {
+			errs = append(errs, r.report(id, err))
+			failedTools = append(failedTools, id)
+		}
+	}
+	for _, id := range docs {
+		if err := r.syncDoc(id); err != nil {
+			errs = append(errs, r.report(id, err))
+			failedDocs = append(failedDocs, id)
+		}
+	}
+	if len(errs) > 0 {
+		r.mu.Lock()
+		for _, id := range failedTools {
+			r.tools[id] = struct{}{}
+		}
+		for _, id := range failedDocs {
+			r.docs[id] = struct{}{}
+		}
+		r.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Resync re-copies every tool and doc in the source to the target.
+func (r *Replica) Resync(ctx context.Context) error {
+	r.markAll()
+	return r.Flush(ctx)
+}
+
+// Close stops replicating and waits for an in-flight sync to finish. It is
+// safe to call more than once. Changes not yet applied are dropped.
+func (r *Replica) Close() error {
+	r.closeOnce.Do(func() {
+		r.stopListening()
+		r.cancel()
+		<-r.done
+	})
+	return nil
+}
+
+func (r *Replica) run(ctx context.Context) {
+	defer close(r.done)
+	var tick <-chan time.Time
+	if r.opts.ResyncInterval > 0 {
+		ticker := time.NewTicker(r.opts.ResyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			r.stopListening()
+			return
+		case <-r.wake:
+			_ = r.Flush(ctx)
+		case <-tick:
+			_ = r.Resync(ctx)
+		}
+	}
+}
+
+func (r *Replica) stopListening() {
+	for _, unsubscribe := range r.unsubscribe {
+		unsubscribe()
+	}
+}
+
+func (r *Replica) handleToolChange(ev index.ChangeEvent) {
+	if ev.ToolID == "" {
+		return // refresh events carry no state to copy
+	}
+	r.mark(r.tools, ev.ToolID)
+}
+
+func (r *Replica) handleDocChange(ev tooldoc.ChangeEvent) {
+	r.mark(r.docs, ev.ToolID)
+}
+
+func (r *Replica) mark(set map[string]struct{}, id string) {
+	r.mu.Lock()
+	set[id] = struct{}{}
+	r.mu.Unlock()
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Replica) markAll() {
+	tools := r.src.toolIDs()
+	docs := r.src.docs.DocIDs()
+	r.mu.Lock()
+	for _, id := range tools {
+		r.tools[id] = struct{}{}
+	}
+	for _, id := range docs {
+		r.docs[id] = struct{}{}
+	}
+	r.mu.Unlock()
+}
+
+// syncTool makes the target's backends and metadata for id match the
+// source. New backends are registered before stale ones are removed, so a
+// tool moving between backends never disappears from the target.
+func (r *Replica) syncTool(id string) error {
+	want, err := r.src.idx.GetAllBackends(id)
+	if err != nil && !errors.Is(err, index.ErrNotFound) {
+		return err
+	}
+	have, err := r.dst.GetAllBackends(id)
+	if err != nil && !errors.Is(err, index.ErrNotFound) {
+		return err
+	}
+
+	if len(want) > 0 {
+		tool, _, err := r.src.idx.GetTool(id)
+		if err != nil {
+			return err
+		}
+		var metadata map[string]string
+		if m, ok := r.src.idx.(interface {
+			GetMetadata(id string) (map[string]string, error)
+		}); ok {
+			if metadata, err = m.GetMetadata(id); err != nil {
+				return err
+			}
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+		}
+		regs := make([]index.ToolRegistration, len(want))
+		for i, backend := range want {
+			regs[i] = index.ToolRegistration{Tool: tool, Backend: backend, Metadata: metadata}
+		}
+		if err := r.dst.RegisterTools(regs); err != nil {
+			return err
+		}
+	}
+
+	for _, backend := range have {
+		key := index.BackendID(backend)
+		if slices.ContainsFunc(want, func(b model.ToolBackend) bool { return b.Kind == backend.Kind && index.BackendID(b) == key }) {
+			continue
+		}
+		if err := r.dst.UnregisterBackend(id, backend.Kind, key); err != nil && !errors.Is(err, index.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Replica) syncDoc(id string) error {
+	doc, err := r.src.docs.GetDoc(id)
+	if errors.Is(err, tooldoc.ErrNotFound) {
+		if err := r.dst.RemoveDoc(id); err != nil && !errors.Is(err, tooldoc.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.dst.RegisterDoc(id, doc)
+}
+
+func (r *Replica) report(id string, err error) error {
+	if r.opts.OnError != nil {
+		r.opts.OnError(id, err)
+	}
+	return err
+}
+
+// toolIDs returns every tool ID in the index, including tools hidden by
+// rollouts when the index can list them.
+func (d *Discovery) toolIDs() []string {
+	if l, ok := d.idx.(interface{ ToolIDs() []string }); ok {
+		return l.ToolIDs()
+	}
+	summaries, err := d.idx.Search("", 1000000)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, len(summaries))
+	for i, s := range summaries {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func sortedSet(set map[string]struct{}) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
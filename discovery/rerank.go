@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// DefaultRerankerTopK is the number of results reordered by a Reranker when
+// Options.RerankerTopK is zero.
+const DefaultRerankerTopK = 20
+
+// Reranker reorders search candidates with a model that reads the query and
+// each candidate together, such as a cross-encoder or an LLM call. It runs
+// after retrieval and any RankingModel, on the top-K results only, since
+// such models are too slow to score the whole catalog.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Context: implementations must honor cancellation and deadlines.
+//   - Output: the returned results are the candidates in their new order,
+//     best first, optionally with new Scores. Candidates left out are
+//     dropped, and results whose ID is not a candidate are ignored, so a
+//     model cannot introduce tools.
+//   - Errors: a returned error fails the search.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, candidates []Result) ([]Result, error)
+}
+
+// RerankerFunc adapts a function to a Reranker.
+type RerankerFunc func(ctx context.Context, query string, candidates []Result) ([]Result, error)
+
+// Rerank calls f.
+func (f RerankerFunc) Rerank(ctx context.Context, query string, candidates []Result) ([]Result, error) {
+	return f(ctx, query, candidates)
+}
+
+// SearchOption configures a single SearchWithOptions or SearchForWithOptions
+// call.
+type SearchOption func(*searchOptions)
+
+// OptionSearcher is implemented by searchers that accept SearchOptions, such
+// as *Discovery and *HistoricalView. Search interfaces that take only a
+// query and limit, like discoverytest.Searcher and eval.Searcher, stay easy
+// to fake; code that needs per-call options can check for OptionSearcher.
+type OptionSearcher interface {
+	SearchWithOptions(ctx context.Context, query string, limit int, opts ...SearchOption) (Results, error)
+}
+
+var (
+	_ OptionSearcher = (*Discovery)(nil)
+	_ OptionSearcher = (*HistoricalView)(nil)
+)
+
+type searchOptions struct {
+	rerankK   int
+	rerankSet bool
+}
+
+// WithRerank reorders the top k results of the search with
+// Options.Reranker, overriding Options.RerankerTopK. k <= 0 skips
+// reranking. Ignored without a Reranker.
+func WithRerank(k int) SearchOption {
+	return func(o *searchOptions) {
+		o.rerankK = k
+		o.rerankSet = true
+	}
+}
+
+// rerankK resolves the number of results the Reranker reorders for a
+// search with opts; 0 means no reranking.
+func (d *Discovery) rerankK(opts []SearchOption) int {
+	if d.reranker == nil {
+		return 0
+	}
+	o := searchOptions{rerankK: d.rerankerTopK}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return max(o.rerankK, 0)
+}
+
+// applyReranker reorders the top k results with the Reranker. The query's
+// metadata, category, and hint filters are not passed on.
+func (d *Discovery) applyReranker(ctx context.Context, query string, results Results, k int) (Results, error) {
+	if k <= 0 || len(results) == 0 {
+		return results, nil
+	}
+	top := results[:min(len(results), k)]
+	text, _ := index.ParseMetadataFilters(query)
+	text, _ = index.ParseCategoryFilter(text)
+	text, _ = index.ParseHintFilters(text)
+	ctx, span := d.telemetry.tracer.Start(ctx, "discovery.Rerank", trace.WithAttributes(AttrLimit.Int(len(top))))
+	reranked, err := d.reranker.Rerank(ctx, strings.TrimSpace(text), slices.Clone([]Result(top)))
+	endSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("rerank: %w", err)
+	}
+
+	candidates := make(map[string]Result, len(top))
+	for _, r := range top {
+		candidates[r.Summary.ID] = r
+	}
+	out := make(Results, 0, len(results))
+	for _, r := range reranked {
+		candidate, ok := candidates[r.Summary.ID]
+		if !ok {
+			continue
+		}
+		delete(candidates, r.Summary.ID)
+		candidate.Score = r.Score
+		candidate.ScoreType = ScoreRerank
+		out = append(out, candidate)
+	}
+	return append(out, results[len(top):]...), nil
+}
@@ -1,6 +1,8 @@
 package discovery
 
 import (
+	"slices"
+
 	"github.com/jonwraymond/tooldiscovery/index"
 )
 
@@ -16,6 +18,18 @@ const (
 
 	// ScoreHybrid indicates the score is a weighted combination of BM25 and embedding.
 	ScoreHybrid ScoreType = "hybrid"
+
+	// ScorePushdown indicates the score came from an index's native search
+	// (see index.SearchPushdown).
+	ScorePushdown ScoreType = "pushdown"
+
+	// ScoreLTR indicates the score came from a learning-to-rank model (see
+	// Options.RankingModel).
+	ScoreLTR ScoreType = "ltr"
+
+	// ScoreRerank indicates the score came from a Reranker (see
+	// Options.Reranker).
+	ScoreRerank ScoreType = "rerank"
 )
 
 // Result represents a unified search result with score details.
@@ -29,11 +43,40 @@ type Result struct {
 
 	// ScoreType indicates how the Score was computed.
 	ScoreType ScoreType
+
+	// Pinned reports that the result was moved to the front because its ID
+	// or name equals the query (see Options.DisableExactMatchPinning). Its
+	// Score is left as ranked, or zero if ranking did not return it.
+	Pinned bool
+
+	// Partial reports that the search hit Options.SearchDeadline before
+	// scoring every document, so better matches may exist.
+	Partial bool
+
+	// AlternateIDs lists the tools collapsed into this result because they
+	// are marked as the same logical tool (see Discovery.MarkDuplicates),
+	// sorted.
+	AlternateIDs []string
+
+	// RewrittenQuery is the query that was scored when Options.QueryRewriter
+	// changed it, for debugging relevance. It is empty when the query was
+	// not rewritten.
+	RewrittenQuery string
+
+	// semantic is the embedding similarity HybridSearcher scored the
+	// result with, kept for the ranking model's semantic feature.
+	semantic *float64
 }
 
 // Results is a slice of Result with helper methods.
 type Results []Result
 
+// Partial reports whether the results were cut short by a search deadline.
+// An empty result set never reports partial.
+func (r Results) Partial() bool {
+	return slices.ContainsFunc(r, func(result Result) bool { return result.Partial })
+}
+
 // IDs returns just the tool IDs from the results.
 func (r Results) IDs() []string {
 	ids := make([]string, len(r))
@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// DefaultResultCacheTTL is the result cache TTL used when
+// Options.ResultCacheTTL is zero.
+const DefaultResultCacheTTL = time.Minute
+
+// resultCacheKey returns the result cache key for a search. Searches are not
+// cacheable without a cache or an index fingerprint to key on, since cached
+// results could then outlive index changes. The fingerprint, unlike the
+// index version, is the same on every replica holding the same catalog.
+func (d *Discovery) resultCacheKey(principal, query string, limit, rerankK int) (string, bool) {
+	if d.results == nil {
+		return "", false
+	}
+	fingerprint := index.Fingerprint(d.idx)
+	if fingerprint == "" {
+		return "", false
+	}
+	// Key on the expanded query so alias changes take effect immediately.
+	expanded := d.expandQuery(query, d.compositeS != nil)
+	sum := sha256.Sum256([]byte(principal + "\x00" + expanded + "\x00" + d.duplicatesFingerprint()))
+	return "results:" + string(d.scoreType) + ":" + fingerprint + ":" +
+		strconv.FormatUint(d.rankingGeneration(), 10) + ":" +
+		strconv.Itoa(limit) + ":" + strconv.Itoa(rerankK) + ":" + hex.EncodeToString(sum[:]), true
+}
+
+// cachedResults returns cached results. Cache failures and undecodable
+// entries are treated as misses.
+func (d *Discovery) cachedResults(ctx context.Context, key string) (Results, bool) {
+	data, ok, err := d.results.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var results Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, false
+	}
+	return results, true
+}
+
+// cacheResults stores results on a best-effort basis.
+func (d *Discovery) cacheResults(ctx context.Context, key string, results Results) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return
+	}
+	_ = d.results.Set(ctx, key, data, d.resultsTTL)
+}
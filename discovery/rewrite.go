@@ -0,0 +1,85 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// QueryRewriter transforms a search query before it is scored, for example
+// to strip filler words, translate non-English queries, or expand domain
+// abbreviations.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Context: implementations must honor cancellation and deadlines.
+//   - Input: the query is passed as given, including any metadata,
+//     category, and hint filter terms, which rewriters should preserve.
+//   - Output: returning the query unchanged is a no-op. SearchPage cursors
+//     are tied to the rewritten query, so rewriters should be
+//     deterministic.
+//   - Errors: a returned error fails the search.
+type QueryRewriter interface {
+	RewriteQuery(ctx context.Context, query string) (string, error)
+}
+
+// QueryRewriterFunc adapts a function to a QueryRewriter.
+type QueryRewriterFunc func(ctx context.Context, query string) (string, error)
+
+// RewriteQuery calls f.
+func (f QueryRewriterFunc) RewriteQuery(ctx context.Context, query string) (string, error) {
+	return f(ctx, query)
+}
+
+// ChainQueryRewriters returns a QueryRewriter that applies rewriters in
+// order, each receiving the previous one's output. Nil rewriters are
+// skipped, and the first error stops the chain.
+func ChainQueryRewriters(rewriters ...QueryRewriter) QueryRewriter {
+	chain := make(queryRewriterChain, 0, len(rewriters))
+	for _, r := range rewriters {
+		if r != nil {
+			chain = append(chain, r)
+		}
+	}
+	return chain
+}
+
+type queryRewriterChain []QueryRewriter
+
+func (c queryRewriterChain) RewriteQuery(ctx context.Context, query string) (string, error) {
+	for _, r := range c {
+		var err error
+		if query, err = r.RewriteQuery(ctx, query); err != nil {
+			return "", err
+		}
+	}
+	return query, nil
+}
+
+// rewriteQuery applies Options.QueryRewriter to query.
+func (d *Discovery) rewriteQuery(ctx context.Context, query string) (string, error) {
+	if d.rewriter == nil {
+		return query, nil
+	}
+	ctx, span := d.telemetry.tracer.Start(ctx, "discovery.RewriteQuery")
+	rewritten, err := d.rewriter.RewriteQuery(ctx, query)
+	endSpan(span, err)
+	if err != nil {
+		return "", fmt.Errorf("rewrite query: %w", err)
+	}
+	return rewritten, nil
+}
+
+// markRewritten records rewritten as the RewrittenQuery of each result when
+// it differs from query. results may be shared with a cache, so it returns
+// a copy rather than modifying them.
+func markRewritten(results Results, query, rewritten string) Results {
+	if rewritten == query || len(results) == 0 {
+		return results
+	}
+	out := slices.Clone(results)
+	for i := range out {
+		out[i].RewrittenQuery = rewritten
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/semantic"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// ErrSelfTestFailed is wrapped by SelfTestReport.Err for every failed check.
+var ErrSelfTestFailed = errcode.New(errcode.Internal, "discovery: self-test failed")
+
+// Self-test check names.
+const (
+	CheckEmbedder = "embedder"
+	CheckSearcher = "searcher"
+	CheckDocStore = "docstore"
+)
+
+// selfTestText is embedded and searched by SelfTest. It is short so the
+// embedding call costs as little as possible.
+const selfTestText = "self test"
+
+// CheckStatus is the outcome of one self-test check.
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckFailed  CheckStatus = "failed"
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// CheckResult is the outcome of one self-test check.
+type CheckResult struct {
+	// Name identifies the component: CheckEmbedder, CheckSearcher, or
+	// CheckDocStore. Embedder checks for routes and the vector index are
+	// suffixed, such as "embedder/route-0".
+	Name     string        `json:"name"`
+	Status   CheckStatus   `json:"status"`
+	Duration time.Duration `json:"duration"`
+
+	// Detail describes a passing or skipped check, such as the embedding
+	// dimensions.
+	Detail string `json:"detail,omitempty"`
+
+	// Error describes why the check failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SelfTestReport is the result of Discovery.SelfTest.
+type SelfTestReport struct {
+	// Healthy reports whether no check failed.
+	Healthy bool          `json:"healthy"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// Err returns nil when the report is healthy, and otherwise the failed
+// checks joined into one error wrapping ErrSelfTestFailed.
+func (r SelfTestReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if c.Status == CheckFailed {
+			errs = append(errs, fmt.Errorf("%w: %s: %s", ErrSelfTestFailed, c.Name, c.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// namedEmbedder is an embedder checked by SelfTest under name.
+type namedEmbedder struct {
+	name     string
+	embedder semantic.Embedder
+}
+
+func selfTestEmbedders(def semantic.Embedder, routes []EmbedderRoute) []namedEmbedder {
+	var out []namedEmbedder
+	if def != nil {
+		out = append(out, namedEmbedder{name: CheckEmbedder, embedder: def})
+	}
+	for i, route := range routes {
+		out = append(out, namedEmbedder{name: fmt.Sprintf("%s/route-%d", CheckEmbedder, i), embedder: route.Embedder})
+	}
+	return out
+}
+
+// SelfTest exercises each configured component and reports its health, so
+// deployments can fail fast on a misconfigured embedder (a bad API key, an
+// unreachable endpoint) before serving agents:
+//   - every embedder, including route and vector index embedders, embeds a
+//     short text and must return a non-empty, finite vector;
+//   - the searcher runs a canned query against the index;
+//   - the doc store describes an indexed tool at schema detail.
+//
+// Embedder checks are skipped for BM25-only search and the doc store check
+// when the index is empty. SelfTest makes real embedding calls, so run it at
+// startup rather than on every readiness probe.
+func (d *Discovery) SelfTest(ctx context.Context) SelfTestReport {
+	var checks []CheckResult
+	embedders := d.embedders
+	if d.vectors != nil {
+		embedders = append([]namedEmbedder{{name: CheckEmbedder + "/vector-index", embedder: d.vectors.Embedder()}}, embedders...)
+	}
+	if len(embedders) == 0 {
+		checks = append(checks, CheckResult{Name: CheckEmbedder, Status: CheckSkipped, Detail: "no embedder configured"})
+	}
+	for _, e := range embedders {
+		checks = append(checks, runCheck(e.name, func() (string, error) {
+			return checkEmbedder(ctx, e.embedder)
+		}))
+	}
+
+	checks = append(checks, runCheck(CheckSearcher, func() (string, error) {
+		results, err := d.Search(ctx, selfTestText, 1)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d results", len(results)), nil
+	}))
+
+	checks = append(checks, d.checkDocStore(ctx))
+
+	report := SelfTestReport{Healthy: true, Checks: checks}
+	for _, c := range checks {
+		if c.Status == CheckFailed {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func checkEmbedder(ctx context.Context, embedder semantic.Embedder) (string, error) {
+	vec, err := embedder.Embed(ctx, selfTestText)
+	if err != nil {
+		return "", err
+	}
+	if len(vec) == 0 {
+		return "", errors.New("empty embedding")
+	}
+	for _, v := range vec {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return "", errors.New("embedding contains non-finite values")
+		}
+	}
+	return fmt.Sprintf("%d dimensions", len(vec)), nil
+}
+
+func (d *Discovery) checkDocStore(ctx context.Context) CheckResult {
+	if err := ctx.Err(); err != nil {
+		return CheckResult{Name: CheckDocStore, Status: CheckFailed, Error: err.Error()}
+	}
+	tools, err := d.idx.Search("", 1)
+	if err != nil {
+		return CheckResult{Name: CheckDocStore, Status: CheckFailed, Error: err.Error()}
+	}
+	if len(tools) == 0 {
+		return CheckResult{Name: CheckDocStore, Status: CheckSkipped, Detail: "no tools indexed"}
+	}
+	return runCheck(CheckDocStore, func() (string, error) {
+		if _, err := d.docs.DescribeTool(tools[0].ID, tooldoc.DetailSchema); err != nil {
+			return "", err
+		}
+		return "described " + tools[0].ID, nil
+	})
+}
+
+// runCheck times check and records its outcome.
+func runCheck(name string, check func() (string, error)) CheckResult {
+	start := time.Now()
+	detail, err := check()
+	result := CheckResult{Name: name, Status: CheckOK, Duration: time.Since(start), Detail: detail}
+	if err != nil {
+		result.Status = CheckFailed
+		result.Detail = ""
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// InstrumentationName is the OpenTelemetry tracer and meter name used by
+// Discovery.
+const InstrumentationName = "github.com/jonwraymond/tooldiscovery/discovery"
+
+// Span and metric attribute keys recorded by Discovery.
+const (
+	AttrToolID       = attribute.Key("discovery.tool_id")
+	AttrLimit        = attribute.Key("discovery.limit")
+	AttrResults      = attribute.Key("discovery.results")
+	AttrScoreType    = attribute.Key("discovery.score_type")
+	AttrCacheHit     = attribute.Key("discovery.cache_hit")
+	AttrDetail       = attribute.Key("discovery.detail_level")
+	AttrProfile      = attribute.Key("discovery.profile")
+	AttrError        = attribute.Key("error")
+	AttrCatalogLimit = attribute.Key("discovery.catalog_limit")
+)
+
+// telemetry holds the OpenTelemetry instruments. Nil providers record
+// nothing.
+type telemetry struct {
+	tracer         trace.Tracer
+	meter          metric.Meter
+	searchDuration metric.Float64Histogram
+	searchResults  metric.Int64Histogram
+	searches       metric.Int64Counter
+	zeroResults    metric.Int64Counter
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) (*telemetry, error) {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(InstrumentationName)
+	t := &telemetry{tracer: tp.Tracer(InstrumentationName), meter: meter}
+	var err error
+	if t.searchDuration, err = meter.Float64Histogram("discovery.search.duration",
+		metric.WithUnit("s"), metric.WithDescription("Search latency.")); err != nil {
+		return nil, err
+	}
+	if t.searchResults, err = meter.Int64Histogram("discovery.search.results",
+		metric.WithUnit("{result}"), metric.WithDescription("Results returned per search.")); err != nil {
+		return nil, err
+	}
+	if t.searches, err = meter.Int64Counter("discovery.search.requests",
+		metric.WithUnit("{search}"), metric.WithDescription("Searches performed.")); err != nil {
+		return nil, err
+	}
+	if t.zeroResults, err = meter.Int64Counter("discovery.search.zero_results",
+		metric.WithUnit("{search}"), metric.WithDescription("Searches that returned no results; divide by discovery.search.requests for the zero-result rate.")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// observeRejections reports registrations rejected by catalog limits in
+// the sources that implement index.LimitReporter.
+func (t *telemetry) observeRejections(sources ...any) error {
+	var reporters []index.LimitReporter
+	for _, source := range sources {
+		if r, ok := source.(index.LimitReporter); ok {
+			reporters = append(reporters, r)
+		}
+	}
+	if len(reporters) == 0 {
+		return nil
+	}
+	_, err := t.meter.Int64ObservableCounter("discovery.registration.rejected",
+		metric.WithUnit("{registration}"), metric.WithDescription("Registrations rejected by catalog limits."),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			totals := make(map[string]uint64)
+			for _, r := range reporters {
+				for limit, n := range r.LimitRejections() {
+					totals[limit] += n
+				}
+			}
+			for limit, n := range totals {
+				o.Observe(int64(n), metric.WithAttributes(AttrCatalogLimit.String(limit)))
+			}
+			return nil
+		}))
+	return err
+}
+
+// recordSearch ends a search span and records search metrics.
+func (t *telemetry) recordSearch(ctx context.Context, span trace.Span, start time.Time, scoreType ScoreType, results Results, cacheHit bool, err error) {
+	attrs := metric.WithAttributes(AttrScoreType.String(string(scoreType)), AttrError.Bool(err != nil))
+	t.searchDuration.Record(ctx, time.Since(start).Seconds(), attrs)
+	t.searches.Add(ctx, 1, attrs)
+	if err == nil {
+		t.searchResults.Record(ctx, int64(len(results)), attrs)
+		if len(results) == 0 {
+			t.zeroResults.Add(ctx, 1, attrs)
+		}
+	}
+	span.SetAttributes(AttrResults.Int(len(results)), AttrCacheHit.Bool(cacheHit))
+	endSpan(span, err)
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
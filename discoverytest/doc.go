@@ -0,0 +1,35 @@
+// Package discoverytest provides golden-file regression helpers for search
+// rankings, so catalogs built on this library can detect ranking drift when
+// they upgrade it or change their tool metadata.
+//
+// # Usage
+//
+// Record the expected rankings for a set of queries in a golden file and
+// assert against it from a test:
+//
+//	func TestRanking(t *testing.T) {
+//	    disc := buildCatalog(t)
+//	    discoverytest.AssertGolden(t, disc, "testdata/ranking.golden.json",
+//	        []discoverytest.Query{
+//	            {Query: "create issue", Limit: 5},
+//	            {Name: "vcs", Query: "commit changes", Limit: 3},
+//	        },
+//	        discoverytest.Options{Tolerance: discoverytest.Tolerance{TopK: 3, ScoreDelta: 0.01}},
+//	    )
+//	}
+//
+// Run the test once with DISCOVERYTEST_UPDATE=1 (or Options.Update) to write
+// the golden file, review it, and commit it. Later runs fail with a
+// side-by-side diff when a ranking drifts beyond the tolerance.
+//
+// # Tolerance
+//
+// [Tolerance] controls how strict the comparison is:
+//   - TopK compares only the leading results, ignoring churn in the tail
+//   - ScoreDelta allows scores to move by a small absolute amount
+//   - IgnoreScores compares only the order of tool IDs
+//   - TieEpsilon lets results whose golden scores are that close appear in
+//     either order
+//
+// Use [Diff] directly to compare rankings outside of a test.
+package discoverytest
@@ -0,0 +1,296 @@
+package discoverytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/discovery"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value
+// other than "0" or "false", makes AssertGolden rewrite golden files instead
+// of comparing against them.
+const UpdateEnv = "DISCOVERYTEST_UPDATE"
+
+// Searcher is the search surface exercised by AssertGolden.
+// *discovery.Discovery satisfies it.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) (discovery.Results, error)
+}
+
+// Query is one search recorded in a golden file.
+type Query struct {
+	// Name identifies the query in the golden file. Defaults to Query.
+	Name string
+
+	// Query is the search text.
+	Query string
+
+	// Limit is the maximum number of results requested.
+	Limit int
+}
+
+func (q Query) key() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return q.Query
+}
+
+// Tolerance controls how closely results must match their golden ranking.
+// The zero value requires the same IDs, in the same order, with identical
+// scores; only results with equal golden scores may swap.
+type Tolerance struct {
+	// TopK compares only the first TopK results. Zero compares all.
+	TopK int
+
+	// ScoreDelta is the allowed absolute difference between golden and
+	// actual scores.
+	ScoreDelta float64
+
+	// IgnoreScores skips score comparison entirely.
+	IgnoreScores bool
+
+	// TieEpsilon treats golden results whose scores differ by at most
+	// TieEpsilon as tied; tied results may appear in any order.
+	TieEpsilon float64
+}
+
+// Options configures AssertGolden.
+type Options struct {
+	Tolerance Tolerance
+
+	// Update rewrites the golden file with the current rankings.
+	// Setting UpdateEnv has the same effect.
+	Update bool
+}
+
+// Ranked is one result in a golden ranking.
+type Ranked struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// GoldenQuery is a query and its recorded ranking.
+type GoldenQuery struct {
+	Name    string   `json:"name"`
+	Query   string   `json:"query"`
+	Limit   int      `json:"limit"`
+	Results []Ranked `json:"results"`
+}
+
+// GoldenFile is the on-disk golden format.
+type GoldenFile struct {
+	Queries []GoldenQuery `json:"queries"`
+}
+
+// Ranking converts search results to a golden ranking.
+func Ranking(results discovery.Results) []Ranked {
+	out := make([]Ranked, len(results))
+	for i, r := range results {
+		out[i] = Ranked{ID: r.Summary.ID, Score: r.Score}
+	}
+	return out
+}
+
+// AssertGolden runs each query against s and compares the rankings to the
+// golden file at path, reporting a readable diff for every query that drifts
+// beyond opts.Tolerance. In update mode it writes the file instead.
+func AssertGolden(t testing.TB, s Searcher, path string, queries []Query, opts Options) {
+	t.Helper()
+
+	ctx := context.Background()
+	current := GoldenFile{Queries: make([]GoldenQuery, 0, len(queries))}
+	for _, q := range queries {
+		results, err := s.Search(ctx, q.Query, q.Limit)
+		if err != nil {
+			t.Fatalf("discoverytest: search %q: %v", q.key(), err)
+		}
+		current.Queries = append(current.Queries, GoldenQuery{
+			Name:    q.key(),
+			Query:   q.Query,
+			Limit:   q.Limit,
+			Results: Ranking(results),
+		})
+	}
+
+	if opts.Update || updateFromEnv() {
+		if err := WriteGolden(path, current); err != nil {
+			t.Fatalf("discoverytest: %v", err)
+		}
+		t.Logf("discoverytest: updated %s", path)
+		return
+	}
+
+	golden, err := ReadGolden(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("discoverytest: golden file %s does not exist; rerun with %s=1 to create it", path, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("discoverytest: %v", err)
+	}
+
+	byName := make(map[string]GoldenQuery, len(golden.Queries))
+	for _, gq := range golden.Queries {
+		byName[gq.Name] = gq
+	}
+	for _, cq := range current.Queries {
+		gq, ok := byName[cq.Name]
+		if !ok {
+			t.Errorf("discoverytest: query %q is not in %s; rerun with %s=1 to record it", cq.Name, path, UpdateEnv)
+			continue
+		}
+		delete(byName, cq.Name)
+		if gq.Query != cq.Query || gq.Limit != cq.Limit {
+			t.Errorf("discoverytest: query %q changed (golden %q limit %d, now %q limit %d); rerun with %s=1",
+				cq.Name, gq.Query, gq.Limit, cq.Query, cq.Limit, UpdateEnv)
+			continue
+		}
+		if diff := Diff(gq.Results, cq.Results, opts.Tolerance); diff != "" {
+			t.Errorf("discoverytest: ranking for %q drifted from %s:\n%s", cq.Name, path, diff)
+		}
+	}
+	for _, gq := range golden.Queries {
+		if _, stale := byName[gq.Name]; stale {
+			t.Errorf("discoverytest: golden query %q in %s is no longer exercised; rerun with %s=1", gq.Name, path, UpdateEnv)
+		}
+	}
+}
+
+// Diff compares a ranking to its golden counterpart under tol. It returns ""
+// when they match, or a side-by-side table with mismatched rows marked "!"
+// followed by one line per problem.
+func Diff(want, got []Ranked, tol Tolerance) string {
+	n := max(len(want), len(got))
+	if tol.TopK > 0 {
+		n = min(n, tol.TopK)
+	}
+
+	groups := tieGroups(want, tol.TieEpsilon)
+	goldenScore := make(map[string]float64, len(want))
+	for _, r := range want {
+		goldenScore[r.ID] = r.Score
+	}
+
+	var problems []string
+	bad := make([]bool, n)
+	seen := make(map[string]bool, n)
+	for i := range n {
+		switch {
+		case i >= len(got):
+			bad[i] = true
+			problems = append(problems, fmt.Sprintf("#%d: missing %s", i+1, want[i].ID))
+			continue
+		case i >= len(want):
+			bad[i] = true
+			problems = append(problems, fmt.Sprintf("#%d: unexpected %s", i+1, got[i].ID))
+			continue
+		}
+
+		id := got[i].ID
+		if seen[id] {
+			bad[i] = true
+			problems = append(problems, fmt.Sprintf("#%d: duplicate %s", i+1, id))
+			continue
+		}
+		seen[id] = true
+		if !groups[i][id] {
+			bad[i] = true
+			problems = append(problems, fmt.Sprintf("#%d: got %s, want %s", i+1, id, want[i].ID))
+			continue
+		}
+		if !tol.IgnoreScores {
+			if delta := math.Abs(got[i].Score - goldenScore[id]); delta > tol.ScoreDelta {
+				bad[i] = true
+				problems = append(problems, fmt.Sprintf("#%d: %s score %s, want %s (±%s)",
+					i+1, id, formatScore(got[i].Score), formatScore(goldenScore[id]), formatScore(tol.ScoreDelta)))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "   %-4s %-40s %s\n", "#", "want", "got")
+	for i := range n {
+		mark := " "
+		if bad[i] {
+			mark = "!"
+		}
+		fmt.Fprintf(&b, " %s %-4d %-40s %s\n", mark, i+1, rankedCell(want, i), rankedCell(got, i))
+	}
+	for _, p := range problems {
+		fmt.Fprintf(&b, "%s\n", p)
+	}
+	return b.String()
+}
+
+// ReadGolden reads a golden file.
+func ReadGolden(path string) (GoldenFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GoldenFile{}, err
+	}
+	var golden GoldenFile
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return GoldenFile{}, fmt.Errorf("parse golden file %s: %w", path, err)
+	}
+	return golden, nil
+}
+
+// WriteGolden writes a golden file, creating parent directories as needed.
+func WriteGolden(path string, golden GoldenFile) error {
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// tieGroups returns, for each golden position, the set of IDs that may
+// appear there: the IDs of all results tied with it.
+func tieGroups(want []Ranked, epsilon float64) []map[string]bool {
+	groups := make([]map[string]bool, len(want))
+	for start := 0; start < len(want); {
+		end := start + 1
+		for end < len(want) && math.Abs(want[end].Score-want[start].Score) <= epsilon {
+			end++
+		}
+		group := make(map[string]bool, end-start)
+		for _, r := range want[start:end] {
+			group[r.ID] = true
+		}
+		for i := start; i < end; i++ {
+			groups[i] = group
+		}
+		start = end
+	}
+	return groups
+}
+
+func rankedCell(ranking []Ranked, i int) string {
+	if i >= len(ranking) {
+		return "-"
+	}
+	return ranking[i].ID + " " + formatScore(ranking[i].Score)
+}
+
+func formatScore(score float64) string {
+	return fmt.Sprintf("%.4g", score)
+}
+
+func updateFromEnv() bool {
+	v := os.Getenv(UpdateEnv)
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
@@ -0,0 +1,156 @@
+package discoverytest
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/discovery"
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+type fakeSearcher map[string][]Ranked
+
+func (f fakeSearcher) Search(_ context.Context, query string, limit int) (discovery.Results, error) {
+	var out discovery.Results
+	for _, r := range f[query] {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, discovery.Result{Summary: index.Summary{ID: r.ID}, Score: r.Score})
+	}
+	return out, nil
+}
+
+// recordingTB captures failures so AssertGolden's reporting can be checked.
+type recordingTB struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+	fatal  bool
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Logf(string, ...any) {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.mu.Lock()
+	r.fatal = true
+	r.mu.Unlock()
+	runtime.Goexit()
+}
+
+func runAssert(t *testing.T, s Searcher, path string, queries []Query, opts Options) *recordingTB {
+	t.Helper()
+	rec := &recordingTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		AssertGolden(rec, s, path, queries, opts)
+	}()
+	<-done
+	return rec
+}
+
+func TestAssertGolden_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "ranking.golden.json")
+	s := fakeSearcher{"issue": {{ID: "github:create_issue", Score: 3}, {ID: "jira:create", Score: 2}}}
+	queries := []Query{{Query: "issue", Limit: 5}}
+
+	rec := runAssert(t, s, path, queries, Options{})
+	if !rec.fatal || !strings.Contains(rec.errors[0], UpdateEnv) {
+		t.Fatalf("missing golden file errors = %v, want fatal mentioning %s", rec.errors, UpdateEnv)
+	}
+
+	if rec := runAssert(t, s, path, queries, Options{Update: true}); len(rec.errors) != 0 {
+		t.Fatalf("update errors = %v", rec.errors)
+	}
+	golden, err := ReadGolden(path)
+	if err != nil {
+		t.Fatalf("ReadGolden failed: %v", err)
+	}
+	if len(golden.Queries) != 1 || golden.Queries[0].Name != "issue" || len(golden.Queries[0].Results) != 2 {
+		t.Fatalf("golden = %+v", golden)
+	}
+
+	if rec := runAssert(t, s, path, queries, Options{}); len(rec.errors) != 0 {
+		t.Fatalf("unchanged ranking errors = %v", rec.errors)
+	}
+
+	s["issue"] = []Ranked{{ID: "jira:create", Score: 3}, {ID: "github:create_issue", Score: 2}}
+	rec = runAssert(t, s, path, queries, Options{})
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "got jira:create, want github:create_issue") {
+		t.Fatalf("drifted ranking errors = %v", rec.errors)
+	}
+}
+
+func TestAssertGolden_QuerySetChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ranking.golden.json")
+	s := fakeSearcher{"a": {{ID: "x", Score: 1}}, "b": {{ID: "y", Score: 1}}}
+	runAssert(t, s, path, []Query{{Query: "a", Limit: 1}}, Options{Update: true})
+
+	rec := runAssert(t, s, path, []Query{{Query: "b", Limit: 1}}, Options{})
+	if len(rec.errors) != 2 {
+		t.Fatalf("errors = %v, want new and stale query reported", rec.errors)
+	}
+	if !strings.Contains(rec.errors[0], `query "b" is not in`) || !strings.Contains(rec.errors[1], `golden query "a"`) {
+		t.Fatalf("errors = %v", rec.errors)
+	}
+
+	rec = runAssert(t, s, path, []Query{{Query: "a", Limit: 3}}, Options{})
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "changed") {
+		t.Fatalf("changed limit errors = %v", rec.errors)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	want := []Ranked{{ID: "a", Score: 3}, {ID: "b", Score: 2}, {ID: "c", Score: 1.99}, {ID: "d", Score: 1}}
+
+	tests := []struct {
+		name string
+		got  []Ranked
+		tol  Tolerance
+		ok   bool
+		msg  string
+	}{
+		{name: "identical", got: want, ok: true},
+		{name: "score drift", got: []Ranked{{"a", 3.05}, {"b", 2}, {"c", 1.99}, {"d", 1}}, msg: "a score 3.05, want 3"},
+		{name: "score within delta", got: []Ranked{{"a", 3.05}, {"b", 2}, {"c", 1.99}, {"d", 1}}, tol: Tolerance{ScoreDelta: 0.1}, ok: true},
+		{name: "ignore scores", got: []Ranked{{"a", 9}, {"b", 9}, {"c", 9}, {"d", 9}}, tol: Tolerance{IgnoreScores: true}, ok: true},
+		{name: "swap", got: []Ranked{{"a", 3}, {"c", 1.99}, {"b", 2}, {"d", 1}}, msg: "#2: got c, want b"},
+		{name: "swap within tie", got: []Ranked{{"a", 3}, {"c", 1.99}, {"b", 2}, {"d", 1}}, tol: Tolerance{TieEpsilon: 0.05}, ok: true},
+		{name: "tail ignored", got: []Ranked{{"a", 3}, {"b", 2}, {"x", 1}}, tol: Tolerance{TopK: 2}, ok: true},
+		{name: "missing", got: []Ranked{{"a", 3}, {"b", 2}, {"c", 1.99}}, msg: "#4: missing d"},
+		{name: "unexpected", got: append(append([]Ranked{}, want...), Ranked{"e", 0.5}), msg: "#5: unexpected e"},
+		{name: "duplicate", got: []Ranked{{"a", 3}, {"b", 2}, {"b", 2}, {"d", 1}}, tol: Tolerance{TieEpsilon: 0.05}, msg: "#3: duplicate b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Diff(want, tt.got, tt.tol)
+			if tt.ok {
+				if diff != "" {
+					t.Fatalf("Diff = %q, want match", diff)
+				}
+				return
+			}
+			if !strings.Contains(diff, tt.msg) {
+				t.Fatalf("Diff = %q, want it to contain %q", diff, tt.msg)
+			}
+			if !strings.Contains(diff, " ! ") {
+				t.Fatalf("Diff = %q, want a marked row", diff)
+			}
+		})
+	}
+}
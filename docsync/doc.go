@@ -0,0 +1,55 @@
+// Package docsync keeps tool documentation in sync with the systems where
+// writers author it, such as a Git repository, Notion, or Confluence.
+//
+// Tool docs registered in code drift from the guides technical writers
+// maintain elsewhere. A [Syncer] periodically pulls documents from one or
+// more [Source] adapters, maps each to a tool ID, and writes it to a
+// [Store] such as *tooldoc.InMemoryStore.
+//
+// # Sources
+//
+// [GitSource] is the reference adapter. It clones or pulls a repository and
+// reads every markdown file whose front matter names a tool:
+//
+//	---
+//	tool: github:create_issue
+//	summary: Creates a GitHub issue
+//	see_also: [github:list_issues]
+//	external_refs:
+//	  - https://docs.github.com/rest/issues
+//	owner: dev-experience
+//	owner_contact: "#devex"
+//	---
+//	Requires a token with the repo scope. Labels must already exist.
+//
+// The body becomes the tool's notes. See [ParseMarkdown] for the keys.
+// Adapters for other systems implement Source and can reuse ParseMarkdown
+// when pages export as markdown.
+//
+// # Syncing
+//
+//	src, _ := docsync.NewGitSource(docsync.GitOptions{
+//	    Dir:    "/var/lib/tooldocs",
+//	    Remote: "https://github.com/acme/tool-docs.git",
+//	    Path:   "tools",
+//	})
+//	syncer, _ := docsync.New(docsync.Options{Store: store, Sources: []docsync.Source{src}})
+//	_ = sched.Add(scheduler.Job{Name: "sync-docs", Spec: "*/15 * * * *", Run: syncer.Run})
+//
+// Each [Syncer.Sync] returns a [Report] of created, updated, unchanged, and
+// removed docs. Examples registered in code are kept when a document
+// declares none.
+//
+// # Conflicts
+//
+// The Syncer fingerprints each entry it writes. If the store's entry was
+// changed by anything else before the next sync, or documentation already
+// existed and differs from the source, the document is reported as a
+// [Conflict]. ConflictSkip (the default) keeps the store's version;
+// ConflictOverwrite applies the source's. A tool defined by more than one
+// document is a conflict and is left untouched.
+//
+// # Thread Safety
+//
+// Syncer is safe for concurrent use; syncs are serialized.
+package docsync
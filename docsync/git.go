@@ -0,0 +1,163 @@
+package docsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidSource is returned when a source is configured without the
+// fields it requires.
+var ErrInvalidSource = errors.New("docsync: invalid source")
+
+// GitOptions configures a GitSource.
+type GitOptions struct {
+	// Dir is the local working tree. Required.
+	Dir string
+
+	// Remote is the repository URL. When set, Fetch clones it into Dir if
+	// Dir is not yet a repository and pulls (fast-forward only) otherwise.
+	// When empty, Dir is read as-is and kept up to date by someone else.
+	Remote string
+
+	// Branch is the branch to clone and pull. Default: the remote's default
+	// branch.
+	Branch string
+
+	// Path is the directory within the repository holding the docs.
+	// Default: the repository root.
+	Path string
+
+	// GitBinary is the git executable.
+	// Default: "git"
+	GitBinary string
+}
+
+// GitSource reads markdown docs from a Git repository. Every ".md" file
+// under GitOptions.Path with front matter is parsed with ParseMarkdown;
+// files without front matter, such as READMEs, are skipped. Documents carry
+// the repository-relative path and the HEAD commit as their revision.
+type GitSource struct {
+	opts GitOptions
+}
+
+// NewGitSource creates a GitSource. It returns ErrInvalidSource if Dir is
+// empty.
+func NewGitSource(opts GitOptions) (*GitSource, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("%w: git source requires Dir", ErrInvalidSource)
+	}
+	if opts.GitBinary == "" {
+		opts.GitBinary = "git"
+	}
+	return &GitSource{opts: opts}, nil
+}
+
+// Name returns "git:" followed by the remote, or the directory when no
+// remote is configured.
+func (g *GitSource) Name() string {
+	if g.opts.Remote != "" {
+		return "git:" + g.opts.Remote
+	}
+	return "git:" + g.opts.Dir
+}
+
+// Fetch updates the working tree when a remote is configured and returns
+// the documents found under Path. A file with malformed front matter fails
+// the fetch so broken docs are noticed rather than silently dropped.
+func (g *GitSource) Fetch(ctx context.Context) ([]Document, error) {
+	if err := g.update(ctx); err != nil {
+		return nil, err
+	}
+	revision, err := g.git(ctx, g.opts.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(g.opts.Dir, g.opts.Path)
+	var docs []Document
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(g.opts.Dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		doc, err := ParseMarkdown(data)
+		if errors.Is(err, ErrNoFrontMatter) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		doc.Path = rel
+		doc.Revision = revision
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(docs, func(a, b Document) int { return strings.Compare(a.Path, b.Path) })
+	return docs, nil
+}
+
+// update clones or pulls the remote, if one is configured.
+func (g *GitSource) update(ctx context.Context) error {
+	if g.opts.Remote == "" {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(g.opts.Dir, ".git")); errors.Is(err, fs.ErrNotExist) {
+		args := []string{"clone", "--quiet"}
+		if g.opts.Branch != "" {
+			args = append(args, "--branch", g.opts.Branch)
+		}
+		_, err := g.git(ctx, "", append(args, "--", g.opts.Remote, g.opts.Dir)...)
+		return err
+	}
+	args := []string{"pull", "--quiet", "--ff-only"}
+	if g.opts.Branch != "" {
+		args = append(args, "origin", g.opts.Branch)
+	}
+	_, err := g.git(ctx, g.opts.Dir, args...)
+	return err
+}
+
+// git runs a git subcommand, in dir when set, and returns its trimmed
+// standard output.
+func (g *GitSource) git(ctx context.Context, dir string, args ...string) (string, error) {
+	command := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, g.opts.GitBinary, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docsync: git %s: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,39 @@
+package docsync
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// Error values for markdown parsing.
+var (
+	// ErrNoFrontMatter is returned by ParseMarkdown when a file does not
+	// start with a front-matter block. Sources skip such files.
+	ErrNoFrontMatter = errors.New("docsync: no front matter")
+
+	// ErrInvalidDocument is returned for malformed front matter or a
+	// missing tool ID.
+	ErrInvalidDocument = errors.New("docsync: invalid document")
+)
+
+// ParseMarkdown parses a markdown document with front matter into a
+// Document with tooldoc.ParseMarkdownDoc, so documents synced from a
+// repository read exactly like those loaded by tooldoc.LoadFromFS. The
+// front matter is YAML delimited by "---" lines; its tool key, the ID of
+// the documented tool, is required. See tooldoc.LoadFromFS for the other
+// keys. The body after the front matter becomes DocEntry.Notes.
+func ParseMarkdown(data []byte) (Document, error) {
+	id, entry, err := tooldoc.ParseMarkdownDoc(data)
+	if errors.Is(err, tooldoc.ErrNoFrontMatter) {
+		return Document{}, ErrNoFrontMatter
+	}
+	if err != nil {
+		return Document{}, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if id == "" {
+		return Document{}, fmt.Errorf("%w: missing tool", ErrInvalidDocument)
+	}
+	return Document{ToolID: id, Entry: entry}, nil
+}
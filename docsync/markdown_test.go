@@ -0,0 +1,66 @@
+package docsync
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	doc, err := ParseMarkdown([]byte(`---
+tool: github:create_issue
+summary: "Creates a GitHub issue"
+# writers' comment
+see_also: [github:list_issues, 'github:close_issue']
+external_refs:
+  - https://docs.github.com/rest/issues
+  - https://example.com/guide
+owner: devex
+owner_contact: "#devex"
+title: ignored
+---
+
+Requires the repo scope.
+`))
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	if doc.ToolID != "github:create_issue" {
+		t.Errorf("ToolID = %q", doc.ToolID)
+	}
+	if doc.Entry.Summary != "Creates a GitHub issue" {
+		t.Errorf("Summary = %q", doc.Entry.Summary)
+	}
+	if doc.Entry.Notes != "Requires the repo scope." {
+		t.Errorf("Notes = %q", doc.Entry.Notes)
+	}
+	if !slices.Equal(doc.Entry.SeeAlso, []string{"github:list_issues", "github:close_issue"}) {
+		t.Errorf("SeeAlso = %v", doc.Entry.SeeAlso)
+	}
+	if !slices.Equal(doc.Entry.ExternalRefs, []string{"https://docs.github.com/rest/issues", "https://example.com/guide"}) {
+		t.Errorf("ExternalRefs = %v", doc.Entry.ExternalRefs)
+	}
+	if doc.Entry.Owner == nil || doc.Entry.Owner.Team != "devex" || doc.Entry.Owner.Contact != "#devex" {
+		t.Errorf("Owner = %+v", doc.Entry.Owner)
+	}
+}
+
+func TestParseMarkdown_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"no front matter", "# Just a README\n", ErrNoFrontMatter},
+		{"missing tool", "---\nsummary: x\n---\nbody\n", ErrInvalidDocument},
+		{"unterminated", "---\ntool: ns:a\nbody\n", ErrInvalidDocument},
+		{"malformed line", "---\ntool: ns:a\nnot a pair\n---\n", ErrInvalidDocument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMarkdown([]byte(tt.input)); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
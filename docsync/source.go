@@ -0,0 +1,44 @@
+package docsync
+
+import (
+	"context"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// Document is documentation for one tool as authored in an external system.
+type Document struct {
+	// ToolID is the canonical ID of the documented tool.
+	ToolID string
+
+	// Path locates the document within its source, such as a file path or
+	// page URL. It is used in reports.
+	Path string
+
+	// Revision identifies the version of the source the document was read
+	// from, such as a commit hash or page version. Optional.
+	Revision string
+
+	// Entry is the documentation to store. Examples are optional; when a
+	// document has none, examples already in the store are kept.
+	Entry tooldoc.DocEntry
+}
+
+// Source fetches documents from a system where writers author docs, such as
+// a Git repository, Notion workspace, or Confluence space.
+//
+// Contract:
+//   - Concurrency: Fetch is never called concurrently by a Syncer, but
+//     implementations shared between Syncers must be safe for concurrent use.
+//   - Completeness: Fetch returns every document the source currently holds;
+//     documents missing from a successful Fetch are treated as deleted.
+//   - Errors: a Fetch error skips the source for that sync; documents
+//     already synced from it are left in place.
+//   - Context: Fetch should honor cancellation.
+type Source interface {
+	// Name identifies the source in reports and conflicts.
+	Name() string
+
+	// Fetch returns the current documents.
+	Fetch(ctx context.Context) ([]Document, error)
+}
@@ -0,0 +1,357 @@
+package docsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// ErrNoStore is returned by New when Options.Store is nil.
+var ErrNoStore = errors.New("docsync: store is required")
+
+// Store is the documentation store a Syncer writes to.
+// *tooldoc.InMemoryStore implements it.
+type Store interface {
+	GetDoc(id string) (tooldoc.DocEntry, error)
+	RegisterDoc(id string, entry tooldoc.DocEntry) error
+	RemoveDoc(id string) error
+}
+
+// ConflictPolicy decides what happens when a synced document was edited in
+// the store since the last sync.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the store untouched and reports the conflict.
+	ConflictSkip ConflictPolicy = "skip"
+
+	// ConflictOverwrite replaces the store's documentation with the
+	// source's and still reports the conflict.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// Conflict reasons reported in Conflict.Reason.
+const (
+	ReasonModified  = "modified outside sync"
+	ReasonDuplicate = "defined by more than one document"
+)
+
+// Options configures a Syncer.
+type Options struct {
+	// Store receives synced documentation. Required.
+	Store Store
+
+	// Sources are fetched in order on every sync.
+	Sources []Source
+
+	// ConflictPolicy applies to documents edited in the store outside of
+	// sync, including documentation that existed before the first sync and
+	// differs from the source.
+	// Default: ConflictSkip
+	ConflictPolicy ConflictPolicy
+
+	// Prune removes documentation whose document disappeared from its
+	// source, unless it was edited in the store since it was synced. Nothing
+	// is pruned while any source fails to fetch.
+	Prune bool
+
+	// Now returns the current time. Default: time.Now
+	Now func() time.Time
+}
+
+// Conflict describes a document that could not be applied cleanly.
+type Conflict struct {
+	ToolID string `json:"toolId"`
+	Source string `json:"source"`
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason"`
+	// Applied reports whether the source's version was written anyway
+	// (ConflictOverwrite).
+	Applied bool `json:"applied"`
+}
+
+// Report summarizes one sync. Tool ID lists are sorted.
+type Report struct {
+	StartedAt time.Time  `json:"startedAt"`
+	Created   []string   `json:"created,omitempty"`
+	Updated   []string   `json:"updated,omitempty"`
+	Unchanged []string   `json:"unchanged,omitempty"`
+	Removed   []string   `json:"removed,omitempty"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Errors holds source fetch and store errors.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// syncState records what the Syncer last wrote for a tool.
+type syncState struct {
+	source     string
+	sourceHash string // hash of the document as fetched
+	storeHash  string // hash of the entry as read back from the store
+	examples   bool   // whether the source owns the examples
+}
+
+// Syncer pulls documents from sources into a store.
+//
+// It remembers a hash of each entry it writes. When the store's entry no
+// longer matches on the next sync, someone edited it outside of sync and
+// the document is reported as a conflict and handled per ConflictPolicy.
+// State is kept in memory; after a restart, store entries that differ from
+// their source are reported as conflicts once.
+type Syncer struct {
+	opts Options
+
+	mu     sync.Mutex // serializes syncs
+	state  map[string]syncState
+	last   Report
+	hasRun bool
+}
+
+// New creates a Syncer. It returns ErrNoStore if opts.Store is nil.
+func New(opts Options) (*Syncer, error) {
+	if opts.Store == nil {
+		return nil, ErrNoStore
+	}
+	if opts.ConflictPolicy == "" {
+		opts.ConflictPolicy = ConflictSkip
+	}
+	if opts.ConflictPolicy != ConflictSkip && opts.ConflictPolicy != ConflictOverwrite {
+		return nil, fmt.Errorf("docsync: unknown conflict policy %q", opts.ConflictPolicy)
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Syncer{opts: opts, state: make(map[string]syncState)}, nil
+}
+
+// incoming is a fetched document with the source it came from.
+type incoming struct {
+	source string
+	doc    Document
+}
+
+// Sync fetches every source and applies the documents to the store. It
+// returns the report and, if any source or store operation failed, an error
+// joining the failures. Documents from sources that fetched successfully
+// are applied even when another source fails.
+func (s *Syncer) Sync(ctx context.Context) (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := Report{StartedAt: s.opts.Now()}
+	var errs []error
+	fail := func(err error) {
+		errs = append(errs, err)
+		report.Errors = append(report.Errors, err.Error())
+	}
+
+	docs := make(map[string]incoming)
+	duplicates := make(map[string]bool)
+	fetchFailed := false
+	for _, src := range s.opts.Sources {
+		fetched, err := src.Fetch(ctx)
+		if err != nil {
+			fetchFailed = true
+			fail(fmt.Errorf("source %s: %w", src.Name(), err))
+			continue
+		}
+		for _, doc := range fetched {
+			if _, ok := docs[doc.ToolID]; ok {
+				duplicates[doc.ToolID] = true
+				report.Conflicts = append(report.Conflicts, Conflict{
+					ToolID: doc.ToolID, Source: src.Name(), Path: doc.Path, Reason: ReasonDuplicate,
+				})
+				continue
+			}
+			docs[doc.ToolID] = incoming{source: src.Name(), doc: doc}
+		}
+	}
+
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		if !duplicates[id] {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			break
+		}
+		if err := s.apply(id, docs[id], &report); err != nil {
+			fail(err)
+		}
+	}
+
+	if s.opts.Prune && !fetchFailed && ctx.Err() == nil {
+		s.prune(docs, &report, fail)
+	} else if !fetchFailed {
+		// Stop tracking docs we no longer see so a later reappearance is
+		// compared against the store afresh.
+		for id := range s.state {
+			if _, ok := docs[id]; !ok {
+				delete(s.state, id)
+			}
+		}
+	}
+
+	s.last = report
+	s.hasRun = true
+	return report, errors.Join(errs...)
+}
+
+// apply writes one document to the store.
+func (s *Syncer) apply(id string, in incoming, report *Report) error {
+	entry := in.doc.Entry
+	ownsExamples := len(entry.Examples) > 0
+	sourceHash := hashEntry(entry, ownsExamples)
+
+	current, err := s.opts.Store.GetDoc(id)
+	exists := err == nil
+	if err != nil && !errors.Is(err, tooldoc.ErrNotFound) {
+		return fmt.Errorf("get %s: %w", id, err)
+	}
+
+	state, tracked := s.state[id]
+	if exists {
+		var modified bool
+		if tracked {
+			modified = hashEntry(current, state.examples) != state.storeHash
+			if !modified && sourceHash == state.sourceHash && ownsExamples == state.examples {
+				report.Unchanged = append(report.Unchanged, id)
+				return nil
+			}
+		} else {
+			if storeHash := hashEntry(current, ownsExamples); storeHash == sourceHash {
+				s.state[id] = syncState{source: in.source, sourceHash: sourceHash, storeHash: storeHash, examples: ownsExamples}
+				report.Unchanged = append(report.Unchanged, id)
+				return nil
+			}
+			modified = true
+		}
+		if modified {
+			conflict := Conflict{
+				ToolID:  id,
+				Source:  in.source,
+				Path:    in.doc.Path,
+				Reason:  ReasonModified,
+				Applied: s.opts.ConflictPolicy == ConflictOverwrite,
+			}
+			report.Conflicts = append(report.Conflicts, conflict)
+			if !conflict.Applied {
+				return nil
+			}
+		}
+		if !ownsExamples {
+			entry.Examples = current.Examples
+		}
+	}
+
+	if err := s.opts.Store.RegisterDoc(id, entry); err != nil {
+		return fmt.Errorf("register %s from %s: %w", id, in.source, err)
+	}
+	stored, err := s.opts.Store.GetDoc(id)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", id, err)
+	}
+	s.state[id] = syncState{
+		source:     in.source,
+		sourceHash: sourceHash,
+		storeHash:  hashEntry(stored, ownsExamples),
+		examples:   ownsExamples,
+	}
+	if exists {
+		report.Updated = append(report.Updated, id)
+	} else {
+		report.Created = append(report.Created, id)
+	}
+	return nil
+}
+
+// prune removes synced documentation whose document is gone.
+func (s *Syncer) prune(docs map[string]incoming, report *Report, fail func(error)) {
+	var gone []string
+	for id := range s.state {
+		if _, ok := docs[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	slices.Sort(gone)
+	for _, id := range gone {
+		state := s.state[id]
+		current, err := s.opts.Store.GetDoc(id)
+		switch {
+		case errors.Is(err, tooldoc.ErrNotFound):
+			delete(s.state, id)
+		case err != nil:
+			fail(fmt.Errorf("get %s: %w", id, err))
+		case hashEntry(current, state.examples) != state.storeHash:
+			delete(s.state, id)
+			report.Conflicts = append(report.Conflicts, Conflict{ToolID: id, Source: state.source, Reason: ReasonModified})
+		default:
+			if err := s.opts.Store.RemoveDoc(id); err != nil && !errors.Is(err, tooldoc.ErrNotFound) {
+				fail(fmt.Errorf("remove %s: %w", id, err))
+				continue
+			}
+			delete(s.state, id)
+			report.Removed = append(report.Removed, id)
+		}
+	}
+}
+
+// Run performs a sync and returns its error. Its signature matches
+// scheduler.JobFunc so syncs can run on a schedule.
+func (s *Syncer) Run(ctx context.Context) error {
+	_, err := s.Sync(ctx)
+	return err
+}
+
+// LastReport returns the report of the most recent sync. It reports false
+// before the first sync.
+func (s *Syncer) LastReport() (Report, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last, s.hasRun
+}
+
+// hashEntry fingerprints the synced fields of entry. Examples are included
+// only when the source owns them.
+func hashEntry(entry tooldoc.DocEntry, examples bool) string {
+	fields := struct {
+		Summary      string
+		Notes        string
+		ExternalRefs []string
+		SeeAlso      []string
+		Owner        *tooldoc.Owner
+		Examples     []tooldoc.ToolExample
+	}{
+		Summary:      entry.Summary,
+		Notes:        entry.Notes,
+		ExternalRefs: entry.ExternalRefs,
+		SeeAlso:      entry.SeeAlso,
+		Owner:        entry.Owner,
+	}
+	if len(fields.ExternalRefs) == 0 {
+		fields.ExternalRefs = nil
+	}
+	if len(fields.SeeAlso) == 0 {
+		fields.SeeAlso = nil
+	}
+	if fields.Owner != nil && fields.Owner.IsZero() {
+		fields.Owner = nil
+	}
+	if examples {
+		fields.Examples = entry.Examples
+	}
+	data, _ := json.Marshal(fields)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,259 @@
+package docsync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+type staticSource struct {
+	name string
+	docs []Document
+	err  error
+}
+
+func (s *staticSource) Name() string { return s.name }
+
+func (s *staticSource) Fetch(context.Context) ([]Document, error) {
+	return s.docs, s.err
+}
+
+func newTestSyncer(t *testing.T, opts Options) *Syncer {
+	t.Helper()
+	s, err := New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return s
+}
+
+func mustSync(t *testing.T, s *Syncer) Report {
+	t.Helper()
+	report, err := s.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	return report
+}
+
+func TestNew_RequiresStore(t *testing.T) {
+	if _, err := New(Options{}); !errors.Is(err, ErrNoStore) {
+		t.Fatalf("expected ErrNoStore, got %v", err)
+	}
+	if _, err := New(Options{Store: tooldoc.NewInMemoryStore(tooldoc.StoreOptions{}), ConflictPolicy: "merge"}); err == nil {
+		t.Fatal("expected error for unknown conflict policy")
+	}
+}
+
+func TestSync_CreateUpdateUnchanged(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	src := &staticSource{name: "static", docs: []Document{
+		{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "A", Notes: "notes"}},
+	}}
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{src}})
+
+	report := mustSync(t, syncer)
+	if !slices.Equal(report.Created, []string{"ns:a"}) {
+		t.Fatalf("Created = %v", report.Created)
+	}
+
+	report = mustSync(t, syncer)
+	if !slices.Equal(report.Unchanged, []string{"ns:a"}) || len(report.Updated) != 0 {
+		t.Fatalf("expected unchanged, got %+v", report)
+	}
+
+	src.docs[0].Entry.Summary = "A2"
+	report = mustSync(t, syncer)
+	if !slices.Equal(report.Updated, []string{"ns:a"}) {
+		t.Fatalf("Updated = %v", report.Updated)
+	}
+	entry, _ := store.GetDoc("ns:a")
+	if entry.Summary != "A2" {
+		t.Errorf("Summary = %q, want A2", entry.Summary)
+	}
+
+	last, ok := syncer.LastReport()
+	if !ok || !slices.Equal(last.Updated, []string{"ns:a"}) {
+		t.Errorf("LastReport = %+v, %v", last, ok)
+	}
+}
+
+func TestSync_PreservesExamples(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	src := &staticSource{name: "static", docs: []Document{{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "A"}}}}
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{src}})
+	mustSync(t, syncer)
+
+	if err := store.RegisterExamples("ns:a", []tooldoc.ToolExample{{ID: "ex", Title: "Example"}}); err != nil {
+		t.Fatalf("RegisterExamples failed: %v", err)
+	}
+	src.docs[0].Entry.Summary = "A2"
+	report := mustSync(t, syncer)
+	if len(report.Conflicts) != 0 || !slices.Equal(report.Updated, []string{"ns:a"}) {
+		t.Fatalf("expected clean update, got %+v", report)
+	}
+	if examples, _ := store.ListExamples("ns:a", 10); len(examples) != 1 {
+		t.Errorf("expected examples to be kept, got %v", examples)
+	}
+}
+
+func TestSync_Conflicts(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	src := &staticSource{name: "static", docs: []Document{{ToolID: "ns:a", Path: "a.md", Entry: tooldoc.DocEntry{Summary: "A"}}}}
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{src}})
+	mustSync(t, syncer)
+
+	local := "edited locally"
+	if err := store.PatchDoc("ns:a", tooldoc.DocPatch{Summary: &local}); err != nil {
+		t.Fatalf("PatchDoc failed: %v", err)
+	}
+	src.docs[0].Entry.Summary = "A2"
+
+	report := mustSync(t, syncer)
+	if len(report.Conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %+v", report)
+	}
+	c := report.Conflicts[0]
+	if c.ToolID != "ns:a" || c.Reason != ReasonModified || c.Path != "a.md" || c.Applied {
+		t.Errorf("unexpected conflict: %+v", c)
+	}
+	if entry, _ := store.GetDoc("ns:a"); entry.Summary != local {
+		t.Errorf("ConflictSkip overwrote local edit: %q", entry.Summary)
+	}
+
+	overwrite := newTestSyncer(t, Options{Store: store, Sources: []Source{src}, ConflictPolicy: ConflictOverwrite})
+	report = mustSync(t, overwrite)
+	if len(report.Conflicts) != 1 || !report.Conflicts[0].Applied {
+		t.Fatalf("expected applied conflict, got %+v", report)
+	}
+	if entry, _ := store.GetDoc("ns:a"); entry.Summary != "A2" {
+		t.Errorf("ConflictOverwrite kept %q", entry.Summary)
+	}
+}
+
+func TestSync_DuplicateDocuments(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{
+		&staticSource{name: "one", docs: []Document{{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "one"}}}},
+		&staticSource{name: "two", docs: []Document{{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "two"}}}},
+	}})
+
+	report := mustSync(t, syncer)
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Reason != ReasonDuplicate {
+		t.Fatalf("expected duplicate conflict, got %+v", report)
+	}
+	if _, err := store.GetDoc("ns:a"); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Errorf("expected duplicate doc to be skipped, got %v", err)
+	}
+}
+
+func TestSync_Prune(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	src := &staticSource{name: "static", docs: []Document{
+		{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "A"}},
+		{ToolID: "ns:b", Entry: tooldoc.DocEntry{Summary: "B"}},
+	}}
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{src}, Prune: true})
+	mustSync(t, syncer)
+
+	src.err = errors.New("unavailable")
+	src.docs = nil
+	if _, err := syncer.Sync(context.Background()); err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if _, err := store.GetDoc("ns:a"); err != nil {
+		t.Fatal("expected nothing pruned while a source fails")
+	}
+
+	src.err = nil
+	src.docs = []Document{{ToolID: "ns:b", Entry: tooldoc.DocEntry{Summary: "B"}}}
+	report := mustSync(t, syncer)
+	if !slices.Equal(report.Removed, []string{"ns:a"}) {
+		t.Fatalf("Removed = %v", report.Removed)
+	}
+	if _, err := store.GetDoc("ns:a"); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Errorf("expected ns:a to be pruned, got %v", err)
+	}
+}
+
+func TestSync_SourceErrorKeepsOtherSources(t *testing.T) {
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{
+		&staticSource{name: "broken", err: errors.New("boom")},
+		&staticSource{name: "ok", docs: []Document{{ToolID: "ns:a", Entry: tooldoc.DocEntry{Summary: "A"}}}},
+	}})
+
+	report, err := syncer.Sync(context.Background())
+	if err == nil || len(report.Errors) != 1 {
+		t.Fatalf("expected one source error, got %v / %+v", err, report.Errors)
+	}
+	if !slices.Equal(report.Created, []string{"ns:a"}) {
+		t.Errorf("Created = %v", report.Created)
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	if _, err := NewGitSource(GitOptions{}); !errors.Is(err, ErrInvalidSource) {
+		t.Fatalf("expected ErrInvalidSource, got %v", err)
+	}
+
+	upstream := t.TempDir()
+	runGit(t, upstream, "init", "--quiet", "--initial-branch=main")
+	writeFile(t, filepath.Join(upstream, "README.md"), "# Tool docs\n")
+	writeFile(t, filepath.Join(upstream, "tools", "search.md"), "---\ntool: ns:search\nsummary: Search things\n---\nUse quotes for phrases.\n")
+	runGit(t, upstream, "add", "-A")
+	runGit(t, upstream, "commit", "--quiet", "-m", "docs")
+
+	src, err := NewGitSource(GitOptions{Dir: filepath.Join(t.TempDir(), "checkout"), Remote: upstream, Path: "tools"})
+	if err != nil {
+		t.Fatalf("NewGitSource failed: %v", err)
+	}
+	store := tooldoc.NewInMemoryStore(tooldoc.StoreOptions{})
+	syncer := newTestSyncer(t, Options{Store: store, Sources: []Source{src}})
+
+	if err := syncer.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	entry, err := store.GetDoc("ns:search")
+	if err != nil || entry.Summary != "Search things" || entry.Notes != "Use quotes for phrases." {
+		t.Fatalf("unexpected entry %+v, %v", entry, err)
+	}
+
+	writeFile(t, filepath.Join(upstream, "tools", "search.md"), "---\ntool: ns:search\nsummary: Search everything\n---\n")
+	runGit(t, upstream, "commit", "--quiet", "-am", "update")
+
+	docs, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(docs) != 1 || docs[0].Path != "tools/search.md" || docs[0].Entry.Summary != "Search everything" || docs[0].Revision == "" {
+		t.Fatalf("unexpected docs after pull: %+v", docs)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
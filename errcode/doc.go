@@ -0,0 +1,18 @@
+// Package errcode defines stable, machine-readable error codes shared by
+// index, tooldoc, discovery, and registry.
+//
+// Sentinel errors in those packages are declared with [New], so every error
+// wrapping one reports its code through [Of], however deeply wrapped. HTTP
+// and RPC layers branch on codes instead of matching messages, and map them
+// to status codes with [Code.HTTPStatus]:
+//
+//	if errcode.Is(err, errcode.ToolNotFound) {
+//	    // ...
+//	}
+//	w.WriteHeader(errcode.Of(err).HTTPStatus())
+//	_ = json.NewEncoder(w).Encode(errcode.PayloadOf(err))
+//
+// Clients decode a [Payload] and call [Payload.Err] to get an error that
+// reports the same code. errors.Is against a package sentinel keeps working
+// in-process; across a wire, compare codes.
+package errcode
@@ -0,0 +1,177 @@
+package errcode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Code is a stable, machine-readable error code. Codes are part of the
+// public API: once released, a code keeps its meaning.
+type Code string
+
+// Error codes shared by index, tooldoc, discovery, and registry.
+const (
+	// Unknown is reported for errors without a code.
+	Unknown Code = "unknown"
+
+	// Canceled and DeadlineExceeded are reported for context errors.
+	Canceled         Code = "canceled"
+	DeadlineExceeded Code = "deadline_exceeded"
+
+	// InvalidArgument is reported for malformed input without a more
+	// specific code.
+	InvalidArgument Code = "invalid_argument"
+	InvalidTool     Code = "invalid_tool"
+	InvalidBackend  Code = "invalid_backend"
+	InvalidCursor   Code = "invalid_cursor"
+	InvalidArgs     Code = "invalid_args"
+
+	ToolNotFound    Code = "tool_not_found"
+	BackendNotFound Code = "backend_not_found"
+	HandlerNotFound Code = "handler_not_found"
+	ResultNotFound  Code = "result_not_found"
+	// NotFound is reported for other missing resources, such as examples
+	// and namespace owners.
+	NotFound Code = "not_found"
+
+	LimitExceeded    Code = "limit_exceeded"
+	ChangesTruncated Code = "changes_truncated"
+	Unsupported      Code = "unsupported"
+
+	NotStarted       Code = "not_started"
+	AlreadyStarted   Code = "already_started"
+	UnderMaintenance Code = "under_maintenance"
+	Overloaded       Code = "overloaded"
+	RateLimited      Code = "rate_limited"
+	Unauthenticated  Code = "unauthenticated"
+	PermissionDenied Code = "permission_denied"
+
+	// ExecutionFailed is reported when a tool or its backend fails.
+	ExecutionFailed Code = "execution_failed"
+
+	// Internal is reported for failures of the library itself, such as a
+	// failed self-test.
+	Internal Code = "internal"
+)
+
+// HTTPStatus returns the HTTP status code conventionally used for c.
+// Canceled maps to 499, the de facto status for a client that closed the
+// request.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case Canceled:
+		return 499
+	case DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case InvalidArgument, InvalidTool, InvalidBackend, InvalidCursor, InvalidArgs:
+		return http.StatusBadRequest
+	case ToolNotFound, BackendNotFound, HandlerNotFound, ResultNotFound, NotFound:
+		return http.StatusNotFound
+	case LimitExceeded:
+		return http.StatusUnprocessableEntity
+	case ChangesTruncated:
+		return http.StatusGone
+	case Unsupported:
+		return http.StatusNotImplemented
+	case AlreadyStarted:
+		return http.StatusConflict
+	case NotStarted, UnderMaintenance, Overloaded:
+		return http.StatusServiceUnavailable
+	case RateLimited:
+		return http.StatusTooManyRequests
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case PermissionDenied:
+		return http.StatusForbidden
+	case ExecutionFailed:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Coder is implemented by errors that carry a Code.
+type Coder interface {
+	error
+	Code() string
+}
+
+// Error is an error with a Code. Packages declare their sentinel errors
+// with New, so wrapped errors keep their code.
+type Error struct {
+	code    Code
+	message string
+}
+
+// New returns an error with code and message.
+func New(code Code, message string) error {
+	return &Error{code: code, message: message}
+}
+
+// Error returns the message.
+func (e *Error) Error() string { return e.message }
+
+// Code returns the error code.
+func (e *Error) Code() string { return string(e.code) }
+
+// Of returns the code of the first error in err's chain that has one. Context
+// errors report Canceled and DeadlineExceeded, other errors Unknown, and a
+// nil error the empty code.
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var coder Coder
+	if errors.As(err, &coder) {
+		return Code(coder.Code())
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded
+	default:
+		return Unknown
+	}
+}
+
+// Is reports whether err has code.
+func Is(err error, code Code) bool {
+	return err != nil && Of(err) == code
+}
+
+// Payload is the JSON form of an error, for HTTP and RPC responses.
+type Payload struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// PayloadOf returns the payload for err.
+func PayloadOf(err error) Payload {
+	if err == nil {
+		return Payload{}
+	}
+	return Payload{Code: Of(err), Message: err.Error()}
+}
+
+// Err returns an error with the payload's code and message, so clients can
+// use Of and Is on errors decoded from a response. It returns nil for the
+// zero Payload.
+func (p Payload) Err() error {
+	if p == (Payload{}) {
+		return nil
+	}
+	code := p.Code
+	if code == "" {
+		code = Unknown
+	}
+	return New(code, p.Message)
+}
+
+// Marshal encodes err as a JSON Payload. Decode a response into a Payload
+// and call Err to recover the error.
+func Marshal(err error) ([]byte, error) {
+	return json.Marshal(PayloadOf(err))
+}
@@ -0,0 +1,85 @@
+package errcode_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/discovery"
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/registry"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want errcode.Code
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), errcode.Unknown},
+		{"canceled", fmt.Errorf("search: %w", context.Canceled), errcode.Canceled},
+		{"deadline", context.DeadlineExceeded, errcode.DeadlineExceeded},
+		{"index not found", fmt.Errorf("%w: a:b", index.ErrNotFound), errcode.ToolNotFound},
+		{"index limit", &index.LimitError{Limit: index.LimitTools, Max: 1}, errcode.LimitExceeded},
+		{"tooldoc detail", fmt.Errorf("%w: bogus", tooldoc.ErrInvalidDetail), errcode.InvalidArgument},
+		{"discovery not found", discovery.ErrNotFound, errcode.ToolNotFound},
+		{"registry args", &registry.ArgsError{ToolID: "t"}, errcode.InvalidArgs},
+		{"registry timeout", fmt.Errorf("%w: %w", registry.ErrCallTimeout, context.DeadlineExceeded), errcode.DeadlineExceeded},
+		{"registry forbidden", fmt.Errorf("wrapped: %w", registry.ErrForbidden), errcode.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errcode.Of(tt.err); got != tt.want {
+				t.Errorf("Of() = %q, want %q", got, tt.want)
+			}
+			if tt.err != nil && !errcode.Is(tt.err, tt.want) {
+				t.Errorf("Is(%q) = false", tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := map[errcode.Code]int{
+		errcode.ToolNotFound:     http.StatusNotFound,
+		errcode.InvalidArgs:      http.StatusBadRequest,
+		errcode.RateLimited:      http.StatusTooManyRequests,
+		errcode.Unauthenticated:  http.StatusUnauthorized,
+		errcode.DeadlineExceeded: http.StatusGatewayTimeout,
+		errcode.Unknown:          http.StatusInternalServerError,
+	}
+	for code, want := range tests {
+		if got := code.HTTPStatus(); got != want {
+			t.Errorf("%s.HTTPStatus() = %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	err := fmt.Errorf("%w: github:create_issue", index.ErrNotFound)
+	data, mErr := errcode.Marshal(err)
+	if mErr != nil {
+		t.Fatalf("Marshal failed: %v", mErr)
+	}
+	if string(data) != `{"code":"tool_not_found","message":"tool not found: github:create_issue"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	var p errcode.Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	decoded := p.Err()
+	if !errcode.Is(decoded, errcode.ToolNotFound) || decoded.Error() != err.Error() {
+		t.Errorf("decoded = %v (%s), want tool_not_found", decoded, errcode.Of(decoded))
+	}
+	if (errcode.Payload{}).Err() != nil {
+		t.Error("zero Payload.Err() should be nil")
+	}
+}
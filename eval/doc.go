@@ -0,0 +1,47 @@
+// Package eval measures search quality against labeled queries, so ranking
+// changes such as field boosts, analyzers, or the hybrid alpha can be tuned
+// against reproducible numbers instead of spot checks.
+//
+// # Usage
+//
+// Label each query with the tool IDs a good search returns, then run the
+// dataset against a searcher:
+//
+//	ds := eval.Dataset{Cases: []eval.Case{
+//	    {Query: "create issue", Relevant: []string{"github:create_issue"}},
+//	    {Query: "list pods", Relevant: []string{"kubectl:get_pods", "kubectl:describe_pod"}},
+//	}}
+//	result, err := eval.Run(ctx, disc, ds)
+//	fmt.Printf("MRR %.3f nDCG@%d %.3f\n", result.Metrics.MRR, result.K, result.Metrics.NDCG)
+//
+// Datasets can be kept as JSON files and read with [LoadDataset].
+//
+// # Metrics
+//
+// Each case requests the top K results (Dataset.K, default [DefaultK]) and
+// scores them with binary relevance:
+//   - MRR is the reciprocal rank of the first relevant result, or 0 when
+//     none is in the top K
+//   - nDCG@K discounts each relevant result by log2(rank+1) and normalizes
+//     by the best achievable ordering
+//   - Recall@K is the fraction of relevant tools found in the top K
+//
+// [Result].Metrics averages them over the cases; [Result].Cases keeps the
+// per-query values and the relevant tools each query missed.
+//
+// # Comparing Configurations
+//
+// [Compare] runs one dataset against several named searchers, typically
+// the same catalog built with BM25 only, embeddings only, and hybrid
+// search, and [Report].String renders the metrics side by side:
+//
+//	report, err := eval.Compare(ctx, ds,
+//	    eval.Config{Name: "bm25", Searcher: bm25Disc},
+//	    eval.Config{Name: "embedding", Searcher: embeddingDisc},
+//	    eval.Config{Name: "hybrid", Searcher: hybridDisc},
+//	)
+//	fmt.Print(report)
+//
+// Results are deterministic for deterministic searchers and embedders, so
+// reports can be committed and diffed as tuning proceeds.
+package eval
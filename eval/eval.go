@@ -0,0 +1,199 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/jonwraymond/tooldiscovery/discovery"
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// DefaultK is the result cutoff used when Dataset.K is zero.
+const DefaultK = 10
+
+// ErrInvalidDataset is returned for a dataset without cases, a case without
+// a query or relevant tools, or a negative K.
+var ErrInvalidDataset = errcode.New(errcode.InvalidArgument, "eval: invalid dataset")
+
+// Searcher is the search surface evaluated by Run.
+// *discovery.Discovery satisfies it.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) (discovery.Results, error)
+}
+
+// Case is a query labeled with the tool IDs a good search returns.
+type Case struct {
+	// Name identifies the case in reports. Defaults to Query.
+	Name string `json:"name,omitempty"`
+
+	// Query is the search text.
+	Query string `json:"query"`
+
+	// Relevant lists the IDs of the tools relevant to Query.
+	Relevant []string `json:"relevant"`
+}
+
+func (c Case) key() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Query
+}
+
+// Dataset is a set of labeled queries.
+type Dataset struct {
+	// Name identifies the dataset in reports.
+	Name string `json:"name,omitempty"`
+
+	// K is the number of results requested and scored per query.
+	// Default: DefaultK.
+	K int `json:"k,omitempty"`
+
+	Cases []Case `json:"cases"`
+}
+
+// Metrics are search quality measures at a result cutoff.
+type Metrics struct {
+	MRR    float64 `json:"mrr"`
+	NDCG   float64 `json:"ndcg"`
+	Recall float64 `json:"recall"`
+}
+
+// CaseResult is the outcome of one case.
+type CaseResult struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+
+	// Rank is the 1-based rank of the first relevant result, or 0 when none
+	// is in the top K.
+	Rank int `json:"rank"`
+
+	Metrics
+
+	// Returned lists the IDs of the results, in rank order.
+	Returned []string `json:"returned"`
+
+	// Missed lists the relevant tool IDs absent from the top K.
+	Missed []string `json:"missed,omitempty"`
+}
+
+// Result is the outcome of running a dataset against one searcher.
+type Result struct {
+	// Name is the configuration name set by Compare.
+	Name string `json:"name,omitempty"`
+
+	// K is the result cutoff the metrics were computed at.
+	K int `json:"k"`
+
+	// Metrics averages the case metrics.
+	Metrics Metrics `json:"metrics"`
+
+	Cases []CaseResult `json:"cases"`
+}
+
+// Run searches s for each case in ds and scores the top K results.
+// Search errors abort the run.
+func Run(ctx context.Context, s Searcher, ds Dataset) (Result, error) {
+	k, err := ds.validate()
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{K: k, Cases: make([]CaseResult, 0, len(ds.Cases))}
+	for _, c := range ds.Cases {
+		results, err := s.Search(ctx, c.Query, k)
+		if err != nil {
+			return Result{}, fmt.Errorf("eval: search %q: %w", c.key(), err)
+		}
+		returned := make([]string, 0, min(len(results), k))
+		for _, r := range results[:min(len(results), k)] {
+			returned = append(returned, r.Summary.ID)
+		}
+		cr := score(c, returned, k)
+		result.Metrics.MRR += cr.MRR
+		result.Metrics.NDCG += cr.NDCG
+		result.Metrics.Recall += cr.Recall
+		result.Cases = append(result.Cases, cr)
+	}
+	n := float64(len(result.Cases))
+	result.Metrics.MRR /= n
+	result.Metrics.NDCG /= n
+	result.Metrics.Recall /= n
+	return result, nil
+}
+
+// validate checks ds and returns its result cutoff.
+func (ds Dataset) validate() (int, error) {
+	if ds.K < 0 {
+		return 0, fmt.Errorf("%w: k must be non-negative", ErrInvalidDataset)
+	}
+	if len(ds.Cases) == 0 {
+		return 0, fmt.Errorf("%w: no cases", ErrInvalidDataset)
+	}
+	for i, c := range ds.Cases {
+		if c.Query == "" {
+			return 0, fmt.Errorf("%w: case %d has no query", ErrInvalidDataset, i)
+		}
+		if len(c.Relevant) == 0 {
+			return 0, fmt.Errorf("%w: case %q has no relevant tools", ErrInvalidDataset, c.key())
+		}
+	}
+	if ds.K == 0 {
+		return DefaultK, nil
+	}
+	return ds.K, nil
+}
+
+// score computes the metrics of returned, the top k result IDs, for c.
+func score(c Case, returned []string, k int) CaseResult {
+	relevant := make(map[string]bool, len(c.Relevant))
+	for _, id := range c.Relevant {
+		relevant[id] = true
+	}
+	cr := CaseResult{Name: c.key(), Query: c.Query, Returned: returned}
+
+	found := make(map[string]bool, len(relevant))
+	var dcg float64
+	for i, id := range returned {
+		if !relevant[id] || found[id] {
+			continue
+		}
+		found[id] = true
+		if cr.Rank == 0 {
+			cr.Rank = i + 1
+			cr.MRR = 1 / float64(cr.Rank)
+		}
+		dcg += 1 / math.Log2(float64(i+2))
+	}
+	var idcg float64
+	for i := range min(len(relevant), k) {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		cr.NDCG = dcg / idcg
+	}
+	cr.Recall = float64(len(found)) / float64(len(relevant))
+
+	for _, id := range c.Relevant {
+		if !found[id] {
+			cr.Missed = append(cr.Missed, id)
+			found[id] = true // report duplicates once
+		}
+	}
+	return cr
+}
+
+// LoadDataset reads a JSON dataset file.
+func LoadDataset(path string) (Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Dataset{}, err
+	}
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return Dataset{}, fmt.Errorf("parse dataset %s: %w", path, err)
+	}
+	return ds, nil
+}
@@ -0,0 +1,255 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/discovery"
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type fakeSearcher map[string][]string
+
+func (f fakeSearcher) Search(_ context.Context, query string, limit int) (discovery.Results, error) {
+	var out discovery.Results
+	for _, id := range f[query] {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, discovery.Result{Summary: index.Summary{ID: id}})
+	}
+	return out, nil
+}
+
+type errSearcher struct{}
+
+func (errSearcher) Search(context.Context, string, int) (discovery.Results, error) {
+	return nil, errors.New("boom")
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRun_Metrics(t *testing.T) {
+	s := fakeSearcher{
+		"first":  {"a", "x", "y"},
+		"second": {"x", "a", "b"},
+		"none":   {"x", "y", "z"},
+	}
+	ds := Dataset{K: 3, Cases: []Case{
+		{Query: "first", Relevant: []string{"a"}},
+		{Name: "two relevant", Query: "second", Relevant: []string{"a", "b"}},
+		{Query: "none", Relevant: []string{"a", "b"}},
+	}}
+
+	result, err := Run(context.Background(), s, ds)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.K != 3 || len(result.Cases) != 3 {
+		t.Fatalf("result K=%d cases=%d, want 3 and 3", result.K, len(result.Cases))
+	}
+
+	first := result.Cases[0]
+	if first.Rank != 1 || !near(first.MRR, 1) || !near(first.NDCG, 1) || !near(first.Recall, 1) || first.Missed != nil {
+		t.Errorf("first case = %+v, want perfect scores", first)
+	}
+
+	// Relevant at ranks 2 and 3: DCG = 1/log2(3) + 1/log2(4); IDCG = 1 + 1/log2(3).
+	second := result.Cases[1]
+	wantNDCG := (1/math.Log2(3) + 0.5) / (1 + 1/math.Log2(3))
+	if second.Name != "two relevant" || second.Rank != 2 || !near(second.MRR, 0.5) || !near(second.NDCG, wantNDCG) || !near(second.Recall, 1) {
+		t.Errorf("second case = %+v, want rank 2, MRR 0.5, nDCG %.4f, recall 1", second, wantNDCG)
+	}
+
+	none := result.Cases[2]
+	if none.Name != "none" || none.Rank != 0 || none.MRR != 0 || none.NDCG != 0 || none.Recall != 0 || !slices.Equal(none.Missed, []string{"a", "b"}) {
+		t.Errorf("none case = %+v, want zero scores and both missed", none)
+	}
+
+	if !near(result.Metrics.MRR, 1.5/3) || !near(result.Metrics.NDCG, (1+wantNDCG)/3) || !near(result.Metrics.Recall, 2.0/3) {
+		t.Errorf("mean metrics = %+v", result.Metrics)
+	}
+}
+
+func TestRun_CutoffAndDefaults(t *testing.T) {
+	s := fakeSearcher{"q": {"x", "y", "a", "b"}}
+
+	result, err := Run(context.Background(), s, Dataset{K: 2, Cases: []Case{{Query: "q", Relevant: []string{"a"}}}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if cr := result.Cases[0]; cr.Rank != 0 || !slices.Equal(cr.Returned, []string{"x", "y"}) {
+		t.Errorf("case = %+v, want relevant tool beyond k=2 unranked", cr)
+	}
+
+	result, err = Run(context.Background(), s, Dataset{Cases: []Case{{Query: "q", Relevant: []string{"a", "b", "c"}}}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.K != DefaultK {
+		t.Errorf("K = %d, want %d", result.K, DefaultK)
+	}
+	cr := result.Cases[0]
+	if cr.Rank != 3 || !near(cr.Recall, 2.0/3) || !slices.Equal(cr.Missed, []string{"c"}) {
+		t.Errorf("case = %+v, want rank 3, recall 2/3, missed c", cr)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	ctx := context.Background()
+	for name, ds := range map[string]Dataset{
+		"no cases":    {},
+		"negative k":  {K: -1, Cases: []Case{{Query: "q", Relevant: []string{"a"}}}},
+		"no query":    {Cases: []Case{{Relevant: []string{"a"}}}},
+		"no relevant": {Cases: []Case{{Query: "q"}}},
+	} {
+		_, err := Run(ctx, fakeSearcher{}, ds)
+		if !errors.Is(err, ErrInvalidDataset) || !errcode.Is(err, errcode.InvalidArgument) {
+			t.Errorf("%s: Run() error = %v, want ErrInvalidDataset", name, err)
+		}
+	}
+
+	_, err := Run(ctx, errSearcher{}, Dataset{Cases: []Case{{Query: "q", Relevant: []string{"a"}}}})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Run() error = %v, want search error", err)
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.json")
+	data := `{"name":"smoke","k":5,"cases":[{"query":"create issue","relevant":["github:create_issue"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := LoadDataset(path)
+	if err != nil {
+		t.Fatalf("LoadDataset() error = %v", err)
+	}
+	if ds.Name != "smoke" || ds.K != 5 || len(ds.Cases) != 1 || ds.Cases[0].Relevant[0] != "github:create_issue" {
+		t.Errorf("LoadDataset() = %+v", ds)
+	}
+
+	if _, err := LoadDataset(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadDataset(missing) error = %v, want ErrNotExist", err)
+	}
+}
+
+// topicEmbedder embeds text as counts of topic words, so texts sharing
+// vocabulary are similar.
+type topicEmbedder struct{}
+
+var topics = [][]string{
+	{"issue", "bug", "ticket"},
+	{"pod", "pods", "container", "cluster"},
+	{"commit", "push", "branch"},
+}
+
+func (topicEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vec := make([]float32, len(topics)+1)
+	vec[len(topics)] = 0.01
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		for i, topic := range topics {
+			if slices.Contains(topic, word) {
+				vec[i]++
+			}
+		}
+	}
+	return vec, nil
+}
+
+func newCatalog(t *testing.T, opts discovery.Options) *discovery.Discovery {
+	t.Helper()
+	disc, err := discovery.New(opts)
+	if err != nil {
+		t.Fatalf("discovery.New() error = %v", err)
+	}
+	for _, tool := range []model.Tool{
+		{Tool: mcp.Tool{Name: "create_issue", Description: "Open a new issue to report a bug", InputSchema: map[string]any{"type": "object"}}, Namespace: "github"},
+		{Tool: mcp.Tool{Name: "get_pods", Description: "List pods running in the cluster", InputSchema: map[string]any{"type": "object"}}, Namespace: "kubectl"},
+		{Tool: mcp.Tool{Name: "push", Description: "Push a commit to a remote branch", InputSchema: map[string]any{"type": "object"}}, Namespace: "git"},
+	} {
+		if err := disc.RegisterTool(tool, model.NewMCPBackend("test"), nil); err != nil {
+			t.Fatalf("RegisterTool() error = %v", err)
+		}
+	}
+	return disc
+}
+
+func TestCompare(t *testing.T) {
+	embedding, err := discovery.NewHybridSearcher(discovery.HybridOptions{Embedder: topicEmbedder{}, Alpha: 0})
+	if err != nil {
+		t.Fatalf("NewHybridSearcher() error = %v", err)
+	}
+	configs := []Config{
+		{Name: "bm25", Searcher: newCatalog(t, discovery.Options{})},
+		{Name: "embedding", Searcher: newCatalog(t, discovery.Options{Searcher: embedding})},
+		{Name: "hybrid", Searcher: newCatalog(t, discovery.Options{Embedder: topicEmbedder{}})},
+	}
+	ds := Dataset{Name: "smoke", K: 3, Cases: []Case{
+		{Query: "create issue", Relevant: []string{"github:create_issue"}},
+		{Query: "ticket", Relevant: []string{"github:create_issue"}},
+		{Query: "container", Relevant: []string{"kubectl:get_pods"}},
+	}}
+
+	report, err := Compare(context.Background(), ds, configs...)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if report.Dataset != "smoke" || report.K != 3 || len(report.Results) != 3 {
+		t.Fatalf("report = %+v", report)
+	}
+	byName := make(map[string]Result)
+	for _, r := range report.Results {
+		byName[r.Name] = r
+	}
+	// BM25 cannot match "ticket" or "container"; embeddings can.
+	if got := byName["bm25"].Metrics.Recall; got >= 1 {
+		t.Errorf("bm25 recall = %v, want < 1", got)
+	}
+	if got := byName["embedding"].Metrics.MRR; !near(got, 1) {
+		t.Errorf("embedding MRR = %v, want 1", got)
+	}
+	if got := byName["hybrid"].Metrics.Recall; got <= byName["bm25"].Metrics.Recall {
+		t.Errorf("hybrid recall = %v, want above bm25 %v", got, byName["bm25"].Metrics.Recall)
+	}
+
+	out := report.String()
+	for _, want := range []string{"dataset: smoke", "nDCG@3", "recall@3", "bm25", "embedding", "hybrid", "1.0000*"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+
+	again, err := Compare(context.Background(), ds, configs...)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if again.String() != out {
+		t.Errorf("repeated report differs:\n%s\nvs\n%s", again, out)
+	}
+}
+
+func TestCompare_InvalidConfigs(t *testing.T) {
+	ds := Dataset{Cases: []Case{{Query: "q", Relevant: []string{"a"}}}}
+	for name, configs := range map[string][]Config{
+		"none":      nil,
+		"unnamed":   {{Searcher: fakeSearcher{}}},
+		"nil":       {{Name: "a"}},
+		"duplicate": {{Name: "a", Searcher: fakeSearcher{}}, {Name: "a", Searcher: fakeSearcher{}}},
+	} {
+		if _, err := Compare(context.Background(), ds, configs...); !errors.Is(err, ErrInvalidDataset) {
+			t.Errorf("%s: Compare() error = %v, want ErrInvalidDataset", name, err)
+		}
+	}
+}
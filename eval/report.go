@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Config is a named searcher compared by Compare.
+type Config struct {
+	Name     string
+	Searcher Searcher
+}
+
+// Report compares a dataset's results across configurations.
+type Report struct {
+	Dataset string `json:"dataset,omitempty"`
+	K       int    `json:"k"`
+
+	// Results holds one entry per configuration, in the order given.
+	Results []Result `json:"results"`
+}
+
+// Compare runs ds against each configuration. Configurations must have
+// distinct, non-empty names.
+func Compare(ctx context.Context, ds Dataset, configs ...Config) (Report, error) {
+	if len(configs) == 0 {
+		return Report{}, fmt.Errorf("%w: no configurations", ErrInvalidDataset)
+	}
+	seen := make(map[string]bool, len(configs))
+	for _, cfg := range configs {
+		if cfg.Name == "" || cfg.Searcher == nil {
+			return Report{}, fmt.Errorf("%w: configuration needs a name and searcher", ErrInvalidDataset)
+		}
+		if seen[cfg.Name] {
+			return Report{}, fmt.Errorf("%w: duplicate configuration %q", ErrInvalidDataset, cfg.Name)
+		}
+		seen[cfg.Name] = true
+	}
+
+	report := Report{Dataset: ds.Name, Results: make([]Result, 0, len(configs))}
+	for _, cfg := range configs {
+		result, err := Run(ctx, cfg.Searcher, ds)
+		if err != nil {
+			return Report{}, fmt.Errorf("%s: %w", cfg.Name, err)
+		}
+		result.Name = cfg.Name
+		report.K = result.K
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// String renders the mean metrics as a table with one row per
+// configuration, marking the best value of each metric with "*".
+func (r Report) String() string {
+	var best Metrics
+	for _, result := range r.Results {
+		best.MRR = max(best.MRR, result.Metrics.MRR)
+		best.NDCG = max(best.NDCG, result.Metrics.NDCG)
+		best.Recall = max(best.Recall, result.Metrics.Recall)
+	}
+
+	var b strings.Builder
+	if r.Dataset != "" {
+		fmt.Fprintf(&b, "dataset: %s\n", r.Dataset)
+	}
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "config\tMRR\tnDCG@%d\trecall@%d\n", r.K, r.K)
+	for _, result := range r.Results {
+		m := result.Metrics
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Name,
+			cell(m.MRR, best.MRR), cell(m.NDCG, best.NDCG), cell(m.Recall, best.Recall))
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// cell formats a metric, marking it when it is the nonzero best.
+func cell(v, best float64) string {
+	if v == best && best > 0 {
+		return fmt.Sprintf("%.4f*", v)
+	}
+	return fmt.Sprintf("%.4f", v)
+}
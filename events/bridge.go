@@ -0,0 +1,181 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Defaults for BridgeOptions.
+const (
+	DefaultTopic          = "tooldiscovery.index.changes"
+	DefaultBufferSize     = 256
+	DefaultPublishTimeout = 5 * time.Second
+)
+
+// BridgeOptions configures a Bridge.
+type BridgeOptions struct {
+	// Topic is the destination for every event. Default: DefaultTopic.
+	Topic string
+
+	// TopicFunc overrides Topic per event when set (e.g. per-namespace topics).
+	TopicFunc func(index.ChangeEvent) string
+
+	// Filter drops events for which it returns false. Nil forwards everything.
+	Filter func(index.ChangeEvent) bool
+
+	// BufferSize is the number of events queued for publishing.
+	// Default: DefaultBufferSize.
+	BufferSize int
+
+	// PublishTimeout bounds each Publish call. Default: DefaultPublishTimeout.
+	PublishTimeout time.Duration
+
+	// OnError is called when an event is dropped or fails to publish.
+	OnError func(ev index.ChangeEvent, err error)
+
+	// Now returns the event timestamp. Default: time.Now.
+	Now func() time.Time
+}
+
+// BridgeStats reports delivery counters for a Bridge.
+type BridgeStats struct {
+	Published uint64
+	Failed    uint64
+	Dropped   uint64
+}
+
+// Bridge forwards index change events to a Publisher.
+type Bridge struct {
+	publisher Publisher
+	opts      BridgeOptions
+
+	queue       chan index.ChangeEvent
+	unsubscribe func()
+	done        chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	stats  BridgeStats
+}
+
+// NewBridge subscribes to notifier and starts forwarding events to publisher.
+// Call Close to unsubscribe and flush queued events.
+func NewBridge(notifier index.ChangeNotifier, publisher Publisher, opts BridgeOptions) (*Bridge, error) {
+	if notifier == nil {
+		return nil, ErrInvalidNotifier
+	}
+	if publisher == nil {
+		return nil, ErrInvalidPublisher
+	}
+	if opts.Topic == "" {
+		opts.Topic = DefaultTopic
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultBufferSize
+	}
+	if opts.PublishTimeout <= 0 {
+		opts.PublishTimeout = DefaultPublishTimeout
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	b := &Bridge{
+		publisher: publisher,
+		opts:      opts,
+		queue:     make(chan index.ChangeEvent, opts.BufferSize),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	b.unsubscribe = notifier.OnChange(b.enqueue)
+	return b, nil
+}
+
+// Stats returns a snapshot of delivery counters.
+func (b *Bridge) Stats() BridgeStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Close unsubscribes from the notifier, publishes queued events, and stops
+// the background worker. It is safe to call multiple times.
+func (b *Bridge) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	b.unsubscribe()
+	close(b.queue)
+	<-b.done
+	return nil
+}
+
+func (b *Bridge) enqueue(ev index.ChangeEvent) {
+	if b.opts.Filter != nil && !b.opts.Filter(ev) {
+		return
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	select {
+	case b.queue <- ev:
+		b.mu.Unlock()
+		return
+	default:
+		b.stats.Dropped++
+	}
+	b.mu.Unlock()
+	b.reportError(ev, ErrBufferFull)
+}
+
+func (b *Bridge) run() {
+	defer close(b.done)
+	for ev := range b.queue {
+		err := b.publish(ev)
+
+		b.mu.Lock()
+		if err != nil {
+			b.stats.Failed++
+		} else {
+			b.stats.Published++
+		}
+		b.mu.Unlock()
+
+		if err != nil {
+			b.reportError(ev, err)
+		}
+	}
+}
+
+func (b *Bridge) publish(ev index.ChangeEvent) error {
+	topic := b.opts.Topic
+	if b.opts.TopicFunc != nil {
+		if t := b.opts.TopicFunc(ev); t != "" {
+			topic = t
+		}
+	}
+	msg, err := encodeMessage(topic, NewEvent(ev, b.opts.Now()))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.PublishTimeout)
+	defer cancel()
+	return b.publisher.Publish(ctx, msg)
+}
+
+func (b *Bridge) reportError(ev index.ChangeEvent, err error) {
+	if b.opts.OnError != nil {
+		b.opts.OnError(ev, err)
+	}
+}
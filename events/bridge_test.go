@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+type recordingPublisher struct {
+	mu   sync.Mutex
+	msgs []Message
+	err  error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func (p *recordingPublisher) messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Message, len(p.msgs))
+	copy(out, p.msgs)
+	return out
+}
+
+type fakeNATSConn struct {
+	subjects []string
+}
+
+func (c *fakeNATSConn) Publish(subject string, _ []byte) error {
+	c.subjects = append(c.subjects, subject)
+	return nil
+}
+
+func testTool(ns, name string) model.Tool {
+	return model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: "test tool",
+			InputSchema: map[string]any{"type": "object"},
+		},
+		Namespace: ns,
+	}
+}
+
+func TestNewBridge_Validation(t *testing.T) {
+	if _, err := NewBridge(nil, &recordingPublisher{}, BridgeOptions{}); !errors.Is(err, ErrInvalidNotifier) {
+		t.Fatalf("expected ErrInvalidNotifier, got %v", err)
+	}
+	if _, err := NewBridge(index.NewInMemoryIndex(), nil, BridgeOptions{}); !errors.Is(err, ErrInvalidPublisher) {
+		t.Fatalf("expected ErrInvalidPublisher, got %v", err)
+	}
+}
+
+func TestBridge_PublishesChangeEvents(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	pub := &recordingPublisher{}
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	bridge, err := NewBridge(idx, pub, BridgeOptions{Now: func() time.Time { return fixed }})
+	if err != nil {
+		t.Fatalf("NewBridge failed: %v", err)
+	}
+
+	if err := idx.RegisterTool(testTool("github", "create_issue"), model.NewLocalBackend("h")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := bridge.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	msgs := pub.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	msg := msgs[0]
+	if msg.Topic != DefaultTopic || msg.Key != "github:create_issue" {
+		t.Errorf("unexpected message routing: %+v", msg)
+	}
+
+	var ev Event
+	if err := json.Unmarshal(msg.Payload, &ev); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if ev.Type != index.ChangeRegistered || ev.ToolID != "github:create_issue" || !ev.Timestamp.Equal(fixed) {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.Backend == nil || ev.Backend.Kind != model.BackendKindLocal {
+		t.Errorf("expected local backend in payload, got %+v", ev.Backend)
+	}
+
+	if stats := bridge.Stats(); stats.Published != 1 {
+		t.Errorf("Published = %d, want 1", stats.Published)
+	}
+}
+
+func TestBridge_FilterAndTopicFunc(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	pub := &recordingPublisher{}
+	bridge, _ := NewBridge(idx, pub, BridgeOptions{
+		Filter: func(ev index.ChangeEvent) bool { return ev.Type == index.ChangeRegistered },
+		TopicFunc: func(ev index.ChangeEvent) string {
+			return "catalog." + string(ev.Type)
+		},
+	})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	idx.Refresh()
+	_ = bridge.Close()
+
+	msgs := pub.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message after filtering, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "catalog.registered" {
+		t.Errorf("Topic = %s, want catalog.registered", msgs[0].Topic)
+	}
+}
+
+func TestBridge_PublishFailureReported(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	pub := &recordingPublisher{err: errors.New("broker down")}
+
+	var mu sync.Mutex
+	var reported []error
+	bridge, _ := NewBridge(idx, pub, BridgeOptions{
+		OnError: func(_ index.ChangeEvent, err error) {
+			mu.Lock()
+			reported = append(reported, err)
+			mu.Unlock()
+		},
+	})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	_ = bridge.Close()
+
+	if stats := bridge.Stats(); stats.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", stats.Failed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 {
+		t.Fatalf("expected 1 reported error, got %d", len(reported))
+	}
+}
+
+func TestBridge_CloseIdempotentAndUnsubscribes(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	pub := &recordingPublisher{}
+	bridge, _ := NewBridge(idx, pub, BridgeOptions{})
+
+	_ = bridge.Close()
+	_ = bridge.Close()
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	if len(pub.messages()) != 0 {
+		t.Fatal("expected no messages after Close")
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	conn := &fakeNATSConn{}
+	pub := NewNATSPublisher(conn)
+	if err := pub.Publish(context.Background(), Message{Topic: "catalog", Payload: []byte("{}")}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if len(conn.subjects) != 1 || conn.subjects[0] != "catalog" {
+		t.Fatalf("unexpected subjects: %v", conn.subjects)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pub.Publish(ctx, Message{Topic: "catalog"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
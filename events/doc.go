@@ -0,0 +1,63 @@
+// Package events delivers index change events to external systems.
+//
+// It exists so services such as billing, audit, or cache invalidation can
+// react to catalog changes without polling the index.
+//
+// # Bridge
+//
+// [Bridge] subscribes to an [index.ChangeNotifier] and forwards every
+// [index.ChangeEvent] to a [Publisher] as a JSON-encoded [Event]:
+//
+//	bridge, err := events.NewBridge(idx, events.NewNATSPublisher(nc), events.BridgeOptions{
+//	    Topic: "catalog.changes",
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer bridge.Close()
+//
+// Publishing happens on a background goroutine behind a bounded buffer so
+// slow brokers never block index mutations. Events that do not fit in the
+// buffer are dropped and reported through BridgeOptions.OnError.
+//
+// # Publishers
+//
+// [NATSPublisher] adapts any client with a Publish(subject, data) method,
+// including *nats.Conn, without adding a NATS dependency to this module.
+// Other brokers (Kafka, SNS, Pub/Sub) plug in through [PublisherFunc]:
+//
+//	pub := events.PublisherFunc(func(ctx context.Context, msg events.Message) error {
+//	    return writer.WriteMessages(ctx, kafka.Message{
+//	        Topic: msg.Topic,
+//	        Key:   []byte(msg.Key),
+//	        Value: msg.Payload,
+//	    })
+//	})
+//
+// # Webhooks
+//
+// [Dispatcher] delivers events to HTTP endpoints for systems that cannot hold
+// a broker connection. Each [Webhook] can filter by namespace and change type
+// and, when a secret is set, receives an HMAC-SHA256 signature of the
+// X-Tooldiscovery-Timestamp header and body in the X-Tooldiscovery-Signature
+// header:
+//
+//	d, _ := events.NewDispatcher(idx, events.DispatcherOptions{})
+//	_ = d.Register(events.Webhook{
+//	    ID:         "billing",
+//	    URL:        "https://billing.internal/hooks/tools",
+//	    Secret:     os.Getenv("WEBHOOK_SECRET"),
+//	    Namespaces: []string{"payments"},
+//	})
+//
+// Transport errors, 429, and 5xx responses are retried with exponential
+// backoff; other responses fail immediately. Recent deliveries per webhook
+// are available from [Dispatcher.Deliveries]. Every attempt carries the time
+// it was sent in X-Tooldiscovery-Timestamp, while the event's own time stays in
+// the payload. Receivers authenticate requests with [VerifySignature] and
+// reject stale timestamps to prevent replays.
+//
+// # Thread Safety
+//
+// All exported types are safe for concurrent use.
+package events
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Error values for event delivery.
+var (
+	ErrInvalidPublisher = errors.New("events: publisher is required")
+	ErrInvalidNotifier  = errors.New("events: change notifier is required")
+	ErrBufferFull       = errors.New("events: buffer full, event dropped")
+)
+
+// Event is the wire representation of an index.ChangeEvent.
+type Event struct {
+	Type      index.ChangeType   `json:"type"`
+	ToolID    string             `json:"toolId,omitempty"`
+	Backend   *model.ToolBackend `json:"backend,omitempty"`
+	Version   uint64             `json:"version"`
+	Timestamp time.Time          `json:"timestamp"`
+
+	// ToolVersion and PreviousToolVersion mirror index.ChangeEvent.
+	ToolVersion         int `json:"toolVersion,omitempty"`
+	PreviousToolVersion int `json:"previousToolVersion,omitempty"`
+}
+
+// NewEvent converts a change event to its wire form, stamped with at.
+func NewEvent(ev index.ChangeEvent, at time.Time) Event {
+	out := Event{
+		Type:      ev.Type,
+		ToolID:    ev.ToolID,
+		Version:   ev.Version,
+		Timestamp: at.UTC(),
+
+		ToolVersion:         ev.ToolVersion,
+		PreviousToolVersion: ev.PreviousToolVersion,
+	}
+	if ev.Backend.Kind != "" {
+		backend := ev.Backend
+		out.Backend = &backend
+	}
+	return out
+}
+
+// Message is a single broker message produced from an Event.
+type Message struct {
+	// Topic is the subject/topic the message is published to.
+	Topic string
+	// Key is the partitioning key (the tool ID, when present).
+	Key string
+	// Payload is the JSON-encoded Event.
+	Payload []byte
+}
+
+// Publisher sends messages to a message bus.
+//
+// Contract:
+// - Concurrency: implementations must be safe for concurrent use.
+// - Context: must honor cancellation/deadlines.
+// - Errors: return an error when the message was not accepted by the broker.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+// Publish calls f(ctx, msg).
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// encodeMessage builds a Message for the given topic and event.
+func encodeMessage(topic string, ev Event) (Message, error) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{Topic: topic, Key: ev.ToolID, Payload: payload}, nil
+}
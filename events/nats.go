@@ -0,0 +1,32 @@
+package events
+
+import "context"
+
+// NATSConn is the subset of a NATS connection used by NATSPublisher.
+// *nats.Conn from github.com/nats-io/nats.go satisfies this interface.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisher publishes messages to NATS subjects.
+type NATSPublisher struct {
+	conn NATSConn
+}
+
+// NewNATSPublisher creates a publisher over an established NATS connection.
+func NewNATSPublisher(conn NATSConn) *NATSPublisher {
+	return &NATSPublisher{conn: conn}
+}
+
+// Publish sends msg.Payload to the msg.Topic subject.
+func (p *NATSPublisher) Publish(ctx context.Context, msg Message) error {
+	if p.conn == nil {
+		return ErrInvalidPublisher
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.conn.Publish(msg.Topic, msg.Payload)
+}
+
+var _ Publisher = (*NATSPublisher)(nil)
@@ -0,0 +1,458 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Webhook request headers.
+const (
+	HeaderSignature = "X-Tooldiscovery-Signature"
+	HeaderEvent     = "X-Tooldiscovery-Event"
+	HeaderDelivery  = "X-Tooldiscovery-Delivery"
+	HeaderTimestamp = "X-Tooldiscovery-Timestamp"
+)
+
+// Defaults for DispatcherOptions.
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultHistorySize    = 100
+)
+
+// Error values for webhook registration.
+var (
+	ErrInvalidWebhook  = errors.New("events: webhook requires an ID and URL")
+	ErrWebhookExists   = errors.New("events: webhook already registered")
+	ErrWebhookNotFound = errors.New("events: webhook not found")
+)
+
+// Webhook is an HTTP endpoint that receives change events.
+type Webhook struct {
+	// ID uniquely identifies the webhook.
+	ID string
+	// URL receives a POST with the JSON-encoded Event.
+	URL string
+	// Secret signs the HeaderTimestamp value and payload with HMAC-SHA256
+	// when set (see SignPayload). Each attempt is stamped and signed anew.
+	Secret string
+	// Namespaces restricts delivery to tools in these namespaces.
+	// An empty list matches all namespaces.
+	Namespaces []string
+	// EventTypes restricts delivery to these change types.
+	// An empty list matches all types.
+	EventTypes []index.ChangeType
+	// Headers are added to every request.
+	Headers map[string]string
+}
+
+// Matches reports whether ev passes the webhook's filters.
+func (w Webhook) Matches(ev index.ChangeEvent) bool {
+	if len(w.EventTypes) > 0 && !slices.Contains(w.EventTypes, ev.Type) {
+		return false
+	}
+	if len(w.Namespaces) > 0 {
+		if ev.ToolID == "" {
+			return false
+		}
+		id, err := index.ParseToolID(ev.ToolID)
+		if err != nil || !slices.Contains(w.Namespaces, id.Namespace) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeliveryStatus is the state of a webhook delivery.
+type DeliveryStatus string
+
+// Delivery states.
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery records one event sent to one webhook.
+type Delivery struct {
+	ID          string         `json:"id"`
+	WebhookID   string         `json:"webhookId"`
+	Event       Event          `json:"event"`
+	Status      DeliveryStatus `json:"status"`
+	Attempts    int            `json:"attempts"`
+	StatusCode  int            `json:"statusCode,omitempty"`
+	LastError   string         `json:"lastError,omitempty"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	CompletedAt time.Time      `json:"completedAt,omitzero"`
+}
+
+// DispatcherOptions configures a Dispatcher.
+type DispatcherOptions struct {
+	// Client sends webhook requests. Default: a client with RequestTimeout.
+	Client *http.Client
+
+	// MaxAttempts is the number of tries per delivery. Default: DefaultMaxAttempts.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry; it doubles on each
+	// subsequent retry up to MaxBackoff.
+	// Default: DefaultInitialBackoff / DefaultMaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// RequestTimeout bounds each HTTP attempt. Default: DefaultRequestTimeout.
+	RequestTimeout time.Duration
+
+	// BufferSize is the number of events queued for dispatch.
+	// Default: DefaultBufferSize.
+	BufferSize int
+
+	// HistorySize is the number of deliveries retained per webhook.
+	// Default: DefaultHistorySize.
+	HistorySize int
+
+	// OnError is called when an event is dropped or a delivery fails permanently.
+	OnError func(ev index.ChangeEvent, err error)
+
+	// Now returns the event timestamp and the HeaderTimestamp value of each
+	// delivery attempt. Default: time.Now.
+	Now func() time.Time
+}
+
+// Dispatcher delivers index change events to registered webhooks.
+type Dispatcher struct {
+	opts DispatcherOptions
+
+	queue       chan index.ChangeEvent
+	unsubscribe func()
+	done        chan struct{}
+	stop        chan struct{} // closed by Close to abandon retries
+	inflight    sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	seq     uint64
+	hooks   map[string]Webhook
+	history map[string][]*Delivery
+}
+
+// NewDispatcher subscribes to notifier and starts dispatching events to
+// registered webhooks. Call Close to unsubscribe and finish pending deliveries.
+func NewDispatcher(notifier index.ChangeNotifier, opts DispatcherOptions) (*Dispatcher, error) {
+	if notifier == nil {
+		return nil, ErrInvalidNotifier
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = DefaultRequestTimeout
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultBufferSize
+	}
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = DefaultHistorySize
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.RequestTimeout}
+	}
+
+	d := &Dispatcher{
+		opts:    opts,
+		queue:   make(chan index.ChangeEvent, opts.BufferSize),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		hooks:   make(map[string]Webhook),
+		history: make(map[string][]*Delivery),
+	}
+	go d.run()
+	d.unsubscribe = notifier.OnChange(d.enqueue)
+	return d, nil
+}
+
+// Register adds a webhook.
+func (d *Dispatcher) Register(hook Webhook) error {
+	if hook.ID == "" || hook.URL == "" {
+		return ErrInvalidWebhook
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.hooks[hook.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrWebhookExists, hook.ID)
+	}
+	hook.Namespaces = slices.Clone(hook.Namespaces)
+	hook.EventTypes = slices.Clone(hook.EventTypes)
+	d.hooks[hook.ID] = hook
+	return nil
+}
+
+// Unregister removes a webhook and its delivery history.
+// Deliveries already in flight are allowed to finish.
+func (d *Dispatcher) Unregister(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.hooks[id]; !exists {
+		return fmt.Errorf("%w: %s", ErrWebhookNotFound, id)
+	}
+	delete(d.hooks, id)
+	delete(d.history, id)
+	return nil
+}
+
+// Webhooks returns the registered webhooks sorted by ID.
+func (d *Dispatcher) Webhooks() []Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]Webhook, 0, len(d.hooks))
+	for _, hook := range d.hooks {
+		out = append(out, hook)
+	}
+	slices.SortFunc(out, func(a, b Webhook) int { return strings.Compare(a.ID, b.ID) })
+	return out
+}
+
+// Deliveries returns the retained deliveries for a webhook, oldest first.
+func (d *Dispatcher) Deliveries(webhookID string) ([]Delivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, exists := d.hooks[webhookID]; !exists {
+		return nil, fmt.Errorf("%w: %s", ErrWebhookNotFound, webhookID)
+	}
+	records := d.history[webhookID]
+	out := make([]Delivery, len(records))
+	for i, rec := range records {
+		out[i] = *rec
+	}
+	return out, nil
+}
+
+// Close unsubscribes from the notifier, makes one attempt at each queued
+// event, and waits for attempts in flight to finish. Deliveries waiting to
+// retry are not retried: they fail with their last error.
+// It is safe to call multiple times.
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	d.unsubscribe()
+	close(d.stop)
+	close(d.queue)
+	<-d.done
+	d.inflight.Wait()
+	return nil
+}
+
+func (d *Dispatcher) enqueue(ev index.ChangeEvent) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	select {
+	case d.queue <- ev:
+		d.mu.Unlock()
+		return
+	default:
+	}
+	d.mu.Unlock()
+	d.reportError(ev, ErrBufferFull)
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for ev := range d.queue {
+		d.dispatch(ev)
+	}
+}
+
+// dispatch starts one delivery per matching webhook.
+func (d *Dispatcher) dispatch(ev index.ChangeEvent) {
+	wire := NewEvent(ev, d.opts.Now())
+	msg, err := encodeMessage("", wire)
+	if err != nil {
+		d.reportError(ev, err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, hook := range d.hooks {
+		if !hook.Matches(ev) {
+			continue
+		}
+		d.seq++
+		rec := &Delivery{
+			ID:        strconv.FormatUint(d.seq, 10),
+			WebhookID: hook.ID,
+			Event:     wire,
+			Status:    DeliveryPending,
+			CreatedAt: wire.Timestamp,
+		}
+		d.recordLocked(rec)
+		d.inflight.Add(1)
+		go d.deliver(hook, ev, rec, msg.Payload)
+	}
+}
+
+// recordLocked appends rec to the webhook history, evicting the oldest entry
+// when the history is full. Caller must hold d.mu.
+func (d *Dispatcher) recordLocked(rec *Delivery) {
+	records := append(d.history[rec.WebhookID], rec)
+	if len(records) > d.opts.HistorySize {
+		records = records[len(records)-d.opts.HistorySize:]
+	}
+	d.history[rec.WebhookID] = records
+}
+
+func (d *Dispatcher) deliver(hook Webhook, ev index.ChangeEvent, rec *Delivery, payload []byte) {
+	defer d.inflight.Done()
+
+	backoff := d.opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		code, err := d.send(hook, rec, payload)
+		retry := err != nil && retryable(code)
+
+		d.mu.Lock()
+		rec.Attempts = attempt
+		rec.StatusCode = code
+		switch {
+		case err == nil:
+			rec.Status = DeliverySucceeded
+			rec.LastError = ""
+			rec.CompletedAt = d.opts.Now().UTC()
+		case !retry || attempt >= d.opts.MaxAttempts:
+			rec.Status = DeliveryFailed
+			rec.LastError = err.Error()
+			rec.CompletedAt = d.opts.Now().UTC()
+		default:
+			rec.LastError = err.Error()
+		}
+		status := rec.Status
+		d.mu.Unlock()
+
+		if status == DeliveryPending && !d.wait(backoff) {
+			// Closed while waiting to retry.
+			d.mu.Lock()
+			rec.Status = DeliveryFailed
+			rec.CompletedAt = d.opts.Now().UTC()
+			status = rec.Status
+			d.mu.Unlock()
+		}
+		if status == DeliveryFailed {
+			d.reportError(ev, fmt.Errorf("webhook %s: %w", hook.ID, err))
+		}
+		if status != DeliveryPending {
+			return
+		}
+		backoff = min(backoff*2, d.opts.MaxBackoff)
+	}
+}
+
+// wait sleeps for backoff and reports false if Close interrupts it.
+func (d *Dispatcher) wait(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.stop:
+		return false
+	}
+}
+
+// send performs one delivery attempt and returns the HTTP status code.
+func (d *Dispatcher) send(hook Webhook, rec *Delivery, payload []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.opts.RequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(rec.Event.Type))
+	req.Header.Set(HeaderDelivery, rec.ID)
+	// Stamp each attempt with the current time so retries after long
+	// backoffs pass receivers' staleness checks; the payload keeps the
+	// event time.
+	timestamp := strconv.FormatInt(d.opts.Now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, SignPayload(hook.Secret, timestamp, payload))
+	}
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) reportError(ev index.ChangeEvent, err error) {
+	if d.opts.OnError != nil {
+		d.opts.OnError(ev, err)
+	}
+}
+
+// retryable reports whether a failed attempt with the given status code
+// should be retried. Transport errors (code 0), 429, and 5xx are retried;
+// other client errors are permanent.
+func retryable(code int) bool {
+	return code == 0 || code == http.StatusTooManyRequests || code >= 500
+}
+
+// SignPayload returns the HeaderSignature value for payload sent with the
+// HeaderTimestamp value timestamp: "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of timestamp + "." + payload keyed with secret. Signing the
+// timestamp lets receivers reject replayed requests.
+func SignPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature is a valid HeaderSignature value
+// for payload and the HeaderTimestamp value timestamp. Receivers use it to
+// authenticate webhook requests, and should also reject timestamps too far
+// from their clock.
+func VerifySignature(secret, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(SignPayload(secret, timestamp, payload)), []byte(signature))
+}
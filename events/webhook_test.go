@@ -0,0 +1,311 @@
+package events
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func newTestDispatcher(t *testing.T, idx *index.InMemoryIndex) *Dispatcher {
+	t.Helper()
+	d, err := NewDispatcher(idx, DispatcherOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+	return d
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	var valid []bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		valid = append(valid, VerifySignature("s3cret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)))
+		mu.Unlock()
+		if r.Header.Get(HeaderEvent) != string(index.ChangeRegistered) {
+			t.Errorf("unexpected %s header: %q", HeaderEvent, r.Header.Get(HeaderEvent))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	idx := index.NewInMemoryIndex()
+	d := newTestDispatcher(t, idx)
+	if err := d.Register(Webhook{ID: "audit", URL: srv.URL, Secret: "s3cret"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	_ = idx.RegisterTool(testTool("github", "create_issue"), model.NewLocalBackend("h"))
+	_ = d.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bodies) != 1 || !valid[0] {
+		t.Fatalf("expected 1 validly signed request, got %d (valid=%v)", len(bodies), valid)
+	}
+
+	deliveries, err := d.Deliveries("audit")
+	if err != nil {
+		t.Fatalf("Deliveries failed: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+func TestDispatcher_Filters(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	idx := index.NewInMemoryIndex()
+	d := newTestDispatcher(t, idx)
+	_ = d.Register(Webhook{
+		ID:         "github-only",
+		URL:        srv.URL,
+		Namespaces: []string{"github"},
+		EventTypes: []index.ChangeType{index.ChangeRegistered},
+	})
+
+	_ = idx.RegisterTool(testTool("github", "create_issue"), model.NewLocalBackend("h"))
+	_ = idx.RegisterTool(testTool("slack", "post"), model.NewLocalBackend("h"))
+	idx.Refresh()
+	_ = d.Close()
+
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 delivery, got %d", got)
+	}
+}
+
+func TestDispatcher_RetriesThenSucceeds(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	idx := index.NewInMemoryIndex()
+	d := newTestDispatcher(t, idx)
+	_ = d.Register(Webhook{ID: "flaky", URL: srv.URL})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	deliveries := waitForDelivery(t, d, "flaky")
+	_ = d.Close()
+
+	if len(deliveries) != 1 || deliveries[0].Status != DeliverySucceeded || deliveries[0].Attempts != 3 {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+func TestDispatcher_RetriesRestampTimestamp(t *testing.T) {
+	var mu sync.Mutex
+	var stamps []string
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		stamp := r.Header.Get(HeaderTimestamp)
+		if !VerifySignature("s3cret", stamp, body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("attempt with timestamp %s has an invalid signature", stamp)
+		}
+		mu.Lock()
+		stamps = append(stamps, stamp)
+		bodies = append(bodies, string(body))
+		attempt := len(stamps)
+		mu.Unlock()
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var clockMu sync.Mutex
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	idx := index.NewInMemoryIndex()
+	d, _ := NewDispatcher(idx, DispatcherOptions{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		Now: func() time.Time {
+			clockMu.Lock()
+			defer clockMu.Unlock()
+			clock = clock.Add(time.Minute)
+			return clock
+		},
+	})
+	_ = d.Register(Webhook{ID: "flaky", URL: srv.URL, Secret: "s3cret"})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	waitForDelivery(t, d, "flaky")
+	_ = d.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(stamps) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(stamps))
+	}
+	for i := 1; i < len(stamps); i++ {
+		if stamps[i] <= stamps[i-1] {
+			t.Errorf("timestamps did not advance across retries: %v", stamps)
+		}
+		if bodies[i] != bodies[0] {
+			t.Errorf("payload changed across retries: %q vs %q", bodies[i], bodies[0])
+		}
+	}
+}
+
+func TestDispatcher_CloseAbandonsRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	idx := index.NewInMemoryIndex()
+	var failures atomic.Int32
+	d, _ := NewDispatcher(idx, DispatcherOptions{
+		InitialBackoff: time.Hour,
+		OnError:        func(index.ChangeEvent, error) { failures.Add(1) },
+	})
+	_ = d.Register(Webhook{ID: "down", URL: srv.URL})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	start := time.Now()
+	_ = d.Close()
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Close took %v, want it not to wait out the backoff", elapsed)
+	}
+	deliveries, _ := d.Deliveries("down")
+	if len(deliveries) != 1 || deliveries[0].Status != DeliveryFailed || deliveries[0].Attempts != 1 || deliveries[0].LastError == "" {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+	if failures.Load() != 1 {
+		t.Errorf("expected OnError once, got %d", failures.Load())
+	}
+}
+
+// waitForDelivery waits until the first delivery to webhook completes and
+// returns the webhook's deliveries.
+func waitForDelivery(t *testing.T, d *Dispatcher, webhook string) []Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, _ := d.Deliveries(webhook)
+		if len(deliveries) > 0 && deliveries[0].Status != DeliveryPending {
+			return deliveries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery to %s still pending: %+v", webhook, deliveries)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcher_PermanentFailure(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	idx := index.NewInMemoryIndex()
+	var failures atomic.Int32
+	d, _ := NewDispatcher(idx, DispatcherOptions{
+		InitialBackoff: time.Millisecond,
+		OnError:        func(index.ChangeEvent, error) { failures.Add(1) },
+	})
+	_ = d.Register(Webhook{ID: "bad", URL: srv.URL})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	_ = d.Close()
+
+	if calls.Load() != 1 {
+		t.Errorf("4xx should not be retried, got %d calls", calls.Load())
+	}
+	if failures.Load() != 1 {
+		t.Errorf("expected OnError once, got %d", failures.Load())
+	}
+	deliveries, _ := d.Deliveries("bad")
+	if len(deliveries) != 1 || deliveries[0].Status != DeliveryFailed || deliveries[0].LastError == "" {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+}
+
+func TestDispatcher_Registration(t *testing.T) {
+	d := newTestDispatcher(t, index.NewInMemoryIndex())
+	defer func() { _ = d.Close() }()
+
+	if err := d.Register(Webhook{ID: "x"}); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("expected ErrInvalidWebhook, got %v", err)
+	}
+	_ = d.Register(Webhook{ID: "b", URL: "http://example.invalid"})
+	_ = d.Register(Webhook{ID: "a", URL: "http://example.invalid"})
+	if err := d.Register(Webhook{ID: "a", URL: "http://example.invalid"}); !errors.Is(err, ErrWebhookExists) {
+		t.Fatalf("expected ErrWebhookExists, got %v", err)
+	}
+	if hooks := d.Webhooks(); len(hooks) != 2 || hooks[0].ID != "a" {
+		t.Fatalf("unexpected webhooks: %+v", hooks)
+	}
+	if err := d.Unregister("a"); err != nil {
+		t.Fatalf("Unregister failed: %v", err)
+	}
+	if _, err := d.Deliveries("a"); !errors.Is(err, ErrWebhookNotFound) {
+		t.Fatalf("expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"type":"registered"}`)
+	sig := SignPayload("k", "1700000000", payload)
+	if !VerifySignature("k", "1700000000", payload, sig) {
+		t.Fatal("expected signature to verify")
+	}
+	if VerifySignature("other", "1700000000", payload, sig) {
+		t.Fatal("expected signature with wrong secret to fail")
+	}
+	if VerifySignature("k", "1700000001", payload, sig) {
+		t.Fatal("expected signature with another timestamp to fail")
+	}
+}
+
+func TestDispatcher_InjectedClock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	fixed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	idx := index.NewInMemoryIndex()
+	d, _ := NewDispatcher(idx, DispatcherOptions{Now: func() time.Time { return fixed }})
+	_ = d.Register(Webhook{ID: "audit", URL: srv.URL})
+
+	_ = idx.RegisterTool(testTool("a", "one"), model.NewLocalBackend("h"))
+	_ = d.Close()
+
+	deliveries, _ := d.Deliveries("audit")
+	if len(deliveries) != 1 || !deliveries[0].CreatedAt.Equal(fixed) || !deliveries[0].CompletedAt.Equal(fixed) {
+		t.Fatalf("deliveries = %+v, want timestamps %v", deliveries, fixed)
+	}
+}
@@ -0,0 +1,144 @@
+package index
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HintFilterPrefix marks a behavior hint filter term in a search query. A
+// query term of the form "hint.<name>=<true|false>" restricts results to
+// tools whose hint has that value; the term itself is not searched.
+const HintFilterPrefix = "hint."
+
+// Hint names accepted in "hint.<name>=<bool>" filter terms. Names are
+// matched case-insensitively.
+const (
+	HintReadOnly    = "readOnly"
+	HintDestructive = "destructive"
+	HintIdempotent  = "idempotent"
+	HintOpenWorld   = "openWorld"
+)
+
+// ToolHints are the behavior hints of a tool's MCP ToolAnnotations with the
+// protocol defaults applied, so each field is what an agent should assume.
+// Hints are advisory: servers declare them and they are not verified.
+type ToolHints struct {
+	// ReadOnly reports that the tool does not modify its environment.
+	ReadOnly bool `json:"readOnly"`
+
+	// Destructive reports that the tool may perform destructive updates.
+	// Read-only tools are never destructive; otherwise it defaults to true.
+	Destructive bool `json:"destructive"`
+
+	// Idempotent reports that repeated calls with the same arguments have
+	// no additional effect. Read-only tools are always idempotent.
+	Idempotent bool `json:"idempotent"`
+
+	// OpenWorld reports that the tool may interact with external entities.
+	// Default: true.
+	OpenWorld bool `json:"openWorld"`
+}
+
+// HintsFromAnnotations resolves annotations to ToolHints. Nil annotations
+// yield the MCP defaults: a destructive, non-idempotent, open-world tool.
+func HintsFromAnnotations(annotations *mcp.ToolAnnotations) ToolHints {
+	if annotations == nil {
+		return ToolHints{Destructive: true, OpenWorld: true}
+	}
+	hints := ToolHints{
+		ReadOnly:    annotations.ReadOnlyHint,
+		Destructive: annotations.DestructiveHint == nil || *annotations.DestructiveHint,
+		Idempotent:  annotations.IdempotentHint,
+		OpenWorld:   annotations.OpenWorldHint == nil || *annotations.OpenWorldHint,
+	}
+	if hints.ReadOnly {
+		hints.Destructive = false
+		hints.Idempotent = true
+	}
+	return hints
+}
+
+// hintsFromTool returns the hints surfaced in Summary.Hints, which are only
+// set for tools that declare annotations.
+func hintsFromTool(annotations *mcp.ToolAnnotations) *ToolHints {
+	if annotations == nil {
+		return nil
+	}
+	hints := HintsFromAnnotations(annotations)
+	return &hints
+}
+
+// hint returns the value of the named hint.
+func (h ToolHints) hint(name string) bool {
+	switch name {
+	case HintReadOnly:
+		return h.ReadOnly
+	case HintDestructive:
+		return h.Destructive
+	case HintIdempotent:
+		return h.Idempotent
+	default:
+		return h.OpenWorld
+	}
+}
+
+// canonicalHint returns the canonical spelling of a hint name.
+func canonicalHint(name string) (string, bool) {
+	for _, hint := range []string{HintReadOnly, HintDestructive, HintIdempotent, HintOpenWorld} {
+		if strings.EqualFold(name, hint) {
+			return hint, true
+		}
+	}
+	return "", false
+}
+
+// ParseHintFilters splits a search query into the remaining query and hint
+// filters keyed by canonical hint name. Terms of the form
+// "hint.<name>=<bool>" become filters; when a hint is filtered several
+// times, the last wins. Terms with an unknown name or a value that is not a
+// boolean are left in the query. Other terms are returned space-joined.
+func ParseHintFilters(query string) (string, map[string]bool) {
+	fields := strings.Fields(query)
+	var filters map[string]bool
+	rest := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if term, ok := strings.CutPrefix(field, HintFilterPrefix); ok {
+			name, value, _ := strings.Cut(term, "=")
+			hint, known := canonicalHint(name)
+			want, err := strconv.ParseBool(value)
+			if known && err == nil {
+				if filters == nil {
+					filters = make(map[string]bool)
+				}
+				filters[hint] = want
+				continue
+			}
+		}
+		rest = append(rest, field)
+	}
+	if filters == nil {
+		return query, nil
+	}
+	return strings.Join(rest, " "), filters
+}
+
+// MatchesHints reports whether hints has every filtered hint with the
+// wanted value. Nil hints match as the MCP defaults, so a tool without
+// annotations is treated as destructive.
+func MatchesHints(hints *ToolHints, filters map[string]bool) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	resolved := HintsFromAnnotations(nil)
+	if hints != nil {
+		resolved = *hints
+	}
+	for name, want := range filters {
+		if resolved.hint(name) != want {
+			return false
+		}
+	}
+	return true
+}
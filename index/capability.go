@@ -0,0 +1,61 @@
+package index
+
+import "github.com/jonwraymond/toolfoundation/model"
+
+// Version reports the version of idx when it implements Versioner.
+// Indexes without version tracking report 0, which callers should treat as
+// "unknown" rather than "empty".
+func Version(idx Index) uint64 {
+	if v, ok := idx.(Versioner); ok {
+		return v.Version()
+	}
+	return 0
+}
+
+// Refresh forces idx to rebuild cached search docs when it implements
+// Refresher and returns the resulting version. Indexes without a cache are
+// always current, so the fallback is Version(idx).
+func Refresh(idx Index) uint64 {
+	if r, ok := idx.(Refresher); ok {
+		return r.Refresh()
+	}
+	return Version(idx)
+}
+
+// NormalizeTags normalizes tags as idx does when it implements
+// TagNormalizer. Other indexes are assumed to use the default policy,
+// model.NormalizeTags.
+func NormalizeTags(idx Index, tags []string) []string {
+	if n, ok := idx.(TagNormalizer); ok {
+		return n.NormalizeTags(tags)
+	}
+	return model.NormalizeTags(tags)
+}
+
+// OnChange subscribes listener to idx when it implements ChangeNotifier.
+// Indexes without change notification return a no-op unsubscribe function;
+// callers that depend on events should poll Version instead.
+func OnChange(idx Index, listener ChangeListener) (unsubscribe func()) {
+	if n, ok := idx.(ChangeNotifier); ok {
+		return n.OnChange(listener)
+	}
+	return func() {}
+}
+
+// Compile-time checks that InMemoryIndex provides every optional capability.
+var (
+	_ Index                = (*InMemoryIndex)(nil)
+	_ Versioner            = (*InMemoryIndex)(nil)
+	_ Refresher            = (*InMemoryIndex)(nil)
+	_ Fingerprinter        = (*InMemoryIndex)(nil)
+	_ TagNormalizer        = (*InMemoryIndex)(nil)
+	_ ChangeNotifier       = (*InMemoryIndex)(nil)
+	_ ChangeWatcher        = (*InMemoryIndex)(nil)
+	_ MaintenanceScheduler = (*InMemoryIndex)(nil)
+	_ ToolVersioner        = (*InMemoryIndex)(nil)
+	_ MCPReplacer          = (*InMemoryIndex)(nil)
+	_ LimitReporter        = (*InMemoryIndex)(nil)
+	_ HealthTracker        = (*InMemoryIndex)(nil)
+	_ CategoryTaxonomy     = (*InMemoryIndex)(nil)
+	_ BackendExplainer     = (*InMemoryIndex)(nil)
+)
@@ -0,0 +1,187 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CategoryFilterPrefix marks a category filter term in a search query. A
+// query term of the form "category=<path>" restricts results to tools in
+// the category or any of its subcategories; the term itself is not
+// searched.
+const CategoryFilterPrefix = "category="
+
+// CategorySeparator separates the levels of a hierarchical category path,
+// such as "devops/containers".
+const CategorySeparator = "/"
+
+// ErrInvalidCategory is returned for empty or malformed category paths.
+var ErrInvalidCategory = fmt.Errorf("%w: invalid category", ErrInvalidTool)
+
+// Category is a node of the category taxonomy.
+type Category struct {
+	// Path is the normalized category path, such as "devops/containers".
+	Path string `json:"path"`
+
+	// Parent is the path of the parent category; empty for top-level
+	// categories.
+	Parent string `json:"parent,omitempty"`
+
+	// Registered reports whether the category was registered with
+	// RegisterCategory, rather than only used by a tool.
+	Registered bool `json:"registered"`
+
+	// Tools is the number of tools in the category itself, and
+	// SubtreeTools the number in the category and its subcategories.
+	Tools        int `json:"tools"`
+	SubtreeTools int `json:"subtreeTools"`
+}
+
+// CategoryTaxonomy is an optional interface for indexes that maintain a
+// hierarchical category taxonomy. Tools are assigned a category through
+// Meta["category"], surfaced as Summary.Category.
+//
+// Contract:
+//   - Concurrency: methods must be safe for concurrent use.
+//   - Errors: malformed paths return ErrInvalidCategory.
+//   - Ordering: ListCategories returns categories ordered by path, so a
+//     parent precedes its subcategories.
+type CategoryTaxonomy interface {
+	RegisterCategory(path string) error
+	ListCategories() []Category
+}
+
+// NormalizeCategory returns the canonical form of a category path: levels
+// are lowercased and trimmed, and leading, trailing, and repeated
+// separators are dropped, so " DevOps//Containers/ " becomes
+// "devops/containers". It reports false for a path with no levels.
+func NormalizeCategory(path string) (string, bool) {
+	var levels []string
+	for level := range strings.SplitSeq(path, CategorySeparator) {
+		if level = strings.ToLower(strings.TrimSpace(level)); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return "", false
+	}
+	return strings.Join(levels, CategorySeparator), true
+}
+
+// InCategory reports whether category lies in the subtree rooted at path:
+// it is path itself or one of its subcategories. Both are normalized first.
+func InCategory(category, path string) bool {
+	category, ok := NormalizeCategory(category)
+	if !ok {
+		return false
+	}
+	path, ok = NormalizeCategory(path)
+	if !ok {
+		return false
+	}
+	return category == path || strings.HasPrefix(category, path+CategorySeparator)
+}
+
+// ParseCategoryFilter splits a search query into the remaining query and a
+// category filter. A "category=<path>" term becomes the filter; when there
+// are several, the last wins. Other terms, including metadata filters, are
+// returned space-joined.
+func ParseCategoryFilter(query string) (string, string) {
+	fields := strings.Fields(query)
+	var category string
+	rest := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if value, ok := strings.CutPrefix(field, CategoryFilterPrefix); ok && value != "" {
+			category = value
+			continue
+		}
+		rest = append(rest, field)
+	}
+	if category == "" {
+		return query, ""
+	}
+	return strings.Join(rest, " "), category
+}
+
+// RegisterCategory adds a category and its ancestors to the taxonomy, so
+// ListCategories reports them before any tool uses them. Registering a
+// category again is a no-op.
+func (idx *InMemoryIndex) RegisterCategory(path string) error {
+	normalized, ok := NormalizeCategory(path)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidCategory, path)
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.categories == nil {
+		idx.categories = make(map[string]struct{})
+	}
+	idx.categories[normalized] = struct{}{}
+	return nil
+}
+
+// RegisteredCategories returns the normalized paths passed to
+// RegisterCategory, sorted, without their ancestors or the categories only
+// used by tools. Persistent indexes store it to restore the taxonomy.
+func (idx *InMemoryIndex) RegisteredCategories() []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	out := make([]string, 0, len(idx.categories))
+	for path := range idx.categories {
+		out = append(out, path)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ListCategories returns the registered categories and those used by
+// tools, with their ancestors, and counts the tools in each.
+func (idx *InMemoryIndex) ListCategories() []Category {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	nodes := make(map[string]*Category)
+	node := func(path string) *Category {
+		if c, ok := nodes[path]; ok {
+			return c
+		}
+		c := &Category{Path: path}
+		if i := strings.LastIndex(path, CategorySeparator); i >= 0 {
+			c.Parent = path[:i]
+		}
+		nodes[path] = c
+		return c
+	}
+	// addPath creates path and its ancestors, calling visit on each.
+	addPath := func(path string, visit func(*Category)) {
+		for p := path; ; {
+			visit(node(p))
+			i := strings.LastIndex(p, CategorySeparator)
+			if i < 0 {
+				return
+			}
+			p = p[:i]
+		}
+	}
+
+	for path := range idx.categories {
+		node(path).Registered = true
+		addPath(path, func(*Category) {})
+	}
+	for _, record := range idx.tools {
+		path, ok := NormalizeCategory(categoryFromMeta(record.tool.Meta))
+		if !ok {
+			continue
+		}
+		node(path).Tools++
+		addPath(path, func(c *Category) { c.SubtreeTools++ })
+	}
+
+	out := make([]Category, 0, len(nodes))
+	for _, c := range nodes {
+		out = append(out, *c)
+	}
+	slices.SortFunc(out, func(a, b Category) int { return strings.Compare(a.Path, b.Path) })
+	return out
+}
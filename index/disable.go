@@ -0,0 +1,107 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DisabledTool describes a tool taken out of rotation with DisableTool.
+type DisabledTool struct {
+	ToolID     string    `json:"toolId"`
+	DisabledAt time.Time `json:"disabledAt"`
+}
+
+// DisableTool hides a registered tool from Search, SearchPage, GetTool, and
+// GetAllBackends, which report ErrNotFound for it, without dropping its
+// registration, backends, metadata, or versions. Re-registering a disabled
+// tool updates it but leaves it disabled; removing its last backend removes
+// it for good. Disabling a disabled tool is a no-op. Emits ChangeDisabled.
+func (idx *InMemoryIndex) DisableTool(id string) error {
+	return idx.setDisabled(id, true)
+}
+
+// EnableTool restores a tool hidden with DisableTool. Enabling a tool that
+// is not disabled is a no-op. Emits ChangeEnabled.
+func (idx *InMemoryIndex) EnableTool(id string) error {
+	return idx.setDisabled(id, false)
+}
+
+// ListDisabled returns the disabled tools ordered by tool ID.
+func (idx *InMemoryIndex) ListDisabled() []DisabledTool {
+	idx.mu.RLock()
+	out := make([]DisabledTool, 0, idx.disabled)
+	for id, record := range idx.tools {
+		if record.disabled() {
+			out = append(out, DisabledTool{ToolID: id, DisabledAt: record.disabledAt})
+		}
+	}
+	idx.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ToolID < out[j].ToolID })
+	return out
+}
+
+func (idx *InMemoryIndex) setDisabled(id string, disable bool) error {
+	idx.mu.Lock()
+	record, ok := idx.tools[id]
+	if !ok {
+		idx.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if record.disabled() == disable {
+		idx.mu.Unlock()
+		return nil
+	}
+	var at time.Time
+	if disable {
+		at = idx.now()
+	}
+	event := idx.setDisabledAtLocked(id, record, at)
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, event)
+	return nil
+}
+
+// setDisabledAtLocked disables record, registered as id, as of at, or
+// enables it when at is zero, and records the resulting ChangeDisabled or
+// ChangeEnabled. Must be called with idx.mu held and the disabled state
+// changing.
+func (idx *InMemoryIndex) setDisabledAtLocked(id string, record *toolRecord, at time.Time) ChangeEvent {
+	changeType := ChangeEnabled
+	if !at.IsZero() {
+		if !record.disabled() {
+			idx.disabled++
+		}
+		changeType = ChangeDisabled
+	} else if record.disabled() {
+		idx.disabled--
+	}
+	record.disabledAt = at
+	idx.markSearchDocsDirtyLocked()
+	event := ChangeEvent{Type: changeType, ToolID: id, Version: idx.indexVersion}
+	idx.recordChangeLocked(event)
+	return event
+}
+
+func (r *toolRecord) disabled() bool {
+	return !r.disabledAt.IsZero()
+}
+
+// filterDocsByDisabled drops docs of disabled tools.
+func (idx *InMemoryIndex) filterDocsByDisabled(docs []SearchDoc) []SearchDoc {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if idx.disabled == 0 {
+		return docs
+	}
+	out := make([]SearchDoc, 0, len(docs))
+	for _, doc := range docs {
+		if record, ok := idx.tools[doc.ID]; ok && record.disabled() {
+			continue
+		}
+		out = append(out, doc)
+	}
+	return out
+}
@@ -33,6 +33,20 @@
 //
 //	results, err := idx.Search("arithmetic", 10)
 //
+// # Tool IDs
+//
+// Tool IDs are "name", "namespace:name", or "namespace:name:version". Each
+// component uses [A-Za-z0-9_.-], and the version may also use "+" for semver
+// build metadata; the ":" separator is reserved. IDs are
+// case-sensitive, but registering an ID that differs from a registered one
+// only by case fails, so lookalike tools cannot coexist. Registration
+// enforces these rules with ErrInvalidTool wrapping a *ToolIDError, which
+// also matches ErrInvalidToolID. ParseToolID and ValidateToolID apply the
+// same rules to IDs from callers:
+//
+//	id, err := index.ParseToolID("github:create_issue:v2")
+//	// id.Namespace == "github", id.Name == "create_issue", id.Version == "v2"
+//
 // # Pluggable Search
 //
 // The index accepts a custom Searcher for advanced search capabilities:
@@ -59,6 +73,126 @@
 //   - OutputModes: Supported output media types
 //   - SecuritySummary: Short auth summary
 //   - Tags: Associated tags for filtering
+//   - Hints: MCP annotation hints (read-only, destructive, idempotent, open world)
+//   - Metadata: Custom organization labels set at registration
+//   - Degraded/DegradedReason: Set while a maintenance window is active
+//   - Health/HealthCheckedAt: Best reported backend health and latest check
+//
+// # Maintenance Windows
+//
+// Planned downtime for a namespace or backend is registered as a
+// MaintenanceWindow. While a window is active, affected tools are returned
+// from Search with Degraded set and the window message as DegradedReason:
+//
+//	err := idx.AddMaintenanceWindow(index.MaintenanceWindow{
+//	    ID:      "db-upgrade",
+//	    Backend: "billing-server",
+//	    Start:   start,
+//	    End:     start.Add(2 * time.Hour),
+//	    Message: "Billing database upgrade",
+//	})
+//
+// # Backend Health
+//
+// Health checkers report each backend's state with SetBackendHealth, naming
+// the backend like a maintenance window does. Search results carry the
+// state of the tool's healthiest backend in Summary.Health; backends without
+// a report count as healthy. With IndexOptions.ExcludeUnreachable, tools
+// whose backends are all unreachable are left out of search:
+//
+//	err := idx.SetBackendHealth("github:create_issue", "github", index.HealthStatus{
+//	    State:   index.HealthUnreachable,
+//	    Message: "connection refused",
+//	})
+//
+// Health is runtime state: it is not persisted and is dropped with the
+// backend.
+//
+// # Canary Rollouts
+//
+// A Rollout limits a tool to a percentage of principals (agents, sessions,
+// or tenants) plus an explicit allowlist. Principals are bucketed by a stable
+// hash, so ramping up only ever adds principals. Search and SearchPage run as
+// the anonymous principal and omit partially rolled-out tools; SearchFor and
+// SearchPageFor search as a given principal:
+//
+//	err := idx.SetRollout(index.Rollout{ToolID: "ops:deploy-v2", Percent: 5})
+//	results, err := idx.SearchFor("agent-42", "deploy", 10)
+//	err = idx.RampRollout("ops:deploy-v2", 50)
+//	err = idx.FinalizeRollout("ops:deploy-v2") // visible to everyone
+//
+// # Disabling Tools
+//
+// DisableTool takes a tool out of rotation without unregistering it: Search,
+// SearchPage, GetTool, and GetAllBackends treat it as not found, while its
+// backends, metadata, and versions are kept for EnableTool to restore.
+// ListDisabled reports what is hidden and since when, and ToolIDs still
+// includes disabled tools:
+//
+//	err := idx.DisableTool("github:delete_repo")
+//	err = idx.EnableTool("github:delete_repo")
+//
+// # Custom Metadata
+//
+// Tools can carry an arbitrary string label set, separate from MCP Meta, for
+// ownership or compliance labels. Metadata is surfaced in Summary and can be
+// filtered with "metadata.<key>=<value>" query terms:
+//
+//	err := idx.RegisterToolWithMetadata(tool, backend, map[string]string{
+//	    "team": "platform",
+//	})
+//	results, err := idx.Search("deploy metadata.team=platform", 10)
+//
+// # Tag Normalization
+//
+// Tags are normalized at registration, and Summary.Tags, the search text,
+// and search fingerprints all use the normalized form. By default this is
+// model.NormalizeTags; IndexOptions.TagPolicy can preserve case, map
+// separator characters, change the maximum tag length, and ban tags:
+//
+//	idx := index.NewInMemoryIndex(index.IndexOptions{TagPolicy: index.TagPolicy{
+//	    PreserveCase: true,
+//	    Separators:   map[rune]rune{'/': '.'},
+//	    BannedTags:   []string{"deprecated"},
+//	}})
+//
+// # Categories
+//
+// Tools are assigned a hierarchical category such as "devops/containers"
+// through Meta["category"], surfaced as Summary.Category. RegisterCategory
+// declares categories ahead of the tools that use them, and ListCategories
+// returns the taxonomy with per-category and subtree tool counts. A
+// "category=<path>" query term restricts a search to a category subtree:
+//
+//	_ = idx.RegisterCategory("devops/containers")
+//	results, err := idx.Search("logs category=devops", 10) // includes devops/containers
+//
+// # Behavior Hints
+//
+// The readOnlyHint, destructiveHint, idempotentHint, and openWorldHint MCP
+// tool annotations are resolved with their protocol defaults at
+// registration and surfaced as Summary.Hints. "hint.<name>=<bool>" query
+// terms filter on them, so agents can exclude destructive tools at
+// retrieval time. Tools without annotations match as the defaults, which
+// count them as destructive:
+//
+//	results, err := idx.Search("branches hint.destructive=false", 10)
+//	results, err = idx.Search("list hint.readOnly=true", 10)
+//
+// # OpenAPI and Function Calling
+//
+// ImportOpenAPI registers each operation of an OpenAPI 3 document as a tool
+// backed by a provider, with parameters and the JSON request body as its
+// input schema and behavior hints derived from the HTTP method.
+// ExportOpenAITools and ExportAnthropicTools convert the catalog to
+// function-calling definitions, and map each function name (see
+// FunctionName) back to its tool ID for dispatching calls:
+//
+//	ids, err := index.ImportOpenAPI(idx, spec, index.OpenAPIOptions{
+//	    ProviderID: "petstore",
+//	    Namespace:  "pets",
+//	})
+//	tools, names, err := index.ExportOpenAITools(idx)
 //
 // # Pagination
 //
@@ -78,6 +212,97 @@
 //	})
 //	defer unsub()
 //
+// # MCP Server Refresh
+//
+// ReplaceToolsFromMCP reconciles an MCP server's tools with a fresh
+// tools/list result in one atomic step: stale tools lose the server's
+// backend, new tools are registered, and changed tools are updated. Only
+// tools that actually changed emit ChangeEvents. A tool whose MCP fields
+// changed is replaced in place when the server is its only backend; one
+// shared with other backends keeps its registration and is reported in the
+// returned error while the rest of the refresh applies. An invalid or
+// duplicate tool rejects the whole call:
+//
+//	err := idx.ReplaceToolsFromMCP("github", tools)
+//	ids := idx.MCPServerToolIDs("github")
+//
+// # Tool Versions
+//
+// Re-registering a tool with different MCP fields (description, schemas,
+// annotations) fails with ErrInvalidTool by default. With
+// IndexOptions.TrackToolVersions it records a new version instead, so schema
+// evolution of backend tools can be inspected; lookups and search use the
+// latest version, and the ChangeEvent carries ToolVersion and
+// PreviousToolVersion:
+//
+//	idx := index.NewInMemoryIndex(index.IndexOptions{TrackToolVersions: true})
+//	versions, _ := idx.ListToolVersions("github:create_issue")
+//	v1, _ := idx.GetToolVersion("github:create_issue", 1)
+//
+// # Catalog Limits
+//
+// Shared discovery services can cap what misbehaving backends register.
+// IndexOptions.MaxTools, MaxToolsPerNamespace, MaxTagsPerTool, and
+// MaxDescriptionLen reject registrations that would exceed them with a
+// *LimitError matching ErrLimitExceeded; ReplaceToolsFromMCP checks the
+// whole replacement and leaves the index unchanged. Limits only block
+// growth, and re-registering an existing tool never counts against the tool
+// caps. LimitRejections reports rejections per limit for metrics:
+//
+//	idx := index.NewInMemoryIndex(index.IndexOptions{MaxTools: 10000, MaxToolsPerNamespace: 500})
+//	var limitErr *index.LimitError
+//	if errors.As(err, &limitErr) {
+//	    log.Printf("rejected %s: %s limit %d", limitErr.ToolID, limitErr.Limit, limitErr.Max)
+//	}
+//
+// # Optional Capabilities
+//
+// Beyond the Index interface, implementations may provide Versioner,
+// Fingerprinter, TagNormalizer, Refresher, ChangeNotifier, ChangeWatcher,
+// MaintenanceScheduler, ToolVersioner, MCPReplacer, LimitReporter,
+// HealthTracker, CategoryTaxonomy, and BackendExplainer. InMemoryIndex
+// provides all thirteen. Version, Fingerprint, NormalizeTags, Refresh, and
+// OnChange call the capability when present and otherwise fall back to 0,
+// "", model.NormalizeTags, the current version, and a no-op unsubscribe, so
+// Discovery and Registry accept any Index:
+//
+//	v := index.Refresh(idx) // works for InMemoryIndex and custom indexes
+//
+// ChangeWatcher streams change events over a channel, first replaying the
+// events buffered after a given version (IndexOptions.ChangeBufferSize), so
+// a consumer that restarts can resume where it left off:
+//
+//	events, err := idx.Watch(ctx, lastVersion)
+//	if errors.Is(err, index.ErrChangesTruncated) {
+//	    // too far behind: relist tools, then watch from idx.Version()
+//	}
+//	for e := range events {
+//	    lastVersion = e.Version
+//	}
+//
+// Database-backed indexes may also implement SearchPushdown to execute
+// queries and metadata filters natively; SummariesFor turns their ranked
+// IDs into visible, Degraded-marked summaries.
+//
+// # Read-Only Views
+//
+// ReadOnly wraps an index for consumers such as plugins that should search
+// and look up tools but never change the catalog. Its registration and
+// unregistration methods fail with ErrReadOnly; reads, Version, and
+// OnChange pass through to the wrapped index:
+//
+//	plugin.Init(index.ReadOnly(idx))
+//
+// # Stored State
+//
+// Persistent and replicated indexes built on InMemoryIndex (boltindex,
+// redisindex, pgstore) write out each tool's ToolState, read with
+// GetToolState, and apply stored or remote changes with SetToolState, which
+// replaces a tool's registration in place or removes it:
+//
+//	state, _ := idx.GetToolState("github:create_issue")
+//	err := replica.SetToolState(state.Tool.ToolID(), &state)
+//
 // # Migration Note
 //
 // This package was migrated from github.com/jonwraymond/toolindex as part of
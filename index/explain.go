@@ -0,0 +1,144 @@
+package index
+
+import (
+	"fmt"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// BackendCandidate is one backend considered for a tool in a
+// BackendDecision.
+type BackendCandidate struct {
+	Backend model.ToolBackend `json:"backend"`
+	// ID is the backend identifier: the MCP server name, local handler
+	// name, or provider ID.
+	ID string `json:"id"`
+	// Priority is the backend's rank under DefaultBackendSelector, lowest
+	// first: 0 local, 1 provider, 2 mcp, 3 any other kind.
+	Priority int `json:"priority"`
+	// Health is the last reported health; State is empty when none was
+	// reported.
+	Health   HealthStatus `json:"health,omitzero"`
+	Selected bool         `json:"selected"`
+}
+
+// BackendDecision explains which backend GetTool returns for a tool.
+type BackendDecision struct {
+	ToolID string `json:"toolId"`
+	// Candidates are the tool's backends in registration order.
+	Candidates []BackendCandidate `json:"candidates"`
+	Selected   model.ToolBackend  `json:"selected"`
+	// Reason says in words why Selected was chosen.
+	Reason string `json:"reason"`
+}
+
+// BackendExplainer is an optional interface for indexes that can explain
+// their default backend choice.
+//
+// Contract:
+//   - Concurrency: ExplainBackend must be safe for concurrent use.
+//   - Consistency: Selected must be the backend GetTool returns at the same
+//     index version.
+//   - Errors: unknown tools return ErrNotFound.
+type BackendExplainer interface {
+	ExplainBackend(toolID string) (BackendDecision, error)
+}
+
+// ExplainBackend reports the backends GetTool considers for a tool, with
+// their priority and health, and why the default one was chosen. Health is
+// informational: the default selector ranks by kind only, so the reason
+// notes when a healthier backend was passed over.
+func (idx *InMemoryIndex) ExplainBackend(toolID string) (BackendDecision, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	record, ok := idx.tools[toolID]
+	if !ok || record.disabled() {
+		return BackendDecision{}, fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	selected := idx.backendSelector(record.backends)
+	selectedKey := backendIdentity(selected)
+
+	decision := BackendDecision{
+		ToolID:     toolID,
+		Candidates: make([]BackendCandidate, len(record.backends)),
+		Selected:   selected,
+	}
+	chosen := -1
+	for i, b := range record.backends {
+		candidate := BackendCandidate{
+			Backend:  b,
+			ID:       backendName(b),
+			Priority: backendPriority(b.Kind),
+			Health:   record.health[backendName(b)],
+		}
+		if chosen < 0 && backendIdentity(b) == selectedKey {
+			candidate.Selected = true
+			chosen = i
+		}
+		decision.Candidates[i] = candidate
+	}
+	decision.Reason = idx.selectionReason(decision.Candidates, chosen)
+	return decision, nil
+}
+
+// selectionReason describes why candidates[chosen] was selected; chosen is
+// -1 when the selector returned a backend the tool does not have.
+func (idx *InMemoryIndex) selectionReason(candidates []BackendCandidate, chosen int) string {
+	if chosen < 0 {
+		return "selector returned a backend not registered for the tool"
+	}
+	c := candidates[chosen]
+	var reason string
+	switch {
+	case len(candidates) == 1:
+		reason = "only registered backend"
+	case idx.customSelector:
+		reason = "chosen by the configured BackendSelector"
+	default:
+		reason = fmt.Sprintf("highest-priority kind %q (local > provider > mcp)", c.Backend.Kind)
+		for _, other := range candidates[chosen+1:] {
+			if other.Priority == c.Priority {
+				reason = fmt.Sprintf("first registered backend of highest-priority kind %q (local > provider > mcp)", c.Backend.Kind)
+				break
+			}
+		}
+	}
+	for _, other := range candidates {
+		if healthRank(other.Health.State) < healthRank(c.Health.State) {
+			reason += fmt.Sprintf("; %s is %s while %s is %s",
+				c.ID, orHealthy(c.Health.State), other.ID, orHealthy(other.Health.State))
+			if !idx.customSelector {
+				reason += " (the default selector ignores health)"
+			}
+			break
+		}
+	}
+	return reason
+}
+
+// backendPriority ranks kinds in DefaultBackendSelector order.
+func backendPriority(kind model.BackendKind) int {
+	switch kind {
+	case model.BackendKindLocal:
+		return 0
+	case model.BackendKindProvider:
+		return 1
+	case model.BackendKindMCP:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// healthRank ranks a reported state, counting no report as healthy.
+func healthRank(state HealthState) int {
+	return orHealthy(state).rank()
+}
+
+func orHealthy(state HealthState) HealthState {
+	if state == "" {
+		return HealthHealthy
+	}
+	return state
+}
@@ -0,0 +1,50 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Fingerprinter is an optional interface for reporting a digest of the
+// searchable catalog.
+//
+// Contract:
+//   - Fingerprint returns the same value for any two indexes holding the
+//     same searchable tools, however they got there, so it can key caches
+//     shared by replicas whose versions differ.
+//   - Runtime state that is not replicated, such as backend health and
+//     rollouts, is not part of the fingerprint.
+//   - Fingerprint must be safe for concurrent use.
+type Fingerprinter interface {
+	Fingerprint() string
+}
+
+// Fingerprint reports the fingerprint of idx when it implements
+// Fingerprinter, or "" when it does not.
+func Fingerprint(idx Index) string {
+	if f, ok := idx.(Fingerprinter); ok {
+		return f.Fingerprint()
+	}
+	return ""
+}
+
+// Fingerprint returns a hex SHA-256 digest of the enabled tools' search
+// documents. It is computed once per index version.
+func (idx *InMemoryIndex) Fingerprint() string {
+	docs, version := idx.snapshotSearchDocs()
+
+	idx.fingerprintMu.Lock()
+	defer idx.fingerprintMu.Unlock()
+	if idx.fingerprint != "" && idx.fingerprintVersion == version {
+		return idx.fingerprint
+	}
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, doc := range idx.filterDocsByDisabled(docs) {
+		_ = enc.Encode(doc)
+	}
+	idx.fingerprint = hex.EncodeToString(h.Sum(nil))
+	idx.fingerprintVersion = version
+	return idx.fingerprint
+}
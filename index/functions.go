@@ -0,0 +1,139 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/adapter"
+)
+
+// MaxFunctionNameLen is the longest function name the OpenAI and Anthropic
+// function-calling APIs accept.
+const MaxFunctionNameLen = 64
+
+// FunctionName converts a tool ID to a function name the OpenAI and
+// Anthropic APIs accept, which allow only letters, digits, "_", and "-":
+// the ":" separators become "__" and other characters become "_", so
+// "github:create_issue" becomes "github__create_issue". Names longer than
+// MaxFunctionNameLen are cut and suffixed with a hash of the ID.
+func FunctionName(toolID string) string {
+	var b strings.Builder
+	for _, r := range toolID {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-':
+			b.WriteRune(r)
+		case r == ':':
+			b.WriteString("__")
+		default:
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	if len(name) > MaxFunctionNameLen {
+		name = hashedFunctionName(name, toolID)
+	}
+	return name
+}
+
+// hashedFunctionName cuts name to fit a hash of toolID within
+// MaxFunctionNameLen.
+func hashedFunctionName(name, toolID string) string {
+	sum := sha256.Sum256([]byte(toolID))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	return name[:min(len(name), MaxFunctionNameLen-len(suffix))] + suffix
+}
+
+// ExportOpenAITools converts the tools registered in idx, ordered by ID, to
+// OpenAI function-calling definitions named with FunctionName, dropping
+// schema features OpenAI does not support. names maps each function name
+// back to its tool ID, for dispatching calls; when two IDs map to the same
+// name, the later one gets a hashed name.
+func ExportOpenAITools(idx Index) (tools []adapter.OpenAITool, names map[string]string, err error) {
+	openai := adapter.NewOpenAIAdapter()
+	names, err = exportTools(idx, func(ct *adapter.CanonicalTool) error {
+		out, err := openai.FromCanonical(ct)
+		if err != nil {
+			return err
+		}
+		tools = append(tools, *out.(*adapter.OpenAITool))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return tools, names, nil
+}
+
+// ExportAnthropicTools converts the tools registered in idx like
+// ExportOpenAITools, to Anthropic tool definitions.
+func ExportAnthropicTools(idx Index) (tools []adapter.AnthropicTool, names map[string]string, err error) {
+	anthropic := adapter.NewAnthropicAdapter()
+	names, err = exportTools(idx, func(ct *adapter.CanonicalTool) error {
+		out, err := anthropic.FromCanonical(ct)
+		if err != nil {
+			return err
+		}
+		tools = append(tools, *out.(*adapter.AnthropicTool))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return tools, names, nil
+}
+
+// exportTools converts each tool in idx to canonical form, renamed with its
+// function name, and passes it to emit. It returns the function names.
+func exportTools(idx Index, emit func(*adapter.CanonicalTool) error) (map[string]string, error) {
+	ids, err := registeredToolIDs(idx)
+	if err != nil {
+		return nil, err
+	}
+	mcpAdapter := adapter.NewMCPAdapter()
+	names := make(map[string]string, len(ids))
+	for _, id := range ids {
+		tool, _, err := idx.GetTool(id)
+		if errors.Is(err, ErrNotFound) {
+			continue // disabled or removed since listing
+		}
+		if err != nil {
+			return nil, err
+		}
+		ct, err := mcpAdapter.ToCanonical(tool)
+		if err != nil {
+			return nil, err
+		}
+		name := FunctionName(id)
+		if _, taken := names[name]; taken {
+			name = hashedFunctionName(name, id)
+		}
+		ct.Name = name
+		names[name] = id
+		if err := emit(ct); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// registeredToolIDs lists every tool ID in idx, sorted. Indexes without
+// ToolIDs are listed by an empty search, which omits tools hidden from
+// anonymous searches.
+func registeredToolIDs(idx Index) ([]string, error) {
+	if l, ok := idx.(interface{ ToolIDs() []string }); ok {
+		return l.ToolIDs(), nil
+	}
+	summaries, err := idx.Search("", 1<<30)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(summaries))
+	for i, s := range summaries {
+		ids[i] = s.ID
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,165 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidHealth is returned by SetBackendHealth for an unknown
+// HealthState.
+var ErrInvalidHealth = errcode.New(errcode.InvalidArgument, "invalid backend health")
+
+// HealthState is the reported health of a tool backend.
+type HealthState string
+
+const (
+	HealthHealthy     HealthState = "healthy"
+	HealthDegraded    HealthState = "degraded"
+	HealthUnreachable HealthState = "unreachable"
+)
+
+// rank orders states from best to worst.
+func (s HealthState) rank() int {
+	switch s {
+	case HealthHealthy:
+		return 0
+	case HealthDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// HealthStatus is the result of a backend health check.
+type HealthStatus struct {
+	State HealthState `json:"state"`
+	// CheckedAt is when the check ran. Zero means now.
+	CheckedAt time.Time `json:"checkedAt,omitzero"`
+	// Message describes the failure for degraded or unreachable backends.
+	Message string `json:"message,omitempty"`
+}
+
+// HealthTracker is an optional interface for indexes that track backend
+// health reported by an external checker.
+//
+// Contract:
+//   - Concurrency: methods must be safe for concurrent use.
+//   - Errors: unknown tools and backends return ErrNotFound; an unknown
+//     state returns ErrInvalidHealth.
+//   - Lifetime: health is dropped with the backend and is not persisted.
+type HealthTracker interface {
+	SetBackendHealth(toolID, backendID string, status HealthStatus) error
+	BackendHealth(toolID string) (map[string]HealthStatus, error)
+}
+
+// SetBackendHealth records the health of one of a tool's backends, named by
+// its identifier: the MCP server name, local handler name, or provider ID.
+// Search results report the tool's best backend state in Summary.Health.
+// With IndexOptions.ExcludeUnreachable, tools whose backends are all
+// unreachable are left out of search.
+func (idx *InMemoryIndex) SetBackendHealth(toolID, backendID string, status HealthStatus) error {
+	switch status.State {
+	case HealthHealthy, HealthDegraded, HealthUnreachable:
+	default:
+		return fmt.Errorf("%w: unknown state %q", ErrInvalidHealth, status.State)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	record, ok := idx.tools[toolID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	found := false
+	for _, backend := range record.backends {
+		if backendName(backend) == backendID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: backend %s for tool %s", ErrNotFound, backendID, toolID)
+	}
+	if status.CheckedAt.IsZero() {
+		status.CheckedAt = idx.now()
+	}
+
+	wasUnreachable := record.unreachable()
+	if record.health == nil {
+		record.health = make(map[string]HealthStatus)
+	}
+	record.health[backendID] = status
+	if idx.excludeUnreachable && record.unreachable() != wasUnreachable {
+		// Search results change, so bump the version for caches.
+		idx.markSearchDocsDirtyLocked()
+	}
+	return nil
+}
+
+// BackendHealth returns the reported health of a tool's backends, keyed by
+// backend identifier. Backends without a report are omitted.
+func (idx *InMemoryIndex) BackendHealth(toolID string) (map[string]HealthStatus, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.tools[toolID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, toolID)
+	}
+	out := maps.Clone(record.health)
+	if out == nil {
+		out = make(map[string]HealthStatus)
+	}
+	return out, nil
+}
+
+// bestHealth returns the state of the record's healthiest backend and the
+// latest check time. Backends without a report count as healthy; a record
+// without any reports has an empty state.
+func (record *toolRecord) bestHealth() (HealthState, time.Time) {
+	if len(record.health) == 0 {
+		return "", time.Time{}
+	}
+	best := HealthUnreachable
+	var checked time.Time
+	for _, backend := range record.backends {
+		status, ok := record.health[backendName(backend)]
+		if !ok {
+			status.State = HealthHealthy
+		}
+		if status.State.rank() < best.rank() {
+			best = status.State
+		}
+		if status.CheckedAt.After(checked) {
+			checked = status.CheckedAt
+		}
+	}
+	return best, checked
+}
+
+// unreachable reports whether every backend of the record is reported
+// unreachable.
+func (record *toolRecord) unreachable() bool {
+	state, _ := record.bestHealth()
+	return state == HealthUnreachable
+}
+
+// filterDocsByHealth drops docs whose backends are all unreachable when
+// IndexOptions.ExcludeUnreachable is set.
+func (idx *InMemoryIndex) filterDocsByHealth(docs []SearchDoc) []SearchDoc {
+	if !idx.excludeUnreachable {
+		return docs
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	out := make([]SearchDoc, 0, len(docs))
+	for _, doc := range docs {
+		if record, ok := idx.tools[doc.ID]; ok && record.unreachable() {
+			continue
+		}
+		out = append(out, doc)
+	}
+	return out
+}
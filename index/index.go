@@ -4,16 +4,20 @@
 package index
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/tooldiscovery/errcode"
 	"github.com/jonwraymond/toolfoundation/model"
 )
 
@@ -22,11 +26,11 @@ const MaxShortDescriptionLen = 120
 
 // Error values for consistent error handling by callers.
 var (
-	ErrNotFound                 = errors.New("tool not found")
-	ErrInvalidTool              = errors.New("invalid tool")
-	ErrInvalidBackend           = errors.New("invalid backend")
-	ErrInvalidCursor            = errors.New("invalid cursor")
-	ErrNonDeterministicSearcher = errors.New("searcher is non-deterministic")
+	ErrNotFound                 = errcode.New(errcode.ToolNotFound, "tool not found")
+	ErrInvalidTool              = errcode.New(errcode.InvalidTool, "invalid tool")
+	ErrInvalidBackend           = errcode.New(errcode.InvalidBackend, "invalid backend")
+	ErrInvalidCursor            = errcode.New(errcode.InvalidCursor, "invalid cursor")
+	ErrNonDeterministicSearcher = errcode.New(errcode.Unsupported, "searcher is non-deterministic")
 )
 
 // Summary represents a lightweight view of a tool for search results.
@@ -43,6 +47,19 @@ type Summary struct {
 	OutputModes      []string `json:"outputModes,omitempty"`
 	SecuritySummary  string   `json:"securitySummary,omitempty"`
 	Tags             []string `json:"tags,omitempty"`
+	// Hints are the tool's MCP annotation hints with defaults applied, set
+	// when the tool declares annotations (see HintsFromAnnotations).
+	Hints *ToolHints `json:"hints,omitempty"`
+	// Metadata holds custom organization labels set at registration.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Degraded is set while a maintenance window affects the tool.
+	Degraded       bool   `json:"degraded,omitempty"`
+	DegradedReason string `json:"degradedReason,omitempty"`
+	// Health is the state of the tool's healthiest backend, and
+	// HealthCheckedAt its latest check, once any backend health has been
+	// reported (see InMemoryIndex.SetBackendHealth).
+	Health          HealthState `json:"health,omitempty"`
+	HealthCheckedAt time.Time   `json:"healthCheckedAt,omitzero"`
 }
 
 // SearchDoc is the internal/exported struct used by Searcher implementations.
@@ -89,6 +106,8 @@ type Index interface {
 type ToolRegistration struct {
 	Tool    model.Tool
 	Backend model.ToolBackend
+	// Metadata, when non-nil, replaces the tool's custom metadata.
+	Metadata map[string]string
 }
 
 // BackendSelector is a function that selects the default backend from a list.
@@ -127,6 +146,8 @@ const (
 	ChangeBackendRemoved ChangeType = "backend_removed"
 	ChangeToolRemoved    ChangeType = "tool_removed"
 	ChangeRefreshed      ChangeType = "refreshed"
+	ChangeDisabled       ChangeType = "disabled"
+	ChangeEnabled        ChangeType = "enabled"
 )
 
 // ChangeEvent captures a mutation in the index for reactive integration.
@@ -135,6 +156,14 @@ type ChangeEvent struct {
 	ToolID  string
 	Backend model.ToolBackend
 	Version uint64
+
+	// ToolVersion is the tool's definition version after a registration
+	// (see ToolVersion). It is zero for other events.
+	ToolVersion int
+
+	// PreviousToolVersion is set when a re-registration created a new tool
+	// version, to the version it replaced.
+	PreviousToolVersion int
 }
 
 // ChangeListener receives change events from an Index implementation.
@@ -150,6 +179,18 @@ type ChangeNotifier interface {
 	OnChange(listener ChangeListener) (unsubscribe func())
 }
 
+// MCPReplacer is an optional interface for replacing the tools of an MCP
+// server in one step.
+//
+// Contract:
+//   - Atomicity: ReplaceToolsFromMCP either applies the whole replacement or,
+//     on error, leaves the index unchanged.
+//   - Events: only tools that were added, changed, or removed emit change
+//     events.
+type MCPReplacer interface {
+	ReplaceToolsFromMCP(serverName string, tools []model.Tool) error
+}
+
 // Refresher is an optional interface for forcing a refresh of cached search docs.
 //
 // Contract:
@@ -159,6 +200,15 @@ type Refresher interface {
 	Refresh() uint64
 }
 
+// Versioner is an optional interface for reporting the index version.
+//
+// Contract:
+// - Version returns a value that increases on every mutation and never decreases.
+// - Version must be safe for concurrent use.
+type Versioner interface {
+	Version() uint64
+}
+
 // IndexOptions configures the behavior of an Index implementation.
 type IndexOptions struct {
 	BackendSelector BackendSelector
@@ -167,6 +217,50 @@ type IndexOptions struct {
 	// When true, SearchPage returns ErrNonDeterministicSearcher if the configured
 	// searcher does not declare deterministic ordering.
 	RequireDeterministicSearcher *bool
+	// Now returns the current time for maintenance window checks and tool
+	// version timestamps.
+	// Default: time.Now.
+	Now func() time.Time
+
+	// TrackToolVersions makes re-registering a tool with changed MCP fields
+	// record a new tool version (see ListToolVersions) instead of failing
+	// with ErrInvalidTool, so schema evolution of backend tools is kept.
+	// Lookups and search always use the latest version. History is held in
+	// memory and dropped when the tool is removed.
+	TrackToolVersions bool
+
+	// MaxToolVersions caps the versions kept per tool; the oldest are
+	// dropped first. 0 = unlimited.
+	MaxToolVersions int
+
+	// ChangeBufferSize is the number of recent change events kept for
+	// Watch replay.
+	// Default: DefaultChangeBufferSize
+	ChangeBufferSize int
+
+	// MaxTools caps the number of registered tools. 0 = unlimited.
+	MaxTools int
+
+	// MaxToolsPerNamespace caps the number of tools in each namespace.
+	// 0 = unlimited.
+	MaxToolsPerNamespace int
+
+	// TagPolicy configures tag normalization. The zero value matches
+	// model.NormalizeTags.
+	TagPolicy TagPolicy
+
+	// MaxTagsPerTool caps the number of tags on a tool, counted as
+	// registered, before normalization. 0 = unlimited.
+	MaxTagsPerTool int
+
+	// MaxDescriptionLen caps the length in bytes of a tool description.
+	// 0 = unlimited.
+	MaxDescriptionLen int
+
+	// ExcludeUnreachable leaves tools whose backends are all reported
+	// unreachable (see InMemoryIndex.SetBackendHealth) out of search, so
+	// agents do not select tools that cannot run. Lookups still find them.
+	ExcludeUnreachable bool
 }
 
 // toolRecord holds all data for a single registered tool.
@@ -177,18 +271,26 @@ type toolRecord struct {
 	normalizedTags []string       // normalized tags for search
 	docText        string         // cached search doc text
 	summary        Summary        // cached summary
+	metadata       map[string]string
+	versions       []ToolVersion           // oldest first; the last is current
+	health         map[string]HealthStatus // by backend identifier
+	disabledAt     time.Time               // zero unless hidden by DisableTool
 }
 
 // InMemoryIndex is the default in-memory implementation of Index.
 type InMemoryIndex struct {
 	mu              sync.RWMutex
 	tools           map[string]*toolRecord // keyed by tool ID
+	canonicalIDs    map[string]string      // CanonicalToolID to tool ID
 	namespaces      map[string]struct{}    // set of namespaces
 	namespaceCounts map[string]int         // number of tools per namespace
 	backendSelector BackendSelector
+	customSelector  bool // backendSelector is not DefaultBackendSelector
 	searcher        Searcher
 	listeners       []listenerEntry
 	nextListenerID  uint64
+	changes         changeRing // recent events for Watch replay
+	watchers        map[uint64]*changeWatcher
 
 	// Search doc cache
 	searchDocs        []SearchDoc
@@ -197,7 +299,32 @@ type InMemoryIndex struct {
 	indexVersion      uint64
 	searchDocsBuilds  int // for test visibility
 
+	// Fingerprint cache, guarded by fingerprintMu
+	fingerprintMu      sync.Mutex
+	fingerprint        string
+	fingerprintVersion uint64
+
 	requireDeterministicSearcher bool
+
+	maintenance map[string]MaintenanceWindow
+	rollouts    map[string]Rollout
+	now         func() time.Time
+
+	trackToolVersions bool
+	maxToolVersions   int
+
+	maxTools             int
+	maxToolsPerNamespace int
+	maxTagsPerTool       int
+	tagPolicy            tagNormalizer
+	maxDescriptionLen    int
+	rejections           limitCounter
+
+	excludeUnreachable bool
+
+	categories map[string]struct{} // registered category paths
+
+	disabled int // number of tools hidden by DisableTool
 }
 
 type listenerEntry struct {
@@ -209,17 +336,22 @@ type listenerEntry struct {
 func NewInMemoryIndex(opts ...IndexOptions) *InMemoryIndex {
 	idx := &InMemoryIndex{
 		tools:                        make(map[string]*toolRecord),
+		canonicalIDs:                 make(map[string]string),
 		namespaces:                   make(map[string]struct{}),
 		namespaceCounts:              make(map[string]int),
 		backendSelector:              DefaultBackendSelector,
 		searcher:                     &lexicalSearcher{},
 		requireDeterministicSearcher: true,
+		now:                          time.Now,
+		tagPolicy:                    TagPolicy{}.normalizer(),
 	}
 
+	var bufferSize int
 	if len(opts) > 0 {
 		opt := opts[0]
 		if opt.BackendSelector != nil {
 			idx.backendSelector = opt.BackendSelector
+			idx.customSelector = true
 		}
 		if opt.Searcher != nil {
 			idx.searcher = opt.Searcher
@@ -227,7 +359,23 @@ func NewInMemoryIndex(opts ...IndexOptions) *InMemoryIndex {
 		if opt.RequireDeterministicSearcher != nil {
 			idx.requireDeterministicSearcher = *opt.RequireDeterministicSearcher
 		}
+		if opt.Now != nil {
+			idx.now = opt.Now
+		}
+		idx.trackToolVersions = opt.TrackToolVersions
+		idx.maxToolVersions = opt.MaxToolVersions
+		idx.maxTools = opt.MaxTools
+		idx.maxToolsPerNamespace = opt.MaxToolsPerNamespace
+		idx.maxTagsPerTool = opt.MaxTagsPerTool
+		idx.tagPolicy = opt.TagPolicy.normalizer()
+		idx.maxDescriptionLen = opt.MaxDescriptionLen
+		idx.excludeUnreachable = opt.ExcludeUnreachable
+		bufferSize = opt.ChangeBufferSize
 	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultChangeBufferSize
+	}
+	idx.changes.events = make([]ChangeEvent, bufferSize)
 
 	return idx
 }
@@ -266,10 +414,12 @@ func (idx *InMemoryIndex) Refresh() uint64 {
 	idx.markSearchDocsDirtyLocked()
 	idx.rebuildSearchDocsLocked()
 	version := idx.indexVersion
+	event := ChangeEvent{Type: ChangeRefreshed, Version: version}
+	idx.recordChangeLocked(event)
 	listeners := idx.snapshotListenersLocked()
 	idx.mu.Unlock()
 
-	notifyListeners(listeners, ChangeEvent{Type: ChangeRefreshed, Version: version})
+	notifyListeners(listeners, event)
 	return version
 }
 
@@ -326,6 +476,27 @@ func backendIdentity(backend model.ToolBackend) string {
 	return ""
 }
 
+// BackendID returns the backendID UnregisterBackend expects for backend:
+// the server name of an MCP backend, "providerID:toolID" for a provider
+// backend, and the handler name of a local backend.
+func BackendID(backend model.ToolBackend) string {
+	switch backend.Kind {
+	case model.BackendKindMCP:
+		if backend.MCP != nil {
+			return backend.MCP.ServerName
+		}
+	case model.BackendKindProvider:
+		if backend.Provider != nil {
+			return backend.Provider.ProviderID + ":" + backend.Provider.ToolID
+		}
+	case model.BackendKindLocal:
+		if backend.Local != nil {
+			return backend.Local.Name
+		}
+	}
+	return ""
+}
+
 // encodeIdentity builds an unambiguous identity string using length-prefixed parts.
 // This prevents collisions when fields include separators like ":".
 func encodeIdentity(parts ...string) string {
@@ -341,6 +512,17 @@ func encodeIdentity(parts ...string) string {
 	return b.String()
 }
 
+// validateTool checks the model invariants of tool and its ID rules.
+func validateTool(tool model.Tool) error {
+	if err := tool.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTool, err)
+	}
+	if err := ToolIDOf(tool).Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTool, err)
+	}
+	return nil
+}
+
 // validateBackend checks if a backend is valid.
 func validateBackend(backend model.ToolBackend) error {
 	switch backend.Kind {
@@ -452,7 +634,7 @@ func jsonEqual(a, b any) bool {
 	case map[string]any:
 		bv, ok := b.(map[string]any)
 		if !ok {
-			return false
+			return jsonEqualCanonical(a, b)
 		}
 		if len(av) != len(bv) {
 			return false
@@ -467,7 +649,7 @@ func jsonEqual(a, b any) bool {
 	case []any:
 		bv, ok := b.([]any)
 		if !ok {
-			return false
+			return jsonEqualCanonical(a, b)
 		}
 		if len(av) != len(bv) {
 			return false
@@ -479,27 +661,42 @@ func jsonEqual(a, b any) bool {
 		}
 		return true
 	case string:
-		bv, ok := b.(string)
-		return ok && av == bv
+		if bv, ok := b.(string); ok {
+			return av == bv
+		}
 	case float64:
-		bv, ok := b.(float64)
-		return ok && av == bv
+		if bv, ok := b.(float64); ok {
+			return av == bv
+		}
 	case bool:
-		bv, ok := b.(bool)
-		return ok && av == bv
-	case int:
-		// Handle int (Go may use int in some contexts)
-		switch bv := b.(type) {
-		case int:
+		if bv, ok := b.(bool); ok {
 			return av == bv
-		case float64:
-			return float64(av) == bv
 		}
-		return false
-	default:
-		// Fallback: direct comparison
-		return a == b
 	}
+	// Mixed or non-JSON-native types (int vs float64, []string vs []any,
+	// typed structs): compare their canonical JSON encodings.
+	return jsonEqualCanonical(a, b)
+}
+
+// jsonEqualCanonical compares two values by their canonical JSON encoding:
+// each is encoded, decoded to generic JSON values, and re-encoded, which
+// sorts object keys and unifies numeric and slice types.
+func jsonEqualCanonical(a, b any) bool {
+	ca, errA := canonicalJSON(a)
+	cb, errB := canonicalJSON(b)
+	return errA == nil && errB == nil && bytes.Equal(ca, cb)
+}
+
+func canonicalJSON(v any) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
 }
 
 // jsonEqualBytes compares a byte slice (JSON) against another value.
@@ -645,41 +842,90 @@ func (idx *InMemoryIndex) removeNamespaceLocked(namespace string) {
 }
 
 // RegisterTool registers a single tool with its backend.
+// Existing custom metadata is preserved on re-registration.
 func (idx *InMemoryIndex) RegisterTool(tool model.Tool, backend model.ToolBackend) error {
-	// Validate tool
-	if err := tool.Validate(); err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidTool, err)
+	return idx.registerTool(tool, backend, nil)
+}
+
+// registerTool registers a tool; a non-nil metadata map replaces existing metadata.
+func (idx *InMemoryIndex) registerTool(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error {
+	if err := validateTool(tool); err != nil {
+		return err
 	}
 
 	// Validate backend
 	if err := validateBackend(backend); err != nil {
 		return err
 	}
+	if err := idx.checkToolLimits(tool); err != nil {
+		return idx.rejections.reject(err)
+	}
 
+	idx.mu.Lock()
+	event, err := idx.registerToolLocked(tool, backend, metadata, false)
+	if err != nil {
+		idx.mu.Unlock()
+		return idx.rejections.reject(err)
+	}
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, event)
+	return nil
+}
+
+// registerToolLocked applies a validated registration and records its
+// change event. Must be called with idx.mu held.
+func (idx *InMemoryIndex) registerToolLocked(tool model.Tool, backend model.ToolBackend, metadata map[string]string, replace bool) (ChangeEvent, error) {
 	toolID := tool.ToolID()
 	backendKey := backendIdentity(backend)
-	normalizedTags := model.NormalizeTags(tool.Tags)
-
-	idx.mu.Lock()
+	normalizedTags := idx.tagPolicy.normalize(tool.Tags)
 
 	record, exists := idx.tools[toolID]
 	changeType := ChangeRegistered
+	previousToolVersion := 0
+	newToolVersion := false
+	namespace := ""
+	if exists {
+		namespace = record.tool.Namespace
+	}
+	if err := idx.checkGrowthLocked(tool, exists, namespace); err != nil {
+		return ChangeEvent{}, err
+	}
 	if !exists {
+		if err := idx.checkCaseConflictLocked(toolID); err != nil {
+			return ChangeEvent{}, err
+		}
 		record = &toolRecord{
 			tool:           tool,
 			backends:       []model.ToolBackend{backend},
 			backendKeys:    map[string]int{backendKey: 0},
 			normalizedTags: normalizedTags,
+			metadata:       metadata,
 		}
 		refreshRecordDerived(record)
+		idx.addToolVersionLocked(record, tool)
+		newToolVersion = true
 		idx.tools[toolID] = record
+		idx.canonicalIDs[CanonicalToolID(toolID)] = toolID
 		idx.addNamespaceLocked(tool.Namespace)
 	} else {
 		changeType = ChangeUpdated
-		// Check MCP field consistency: new tool's MCP fields must match existing
-		if !toolMCPFieldsEqual(record.tool, tool) {
-			idx.mu.Unlock()
-			return fmt.Errorf("%w: tool %q MCP fields differ from existing registration", ErrInvalidTool, toolID)
+		// Check MCP field consistency: new tool's MCP fields must match
+		// existing, unless changes are recorded as new tool versions or the
+		// backend replaces a tool only it serves.
+		switch {
+		case toolMCPFieldsEqual(record.tool, tool):
+			// Extensions such as Tags may change without a new version.
+			record.versions[len(record.versions)-1].Tool = tool
+		case idx.trackToolVersions:
+			previousToolVersion = record.currentToolVersion()
+			idx.addToolVersionLocked(record, tool)
+			newToolVersion = true
+		case replace && soleBackend(record, backendKey):
+			record.versions[len(record.versions)-1].Tool = tool
+		default:
+			return ChangeEvent{}, fmt.Errorf("%w: tool %q MCP fields differ from existing registration", ErrInvalidTool, toolID)
 		}
 
 		// Track namespace changes if tool is re-registered under a new namespace.
@@ -691,6 +937,9 @@ func (idx *InMemoryIndex) RegisterTool(tool model.Tool, backend model.ToolBacken
 		// Update toolmodel extensions (Tags) - these are allowed to differ
 		record.tool = tool
 		record.normalizedTags = normalizedTags
+		if metadata != nil {
+			record.metadata = metadata
+		}
 		refreshRecordDerived(record)
 
 		// Check if backend already exists
@@ -706,22 +955,31 @@ func (idx *InMemoryIndex) RegisterTool(tool model.Tool, backend model.ToolBacken
 
 	idx.markSearchDocsDirtyLocked()
 	version := idx.indexVersion
-	listeners := idx.snapshotListenersLocked()
-	idx.mu.Unlock()
-
-	notifyListeners(listeners, ChangeEvent{
-		Type:    changeType,
-		ToolID:  toolID,
-		Backend: backend,
-		Version: version,
-	})
-	return nil
+	if newToolVersion {
+		record.versions[len(record.versions)-1].IndexVersion = version
+	}
+	event := ChangeEvent{
+		Type:                changeType,
+		ToolID:              toolID,
+		Backend:             backend,
+		Version:             version,
+		ToolVersion:         record.currentToolVersion(),
+		PreviousToolVersion: previousToolVersion,
+	}
+	idx.recordChangeLocked(event)
+	return event, nil
 }
 
 // RegisterTools registers multiple tools in batch.
 func (idx *InMemoryIndex) RegisterTools(regs []ToolRegistration) error {
 	for _, reg := range regs {
-		if err := idx.RegisterTool(reg.Tool, reg.Backend); err != nil {
+		var err error
+		if reg.Metadata != nil {
+			err = idx.RegisterToolWithMetadata(reg.Tool, reg.Backend, reg.Metadata)
+		} else {
+			err = idx.RegisterTool(reg.Tool, reg.Backend)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -743,27 +1001,188 @@ func (idx *InMemoryIndex) RegisterToolsFromMCP(serverName string, tools []model.
 	return nil
 }
 
+// ReplaceToolsFromMCP atomically replaces the tools served by an MCP
+// server with tools, as after a tools/list refresh. Tools no longer listed
+// lose the server's backend (and are removed if it was their last), new
+// tools are registered, and changed tools are updated. Unchanged tools emit
+// no events, so a refresh emits one ChangeEvent per tool that actually
+// changed. Concurrent readers see the old or the new tool set, never a mix.
+//
+// A tool whose MCP fields changed is updated in place when the server is
+// its only backend, or recorded as a new tool version with
+// IndexOptions.TrackToolVersions. Without version tracking, a changed tool
+// that other backends also serve keeps its existing registration; the rest
+// of the replacement is applied and an error wrapping ErrInvalidTool is
+// returned for each such tool.
+//
+// The replacement is otherwise validated up front: an invalid or duplicate
+// tool rejects the whole call with ErrInvalidTool and leaves the index
+// unchanged. A replacement that would exceed a catalog limit is likewise
+// rejected with a *LimitError.
+func (idx *InMemoryIndex) ReplaceToolsFromMCP(serverName string, tools []model.Tool) error {
+	backend := model.ToolBackend{
+		Kind: model.BackendKindMCP,
+		MCP:  &model.MCPBackend{ServerName: serverName},
+	}
+	if err := validateBackend(backend); err != nil {
+		return err
+	}
+	backendKey := backendIdentity(backend)
+
+	incoming := make(map[string]model.Tool, len(tools))
+	for _, tool := range tools {
+		if err := validateTool(tool); err != nil {
+			return err
+		}
+		if err := idx.checkToolLimits(tool); err != nil {
+			return idx.rejections.reject(err)
+		}
+		id := tool.ToolID()
+		if _, dup := incoming[id]; dup {
+			return fmt.Errorf("%w: duplicate tool %q", ErrInvalidTool, id)
+		}
+		incoming[id] = tool
+	}
+
+	idx.mu.Lock()
+	folded := make(map[string]string, len(incoming))
+	for id := range incoming {
+		if _, ok := idx.tools[id]; ok {
+			continue
+		}
+		if err := idx.checkCaseConflictLocked(id); err != nil {
+			idx.mu.Unlock()
+			return err
+		}
+		if other, ok := folded[CanonicalToolID(id)]; ok {
+			idx.mu.Unlock()
+			return caseConflictError(id, other)
+		}
+		folded[CanonicalToolID(id)] = id
+	}
+
+	var stale, removed []string
+	for id, record := range idx.tools {
+		if _, keep := incoming[id]; !keep {
+			if _, ok := record.backendKeys[backendKey]; ok {
+				stale = append(stale, id)
+				if len(record.backends) == 1 {
+					removed = append(removed, id)
+				}
+			}
+		}
+	}
+	// A changed tool that other backends also serve keeps its registration;
+	// the rest of the replacement still applies.
+	var conflicts []error
+	ids := make([]string, 0, len(incoming))
+	for id, tool := range incoming {
+		if record, ok := idx.tools[id]; ok && !idx.trackToolVersions &&
+			!toolMCPFieldsEqual(record.tool, tool) && !soleBackend(record, backendKey) {
+			conflicts = append(conflicts, fmt.Errorf("%w: tool %q MCP fields differ from registration on other backends", ErrInvalidTool, id))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if err := idx.checkReplaceLimitsLocked(incoming, ids, removed); err != nil {
+		idx.mu.Unlock()
+		return idx.rejections.reject(err)
+	}
+
+	var events []ChangeEvent
+	sort.Strings(stale)
+	for _, id := range stale {
+		events = append(events, idx.removeBackendLocked(id, idx.tools[id], backendKey))
+	}
+
+	for _, id := range ids {
+		tool := incoming[id]
+		if record, ok := idx.tools[id]; ok && registrationUnchanged(record, tool, backendKey) {
+			continue
+		}
+		// Conflicts were set aside above, so registration cannot fail.
+		event, _ := idx.registerToolLocked(tool, backend, nil, true)
+		events = append(events, event)
+	}
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	for _, event := range events {
+		notifyListeners(listeners, event)
+	}
+	slices.SortFunc(conflicts, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(conflicts...)
+}
+
+// soleBackend reports whether backendKey is the only backend of record.
+func soleBackend(record *toolRecord, backendKey string) bool {
+	_, ok := record.backendKeys[backendKey]
+	return ok && len(record.backends) == 1
+}
+
+// MCPServerToolIDs returns the sorted IDs of the tools with a backend on
+// the named MCP server.
+func (idx *InMemoryIndex) MCPServerToolIDs(serverName string) []string {
+	key := encodeIdentity(string(model.BackendKindMCP), serverName)
+	idx.mu.RLock()
+	var ids []string
+	for id, record := range idx.tools {
+		if _, ok := record.backendKeys[key]; ok {
+			ids = append(ids, id)
+		}
+	}
+	idx.mu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
+// checkCaseConflictLocked rejects a new tool ID that differs from a
+// registered one only by case. Must be called with idx.mu held.
+func (idx *InMemoryIndex) checkCaseConflictLocked(toolID string) error {
+	if other, ok := idx.canonicalIDs[CanonicalToolID(toolID)]; ok && other != toolID {
+		return caseConflictError(toolID, other)
+	}
+	return nil
+}
+
+func caseConflictError(toolID, other string) error {
+	return fmt.Errorf("%w: %w", ErrInvalidTool, &ToolIDError{
+		ID:     toolID,
+		Reason: fmt.Sprintf("differs only by case from tool %q", other),
+	})
+}
+
+// registrationUnchanged reports whether re-registering tool with the
+// backend identified by backendKey would leave record as it is.
+func registrationUnchanged(record *toolRecord, tool model.Tool, backendKey string) bool {
+	if _, ok := record.backendKeys[backendKey]; !ok {
+		return false
+	}
+	return toolMCPFieldsEqual(record.tool, tool) &&
+		record.tool.Namespace == tool.Namespace &&
+		record.tool.Version == tool.Version &&
+		slices.Equal(record.tool.Tags, tool.Tags)
+}
+
 // UnregisterBackend removes a specific backend from a tool.
 // If the last backend is removed, the tool is also removed.
 //
 // For provider backends, backendID must be in the format "providerID:toolID".
+// Either part may itself contain colons; every split is tried, and a
+// backendID matching more than one registered backend is rejected with
+// ErrInvalidBackend.
 // For MCP backends, backendID is the server name.
 // For local backends, backendID is the handler name.
 func (idx *InMemoryIndex) UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error {
-	var providerID string
-	var providerToolID string
-
 	// Validate backendID format for provider backends
 	if kind == model.BackendKindProvider {
 		if !strings.Contains(backendID, ":") {
 			return fmt.Errorf("%w: provider backendID must be in format 'providerID:toolID'", ErrInvalidBackend)
 		}
-		parts := strings.SplitN(backendID, ":", 2)
-		if parts[0] == "" || parts[1] == "" {
+		if strings.HasPrefix(backendID, ":") || strings.HasSuffix(backendID, ":") {
 			return fmt.Errorf("%w: provider backendID must have non-empty providerID and toolID", ErrInvalidBackend)
 		}
-		providerID = parts[0]
-		providerToolID = parts[1]
 	}
 
 	idx.mu.Lock()
@@ -780,59 +1199,97 @@ func (idx *InMemoryIndex) UnregisterBackend(toolID string, kind model.BackendKin
 	case model.BackendKindMCP:
 		searchKey = encodeIdentity(string(kind), backendID)
 	case model.BackendKindProvider:
-		searchKey = encodeIdentity(string(kind), providerID, providerToolID)
+		keys := providerBackendKeys(record, backendID)
+		if len(keys) > 1 {
+			idx.mu.Unlock()
+			return fmt.Errorf("%w: provider backendID %q matches %d backends", ErrInvalidBackend, backendID, len(keys))
+		}
+		if len(keys) == 1 {
+			searchKey = keys[0]
+		}
 	case model.BackendKindLocal:
 		searchKey = encodeIdentity(string(kind), backendID)
 	}
 
-	// Find and remove the backend
-	foundIdx := -1
-	for key, idx := range record.backendKeys {
-		if key == searchKey {
-			foundIdx = idx
-			delete(record.backendKeys, key)
-			break
-		}
-	}
-
-	if foundIdx == -1 {
+	if _, ok := record.backendKeys[searchKey]; !ok {
 		idx.mu.Unlock()
 		return fmt.Errorf("%w: backend not found", ErrNotFound)
 	}
+	event := idx.removeBackendLocked(toolID, record, searchKey)
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	notifyListeners(listeners, event)
+	return nil
+}
+
+// providerBackendKeys returns the identity keys of record's provider
+// backends matching backendID split at any colon into providerID and
+// toolID.
+func providerBackendKeys(record *toolRecord, backendID string) []string {
+	var keys []string
+	for i := range len(backendID) {
+		if backendID[i] != ':' || i == 0 || i == len(backendID)-1 {
+			continue
+		}
+		key := encodeIdentity(string(model.BackendKindProvider), backendID[:i], backendID[i+1:])
+		if _, ok := record.backendKeys[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// removeBackendLocked removes the backend with identity key from record,
+// removing the tool when no backends remain, and records the change event.
+// Must be called with idx.mu held and key present in record.backendKeys.
+func (idx *InMemoryIndex) removeBackendLocked(toolID string, record *toolRecord, key string) ChangeEvent {
+	foundIdx := record.backendKeys[key]
+	delete(record.backendKeys, key)
 
 	removedBackend := record.backends[foundIdx]
 
 	// Remove from slice
 	record.backends = append(record.backends[:foundIdx], record.backends[foundIdx+1:]...)
+	name := backendName(removedBackend)
+	if !slices.ContainsFunc(record.backends, func(b model.ToolBackend) bool { return backendName(b) == name }) {
+		delete(record.health, name)
+	}
 
 	// Update indices in backendKeys for backends after the removed one
-	for key, idx := range record.backendKeys {
-		if idx > foundIdx {
-			record.backendKeys[key] = idx - 1
+	for k, i := range record.backendKeys {
+		if i > foundIdx {
+			record.backendKeys[k] = i - 1
 		}
 	}
 
 	// If no backends left, remove the tool entirely
 	changeType := ChangeBackendRemoved
 	if len(record.backends) == 0 {
-		namespace := record.tool.Namespace
-		delete(idx.tools, toolID)
-		idx.removeNamespaceLocked(namespace)
+		idx.deleteToolLocked(toolID, record)
 		changeType = ChangeToolRemoved
 	}
 
 	idx.markSearchDocsDirtyLocked()
-	version := idx.indexVersion
-	listeners := idx.snapshotListenersLocked()
-	idx.mu.Unlock()
-
-	notifyListeners(listeners, ChangeEvent{
+	event := ChangeEvent{
 		Type:    changeType,
 		ToolID:  toolID,
 		Backend: removedBackend,
-		Version: version,
-	})
-	return nil
+		Version: idx.indexVersion,
+	}
+	idx.recordChangeLocked(event)
+	return event
+}
+
+// deleteToolLocked removes record, the registration of toolID, from the
+// index. Must be called with idx.mu held.
+func (idx *InMemoryIndex) deleteToolLocked(toolID string, record *toolRecord) {
+	if record.disabled() {
+		idx.disabled--
+	}
+	delete(idx.tools, toolID)
+	delete(idx.canonicalIDs, CanonicalToolID(toolID))
+	idx.removeNamespaceLocked(record.tool.Namespace)
 }
 
 // GetTool returns the full tool and its default backend.
@@ -841,7 +1298,7 @@ func (idx *InMemoryIndex) GetTool(id string) (model.Tool, model.ToolBackend, err
 	defer idx.mu.RUnlock()
 
 	record, exists := idx.tools[id]
-	if !exists {
+	if !exists || record.disabled() {
 		return model.Tool{}, model.ToolBackend{}, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
 
@@ -849,13 +1306,26 @@ func (idx *InMemoryIndex) GetTool(id string) (model.Tool, model.ToolBackend, err
 	return record.tool, defaultBackend, nil
 }
 
+// ToolIDs returns the IDs of every registered tool, sorted, including tools
+// hidden from search by rollouts or DisableTool.
+func (idx *InMemoryIndex) ToolIDs() []string {
+	idx.mu.RLock()
+	ids := make([]string, 0, len(idx.tools))
+	for id := range idx.tools {
+		ids = append(ids, id)
+	}
+	idx.mu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
 // GetAllBackends returns all backends for a tool.
 func (idx *InMemoryIndex) GetAllBackends(id string) ([]model.ToolBackend, error) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
 
 	record, exists := idx.tools[id]
-	if !exists {
+	if !exists || record.disabled() {
 		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
 	}
 
@@ -866,25 +1336,81 @@ func (idx *InMemoryIndex) GetAllBackends(id string) ([]model.ToolBackend, error)
 }
 
 // Search performs a search over the indexed tools.
+// Query terms of the form "metadata.<key>=<value>" filter by custom metadata,
+// a "category=<path>" term restricts results to a category subtree, and
+// "hint.<name>=<bool>" terms filter by MCP annotation hints.
+// Tools under a partial rollout are omitted; use SearchFor to search as a
+// principal.
 func (idx *InMemoryIndex) Search(query string, limit int) ([]Summary, error) {
+	return idx.search("", query, limit)
+}
+
+func (idx *InMemoryIndex) search(principal, query string, limit int) ([]Summary, error) {
 	docs, _ := idx.snapshotSearchDocs()
-	return idx.searcher.Search(query, limit, docs)
+	docs = idx.filterDocsByHealth(idx.filterDocsByRollout(idx.filterDocsByDisabled(docs), principal))
+	results, err := idx.searchFiltered(query, limit, docs)
+	if err != nil {
+		return nil, err
+	}
+	return idx.markDegraded(results), nil
+}
+
+// searchFiltered ranks query over docs and applies its metadata, category,
+// and hint filter terms to the ranked hits, returning at most limit. The
+// searcher always sees the whole visible document set, so a caching
+// searcher such as search.BM25Searcher keeps one index instead of
+// rebuilding for every distinct filter.
+func (idx *InMemoryIndex) searchFiltered(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	return filterRanked(query, limit, docs, idx.searcher.Search, func(s Summary) Summary { return s })
+}
+
+// filterRanked implements searchFiltered for a rank function returning
+// results of type T, whose summaries summary returns.
+func filterRanked[T any](query string, limit int, docs []SearchDoc, rank func(string, int, []SearchDoc) ([]T, error), summary func(T) Summary) ([]T, error) {
+	query, filters := ParseMetadataFilters(query)
+	query, category := ParseCategoryFilter(query)
+	query, hints := ParseHintFilters(query)
+	if len(filters) == 0 && category == "" && len(hints) == 0 {
+		return rank(query, limit, docs)
+	}
+	results, err := rank(query, len(docs), docs)
+	if err != nil {
+		return nil, err
+	}
+	out := results[:0]
+	for _, r := range results {
+		if len(out) == limit {
+			break
+		}
+		s := summary(r)
+		if MatchesMetadata(s.Metadata, filters) &&
+			(category == "" || InCategory(s.Category, category)) &&
+			MatchesHints(s.Hints, hints) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
 }
 
 // SearchPage performs a search over the indexed tools with cursor pagination.
 func (idx *InMemoryIndex) SearchPage(query string, limit int, cursor string) ([]Summary, string, error) {
+	return idx.searchPage("", query, limit, cursor)
+}
+
+func (idx *InMemoryIndex) searchPage(principal, query string, limit int, cursor string) ([]Summary, string, error) {
 	if limit <= 0 {
 		return nil, "", fmt.Errorf("limit must be positive")
 	}
 
 	docs, version := idx.snapshotSearchDocs()
+	docs = idx.filterDocsByHealth(idx.filterDocsByRollout(idx.filterDocsByDisabled(docs), principal))
 
 	if idx.requireDeterministicSearcher {
 		if ds, ok := idx.searcher.(DeterministicSearcher); !ok || !ds.Deterministic() {
 			return nil, "", ErrNonDeterministicSearcher
 		}
 	}
-	results, err := idx.searcher.Search(query, len(docs), docs)
+	results, err := idx.searchFiltered(query, len(docs), docs)
 	if err != nil {
 		return nil, "", err
 	}
@@ -893,7 +1419,7 @@ func (idx *InMemoryIndex) SearchPage(query string, limit int, cursor string) ([]
 	if err != nil {
 		return nil, "", err
 	}
-	return page, nextCursor, nil
+	return idx.markDegraded(page), nextCursor, nil
 }
 
 // ensureSearchDocsLocked rebuilds the search docs cache if dirty.
@@ -1012,6 +1538,9 @@ func (idx *InMemoryIndex) ListNamespacesPage(limit int, cursor string) ([]string
 func refreshRecordDerived(record *toolRecord) {
 	record.docText = buildDocText(record.tool, record.normalizedTags)
 	record.summary = buildSummary(record.tool, record.normalizedTags)
+	if len(record.metadata) > 0 {
+		record.summary.Metadata = record.metadata
+	}
 }
 
 // buildDocText creates the lowercased search text for a tool.
@@ -1036,7 +1565,9 @@ func buildDocText(tool model.Tool, normalizedTags []string) string {
 	if len(outputModes) > 0 {
 		parts = append(parts, strings.ToLower(strings.Join(outputModes, " ")))
 	}
-	parts = append(parts, normalizedTags...) // already normalized/lowercased
+	for _, tag := range normalizedTags {
+		parts = append(parts, strings.ToLower(tag)) // TagPolicy.PreserveCase keeps case
+	}
 	return strings.Join(parts, " ")
 }
 
@@ -1059,6 +1590,7 @@ func buildSummary(tool model.Tool, normalizedTags []string) Summary {
 		OutputModes:      stringSliceFromAny(tool.Meta["outputModes"]),
 		SecuritySummary:  securitySummaryFromMeta(tool.Meta),
 		Tags:             normalizedTags,
+		Hints:            hintsFromTool(tool.Annotations),
 	}
 }
 
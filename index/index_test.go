@@ -1,12 +1,16 @@
 package index
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -911,6 +915,17 @@ func TestGetAllBackends_NotFound(t *testing.T) {
 	}
 }
 
+func TestToolIDs(t *testing.T) {
+	idx := NewInMemoryIndex()
+	_ = idx.RegisterTool(makeTestTool("b", "ns", "d", nil), makeMCPBackend("s"))
+	_ = idx.RegisterTool(makeTestTool("a", "", "d", nil), makeMCPBackend("s"))
+
+	ids := idx.ToolIDs()
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "ns:b" {
+		t.Errorf("ToolIDs() = %v, want [a ns:b]", ids)
+	}
+}
+
 // ============================================================
 // Tests for Namespaces
 // ============================================================
@@ -1194,6 +1209,55 @@ func TestSearch_ReturnsSummaryOnly(t *testing.T) {
 	}
 }
 
+// lengthScorer scores docs by ID length, shortest first.
+type lengthScorer struct{}
+
+func (lengthScorer) Search(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+	scored, _ := lengthScorer{}.SearchScored(query, limit, docs)
+	out := make([]Summary, len(scored))
+	for i, r := range scored {
+		out[i] = r.Summary
+	}
+	return out, nil
+}
+
+func (lengthScorer) SearchScored(_ string, limit int, docs []SearchDoc) ([]ScoredSummary, error) {
+	out := make([]ScoredSummary, 0, len(docs))
+	for _, doc := range docs {
+		out = append(out, ScoredSummary{Summary: doc.Summary, Score: 1 / float64(len(doc.ID))})
+	}
+	slices.SortFunc(out, func(a, b ScoredSummary) int { return len(a.Summary.ID) - len(b.Summary.ID) })
+	return out[:min(limit, len(out))], nil
+}
+
+func TestSearchWithScores(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{Searcher: lengthScorer{}})
+	for _, name := range []string{"a", "bb", "ccc"} {
+		team := map[string]string{"team": "x"}
+		if name == "bb" {
+			team = nil
+		}
+		if err := idx.RegisterToolWithMetadata(makeTestTool(name, "ns", "A tool", nil), makeMCPBackend("s"), team); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := idx.SearchWithScores("tool metadata.team=x", 10)
+	if err != nil {
+		t.Fatalf("SearchWithScores failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Summary.ID != "ns:a" || results[1].Summary.ID != "ns:ccc" {
+		t.Fatalf("SearchWithScores = %+v, want ns:a then ns:ccc", results)
+	}
+	if results[0].Score != 1.0/4 || results[1].Score != 1.0/6 {
+		t.Errorf("scores = %v, %v; want 1/4, 1/6", results[0].Score, results[1].Score)
+	}
+
+	if _, err := NewInMemoryIndex().SearchWithScores("tool", 10); !errors.Is(err, ErrScoresUnsupported) {
+		t.Errorf("default searcher: err = %v, want ErrScoresUnsupported", err)
+	}
+}
+
 func TestSearch_TruncatesLongDescription(t *testing.T) {
 	idx := NewInMemoryIndex()
 
@@ -1853,6 +1917,113 @@ func TestOnChange_MultipleListeners(t *testing.T) {
 	}
 }
 
+func receiveEvent(t *testing.T, ch <-chan ChangeEvent) ChangeEvent {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("watch channel closed")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for change event")
+	}
+	return ChangeEvent{}
+}
+
+func TestWatch_ReplaysThenStreams(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("t1", "ns", "desc", nil), makeLocalBackend("h1"))
+	since := idx.Version()
+	mustRegister(t, idx, makeTestTool("t2", "ns", "desc", nil), makeLocalBackend("h2"))
+	_ = idx.UnregisterBackend("ns:t2", model.BackendKindLocal, "h2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := idx.Watch(ctx, since)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Missed events are replayed in order, then new ones stream.
+	if e := receiveEvent(t, ch); e.Type != ChangeRegistered || e.ToolID != "ns:t2" || e.Version != since+1 {
+		t.Errorf("first replayed event = %+v", e)
+	}
+	if e := receiveEvent(t, ch); e.Type != ChangeToolRemoved || e.ToolID != "ns:t2" {
+		t.Errorf("second replayed event = %+v", e)
+	}
+	idx.Refresh()
+	if e := receiveEvent(t, ch); e.Type != ChangeRefreshed || e.Version != idx.Version() {
+		t.Errorf("streamed event = %+v", e)
+	}
+
+	// Watching from the current version streams only new events.
+	live, err := idx.Watch(ctx, idx.Version())
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	mustRegister(t, idx, makeTestTool("t3", "ns", "desc", nil), makeLocalBackend("h3"))
+	if e := receiveEvent(t, live); e.ToolID != "ns:t3" {
+		t.Errorf("live event = %+v, want ns:t3", e)
+	}
+}
+
+func TestWatch_Truncated(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{ChangeBufferSize: 2})
+	for i := range 4 {
+		mustRegister(t, idx, makeTestTool(fmt.Sprintf("t%d", i), "ns", "desc", nil), makeLocalBackend("h"))
+	}
+	ctx := context.Background()
+	if _, err := idx.Watch(ctx, 1); !errors.Is(err, ErrChangesTruncated) {
+		t.Errorf("Watch(1) err = %v, want ErrChangesTruncated", err)
+	}
+	if _, err := idx.Watch(ctx, idx.Version()+1); !errors.Is(err, ErrChangesTruncated) {
+		t.Errorf("Watch(future) err = %v, want ErrChangesTruncated", err)
+	}
+	ch, err := idx.Watch(ctx, 2)
+	if err != nil {
+		t.Fatalf("Watch(2) failed: %v", err)
+	}
+	if e := receiveEvent(t, ch); e.Version != 3 {
+		t.Errorf("replayed version = %d, want 3", e.Version)
+	}
+}
+
+func TestWatch_ClosesChannel(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{ChangeBufferSize: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := idx.Watch(ctx, 0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("unexpected event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if _, err := idx.Watch(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch with done ctx err = %v", err)
+	}
+
+	// A consumer that falls more than the buffer behind is cut off after
+	// the events queued before the overflow.
+	slow, _ := idx.Watch(context.Background(), idx.Version())
+	for i := range 4 {
+		mustRegister(t, idx, makeTestTool(fmt.Sprintf("t%d", i), "ns", "desc", nil), makeLocalBackend("h"))
+	}
+	var got int
+	for range slow {
+		got++
+	}
+	if got == 0 || got > 3 {
+		t.Errorf("slow consumer received %d events before close", got)
+	}
+}
+
 // ============================================================
 // Tests for boolPtrEqual
 // ============================================================
@@ -2145,6 +2316,10 @@ func TestJsonEqual(t *testing.T) {
 		{"different bool", true, false, false},
 		{"same int", 42, 42, true},
 		{"int vs float64", 42, 42.0, true},
+		{"float64 vs int", 42.0, 42, true},
+		{"typed slice vs decoded", []string{"a", "b"}, []any{"a", "b"}, true},
+		{"decoded vs typed slice", []any{"a"}, []string{"a"}, true},
+		{"nested int vs decoded", map[string]any{"min": 1}, map[string]any{"min": 1.0}, true},
 		{"different int", 42, 43, false},
 		{"same slice", []any{"a", "b"}, []any{"a", "b"}, true},
 		{"different slice length", []any{"a"}, []any{"a", "b"}, false},
@@ -2680,3 +2855,1453 @@ func TestRegisterToolsFromMCP_EmptyBatch(t *testing.T) {
 		t.Fatalf("RegisterToolsFromMCP with empty slice should succeed, got: %v", err)
 	}
 }
+
+func TestReplaceToolsFromMCP(t *testing.T) {
+	idx := NewInMemoryIndex()
+	a := makeTestTool("a", "gh", "Tool A", nil)
+	b := makeTestTool("b", "gh", "Tool B", nil)
+	c := makeTestTool("c", "gh", "Tool C", nil)
+	e := makeTestTool("e", "gh", "Tool E", nil)
+	if err := idx.RegisterToolsFromMCP("github", []model.Tool{a, b, c, e}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+	mustRegister(t, idx, c, makeLocalBackend("fallback"))
+
+	var events []ChangeEvent
+	idx.OnChange(func(ev ChangeEvent) { events = append(events, ev) })
+
+	b.Tags = []string{"changed"}
+	d := makeTestTool("d", "gh", "Tool D", nil)
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{d, b, a}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP failed: %v", err)
+	}
+
+	want := []struct {
+		typ ChangeType
+		id  string
+	}{
+		{ChangeBackendRemoved, "gh:c"},
+		{ChangeToolRemoved, "gh:e"},
+		{ChangeUpdated, "gh:b"},
+		{ChangeRegistered, "gh:d"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].ToolID != w.id {
+			t.Errorf("event %d = %s %s, want %s %s", i, events[i].Type, events[i].ToolID, w.typ, w.id)
+		}
+		if i > 0 && events[i].Version <= events[i-1].Version {
+			t.Errorf("event %d version %d not after %d", i, events[i].Version, events[i-1].Version)
+		}
+	}
+
+	if got := strings.Join(idx.MCPServerToolIDs("github"), ","); got != "gh:a,gh:b,gh:d" {
+		t.Errorf("MCPServerToolIDs = %s, want gh:a,gh:b,gh:d", got)
+	}
+	if _, backend, err := idx.GetTool("gh:c"); err != nil || backend.Kind != model.BackendKindLocal {
+		t.Errorf("gh:c = %+v, %v; want local backend kept", backend, err)
+	}
+	if _, _, err := idx.GetTool("gh:e"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("gh:e error = %v, want ErrNotFound", err)
+	}
+	if tool, _, _ := idx.GetTool("gh:b"); len(tool.Tags) != 1 || tool.Tags[0] != "changed" {
+		t.Errorf("gh:b tags = %v, want [changed]", tool.Tags)
+	}
+
+	events = nil
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{a, b, d}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("unchanged replace emitted %+v, want no events", events)
+	}
+}
+
+func TestReplaceToolsFromMCP_RejectsWithoutChanges(t *testing.T) {
+	idx := NewInMemoryIndex()
+	a := makeTestTool("a", "gh", "Tool A", nil)
+	b := makeTestTool("b", "gh", "Tool B", nil)
+	if err := idx.RegisterToolsFromMCP("github", []model.Tool{a, b}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+	version := idx.Version()
+
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{a, a}); !errors.Is(err, ErrInvalidTool) {
+		t.Fatalf("duplicate replace error = %v, want ErrInvalidTool", err)
+	}
+	if err := idx.ReplaceToolsFromMCP("", []model.Tool{a}); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("empty server error = %v, want ErrInvalidBackend", err)
+	}
+
+	if got := idx.Version(); got != version {
+		t.Errorf("version = %d, want unchanged %d", got, version)
+	}
+	if got := strings.Join(idx.MCPServerToolIDs("github"), ","); got != "gh:a,gh:b" {
+		t.Errorf("MCPServerToolIDs = %s, want gh:a,gh:b", got)
+	}
+}
+
+func TestReplaceToolsFromMCP_ChangedMCPFields(t *testing.T) {
+	idx := NewInMemoryIndex()
+	a := makeTestTool("a", "gh", "Tool A", nil)
+	shared := makeTestTool("shared", "gh", "Shared", nil)
+	if err := idx.RegisterToolsFromMCP("github", []model.Tool{a, shared}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+	if err := idx.RegisterTool(shared, makeMCPBackend("mirror")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	changedA := makeTestTool("a", "gh", "Tool A, revised", nil)
+	changedShared := makeTestTool("shared", "gh", "Shared, revised", nil)
+	c := makeTestTool("c", "gh", "Tool C", nil)
+	err := idx.ReplaceToolsFromMCP("github", []model.Tool{changedA, changedShared, c})
+	if !errors.Is(err, ErrInvalidTool) || !strings.Contains(err.Error(), "gh:shared") || strings.Contains(err.Error(), "gh:a") {
+		t.Fatalf("ReplaceToolsFromMCP error = %v, want ErrInvalidTool for gh:shared only", err)
+	}
+
+	// The server's sole tool is replaced in place and the new tool added.
+	if tool, _, err := idx.GetTool("gh:a"); err != nil || tool.Description != "Tool A, revised" {
+		t.Errorf("gh:a = %q, %v; want revised description", tool.Description, err)
+	}
+	if _, _, err := idx.GetTool("gh:c"); err != nil {
+		t.Errorf("GetTool(gh:c) error = %v", err)
+	}
+	// The shared tool keeps its registration and both backends.
+	if tool, _, err := idx.GetTool("gh:shared"); err != nil || tool.Description != "Shared" {
+		t.Errorf("gh:shared = %q, %v; want original description", tool.Description, err)
+	}
+	if backends, err := idx.GetAllBackends("gh:shared"); err != nil || len(backends) != 2 {
+		t.Errorf("gh:shared backends = %v, %v; want 2", backends, err)
+	}
+}
+
+func TestReplaceToolsFromMCP_TrackToolVersions(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{TrackToolVersions: true})
+	if err := idx.RegisterToolsFromMCP("github", []model.Tool{makeTestTool("a", "gh", "v1", nil)}); err != nil {
+		t.Fatalf("RegisterToolsFromMCP failed: %v", err)
+	}
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{makeTestTool("a", "gh", "v2", nil)}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP failed: %v", err)
+	}
+	tool, _, err := idx.GetTool("gh:a")
+	if err != nil || tool.Description != "v2" {
+		t.Errorf("gh:a = %q, %v; want v2", tool.Description, err)
+	}
+}
+
+func TestRestoreToolVersions(t *testing.T) {
+	src := NewInMemoryIndex(IndexOptions{TrackToolVersions: true})
+	mustRegister(t, src, makeTestTool("a", "gh", "v1", nil), makeMCPBackend("github"))
+	mustRegister(t, src, makeTestTool("a", "gh", "v2", nil), makeMCPBackend("github"))
+	history, _ := src.ListToolVersions("gh:a")
+	state, _ := src.GetToolState("gh:a")
+
+	dst := NewInMemoryIndex(IndexOptions{TrackToolVersions: true, MaxToolVersions: 1})
+	if err := dst.SetToolState("gh:a", &state); err != nil {
+		t.Fatalf("SetToolState failed: %v", err)
+	}
+	if err := dst.RestoreToolVersions("gh:a", history); err != nil {
+		t.Fatalf("RestoreToolVersions failed: %v", err)
+	}
+	got, _ := dst.ListToolVersions("gh:a")
+	if len(got) != 1 || got[0].Version != 2 || got[0].Tool.Description != "v2" {
+		t.Errorf("restored versions = %+v, want only version 2", got)
+	}
+
+	if err := dst.RestoreToolVersions("gh:a", history[:1]); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("history ending at an old definition error = %v, want ErrInvalidTool", err)
+	}
+	if err := dst.RestoreToolVersions("gh:a", []ToolVersion{history[1], history[0]}); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("unordered history error = %v, want ErrInvalidTool", err)
+	}
+	if err := dst.RestoreToolVersions("gh:missing", history); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown tool error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRegisterToolWithMetadata_SurfacedAndFiltered(t *testing.T) {
+	idx := NewInMemoryIndex()
+	platform := makeTestTool("deploy", "ops", "Deploy service", nil)
+	payments := makeTestTool("refund", "billing", "Issue refund", nil)
+
+	if err := idx.RegisterToolWithMetadata(platform, makeMCPBackend("s1"), map[string]string{"team": "platform", "tier": "1"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata failed: %v", err)
+	}
+	if err := idx.RegisterTools([]ToolRegistration{{
+		Tool:     payments,
+		Backend:  makeMCPBackend("s1"),
+		Metadata: map[string]string{"team": "payments"},
+	}}); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	results, err := idx.Search("metadata.team=platform", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "ops:deploy" {
+		t.Fatalf("expected only ops:deploy, got %+v", results)
+	}
+	if results[0].Metadata["tier"] != "1" {
+		t.Errorf("expected metadata in summary, got %v", results[0].Metadata)
+	}
+
+	results, _ = idx.Search("refund metadata.team=platform", 10)
+	if len(results) != 0 {
+		t.Errorf("expected text and metadata filters to combine, got %+v", results)
+	}
+
+	page, _, err := idx.SearchPage("metadata.team=payments", 10, "")
+	if err != nil {
+		t.Fatalf("SearchPage failed: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != "billing:refund" {
+		t.Fatalf("expected billing:refund, got %+v", page)
+	}
+}
+
+func TestSearch_FiltersApplyToRankedHits(t *testing.T) {
+	var docCounts []int
+	searcher := &mockSearcher{searchFunc: func(query string, limit int, docs []SearchDoc) ([]Summary, error) {
+		docCounts = append(docCounts, len(docs))
+		out := make([]Summary, 0, len(docs))
+		for _, doc := range docs[:min(limit, len(docs))] {
+			out = append(out, doc.Summary)
+		}
+		return out, nil
+	}}
+	idx := NewInMemoryIndex(IndexOptions{Searcher: searcher})
+	for i, team := range []string{"a", "b", "a"} {
+		tool := makeTestTool(fmt.Sprintf("pods%d", i), "k8s", "List pods", nil)
+		if err := idx.RegisterToolWithMetadata(tool, makeMCPBackend("s1"), map[string]string{"team": team}); err != nil {
+			t.Fatalf("RegisterToolWithMetadata failed: %v", err)
+		}
+	}
+
+	results, err := idx.Search("pods metadata.team=a", 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "k8s:pods0" {
+		t.Errorf("expected k8s:pods0, got %+v", results)
+	}
+	results, _ = idx.Search("pods metadata.team=b hint.readOnly=false", 10)
+	if len(results) != 1 || results[0].ID != "k8s:pods1" {
+		t.Errorf("expected k8s:pods1, got %+v", results)
+	}
+	for _, n := range docCounts {
+		if n != 3 {
+			t.Errorf("searcher saw %d docs, want the full set of 3 for every filter", n)
+		}
+	}
+}
+
+func TestRegisterTool_PreservesMetadata(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("deploy", "ops", "Deploy service", nil)
+	_ = idx.RegisterToolWithMetadata(tool, makeMCPBackend("s1"), map[string]string{"team": "platform"})
+	_ = idx.RegisterTool(tool, makeMCPBackend("s2"))
+
+	md, err := idx.GetMetadata("ops:deploy")
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if md["team"] != "platform" {
+		t.Fatalf("expected metadata preserved, got %v", md)
+	}
+
+	md["team"] = "mutated"
+	if again, _ := idx.GetMetadata("ops:deploy"); again["team"] != "platform" {
+		t.Error("GetMetadata should return a copy")
+	}
+
+	_ = idx.RegisterToolWithMetadata(tool, makeMCPBackend("s1"), nil)
+	if md, _ := idx.GetMetadata("ops:deploy"); len(md) != 0 {
+		t.Errorf("expected nil metadata to clear, got %v", md)
+	}
+}
+
+func TestRegisterToolWithMetadata_InvalidKey(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("deploy", "ops", "Deploy service", nil)
+	for _, key := range []string{"", "a=b", "has space"} {
+		err := idx.RegisterToolWithMetadata(tool, makeMCPBackend("s1"), map[string]string{key: "x"})
+		if !errors.Is(err, ErrInvalidMetadata) || !errors.Is(err, ErrInvalidTool) {
+			t.Errorf("key %q: expected ErrInvalidMetadata, got %v", key, err)
+		}
+	}
+	if _, err := idx.GetMetadata("ops:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseMetadataFilters(t *testing.T) {
+	text, filters := ParseMetadataFilters("deploy metadata.team=platform metadata.env=prod metadata.bad")
+	if text != "deploy metadata.bad" {
+		t.Errorf("text = %q", text)
+	}
+	if len(filters) != 2 || filters["team"] != "platform" || filters["env"] != "prod" {
+		t.Errorf("filters = %v", filters)
+	}
+
+	text, filters = ParseMetadataFilters("  plain query ")
+	if text != "  plain query " || filters != nil {
+		t.Errorf("expected query unchanged without filters, got %q %v", text, filters)
+	}
+}
+
+func TestMaintenanceWindow_DegradedSummaries(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	idx := NewInMemoryIndex(IndexOptions{Now: func() time.Time { return now }})
+	_ = idx.RegisterTool(makeTestTool("deploy", "ops", "Deploy", nil), makeMCPBackend("ops-server"))
+	_ = idx.RegisterTool(makeTestTool("refund", "billing", "Refund", nil), makeMCPBackend("billing-server"))
+
+	if err := idx.AddMaintenanceWindow(MaintenanceWindow{
+		ID:      "ops-upgrade",
+		Backend: "ops-server",
+		Start:   now.Add(-time.Hour),
+		End:     now.Add(time.Hour),
+		Message: "upgrading",
+	}); err != nil {
+		t.Fatalf("AddMaintenanceWindow failed: %v", err)
+	}
+	_ = idx.AddMaintenanceWindow(MaintenanceWindow{
+		ID:        "billing-later",
+		Namespace: "billing",
+		Start:     now.Add(time.Hour),
+	})
+
+	results, _ := idx.Search("", 10)
+	degraded := map[string]bool{}
+	for _, r := range results {
+		degraded[r.ID] = r.Degraded
+	}
+	if !degraded["ops:deploy"] || degraded["billing:refund"] {
+		t.Fatalf("unexpected degraded flags: %v", degraded)
+	}
+
+	w, ok := idx.ActiveMaintenance("ops:deploy")
+	if !ok || w.ID != "ops-upgrade" {
+		t.Fatalf("expected active ops-upgrade window, got %+v %v", w, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := idx.ActiveMaintenance("ops:deploy"); ok {
+		t.Error("expected ops window to have ended")
+	}
+	if w, ok := idx.ActiveMaintenance("billing:refund"); !ok || w.ID != "billing-later" {
+		t.Errorf("expected open-ended billing window active, got %+v %v", w, ok)
+	}
+	if ws := idx.MaintenanceWindows(); len(ws) != 2 || ws[0].ID != "ops-upgrade" {
+		t.Errorf("unexpected windows: %+v", ws)
+	}
+}
+
+func TestMaintenanceWindow_Validation(t *testing.T) {
+	idx := NewInMemoryIndex()
+	start := time.Now()
+	invalid := []MaintenanceWindow{
+		{Namespace: "ns", Start: start},
+		{ID: "a", Start: start},
+		{ID: "a", Namespace: "ns", Backend: "b", Start: start},
+		{ID: "a", Namespace: "ns"},
+		{ID: "a", Namespace: "ns", Start: start, End: start.Add(-time.Second)},
+	}
+	for _, w := range invalid {
+		if err := idx.AddMaintenanceWindow(w); !errors.Is(err, ErrInvalidMaintenanceWindow) {
+			t.Errorf("%+v: expected ErrInvalidMaintenanceWindow, got %v", w, err)
+		}
+	}
+	if err := idx.RemoveMaintenanceWindow("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSummariesFor(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("stable", "ops", "Stable tool", nil), makeMCPBackend("ops"))
+	mustRegister(t, idx, makeTestTool("canary", "ops", "Canary tool", nil), makeMCPBackend("ops"))
+	_ = idx.SetRollout(Rollout{ToolID: "ops:canary", Principals: []string{"qa-agent"}})
+
+	ids := []string{"ops:canary", "ops:missing", "ops:stable"}
+	if got := idx.SummariesFor("", ids); len(got) != 1 || got[0].ID != "ops:stable" {
+		t.Errorf("anonymous SummariesFor = %v, want [ops:stable]", got)
+	}
+	if got := idx.SummariesFor("qa-agent", ids); len(got) != 2 || got[0].ID != "ops:canary" {
+		t.Errorf("allowlisted SummariesFor = %v, want canary first", got)
+	}
+}
+
+func TestRollout_PercentageVisibility(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("stable", "ops", "Stable tool", nil), makeMCPBackend("ops"))
+	mustRegister(t, idx, makeTestTool("canary", "ops", "Canary tool", nil), makeMCPBackend("ops"))
+
+	if err := idx.SetRollout(Rollout{ToolID: "ops:canary", Percent: 0, Principals: []string{"qa-agent"}}); err != nil {
+		t.Fatalf("SetRollout failed: %v", err)
+	}
+
+	ids := func(results []Summary) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.ID
+		}
+		return out
+	}
+	results, _ := idx.Search("", 10)
+	if got := ids(results); len(got) != 1 || got[0] != "ops:stable" {
+		t.Fatalf("anonymous search = %v, want [ops:stable]", got)
+	}
+	results, _ = idx.SearchFor("qa-agent", "", 10)
+	if len(results) != 2 {
+		t.Fatalf("allowlisted search = %v, want both tools", ids(results))
+	}
+
+	countVisible := func() int {
+		n := 0
+		for i := range 1000 {
+			if idx.VisibleTo("ops:canary", fmt.Sprintf("agent-%d", i)) {
+				n++
+			}
+		}
+		return n
+	}
+	if n := countVisible(); n != 0 {
+		t.Fatalf("0%% rollout visible to %d principals", n)
+	}
+
+	if err := idx.RampRollout("ops:canary", 25); err != nil {
+		t.Fatalf("RampRollout failed: %v", err)
+	}
+	var early []string
+	for i := range 1000 {
+		if p := fmt.Sprintf("agent-%d", i); idx.VisibleTo("ops:canary", p) {
+			early = append(early, p)
+		}
+	}
+	if n := len(early); n < 150 || n > 350 {
+		t.Fatalf("25%% rollout visible to %d of 1000 principals", n)
+	}
+
+	// Ramping up keeps earlier principals in the rollout.
+	if err := idx.RampRollout("ops:canary", 60); err != nil {
+		t.Fatalf("RampRollout failed: %v", err)
+	}
+	for _, p := range early {
+		if !idx.VisibleTo("ops:canary", p) {
+			t.Fatalf("principal %s lost visibility after ramp up", p)
+		}
+	}
+	results, _ = idx.SearchFor(early[0], "canary", 10)
+	if len(results) != 1 || results[0].ID != "ops:canary" {
+		t.Fatalf("SearchFor(%s) = %v, want [ops:canary]", early[0], ids(results))
+	}
+
+	if err := idx.FinalizeRollout("ops:canary"); err != nil {
+		t.Fatalf("FinalizeRollout failed: %v", err)
+	}
+	if _, ok := idx.GetRollout("ops:canary"); ok {
+		t.Fatal("expected rollout to be removed")
+	}
+	results, _ = idx.Search("", 10)
+	if len(results) != 2 {
+		t.Fatalf("search after finalize = %v, want both tools", ids(results))
+	}
+}
+
+func TestRollout_Validation(t *testing.T) {
+	idx := NewInMemoryIndex()
+	for _, r := range []Rollout{{Percent: 10}, {ToolID: "ns:a", Percent: -1}, {ToolID: "ns:a", Percent: 101}} {
+		if err := idx.SetRollout(r); !errors.Is(err, ErrInvalidRollout) {
+			t.Errorf("%+v: expected ErrInvalidRollout, got %v", r, err)
+		}
+	}
+	if err := idx.RampRollout("ns:missing", 50); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RampRollout: expected ErrNotFound, got %v", err)
+	}
+	if err := idx.FinalizeRollout("ns:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FinalizeRollout: expected ErrNotFound, got %v", err)
+	}
+	_ = idx.SetRollout(Rollout{ToolID: "ns:a", Percent: 10})
+	if err := idx.RampRollout("ns:a", 200); !errors.Is(err, ErrInvalidRollout) {
+		t.Errorf("RampRollout: expected ErrInvalidRollout, got %v", err)
+	}
+	if r, _ := idx.GetRollout("ns:a"); r.Percent != 10 {
+		t.Errorf("invalid ramp changed percent to %d", r.Percent)
+	}
+}
+
+func TestRollout_InvalidatesCursors(t *testing.T) {
+	idx := NewInMemoryIndex()
+	for _, name := range []string{"a", "b", "c"} {
+		mustRegister(t, idx, makeTestTool(name, "ns", "tool", nil), makeMCPBackend("srv"))
+	}
+	_, cursor, err := idx.SearchPage("", 1, "")
+	if err != nil || cursor == "" {
+		t.Fatalf("SearchPage failed: cursor=%q err=%v", cursor, err)
+	}
+	_ = idx.SetRollout(Rollout{ToolID: "ns:b", Percent: 50})
+	if _, _, err := idx.SearchPage("", 1, cursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor after rollout change, got %v", err)
+	}
+}
+
+// plainIndex hides every optional capability of the wrapped index.
+type plainIndex struct{ Index }
+
+func TestCapabilityHelpers_InMemory(t *testing.T) {
+	idx := NewInMemoryIndex()
+	var events int
+	unsub := OnChange(idx, func(ChangeEvent) { events++ })
+	defer unsub()
+
+	if err := idx.RegisterTool(makeTestTool("a", "ns", "A tool", nil), makeMCPBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if got, want := Version(idx), idx.Version(); got != want || got == 0 {
+		t.Fatalf("Version = %d, want %d (non-zero)", got, want)
+	}
+	if got := Refresh(idx); got != idx.Version() {
+		t.Fatalf("Refresh = %d, want %d", got, idx.Version())
+	}
+	if events != 2 {
+		t.Fatalf("events = %d, want 2 (registered, refreshed)", events)
+	}
+}
+
+func TestCapabilityHelpers_Fallbacks(t *testing.T) {
+	idx := plainIndex{NewInMemoryIndex()}
+	if err := idx.RegisterTool(makeTestTool("a", "ns", "A tool", nil), makeMCPBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if got := Version(idx); got != 0 {
+		t.Fatalf("Version = %d, want 0", got)
+	}
+	if got := Refresh(idx); got != 0 {
+		t.Fatalf("Refresh = %d, want 0", got)
+	}
+	unsub := OnChange(idx, func(ChangeEvent) { t.Fatal("unexpected event") })
+	if unsub == nil {
+		t.Fatal("expected non-nil unsubscribe function")
+	}
+	unsub()
+	unsub()
+}
+
+func TestRegisterTool_TrackToolVersions(t *testing.T) {
+	now := time.Unix(1000, 0)
+	idx := NewInMemoryIndex(IndexOptions{
+		TrackToolVersions: true,
+		MaxToolVersions:   2,
+		Now:               func() time.Time { return now },
+	})
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	if err := idx.RegisterTool(makeTestTool("mytool", "ns", "v1", nil), makeMCPBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool v1 failed: %v", err)
+	}
+	// Tag-only changes update the current version in place.
+	if err := idx.RegisterTool(makeTestTool("mytool", "ns", "v1", []string{"tag"}), makeMCPBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool tags failed: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if err := idx.RegisterTool(makeTestTool("mytool", "ns", "v2", nil), makeMCPBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool v2 failed: %v", err)
+	}
+
+	versions, err := idx.ListToolVersions("ns:mytool")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("ListToolVersions = %v, %v", versions, err)
+	}
+	if versions[0].Version != 1 || len(versions[0].Tool.Tags) != 1 || versions[1].Version != 2 {
+		t.Errorf("versions = %+v", versions)
+	}
+	if !versions[1].RegisteredAt.Equal(now) || versions[1].IndexVersion != idx.Version() {
+		t.Errorf("version 2 metadata = %+v", versions[1])
+	}
+	if tool, _, _ := idx.GetTool("ns:mytool"); tool.Description != "v2" {
+		t.Errorf("GetTool should return the latest version, got %q", tool.Description)
+	}
+	if tool, err := idx.GetToolVersion("ns:mytool", 1); err != nil || tool.Description != "v1" {
+		t.Errorf("GetToolVersion(1) = %q, %v", tool.Description, err)
+	}
+	if _, err := idx.GetToolVersion("ns:mytool", 5); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetToolVersion(5) error = %v", err)
+	}
+
+	last := events[len(events)-1]
+	if last.Type != ChangeUpdated || last.ToolVersion != 2 || last.PreviousToolVersion != 1 {
+		t.Errorf("last event = %+v", last)
+	}
+	if events[1].ToolVersion != 1 || events[1].PreviousToolVersion != 0 {
+		t.Errorf("tag update event = %+v", events[1])
+	}
+
+	// The oldest versions are dropped beyond MaxToolVersions.
+	_ = idx.RegisterTool(makeTestTool("mytool", "ns", "v3", nil), makeMCPBackend("s1"))
+	versions, _ = idx.ListToolVersions("ns:mytool")
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 3 {
+		t.Errorf("capped versions = %+v", versions)
+	}
+	if _, err := idx.ListToolVersions("ns:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListToolVersions(missing) error = %v", err)
+	}
+}
+
+func TestParseToolID(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      ToolID
+		component string
+	}{
+		{in: "read", want: ToolID{Name: "read"}},
+		{in: "fs:read", want: ToolID{Namespace: "fs", Name: "read"}},
+		{in: "fs:read:1.2.0", want: ToolID{Namespace: "fs", Name: "read", Version: "1.2.0"}},
+		{in: "fs:read:1.2.0+build.5", want: ToolID{Namespace: "fs", Name: "read", Version: "1.2.0+build.5"}},
+		{in: "fs:read+all", component: "name"},
+		{in: ""},
+		{in: "a:b:c:d"},
+		{in: ":read", component: "namespace"},
+		{in: "fs:", component: "name"},
+		{in: "fs:read:", component: "version"},
+		{in: "my fs:read", component: "namespace"},
+		{in: "fs:read/all", component: "name"},
+	}
+	for _, tt := range tests {
+		got, err := ParseToolID(tt.in)
+		if tt.want.Name != "" {
+			if err != nil || got != tt.want {
+				t.Errorf("ParseToolID(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+			}
+			if got.String() != tt.in {
+				t.Errorf("ParseToolID(%q).String() = %q", tt.in, got.String())
+			}
+			continue
+		}
+		var idErr *ToolIDError
+		if !errors.As(err, &idErr) || !errors.Is(err, ErrInvalidToolID) || !errors.Is(err, model.ErrInvalidToolID) {
+			t.Errorf("ParseToolID(%q) error = %v, want *ToolIDError", tt.in, err)
+			continue
+		}
+		if idErr.Component != tt.component {
+			t.Errorf("ParseToolID(%q) component = %q, want %q", tt.in, idErr.Component, tt.component)
+		}
+	}
+
+	if err := ValidateToolID("fs:" + strings.Repeat("x", MaxToolIDComponentLen+1)); !errors.Is(err, ErrInvalidToolID) {
+		t.Errorf("long name error = %v, want ErrInvalidToolID", err)
+	}
+}
+
+func TestRegisterTool_ToolIDRules(t *testing.T) {
+	idx := NewInMemoryIndex()
+
+	var idErr *ToolIDError
+	err := idx.RegisterTool(makeTestTool("read", "fs:v2", "Read", nil), makeMCPBackend("s"))
+	if !errors.Is(err, ErrInvalidTool) || !errors.As(err, &idErr) || idErr.Component != "namespace" {
+		t.Fatalf("reserved separator error = %v, want ErrInvalidTool with namespace ToolIDError", err)
+	}
+
+	built := makeTestTool("write", "fs", "Write", nil)
+	built.Version = "1.2.0+build.5"
+	mustRegister(t, idx, built, makeMCPBackend("s"))
+	if _, _, err := idx.GetTool("fs:write:1.2.0+build.5"); err != nil {
+		t.Fatalf("GetTool with build metadata failed: %v", err)
+	}
+
+	mustRegister(t, idx, makeTestTool("read", "FS", "Read", nil), makeMCPBackend("s"))
+	err = idx.RegisterTool(makeTestTool("read", "fs", "Read", nil), makeMCPBackend("s"))
+	if !errors.Is(err, ErrInvalidToolID) || !strings.Contains(err.Error(), `"FS:read"`) {
+		t.Fatalf("case conflict error = %v, want ErrInvalidToolID naming FS:read", err)
+	}
+	err = idx.ReplaceToolsFromMCP("t", []model.Tool{makeTestTool("x", "ns", "X", nil), makeTestTool("X", "ns", "X", nil)})
+	if !errors.Is(err, ErrInvalidToolID) {
+		t.Fatalf("replace case conflict error = %v, want ErrInvalidToolID", err)
+	}
+
+	// Re-registering the same ID and registering after removal are allowed.
+	mustRegister(t, idx, makeTestTool("read", "FS", "Read", nil), makeLocalBackend("h"))
+	if err := idx.UnregisterBackend("FS:read", model.BackendKindMCP, "s"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if err := idx.UnregisterBackend("FS:read", model.BackendKindLocal, "h"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	mustRegister(t, idx, makeTestTool("read", "fs", "Read", nil), makeMCPBackend("s"))
+}
+
+func TestUnregisterBackend_ProviderIDWithColon(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("mytool", "ns", "desc", nil)
+	mustRegister(t, idx, tool, makeProviderBackend("acme:eu", "create"))
+	mustRegister(t, idx, tool, makeProviderBackend("acme", "eu:update"))
+	mustRegister(t, idx, tool, makeProviderBackend("other", "x"))
+
+	if err := idx.UnregisterBackend("ns:mytool", model.BackendKindProvider, "acme:eu:create"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	backends, _ := idx.GetAllBackends("ns:mytool")
+	if len(backends) != 2 {
+		t.Fatalf("backends = %+v, want 2 left", backends)
+	}
+
+	mustRegister(t, idx, tool, makeProviderBackend("a:b", "c"))
+	mustRegister(t, idx, tool, makeProviderBackend("a", "b:c"))
+	if err := idx.UnregisterBackend("ns:mytool", model.BackendKindProvider, "a:b:c"); !errors.Is(err, ErrInvalidBackend) {
+		t.Fatalf("ambiguous provider backendID error = %v, want ErrInvalidBackend", err)
+	}
+}
+
+func TestCatalogLimits(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{
+		MaxTools:             3,
+		MaxToolsPerNamespace: 2,
+		MaxTagsPerTool:       2,
+		MaxDescriptionLen:    10,
+	})
+	backend := makeMCPBackend("github")
+	for _, tool := range []model.Tool{
+		makeTestTool("a", "gh", "A", nil),
+		makeTestTool("b", "gh", "B", nil),
+	} {
+		if err := idx.RegisterTool(tool, backend); err != nil {
+			t.Fatalf("RegisterTool(%s) failed: %v", tool.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		tool  model.Tool
+		limit string
+	}{
+		{"namespace full", makeTestTool("c", "gh", "C", nil), LimitToolsPerNamespace},
+		{"too many tags", makeTestTool("c", "other", "C", []string{"x", "y", "z"}), LimitTagsPerTool},
+		{"tags counted before normalization", makeTestTool("c", "other", "C", []string{"x", "X", " x "}), LimitTagsPerTool},
+		{"description too long", makeTestTool("c", "other", "a long description", nil), LimitDescriptionBytes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := idx.RegisterTool(tt.tool, backend)
+			var limitErr *LimitError
+			if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Fatalf("RegisterTool() error = %v, want %s LimitError", err, tt.limit)
+			}
+		})
+	}
+
+	// Re-registering an existing tool does not count against the caps.
+	if err := idx.RegisterTool(makeTestTool("a", "gh", "A", []string{"x"}), backend); err != nil {
+		t.Fatalf("re-registration failed: %v", err)
+	}
+	if err := idx.RegisterTool(makeTestTool("c", "other", "C", nil), backend); err != nil {
+		t.Fatalf("RegisterTool(c) failed: %v", err)
+	}
+	err := idx.RegisterTool(makeTestTool("d", "more", "D", nil), backend)
+	if limitErr, ok := err.(*LimitError); !ok || limitErr.Limit != LimitTools || limitErr.Max != 3 {
+		t.Fatalf("RegisterTool(d) error = %v, want tools LimitError", err)
+	}
+
+	want := map[string]uint64{LimitToolsPerNamespace: 1, LimitTagsPerTool: 2, LimitDescriptionBytes: 1, LimitTools: 1}
+	if got := idx.LimitRejections(); !maps.Equal(got, want) {
+		t.Errorf("LimitRejections() = %v, want %v", got, want)
+	}
+}
+
+func TestCatalogLimits_ReplaceToolsFromMCP(t *testing.T) {
+	idx := NewInMemoryIndex(IndexOptions{MaxTools: 2})
+	a := makeTestTool("a", "gh", "A", nil)
+	b := makeTestTool("b", "gh", "B", nil)
+	c := makeTestTool("c", "gh", "C", nil)
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{a, b}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP failed: %v", err)
+	}
+
+	// Removed tools free their slots within the same replacement.
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{b, c}); err != nil {
+		t.Fatalf("ReplaceToolsFromMCP(b, c) failed: %v", err)
+	}
+	if err := idx.ReplaceToolsFromMCP("github", []model.Tool{a, b, c}); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("ReplaceToolsFromMCP(a, b, c) error = %v, want ErrLimitExceeded", err)
+	}
+	if ids := idx.MCPServerToolIDs("github"); !slices.Equal(ids, []string{"gh:b", "gh:c"}) {
+		t.Errorf("MCPServerToolIDs() = %v, want unchanged [gh:b gh:c]", ids)
+	}
+}
+
+func TestBackendHealth(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	idx := NewInMemoryIndex(IndexOptions{ExcludeUnreachable: true, Now: func() time.Time { return now }})
+	issue := makeTestTool("create_issue", "gh", "Create an issue", nil)
+	closeIssue := makeTestTool("close_issue", "gh", "Close an issue", nil)
+	mustRegister(t, idx, issue, makeMCPBackend("github"))
+	mustRegister(t, idx, issue, makeMCPBackend("github-mirror"))
+	mustRegister(t, idx, closeIssue, makeMCPBackend("github"))
+
+	if err := idx.SetBackendHealth("gh:create_issue", "github", HealthStatus{State: "sick"}); !errors.Is(err, ErrInvalidHealth) {
+		t.Fatalf("SetBackendHealth(invalid state) error = %v, want ErrInvalidHealth", err)
+	}
+	if err := idx.SetBackendHealth("gh:create_issue", "gitlab", HealthStatus{State: HealthHealthy}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("SetBackendHealth(unknown backend) error = %v, want ErrNotFound", err)
+	}
+
+	unreachable := HealthStatus{State: HealthUnreachable, Message: "connection refused"}
+	for _, id := range []string{"gh:create_issue", "gh:close_issue"} {
+		if err := idx.SetBackendHealth(id, "github", unreachable); err != nil {
+			t.Fatalf("SetBackendHealth(%s) failed: %v", id, err)
+		}
+	}
+	version := idx.Version()
+
+	// create_issue still has a backend without a report, which counts as healthy.
+	results, err := idx.Search("issue", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "gh:create_issue" {
+		t.Fatalf("Search() = %v, want only gh:create_issue", results)
+	}
+	if results[0].Health != HealthHealthy || !results[0].HealthCheckedAt.Equal(now) {
+		t.Errorf("Health = %q at %v, want healthy at %v", results[0].Health, results[0].HealthCheckedAt, now)
+	}
+
+	if err := idx.SetBackendHealth("gh:close_issue", "github", HealthStatus{State: HealthDegraded}); err != nil {
+		t.Fatalf("SetBackendHealth failed: %v", err)
+	}
+	if idx.Version() == version {
+		t.Error("Version() unchanged after a tool became reachable")
+	}
+	results, _ = idx.Search("close", 10)
+	if len(results) != 1 || results[0].Health != HealthDegraded {
+		t.Errorf("Search(close) = %+v, want degraded gh:close_issue", results)
+	}
+
+	health, err := idx.BackendHealth("gh:create_issue")
+	if err != nil || len(health) != 1 || health["github"].Message != "connection refused" {
+		t.Errorf("BackendHealth() = %v, %v", health, err)
+	}
+	if err := idx.UnregisterBackend("gh:create_issue", model.BackendKindMCP, "github"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if health, _ := idx.BackendHealth("gh:create_issue"); len(health) != 0 {
+		t.Errorf("BackendHealth() after backend removal = %v, want empty", health)
+	}
+}
+
+func TestToolHints(t *testing.T) {
+	idx := NewInMemoryIndex()
+	annotated := func(name string, annotations *mcp.ToolAnnotations) model.Tool {
+		tool := makeTestTool(name, "repo", "Manage repository "+name, nil)
+		tool.Annotations = annotations
+		return tool
+	}
+	no := false
+	mustRegister(t, idx, annotated("list", &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: &no}), makeMCPBackend("git"))
+	mustRegister(t, idx, annotated("tag", &mcp.ToolAnnotations{DestructiveHint: &no, IdempotentHint: true}), makeMCPBackend("git"))
+	mustRegister(t, idx, annotated("delete", &mcp.ToolAnnotations{}), makeMCPBackend("git"))
+	mustRegister(t, idx, annotated("push", nil), makeMCPBackend("git"))
+
+	results, err := idx.Search("repository", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	hints := make(map[string]*ToolHints)
+	for _, s := range results {
+		hints[s.Name] = s.Hints
+	}
+	if got := hints["list"]; got == nil || *got != (ToolHints{ReadOnly: true, Idempotent: true}) {
+		t.Errorf("list hints = %+v, want read-only, idempotent, closed world", got)
+	}
+	if got := hints["tag"]; got == nil || *got != (ToolHints{Idempotent: true, OpenWorld: true}) {
+		t.Errorf("tag hints = %+v", got)
+	}
+	if got := hints["delete"]; got == nil || *got != (ToolHints{Destructive: true, OpenWorld: true}) {
+		t.Errorf("delete hints = %+v, want MCP defaults", got)
+	}
+	if hints["push"] != nil {
+		t.Errorf("push hints = %+v, want nil without annotations", hints["push"])
+	}
+
+	names := func(query string) string {
+		t.Helper()
+		results, err := idx.Search(query, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		var out []string
+		for _, s := range results {
+			out = append(out, s.Name)
+		}
+		slices.Sort(out)
+		return strings.Join(out, ",")
+	}
+	for query, want := range map[string]string{
+		"repository hint.destructive=false":                   "list,tag",
+		"repository hint.readOnly=true":                       "list",
+		"repository HINT.readonly=true":                       "",
+		"repository hint.READONLY=1 hint.openWorld=false":     "list",
+		"repository hint.idempotent=true hint.readOnly=false": "tag",
+		"repository hint.destructive=true":                    "delete,push",
+	} {
+		if got := names(query); got != want {
+			t.Errorf("Search(%q) = %s, want %s", query, got, want)
+		}
+	}
+
+	page, _, err := idx.SearchPage("repository hint.destructive=false", 1, "")
+	if err != nil || len(page) != 1 {
+		t.Errorf("SearchPage = %v, %v, want one non-destructive tool", page, err)
+	}
+
+	text, filters := ParseHintFilters("deploy hint.unknown=true hint.destructive=maybe hint.Destructive=false")
+	if text != "deploy hint.unknown=true hint.destructive=maybe" || len(filters) != 1 || filters[HintDestructive] {
+		t.Errorf("ParseHintFilters = %q, %v", text, filters)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	idx := NewInMemoryIndex()
+	categorized := func(name, category string) model.Tool {
+		tool := makeTestTool(name, "ops", "Manage "+name+" resources", nil)
+		tool.Meta = mcp.Meta{"category": category}
+		return tool
+	}
+	mustRegister(t, idx, categorized("pods", "DevOps/Containers"), makeMCPBackend("k8s"))
+	mustRegister(t, idx, categorized("images", "devops/containers/registry"), makeMCPBackend("k8s"))
+	mustRegister(t, idx, categorized("alerts", "devops/monitoring"), makeMCPBackend("k8s"))
+	mustRegister(t, idx, categorized("invoices", "finance"), makeMCPBackend("billing"))
+
+	if err := idx.RegisterCategory(" security//secrets/ "); err != nil {
+		t.Fatalf("RegisterCategory failed: %v", err)
+	}
+	if err := idx.RegisterCategory(" / "); !errors.Is(err, ErrInvalidCategory) {
+		t.Errorf("RegisterCategory(empty) err = %v, want ErrInvalidCategory", err)
+	}
+	if got := idx.RegisteredCategories(); !slices.Equal(got, []string{"security/secrets"}) {
+		t.Errorf("RegisteredCategories = %v, want only the registered path", got)
+	}
+
+	var got []string
+	for _, c := range idx.ListCategories() {
+		got = append(got, fmt.Sprintf("%s(%s) %d/%d %v", c.Path, c.Parent, c.Tools, c.SubtreeTools, c.Registered))
+	}
+	want := []string{
+		"devops() 0/3 false",
+		"devops/containers(devops) 1/2 false",
+		"devops/containers/registry(devops/containers) 1/1 false",
+		"devops/monitoring(devops) 1/1 false",
+		"finance() 1/1 false",
+		"security() 0/0 false",
+		"security/secrets(security) 0/0 true",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ListCategories() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	ids := func(results []Summary) string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.ID
+		}
+		slices.Sort(out)
+		return strings.Join(out, ",")
+	}
+	results, err := idx.Search("manage category=devops/containers", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if got := ids(results); got != "ops:images,ops:pods" {
+		t.Errorf("Search(category=devops/containers) = %s", got)
+	}
+	results, _, err = idx.SearchPage("category=DevOps", 10, "")
+	if err != nil {
+		t.Fatalf("SearchPage failed: %v", err)
+	}
+	if got := ids(results); got != "ops:alerts,ops:images,ops:pods" {
+		t.Errorf("SearchPage(category=DevOps) = %s", got)
+	}
+	if results, _ := idx.Search("category=devops/cont", 10); len(results) != 0 {
+		t.Errorf("partial level matched: %v", results)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("create_issue", "github", "Create an issue", nil), makeMCPBackend("github"))
+	ro := ReadOnly(idx)
+
+	tool := makeTestTool("close_issue", "github", "Close an issue", nil)
+	for name, err := range map[string]error{
+		"RegisterTool":         ro.RegisterTool(tool, makeMCPBackend("github")),
+		"RegisterTools":        ro.RegisterTools([]ToolRegistration{{Tool: tool, Backend: makeMCPBackend("github")}}),
+		"RegisterToolsFromMCP": ro.RegisterToolsFromMCP("github", []model.Tool{tool}),
+		"UnregisterBackend":    ro.UnregisterBackend("github:create_issue", model.BackendKindMCP, "github"),
+	} {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s err = %v, want ErrReadOnly", name, err)
+		}
+	}
+	if _, ok := ro.(MCPReplacer); ok {
+		t.Error("read-only view exposes MCPReplacer")
+	}
+
+	var events []ChangeEvent
+	unsubscribe := OnChange(ro, func(ev ChangeEvent) { events = append(events, ev) })
+	defer unsubscribe()
+	before := Version(ro)
+	mustRegister(t, idx, tool, makeMCPBackend("github"))
+
+	if _, _, err := ro.GetTool("github:close_issue"); err != nil {
+		t.Errorf("GetTool through view: %v", err)
+	}
+	results, err := ro.Search("issue", 10)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Search = %v, %v; want 2 results", results, err)
+	}
+	if Version(ro) <= before || len(events) != 1 {
+		t.Errorf("version %d -> %d, events %d; want the owner's change observed", before, Version(ro), len(events))
+	}
+	if ReadOnly(ro) != ro {
+		t.Error("ReadOnly(ReadOnly(idx)) wrapped twice")
+	}
+}
+
+func TestDisableTool(t *testing.T) {
+	idx := NewInMemoryIndex()
+	mustRegister(t, idx, makeTestTool("create_issue", "github", "Create an issue", nil), makeMCPBackend("github"))
+	mustRegister(t, idx, makeTestTool("close_issue", "github", "Close an issue", nil), makeMCPBackend("github"))
+
+	var events []ChangeEvent
+	unsubscribe := idx.OnChange(func(ev ChangeEvent) { events = append(events, ev) })
+	defer unsubscribe()
+
+	if err := idx.DisableTool("github:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DisableTool(missing) err = %v, want ErrNotFound", err)
+	}
+	if err := idx.DisableTool("github:create_issue"); err != nil {
+		t.Fatalf("DisableTool: %v", err)
+	}
+	if err := idx.DisableTool("github:create_issue"); err != nil {
+		t.Fatalf("DisableTool again: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != ChangeDisabled {
+		t.Errorf("events = %+v, want one ChangeDisabled", events)
+	}
+
+	if _, _, err := idx.GetTool("github:create_issue"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTool(disabled) err = %v, want ErrNotFound", err)
+	}
+	if _, err := idx.GetAllBackends("github:create_issue"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAllBackends(disabled) err = %v, want ErrNotFound", err)
+	}
+	results, err := idx.Search("issue", 10)
+	if err != nil || len(results) != 1 || results[0].ID != "github:close_issue" {
+		t.Errorf("Search = %v, %v; want only close_issue", results, err)
+	}
+	if got := idx.SummariesFor("", []string{"github:create_issue", "github:close_issue"}); len(got) != 1 {
+		t.Errorf("SummariesFor = %v, want the disabled tool skipped", got)
+	}
+	if ids := idx.ToolIDs(); len(ids) != 2 {
+		t.Errorf("ToolIDs = %v, want disabled tools included", ids)
+	}
+	disabled := idx.ListDisabled()
+	if len(disabled) != 1 || disabled[0].ToolID != "github:create_issue" || disabled[0].DisabledAt.IsZero() {
+		t.Errorf("ListDisabled = %+v", disabled)
+	}
+
+	// Re-registration keeps the tool disabled.
+	mustRegister(t, idx, makeTestTool("create_issue", "github", "Create an issue", nil), makeMCPBackend("github2"))
+	if _, _, err := idx.GetTool("github:create_issue"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTool after re-register err = %v, want ErrNotFound", err)
+	}
+
+	if err := idx.EnableTool("github:create_issue"); err != nil {
+		t.Fatalf("EnableTool: %v", err)
+	}
+	backends, err := idx.GetAllBackends("github:create_issue")
+	if err != nil || len(backends) != 2 {
+		t.Errorf("GetAllBackends after enable = %v, %v; want both backends", backends, err)
+	}
+	if results, _ := idx.Search("issue", 10); len(results) != 2 {
+		t.Errorf("Search after enable = %v, want 2 results", results)
+	}
+	if len(idx.ListDisabled()) != 0 {
+		t.Error("ListDisabled not empty after enable")
+	}
+	if last := events[len(events)-1]; last.Type != ChangeEnabled {
+		t.Errorf("last event = %+v, want ChangeEnabled", last)
+	}
+
+	// Removing a disabled tool drops it from ListDisabled.
+	if err := idx.DisableTool("github:close_issue"); err != nil {
+		t.Fatalf("DisableTool: %v", err)
+	}
+	if err := idx.UnregisterBackend("github:close_issue", model.BackendKindMCP, "github"); err != nil {
+		t.Fatalf("UnregisterBackend: %v", err)
+	}
+	if len(idx.ListDisabled()) != 0 {
+		t.Errorf("ListDisabled = %+v after removal", idx.ListDisabled())
+	}
+}
+
+func TestExplainBackend(t *testing.T) {
+	idx := NewInMemoryIndex()
+	tool := makeTestTool("create_issue", "github", "Create an issue", nil)
+	mustRegister(t, idx, tool, makeMCPBackend("primary"))
+	mustRegister(t, idx, tool, makeMCPBackend("secondary"))
+	if err := idx.SetBackendHealth("github:create_issue", "primary", HealthStatus{State: HealthUnreachable}); err != nil {
+		t.Fatalf("SetBackendHealth: %v", err)
+	}
+
+	decision, err := idx.ExplainBackend("github:create_issue")
+	if err != nil {
+		t.Fatalf("ExplainBackend: %v", err)
+	}
+	_, backend, _ := idx.GetTool("github:create_issue")
+	if decision.Selected.MCP.ServerName != backend.MCP.ServerName || len(decision.Candidates) != 2 {
+		t.Fatalf("decision = %+v, want GetTool's backend %v", decision, backend.MCP)
+	}
+	if !decision.Candidates[0].Selected || decision.Candidates[1].Selected {
+		t.Errorf("candidates = %+v, want the first selected", decision.Candidates)
+	}
+	if !strings.Contains(decision.Reason, "first registered") || !strings.Contains(decision.Reason, "primary is unreachable while secondary is healthy") {
+		t.Errorf("reason = %q", decision.Reason)
+	}
+
+	if _, err := idx.ExplainBackend("github:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ExplainBackend(missing) err = %v, want ErrNotFound", err)
+	}
+
+	custom := NewInMemoryIndex(IndexOptions{BackendSelector: func(b []model.ToolBackend) model.ToolBackend { return b[len(b)-1] }})
+	mustRegister(t, custom, tool, makeMCPBackend("primary"))
+	mustRegister(t, custom, tool, makeMCPBackend("secondary"))
+	decision, _ = custom.ExplainBackend("github:create_issue")
+	if !decision.Candidates[1].Selected || decision.Reason != "chosen by the configured BackendSelector" {
+		t.Errorf("custom decision = %+v", decision)
+	}
+}
+
+func TestTagPolicy(t *testing.T) {
+	policy := TagPolicy{
+		PreserveCase: true,
+		Separators:   map[rune]rune{' ': '_', '/': '.'},
+		MaxTagLen:    8,
+		BannedTags:   []string{"Deprecated"},
+	}
+	got := policy.Normalize([]string{" Cloud Storage ", "aws/S3", "Deprecated", "verylongtagname", "aws/S3", "ünï"})
+	want := []string{"Cloud_St", "aws.S3", "verylong", "n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Normalize = %q, want %q", got, want)
+	}
+
+	if got := (TagPolicy{}).Normalize([]string{"Cloud Storage", "a/b"}); !slices.Equal(got, model.NormalizeTags([]string{"Cloud Storage", "a/b"})) {
+		t.Errorf("zero policy = %q, want model.NormalizeTags", got)
+	}
+	if got := (TagPolicy{BannedTags: []string{"Legacy API"}}).Normalize([]string{"legacy-api", "ok"}); !slices.Equal(got, []string{"ok"}) {
+		t.Errorf("banned after normalization = %q", got)
+	}
+
+	idx := NewInMemoryIndex(IndexOptions{TagPolicy: policy, MaxTagsPerTool: 3})
+	tool := makeTestTool("upload", "storage", "Upload an object", []string{"Cloud Storage", "Deprecated", "aws/S3"})
+	mustRegister(t, idx, tool, makeMCPBackend("storage"))
+	results, err := idx.Search("cloud_st", 10)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search = %v, %v; want the tool found by its normalized tag", results, err)
+	}
+	if !slices.Equal(results[0].Tags, []string{"Cloud_St", "aws.S3"}) {
+		t.Errorf("Summary.Tags = %q", results[0].Tags)
+	}
+
+	tool = makeTestTool("download", "storage", "Download an object", []string{"a", "b", "Deprecated", "c"})
+	var limitErr *LimitError
+	if err := idx.RegisterTool(tool, makeMCPBackend("storage")); !errors.As(err, &limitErr) {
+		t.Errorf("RegisterTool err = %v, want tag limit counted before the policy", err)
+	}
+}
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          description: Maximum number of pets
+          schema:
+            type: integer
+      responses:
+        200:
+          description: A page of pets
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  pets:
+                    type: array
+                    items:
+                      $ref: '#/components/schemas/Pet'
+    post:
+      operationId: create pet
+      description: Add a pet to the store.
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "201":
+          description: Created
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    delete:
+      deprecated: true
+      responses:
+        "204":
+          description: Deleted
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      schema:
+        type: string
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Pet'
+`
+
+func TestParseOpenAPI(t *testing.T) {
+	regs, err := ParseOpenAPI([]byte(petstoreSpec), OpenAPIOptions{ProviderID: "petstore", Namespace: "pets", Tags: []string{"rest"}})
+	if err != nil {
+		t.Fatalf("ParseOpenAPI failed: %v", err)
+	}
+	var names []string
+	for _, reg := range regs {
+		names = append(names, reg.Tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "listPets,create_pet,delete_pets_petId" {
+		t.Fatalf("tool names = %s", got)
+	}
+
+	list := regs[0].Tool
+	if list.Description != "List pets" || list.Meta["summary"] != "List pets" {
+		t.Errorf("listPets description = %q, summary = %v", list.Description, list.Meta["summary"])
+	}
+	if !slices.Equal(list.Tags, []string{"pets", "rest"}) || list.Annotations == nil || !list.Annotations.ReadOnlyHint {
+		t.Errorf("listPets tags = %v, annotations = %+v", list.Tags, list.Annotations)
+	}
+	limit := list.InputSchema.(map[string]any)["properties"].(map[string]any)["limit"].(map[string]any)
+	if limit["type"] != "integer" || limit["description"] != "Maximum number of pets" {
+		t.Errorf("limit property = %v", limit)
+	}
+	output, ok := list.OutputSchema.(map[string]any)
+	if !ok || output["type"] != "object" {
+		t.Fatalf("listPets output schema = %v", list.OutputSchema)
+	}
+	pet := output["properties"].(map[string]any)["pets"].(map[string]any)["items"].(map[string]any)
+	if pet["type"] != "object" || len(pet["properties"].(map[string]any)["parent"].(map[string]any)) != 0 {
+		t.Errorf("inlined Pet = %v, want recursive parent cut to an empty schema", pet)
+	}
+	if b := regs[0].Backend; b.Kind != model.BackendKindProvider || *b.Provider != (model.ProviderBackend{ProviderID: "petstore", ToolID: "listPets"}) {
+		t.Errorf("backend = %+v, want petstore provider", b)
+	}
+
+	create := regs[1].Tool.InputSchema.(map[string]any)
+	if !slices.Equal(create["required"].([]string), []string{"body"}) {
+		t.Errorf("create_pet required = %v", create["required"])
+	}
+	if regs[1].Tool.OutputSchema != nil {
+		t.Errorf("create_pet output schema = %v, want none without a JSON body", regs[1].Tool.OutputSchema)
+	}
+
+	del := regs[2].Tool
+	if !slices.Contains(del.Tags, "deprecated") || !del.Annotations.IdempotentHint {
+		t.Errorf("delete tags = %v, annotations = %+v", del.Tags, del.Annotations)
+	}
+	call := del.Meta[OpenAPIMetaKey].(map[string]any)
+	if call["method"] != "DELETE" || call["path"] != "/pets/{petId}" || call["parameters"].(map[string]any)["petId"] != "path" {
+		t.Errorf("delete call meta = %v", call)
+	}
+
+	for name, spec := range map[string]string{
+		"swagger 2":      `swagger: "2.0"`,
+		"bad reference":  "openapi: 3.1.0\npaths:\n  /x:\n    get:\n      parameters:\n        - $ref: '#/components/parameters/Missing'\n",
+		"duplicate name": "openapi: 3.1.0\npaths:\n  /a:\n    get:\n      operationId: same\n  /b:\n    get:\n      operationId: same\n",
+	} {
+		if _, err := ParseOpenAPI([]byte(spec), OpenAPIOptions{ProviderID: "p"}); !errors.Is(err, ErrInvalidOpenAPI) {
+			t.Errorf("%s: error = %v, want ErrInvalidOpenAPI", name, err)
+		}
+	}
+	if _, err := ParseOpenAPI([]byte(petstoreSpec), OpenAPIOptions{}); !errors.Is(err, ErrInvalidOpenAPI) {
+		t.Errorf("missing provider ID: error = %v, want ErrInvalidOpenAPI", err)
+	}
+}
+
+func TestExportFunctionTools(t *testing.T) {
+	idx := NewInMemoryIndex()
+	ids, err := ImportOpenAPI(idx, []byte(petstoreSpec), OpenAPIOptions{ProviderID: "petstore", Namespace: "pets"})
+	if err != nil {
+		t.Fatalf("ImportOpenAPI failed: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != "pets:listPets" {
+		t.Fatalf("imported IDs = %v", ids)
+	}
+	mustRegister(t, idx, makeTestTool("list.v2", "pets", "List pets again", nil), makeMCPBackend("pets"))
+	if err := idx.DisableTool("pets:delete_pets_petId"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+
+	tools, names, err := ExportOpenAITools(idx)
+	if err != nil {
+		t.Fatalf("ExportOpenAITools failed: %v", err)
+	}
+	if len(tools) != 3 || len(names) != 3 {
+		t.Fatalf("exported %d tools, %d names, want 3", len(tools), len(names))
+	}
+	for _, tool := range tools {
+		if tool.Type != "function" || names[tool.Function.Name] == "" {
+			t.Errorf("exported %+v without a tool ID", tool)
+		}
+		if tool.Function.Parameters["type"] != "object" {
+			t.Errorf("%s parameters = %v", tool.Function.Name, tool.Function.Parameters)
+		}
+	}
+	if names["pets__listPets"] != "pets:listPets" || names["pets__list_v2"] != "pets:list.v2" {
+		t.Errorf("function names = %v", names)
+	}
+
+	anthropic, anthropicNames, err := ExportAnthropicTools(idx)
+	if err != nil {
+		t.Fatalf("ExportAnthropicTools failed: %v", err)
+	}
+	if len(anthropic) != 3 || !maps.Equal(anthropicNames, names) || anthropic[2].InputSchema["type"] != "object" {
+		t.Errorf("ExportAnthropicTools = %+v, %v", anthropic, anthropicNames)
+	}
+
+	long := FunctionName("ns:" + strings.Repeat("x", 100))
+	if len(long) != MaxFunctionNameLen || long == FunctionName("ns:"+strings.Repeat("x", 101)) {
+		t.Errorf("FunctionName(long) = %q, want %d unique characters", long, MaxFunctionNameLen)
+	}
+}
+
+func TestSetToolState(t *testing.T) {
+	idx := NewInMemoryIndex()
+	var events []ChangeEvent
+	idx.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	tool := makeTestTool("read", "fs", "Read a file", nil)
+	state := ToolState{Tool: tool, Backends: []model.ToolBackend{makeMCPBackend("s1"), makeMCPBackend("s2")}, Metadata: map[string]string{"team": "fs"}}
+	if err := idx.SetToolState("fs:read", &state); err != nil {
+		t.Fatalf("SetToolState failed: %v", err)
+	}
+	got, err := idx.GetToolState("fs:read")
+	if err != nil || !got.Equal(state) {
+		t.Fatalf("GetToolState = %+v, %v; want %+v", got, err, state)
+	}
+	if err := idx.SetToolState("fs:read", &state); err != nil || len(events) != 1 || events[0].Type != ChangeRegistered {
+		t.Fatalf("events = %+v, %v; want one registration", events, err)
+	}
+
+	// A change of only the disabled state emits only ChangeDisabled.
+	disabledAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	disabled := state
+	disabled.DisabledAt = disabledAt
+	events = nil
+	if err := idx.SetToolState("fs:read", &disabled); err != nil {
+		t.Fatalf("SetToolState(disabled) failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != ChangeDisabled {
+		t.Errorf("events = %+v, want one ChangeDisabled", events)
+	}
+	if list := idx.ListDisabled(); len(list) != 1 || !list[0].DisabledAt.Equal(disabledAt) {
+		t.Errorf("ListDisabled = %v, want the tool disabled at %v", list, disabledAt)
+	}
+
+	// MCP fields and backends change in place, keeping disabled state.
+	changed := ToolState{Tool: makeTestTool("read", "fs", "Read a file, revised", nil), Backends: []model.ToolBackend{makeMCPBackend("s2")}, DisabledAt: disabledAt}
+	if err := idx.SetToolState("fs:read", &changed); err != nil {
+		t.Fatalf("SetToolState(changed) failed: %v", err)
+	}
+	if got, err := idx.GetToolState("fs:read"); err != nil || !got.Equal(changed) {
+		t.Errorf("GetToolState = %+v, %v; want %+v", got, err, changed)
+	}
+	if list := idx.ListDisabled(); len(list) != 1 {
+		t.Errorf("ListDisabled = %v, want the tool still disabled", list)
+	}
+
+	// Changing the tool and enabling it emits both events.
+	events = nil
+	if err := idx.SetToolState("fs:read", &state); err != nil {
+		t.Fatalf("SetToolState(enabled) failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != ChangeUpdated || events[1].Type != ChangeEnabled {
+		t.Errorf("events = %+v, want ChangeUpdated then ChangeEnabled", events)
+	}
+	if list := idx.ListDisabled(); len(list) != 0 {
+		t.Errorf("ListDisabled = %v, want none", list)
+	}
+
+	if err := idx.SetToolState("fs:read", nil); err != nil {
+		t.Fatalf("SetToolState(nil) failed: %v", err)
+	}
+	if _, err := idx.GetToolState("fs:read"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetToolState after removal error = %v, want ErrNotFound", err)
+	}
+	if last := events[len(events)-1]; last.Type != ChangeToolRemoved {
+		t.Errorf("last event = %+v, want ChangeToolRemoved", last)
+	}
+
+	if err := idx.SetToolState("fs:other", &state); !errors.Is(err, ErrInvalidTool) {
+		t.Errorf("mismatched ID error = %v, want ErrInvalidTool", err)
+	}
+	if err := idx.SetToolState("fs:read", &ToolState{Tool: tool}); !errors.Is(err, ErrInvalidBackend) {
+		t.Errorf("no backends error = %v, want ErrInvalidBackend", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := NewInMemoryIndex()
+	b := NewInMemoryIndex()
+	mustRegister(t, a, makeTestTool("create_issue", "github", "Open a new issue", nil), makeMCPBackend("github"))
+	mustRegister(t, b, makeTestTool("scratch", "tmp", "Scratch tool", nil), makeMCPBackend("tmp"))
+	mustRegister(t, b, makeTestTool("create_issue", "github", "Open a new issue", nil), makeMCPBackend("github"))
+	if err := b.UnregisterBackend("tmp:scratch", model.BackendKindMCP, "tmp"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if a.Version() == b.Version() {
+		t.Fatalf("versions both %d, want them to differ", a.Version())
+	}
+	if fa, fb := a.Fingerprint(), b.Fingerprint(); fa == "" || fa != fb {
+		t.Fatalf("Fingerprint() = %q and %q, want equal for the same tools", fa, fb)
+	}
+
+	before := a.Fingerprint()
+	if err := a.DisableTool("github:create_issue"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	if a.Fingerprint() == before {
+		t.Error("Fingerprint() unchanged after DisableTool")
+	}
+	if got := Fingerprint(ReadOnly(a)); got != a.Fingerprint() {
+		t.Errorf("Fingerprint(ReadOnly) = %q, want %q", got, a.Fingerprint())
+	}
+}
@@ -0,0 +1,159 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrLimitExceeded is returned, wrapped in a *LimitError, when a
+// registration would exceed a catalog limit (see IndexOptions.MaxTools).
+var ErrLimitExceeded = errcode.New(errcode.LimitExceeded, "catalog limit exceeded")
+
+// Catalog limit names reported in LimitError.Limit and by LimitReporter.
+const (
+	LimitTools             = "tools"
+	LimitToolsPerNamespace = "tools_per_namespace"
+	LimitTagsPerTool       = "tags_per_tool"
+	LimitDescriptionBytes  = "description_bytes"
+	LimitDocBytes          = "doc_bytes" // see tooldoc.StoreOptions.MaxDocBytes
+)
+
+// LimitError describes a registration rejected by a catalog limit. It
+// matches ErrLimitExceeded with errors.Is.
+type LimitError struct {
+	// Limit is the exceeded limit, such as LimitTools.
+	Limit string
+	// Max is the configured maximum.
+	Max int
+	// ToolID is the rejected tool.
+	ToolID string
+	// Namespace is set for LimitToolsPerNamespace.
+	Namespace string
+}
+
+func (e *LimitError) Error() string {
+	if e.Namespace != "" {
+		return fmt.Sprintf("%v: tool %q: %s in namespace %q exceeds %d", ErrLimitExceeded, e.ToolID, e.Limit, e.Namespace, e.Max)
+	}
+	return fmt.Sprintf("%v: tool %q: %s exceeds %d", ErrLimitExceeded, e.ToolID, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// LimitReporter is implemented by indexes and doc stores that enforce
+// catalog limits, for metrics on rejected registrations.
+//
+// Contract:
+// - Concurrency: implementations must be safe for concurrent use.
+// - Ownership: the returned map is a copy the caller may modify.
+type LimitReporter interface {
+	// LimitRejections returns the number of registrations each limit has
+	// rejected since creation, keyed by limit name.
+	LimitRejections() map[string]uint64
+}
+
+// limitCounter counts rejections per limit.
+type limitCounter struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// reject counts err if it is a *LimitError and returns it.
+func (c *limitCounter) reject(err error) error {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		c.mu.Lock()
+		if c.counts == nil {
+			c.counts = make(map[string]uint64)
+		}
+		c.counts[limitErr.Limit]++
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *limitCounter) snapshot() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.counts)
+}
+
+// LimitRejections returns the number of registrations rejected by each
+// catalog limit since the index was created.
+func (idx *InMemoryIndex) LimitRejections() map[string]uint64 {
+	out := idx.rejections.snapshot()
+	if out == nil {
+		out = make(map[string]uint64)
+	}
+	return out
+}
+
+// checkToolLimits checks the per-tool limits, which do not depend on the
+// rest of the catalog. Tags are counted as registered, so an oversized tag
+// list is rejected before any work is spent normalizing it.
+func (idx *InMemoryIndex) checkToolLimits(tool model.Tool) error {
+	if idx.maxTagsPerTool > 0 && len(tool.Tags) > idx.maxTagsPerTool {
+		return &LimitError{Limit: LimitTagsPerTool, Max: idx.maxTagsPerTool, ToolID: tool.ToolID()}
+	}
+	if idx.maxDescriptionLen > 0 && len(tool.Description) > idx.maxDescriptionLen {
+		return &LimitError{Limit: LimitDescriptionBytes, Max: idx.maxDescriptionLen, ToolID: tool.ToolID()}
+	}
+	return nil
+}
+
+// checkGrowthLocked checks the catalog-wide limits for registering tool,
+// given whether it is already registered and its current namespace. Limits
+// only block growth: a catalog already over a lowered limit keeps its
+// tools. Must be called with idx.mu held.
+func (idx *InMemoryIndex) checkGrowthLocked(tool model.Tool, exists bool, namespace string) error {
+	if !exists && idx.maxTools > 0 && len(idx.tools) >= idx.maxTools {
+		return &LimitError{Limit: LimitTools, Max: idx.maxTools, ToolID: tool.ToolID()}
+	}
+	if idx.maxToolsPerNamespace > 0 && (!exists || namespace != tool.Namespace) &&
+		idx.namespaceCounts[tool.Namespace] >= idx.maxToolsPerNamespace {
+		return &LimitError{Limit: LimitToolsPerNamespace, Max: idx.maxToolsPerNamespace, ToolID: tool.ToolID(), Namespace: tool.Namespace}
+	}
+	return nil
+}
+
+// checkReplaceLimitsLocked checks the catalog-wide limits for a
+// ReplaceToolsFromMCP that removes the tools in removed and registers
+// incoming. Must be called with idx.mu held.
+func (idx *InMemoryIndex) checkReplaceLimitsLocked(incoming map[string]model.Tool, ids, removed []string) error {
+	if idx.maxTools <= 0 && idx.maxToolsPerNamespace <= 0 {
+		return nil
+	}
+	total := len(idx.tools) - len(removed)
+	counts := maps.Clone(idx.namespaceCounts)
+	for _, id := range removed {
+		counts[idx.tools[id].tool.Namespace]--
+	}
+	for _, id := range ids {
+		tool := incoming[id]
+		record, exists := idx.tools[id]
+		if !exists {
+			total++
+			if idx.maxTools > 0 && total > idx.maxTools && total > len(idx.tools) {
+				return &LimitError{Limit: LimitTools, Max: idx.maxTools, ToolID: id}
+			}
+		} else if record.tool.Namespace == tool.Namespace {
+			continue
+		} else {
+			counts[record.tool.Namespace]--
+		}
+		counts[tool.Namespace]++
+		if idx.maxToolsPerNamespace > 0 && counts[tool.Namespace] > idx.maxToolsPerNamespace &&
+			counts[tool.Namespace] > idx.namespaceCounts[tool.Namespace] {
+			return &LimitError{Limit: LimitToolsPerNamespace, Max: idx.maxToolsPerNamespace, ToolID: id, Namespace: tool.Namespace}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrInvalidMaintenanceWindow is returned for malformed maintenance windows.
+var ErrInvalidMaintenanceWindow = errcode.New(errcode.InvalidArgument, "invalid maintenance window")
+
+// MaintenanceWindow marks a namespace or backend as down for planned work.
+// Exactly one of Namespace or Backend must be set.
+type MaintenanceWindow struct {
+	// ID uniquely identifies the window.
+	ID string `json:"id"`
+
+	// Namespace affects every tool in the namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Backend affects tools whose default backend has this identifier:
+	// the MCP server name, local handler name, or provider ID.
+	Backend string `json:"backend,omitempty"`
+
+	// Start and End bound the window. A zero End means open-ended.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitzero"`
+
+	// Message is surfaced as Summary.DegradedReason.
+	Message string `json:"message,omitempty"`
+}
+
+// MaintenanceScheduler is an optional interface for indexes that track
+// maintenance windows.
+//
+// Contract:
+// - Methods must be safe for concurrent use.
+// - AddMaintenanceWindow replaces a window with the same ID.
+// - RemoveMaintenanceWindow returns ErrNotFound for unknown IDs.
+// - MaintenanceWindows returns windows ordered by start time, then ID.
+type MaintenanceScheduler interface {
+	AddMaintenanceWindow(w MaintenanceWindow) error
+	RemoveMaintenanceWindow(id string) error
+	MaintenanceWindows() []MaintenanceWindow
+	ActiveMaintenance(toolID string) (MaintenanceWindow, bool)
+}
+
+// Active reports whether the window covers at.
+func (w MaintenanceWindow) Active(at time.Time) bool {
+	if at.Before(w.Start) {
+		return false
+	}
+	return w.End.IsZero() || at.Before(w.End)
+}
+
+// Affects reports whether the window applies to a tool in namespace whose
+// default backend is backend.
+func (w MaintenanceWindow) Affects(namespace string, backend model.ToolBackend) bool {
+	if w.Namespace != "" {
+		return w.Namespace == namespace
+	}
+	return w.Backend != "" && w.Backend == backendName(backend)
+}
+
+func (w MaintenanceWindow) validate() error {
+	switch {
+	case w.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidMaintenanceWindow)
+	case (w.Namespace == "") == (w.Backend == ""):
+		return fmt.Errorf("%w: exactly one of namespace or backend is required", ErrInvalidMaintenanceWindow)
+	case w.Start.IsZero():
+		return fmt.Errorf("%w: start is required", ErrInvalidMaintenanceWindow)
+	case !w.End.IsZero() && !w.End.After(w.Start):
+		return fmt.Errorf("%w: end must be after start", ErrInvalidMaintenanceWindow)
+	}
+	return nil
+}
+
+// AddMaintenanceWindow registers or replaces a maintenance window. While it is
+// active, affected tools are returned from Search with Summary.Degraded set.
+func (idx *InMemoryIndex) AddMaintenanceWindow(w MaintenanceWindow) error {
+	if err := w.validate(); err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.maintenance == nil {
+		idx.maintenance = make(map[string]MaintenanceWindow)
+	}
+	idx.maintenance[w.ID] = w
+	return nil
+}
+
+// RemoveMaintenanceWindow deletes a maintenance window.
+func (idx *InMemoryIndex) RemoveMaintenanceWindow(id string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.maintenance[id]; !ok {
+		return fmt.Errorf("%w: maintenance window %s", ErrNotFound, id)
+	}
+	delete(idx.maintenance, id)
+	return nil
+}
+
+// MaintenanceWindows returns all registered windows ordered by start time, then ID.
+func (idx *InMemoryIndex) MaintenanceWindows() []MaintenanceWindow {
+	idx.mu.RLock()
+	out := make([]MaintenanceWindow, 0, len(idx.maintenance))
+	for _, w := range idx.maintenance {
+		out = append(out, w)
+	}
+	idx.mu.RUnlock()
+	sortWindows(out)
+	return out
+}
+
+// ActiveMaintenance returns the active window affecting a tool, if any.
+// When several windows apply, the one that started first is returned.
+func (idx *InMemoryIndex) ActiveMaintenance(toolID string) (MaintenanceWindow, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.activeMaintenanceLocked(toolID, idx.now())
+}
+
+// activeMaintenanceLocked finds the active window for toolID at the given
+// time. Caller must hold idx.mu.
+func (idx *InMemoryIndex) activeMaintenanceLocked(toolID string, at time.Time) (MaintenanceWindow, bool) {
+	if len(idx.maintenance) == 0 {
+		return MaintenanceWindow{}, false
+	}
+	record, ok := idx.tools[toolID]
+	if !ok {
+		return MaintenanceWindow{}, false
+	}
+	backend := idx.backendSelector(record.backends)
+
+	var matches []MaintenanceWindow
+	for _, w := range idx.maintenance {
+		if w.Active(at) && w.Affects(record.tool.Namespace, backend) {
+			matches = append(matches, w)
+		}
+	}
+	if len(matches) == 0 {
+		return MaintenanceWindow{}, false
+	}
+	sortWindows(matches)
+	return matches[0], true
+}
+
+// markDegraded sets Degraded on summaries affected by an active window and
+// Health on summaries of tools with reported backend health.
+// Summaries are copied before modification.
+func (idx *InMemoryIndex) markDegraded(results []Summary) []Summary {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	at := idx.now()
+	for i := range results {
+		if w, ok := idx.activeMaintenanceLocked(results[i].ID, at); ok {
+			results[i].Degraded = true
+			results[i].DegradedReason = w.Message
+		}
+		if record, ok := idx.tools[results[i].ID]; ok && len(record.health) > 0 {
+			results[i].Health, results[i].HealthCheckedAt = record.bestHealth()
+		}
+	}
+	return results
+}
+
+// backendName returns the identifier maintenance windows match against.
+func backendName(backend model.ToolBackend) string {
+	switch backend.Kind {
+	case model.BackendKindMCP:
+		if backend.MCP != nil {
+			return backend.MCP.ServerName
+		}
+	case model.BackendKindProvider:
+		if backend.Provider != nil {
+			return backend.Provider.ProviderID
+		}
+	case model.BackendKindLocal:
+		if backend.Local != nil {
+			return backend.Local.Name
+		}
+	}
+	return ""
+}
+
+func sortWindows(ws []MaintenanceWindow) {
+	sort.Slice(ws, func(i, j int) bool {
+		if ws[i].Start.Equal(ws[j].Start) {
+			return ws[i].ID < ws[j].ID
+		}
+		return ws[i].Start.Before(ws[j].Start)
+	})
+}
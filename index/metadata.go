@@ -0,0 +1,91 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// MetadataFilterPrefix marks a metadata filter term in a search query.
+// A query term of the form "metadata.<key>=<value>" restricts results to
+// tools whose Metadata[key] equals value; the term itself is not searched.
+const MetadataFilterPrefix = "metadata."
+
+// ErrInvalidMetadata is returned when metadata keys are empty or malformed.
+var ErrInvalidMetadata = fmt.Errorf("%w: invalid metadata", ErrInvalidTool)
+
+// RegisterToolWithMetadata registers a tool like RegisterTool and replaces its
+// custom metadata. Metadata is an organization-defined label set (ownership,
+// compliance, cost center) kept separate from MCP Meta; it is surfaced in
+// Summary.Metadata and filterable with "metadata.<key>=<value>" query terms.
+//
+// A nil map clears existing metadata. Keys must be non-empty and must not
+// contain whitespace or "=".
+func (idx *InMemoryIndex) RegisterToolWithMetadata(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error {
+	if err := validateMetadata(metadata); err != nil {
+		return err
+	}
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return idx.registerTool(tool, backend, maps.Clone(metadata))
+}
+
+// GetMetadata returns a copy of the custom metadata for a tool.
+func (idx *InMemoryIndex) GetMetadata(id string) (map[string]string, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.tools[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return maps.Clone(record.metadata), nil
+}
+
+// ParseMetadataFilters splits a search query into free text and metadata
+// filters. Terms of the form "metadata.<key>=<value>" become filters; all
+// other terms are returned, space-joined, as the text query.
+func ParseMetadataFilters(query string) (string, map[string]string) {
+	fields := strings.Fields(query)
+	var filters map[string]string
+	text := make([]string, 0, len(fields))
+	for _, field := range fields {
+		rest, ok := strings.CutPrefix(field, MetadataFilterPrefix)
+		if ok {
+			if key, value, found := strings.Cut(rest, "="); found && key != "" {
+				if filters == nil {
+					filters = make(map[string]string)
+				}
+				filters[key] = value
+				continue
+			}
+		}
+		text = append(text, field)
+	}
+	if filters == nil {
+		return query, nil
+	}
+	return strings.Join(text, " "), filters
+}
+
+// MatchesMetadata reports whether metadata contains every filter key with an
+// equal value.
+func MatchesMetadata(metadata, filters map[string]string) bool {
+	for key, want := range filters {
+		if got, ok := metadata[key]; !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+func validateMetadata(metadata map[string]string) error {
+	for key := range metadata {
+		if key == "" || strings.ContainsAny(key, "= \t\r\n") {
+			return fmt.Errorf("%w: key %q", ErrInvalidMetadata, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package index
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/model"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrInvalidOpenAPI is returned for OpenAPI documents that cannot be
+// imported.
+var ErrInvalidOpenAPI = fmt.Errorf("%w: invalid OpenAPI document", ErrInvalidTool)
+
+// OpenAPIMetaKey is the Meta key under which imported tools record how to
+// call their operation: the HTTP "method", the "path" template, and
+// "parameters", mapping each input property to its location ("path",
+// "query", "header", "cookie", or "body").
+const OpenAPIMetaKey = "openapi"
+
+// maxToolNameLen matches the tool name limit of model.Tool.Validate.
+const maxToolNameLen = 128
+
+// openAPIMethods are the operation fields of an OpenAPI path item, in the
+// order tools are created for them.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPIOptions configures ParseOpenAPI and ImportOpenAPI.
+type OpenAPIOptions struct {
+	// ProviderID is the provider that executes imported operations: each
+	// tool is backed by model.NewProviderBackend(ProviderID, name), where
+	// name is the tool name. Required.
+	ProviderID string
+
+	// Namespace is the namespace of the imported tools. Default: none.
+	Namespace string
+
+	// Tags are added to every tool after the operation's own tags.
+	Tags []string
+}
+
+// ParseOpenAPI converts each operation of an OpenAPI 3 document, in JSON or
+// YAML, to a tool registration, ordered by path and then method:
+//
+//   - The name is the operationId, with characters tool names do not allow
+//     replaced by "_", or the method and path when there is none.
+//   - The description is the operation description, falling back to its
+//     summary, which is also used as Meta["summary"].
+//   - Parameters become input properties, and a JSON request body becomes
+//     the "body" property. Local $refs are inlined; recursive ones are cut
+//     to an empty schema.
+//   - A JSON object schema of the first 2xx response becomes the output
+//     schema.
+//   - Safe methods (GET, HEAD, OPTIONS, TRACE) are annotated read-only,
+//     PUT and DELETE idempotent, and deprecated operations are tagged
+//     "deprecated".
+//
+// Meta[OpenAPIMetaKey] records how to call the operation.
+func ParseOpenAPI(spec []byte, opts OpenAPIOptions) ([]ToolRegistration, error) {
+	if opts.ProviderID == "" {
+		return nil, fmt.Errorf("%w: provider ID is required", ErrInvalidOpenAPI)
+	}
+	var raw any
+	if err := yaml.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOpenAPI, err)
+	}
+	doc, _ := stringKeys(raw).(map[string]any)
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidOpenAPI, version)
+	}
+
+	refs := openAPIRefs{root: doc}
+	paths, _ := doc["paths"].(map[string]any)
+	var regs []ToolRegistration
+	seen := make(map[string]string)
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		resolved, err := refs.inline(paths[path], nil)
+		if err != nil {
+			return nil, err
+		}
+		item, _ := resolved.(map[string]any)
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			tool, err := openAPITool(path, method, op, item["parameters"], opts)
+			if err != nil {
+				return nil, err
+			}
+			id := tool.ToolID()
+			if prev, dup := seen[id]; dup {
+				return nil, fmt.Errorf("%w: %s %s and %s map to the same tool %s", ErrInvalidOpenAPI,
+					strings.ToUpper(method), path, prev, id)
+			}
+			seen[id] = strings.ToUpper(method) + " " + path
+			regs = append(regs, ToolRegistration{
+				Tool:    tool,
+				Backend: model.NewProviderBackend(opts.ProviderID, tool.Name),
+			})
+		}
+	}
+	return regs, nil
+}
+
+// ImportOpenAPI registers the operations of an OpenAPI 3 document with idx
+// (see ParseOpenAPI) and returns the registered tool IDs.
+func ImportOpenAPI(idx Index, spec []byte, opts OpenAPIOptions) ([]string, error) {
+	regs, err := ParseOpenAPI(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := idx.RegisterTools(regs); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(regs))
+	for i, reg := range regs {
+		ids[i] = reg.Tool.ToolID()
+	}
+	return ids, nil
+}
+
+// openAPITool converts one operation, whose $refs are already inlined.
+func openAPITool(path, method string, op map[string]any, pathParams any, opts OpenAPIOptions) (model.Tool, error) {
+	operationID, _ := op["operationId"].(string)
+	name := sanitizeToolName(operationID)
+	if name == "" {
+		name = sanitizeToolName(method + "_" + path)
+	}
+	if name == "" {
+		return model.Tool{}, fmt.Errorf("%w: no tool name for %s %s", ErrInvalidOpenAPI, strings.ToUpper(method), path)
+	}
+
+	properties := make(map[string]any)
+	locations := make(map[string]any)
+	var required []string
+	for _, param := range mergeOpenAPIParameters(pathParams, op["parameters"]) {
+		paramName, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		schema, _ := param["schema"].(map[string]any)
+		prop := maps.Clone(schema)
+		if prop == nil {
+			prop = map[string]any{}
+		}
+		if desc, ok := param["description"].(string); ok && prop["description"] == nil {
+			prop["description"] = desc
+		}
+		properties[paramName] = prop
+		locations[paramName] = in
+		if in == "path" || param["required"] == true {
+			required = append(required, paramName)
+		}
+	}
+	if body, ok := op["requestBody"].(map[string]any); ok {
+		if schema := openAPIJSONSchema(body["content"]); schema != nil {
+			properties["body"] = schema
+			locations["body"] = "body"
+			if body["required"] == true {
+				required = append(required, "body")
+			}
+		}
+	}
+	input := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		input["required"] = required
+	}
+
+	summary, _ := op["summary"].(string)
+	description, _ := op["description"].(string)
+	if description == "" {
+		description = summary
+	}
+	if description == "" {
+		description = strings.ToUpper(method) + " " + path
+	}
+	meta := mcp.Meta{OpenAPIMetaKey: map[string]any{
+		"method":     strings.ToUpper(method),
+		"path":       path,
+		"parameters": locations,
+	}}
+	if summary != "" {
+		meta["summary"] = summary
+	}
+
+	var tags []string
+	if opTags, ok := op["tags"].([]any); ok {
+		for _, tag := range opTags {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+	if op["deprecated"] == true {
+		tags = append(tags, "deprecated")
+	}
+	tags = append(tags, opts.Tags...)
+
+	tool := model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: input,
+			Annotations: openAPIAnnotations(method),
+			Meta:        meta,
+		},
+		Namespace: opts.Namespace,
+		Tags:      tags,
+	}
+	if output := openAPIOutputSchema(op["responses"]); output != nil {
+		tool.OutputSchema = output
+	}
+	return tool, nil
+}
+
+// mergeOpenAPIParameters returns the path item parameters overridden by the
+// operation's, which replace those with the same name and location.
+func mergeOpenAPIParameters(pathParams, opParams any) []map[string]any {
+	var out []map[string]any
+	add := func(params any) {
+		list, _ := params.([]any)
+		for _, p := range list {
+			param, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			if name == "" || in == "" {
+				continue
+			}
+			i := slices.IndexFunc(out, func(o map[string]any) bool { return o["name"] == name && o["in"] == in })
+			if i >= 0 {
+				out[i] = param
+			} else {
+				out = append(out, param)
+			}
+		}
+	}
+	add(pathParams)
+	add(opParams)
+	return out
+}
+
+// openAPIJSONSchema returns the schema of the JSON media type in a content
+// map, if any.
+func openAPIJSONSchema(content any) map[string]any {
+	media, _ := content.(map[string]any)
+	for _, mediaType := range slices.Sorted(maps.Keys(media)) {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			entry, _ := media[mediaType].(map[string]any)
+			schema, _ := entry["schema"].(map[string]any)
+			return schema
+		}
+	}
+	return nil
+}
+
+// openAPIOutputSchema returns the JSON schema of the first 2xx response
+// when it describes an object, as MCP output schemas must.
+func openAPIOutputSchema(responses any) map[string]any {
+	codes, _ := responses.(map[string]any)
+	for _, code := range slices.Sorted(maps.Keys(codes)) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		response, _ := codes[code].(map[string]any)
+		schema := openAPIJSONSchema(response["content"])
+		if schema == nil || schema["type"] != "object" {
+			return nil
+		}
+		return schema
+	}
+	return nil
+}
+
+// openAPIAnnotations derives behavior hints from HTTP method semantics.
+func openAPIAnnotations(method string) *mcp.ToolAnnotations {
+	switch method {
+	case "get", "head", "options", "trace":
+		return &mcp.ToolAnnotations{ReadOnlyHint: true}
+	case "put", "delete":
+		return &mcp.ToolAnnotations{IdempotentHint: true}
+	default:
+		return &mcp.ToolAnnotations{}
+	}
+}
+
+// sanitizeToolName replaces characters tool names do not allow with "_",
+// collapsing runs and trimming them from the ends, and caps the length.
+func sanitizeToolName(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range s {
+		valid := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '-' || r == '.'
+		if !valid {
+			underscore = b.Len() > 0
+			continue
+		}
+		if underscore {
+			b.WriteByte('_')
+			underscore = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if len(name) > maxToolNameLen {
+		name = strings.TrimRight(name[:maxToolNameLen], "_")
+	}
+	return name
+}
+
+// stringKeys converts the maps of a decoded YAML document to
+// map[string]any, since YAML keys such as response codes decode as ints.
+func stringKeys(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = stringKeys(child)
+		}
+		return v
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			out[fmt.Sprint(key)] = stringKeys(child)
+		}
+		return out
+	case []any:
+		for i, child := range v {
+			v[i] = stringKeys(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// openAPIRefs inlines local references of an OpenAPI document.
+type openAPIRefs struct {
+	root map[string]any
+}
+
+// inline returns node with every local "$ref" replaced by its target.
+// stack holds the references being expanded; a reference to one of them
+// is recursive and becomes an empty schema.
+func (r openAPIRefs) inline(node any, stack []string) (any, error) {
+	switch v := node.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if slices.Contains(stack, ref) {
+				return map[string]any{}, nil
+			}
+			target, err := r.lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+			return r.inline(target, append(stack, ref))
+		}
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			inlined, err := r.inline(child, stack)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = inlined
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			inlined, err := r.inline(child, stack)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = inlined
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// lookup resolves a local JSON pointer reference such as
+// "#/components/schemas/Pet".
+func (r openAPIRefs) lookup(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported reference %q", ErrInvalidOpenAPI, ref)
+	}
+	var node any = r.root
+	for token := range strings.SplitSeq(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: unresolved reference %q", ErrInvalidOpenAPI, ref)
+		}
+		if node, ok = m[token]; !ok {
+			return nil, fmt.Errorf("%w: unresolved reference %q", ErrInvalidOpenAPI, ref)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,83 @@
+package index
+
+import (
+	"context"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrPushdownUnsupported is returned by SearchPushdown implementations that
+// cannot execute a particular query natively. Callers fall back to Search.
+var ErrPushdownUnsupported = errcode.New(errcode.Unsupported, "search pushdown unsupported")
+
+// PushdownQuery is a parsed search request for SearchPushdown.
+type PushdownQuery struct {
+	// Principal is the caller, for rollout visibility. Empty searches as an
+	// anonymous caller, like Search.
+	Principal string
+
+	// Text is the free-text query, with metadata filters removed and
+	// synonyms expanded.
+	Text string
+
+	// Filters are the "metadata.<key>=<value>" filters parsed from the
+	// query (see ParseMetadataFilters). Every filter must match.
+	Filters map[string]string
+
+	// Vector is the embedded query, set by callers ranking semantically.
+	// Implementations without vector support should return
+	// ErrPushdownUnsupported when it is set.
+	Vector []float32
+
+	// Limit is the maximum number of results.
+	Limit int
+}
+
+// PushdownResult is one ranked result of a pushed-down search.
+type PushdownResult struct {
+	Summary Summary
+
+	// Score is the implementation's relevance score; higher is better.
+	Score float64
+}
+
+// SearchPushdown is implemented by database-backed indexes that execute
+// queries and filters natively (SQL full-text search, pgvector) instead of
+// materializing every SearchDoc for an in-process Searcher. Discovery
+// prefers it when available.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Visibility: results must honor rollouts for q.Principal and carry
+//     Degraded flags, exactly as Search would.
+//   - Ordering: results are ordered by Score descending with deterministic
+//     tie-breaking, and at most q.Limit long; q.Limit <= 0 returns none.
+//   - Errors: return ErrPushdownUnsupported (possibly wrapped) to make the
+//     caller fall back to Search; other errors are returned to the caller.
+type SearchPushdown interface {
+	PushdownSearch(ctx context.Context, q PushdownQuery) ([]PushdownResult, error)
+}
+
+// SummariesFor returns the summaries of ids visible to principal, in the
+// given order and marked Degraded like Search results. Unknown and hidden
+// IDs are skipped. Database-backed indexes that rank natively use it to
+// turn ranked IDs into results.
+func (idx *InMemoryIndex) SummariesFor(principal string, ids []string) []Summary {
+	idx.mu.RLock()
+	summaries := make([]Summary, 0, len(ids))
+	for _, id := range ids {
+		record, ok := idx.tools[id]
+		if !ok || record.disabled() {
+			continue
+		}
+		if r, ok := idx.rollouts[id]; ok && !r.VisibleTo(principal) {
+			continue
+		}
+		if idx.excludeUnreachable && record.unreachable() {
+			continue
+		}
+		summaries = append(summaries, record.summary)
+	}
+	idx.mu.RUnlock()
+	return idx.markDegraded(summaries)
+}
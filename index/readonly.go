@@ -0,0 +1,85 @@
+package index
+
+import (
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrReadOnly is returned by the mutating methods of an index returned by
+// ReadOnly.
+var ErrReadOnly = errcode.New(errcode.PermissionDenied, "index is read-only")
+
+// ReadOnly returns a view of idx for sharing with plugins and other
+// consumers that should only search and look up tools. Registration and
+// unregistration fail with ErrReadOnly, while reads go to idx, so the view
+// sees every change its owner makes.
+//
+// Of the optional capabilities, the view implements only the read-side
+// Versioner and ChangeNotifier, falling back like Version and OnChange when
+// idx lacks them. Mutating capabilities such as MCPReplacer are hidden.
+// Wrapping a read-only view returns it unchanged.
+func ReadOnly(idx Index) Index {
+	if ro, ok := idx.(readOnlyIndex); ok {
+		return ro
+	}
+	return readOnlyIndex{idx: idx}
+}
+
+type readOnlyIndex struct {
+	idx Index
+}
+
+func (readOnlyIndex) RegisterTool(model.Tool, model.ToolBackend) error { return ErrReadOnly }
+
+func (readOnlyIndex) RegisterTools([]ToolRegistration) error { return ErrReadOnly }
+
+func (readOnlyIndex) RegisterToolsFromMCP(string, []model.Tool) error { return ErrReadOnly }
+
+func (readOnlyIndex) UnregisterBackend(string, model.BackendKind, string) error { return ErrReadOnly }
+
+func (r readOnlyIndex) GetTool(id string) (model.Tool, model.ToolBackend, error) {
+	return r.idx.GetTool(id)
+}
+
+func (r readOnlyIndex) GetAllBackends(id string) ([]model.ToolBackend, error) {
+	return r.idx.GetAllBackends(id)
+}
+
+func (r readOnlyIndex) Search(query string, limit int) ([]Summary, error) {
+	return r.idx.Search(query, limit)
+}
+
+func (r readOnlyIndex) SearchPage(query string, limit int, cursor string) ([]Summary, string, error) {
+	return r.idx.SearchPage(query, limit, cursor)
+}
+
+func (r readOnlyIndex) ListNamespaces() ([]string, error) {
+	return r.idx.ListNamespaces()
+}
+
+func (r readOnlyIndex) ListNamespacesPage(limit int, cursor string) ([]string, string, error) {
+	return r.idx.ListNamespacesPage(limit, cursor)
+}
+
+func (r readOnlyIndex) Version() uint64 {
+	return Version(r.idx)
+}
+
+func (r readOnlyIndex) Fingerprint() string {
+	return Fingerprint(r.idx)
+}
+
+func (r readOnlyIndex) NormalizeTags(tags []string) []string {
+	return NormalizeTags(r.idx, tags)
+}
+
+func (r readOnlyIndex) OnChange(listener ChangeListener) (unsubscribe func()) {
+	return OnChange(r.idx, listener)
+}
+
+var (
+	_ Versioner      = readOnlyIndex{}
+	_ Fingerprinter  = readOnlyIndex{}
+	_ TagNormalizer  = readOnlyIndex{}
+	_ ChangeNotifier = readOnlyIndex{}
+)
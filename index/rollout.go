@@ -0,0 +1,171 @@
+package index
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidRollout is returned for malformed rollouts.
+var ErrInvalidRollout = errcode.New(errcode.InvalidArgument, "invalid rollout")
+
+// Rollout limits the visibility of a newly introduced tool to a fraction of
+// principals (agents, sessions, or tenants). Tools without a rollout are
+// visible to everyone.
+type Rollout struct {
+	// ToolID is the canonical ID of the tool under rollout.
+	ToolID string `json:"toolId"`
+
+	// Percent is the share of principals (0-100) that see the tool. Principals
+	// are bucketed by a stable hash of the tool ID and principal, so a given
+	// principal keeps its visibility as Percent ramps up.
+	Percent int `json:"percent"`
+
+	// Principals always see the tool regardless of Percent.
+	Principals []string `json:"principals,omitempty"`
+}
+
+// VisibleTo reports whether principal sees the tool. The empty principal
+// (anonymous callers, including Search and SearchPage) only sees tools rolled
+// out to 100% or explicitly allowlisted with "".
+func (r Rollout) VisibleTo(principal string) bool {
+	if slices.Contains(r.Principals, principal) {
+		return true
+	}
+	if r.Percent >= 100 {
+		return true
+	}
+	if principal == "" || r.Percent <= 0 {
+		return false
+	}
+	return rolloutBucket(r.ToolID, principal) < r.Percent
+}
+
+func (r Rollout) validate() error {
+	switch {
+	case r.ToolID == "":
+		return fmt.Errorf("%w: tool id is required", ErrInvalidRollout)
+	case r.Percent < 0 || r.Percent > 100:
+		return fmt.Errorf("%w: percent %d out of range [0, 100]", ErrInvalidRollout, r.Percent)
+	}
+	return nil
+}
+
+// rolloutBucket maps a principal to a stable bucket in [0, 100) per tool.
+func rolloutBucket(toolID, principal string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(toolID))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(principal))
+	return int(h.Sum32() % 100)
+}
+
+// SetRollout registers or replaces the rollout for a tool. The tool need not
+// be registered yet, so a rollout can be staged before the tool is introduced.
+// Outstanding pagination cursors are invalidated.
+func (idx *InMemoryIndex) SetRollout(r Rollout) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	r.Principals = slices.Clone(r.Principals)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.rollouts == nil {
+		idx.rollouts = make(map[string]Rollout)
+	}
+	idx.rollouts[r.ToolID] = r
+	idx.markSearchDocsDirtyLocked()
+	return nil
+}
+
+// RampRollout changes the visibility percentage of an existing rollout.
+func (idx *InMemoryIndex) RampRollout(toolID string, percent int) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	r, ok := idx.rollouts[toolID]
+	if !ok {
+		return fmt.Errorf("%w: rollout %s", ErrNotFound, toolID)
+	}
+	r.Percent = percent
+	if err := r.validate(); err != nil {
+		return err
+	}
+	idx.rollouts[toolID] = r
+	idx.markSearchDocsDirtyLocked()
+	return nil
+}
+
+// FinalizeRollout removes the rollout for a tool, making it visible to
+// everyone.
+func (idx *InMemoryIndex) FinalizeRollout(toolID string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.rollouts[toolID]; !ok {
+		return fmt.Errorf("%w: rollout %s", ErrNotFound, toolID)
+	}
+	delete(idx.rollouts, toolID)
+	idx.markSearchDocsDirtyLocked()
+	return nil
+}
+
+// GetRollout returns the rollout for a tool, if any.
+func (idx *InMemoryIndex) GetRollout(toolID string) (Rollout, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	r, ok := idx.rollouts[toolID]
+	r.Principals = slices.Clone(r.Principals)
+	return r, ok
+}
+
+// Rollouts returns all rollouts ordered by tool ID.
+func (idx *InMemoryIndex) Rollouts() []Rollout {
+	idx.mu.RLock()
+	out := make([]Rollout, 0, len(idx.rollouts))
+	for _, r := range idx.rollouts {
+		r.Principals = slices.Clone(r.Principals)
+		out = append(out, r)
+	}
+	idx.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ToolID < out[j].ToolID })
+	return out
+}
+
+// VisibleTo reports whether principal can see a tool. Tools without a rollout
+// are visible to everyone.
+func (idx *InMemoryIndex) VisibleTo(toolID, principal string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	r, ok := idx.rollouts[toolID]
+	return !ok || r.VisibleTo(principal)
+}
+
+// SearchFor performs Search as principal, including tools whose rollout
+// makes them visible to principal.
+func (idx *InMemoryIndex) SearchFor(principal, query string, limit int) ([]Summary, error) {
+	return idx.search(principal, query, limit)
+}
+
+// SearchPageFor performs SearchPage as principal.
+func (idx *InMemoryIndex) SearchPageFor(principal, query string, limit int, cursor string) ([]Summary, string, error) {
+	return idx.searchPage(principal, query, limit, cursor)
+}
+
+// filterDocsByRollout drops docs hidden from principal.
+func (idx *InMemoryIndex) filterDocsByRollout(docs []SearchDoc, principal string) []SearchDoc {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	if len(idx.rollouts) == 0 {
+		return docs
+	}
+	out := make([]SearchDoc, 0, len(docs))
+	for _, doc := range docs {
+		if r, ok := idx.rollouts[doc.ID]; ok && !r.VisibleTo(principal) {
+			continue
+		}
+		out = append(out, doc)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package index
+
+import (
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrScoresUnsupported is returned by SearchWithScores when the index's
+// Searcher does not implement ScoredSearcher.
+var ErrScoresUnsupported = errcode.New(errcode.Unsupported, "searcher does not report scores")
+
+// ScoredSummary is a search result with the score its Searcher ranked it by.
+type ScoredSummary struct {
+	Summary Summary
+
+	// Score is the searcher's relevance score; higher is better.
+	Score float64
+}
+
+// ScoredSearcher is implemented by Searchers that can report the score of
+// each result, such as search.BM25Searcher.
+//
+// Contract:
+//   - Consistency: SearchScored must return the results Search returns for
+//     the same inputs, in the same order.
+type ScoredSearcher interface {
+	Searcher
+	SearchScored(query string, limit int, docs []SearchDoc) ([]ScoredSummary, error)
+}
+
+// SearchWithScores performs Search and returns each result with its score.
+// It returns ErrScoresUnsupported when the index's Searcher does not
+// implement ScoredSearcher.
+func (idx *InMemoryIndex) SearchWithScores(query string, limit int) ([]ScoredSummary, error) {
+	return idx.SearchWithScoresFor("", query, limit)
+}
+
+// SearchWithScoresFor performs SearchWithScores as principal.
+func (idx *InMemoryIndex) SearchWithScoresFor(principal, query string, limit int) ([]ScoredSummary, error) {
+	scorer, ok := idx.searcher.(ScoredSearcher)
+	if !ok {
+		return nil, ErrScoresUnsupported
+	}
+	docs, _ := idx.snapshotSearchDocs()
+	docs = idx.filterDocsByHealth(idx.filterDocsByRollout(idx.filterDocsByDisabled(docs), principal))
+	results, err := filterRanked(query, limit, docs, scorer.SearchScored, func(r ScoredSummary) Summary { return r.Summary })
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, len(results))
+	for i, r := range results {
+		summaries[i] = r.Summary
+	}
+	for i, s := range idx.markDegraded(summaries) {
+		results[i].Summary = s
+	}
+	return results, nil
+}
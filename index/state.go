@@ -0,0 +1,207 @@
+package index
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ToolState is the stored state of one registered tool: what persistent and
+// replicated indexes built on InMemoryIndex write out and apply back.
+// Backend health, rollouts, and maintenance windows are runtime state and
+// not part of it.
+type ToolState struct {
+	Tool     model.Tool          `json:"tool"`
+	Backends []model.ToolBackend `json:"backends"`
+	Metadata map[string]string   `json:"metadata,omitempty"`
+
+	// DisabledAt is when DisableTool hid the tool, or zero when it is
+	// enabled.
+	DisabledAt time.Time `json:"disabledAt,omitzero"`
+}
+
+// Equal reports whether s and other describe the same state. Nil and empty
+// metadata are equal.
+func (s ToolState) Equal(other ToolState) bool {
+	if len(s.Metadata) == 0 && len(other.Metadata) == 0 {
+		s.Metadata, other.Metadata = nil, nil
+	}
+	a, errA := json.Marshal(s)
+	b, errB := json.Marshal(other)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// GetToolState returns the stored state of tool id. Unlike GetTool it
+// includes tools hidden by DisableTool.
+func (idx *InMemoryIndex) GetToolState(id string) (ToolState, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.tools[id]
+	if !ok {
+		return ToolState{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return record.state(), nil
+}
+
+// SetToolState makes tool id match state, or removes it when state is nil,
+// as when applying a change read from shared storage. The tool, backends,
+// metadata, and disabled state are replaced as a whole, so a change of MCP
+// fields applies in place (recorded as a new tool version with
+// IndexOptions.TrackToolVersions) and other state such as backend health is
+// kept. Catalog limits are not applied, since the state was accepted where
+// it was written.
+//
+// Setting a tool to its current state is a no-op. Otherwise ChangeRegistered
+// is emitted for a new tool, ChangeUpdated for an existing one, or
+// ChangeToolRemoved, followed by ChangeDisabled or ChangeEnabled when the
+// disabled state changes. A change of only the disabled state emits just
+// the latter.
+func (idx *InMemoryIndex) SetToolState(id string, state *ToolState) error {
+	if state != nil {
+		if err := validateTool(state.Tool); err != nil {
+			return err
+		}
+		if got := state.Tool.ToolID(); got != id {
+			return fmt.Errorf("%w: state is for tool %q, not %q", ErrInvalidTool, got, id)
+		}
+		if len(state.Backends) == 0 {
+			return fmt.Errorf("%w: tool %q has no backends", ErrInvalidBackend, id)
+		}
+		for _, backend := range state.Backends {
+			if err := validateBackend(backend); err != nil {
+				return err
+			}
+		}
+		if err := validateMetadata(state.Metadata); err != nil {
+			return err
+		}
+	}
+
+	idx.mu.Lock()
+	record, exists := idx.tools[id]
+	var events []ChangeEvent
+	switch {
+	case state == nil && !exists:
+		idx.mu.Unlock()
+		return nil
+	case state == nil:
+		idx.deleteToolLocked(id, record)
+		idx.markSearchDocsDirtyLocked()
+		event := ChangeEvent{Type: ChangeToolRemoved, ToolID: id, Backend: record.backends[0], Version: idx.indexVersion}
+		idx.recordChangeLocked(event)
+		events = []ChangeEvent{event}
+	case exists && record.state().Equal(*state):
+		idx.mu.Unlock()
+		return nil
+	default:
+		current := ToolState{}
+		if exists {
+			current = record.state()
+			current.DisabledAt = state.DisabledAt
+		}
+		if !exists || !current.Equal(*state) {
+			event, err := idx.setToolStateLocked(id, record, *state)
+			if err != nil {
+				idx.mu.Unlock()
+				return err
+			}
+			events = append(events, event)
+			record = idx.tools[id]
+		}
+		if !record.disabledAt.Equal(state.DisabledAt) {
+			events = append(events, idx.setDisabledAtLocked(id, record, state.DisabledAt))
+		}
+	}
+	listeners := idx.snapshotListenersLocked()
+	idx.mu.Unlock()
+
+	for _, event := range events {
+		notifyListeners(listeners, event)
+	}
+	return nil
+}
+
+// setToolStateLocked replaces the registration of tool id, which is record
+// or new when record is nil, with state. Must be called with idx.mu held.
+func (idx *InMemoryIndex) setToolStateLocked(id string, record *toolRecord, state ToolState) (ChangeEvent, error) {
+	backends := slices.Clone(state.Backends)
+	keys := make(map[string]int, len(backends))
+	for i, backend := range backends {
+		key := backendIdentity(backend)
+		if _, dup := keys[key]; dup {
+			return ChangeEvent{}, fmt.Errorf("%w: tool %q lists backend %q twice", ErrInvalidBackend, id, BackendID(backend))
+		}
+		keys[key] = i
+	}
+
+	changeType := ChangeUpdated
+	previousToolVersion := 0
+	newToolVersion := false
+	if record == nil {
+		if err := idx.checkCaseConflictLocked(id); err != nil {
+			return ChangeEvent{}, err
+		}
+		changeType = ChangeRegistered
+		record = &toolRecord{}
+		idx.addToolVersionLocked(record, state.Tool)
+		newToolVersion = true
+		idx.tools[id] = record
+		idx.canonicalIDs[CanonicalToolID(id)] = id
+		idx.addNamespaceLocked(state.Tool.Namespace)
+	} else {
+		if idx.trackToolVersions && !toolMCPFieldsEqual(record.tool, state.Tool) {
+			previousToolVersion = record.currentToolVersion()
+			idx.addToolVersionLocked(record, state.Tool)
+			newToolVersion = true
+		} else {
+			record.versions[len(record.versions)-1].Tool = state.Tool
+		}
+		if record.tool.Namespace != state.Tool.Namespace {
+			idx.removeNamespaceLocked(record.tool.Namespace)
+			idx.addNamespaceLocked(state.Tool.Namespace)
+		}
+		for name := range record.health {
+			if !slices.ContainsFunc(backends, func(b model.ToolBackend) bool { return backendName(b) == name }) {
+				delete(record.health, name)
+			}
+		}
+	}
+
+	record.tool = state.Tool
+	record.backends = backends
+	record.backendKeys = keys
+	record.normalizedTags = idx.tagPolicy.normalize(state.Tool.Tags)
+	record.metadata = maps.Clone(state.Metadata)
+	refreshRecordDerived(record)
+
+	idx.markSearchDocsDirtyLocked()
+	version := idx.indexVersion
+	if newToolVersion {
+		record.versions[len(record.versions)-1].IndexVersion = version
+	}
+	event := ChangeEvent{
+		Type:                changeType,
+		ToolID:              id,
+		Backend:             backends[0],
+		Version:             version,
+		ToolVersion:         record.currentToolVersion(),
+		PreviousToolVersion: previousToolVersion,
+	}
+	idx.recordChangeLocked(event)
+	return event, nil
+}
+
+// state returns the stored state of record.
+func (r *toolRecord) state() ToolState {
+	return ToolState{
+		Tool:       r.tool,
+		Backends:   slices.Clone(r.backends),
+		Metadata:   maps.Clone(r.metadata),
+		DisabledAt: r.disabledAt,
+	}
+}
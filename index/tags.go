@@ -0,0 +1,155 @@
+package index
+
+import (
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Defaults applied by TagPolicy, matching model.NormalizeTags.
+const (
+	DefaultMaxTagLen = 64
+	maxTagCount      = 20
+)
+
+// TagPolicy configures how InMemoryIndex normalizes tool tags. Normalized
+// tags are what Summary.Tags reports, so the policy also governs the search
+// text, facets, and fingerprints derived from them. The zero value is
+// model.NormalizeTags: tags are lowercased and trimmed, whitespace runs
+// become "-", characters outside [a-z0-9-_.] are dropped, tags are cut to
+// DefaultMaxTagLen bytes, and duplicates are removed, keeping at most 20.
+type TagPolicy struct {
+	// PreserveCase keeps the case of tags instead of lowercasing them, and
+	// allows A-Z. Search still matches tags case-insensitively.
+	PreserveCase bool
+
+	// Separators maps characters to the character that replaces them, such
+	// as '/' to '.'. The entry for ' ' replaces whitespace runs, which
+	// otherwise become '-'. Replacements outside the allowed characters are
+	// dropped.
+	Separators map[rune]rune
+
+	// MaxTagLen caps the length of each tag in bytes; longer tags are cut.
+	// Default: DefaultMaxTagLen.
+	MaxTagLen int
+
+	// BannedTags are dropped from every tool. They are compared after
+	// normalization, so "Deprecated" bans "deprecated" under the default
+	// policy.
+	BannedTags []string
+}
+
+// Normalize applies the policy to tags, preserving their order.
+func (p TagPolicy) Normalize(tags []string) []string {
+	return p.normalizer().normalize(tags)
+}
+
+// TagNormalizer is an optional interface for normalizing tags as an index
+// does, so derived state such as changelog diffs and tools built by callers
+// matches Summary.Tags.
+//
+// Contract:
+//   - NormalizeTags must be deterministic and safe for concurrent use.
+type TagNormalizer interface {
+	NormalizeTags(tags []string) []string
+}
+
+// NormalizeTags applies the index's IndexOptions.TagPolicy to tags.
+func (idx *InMemoryIndex) NormalizeTags(tags []string) []string {
+	return idx.tagPolicy.normalize(tags)
+}
+
+// tagNormalizer is a TagPolicy prepared for repeated use.
+type tagNormalizer struct {
+	policy TagPolicy
+	banned map[string]struct{}
+}
+
+func (p TagPolicy) normalizer() tagNormalizer {
+	n := tagNormalizer{policy: p}
+	if n.policy.MaxTagLen <= 0 {
+		n.policy.MaxTagLen = DefaultMaxTagLen
+	}
+	if len(p.BannedTags) > 0 {
+		n.banned = make(map[string]struct{}, len(p.BannedTags))
+		for _, tag := range n.normalizeAll(p.BannedTags, len(p.BannedTags)) {
+			n.banned[tag] = struct{}{}
+		}
+	}
+	return n
+}
+
+// isDefault reports whether the policy behaves like model.NormalizeTags.
+func (n tagNormalizer) isDefault() bool {
+	return !n.policy.PreserveCase && len(n.policy.Separators) == 0 &&
+		n.policy.MaxTagLen == DefaultMaxTagLen && len(n.banned) == 0
+}
+
+func (n tagNormalizer) normalize(tags []string) []string {
+	if n.isDefault() {
+		return model.NormalizeTags(tags)
+	}
+	return n.normalizeAll(tags, maxTagCount)
+}
+
+// normalizeAll normalizes and dedupes tags, dropping banned ones, and keeps
+// at most limit.
+func (n tagNormalizer) normalizeAll(tags []string, limit int) []string {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		if len(out) >= limit {
+			break
+		}
+		tag := n.normalizeTag(raw)
+		if tag == "" {
+			continue
+		}
+		if _, ok := n.banned[tag]; ok {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out
+}
+
+func (n tagNormalizer) normalizeTag(raw string) string {
+	t := strings.TrimSpace(raw)
+	if !n.policy.PreserveCase {
+		t = strings.ToLower(t)
+	}
+	space, ok := n.policy.Separators[' ']
+	if !ok {
+		space = '-'
+	}
+	fields := strings.Fields(t)
+
+	var b strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			b.WriteRune(space)
+		}
+		for _, r := range field {
+			if sep, ok := n.policy.Separators[r]; ok {
+				r = sep
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	out := make([]byte, 0, b.Len())
+	for _, c := range []byte(b.String()) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' ||
+			(n.policy.PreserveCase && c >= 'A' && c <= 'Z') {
+			out = append(out, c)
+		}
+	}
+	if len(out) > n.policy.MaxTagLen {
+		out = out[:n.policy.MaxTagLen]
+	}
+	return string(out)
+}
@@ -0,0 +1,164 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ErrInvalidToolID is returned, wrapped in a *ToolIDError, when a tool ID or
+// one of its components breaks the tool ID rules. It is model.ErrInvalidToolID,
+// so either can be matched with errors.Is.
+var ErrInvalidToolID = model.ErrInvalidToolID
+
+// ToolIDSeparator separates the namespace, name, and version of a tool ID.
+// It is reserved and may not appear inside a component.
+const ToolIDSeparator = ":"
+
+// MaxToolIDComponentLen is the maximum length of each tool ID component.
+const MaxToolIDComponentLen = 128
+
+// ToolID is a parsed tool ID: "name", "namespace:name", or
+// "namespace:name:version".
+//
+// Rules, enforced by Validate and at registration:
+//   - Name is required. As in model.Tool.ToolID, a Version without a
+//     Namespace is not part of the joined ID.
+//   - Each component is 1 to MaxToolIDComponentLen characters from
+//     [A-Za-z0-9_.-], so the reserved ToolIDSeparator never appears inside one.
+//     Version may also use "+", as in semver build metadata ("1.2.0+build.5").
+//   - IDs are case-sensitive, but an index rejects a registration whose ID
+//     differs from a registered tool's only by case (see CanonicalToolID).
+type ToolID struct {
+	Namespace string
+	Name      string
+	Version   string
+}
+
+// ToolIDError describes why a tool ID is invalid. It matches
+// ErrInvalidToolID with errors.Is.
+type ToolIDError struct {
+	// ID is the tool ID, or the joined components when built from parts.
+	ID string
+	// Component is "namespace", "name", or "version", or empty when the
+	// problem is the ID as a whole.
+	Component string
+	// Reason explains the violated rule.
+	Reason string
+}
+
+func (e *ToolIDError) Error() string {
+	if e.Component == "" {
+		return fmt.Sprintf("%v %q: %s", ErrInvalidToolID, e.ID, e.Reason)
+	}
+	return fmt.Sprintf("%v %q: %s %s", ErrInvalidToolID, e.ID, e.Component, e.Reason)
+}
+
+// Unwrap returns ErrInvalidToolID.
+func (e *ToolIDError) Unwrap() error {
+	return ErrInvalidToolID
+}
+
+// ToolIDOf returns the ID components of tool.
+func ToolIDOf(tool model.Tool) ToolID {
+	return ToolID{Namespace: tool.Namespace, Name: tool.Name, Version: tool.Version}
+}
+
+// String returns the ID in its joined form. It matches model.Tool.ToolID for
+// valid IDs.
+func (id ToolID) String() string {
+	switch {
+	case id.Namespace == "":
+		return id.Name
+	case id.Version == "":
+		return id.Namespace + ToolIDSeparator + id.Name
+	default:
+		return id.Namespace + ToolIDSeparator + id.Name + ToolIDSeparator + id.Version
+	}
+}
+
+// Validate checks id against the tool ID rules and returns a *ToolIDError
+// for the first violation.
+func (id ToolID) Validate() error {
+	if id.Name == "" {
+		return &ToolIDError{ID: id.String(), Component: "name", Reason: "is required"}
+	}
+	for _, part := range []struct {
+		component, value string
+		build            bool
+	}{
+		{"namespace", id.Namespace, false},
+		{"name", id.Name, false},
+		{"version", id.Version, true},
+	} {
+		if err := validateToolIDComponent(part.value, part.build); err != "" {
+			return &ToolIDError{ID: id.String(), Component: part.component, Reason: err}
+		}
+	}
+	return nil
+}
+
+// validateToolIDComponent returns the rule value breaks, or "" when it is
+// valid or empty. build also allows "+", for semver build metadata in
+// versions.
+func validateToolIDComponent(value string, build bool) string {
+	if len(value) > MaxToolIDComponentLen {
+		return fmt.Sprintf("exceeds %d characters", MaxToolIDComponentLen)
+	}
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+		case r == '+' && build:
+		case string(r) == ToolIDSeparator:
+			return fmt.Sprintf("contains reserved separator %q", ToolIDSeparator)
+		default:
+			return fmt.Sprintf("contains invalid character %q", r)
+		}
+	}
+	return ""
+}
+
+// ParseToolID splits and validates a tool ID. Unlike model.ParseToolID, the
+// version is returned separately rather than appended to the name.
+func ParseToolID(s string) (ToolID, error) {
+	if s == "" {
+		return ToolID{}, &ToolIDError{Reason: "is empty"}
+	}
+	var id ToolID
+	parts := strings.Split(s, ToolIDSeparator)
+	switch len(parts) {
+	case 1:
+		id.Name = parts[0]
+	case 2:
+		id.Namespace, id.Name = parts[0], parts[1]
+	case 3:
+		id.Namespace, id.Name, id.Version = parts[0], parts[1], parts[2]
+	default:
+		return ToolID{}, &ToolIDError{ID: s, Reason: "has more than three components"}
+	}
+	if len(parts) > 1 && id.Namespace == "" {
+		return ToolID{}, &ToolIDError{ID: s, Component: "namespace", Reason: "is empty"}
+	}
+	if len(parts) > 2 && id.Version == "" {
+		return ToolID{}, &ToolIDError{ID: s, Component: "version", Reason: "is empty"}
+	}
+	if err := id.Validate(); err != nil {
+		err.(*ToolIDError).ID = s
+		return ToolID{}, err
+	}
+	return id, nil
+}
+
+// ValidateToolID reports whether s is a valid tool ID, returning a
+// *ToolIDError otherwise.
+func ValidateToolID(s string) error {
+	_, err := ParseToolID(s)
+	return err
+}
+
+// CanonicalToolID returns the case-folded form of a tool ID, used to detect
+// IDs that differ only by case. Lookups remain case-sensitive.
+func CanonicalToolID(s string) string {
+	return strings.ToLower(s)
+}
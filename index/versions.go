@@ -0,0 +1,119 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ToolVersion is one recorded definition of a tool. A tool's first
+// registration is version 1; with IndexOptions.TrackToolVersions, each
+// re-registration that changes its MCP fields adds the next version.
+type ToolVersion struct {
+	Version int        `json:"version"`
+	Tool    model.Tool `json:"tool"`
+
+	// RegisteredAt is when the version was first registered.
+	RegisteredAt time.Time `json:"registeredAt"`
+
+	// IndexVersion is the index version of the change that created it.
+	IndexVersion uint64 `json:"indexVersion"`
+}
+
+// ToolVersioner is an optional interface for indexes that keep a history of
+// tool definitions.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Errors: unknown tools or versions return ErrNotFound.
+//   - Ordering: ListToolVersions returns versions oldest first.
+type ToolVersioner interface {
+	GetToolVersion(id string, version int) (model.Tool, error)
+	ListToolVersions(id string) ([]ToolVersion, error)
+}
+
+// GetToolVersion returns the definition of tool id at version.
+func (idx *InMemoryIndex) GetToolVersion(id string, version int) (model.Tool, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.tools[id]
+	if !ok {
+		return model.Tool{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	for _, v := range record.versions {
+		if v.Version == version {
+			return v.Tool, nil
+		}
+	}
+	return model.Tool{}, fmt.Errorf("%w: %s version %d", ErrNotFound, id, version)
+}
+
+// ListToolVersions returns the recorded versions of tool id, oldest first.
+// Versions beyond IndexOptions.MaxToolVersions have been dropped.
+func (idx *InMemoryIndex) ListToolVersions(id string) ([]ToolVersion, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	record, ok := idx.tools[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return slices.Clone(record.versions), nil
+}
+
+// RestoreToolVersions replaces the recorded versions of tool id with
+// versions, as read back by a persistent index after SetToolState. Versions
+// must be numbered in increasing order and end at the tool's current MCP
+// fields; versions beyond IndexOptions.MaxToolVersions are dropped. Their
+// IndexVersion values are kept as recorded, so they may refer to another
+// process's index versions. No ChangeEvent is emitted.
+func (idx *InMemoryIndex) RestoreToolVersions(id string, versions []ToolVersion) error {
+	if len(versions) == 0 {
+		return fmt.Errorf("%w: tool %q has no versions", ErrInvalidTool, id)
+	}
+	for i, v := range versions {
+		if v.Version < 1 || (i > 0 && v.Version <= versions[i-1].Version) {
+			return fmt.Errorf("%w: tool %q versions are not in increasing order", ErrInvalidTool, id)
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	record, ok := idx.tools[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if !toolMCPFieldsEqual(versions[len(versions)-1].Tool, record.tool) {
+		return fmt.Errorf("%w: tool %q versions do not end at its current definition", ErrInvalidTool, id)
+	}
+	versions = slices.Clone(versions)
+	versions[len(versions)-1].Tool = record.tool
+	if idx.maxToolVersions > 0 && len(versions) > idx.maxToolVersions {
+		versions = slices.Delete(versions, 0, len(versions)-idx.maxToolVersions)
+	}
+	record.versions = versions
+	return nil
+}
+
+// currentToolVersion returns the latest version number of record.
+func (r *toolRecord) currentToolVersion() int {
+	if len(r.versions) == 0 {
+		return 0
+	}
+	return r.versions[len(r.versions)-1].Version
+}
+
+// addToolVersionLocked records tool as the next version of record, dropping
+// the oldest versions beyond the configured cap. IndexVersion is filled in
+// by the caller once the change has bumped the index version.
+func (idx *InMemoryIndex) addToolVersionLocked(record *toolRecord, tool model.Tool) {
+	record.versions = append(record.versions, ToolVersion{
+		Version:      record.currentToolVersion() + 1,
+		Tool:         tool,
+		RegisteredAt: idx.now(),
+	})
+	if idx.maxToolVersions > 0 && len(record.versions) > idx.maxToolVersions {
+		record.versions = slices.Delete(record.versions, 0, len(record.versions)-idx.maxToolVersions)
+	}
+}
@@ -0,0 +1,172 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// DefaultChangeBufferSize is the number of recent change events kept for
+// Watch replay when IndexOptions.ChangeBufferSize is zero.
+const DefaultChangeBufferSize = 1024
+
+// ErrChangesTruncated is returned by Watch when events after the requested
+// version are no longer buffered. Callers should resynchronize from a full
+// listing and watch from the current Version.
+var ErrChangesTruncated = errcode.New(errcode.ChangesTruncated, "change history truncated")
+
+// ChangeWatcher is an optional interface for streaming change events with
+// replay, so consumers that were offline or subscribe late do not miss
+// events.
+//
+// Contract:
+//   - Concurrency: Watch must be safe for concurrent use.
+//   - Replay: the channel first yields every buffered event with a Version
+//     greater than sinceVersion, then new events, in Version order and
+//     without duplicates. Watch(ctx, Version()) streams only new events.
+//   - Truncation: if events after sinceVersion are no longer buffered, Watch
+//     returns ErrChangesTruncated (possibly wrapped) and no channel.
+//   - Lifecycle: the channel is closed when ctx is done, or when the consumer
+//     falls too far behind; resume with Watch from the last Version seen.
+type ChangeWatcher interface {
+	ChangeNotifier
+	Watch(ctx context.Context, sinceVersion uint64) (<-chan ChangeEvent, error)
+}
+
+// changeRing is a fixed-size buffer of the most recent change events.
+type changeRing struct {
+	events  []ChangeEvent
+	head    int // index of the oldest event
+	len     int
+	evicted uint64 // Version of the newest event dropped from the buffer
+}
+
+func (r *changeRing) push(event ChangeEvent) {
+	if r.len < len(r.events) {
+		r.events[(r.head+r.len)%len(r.events)] = event
+		r.len++
+		return
+	}
+	r.evicted = r.events[r.head].Version
+	r.events[r.head] = event
+	r.head = (r.head + 1) % len(r.events)
+}
+
+// since returns the buffered events with a Version greater than version,
+// oldest first.
+func (r *changeRing) since(version uint64) []ChangeEvent {
+	var out []ChangeEvent
+	for i := 0; i < r.len; i++ {
+		event := r.events[(r.head+i)%len(r.events)]
+		if event.Version > version {
+			out = append(out, event)
+		}
+	}
+	return out
+}
+
+// changeWatcher queues events for one Watch channel so that emitting an
+// event never blocks on a slow consumer.
+type changeWatcher struct {
+	mu       sync.Mutex
+	pending  []ChangeEvent
+	limit    int
+	overflow bool
+	wake     chan struct{}
+}
+
+func (w *changeWatcher) push(event ChangeEvent) {
+	w.mu.Lock()
+	if len(w.pending) >= w.limit {
+		w.overflow = true
+	} else if !w.overflow {
+		w.pending = append(w.pending, event)
+	}
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *changeWatcher) run(ctx context.Context, out chan<- ChangeEvent, stop func()) {
+	defer close(out)
+	defer stop()
+	for {
+		w.mu.Lock()
+		batch, overflow := w.pending, w.overflow
+		w.pending = nil
+		w.mu.Unlock()
+
+		for _, event := range batch {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if overflow {
+			return
+		}
+		if len(batch) == 0 {
+			select {
+			case <-w.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Watch replays buffered events after sinceVersion and then streams new
+// ones until ctx is done. The buffer holds the last
+// IndexOptions.ChangeBufferSize events; a consumer that falls that far
+// behind has its channel closed and should resume from the last Version it
+// received.
+func (idx *InMemoryIndex) Watch(ctx context.Context, sinceVersion uint64) (<-chan ChangeEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	idx.mu.Lock()
+	if sinceVersion > idx.indexVersion {
+		current := idx.indexVersion
+		idx.mu.Unlock()
+		return nil, fmt.Errorf("%w: version %d is ahead of index version %d", ErrChangesTruncated, sinceVersion, current)
+	}
+	if sinceVersion < idx.changes.evicted {
+		evicted := idx.changes.evicted
+		idx.mu.Unlock()
+		return nil, fmt.Errorf("%w: events through version %d were dropped", ErrChangesTruncated, evicted)
+	}
+	w := &changeWatcher{
+		pending: idx.changes.since(sinceVersion),
+		limit:   len(idx.changes.events),
+		wake:    make(chan struct{}, 1),
+	}
+	idx.nextListenerID++
+	id := idx.nextListenerID
+	if idx.watchers == nil {
+		idx.watchers = make(map[uint64]*changeWatcher)
+	}
+	idx.watchers[id] = w
+	idx.mu.Unlock()
+
+	out := make(chan ChangeEvent)
+	go w.run(ctx, out, func() {
+		idx.mu.Lock()
+		delete(idx.watchers, id)
+		idx.mu.Unlock()
+	})
+	return out, nil
+}
+
+// recordChangeLocked buffers event for replay and queues it for watchers.
+// Must be called with idx.mu held, so watchers see events in Version order.
+func (idx *InMemoryIndex) recordChangeLocked(event ChangeEvent) {
+	idx.changes.push(event)
+	for _, w := range idx.watchers {
+		w.push(event)
+	}
+}
@@ -0,0 +1,24 @@
+// Package indextest provides a contract test for index.Index
+// implementations that persist or replicate an index.InMemoryIndex, such as
+// boltindex, redisindex, and pgstore.
+//
+// # Usage
+//
+// Call TestStore from a test with a function that opens a store over fresh
+// storage and a way to reload it, as a restarted process or another replica
+// would see it:
+//
+//	func TestContract(t *testing.T) {
+//	    indextest.TestStore(t, func(t *testing.T) (indextest.Store, indextest.Reload) {
+//	        path := filepath.Join(t.TempDir(), "index.db")
+//	        idx := open(t, path)
+//	        return idx, func(t *testing.T, prev indextest.Store) indextest.Store {
+//	            _ = prev.(*boltindex.Index).Close()
+//	            return open(t, path)
+//	        }
+//	    })
+//	}
+//
+// The contract covers tools with their backends and metadata, disabled
+// state (DisableTool, EnableTool), and registered categories.
+package indextest
@@ -0,0 +1,118 @@
+package indextest
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Store is the surface of a persistent index checked by TestStore.
+// Indexes embedding *index.InMemoryIndex satisfy it once they wrap its
+// mutations.
+type Store interface {
+	index.Index
+	RegisterToolWithMetadata(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error
+	GetMetadata(id string) (map[string]string, error)
+	DisableTool(id string) error
+	EnableTool(id string) error
+	ListDisabled() []index.DisabledTool
+	RegisterCategory(path string) error
+	RegisteredCategories() []string
+}
+
+// Reload returns a store over the same storage as prev that reflects every
+// write made through it. It may close prev; TestStore keeps writing through
+// the returned store.
+type Reload func(t *testing.T, prev Store) Store
+
+// Open opens a store over fresh storage.
+type Open func(t *testing.T) (Store, Reload)
+
+// maxTimeSkew is how far a reloaded DisabledAt may drift from the original,
+// for stores that keep timestamps at reduced precision.
+const maxTimeSkew = time.Millisecond
+
+// TestStore checks that the store returned by open keeps its state across
+// a reload.
+func TestStore(t *testing.T, open Open) {
+	t.Helper()
+	store, reload := open(t)
+
+	tool := model.Tool{
+		Tool: mcp.Tool{
+			Name:        "create_issue",
+			Description: "Create an issue",
+			InputSchema: map[string]any{"type": "object"},
+		},
+		Namespace: "github",
+	}
+	const id = "github:create_issue"
+	primary := model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: "github"}}
+	secondary := model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: "issues"}}
+
+	if err := store.RegisterToolWithMetadata(tool, primary, map[string]string{"team": "platform"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata failed: %v", err)
+	}
+	if err := store.RegisterTool(tool, secondary); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	store = reload(t, store)
+	if backends, err := store.GetAllBackends(id); err != nil || len(backends) != 2 {
+		t.Errorf("reloaded backends = %v, %v; want both", backends, err)
+	}
+	if metadata, err := store.GetMetadata(id); err != nil || metadata["team"] != "platform" {
+		t.Errorf("reloaded metadata = %v, %v", metadata, err)
+	}
+
+	if err := store.DisableTool(id); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	disabled := store.ListDisabled()
+	store = reload(t, store)
+	got := store.ListDisabled()
+	if len(got) != 1 || len(disabled) != 1 || got[0].ToolID != id || got[0].DisabledAt.Sub(disabled[0].DisabledAt).Abs() > maxTimeSkew {
+		t.Errorf("reloaded disabled tools = %v, want %v", got, disabled)
+	}
+	if _, _, err := store.GetTool(id); !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("reloaded GetTool of a disabled tool error = %v, want ErrNotFound", err)
+	}
+
+	if err := store.EnableTool(id); err != nil {
+		t.Fatalf("EnableTool failed: %v", err)
+	}
+	store = reload(t, store)
+	if got := store.ListDisabled(); len(got) != 0 {
+		t.Errorf("reloaded disabled tools = %v, want none", got)
+	}
+	if _, _, err := store.GetTool(id); err != nil {
+		t.Errorf("reloaded GetTool of an enabled tool failed: %v", err)
+	}
+
+	if err := store.RegisterCategory("devops/containers"); err != nil {
+		t.Fatalf("RegisterCategory failed: %v", err)
+	}
+	if err := store.RegisterCategory("finance"); err != nil {
+		t.Fatalf("RegisterCategory failed: %v", err)
+	}
+	store = reload(t, store)
+	if got := store.RegisteredCategories(); !slices.Equal(got, []string{"devops/containers", "finance"}) {
+		t.Errorf("reloaded categories = %v", got)
+	}
+
+	if err := store.UnregisterBackend(id, model.BackendKindMCP, "github"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if err := store.UnregisterBackend(id, model.BackendKindLocal, "issues"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	store = reload(t, store)
+	if _, _, err := store.GetTool(id); !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("reloaded GetTool of a removed tool error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package pgstore provides a PostgreSQL-backed index.Index and tooldoc.Store
+// for teams that already run Postgres and want the catalog and its
+// documentation stored there and shared across replicas.
+//
+// It lives outside the index and tooldoc packages to keep them
+// dependency-light. It works over database/sql, so any PostgreSQL driver
+// (pgx's stdlib adapter, lib/pq) can be used, and receives LISTEN
+// notifications through [Listener].
+//
+// # Usage
+//
+//	db, _ := sql.Open("pgx", dsn)
+//	searcher, _ := pgstore.NewSearcher(db)
+//	idx, err := pgstore.Open(ctx, db, pgstore.Options{
+//	    Index:    index.IndexOptions{Searcher: searcher},
+//	    Listener: listener,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer idx.Close()
+//
+//	docs, err := pgstore.OpenDocStore(ctx, db, pgstore.DocStoreOptions{
+//	    Store:    tooldoc.StoreOptions{Index: idx},
+//	    Listener: listener,
+//	})
+//
+// # Migrations
+//
+// Open and OpenDocStore run [Migrate], which applies pending schema
+// migrations in transactions under an advisory lock and records them in
+// tooldiscovery_schema_migrations, so replicas starting together are safe.
+// Teams managing schemas with their own tooling can apply [Migrations] and
+// set SkipMigrate.
+//
+// # Full-Text Search
+//
+// [Searcher] is an index.Searcher that ranks tools with PostgreSQL full-text
+// search over a generated tsvector column (name, then namespace and tags,
+// then description) and a GIN index, instead of ranking in process. The
+// in-memory index still decides which tools are visible, so rollouts and
+// metadata filters apply. SearcherOptions.Fallback answers empty queries
+// and queries made while the database is unavailable.
+//
+// [Index] also implements index.SearchPushdown, which Discovery prefers: the
+// query and its metadata filters run in one SQL statement (JSONB containment
+// on the metadata column) rather than over documents materialized in
+// memory. Queries with an embedding vector fall back to in-process ranking.
+//
+// # Change Events
+//
+// Each mutation is written, together with a NOTIFY naming the changed IDs,
+// in one transaction, so other replicas hear about it only once it is
+// committed. They reload those rows and apply them, firing their own
+// OnChange listeners. Delivery is at-most-once and replicas are eventually
+// consistent; Index.Resync and DocStore.Resync repair missed notifications
+// and suit a periodic scheduler job. A change set too large for one NOTIFY
+// payload asks receivers to resync instead.
+//
+// Writes lock the affected rows with SELECT ... FOR UPDATE and merge the
+// local change into them: backends another replica registered on a tool
+// meanwhile are kept, in the row and in the writer's memory.
+//
+// A failed database write is returned to the caller after memory has been
+// updated; the next Resync restores the stored state.
+//
+// # What Is Stored
+//
+// Tools, their backends, their custom metadata, whether they are disabled,
+// the categories added with RegisterCategory, and per-tool documentation.
+// Rollouts, maintenance windows, tool version history, and namespace owners
+// are per-replica runtime state.
+package pgstore
@@ -0,0 +1,305 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+const (
+	sqlUpsertDoc = `INSERT INTO tooldiscovery_docs (id, doc) VALUES ($1, $2::jsonb)
+ON CONFLICT (id) DO UPDATE SET doc = EXCLUDED.doc, updated_at = now()`
+	sqlDeleteDoc  = `DELETE FROM tooldiscovery_docs WHERE id = $1`
+	sqlSelectDoc  = `SELECT id, doc FROM tooldiscovery_docs WHERE id = $1`
+	sqlSelectDocs = `SELECT id, doc FROM tooldiscovery_docs ORDER BY id`
+)
+
+// DocStoreOptions configures a Postgres-backed documentation store.
+type DocStoreOptions struct {
+	// Store configures the in-memory store that serves reads.
+	Store tooldoc.StoreOptions
+
+	// Listener receives change notifications from other replicas. Without
+	// it, replicas converge only when Resync runs.
+	Listener Listener
+
+	// Channel is the NOTIFY channel for documentation changes.
+	// Default: DefaultDocsChannel.
+	Channel string
+
+	// ReplicaID identifies this replica in notifications so it skips its
+	// own. Default: a random ID.
+	ReplicaID string
+
+	// Timeout bounds each database round trip. Default: DefaultTimeout.
+	Timeout time.Duration
+
+	// SkipMigrate skips running Migrate on open.
+	SkipMigrate bool
+
+	// OnError is called when applying a notification or listening fails.
+	// Optional.
+	OnError func(error)
+}
+
+// DocStore is a tooldoc.Store persisted in PostgreSQL and shared by every
+// replica using the same database. It follows the same model as [Index]:
+// reads are served from an embedded tooldoc.InMemoryStore, mutations are
+// written through in one transaction with a NOTIFY, and Resync repairs missed
+// notifications.
+//
+// Per-tool documentation, including examples and owner overrides, is
+// stored. Namespace owners and change listeners are per replica.
+type DocStore struct {
+	*tooldoc.InMemoryStore
+	*replica
+}
+
+// docRow is the stored form of one tool's documentation.
+type docRow struct {
+	Summary      string                `json:"summary,omitempty"`
+	Notes        string                `json:"notes,omitempty"`
+	Examples     []tooldoc.ToolExample `json:"examples,omitempty"`
+	ExternalRefs []string              `json:"externalRefs,omitempty"`
+	SeeAlso      []string              `json:"seeAlso,omitempty"`
+	Owner        *tooldoc.Owner        `json:"owner,omitempty"`
+}
+
+// OpenDocStore migrates the schema, loads stored documentation into memory
+// and, when DocStoreOptions.Listener is set, starts applying changes from
+// other replicas.
+func OpenDocStore(ctx context.Context, db *sql.DB, opts ...DocStoreOptions) (*DocStore, error) {
+	if db == nil {
+		return nil, ErrInvalidDB
+	}
+	var opt DocStoreOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Channel == "" {
+		opt.Channel = DefaultDocsChannel
+	}
+	if !opt.SkipMigrate {
+		if err := Migrate(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &DocStore{
+		InMemoryStore: tooldoc.NewInMemoryStore(opt.Store),
+		replica:       newReplica(db, opt.Channel, opt.ReplicaID, opt.Timeout, opt.OnError),
+	}
+	if opt.Listener != nil {
+		s.listen(opt.Listener, s.handleNotification)
+	}
+	if err := s.Resync(ctx); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// RegisterDoc registers documentation for a tool and stores it.
+func (s *DocStore) RegisterDoc(id string, entry tooldoc.DocEntry) error {
+	return s.mutate(func() error { return s.InMemoryStore.RegisterDoc(id, entry) }, id)
+}
+
+// RegisterExamples adds or replaces examples for a tool and stores them.
+func (s *DocStore) RegisterExamples(id string, examples []tooldoc.ToolExample) error {
+	return s.mutate(func() error { return s.InMemoryStore.RegisterExamples(id, examples) }, id)
+}
+
+// PatchDoc applies a partial update to a tool's documentation and stores the
+// result.
+func (s *DocStore) PatchDoc(id string, patch tooldoc.DocPatch) error {
+	return s.mutate(func() error { return s.InMemoryStore.PatchDoc(id, patch) }, id)
+}
+
+// RemoveDoc deletes a tool's documentation and its row.
+func (s *DocStore) RemoveDoc(id string) error {
+	return s.mutate(func() error { return s.InMemoryStore.RemoveDoc(id) }, id)
+}
+
+// RemoveExamples deletes examples by ID and stores the result.
+func (s *DocStore) RemoveExamples(id string, exampleIDs []string) error {
+	return s.mutate(func() error { return s.InMemoryStore.RemoveExamples(id, exampleIDs) }, id)
+}
+
+// UpdateExample replaces one example and stores the result.
+func (s *DocStore) UpdateExample(id, exampleID string, example tooldoc.ToolExample) error {
+	return s.mutate(func() error { return s.InMemoryStore.UpdateExample(id, exampleID, example) }, id)
+}
+
+// DeleteExample removes one example and stores the result.
+func (s *DocStore) DeleteExample(id, exampleID string) error {
+	return s.mutate(func() error { return s.InMemoryStore.DeleteExample(id, exampleID) }, id)
+}
+
+// PruneOrphans removes documentation for tools that no longer resolve,
+// deletes their rows, and returns the removed IDs. A failed delete is
+// reported to DocStoreOptions.OnError and repaired by the next Resync.
+func (s *DocStore) PruneOrphans() []string {
+	var removed []string
+	err := s.mutateFunc(func() ([]string, error) {
+		removed = s.InMemoryStore.PruneOrphans()
+		return removed, nil
+	})
+	if err != nil {
+		s.report(err)
+	}
+	return removed
+}
+
+// mutate applies fn to memory and writes the resulting documentation for
+// id. Nothing is written when fn fails, since the in-memory store leaves
+// documentation unchanged on error.
+func (s *DocStore) mutate(fn func() error, id string) error {
+	return s.mutateFunc(func() ([]string, error) {
+		return []string{id}, fn()
+	})
+}
+
+func (s *DocStore) mutateFunc(fn func() ([]string, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	ids, err := fn()
+	if err != nil {
+		return err
+	}
+	return s.write(ids, func(ctx context.Context, tx *sql.Tx, id string) error {
+		entry, err := s.InMemoryStore.GetDoc(id)
+		if errors.Is(err, tooldoc.ErrNotFound) {
+			_, err = tx.ExecContext(ctx, sqlDeleteDoc, id)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		doc, err := json.Marshal(docRow(entry))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, sqlUpsertDoc, id, string(doc))
+		return err
+	})
+}
+
+// Resync reloads stored documentation, applying every entry that differs
+// from memory and removing entries deleted by other replicas. It has the
+// scheduler.JobFunc signature.
+func (s *DocStore) Resync(ctx context.Context) error {
+	docs, err := s.fetchDocs(ctx, sqlSelectDocs)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	var errs []error
+	for _, id := range s.InMemoryStore.DocIDs() {
+		if _, ok := docs[id]; !ok {
+			errs = append(errs, s.applyLocked(id, docRow{}, false))
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(docs)) {
+		errs = append(errs, s.applyLocked(id, docs[id], true))
+	}
+	return errors.Join(errs...)
+}
+
+// handleNotification reloads the documentation changed by another replica.
+func (s *DocStore) handleNotification(n notification) {
+	if n.Resync {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		if err := s.Resync(ctx); err != nil {
+			s.report(err)
+		}
+		return
+	}
+	for _, id := range n.IDs {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		docs, err := s.fetchDocs(ctx, sqlSelectDoc, id)
+		cancel()
+		if err != nil {
+			s.report(err)
+			continue
+		}
+		doc, ok := docs[id]
+		s.mu.Lock()
+		if !s.closed {
+			err = s.applyLocked(id, doc, ok)
+		}
+		s.mu.Unlock()
+		if err != nil {
+			s.report(err)
+		}
+	}
+}
+
+// applyLocked replaces the in-memory documentation for id with doc, or
+// removes it when found is false. The in-memory store only emits change
+// events for fields that differ.
+func (s *DocStore) applyLocked(id string, doc docRow, found bool) error {
+	if !found {
+		err := s.InMemoryStore.RemoveDoc(id)
+		if err != nil && !errors.Is(err, tooldoc.ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+	if err := s.InMemoryStore.RegisterDoc(id, tooldoc.DocEntry(doc)); err != nil {
+		return fmt.Errorf("pgstore: apply doc %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *DocStore) fetchDocs(ctx context.Context, query string, args ...any) (map[string]docRow, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: read docs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	docs := make(map[string]docRow)
+	for rows.Next() {
+		var (
+			id  string
+			raw []byte
+		)
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, fmt.Errorf("pgstore: read docs: %w", err)
+		}
+		var doc docRow
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("%w: doc %s: %v", ErrInvalidStore, id, err)
+		}
+		docs[id] = doc
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: read docs: %w", err)
+	}
+	return docs, nil
+}
+
+// Close stops applying notifications. The in-memory store keeps serving
+// reads; mutations return ErrClosed. Close is idempotent and does not close
+// the *sql.DB.
+func (s *DocStore) Close() error {
+	s.close()
+	return nil
+}
+
+var _ tooldoc.Store = (*DocStore)(nil)
@@ -0,0 +1,327 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// fakePG is an in-memory stand-in for PostgreSQL that understands exactly
+// the statements this package issues. NOTIFY is delivered on commit, like
+// PostgreSQL.
+type fakePG struct {
+	mu         sync.Mutex
+	tools      map[string][]string // id -> namespace, tool, backends, metadata, disabled_at
+	categories map[string]bool
+	docs       map[string]string
+	versions   map[int]string
+	migrations int // migration bodies executed
+	listeners  map[string][]chan string
+	fail       error // returned by every statement when set
+}
+
+func newFakePG() *fakePG {
+	return &fakePG{
+		tools:      map[string][]string{},
+		categories: map[string]bool{},
+		docs:       map[string]string{},
+		versions:   map[int]string{},
+		listeners:  map[string][]chan string{},
+	}
+}
+
+func (f *fakePG) open(t *testing.T) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(fakeConnector{f})
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func (f *fakePG) setFail(err error) {
+	f.mu.Lock()
+	f.fail = err
+	f.mu.Unlock()
+}
+
+// count returns len(m) under the fake's lock.
+func (f *fakePG) count(m any) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch m := m.(type) {
+	case map[string][]string:
+		return len(m)
+	case map[string]string:
+		return len(m)
+	}
+	return -1
+}
+
+func (f *fakePG) listenerCount(channel string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.listeners[channel])
+}
+
+func (f *fakePG) Listen(ctx context.Context, channel string, handle func(string)) error {
+	ch := make(chan string, 64)
+	f.mu.Lock()
+	f.listeners[channel] = append(f.listeners[channel], ch)
+	f.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case payload := <-ch:
+			handle(payload)
+		}
+	}
+}
+
+type fakeConnector struct{ pg *fakePG }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{pg: c.pg}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use fakeConnector") }
+
+type fakeConn struct {
+	pg *fakePG
+	tx *fakeTx
+}
+
+type fakeTx struct {
+	conn    *fakeConn
+	notices [][2]string
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare unsupported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c}
+	return c.tx, nil
+}
+
+func (t *fakeTx) Commit() error {
+	pg := t.conn.pg
+	pg.mu.Lock()
+	for _, n := range t.notices {
+		for _, ch := range pg.listeners[n[0]] {
+			ch <- n[1]
+		}
+	}
+	pg.mu.Unlock()
+	t.conn.tx = nil
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.tx = nil
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, named []driver.NamedValue) (driver.Result, error) {
+	args := values(named)
+	pg := c.pg
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	if pg.fail != nil {
+		return nil, pg.fail
+	}
+	switch query {
+	case sqlCreateMigrationsTable, sqlLockMigrations:
+	case sqlInsertMigration:
+		pg.versions[int(args[0].(int64))] = args[1].(string)
+	case sqlUpsertTool:
+		disabledAt := ""
+		if at, ok := args[5].(time.Time); ok {
+			disabledAt = at.Format(time.RFC3339Nano)
+		}
+		pg.tools[args[0].(string)] = []string{args[1].(string), args[2].(string), args[3].(string), args[4].(string), disabledAt}
+	case sqlInsertCategory:
+		pg.categories[args[0].(string)] = true
+	case sqlDeleteTool:
+		delete(pg.tools, args[0].(string))
+	case sqlUpsertDoc:
+		pg.docs[args[0].(string)] = args[1].(string)
+	case sqlDeleteDoc:
+		delete(pg.docs, args[0].(string))
+	case sqlNotify:
+		if c.tx == nil {
+			return nil, errors.New("notify outside transaction")
+		}
+		c.tx.notices = append(c.tx.notices, [2]string{args[0].(string), args[1].(string)})
+	default:
+		if !slices.ContainsFunc(migrations, func(m Migration) bool { return m.SQL == query }) {
+			return nil, fmt.Errorf("unexpected exec %q", query)
+		}
+		pg.migrations++
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := values(named)
+	pg := c.pg
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	if pg.fail != nil {
+		return nil, pg.fail
+	}
+	rows := &fakeRows{}
+	switch query {
+	case sqlSelectSchemaVersion:
+		var latest int64
+		for v := range pg.versions {
+			latest = max(latest, int64(v))
+		}
+		rows.cols = []string{"version"}
+		rows.data = [][]driver.Value{{latest}}
+	case sqlSelectTool, sqlSelectToolForUpdate, sqlSelectTools:
+		rows.cols = []string{"id", "tool", "backends", "metadata", "disabled_at"}
+		for _, id := range sortedKeys(pg.tools) {
+			if query != sqlSelectTools && id != args[0] {
+				continue
+			}
+			r := pg.tools[id]
+			var disabledAt driver.Value
+			if r[4] != "" {
+				disabledAt, _ = time.Parse(time.RFC3339Nano, r[4])
+			}
+			rows.data = append(rows.data, []driver.Value{id, []byte(r[1]), []byte(r[2]), []byte(r[3]), disabledAt})
+		}
+	case sqlSelectCategories:
+		rows.cols = []string{"path"}
+		for _, path := range sortedKeys(pg.categories) {
+			rows.data = append(rows.data, []driver.Value{path})
+		}
+	case sqlSelectDoc, sqlSelectDocs:
+		rows.cols = []string{"id", "doc"}
+		for _, id := range sortedKeys(pg.docs) {
+			if query == sqlSelectDoc && id != args[0] {
+				continue
+			}
+			rows.data = append(rows.data, []driver.Value{id, []byte(pg.docs[id])})
+		}
+	case sqlSearchTools:
+		rows.cols = []string{"id"}
+		for _, h := range pg.textHits(args[0].(string), nil) {
+			rows.data = append(rows.data, []driver.Value{h.id})
+		}
+	case sqlPushdownSearch, sqlPushdownList:
+		var filters map[string]string
+		_ = json.Unmarshal([]byte(args[len(args)-1].(string)), &filters)
+		rows.cols = []string{"id", "score"}
+		if query == sqlPushdownList {
+			for _, id := range sortedKeys(pg.tools) {
+				if pg.matches(id, filters) {
+					rows.data = append(rows.data, []driver.Value{id, 0.0})
+				}
+			}
+			break
+		}
+		for _, h := range pg.textHits(args[0].(string), filters) {
+			rows.data = append(rows.data, []driver.Value{h.id, float64(h.score)})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	return rows, nil
+}
+
+type fakeHit struct {
+	id    string
+	score int
+}
+
+// textHits ranks tools matching any query word, name matches above
+// description matches, then by ID. Callers hold pg.mu.
+func (pg *fakePG) textHits(query string, filters map[string]string) []fakeHit {
+	var hits []fakeHit
+	words := strings.Fields(strings.ToLower(query))
+	for _, id := range sortedKeys(pg.tools) {
+		if !pg.matches(id, filters) {
+			continue
+		}
+		var tool model.Tool
+		_ = json.Unmarshal([]byte(pg.tools[id][1]), &tool)
+		score := 0
+		for _, w := range words {
+			if strings.Contains(strings.ToLower(tool.Name), w) {
+				score += 2
+			}
+			if strings.Contains(strings.ToLower(tool.Description), w) {
+				score++
+			}
+		}
+		if score > 0 {
+			hits = append(hits, fakeHit{id, score})
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b fakeHit) int { return b.score - a.score })
+	return hits
+}
+
+// matches emulates metadata @> filters. Callers hold pg.mu.
+func (pg *fakePG) matches(id string, filters map[string]string) bool {
+	var metadata map[string]string
+	_ = json.Unmarshal([]byte(pg.tools[id][3]), &metadata)
+	for k, v := range filters {
+		if metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type fakeRows struct {
+	cols []string
+	data [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
+
+func values(named []driver.NamedValue) []any {
+	args := make([]any, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+	return args
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,534 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+const (
+	sqlUpsertTool = `INSERT INTO tooldiscovery_tools (id, namespace, tool, backends, metadata, disabled_at)
+VALUES ($1, $2, $3::jsonb, $4::jsonb, $5::jsonb, $6)
+ON CONFLICT (id) DO UPDATE SET namespace = EXCLUDED.namespace, tool = EXCLUDED.tool,
+	backends = EXCLUDED.backends, metadata = EXCLUDED.metadata, disabled_at = EXCLUDED.disabled_at,
+	updated_at = now()`
+	sqlDeleteTool          = `DELETE FROM tooldiscovery_tools WHERE id = $1`
+	sqlSelectTool          = `SELECT id, tool, backends, metadata, disabled_at FROM tooldiscovery_tools WHERE id = $1`
+	sqlSelectToolForUpdate = `SELECT id, tool, backends, metadata, disabled_at FROM tooldiscovery_tools WHERE id = $1 FOR UPDATE`
+	sqlSelectTools         = `SELECT id, tool, backends, metadata, disabled_at FROM tooldiscovery_tools ORDER BY id`
+	sqlInsertCategory      = `INSERT INTO tooldiscovery_categories (path) VALUES ($1) ON CONFLICT (path) DO NOTHING`
+	sqlSelectCategories    = `SELECT path FROM tooldiscovery_categories ORDER BY path`
+	sqlNotify              = `SELECT pg_notify($1, $2)`
+)
+
+// Options configures a Postgres-backed index.
+type Options struct {
+	// Index configures the in-memory index that serves reads. Set
+	// Index.Searcher to a [Searcher] to push full-text search down to
+	// PostgreSQL.
+	Index index.IndexOptions
+
+	// Listener receives change notifications from other replicas. Without
+	// it, replicas converge only when Resync runs.
+	Listener Listener
+
+	// Channel is the NOTIFY channel for tool changes.
+	// Default: DefaultToolsChannel.
+	Channel string
+
+	// ReplicaID identifies this replica in notifications so it skips its
+	// own. Default: a random ID.
+	ReplicaID string
+
+	// Timeout bounds each database round trip. Default: DefaultTimeout.
+	Timeout time.Duration
+
+	// SkipMigrate skips running Migrate on Open, for schemas managed by
+	// other tooling (see Migrations).
+	SkipMigrate bool
+
+	// OnError is called when applying a notification or listening fails.
+	// Optional.
+	OnError func(error)
+}
+
+// Index is an index.Index persisted in PostgreSQL and shared by every
+// replica using the same database.
+//
+// Reads are served by an embedded index.InMemoryIndex loaded on Open.
+// Mutations update memory and write the affected tools in one transaction
+// that also NOTIFYs other replicas, which reload those tools. Each written
+// row is locked with SELECT ... FOR UPDATE and merged with the local change,
+// so backends registered concurrently by other replicas are kept. Like
+// redisindex, the catalog is eventually consistent across replicas, and a
+// missed notification is repaired by the next Resync.
+//
+// Tools, their backends, custom metadata, and disabled state are stored,
+// along with registered categories. Rollouts, maintenance windows, and tool
+// version history are per replica.
+type Index struct {
+	*index.InMemoryIndex
+	*replica
+}
+
+// Open migrates the schema, loads the stored catalog into memory and, when
+// Options.Listener is set, starts applying changes from other replicas.
+func Open(ctx context.Context, db *sql.DB, opts ...Options) (*Index, error) {
+	if db == nil {
+		return nil, ErrInvalidDB
+	}
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Channel == "" {
+		opt.Channel = DefaultToolsChannel
+	}
+	if !opt.SkipMigrate {
+		if err := Migrate(ctx, db); err != nil {
+			return nil, err
+		}
+	}
+
+	idx := &Index{
+		InMemoryIndex: index.NewInMemoryIndex(opt.Index),
+		replica:       newReplica(db, opt.Channel, opt.ReplicaID, opt.Timeout, opt.OnError),
+	}
+	// Listen before loading so changes made while loading are not missed.
+	if opt.Listener != nil {
+		idx.listen(opt.Listener, idx.handleNotification)
+	}
+	if err := idx.Resync(ctx); err != nil {
+		_ = idx.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// RegisterTool registers a tool and stores it.
+func (p *Index) RegisterTool(tool model.Tool, backend model.ToolBackend) error {
+	return p.mutate(func() error {
+		return p.InMemoryIndex.RegisterTool(tool, backend)
+	}, tool.ToolID())
+}
+
+// RegisterToolWithMetadata registers a tool, replaces its metadata, and
+// stores it.
+func (p *Index) RegisterToolWithMetadata(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error {
+	return p.mutate(func() error {
+		return p.InMemoryIndex.RegisterToolWithMetadata(tool, backend, metadata)
+	}, tool.ToolID())
+}
+
+// RegisterTools registers tools in batch and stores them in one
+// transaction. Tools registered before a failing entry are still stored.
+func (p *Index) RegisterTools(regs []index.ToolRegistration) error {
+	ids := make([]string, len(regs))
+	for i, reg := range regs {
+		ids[i] = reg.Tool.ToolID()
+	}
+	return p.mutate(func() error {
+		return p.InMemoryIndex.RegisterTools(regs)
+	}, ids...)
+}
+
+// RegisterToolsFromMCP registers tools from an MCP server and stores them in
+// one transaction.
+func (p *Index) RegisterToolsFromMCP(serverName string, tools []model.Tool) error {
+	ids := make([]string, len(tools))
+	for i, tool := range tools {
+		ids[i] = tool.ToolID()
+	}
+	return p.mutate(func() error {
+		return p.InMemoryIndex.RegisterToolsFromMCP(serverName, tools)
+	}, ids...)
+}
+
+// ReplaceToolsFromMCP atomically replaces the tools of an MCP server (see
+// index.InMemoryIndex.ReplaceToolsFromMCP) and stores the affected tools in
+// one transaction.
+func (p *Index) ReplaceToolsFromMCP(serverName string, tools []model.Tool) error {
+	return p.mutateFunc(func() []string {
+		ids := p.InMemoryIndex.MCPServerToolIDs(serverName)
+		for _, tool := range tools {
+			ids = append(ids, tool.ToolID())
+		}
+		return ids
+	}, func() error {
+		return p.InMemoryIndex.ReplaceToolsFromMCP(serverName, tools)
+	})
+}
+
+// UnregisterBackend removes a backend from a tool and stores the result,
+// deleting the tool's row when its last backend is removed.
+func (p *Index) UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error {
+	return p.mutate(func() error {
+		return p.InMemoryIndex.UnregisterBackend(toolID, kind, backendID)
+	}, toolID)
+}
+
+// DisableTool hides a tool and stores its disabled state.
+func (p *Index) DisableTool(id string) error {
+	return p.mutate(func() error {
+		return p.InMemoryIndex.DisableTool(id)
+	}, id)
+}
+
+// EnableTool restores a tool hidden with DisableTool and stores it.
+func (p *Index) EnableTool(id string) error {
+	return p.mutate(func() error {
+		return p.InMemoryIndex.EnableTool(id)
+	}, id)
+}
+
+// RegisterCategory adds a category to the taxonomy and stores it.
+func (p *Index) RegisterCategory(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	if err := p.InMemoryIndex.RegisterCategory(path); err != nil {
+		return err
+	}
+	n := notification{Origin: p.origin, Categories: true}
+	return p.transact(n, func(ctx context.Context, tx *sql.Tx) error {
+		for _, category := range p.InMemoryIndex.RegisteredCategories() {
+			if _, err := tx.ExecContext(ctx, sqlInsertCategory, category); err != nil {
+				return fmt.Errorf("pgstore: write category %q: %w", category, err)
+			}
+		}
+		return nil
+	})
+}
+
+// mutate applies fn to memory and writes the resulting state of ids. The
+// write happens even if fn fails, since batch operations may have applied
+// some entries before the error. Backends that other replicas added to a
+// stored tool meanwhile are merged into the write and into memory.
+func (p *Index) mutate(fn func() error, ids ...string) error {
+	return p.mutateFunc(func() []string { return ids }, fn)
+}
+
+// mutateFunc is mutate with the IDs computed by idsFn under p.mu before fn
+// runs, so they cannot go stale between the two.
+func (p *Index) mutateFunc(idsFn func() []string, fn func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	ids := idsFn()
+	before := make(map[string]*index.ToolState, len(ids))
+	for _, id := range ids {
+		before[id] = p.localState(id)
+	}
+	applyErr := fn()
+	merged := make(map[string]*index.ToolState)
+	err := p.write(ids, func(ctx context.Context, tx *sql.Tx, id string) error {
+		after := p.localState(id)
+		stored, err := fetchForUpdate(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		state := mergeToolState(stored, before[id], after)
+		if state == nil {
+			_, err = tx.ExecContext(ctx, sqlDeleteTool, id)
+			return err
+		}
+		if after == nil || !state.Equal(*after) {
+			merged[id] = state
+		}
+		tool, backends, metadata, err := encodeToolState(*state)
+		if err != nil {
+			return err
+		}
+		disabledAt := sql.NullTime{Time: state.DisabledAt, Valid: !state.DisabledAt.IsZero()}
+		_, err = tx.ExecContext(ctx, sqlUpsertTool, id, state.Tool.Namespace, tool, backends, metadata, disabledAt)
+		return err
+	})
+	if err != nil {
+		return errors.Join(applyErr, err)
+	}
+	var errs []error
+	for _, id := range slices.Sorted(maps.Keys(merged)) {
+		errs = append(errs, p.InMemoryIndex.SetToolState(id, merged[id]))
+	}
+	return errors.Join(applyErr, errors.Join(errs...))
+}
+
+// localState returns the in-memory state of tool id, or nil when it is not
+// registered.
+func (p *Index) localState(id string) *index.ToolState {
+	state, err := p.InMemoryIndex.GetToolState(id)
+	if err != nil {
+		return nil
+	}
+	return &state
+}
+
+// mergeToolState returns the state to store for a tool that changed
+// locally from before to after while the store held stored: the local
+// change, plus any stored backends the change did not know about. It
+// returns nil when the tool should be deleted.
+func mergeToolState(stored, before, after *index.ToolState) *index.ToolState {
+	if stored == nil {
+		return after
+	}
+	known := func(b model.ToolBackend) bool {
+		return (before != nil && containsBackend(before.Backends, b)) || (after != nil && containsBackend(after.Backends, b))
+	}
+	var extra []model.ToolBackend
+	for _, b := range stored.Backends {
+		if !known(b) {
+			extra = append(extra, b)
+		}
+	}
+	if after == nil {
+		if len(extra) == 0 {
+			return nil
+		}
+		return &index.ToolState{Tool: stored.Tool, Backends: extra, Metadata: stored.Metadata, DisabledAt: stored.DisabledAt}
+	}
+	merged := *after
+	merged.Backends = append(slices.Clone(after.Backends), extra...)
+	return &merged
+}
+
+func containsBackend(backends []model.ToolBackend, backend model.ToolBackend) bool {
+	return slices.ContainsFunc(backends, func(b model.ToolBackend) bool {
+		return b.Kind == backend.Kind && index.BackendID(b) == index.BackendID(backend)
+	})
+}
+
+// Resync reloads the stored catalog, applying every tool that differs from
+// memory, removing tools deleted by other replicas, and adding their
+// categories. Run it periodically to repair missed notifications; it has
+// the scheduler.JobFunc signature.
+func (p *Index) Resync(ctx context.Context) error {
+	rows, err := p.fetchAll(ctx)
+	if err != nil {
+		return err
+	}
+	categories, err := p.fetchCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	var errs []error
+	for _, id := range p.InMemoryIndex.ToolIDs() {
+		if _, ok := rows[id]; !ok {
+			errs = append(errs, p.applyLocked(id, nil))
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(rows)) {
+		row := rows[id]
+		errs = append(errs, p.applyLocked(id, &row))
+	}
+	errs = append(errs, p.applyCategoriesLocked(categories))
+	return errors.Join(errs...)
+}
+
+// handleNotification reloads the tools changed by another replica.
+func (p *Index) handleNotification(n notification) {
+	if n.Resync {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+		if err := p.Resync(ctx); err != nil {
+			p.report(err)
+		}
+		return
+	}
+	if n.Categories {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		categories, err := p.fetchCategories(ctx)
+		cancel()
+		if err == nil {
+			p.mu.Lock()
+			if !p.closed {
+				err = p.applyCategoriesLocked(categories)
+			}
+			p.mu.Unlock()
+		}
+		if err != nil {
+			p.report(err)
+		}
+	}
+	for _, id := range n.IDs {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		state, err := p.fetch(ctx, id)
+		cancel()
+		if err != nil {
+			p.report(err)
+			continue
+		}
+		p.mu.Lock()
+		if !p.closed {
+			err = p.applyLocked(id, state)
+		}
+		p.mu.Unlock()
+		if err != nil {
+			p.report(err)
+		}
+	}
+}
+
+// applyLocked makes the in-memory tool id match state, or removes it when
+// state is nil.
+func (p *Index) applyLocked(id string, state *index.ToolState) error {
+	if err := p.InMemoryIndex.SetToolState(id, state); err != nil {
+		return fmt.Errorf("pgstore: apply tool %s: %w", id, err)
+	}
+	return nil
+}
+
+// applyCategoriesLocked registers the stored categories in memory.
+func (p *Index) applyCategoriesLocked(categories []string) error {
+	var errs []error
+	for _, category := range categories {
+		if err := p.InMemoryIndex.RegisterCategory(category); err != nil {
+			errs = append(errs, fmt.Errorf("%w: category %q: %v", ErrInvalidStore, category, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fetchCategories reads the registered categories.
+func (p *Index) fetchCategories(ctx context.Context) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx, sqlSelectCategories)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: read categories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var categories []string
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("pgstore: read categories: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: read categories: %w", err)
+	}
+	return categories, nil
+}
+
+// fetch reads tool id, returning nil when it does not exist.
+func (p *Index) fetch(ctx context.Context, id string) (*index.ToolState, error) {
+	rows, err := p.db.QueryContext(ctx, sqlSelectTool, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: read tool %s: %w", id, err)
+	}
+	return singleToolRow(rows, id)
+}
+
+// fetchForUpdate reads and locks the row of tool id in tx, returning nil
+// when it does not exist.
+func fetchForUpdate(ctx context.Context, tx *sql.Tx, id string) (*index.ToolState, error) {
+	rows, err := tx.QueryContext(ctx, sqlSelectToolForUpdate, id)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: read tool %s: %w", id, err)
+	}
+	return singleToolRow(rows, id)
+}
+
+func singleToolRow(rows *sql.Rows, id string) (*index.ToolState, error) {
+	found, err := scanToolRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := found[id]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (p *Index) fetchAll(ctx context.Context) (map[string]index.ToolState, error) {
+	rows, err := p.db.QueryContext(ctx, sqlSelectTools)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: read tools: %w", err)
+	}
+	return scanToolRows(rows)
+}
+
+func scanToolRows(rows *sql.Rows) (map[string]index.ToolState, error) {
+	defer func() { _ = rows.Close() }()
+	found := make(map[string]index.ToolState)
+	for rows.Next() {
+		var (
+			id                       string
+			tool, backends, metadata []byte
+			disabledAt               sql.NullTime
+		)
+		if err := rows.Scan(&id, &tool, &backends, &metadata, &disabledAt); err != nil {
+			return nil, fmt.Errorf("pgstore: read tools: %w", err)
+		}
+		var row index.ToolState
+		if err := json.Unmarshal(tool, &row.Tool); err != nil {
+			return nil, fmt.Errorf("%w: tool %s: %v", ErrInvalidStore, id, err)
+		}
+		if err := json.Unmarshal(backends, &row.Backends); err != nil {
+			return nil, fmt.Errorf("%w: tool %s backends: %v", ErrInvalidStore, id, err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &row.Metadata); err != nil {
+				return nil, fmt.Errorf("%w: tool %s metadata: %v", ErrInvalidStore, id, err)
+			}
+		}
+		if disabledAt.Valid {
+			row.DisabledAt = disabledAt.Time
+		}
+		found[id] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: read tools: %w", err)
+	}
+	return found, nil
+}
+
+func encodeToolState(row index.ToolState) (tool, backends, metadata string, err error) {
+	t, err := json.Marshal(row.Tool)
+	if err != nil {
+		return "", "", "", err
+	}
+	b, err := json.Marshal(row.Backends)
+	if err != nil {
+		return "", "", "", err
+	}
+	if row.Metadata == nil {
+		row.Metadata = map[string]string{}
+	}
+	m, err := json.Marshal(row.Metadata)
+	if err != nil {
+		return "", "", "", err
+	}
+	return string(t), string(b), string(m), nil
+}
+
+// Close stops applying notifications. The in-memory index keeps serving
+// reads; mutations return ErrClosed. Close is idempotent and does not close
+// the *sql.DB.
+func (p *Index) Close() error {
+	p.close()
+	return nil
+}
+
+var (
+	_ index.Index       = (*Index)(nil)
+	_ index.MCPReplacer = (*Index)(nil)
+)
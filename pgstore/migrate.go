@@ -0,0 +1,128 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// migrations are applied in order and never edited once released; schema
+// changes append a new version.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create tools and docs",
+		SQL: `CREATE TABLE IF NOT EXISTS tooldiscovery_tools (
+	id         TEXT PRIMARY KEY,
+	namespace  TEXT NOT NULL,
+	tool       JSONB NOT NULL,
+	backends   JSONB NOT NULL,
+	metadata   JSONB NOT NULL DEFAULT '{}',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS tooldiscovery_tools_namespace ON tooldiscovery_tools (namespace);
+CREATE TABLE IF NOT EXISTS tooldiscovery_docs (
+	id         TEXT PRIMARY KEY,
+	doc        JSONB NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`,
+	},
+	{
+		Version: 2,
+		Name:    "add tool full-text search",
+		SQL: `ALTER TABLE tooldiscovery_tools ADD COLUMN IF NOT EXISTS search TSVECTOR
+	GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(tool->>'name', '')), 'A') ||
+		setweight(to_tsvector('english', namespace), 'B') ||
+		setweight(jsonb_to_tsvector('english', coalesce(tool->'tags', '[]'), '["string"]'), 'B') ||
+		setweight(to_tsvector('english', coalesce(tool->>'description', '')), 'C')
+	) STORED;
+CREATE INDEX IF NOT EXISTS tooldiscovery_tools_search ON tooldiscovery_tools USING GIN (search);`,
+	},
+	{
+		Version: 3,
+		Name:    "add disabled tools and categories",
+		SQL: `ALTER TABLE tooldiscovery_tools ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+CREATE TABLE IF NOT EXISTS tooldiscovery_categories (
+	path       TEXT PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`,
+	},
+}
+
+// migrationLockID is the advisory lock key held while migrating, so replicas
+// starting together apply each migration once.
+const migrationLockID = 7_348_201_559
+
+const (
+	sqlCreateMigrationsTable = `CREATE TABLE IF NOT EXISTS tooldiscovery_schema_migrations (
+	version    INTEGER PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+	sqlLockMigrations      = `SELECT pg_advisory_xact_lock($1)`
+	sqlSelectSchemaVersion = `SELECT COALESCE(MAX(version), 0) FROM tooldiscovery_schema_migrations`
+	sqlInsertMigration     = `INSERT INTO tooldiscovery_schema_migrations (version, name) VALUES ($1, $2)`
+)
+
+// Migrations returns the schema migrations in order, for teams that apply
+// schema changes with their own tooling and open stores with SkipMigrate.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// SchemaVersion returns the latest migration version this package expects.
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate applies pending migrations, each in its own transaction, and
+// records them in tooldiscovery_schema_migrations. It is safe to run from
+// several processes at once.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if db == nil {
+		return ErrInvalidDB
+	}
+	if _, err := db.ExecContext(ctx, sqlCreateMigrationsTable); err != nil {
+		return fmt.Errorf("pgstore: migrate: %w", err)
+	}
+	for _, m := range migrations {
+		if err := applyMigration(ctx, db, m); err != nil {
+			return fmt.Errorf("pgstore: migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqlLockMigrations, migrationLockID); err != nil {
+		return err
+	}
+	var current int
+	if err := tx.QueryRowContext(ctx, sqlSelectSchemaVersion).Scan(&current); err != nil {
+		return err
+	}
+	if current >= m.Version {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, sqlInsertMigration, m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
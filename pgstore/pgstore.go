@@ -0,0 +1,214 @@
+package pgstore
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds each database round trip when an Options.Timeout is
+// zero.
+const DefaultTimeout = 5 * time.Second
+
+// Default NOTIFY channels for tool and documentation changes.
+const (
+	DefaultToolsChannel = "tooldiscovery_tools"
+	DefaultDocsChannel  = "tooldiscovery_docs"
+)
+
+// maxNotifyPayload keeps notifications under PostgreSQL's 8000-byte NOTIFY
+// payload limit. Larger change sets ask receivers to resync instead.
+const maxNotifyPayload = 7900
+
+var (
+	// ErrClosed is returned by mutations after Close.
+	ErrClosed = errors.New("pgstore: closed")
+
+	// ErrInvalidDB is returned when a nil *sql.DB is supplied.
+	ErrInvalidDB = errors.New("pgstore: invalid database")
+
+	// ErrInvalidStore is returned when the database holds rows this package
+	// cannot decode.
+	ErrInvalidStore = errors.New("pgstore: invalid store")
+)
+
+// Listener delivers notifications sent with NOTIFY on a PostgreSQL channel.
+// database/sql has no LISTEN support, so it is adapted from the driver.
+// With github.com/jackc/pgx/v5:
+//
+//	listener := pgstore.ListenerFunc(func(ctx context.Context, channel string, handle func(string)) error {
+//	    conn, err := pgx.Connect(ctx, dsn)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    defer conn.Close(context.Background())
+//	    if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+//	        return err
+//	    }
+//	    for {
+//	        n, err := conn.WaitForNotification(ctx)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        handle(n.Payload)
+//	    }
+//	})
+//
+// Contract:
+//   - Listen blocks, calling handle for each notification in order, until ctx
+//     is canceled or the connection fails.
+//   - handle may block; implementations must not call it concurrently.
+type Listener interface {
+	Listen(ctx context.Context, channel string, handle func(payload string)) error
+}
+
+// ListenerFunc adapts a function to Listener.
+type ListenerFunc func(ctx context.Context, channel string, handle func(payload string)) error
+
+// Listen calls f.
+func (f ListenerFunc) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	return f(ctx, channel, handle)
+}
+
+// notification is sent with NOTIFY in the transaction that changes the rows,
+// so it is delivered only once they are committed.
+type notification struct {
+	Origin     string   `json:"origin"`
+	IDs        []string `json:"ids,omitempty"`
+	Resync     bool     `json:"resync,omitempty"`
+	Categories bool     `json:"categories,omitempty"`
+}
+
+// replica holds the write-through and LISTEN plumbing shared by Index and
+// DocStore.
+type replica struct {
+	db      *sql.DB
+	channel string
+	origin  string
+	timeout time.Duration
+	onError func(error)
+
+	// mu serializes local mutations and applied notifications so database
+	// writes follow memory order.
+	mu     sync.Mutex
+	closed bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newReplica(db *sql.DB, channel, origin string, timeout time.Duration, onError func(error)) *replica {
+	if origin == "" {
+		origin = randomID()
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &replica{db: db, channel: channel, origin: origin, timeout: timeout, onError: onError}
+}
+
+// listen starts delivering notifications from other replicas to apply.
+func (r *replica) listen(listener Listener, apply func(notification)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		err := listener.Listen(ctx, r.channel, func(payload string) {
+			var n notification
+			if err := json.Unmarshal([]byte(payload), &n); err != nil {
+				r.report(fmt.Errorf("%w: notification: %v", ErrInvalidStore, err))
+				return
+			}
+			if n.Origin != r.origin {
+				apply(n)
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			r.report(fmt.Errorf("pgstore: listen: %w", err))
+		}
+	}()
+}
+
+// write runs fn and a NOTIFY naming ids in one transaction.
+func (r *replica) write(ids []string, fn func(ctx context.Context, tx *sql.Tx, id string) error) error {
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "" })
+	if len(ids) == 0 {
+		return nil
+	}
+	n := notification{Origin: r.origin, IDs: ids}
+	return r.transact(n, func(ctx context.Context, tx *sql.Tx) error {
+		for _, id := range ids {
+			if err := fn(ctx, tx, id); err != nil {
+				return fmt.Errorf("pgstore: write %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// transact runs fn and a NOTIFY sending n in one transaction.
+func (r *replica) transact(n notification, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		payload, _ = json.Marshal(notification{Origin: r.origin, Resync: true})
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("pgstore: begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, sqlNotify, r.channel, string(payload)); err != nil {
+		return fmt.Errorf("pgstore: notify: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("pgstore: commit: %w", err)
+	}
+	return nil
+}
+
+// close stops applying notifications. It is idempotent.
+func (r *replica) close() {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (r *replica) report(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+func randomID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,437 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/indextest"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func testTool(namespace, name, desc string) model.Tool {
+	return model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: desc,
+			InputSchema: map[string]any{"type": "object"},
+		},
+		Namespace: namespace,
+	}
+}
+
+func mcpBackend(server string) model.ToolBackend {
+	return model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: server}}
+}
+
+func openIndex(t *testing.T, pg *fakePG, opts Options) *Index {
+	t.Helper()
+	if opts.Listener == nil {
+		opts.Listener = pg
+	}
+	opts.OnError = func(err error) { t.Errorf("replica error: %v", err) }
+	idx, err := Open(context.Background(), pg.open(t), opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx
+}
+
+// eventually polls cond until it holds or a second passes.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	pg := newFakePG()
+	db := pg.open(t)
+	for range 2 {
+		if err := Migrate(context.Background(), db); err != nil {
+			t.Fatalf("Migrate failed: %v", err)
+		}
+	}
+	if pg.migrations != len(migrations) {
+		t.Errorf("ran %d migration bodies, want %d", pg.migrations, len(migrations))
+	}
+	if _, ok := pg.versions[SchemaVersion()]; !ok || len(pg.versions) != len(Migrations()) {
+		t.Errorf("recorded versions = %v", pg.versions)
+	}
+	if err := Migrate(context.Background(), nil); !errors.Is(err, ErrInvalidDB) {
+		t.Errorf("Migrate(nil) error = %v, want ErrInvalidDB", err)
+	}
+}
+
+func TestIndex_ReplicasShareCatalog(t *testing.T) {
+	pg := newFakePG()
+	a := openIndex(t, pg, Options{})
+	b := openIndex(t, pg, Options{})
+	eventually(t, "both replicas to listen", func() bool { return pg.listenerCount(DefaultToolsChannel) == 2 })
+
+	var events []index.ChangeEvent
+	var mu sync.Mutex
+	b.OnChange(func(e index.ChangeEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+
+	if err := a.RegisterToolWithMetadata(testTool("github", "create_issue", "v1"), mcpBackend("gh"), map[string]string{"team": "dev"}); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	_ = a.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh-backup"))
+	eventually(t, "replica b to see both backends", func() bool {
+		backends, _ := b.GetAllBackends("github:create_issue")
+		return len(backends) == 2
+	})
+	if metadata, _ := b.GetMetadata("github:create_issue"); metadata["team"] != "dev" {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	// A changed definition replaces the tool on other replicas.
+	_ = a.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh-backup")
+	_ = a.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh")
+	_ = a.RegisterTool(testTool("github", "create_issue", "v2"), mcpBackend("gh"))
+	eventually(t, "replica b to see v2", func() bool {
+		tool, _, err := b.GetTool("github:create_issue")
+		return err == nil && tool.Description == "v2"
+	})
+
+	// Removal on b reaches a and deletes the row.
+	if err := b.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh"); err != nil {
+		t.Fatalf("UnregisterBackend on b failed: %v", err)
+	}
+	eventually(t, "replica a to drop the tool", func() bool {
+		_, _, err := a.GetTool("github:create_issue")
+		return errors.Is(err, index.ErrNotFound)
+	})
+	if n := pg.count(pg.tools); n != 0 {
+		t.Errorf("expected no stored tools, got %d", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) == 0 || events[0].Type != index.ChangeRegistered {
+		t.Errorf("replica b events = %v", events)
+	}
+}
+
+func TestIndex_StoreContract(t *testing.T) {
+	indextest.TestStore(t, func(t *testing.T) (indextest.Store, indextest.Reload) {
+		pg := newFakePG()
+		return openIndex(t, pg, Options{}), func(t *testing.T, _ indextest.Store) indextest.Store {
+			return openIndex(t, pg, Options{})
+		}
+	})
+}
+
+func TestIndex_DisabledAndCategoriesPropagate(t *testing.T) {
+	pg := newFakePG()
+	a := openIndex(t, pg, Options{})
+	b := openIndex(t, pg, Options{})
+	eventually(t, "both replicas to listen", func() bool { return pg.listenerCount(DefaultToolsChannel) == 2 })
+
+	_ = a.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh"))
+	if err := a.DisableTool("github:create_issue"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	if err := a.RegisterCategory("devops/containers"); err != nil {
+		t.Fatalf("RegisterCategory failed: %v", err)
+	}
+	eventually(t, "replica b to see the tool disabled", func() bool {
+		return len(b.ListDisabled()) == 1
+	})
+	eventually(t, "replica b to see the category", func() bool {
+		return slices.Equal(b.RegisteredCategories(), []string{"devops/containers"})
+	})
+
+	if err := a.EnableTool("github:create_issue"); err != nil {
+		t.Fatalf("EnableTool failed: %v", err)
+	}
+	eventually(t, "replica b to see the tool enabled", func() bool {
+		_, _, err := b.GetTool("github:create_issue")
+		return err == nil
+	})
+}
+
+func TestIndex_OpenLoadsAndResyncRepairs(t *testing.T) {
+	pg := newFakePG()
+	writer := openIndex(t, pg, Options{})
+	_ = writer.RegisterTool(testTool("k8s", "list_pods", "List pods"), mcpBackend("k8s"))
+	_ = writer.RegisterTool(testTool("", "echo", "Echo"), mcpBackend("local"))
+
+	// With a listener that never delivers, the reader converges on Resync.
+	reader := openIndex(t, pg, Options{Listener: ListenerFunc(func(ctx context.Context, _ string, _ func(string)) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})})
+	if ids := reader.ToolIDs(); !slices.Equal(ids, []string{"echo", "k8s:list_pods"}) {
+		t.Fatalf("loaded tools = %v", ids)
+	}
+
+	_ = writer.UnregisterBackend("echo", model.BackendKindMCP, "local")
+	_ = writer.RegisterTool(testTool("k8s", "get_logs", "Get logs"), mcpBackend("k8s"))
+	if err := reader.Resync(context.Background()); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	if ids := reader.ToolIDs(); !slices.Equal(ids, []string{"k8s:get_logs", "k8s:list_pods"}) {
+		t.Fatalf("tools after Resync = %v", ids)
+	}
+
+	version := reader.Version()
+	_ = reader.Resync(context.Background())
+	if reader.Version() != version {
+		t.Errorf("Resync of an unchanged catalog bumped the version")
+	}
+}
+
+func TestIndex_ConcurrentBackendsMerge(t *testing.T) {
+	pg := newFakePG()
+	deaf := Options{Listener: ListenerFunc(func(ctx context.Context, _ string, _ func(string)) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})}
+	a := openIndex(t, pg, deaf)
+	b := openIndex(t, pg, deaf)
+	tool := testTool("fs", "read", "Read a file")
+
+	// Neither replica has seen the other's backend when it writes.
+	if err := a.RegisterTool(tool, mcpBackend("s1")); err != nil {
+		t.Fatalf("RegisterTool(s1) failed: %v", err)
+	}
+	if err := b.RegisterTool(tool, mcpBackend("s2")); err != nil {
+		t.Fatalf("RegisterTool(s2) failed: %v", err)
+	}
+	if backends, _ := b.GetAllBackends("fs:read"); len(backends) != 2 {
+		t.Errorf("writer backends = %v, want both after merge", backends)
+	}
+
+	// Removing the last backend a replica knows keeps the other's.
+	if err := a.UnregisterBackend("fs:read", model.BackendKindMCP, "s1"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if err := a.Resync(context.Background()); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	backends, err := a.GetAllBackends("fs:read")
+	if err != nil || len(backends) != 1 || backends[0].MCP.ServerName != "s2" {
+		t.Errorf("backends after unregister = %v, %v; want only s2", backends, err)
+	}
+}
+
+func TestIndex_WriteFailure(t *testing.T) {
+	pg := newFakePG()
+	idx := openIndex(t, pg, Options{})
+	pg.setFail(errors.New("connection reset"))
+	err := idx.RegisterTool(testTool("ns", "tool", "d"), mcpBackend("s"))
+	if err == nil {
+		t.Fatal("expected the write failure to be returned")
+	}
+	pg.setFail(nil)
+	if n := pg.count(pg.tools); n != 0 {
+		t.Errorf("expected nothing stored, got %d tools", n)
+	}
+}
+
+func TestIndex_Close(t *testing.T) {
+	pg := newFakePG()
+	idx := openIndex(t, pg, Options{})
+	_ = idx.RegisterTool(testTool("ns", "tool", "d"), mcpBackend("s"))
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	if err := idx.RegisterTool(testTool("ns", "other", "d"), mcpBackend("s")); !errors.Is(err, ErrClosed) {
+		t.Errorf("RegisterTool after Close error = %v, want ErrClosed", err)
+	}
+	if _, _, err := idx.GetTool("ns:tool"); err != nil {
+		t.Errorf("reads should keep working after Close: %v", err)
+	}
+}
+
+func TestOpen_InvalidStore(t *testing.T) {
+	pg := newFakePG()
+	pg.tools["ns:bad"] = []string{"ns", "{", "[]", "{}", ""}
+	if _, err := Open(context.Background(), pg.open(t)); !errors.Is(err, ErrInvalidStore) {
+		t.Errorf("Open error = %v, want ErrInvalidStore", err)
+	}
+	if _, err := Open(context.Background(), nil); !errors.Is(err, ErrInvalidDB) {
+		t.Errorf("Open(nil) error = %v, want ErrInvalidDB", err)
+	}
+}
+
+func TestSearcher_Pushdown(t *testing.T) {
+	pg := newFakePG()
+	db := pg.open(t)
+	var fallbackErr error
+	searcher, err := NewSearcher(db, SearcherOptions{
+		Fallback: fallbackSearcher{},
+		OnError:  func(err error) { fallbackErr = err },
+	})
+	if err != nil {
+		t.Fatalf("NewSearcher failed: %v", err)
+	}
+	idx := openIndex(t, pg, Options{Index: index.IndexOptions{Searcher: searcher}})
+	_ = idx.RegisterTool(testTool("github", "create_issue", "Create an issue"), mcpBackend("gh"))
+	_ = idx.RegisterTool(testTool("github", "list_repos", "List repos with open issue counts"), mcpBackend("gh"))
+	_ = idx.RegisterTool(testTool("k8s", "get_pods", "Get pods"), mcpBackend("k8s"))
+
+	results, err := idx.Search("issue", 10)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if ids := summaryIDs(results); !slices.Equal(ids, []string{"github:create_issue", "github:list_repos"}) {
+		t.Errorf("ranked ids = %v", ids)
+	}
+
+	// Results are limited to the docs the index passes in.
+	results, _ = idx.Search("issue metadata.team=none", 10)
+	if len(results) != 0 {
+		t.Errorf("metadata filter ignored: %v", summaryIDs(results))
+	}
+
+	// Database failures fall back to the in-process searcher.
+	pg.setFail(errors.New("connection reset"))
+	results, err = idx.Search("pods", 10)
+	pg.setFail(nil)
+	if err != nil || len(results) != 1 || fallbackErr == nil {
+		t.Errorf("fallback results = %v, err = %v, reported = %v", summaryIDs(results), err, fallbackErr)
+	}
+}
+
+// fallbackSearcher returns every doc, marking that the fallback answered.
+type fallbackSearcher struct{}
+
+func (fallbackSearcher) Search(_ string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	var out []index.Summary
+	for _, doc := range docs {
+		if doc.ID == "k8s:get_pods" && len(out) < limit {
+			out = append(out, doc.Summary)
+		}
+	}
+	return out, nil
+}
+
+func summaryIDs(results []index.Summary) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestIndex_PushdownSearch(t *testing.T) {
+	pg := newFakePG()
+	idx := openIndex(t, pg, Options{})
+	ctx := context.Background()
+	_ = idx.RegisterToolWithMetadata(testTool("github", "create_issue", "Create an issue"), mcpBackend("gh"), map[string]string{"team": "dev"})
+	_ = idx.RegisterToolWithMetadata(testTool("github", "close_issue", "Close an issue"), mcpBackend("gh"), map[string]string{"team": "ops"})
+	_ = idx.RegisterToolWithMetadata(testTool("github", "list_repos", "List repos with open issue counts"), mcpBackend("gh"), map[string]string{"team": "dev"})
+
+	results, err := idx.PushdownSearch(ctx, index.PushdownQuery{Text: "issue", Filters: map[string]string{"team": "dev"}, Limit: 10})
+	if err != nil {
+		t.Fatalf("PushdownSearch failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Summary.ID != "github:create_issue" || results[1].Summary.ID != "github:list_repos" {
+		t.Fatalf("results = %+v", results)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("scores not descending: %v, %v", results[0].Score, results[1].Score)
+	}
+
+	// Rollouts are applied from memory.
+	_ = idx.SetRollout(index.Rollout{ToolID: "github:create_issue", Principals: []string{"agent-1"}})
+	results, _ = idx.PushdownSearch(ctx, index.PushdownQuery{Text: "issue", Limit: 10})
+	if len(results) != 2 || results[0].Summary.ID != "github:close_issue" {
+		t.Errorf("anonymous results = %+v, want create_issue hidden", results)
+	}
+	results, _ = idx.PushdownSearch(ctx, index.PushdownQuery{Principal: "agent-1", Text: "create issue", Limit: 1})
+	if len(results) != 1 || results[0].Summary.ID != "github:create_issue" {
+		t.Errorf("principal results = %+v", results)
+	}
+
+	// Empty text lists filtered tools by ID.
+	results, _ = idx.PushdownSearch(ctx, index.PushdownQuery{Principal: "agent-1", Filters: map[string]string{"team": "dev"}, Limit: 10})
+	if len(results) != 2 || results[0].Summary.ID != "github:create_issue" {
+		t.Errorf("list results = %+v", results)
+	}
+
+	if _, err := idx.PushdownSearch(ctx, index.PushdownQuery{Text: "issue", Vector: []float32{1}, Limit: 10}); !errors.Is(err, index.ErrPushdownUnsupported) {
+		t.Errorf("vector query err = %v, want ErrPushdownUnsupported", err)
+	}
+}
+
+func TestDocStore_ReplicasShareDocs(t *testing.T) {
+	pg := newFakePG()
+	open := func() *DocStore {
+		s, err := OpenDocStore(context.Background(), pg.open(t), DocStoreOptions{
+			Listener: pg,
+			OnError:  func(err error) { t.Errorf("replica error: %v", err) },
+		})
+		if err != nil {
+			t.Fatalf("OpenDocStore failed: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+	a, b := open(), open()
+	eventually(t, "both replicas to listen", func() bool { return pg.listenerCount(DefaultDocsChannel) == 2 })
+
+	err := a.RegisterDoc("github:create_issue", tooldoc.DocEntry{
+		Summary:  "Opens an issue",
+		Examples: []tooldoc.ToolExample{{ID: "basic", Title: "Basic", Args: map[string]any{"title": "Bug", "count": 2}}},
+		Owner:    &tooldoc.Owner{Team: "dev"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterDoc failed: %v", err)
+	}
+	eventually(t, "replica b to see the doc", func() bool {
+		entry, err := b.GetDoc("github:create_issue")
+		return err == nil && entry.Summary == "Opens an issue"
+	})
+	entry, _ := b.GetDoc("github:create_issue")
+	if len(entry.Examples) != 1 || entry.Examples[0].Args["count"] != 2.0 || entry.Owner == nil || entry.Owner.Team != "dev" {
+		t.Errorf("replicated doc = %+v", entry)
+	}
+
+	_ = a.DeleteExample("github:create_issue", "basic")
+	eventually(t, "replica b to drop the example", func() bool {
+		entry, _ := b.GetDoc("github:create_issue")
+		return len(entry.Examples) == 0
+	})
+
+	if err := b.RemoveDoc("github:create_issue"); err != nil {
+		t.Fatalf("RemoveDoc failed: %v", err)
+	}
+	eventually(t, "replica a to drop the doc", func() bool {
+		_, err := a.GetDoc("github:create_issue")
+		return errors.Is(err, tooldoc.ErrNotFound)
+	})
+	if n := pg.count(pg.docs); n != 0 {
+		t.Errorf("expected no stored docs, got %d", n)
+	}
+
+	// Failed in-memory mutations write nothing.
+	if err := a.UpdateExample("missing", "x", tooldoc.ToolExample{}); !errors.Is(err, tooldoc.ErrNotFound) {
+		t.Errorf("UpdateExample error = %v, want ErrNotFound", err)
+	}
+	if n := pg.count(pg.docs); n != 0 {
+		t.Errorf("failed mutation was stored: %d docs", n)
+	}
+}
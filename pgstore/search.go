@@ -0,0 +1,197 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// sqlSearchTools ranks stored tools with PostgreSQL full-text search over the
+// generated search column: name first, then namespace and tags, then
+// description.
+const sqlSearchTools = `SELECT id FROM tooldiscovery_tools
+WHERE search @@ websearch_to_tsquery('english', $1)
+ORDER BY ts_rank(search, websearch_to_tsquery('english', $1)) DESC, id`
+
+// sqlPushdownSearch and sqlPushdownList back Index.PushdownSearch, filtering
+// on metadata with JSONB containment.
+const (
+	sqlPushdownSearch = `SELECT id, ts_rank(search, query) AS score
+FROM tooldiscovery_tools, websearch_to_tsquery('english', $1) AS query
+WHERE search @@ query AND metadata @> $2::jsonb
+ORDER BY score DESC, id`
+	sqlPushdownList = `SELECT id, 0.0 FROM tooldiscovery_tools WHERE metadata @> $1::jsonb ORDER BY id`
+)
+
+// PushdownSearch ranks tools with PostgreSQL full-text search and filters
+// on metadata in the database, implementing index.SearchPushdown. Queries
+// carrying a vector return index.ErrPushdownUnsupported, since the schema
+// has no embedding column.
+func (p *Index) PushdownSearch(ctx context.Context, q index.PushdownQuery) ([]index.PushdownResult, error) {
+	if q.Limit <= 0 {
+		return []index.PushdownResult{}, nil
+	}
+	if q.Vector != nil {
+		return nil, fmt.Errorf("%w: pgstore has no vector column", index.ErrPushdownUnsupported)
+	}
+	filters := q.Filters
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	filterJSON, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	var rows *sql.Rows
+	if strings.TrimSpace(q.Text) == "" {
+		rows, err = p.db.QueryContext(ctx, sqlPushdownList, string(filterJSON))
+	} else {
+		rows, err = p.db.QueryContext(ctx, sqlPushdownSearch, q.Text, string(filterJSON))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: search: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var (
+		ids    []string
+		scores = make(map[string]float64)
+	)
+	for rows.Next() {
+		var (
+			id    string
+			score float64
+		)
+		if err := rows.Scan(&id, &score); err != nil {
+			return nil, fmt.Errorf("pgstore: search: %w", err)
+		}
+		ids = append(ids, id)
+		scores[id] = score
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: search: %w", err)
+	}
+
+	// Memory decides visibility, so rollouts and maintenance still apply.
+	summaries := p.InMemoryIndex.SummariesFor(q.Principal, ids)
+	results := make([]index.PushdownResult, 0, min(q.Limit, len(summaries)))
+	for _, s := range summaries[:min(q.Limit, len(summaries))] {
+		results = append(results, index.PushdownResult{Summary: s, Score: scores[s.ID]})
+	}
+	return results, nil
+}
+
+// SearcherOptions configures a Searcher.
+type SearcherOptions struct {
+	// Fallback handles empty queries and, when the database fails, every
+	// query. Without it, empty queries return the first documents and
+	// database failures are returned.
+	Fallback index.Searcher
+
+	// Timeout bounds each query. Default: DefaultTimeout.
+	Timeout time.Duration
+
+	// OnError is called when a query fails and Fallback answers it.
+	// Optional.
+	OnError func(error)
+}
+
+// Searcher is an index.Searcher that ranks tools with PostgreSQL full-text
+// search instead of in process. Ranking reads the tooldiscovery_tools table,
+// so use it with an [Index] on the same database; results are limited to
+// the docs the index passes in, so rollout and metadata filters still apply.
+//
+// Searcher is safe for concurrent use.
+type Searcher struct {
+	db   *sql.DB
+	opts SearcherOptions
+}
+
+// NewSearcher returns a Searcher querying db.
+func NewSearcher(db *sql.DB, opts ...SearcherOptions) (*Searcher, error) {
+	if db == nil {
+		return nil, ErrInvalidDB
+	}
+	var opt SearcherOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultTimeout
+	}
+	return &Searcher{db: db, opts: opt}, nil
+}
+
+// Search returns up to limit of docs, ordered by full-text rank with ties
+// broken by ID.
+func (s *Searcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	if limit <= 0 || len(docs) == 0 {
+		return []index.Summary{}, nil
+	}
+	if strings.TrimSpace(query) == "" {
+		if s.opts.Fallback != nil {
+			return s.opts.Fallback.Search(query, limit, docs)
+		}
+		results := make([]index.Summary, 0, min(limit, len(docs)))
+		for _, doc := range docs[:min(limit, len(docs))] {
+			results = append(results, doc.Summary)
+		}
+		return results, nil
+	}
+
+	results, err := s.search(query, limit, docs)
+	if err != nil && s.opts.Fallback != nil {
+		if s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+		return s.opts.Fallback.Search(query, limit, docs)
+	}
+	return results, err
+}
+
+func (s *Searcher) search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	byID := make(map[string]index.Summary, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc.Summary
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, sqlSearchTools, query)
+	if err != nil {
+		return nil, fmt.Errorf("pgstore: search: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	results := make([]index.Summary, 0, min(limit, len(docs)))
+	for len(results) < limit && rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("pgstore: search: %w", err)
+		}
+		if summary, ok := byID[id]; ok {
+			results = append(results, summary)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgstore: search: %w", err)
+	}
+	return results, nil
+}
+
+// Deterministic reports that results are stably ordered, so the searcher
+// supports SearchPage.
+func (s *Searcher) Deterministic() bool { return true }
+
+var (
+	_ index.Searcher              = (*Searcher)(nil)
+	_ index.DeterministicSearcher = (*Searcher)(nil)
+	_ index.SearchPushdown        = (*Index)(nil)
+)
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jonwraymond/toolfoundation/adapter"
+)
+
+// ErrInvalidCapabilities is returned by RegisterProvider and
+// ParseCapabilities when a provider's structured capabilities are malformed.
+var ErrInvalidCapabilities = errors.New("invalid provider capabilities")
+
+// Capability keys in adapter.CanonicalProvider.Capabilities that hold the
+// structured capability model. Other keys, such as A2A's "streaming", are
+// free-form and not validated.
+const (
+	CapabilityModalities  = "modalities"
+	CapabilityAuthSchemes = "authSchemes"
+	CapabilityRateLimit   = "rateLimit"
+)
+
+// Supported modalities.
+const (
+	ModalityText  = "text"
+	ModalityImage = "image"
+	ModalityAudio = "audio"
+	ModalityVideo = "video"
+	ModalityFile  = "file"
+	ModalityData  = "data"
+)
+
+// Supported auth schemes, matching OpenAPI security scheme types, plus
+// AuthNone for providers that need no credentials.
+const (
+	AuthNone          = "none"
+	AuthAPIKey        = "apiKey"
+	AuthHTTP          = "http"
+	AuthOAuth2        = "oauth2"
+	AuthOpenIDConnect = "openIdConnect"
+	AuthMutualTLS     = "mutualTLS"
+)
+
+var (
+	modalities  = []string{ModalityText, ModalityImage, ModalityAudio, ModalityVideo, ModalityFile, ModalityData}
+	authSchemes = []string{AuthNone, AuthAPIKey, AuthHTTP, AuthOAuth2, AuthOpenIDConnect, AuthMutualTLS}
+)
+
+// Capabilities is the structured capability model of a provider.
+type Capabilities struct {
+	// Modalities lists the supported modalities, such as ModalityText.
+	Modalities []string `json:"modalities,omitempty"`
+
+	// AuthSchemes lists the accepted auth schemes, such as AuthOAuth2. The
+	// types of the provider's SecuritySchemes are included.
+	AuthSchemes []string `json:"authSchemes,omitempty"`
+
+	// RateLimit is the provider's advertised rate limit, if any.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit describes how often a provider may be called.
+type RateLimit struct {
+	// RequestsPerMinute is the sustained request rate. Must be positive.
+	RequestsPerMinute int `json:"requestsPerMinute"`
+
+	// Burst is the number of requests allowed above the sustained rate.
+	Burst int `json:"burst,omitempty"`
+}
+
+// ParseCapabilities extracts and validates the structured capabilities of
+// p from its Capabilities map and SecuritySchemes. A provider without
+// structured capabilities has a zero Capabilities apart from the types of
+// its SecuritySchemes.
+func ParseCapabilities(p adapter.CanonicalProvider) (Capabilities, error) {
+	structured := make(map[string]any, 3)
+	for _, key := range []string{CapabilityModalities, CapabilityAuthSchemes, CapabilityRateLimit} {
+		if v, ok := p.Capabilities[key]; ok {
+			structured[key] = v
+		}
+	}
+	var caps Capabilities
+	if len(structured) > 0 {
+		data, err := json.Marshal(structured)
+		if err != nil {
+			return Capabilities{}, fmt.Errorf("%w: %v", ErrInvalidCapabilities, err)
+		}
+		if err := json.Unmarshal(data, &caps); err != nil {
+			return Capabilities{}, fmt.Errorf("%w: %v", ErrInvalidCapabilities, err)
+		}
+	}
+
+	for _, m := range caps.Modalities {
+		if !slices.Contains(modalities, m) {
+			return Capabilities{}, fmt.Errorf("%w: unknown modality %q", ErrInvalidCapabilities, m)
+		}
+	}
+	for name, scheme := range p.SecuritySchemes {
+		typ, _ := scheme["type"].(string)
+		if typ == "" {
+			return Capabilities{}, fmt.Errorf("%w: security scheme %q has no type", ErrInvalidCapabilities, name)
+		}
+		caps.AuthSchemes = append(caps.AuthSchemes, typ)
+	}
+	for _, s := range caps.AuthSchemes {
+		if !slices.Contains(authSchemes, s) {
+			return Capabilities{}, fmt.Errorf("%w: unknown auth scheme %q", ErrInvalidCapabilities, s)
+		}
+	}
+	if rl := caps.RateLimit; rl != nil && (rl.RequestsPerMinute <= 0 || rl.Burst < 0) {
+		return Capabilities{}, fmt.Errorf("%w: rate limit must allow a positive request rate", ErrInvalidCapabilities)
+	}
+
+	slices.Sort(caps.Modalities)
+	caps.Modalities = slices.Compact(caps.Modalities)
+	slices.Sort(caps.AuthSchemes)
+	caps.AuthSchemes = slices.Compact(caps.AuthSchemes)
+	return caps, nil
+}
+
+// Capability is a capability requirement for FindProvidersByCapability.
+// Every set field must be met; the zero Capability matches every provider.
+type Capability struct {
+	// Modality requires support for a modality, such as ModalityImage.
+	Modality string
+
+	// AuthScheme requires acceptance of an auth scheme, such as AuthOAuth2.
+	AuthScheme string
+
+	// MinRequestsPerMinute requires an advertised rate limit of at least
+	// this many requests per minute.
+	MinRequestsPerMinute int
+
+	// Flag requires a free-form capability, such as "streaming", to be
+	// true.
+	Flag string
+}
+
+// Matches reports whether a provider with caps and the raw capability map
+// raw meets c.
+func (c Capability) Matches(caps Capabilities, raw map[string]any) bool {
+	if c.Modality != "" && !slices.Contains(caps.Modalities, c.Modality) {
+		return false
+	}
+	if c.AuthScheme != "" && !slices.Contains(caps.AuthSchemes, c.AuthScheme) {
+		return false
+	}
+	if c.MinRequestsPerMinute > 0 && (caps.RateLimit == nil || caps.RateLimit.RequestsPerMinute < c.MinRequestsPerMinute) {
+		return false
+	}
+	if c.Flag != "" {
+		if enabled, _ := raw[c.Flag].(bool); !enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// CapabilityFinder is an optional Store extension for stores that can
+// look providers up by capability without listing every provider, such as
+// from capabilities parsed at registration.
+//
+// Contract:
+//   - FindProvidersByCapability returns the providers that meet c, in the
+//     same stable order as ListProviders.
+type CapabilityFinder interface {
+	FindProvidersByCapability(c Capability) ([]adapter.CanonicalProvider, error)
+}
+
+var _ CapabilityFinder = (*InMemoryStore)(nil)
+
+// FindProvidersByCapability returns the providers in s that meet c. It uses
+// s's CapabilityFinder implementation when present and otherwise scans
+// ListProviders, parsing each provider's capabilities; providers whose
+// structured capabilities are malformed are skipped.
+func FindProvidersByCapability(s Store, c Capability) ([]adapter.CanonicalProvider, error) {
+	if f, ok := s.(CapabilityFinder); ok {
+		return f.FindProvidersByCapability(c)
+	}
+	providers, err := s.ListProviders()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]adapter.CanonicalProvider, 0)
+	for _, p := range providers {
+		caps, err := ParseCapabilities(p)
+		if err == nil && c.Matches(caps, p.Capabilities) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// FindProvidersByCapability returns the providers that meet c, in ID
+// order.
+func (s *InMemoryStore) FindProvidersByCapability(c Capability) ([]adapter.CanonicalProvider, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.providers))
+	for id := range s.providers {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	result := make([]adapter.CanonicalProvider, 0)
+	for _, id := range ids {
+		if c.Matches(s.capabilities[id], s.providers[id].Capabilities) {
+			result = append(result, s.providers[id])
+		}
+	}
+	return result, nil
+}
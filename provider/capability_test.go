@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jonwraymond/toolfoundation/adapter"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	p := testProvider("Vision", "1.0.0")
+	p.Capabilities = map[string]any{
+		"streaming":  true,
+		"modalities": []any{"image", "text", "image"},
+		"rateLimit":  map[string]any{"requestsPerMinute": 60.0, "burst": 10},
+	}
+	p.SecuritySchemes = map[string]adapter.SecurityScheme{"oauth": {"type": "oauth2"}}
+
+	caps, err := ParseCapabilities(p)
+	if err != nil {
+		t.Fatalf("ParseCapabilities error = %v", err)
+	}
+	if !slices.Equal(caps.Modalities, []string{ModalityImage, ModalityText}) {
+		t.Errorf("Modalities = %v, want [image text]", caps.Modalities)
+	}
+	if !slices.Equal(caps.AuthSchemes, []string{AuthOAuth2}) {
+		t.Errorf("AuthSchemes = %v, want [oauth2]", caps.AuthSchemes)
+	}
+	if caps.RateLimit == nil || caps.RateLimit.RequestsPerMinute != 60 || caps.RateLimit.Burst != 10 {
+		t.Errorf("RateLimit = %+v, want 60/10", caps.RateLimit)
+	}
+}
+
+func TestInMemoryStore_RegisterProvider_InvalidCapabilities(t *testing.T) {
+	tests := []struct {
+		name    string
+		caps    map[string]any
+		schemes map[string]adapter.SecurityScheme
+	}{
+		{"unknown modality", map[string]any{"modalities": []string{"smell"}}, nil},
+		{"wrong type", map[string]any{"modalities": "text"}, nil},
+		{"unknown auth scheme", map[string]any{"authSchemes": []string{"magic"}}, nil},
+		{"untyped security scheme", nil, map[string]adapter.SecurityScheme{"key": {}}},
+		{"zero rate limit", map[string]any{"rateLimit": map[string]any{"requestsPerMinute": 0}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewInMemoryStore()
+			p := testProvider("Agent", "1.0.0")
+			p.Capabilities = tt.caps
+			p.SecuritySchemes = tt.schemes
+			if _, err := store.RegisterProvider("", p); !errors.Is(err, ErrInvalidCapabilities) {
+				t.Fatalf("RegisterProvider error = %v, want ErrInvalidCapabilities", err)
+			}
+			if list, _ := store.ListProviders(); len(list) != 0 {
+				t.Errorf("ListProviders = %v, want empty", list)
+			}
+		})
+	}
+}
+
+func TestInMemoryStore_FindProvidersByCapability(t *testing.T) {
+	store := NewInMemoryStore()
+	vision := testProvider("Vision", "1.0.0")
+	vision.Capabilities = map[string]any{
+		"streaming":   true,
+		"modalities":  []string{"image", "text"},
+		"authSchemes": []string{"apiKey"},
+		"rateLimit":   map[string]any{"requestsPerMinute": 600},
+	}
+	chat := testProvider("Chat", "1.0.0")
+	chat.Capabilities = map[string]any{"modalities": []string{"text"}, "rateLimit": map[string]any{"requestsPerMinute": 60}}
+	chat.SecuritySchemes = map[string]adapter.SecurityScheme{"oauth": {"type": "oauth2"}}
+	for _, p := range []adapter.CanonicalProvider{vision, chat, testProvider("Legacy", "")} {
+		if _, err := store.RegisterProvider("", p); err != nil {
+			t.Fatalf("RegisterProvider(%s) error = %v", p.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		c    Capability
+		want []string
+	}{
+		{"any", Capability{}, []string{"Chat", "Legacy", "Vision"}},
+		{"modality", Capability{Modality: ModalityText}, []string{"Chat", "Vision"}},
+		{"auth from security schemes", Capability{AuthScheme: AuthOAuth2}, []string{"Chat"}},
+		{"rate limit", Capability{MinRequestsPerMinute: 100}, []string{"Vision"}},
+		{"flag", Capability{Flag: "streaming"}, []string{"Vision"}},
+		{"combined", Capability{Modality: ModalityImage, AuthScheme: AuthOAuth2}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, s := range map[string]Store{"finder": store, "scan": listOnlyStore{store}} {
+				found, err := FindProvidersByCapability(s, tt.c)
+				if err != nil {
+					t.Fatalf("%s: FindProvidersByCapability error = %v", name, err)
+				}
+				var names []string
+				for _, p := range found {
+					names = append(names, p.Name)
+				}
+				if !slices.Equal(names, tt.want) {
+					t.Errorf("%s: FindProvidersByCapability(%+v) = %v, want %v", name, tt.c, names, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// listOnlyStore hides the CapabilityFinder implementation of a store.
+type listOnlyStore struct {
+	store *InMemoryStore
+}
+
+func (s listOnlyStore) RegisterProvider(id string, p adapter.CanonicalProvider) (string, error) {
+	return s.store.RegisterProvider(id, p)
+}
+
+func (s listOnlyStore) DescribeProvider(id string) (adapter.CanonicalProvider, error) {
+	return s.store.DescribeProvider(id)
+}
+
+func (s listOnlyStore) ListProviders() ([]adapter.CanonicalProvider, error) {
+	return s.store.ListProviders()
+}
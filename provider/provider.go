@@ -27,14 +27,16 @@ type Store interface {
 
 // InMemoryStore stores providers in memory.
 type InMemoryStore struct {
-	mu        sync.RWMutex
-	providers map[string]adapter.CanonicalProvider
+	mu           sync.RWMutex
+	providers    map[string]adapter.CanonicalProvider
+	capabilities map[string]Capabilities // parsed at registration
 }
 
 // NewInMemoryStore creates a new provider store.
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		providers: make(map[string]adapter.CanonicalProvider),
+		providers:    make(map[string]adapter.CanonicalProvider),
+		capabilities: make(map[string]Capabilities),
 	}
 }
 
@@ -50,6 +52,8 @@ func ProviderID(name, version string) string {
 }
 
 // RegisterProvider registers a provider and returns its resolved ID.
+// Returns ErrInvalidCapabilities if its structured capabilities are
+// malformed (see ParseCapabilities).
 func (s *InMemoryStore) RegisterProvider(id string, provider adapter.CanonicalProvider) (string, error) {
 	if provider.Name == "" {
 		return "", ErrInvalidProvider
@@ -60,9 +64,14 @@ func (s *InMemoryStore) RegisterProvider(id string, provider adapter.CanonicalPr
 	if id == "" {
 		return "", ErrInvalidProviderID
 	}
+	caps, err := ParseCapabilities(provider)
+	if err != nil {
+		return "", err
+	}
 
 	s.mu.Lock()
 	s.providers[id] = provider
+	s.capabilities[id] = caps
 	s.mu.Unlock()
 
 	return id, nil
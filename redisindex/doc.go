@@ -0,0 +1,55 @@
+// Package redisindex provides a Redis-backed index.Index so several
+// discovery replicas serve one shared tool catalog.
+//
+// It lives outside the index package to keep index dependency-light. It
+// talks to Redis through [cache.RedisClient] and [Subscriber], so any client
+// library can be adapted without this module depending on it.
+//
+// # Usage
+//
+//	idx, err := redisindex.Open(ctx, client, redisindex.Options{
+//	    Index:      index.IndexOptions{Searcher: search.NewBM25Searcher(search.BM25Config{})},
+//	    Subscriber: sub,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer idx.Close()
+//
+//	_ = idx.RegisterToolsFromMCP("github", tools) // written to Redis, announced
+//	results, _ := idx.Search("create issue", 10)  // served from memory
+//
+// # Consistency
+//
+// Each replica keeps a full in-memory copy that serves all reads. Mutations
+// are applied locally, written to Redis, then announced on Options.Channel
+// with the IDs of the changed tools; other replicas re-read those tools and
+// apply them, firing their own change listeners. Replicas therefore converge
+// eventually: a read on one replica may briefly miss a write made on another.
+//
+// Pub/sub delivery is at-most-once, so a notification sent while a replica
+// is disconnected is lost. [Index.Resync] reconciles the local copy with
+// Redis; Open calls it once, and running it periodically (for example as a
+// scheduler job) bounds how long a missed notification can go unnoticed.
+// Each backend of a tool is its own hash field, so replicas adding or
+// removing different backends of the same tool concurrently merge, and a
+// tool is deleted only once its last backend is gone. The tool definition
+// and metadata are last-writer-wins.
+//
+// # Key Layout
+//
+// Under Options.KeyPrefix:
+//
+//	tool:<id>          hash of JSON fields: tool, metadata, disabledAt,
+//	                   and backend:<kind>:<backend id> per backend
+//	namespace:<ns>     sorted set of tool IDs in ns
+//	namespaces         sorted set of namespaces in use
+//	categories         sorted set of registered categories
+//	changes            pub/sub channel for change notifications
+//
+// # What Is Shared
+//
+// Tools, their backends, their custom metadata, and whether they are
+// disabled, plus the categories added with RegisterCategory. Rollouts,
+// maintenance windows, and change listeners are per-replica runtime state.
+package redisindex
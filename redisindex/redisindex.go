@@ -0,0 +1,487 @@
+package redisindex
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// DefaultKeyPrefix is prepended to every Redis key when Options.KeyPrefix is
+// empty.
+const DefaultKeyPrefix = "tooldiscovery:"
+
+// DefaultTimeout bounds each Redis round trip when Options.Timeout is zero.
+const DefaultTimeout = 5 * time.Second
+
+var (
+	// ErrClosed is returned by mutations after Close.
+	ErrClosed = errors.New("redisindex: index closed")
+
+	// ErrInvalidStore is returned when Redis holds data this package cannot
+	// decode.
+	ErrInvalidStore = errors.New("redisindex: invalid store")
+)
+
+// Subscriber delivers messages published on a Redis channel.
+// With github.com/redis/go-redis:
+//
+//	sub := redisindex.SubscriberFunc(func(ctx context.Context, channel string, handle func([]byte)) error {
+//	    ps := rdb.Subscribe(ctx, channel)
+//	    defer ps.Close()
+//	    for {
+//	        select {
+//	        case <-ctx.Done():
+//	            return ctx.Err()
+//	        case msg := <-ps.Channel():
+//	            handle([]byte(msg.Payload))
+//	        }
+//	    }
+//	})
+//
+// Contract:
+//   - Subscribe blocks, calling handle for each message in order, until ctx
+//     is canceled or the subscription fails.
+//   - handle may block; implementations must not call it concurrently.
+type Subscriber interface {
+	Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error
+}
+
+// SubscriberFunc adapts a function to Subscriber.
+type SubscriberFunc func(ctx context.Context, channel string, handle func(payload []byte)) error
+
+// Subscribe calls f.
+func (f SubscriberFunc) Subscribe(ctx context.Context, channel string, handle func(payload []byte)) error {
+	return f(ctx, channel, handle)
+}
+
+// Options configures a Redis-backed index.
+type Options struct {
+	// Index configures the in-memory index that serves reads.
+	Index index.IndexOptions
+
+	// KeyPrefix namespaces every key, so several catalogs can share one
+	// Redis database. Default: DefaultKeyPrefix.
+	KeyPrefix string
+
+	// Channel is the pub/sub channel for change notifications.
+	// Default: KeyPrefix + "changes".
+	Channel string
+
+	// Subscriber receives change notifications from other replicas. Without
+	// it, replicas converge only when Resync runs.
+	Subscriber Subscriber
+
+	// ReplicaID identifies this replica in change notifications so it skips
+	// its own. Default: a random ID.
+	ReplicaID string
+
+	// Timeout bounds each Redis round trip. Default: DefaultTimeout.
+	Timeout time.Duration
+
+	// OnError is called when applying a notification or a subscription
+	// fails. Optional.
+	OnError func(error)
+}
+
+// Index is an index.Index whose catalog is shared by every replica pointing
+// at the same Redis keys.
+//
+// Reads are served by an embedded index.InMemoryIndex loaded from Redis on
+// Open. Mutations update memory, write the affected tools to Redis, and
+// publish their IDs; other replicas reload those tools when the notification
+// arrives. The catalog is eventually consistent: a replica may serve a stale
+// tool until it receives the notification, and a missed notification is
+// repaired by the next Resync.
+//
+// Tools, their backends, custom metadata, and disabled state are shared,
+// along with registered categories. Each backend is stored separately, so
+// replicas adding backends to the same tool concurrently keep them all.
+// Rollouts, maintenance windows, and tool version history are per replica.
+type Index struct {
+	*index.InMemoryIndex
+
+	client  cache.RedisClient
+	prefix  string
+	channel string
+	replica string
+	timeout time.Duration
+	onError func(error)
+
+	// mu serializes local mutations and applied notifications so Redis
+	// writes follow memory order.
+	mu     sync.Mutex
+	closed bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// notification is published on Options.Channel after each mutation.
+type notification struct {
+	Origin     string   `json:"origin"`
+	ToolIDs    []string `json:"toolIds"`
+	Categories bool     `json:"categories,omitempty"`
+}
+
+// Open loads the shared catalog into memory and, when Options.Subscriber is
+// set, starts applying change notifications from other replicas.
+func Open(ctx context.Context, client cache.RedisClient, opts ...Options) (*Index, error) {
+	if client == nil {
+		return nil, cache.ErrInvalidClient
+	}
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.KeyPrefix == "" {
+		opt.KeyPrefix = DefaultKeyPrefix
+	}
+	if opt.Channel == "" {
+		opt.Channel = opt.KeyPrefix + "changes"
+	}
+	if opt.ReplicaID == "" {
+		opt.ReplicaID = randomID()
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultTimeout
+	}
+
+	idx := &Index{
+		InMemoryIndex: index.NewInMemoryIndex(opt.Index),
+		client:        client,
+		prefix:        opt.KeyPrefix,
+		channel:       opt.Channel,
+		replica:       opt.ReplicaID,
+		timeout:       opt.Timeout,
+		onError:       opt.OnError,
+	}
+
+	// Subscribe before loading so changes made while loading are not missed.
+	if opt.Subscriber != nil {
+		subCtx, cancel := context.WithCancel(context.Background())
+		idx.cancel = cancel
+		idx.done = make(chan struct{})
+		go func() {
+			defer close(idx.done)
+			err := opt.Subscriber.Subscribe(subCtx, idx.channel, idx.handleNotification)
+			if err != nil && subCtx.Err() == nil {
+				idx.report(fmt.Errorf("redisindex: subscribe: %w", err))
+			}
+		}()
+	}
+
+	if err := idx.Resync(ctx); err != nil {
+		_ = idx.Close()
+		return nil, err
+	}
+	return idx, nil
+}
+
+// RegisterTool registers a tool and shares it.
+func (r *Index) RegisterTool(tool model.Tool, backend model.ToolBackend) error {
+	return r.mutate(func() error {
+		return r.InMemoryIndex.RegisterTool(tool, backend)
+	}, tool.ToolID())
+}
+
+// RegisterToolWithMetadata registers a tool, replaces its metadata, and
+// shares it.
+func (r *Index) RegisterToolWithMetadata(tool model.Tool, backend model.ToolBackend, metadata map[string]string) error {
+	return r.mutate(func() error {
+		return r.InMemoryIndex.RegisterToolWithMetadata(tool, backend, metadata)
+	}, tool.ToolID())
+}
+
+// RegisterTools registers tools in batch and shares them with one
+// notification. Tools registered before a failing entry are still shared.
+func (r *Index) RegisterTools(regs []index.ToolRegistration) error {
+	ids := make([]string, len(regs))
+	for i, reg := range regs {
+		ids[i] = reg.Tool.ToolID()
+	}
+	return r.mutate(func() error {
+		return r.InMemoryIndex.RegisterTools(regs)
+	}, ids...)
+}
+
+// RegisterToolsFromMCP registers tools from an MCP server and shares them
+// with one notification.
+func (r *Index) RegisterToolsFromMCP(serverName string, tools []model.Tool) error {
+	ids := make([]string, len(tools))
+	for i, tool := range tools {
+		ids[i] = tool.ToolID()
+	}
+	return r.mutate(func() error {
+		return r.InMemoryIndex.RegisterToolsFromMCP(serverName, tools)
+	}, ids...)
+}
+
+// ReplaceToolsFromMCP atomically replaces the tools of an MCP server (see
+// index.InMemoryIndex.ReplaceToolsFromMCP) and shares the affected tools with
+// one notification.
+func (r *Index) ReplaceToolsFromMCP(serverName string, tools []model.Tool) error {
+	return r.mutateFunc(func() []string {
+		ids := r.InMemoryIndex.MCPServerToolIDs(serverName)
+		for _, tool := range tools {
+			ids = append(ids, tool.ToolID())
+		}
+		return ids
+	}, func() error {
+		return r.InMemoryIndex.ReplaceToolsFromMCP(serverName, tools)
+	})
+}
+
+// UnregisterBackend removes a backend from a tool and shares the result,
+// deleting the tool from Redis when its last backend is removed.
+func (r *Index) UnregisterBackend(toolID string, kind model.BackendKind, backendID string) error {
+	return r.mutate(func() error {
+		return r.InMemoryIndex.UnregisterBackend(toolID, kind, backendID)
+	}, toolID)
+}
+
+// DisableTool hides a tool and shares its disabled state.
+func (r *Index) DisableTool(id string) error {
+	return r.mutate(func() error {
+		return r.InMemoryIndex.DisableTool(id)
+	}, id)
+}
+
+// EnableTool restores a tool hidden with DisableTool and shares it.
+func (r *Index) EnableTool(id string) error {
+	return r.mutate(func() error {
+		return r.InMemoryIndex.EnableTool(id)
+	}, id)
+}
+
+// RegisterCategory adds a category to the taxonomy and shares it.
+func (r *Index) RegisterCategory(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if err := r.InMemoryIndex.RegisterCategory(path); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	if err := r.putCategories(ctx, r.InMemoryIndex.RegisteredCategories()); err != nil {
+		return fmt.Errorf("redisindex: write categories: %w", err)
+	}
+	return r.publish(ctx, notification{Origin: r.replica, Categories: true})
+}
+
+// mutate applies fn to memory, writes the resulting state of ids to Redis,
+// and notifies other replicas. The write happens even if fn fails, since
+// batch operations may have applied some entries before the error.
+func (r *Index) mutate(fn func() error, ids ...string) error {
+	return r.mutateFunc(func() []string { return ids }, fn)
+}
+
+// mutateFunc is mutate with the IDs computed by idsFn under r.mu before fn
+// runs, so they cannot go stale between the two.
+func (r *Index) mutateFunc(idsFn func() []string, fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+
+	ids := slices.Compact(slices.Sorted(slices.Values(idsFn())))
+	ids = slices.DeleteFunc(ids, func(id string) bool { return id == "" })
+	before := make(map[string]*index.ToolState, len(ids))
+	for _, id := range ids {
+		if state, err := r.InMemoryIndex.GetToolState(id); err == nil {
+			before[id] = &state
+		}
+	}
+	applyErr := fn()
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	if err := r.persistLocked(ctx, ids, before); err != nil {
+		return errors.Join(applyErr, err)
+	}
+	return applyErr
+}
+
+// persistLocked writes the change of each of ids from its before state to
+// Redis, applies the merged result with backends of other replicas back to
+// memory, and publishes the IDs.
+func (r *Index) persistLocked(ctx context.Context, ids []string, before map[string]*index.ToolState) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		var err error
+		if state, gerr := r.InMemoryIndex.GetToolState(id); gerr == nil {
+			err = r.put(ctx, id, state, before[id])
+		} else {
+			err = r.remove(ctx, id, before[id])
+		}
+		if err != nil {
+			return fmt.Errorf("redisindex: write tool %s: %w", id, err)
+		}
+		merged, err := r.fetch(ctx, id)
+		if err != nil {
+			return fmt.Errorf("redisindex: read tool %s: %w", id, err)
+		}
+		if err := r.applyLocked(id, merged); err != nil {
+			return err
+		}
+	}
+	return r.publish(ctx, notification{Origin: r.replica, ToolIDs: ids})
+}
+
+func (r *Index) publish(ctx context.Context, n notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.Do(ctx, "PUBLISH", r.channel, payload); err != nil {
+		return fmt.Errorf("redisindex: publish: %w", err)
+	}
+	return nil
+}
+
+// Resync reloads the shared catalog, applying every tool that differs from
+// memory, removing tools deleted by other replicas, and adding their
+// categories. Run it periodically to repair missed notifications; it has
+// the scheduler.JobFunc signature.
+func (r *Index) Resync(ctx context.Context) error {
+	remote, err := r.fetchAll(ctx)
+	if err != nil {
+		return err
+	}
+	categories, err := r.fetchCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	var errs []error
+	for _, id := range r.InMemoryIndex.ToolIDs() {
+		if _, ok := remote[id]; !ok {
+			errs = append(errs, r.applyLocked(id, nil))
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(remote)) {
+		state := remote[id]
+		errs = append(errs, r.applyLocked(id, &state))
+	}
+	errs = append(errs, r.applyCategoriesLocked(categories))
+	return errors.Join(errs...)
+}
+
+// handleNotification reloads the tools named by another replica.
+func (r *Index) handleNotification(payload []byte) {
+	var n notification
+	if err := json.Unmarshal(payload, &n); err != nil {
+		r.report(fmt.Errorf("%w: notification: %v", ErrInvalidStore, err))
+		return
+	}
+	if n.Origin == r.replica {
+		return
+	}
+	if n.Categories {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		categories, err := r.fetchCategories(ctx)
+		cancel()
+		if err == nil {
+			r.mu.Lock()
+			if !r.closed {
+				err = r.applyCategoriesLocked(categories)
+			}
+			r.mu.Unlock()
+		}
+		if err != nil {
+			r.report(err)
+		}
+	}
+	for _, id := range n.ToolIDs {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		state, err := r.fetch(ctx, id)
+		cancel()
+		if err != nil {
+			r.report(err)
+			continue
+		}
+		r.mu.Lock()
+		if !r.closed {
+			err = r.applyLocked(id, state)
+		}
+		r.mu.Unlock()
+		if err != nil {
+			r.report(err)
+		}
+	}
+}
+
+// applyLocked makes the in-memory tool id match state, or removes it when
+// state is nil.
+func (r *Index) applyLocked(id string, state *index.ToolState) error {
+	if err := r.InMemoryIndex.SetToolState(id, state); err != nil {
+		return fmt.Errorf("redisindex: apply tool %s: %w", id, err)
+	}
+	return nil
+}
+
+// applyCategoriesLocked registers the shared categories in memory.
+func (r *Index) applyCategoriesLocked(categories []string) error {
+	var errs []error
+	for _, category := range categories {
+		if err := r.InMemoryIndex.RegisterCategory(category); err != nil {
+			errs = append(errs, fmt.Errorf("%w: category %q: %v", ErrInvalidStore, category, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops applying notifications. The in-memory index keeps serving
+// reads; mutations return ErrClosed. Close is idempotent.
+func (r *Index) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+func (r *Index) report(err error) {
+	if r.onError != nil {
+		r.onError(err)
+	}
+}
+
+func randomID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+var (
+	_ index.Index       = (*Index)(nil)
+	_ index.MCPReplacer = (*Index)(nil)
+)
@@ -0,0 +1,393 @@
+package redisindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/indextest"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// fakeRedis implements the commands used by Index over in-memory hashes and
+// sorted sets, replying like go-redis over RESP2. EVAL runs removeScript
+// natively, and PUBLISH fans out to every subscriber in order.
+type fakeRedis struct {
+	mu     sync.Mutex
+	hashes map[string]map[string]string
+	zsets  map[string]map[string]struct{}
+	subs   map[string][]chan []byte
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		hashes: map[string]map[string]string{},
+		zsets:  map[string]map[string]struct{}{},
+		subs:   map[string][]chan []byte{},
+	}
+}
+
+func (f *fakeRedis) Do(ctx context.Context, args ...any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	str := func(v any) string {
+		if b, ok := v.([]byte); ok {
+			return string(b)
+		}
+		return fmt.Sprint(v)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := str(args[1])
+	switch args[0] {
+	case "HSET":
+		if f.hashes[key] == nil {
+			f.hashes[key] = map[string]string{}
+		}
+		for i := 2; i+1 < len(args); i += 2 {
+			f.hashes[key][str(args[i])] = str(args[i+1])
+		}
+		return int64(0), nil
+	case "HGETALL":
+		var out []any
+		for field, value := range f.hashes[key] {
+			out = append(out, field, value)
+		}
+		return out, nil
+	case "HDEL":
+		for _, field := range args[2:] {
+			delete(f.hashes[key], str(field))
+		}
+		return int64(len(args) - 2), nil
+	case "DEL":
+		delete(f.hashes, key)
+		return int64(1), nil
+	case "EVAL":
+		if str(args[1]) != removeScript {
+			return nil, fmt.Errorf("unsupported script")
+		}
+		toolKey, nsKey, nssKey := str(args[3]), str(args[4]), str(args[5])
+		for _, field := range args[8:] {
+			delete(f.hashes[toolKey], str(field))
+		}
+		for field := range f.hashes[toolKey] {
+			if strings.HasPrefix(field, fieldBackendPrefix) {
+				return int64(0), nil
+			}
+		}
+		delete(f.hashes, toolKey)
+		f.zrem(nsKey, str(args[6]))
+		if len(f.zsets[nsKey]) == 0 {
+			f.zrem(nssKey, str(args[7]))
+		}
+		return int64(1), nil
+	case "ZADD":
+		if f.zsets[key] == nil {
+			f.zsets[key] = map[string]struct{}{}
+		}
+		for i := 3; i < len(args); i += 2 {
+			f.zsets[key][str(args[i])] = struct{}{}
+		}
+		return int64(1), nil
+	case "ZREM":
+		f.zrem(key, str(args[2]))
+		return int64(1), nil
+	case "ZCARD":
+		return int64(len(f.zsets[key])), nil
+	case "ZRANGE":
+		members := make([]string, 0, len(f.zsets[key]))
+		for m := range f.zsets[key] {
+			members = append(members, m)
+		}
+		slices.Sort(members)
+		out := make([]any, len(members))
+		for i, m := range members {
+			out[i] = m
+		}
+		return out, nil
+	case "PUBLISH":
+		payload := []byte(str(args[2]))
+		for _, ch := range f.subs[key] {
+			ch <- payload
+		}
+		return int64(len(f.subs[key])), nil
+	}
+	return nil, fmt.Errorf("unsupported command %v", args[0])
+}
+
+func (f *fakeRedis) zrem(key, member string) {
+	delete(f.zsets[key], member)
+	if len(f.zsets[key]) == 0 {
+		delete(f.zsets, key)
+	}
+}
+
+func (f *fakeRedis) Subscribe(ctx context.Context, channel string, handle func([]byte)) error {
+	ch := make(chan []byte, 64)
+	f.mu.Lock()
+	f.subs[channel] = append(f.subs[channel], ch)
+	f.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case payload := <-ch:
+			handle(payload)
+		}
+	}
+}
+
+func testTool(namespace, name, desc string) model.Tool {
+	return model.Tool{
+		Tool: mcp.Tool{
+			Name:        name,
+			Description: desc,
+			InputSchema: map[string]any{"type": "object"},
+		},
+		Namespace: namespace,
+	}
+}
+
+func mcpBackend(server string) model.ToolBackend {
+	return model.ToolBackend{Kind: model.BackendKindMCP, MCP: &model.MCPBackend{ServerName: server}}
+}
+
+func openReplica(t *testing.T, redis *fakeRedis, subscribe bool) *Index {
+	t.Helper()
+	opts := Options{}
+	if subscribe {
+		opts.Subscriber = redis
+		opts.OnError = func(err error) { t.Errorf("replica error: %v", err) }
+	}
+	idx, err := Open(context.Background(), redis, opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx
+}
+
+// eventually polls cond until it holds or a second passes.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestIndex_ReplicasShareCatalog(t *testing.T) {
+	redis := newFakeRedis()
+	a := openReplica(t, redis, true)
+	b := openReplica(t, redis, true)
+	eventually(t, "both replicas to subscribe", func() bool {
+		redis.mu.Lock()
+		defer redis.mu.Unlock()
+		return len(redis.subs["tooldiscovery:changes"]) == 2
+	})
+
+	var events []index.ChangeEvent
+	var mu sync.Mutex
+	b.OnChange(func(e index.ChangeEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	})
+
+	if err := a.RegisterToolWithMetadata(testTool("github", "create_issue", "v1"), mcpBackend("gh"), map[string]string{"team": "dev"}); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	_ = a.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh-backup"))
+	eventually(t, "replica b to see both backends", func() bool {
+		backends, _ := b.GetAllBackends("github:create_issue")
+		return len(backends) == 2
+	})
+	if metadata, _ := b.GetMetadata("github:create_issue"); metadata["team"] != "dev" {
+		t.Errorf("metadata = %v", metadata)
+	}
+	if ns, _ := b.ListNamespaces(); !slices.Equal(ns, []string{"github"}) {
+		t.Errorf("namespaces = %v", ns)
+	}
+
+	// A changed definition replaces the tool on other replicas.
+	_ = a.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh-backup")
+	_ = a.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh")
+	_ = a.RegisterTool(testTool("github", "create_issue", "v2"), mcpBackend("gh"))
+	eventually(t, "replica b to see v2", func() bool {
+		tool, _, err := b.GetTool("github:create_issue")
+		return err == nil && tool.Description == "v2"
+	})
+
+	// Removal on b reaches a.
+	if err := b.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh"); err != nil {
+		t.Fatalf("UnregisterBackend on b failed: %v", err)
+	}
+	eventually(t, "replica a to drop the tool", func() bool {
+		_, _, err := a.GetTool("github:create_issue")
+		return errors.Is(err, index.ErrNotFound)
+	})
+	if len(redis.hashes) != 0 || len(redis.zsets) != 0 {
+		t.Errorf("expected Redis to be empty, got %v %v", redis.hashes, redis.zsets)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) == 0 || events[0].Type != index.ChangeRegistered {
+		t.Errorf("replica b events = %v", events)
+	}
+}
+
+func TestIndex_OpenLoadsAndResyncRepairs(t *testing.T) {
+	redis := newFakeRedis()
+	writer := openReplica(t, redis, false)
+	_ = writer.RegisterTool(testTool("k8s", "list_pods", "List pods"), mcpBackend("k8s"))
+	_ = writer.RegisterTool(testTool("", "echo", "Echo"), mcpBackend("local"))
+
+	reader := openReplica(t, redis, false)
+	if ids := reader.ToolIDs(); !slices.Equal(ids, []string{"echo", "k8s:list_pods"}) {
+		t.Fatalf("loaded tools = %v", ids)
+	}
+	version := reader.Version()
+
+	// Without a subscriber, changes arrive only on Resync.
+	_ = writer.UnregisterBackend("echo", model.BackendKindMCP, "local")
+	_ = writer.RegisterTool(testTool("k8s", "get_logs", "Get logs"), mcpBackend("k8s"))
+	if ids := reader.ToolIDs(); len(ids) != 2 || ids[0] != "echo" {
+		t.Fatalf("expected a stale catalog before Resync, got %v", ids)
+	}
+	if err := reader.Resync(context.Background()); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	if ids := reader.ToolIDs(); !slices.Equal(ids, []string{"k8s:get_logs", "k8s:list_pods"}) {
+		t.Fatalf("tools after Resync = %v", ids)
+	}
+
+	// An unchanged catalog leaves the index untouched.
+	version = reader.Version()
+	_ = reader.Resync(context.Background())
+	if reader.Version() != version {
+		t.Errorf("Resync of an unchanged catalog bumped the version")
+	}
+}
+
+func TestIndex_StoreContract(t *testing.T) {
+	indextest.TestStore(t, func(t *testing.T) (indextest.Store, indextest.Reload) {
+		redis := newFakeRedis()
+		return openReplica(t, redis, false), func(t *testing.T, _ indextest.Store) indextest.Store {
+			return openReplica(t, redis, false)
+		}
+	})
+}
+
+func TestIndex_DisabledAndCategoriesPropagate(t *testing.T) {
+	redis := newFakeRedis()
+	a := openReplica(t, redis, true)
+	b := openReplica(t, redis, true)
+	eventually(t, "both replicas to subscribe", func() bool {
+		redis.mu.Lock()
+		defer redis.mu.Unlock()
+		return len(redis.subs["tooldiscovery:changes"]) == 2
+	})
+
+	_ = a.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh"))
+	if err := a.DisableTool("github:create_issue"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	if err := a.RegisterCategory("devops/containers"); err != nil {
+		t.Fatalf("RegisterCategory failed: %v", err)
+	}
+	eventually(t, "replica b to see the tool disabled", func() bool {
+		return len(b.ListDisabled()) == 1
+	})
+	eventually(t, "replica b to see the category", func() bool {
+		return slices.Equal(b.RegisteredCategories(), []string{"devops/containers"})
+	})
+
+	if err := a.EnableTool("github:create_issue"); err != nil {
+		t.Fatalf("EnableTool failed: %v", err)
+	}
+	eventually(t, "replica b to see the tool enabled", func() bool {
+		_, _, err := b.GetTool("github:create_issue")
+		return err == nil
+	})
+}
+
+func TestIndex_ConcurrentBackendsMerge(t *testing.T) {
+	redis := newFakeRedis()
+	a := openReplica(t, redis, false)
+	b := openReplica(t, redis, false)
+
+	// Neither replica has seen the other's write when it registers.
+	if err := a.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh-a")); err != nil {
+		t.Fatalf("RegisterTool on a failed: %v", err)
+	}
+	if err := b.RegisterTool(testTool("github", "create_issue", "v1"), mcpBackend("gh-b")); err != nil {
+		t.Fatalf("RegisterTool on b failed: %v", err)
+	}
+	if err := a.Resync(context.Background()); err != nil {
+		t.Fatalf("Resync failed: %v", err)
+	}
+	for name, replica := range map[string]*Index{"a": a, "b": b} {
+		if backends, _ := replica.GetAllBackends("github:create_issue"); len(backends) != 2 {
+			t.Errorf("replica %s backends = %v, want both", name, backends)
+		}
+	}
+
+	// Removing one replica's backend keeps the other's.
+	if err := a.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh-a"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	_ = b.Resync(context.Background())
+	for name, replica := range map[string]*Index{"a": a, "b": b} {
+		backends, err := replica.GetAllBackends("github:create_issue")
+		if err != nil || len(backends) != 1 || backends[0].MCP.ServerName != "gh-b" {
+			t.Errorf("replica %s backends = %v, %v; want gh-b", name, backends, err)
+		}
+	}
+	if err := b.UnregisterBackend("github:create_issue", model.BackendKindMCP, "gh-b"); err != nil {
+		t.Fatalf("UnregisterBackend failed: %v", err)
+	}
+	if len(redis.hashes) != 0 || len(redis.zsets) != 0 {
+		t.Errorf("expected Redis to be empty, got %v %v", redis.hashes, redis.zsets)
+	}
+}
+
+func TestIndex_Close(t *testing.T) {
+	redis := newFakeRedis()
+	idx := openReplica(t, redis, true)
+	_ = idx.RegisterTool(testTool("ns", "tool", "d"), mcpBackend("s"))
+	if err := idx.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+	if err := idx.RegisterTool(testTool("ns", "other", "d"), mcpBackend("s")); !errors.Is(err, ErrClosed) {
+		t.Errorf("RegisterTool after Close error = %v, want ErrClosed", err)
+	}
+	if _, _, err := idx.GetTool("ns:tool"); err != nil {
+		t.Errorf("reads should keep working after Close: %v", err)
+	}
+}
+
+func TestOpen_InvalidStore(t *testing.T) {
+	redis := newFakeRedis()
+	redis.zsets["tooldiscovery:namespaces"] = map[string]struct{}{"ns": {}}
+	redis.zsets["tooldiscovery:namespace:ns"] = map[string]struct{}{"ns:bad": {}}
+	redis.hashes["tooldiscovery:tool:ns:bad"] = map[string]string{"tool": "{", "backend:mcp:s": "{}"}
+	if _, err := Open(context.Background(), redis); !errors.Is(err, ErrInvalidStore) {
+		t.Errorf("Open error = %v, want ErrInvalidStore", err)
+	}
+}
@@ -0,0 +1,314 @@
+package redisindex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Key layout, relative to the key prefix:
+//
+//	tool:<id>           hash: tool, metadata, disabledAt when disabled, and
+//	                    one backend:<kind>:<id> field per backend (JSON)
+//	namespace:<ns>      sorted set of tool IDs in the namespace
+//	namespaces          sorted set of namespaces with tools
+//	categories          sorted set of registered categories
+//
+// Sorted set members all score 0, so they are ordered lexicographically and
+// the catalog can be enumerated without SCAN. Each backend has its own hash
+// field so replicas registering the same tool with different backends merge
+// instead of overwriting each other.
+const (
+	fieldTool          = "tool"
+	fieldMetadata      = "metadata"
+	fieldDisabledAt    = "disabledAt"
+	fieldBackendPrefix = "backend:"
+)
+
+func (r *Index) toolKey(id string) string      { return r.prefix + "tool:" + id }
+func (r *Index) namespaceKey(ns string) string { return r.prefix + "namespace:" + ns }
+func (r *Index) namespacesKey() string         { return r.prefix + "namespaces" }
+func (r *Index) categoriesKey() string         { return r.prefix + "categories" }
+
+// backendField returns the hash field holding backend.
+func backendField(backend model.ToolBackend) string {
+	return fieldBackendPrefix + string(backend.Kind) + ":" + index.BackendID(backend)
+}
+
+// removeScript drops the backend fields passed after the tool ID and
+// namespace, then deletes the tool and its empty namespace only when no
+// backend is left, atomically with respect to other replicas.
+//
+//	KEYS: tool hash, namespace set, namespaces set
+//	ARGV: tool ID, namespace, backend fields...
+const removeScript = `
+for i = 3, #ARGV do
+  redis.call('HDEL', KEYS[1], ARGV[i])
+end
+for _, field in ipairs(redis.call('HKEYS', KEYS[1])) do
+  if string.sub(field, 1, 8) == 'backend:' then
+    return 0
+  end
+end
+redis.call('DEL', KEYS[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+if redis.call('ZCARD', KEYS[2]) == 0 then
+  redis.call('ZREM', KEYS[3], ARGV[2])
+end
+return 1
+`
+
+// put writes rec with a single HSET, so readers never see a partial tool,
+// drops the fields of backends in before that rec no longer has, and adds
+// the tool to its namespace sets. Backends written by other replicas are
+// left in place.
+func (r *Index) put(ctx context.Context, id string, rec index.ToolState, before *index.ToolState) error {
+	tool, err := json.Marshal(rec.Tool)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(rec.Metadata)
+	if err != nil {
+		return err
+	}
+	args := []any{"HSET", r.toolKey(id), fieldTool, tool, fieldMetadata, metadata}
+	if !rec.DisabledAt.IsZero() {
+		disabledAt, err := json.Marshal(rec.DisabledAt)
+		if err != nil {
+			return err
+		}
+		args = append(args, fieldDisabledAt, disabledAt)
+	}
+	fields := make(map[string]bool, len(rec.Backends))
+	for _, backend := range rec.Backends {
+		data, err := json.Marshal(backend)
+		if err != nil {
+			return err
+		}
+		field := backendField(backend)
+		fields[field] = true
+		args = append(args, field, data)
+	}
+	if _, err := r.client.Do(ctx, args...); err != nil {
+		return err
+	}
+	if before != nil {
+		stale := []any{"HDEL", r.toolKey(id)}
+		if rec.DisabledAt.IsZero() && !before.DisabledAt.IsZero() {
+			stale = append(stale, fieldDisabledAt)
+		}
+		for _, backend := range before.Backends {
+			if field := backendField(backend); !fields[field] {
+				stale = append(stale, field)
+			}
+		}
+		if len(stale) > 2 {
+			if _, err := r.client.Do(ctx, stale...); err != nil {
+				return err
+			}
+		}
+	}
+	ns := rec.Tool.Namespace
+	if _, err := r.client.Do(ctx, "ZADD", r.namespaceKey(ns), 0, id); err != nil {
+		return err
+	}
+	_, err = r.client.Do(ctx, "ZADD", r.namespacesKey(), 0, ns)
+	return err
+}
+
+// remove drops the backends of before from tool id, deleting the tool and
+// its empty namespace once no replica's backend is left.
+func (r *Index) remove(ctx context.Context, id string, before *index.ToolState) error {
+	if before == nil {
+		return nil
+	}
+	toolID, err := index.ParseToolID(id)
+	if err != nil {
+		return err
+	}
+	ns := toolID.Namespace
+	args := []any{"EVAL", removeScript, 3, r.toolKey(id), r.namespaceKey(ns), r.namespacesKey(), id, ns}
+	for _, backend := range before.Backends {
+		args = append(args, backendField(backend))
+	}
+	_, err = r.client.Do(ctx, args...)
+	return err
+}
+
+// fetch reads tool id, returning nil when it does not exist. Backends are
+// ordered by hash field, so every replica applies them in the same order.
+func (r *Index) fetch(ctx context.Context, id string) (*index.ToolState, error) {
+	reply, err := r.client.Do(ctx, "HGETALL", r.toolKey(id))
+	if err != nil {
+		return nil, err
+	}
+	fields, err := hashReply(reply)
+	if err != nil {
+		return nil, err
+	}
+	var backendFields []string
+	for field := range fields {
+		if strings.HasPrefix(field, fieldBackendPrefix) {
+			backendFields = append(backendFields, field)
+		}
+	}
+	if len(backendFields) == 0 {
+		return nil, nil
+	}
+	slices.Sort(backendFields)
+	var rec index.ToolState
+	if err := json.Unmarshal([]byte(fields[fieldTool]), &rec.Tool); err != nil {
+		return nil, fmt.Errorf("%w: tool %s: %v", ErrInvalidStore, id, err)
+	}
+	for _, field := range backendFields {
+		var backend model.ToolBackend
+		if err := json.Unmarshal([]byte(fields[field]), &backend); err != nil {
+			return nil, fmt.Errorf("%w: tool %s %s: %v", ErrInvalidStore, id, field, err)
+		}
+		rec.Backends = append(rec.Backends, backend)
+	}
+	if raw := fields[fieldMetadata]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &rec.Metadata); err != nil {
+			return nil, fmt.Errorf("%w: tool %s metadata: %v", ErrInvalidStore, id, err)
+		}
+	}
+	if raw := fields[fieldDisabledAt]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &rec.DisabledAt); err != nil {
+			return nil, fmt.Errorf("%w: tool %s disabledAt: %v", ErrInvalidStore, id, err)
+		}
+	}
+	return &rec, nil
+}
+
+// putCategories adds categories to the registered set.
+func (r *Index) putCategories(ctx context.Context, categories []string) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	args := []any{"ZADD", r.categoriesKey()}
+	for _, category := range categories {
+		args = append(args, 0, category)
+	}
+	_, err := r.client.Do(ctx, args...)
+	return err
+}
+
+// fetchCategories reads the registered categories.
+func (r *Index) fetchCategories(ctx context.Context) ([]string, error) {
+	reply, err := r.client.Do(ctx, "ZRANGE", r.categoriesKey(), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	return stringsReply(reply)
+}
+
+// fetchAll reads every tool listed in the namespace sets. IDs whose hash has
+// vanished, from a concurrent removal, are skipped.
+func (r *Index) fetchAll(ctx context.Context) (map[string]index.ToolState, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	reply, err := r.client.Do(ctx, "ZRANGE", r.namespacesKey(), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := stringsReply(reply)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]index.ToolState)
+	for _, ns := range namespaces {
+		reply, err := r.client.Do(ctx, "ZRANGE", r.namespaceKey(ns), 0, -1)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := stringsReply(reply)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			rec, err := r.fetch(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if rec != nil {
+				out[id] = *rec
+			}
+		}
+	}
+	return out, nil
+}
+
+// stringsReply decodes an array reply of bulk strings.
+func stringsReply(reply any) ([]string, error) {
+	items, ok := reply.([]any)
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("%w: array reply is %T", ErrInvalidStore, reply)
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := bulkString(item)
+		if !ok {
+			return nil, fmt.Errorf("%w: array element is %T", ErrInvalidStore, item)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// hashReply decodes an HGETALL reply: a flat field/value array (RESP2) or a
+// map (RESP3).
+func hashReply(reply any) (map[string]string, error) {
+	out := make(map[string]string)
+	switch v := reply.(type) {
+	case nil:
+	case map[any]any:
+		for key, value := range v {
+			k, okK := bulkString(key)
+			s, okV := bulkString(value)
+			if !okK || !okV {
+				return nil, fmt.Errorf("%w: hash entry %T=%T", ErrInvalidStore, key, value)
+			}
+			out[k] = s
+		}
+	case map[string]any:
+		for k, value := range v {
+			s, ok := bulkString(value)
+			if !ok {
+				return nil, fmt.Errorf("%w: hash value %T", ErrInvalidStore, value)
+			}
+			out[k] = s
+		}
+	case map[string]string:
+		for k, s := range v {
+			out[k] = s
+		}
+	default:
+		items, err := stringsReply(reply)
+		if err != nil {
+			return nil, err
+		}
+		if len(items)%2 != 0 {
+			return nil, fmt.Errorf("%w: odd hash reply length", ErrInvalidStore)
+		}
+		for i := 0; i < len(items); i += 2 {
+			out[items[i]] = items[i+1]
+		}
+	}
+	return out, nil
+}
+
+func bulkString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case []byte:
+		return string(s), true
+	}
+	return "", false
+}
@@ -0,0 +1,433 @@
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeysPath is where Serve mounts the API key admin endpoint when
+// ServeOptions.APIKeys is set.
+const DefaultAPIKeysPath = "/admin/apikeys"
+
+// apiKeyPrefix marks API key secrets so they are recognizable in logs and
+// secret scanners.
+const apiKeyPrefix = "tdk_"
+
+// Role is the permission level of an API key. Each role includes the
+// permissions of the roles below it.
+type Role string
+
+const (
+	// RoleReadOnly may initialize and list tools.
+	RoleReadOnly Role = "read-only"
+	// RoleExecute may also call tools.
+	RoleExecute Role = "execute"
+	// RoleAdmin may also manage API keys.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) rank() int {
+	switch r {
+	case RoleReadOnly:
+		return 1
+	case RoleExecute:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Allows reports whether r includes the permissions of required.
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// methodRole returns the role an MCP method requires.
+func methodRole(method string) Role {
+	if method == "tools/call" {
+		return RoleExecute
+	}
+	return RoleReadOnly
+}
+
+// RateLimit is a token-bucket limit on requests made with one API key. The
+// zero value is unlimited.
+type RateLimit struct {
+	// PerSecond is the sustained request rate.
+	PerSecond float64 `json:"perSecond,omitempty"`
+	// Burst is the number of requests allowed at once. Default: 1 when
+	// PerSecond is set.
+	Burst int `json:"burst,omitempty"`
+}
+
+// APIKey describes an API key. The secret is only returned by
+// APIKeyStore.Create; the store keeps a hash.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	RateLimit RateLimit `json:"rateLimit"`
+	CreatedAt time.Time `json:"createdAt"`
+	RevokedAt time.Time `json:"revokedAt,omitzero"`
+}
+
+// Revoked reports whether the key has been revoked.
+func (k APIKey) Revoked() bool { return !k.RevokedAt.IsZero() }
+
+// AuditEvent attributes one authenticated request to an API key.
+type AuditEvent struct {
+	Time    time.Time
+	KeyID   string
+	KeyName string
+	Role    Role
+	// Method is the MCP method. For plain HTTP endpoints it is the HTTP
+	// method, followed by the path for checks made by RequireRole.
+	Method string
+	// ToolID is the tool named by tools/call requests.
+	ToolID string
+	// Allowed reports whether the request was served; Reason says why not.
+	Allowed bool
+	Reason  string
+}
+
+// APIKeyStoreOptions configures an APIKeyStore.
+type APIKeyStoreOptions struct {
+	// Now returns the current time for rate limiting and timestamps.
+	// Default: time.Now.
+	Now func() time.Time
+
+	// OnAudit receives an event for every request made with a known key,
+	// including rejected ones. It runs synchronously. Optional.
+	OnAudit func(AuditEvent)
+}
+
+// APIKeyStore is an in-memory set of API keys with roles and rate limits.
+// Keys do not survive a restart; create them at startup from configuration
+// or secrets storage.
+//
+// APIKeyStore is safe for concurrent use.
+type APIKeyStore struct {
+	mu      sync.Mutex
+	keys    map[string]*apiKeyRecord // keyed by ID
+	byHash  map[[sha256.Size]byte]string
+	now     func() time.Time
+	onAudit func(AuditEvent)
+}
+
+type apiKeyRecord struct {
+	key    APIKey
+	hash   [sha256.Size]byte
+	tokens float64
+	filled time.Time // when tokens was last refilled
+}
+
+// NewAPIKeyStore returns an empty APIKeyStore.
+func NewAPIKeyStore(opts ...APIKeyStoreOptions) *APIKeyStore {
+	s := &APIKeyStore{
+		keys:   make(map[string]*apiKeyRecord),
+		byHash: make(map[[sha256.Size]byte]string),
+		now:    time.Now,
+	}
+	if len(opts) > 0 {
+		if opts[0].Now != nil {
+			s.now = opts[0].Now
+		}
+		s.onAudit = opts[0].OnAudit
+	}
+	return s
+}
+
+// Create adds a key and returns it with its secret. The secret cannot be
+// recovered later.
+func (s *APIKeyStore) Create(name string, role Role, limit RateLimit) (APIKey, string, error) {
+	if role.rank() == 0 {
+		return APIKey{}, "", fmt.Errorf("%w: unknown role %q", ErrInvalidRequest, role)
+	}
+	if limit.PerSecond < 0 || limit.Burst < 0 {
+		return APIKey{}, "", fmt.Errorf("%w: rate limit must not be negative", ErrInvalidRequest)
+	}
+	if limit.PerSecond > 0 && limit.Burst == 0 {
+		limit.Burst = 1
+	}
+	id, err := randomToken(8, hex.EncodeToString)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret = apiKeyPrefix + secret
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	record := &apiKeyRecord{
+		key:    APIKey{ID: id, Name: name, Role: role, RateLimit: limit, CreatedAt: now},
+		hash:   sha256.Sum256([]byte(secret)),
+		tokens: float64(limit.Burst),
+		filled: now,
+	}
+	s.keys[id] = record
+	s.byHash[record.hash] = id
+	return record.key, secret, nil
+}
+
+// Revoke disables a key. Revoked keys stay listed with RevokedAt set.
+// Returns ErrInvalidAPIKey if no key has the ID.
+func (s *APIKeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidAPIKey, id)
+	}
+	if !record.key.Revoked() {
+		record.key.RevokedAt = s.now()
+		delete(s.byHash, record.hash)
+	}
+	return nil
+}
+
+// List returns every key, including revoked ones, ordered by creation.
+func (s *APIKeyStore) List() []APIKey {
+	s.mu.Lock()
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, record := range s.keys {
+		keys = append(keys, record.key)
+	}
+	s.mu.Unlock()
+	slices.SortFunc(keys, func(a, b APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return keys
+}
+
+// Authenticate returns the key for secret. Returns ErrInvalidAPIKey for
+// unknown and revoked secrets.
+func (s *APIKeyStore) Authenticate(secret string) (APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.byHash[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+	return s.keys[id].key, nil
+}
+
+// allow takes a token from the key's bucket, reporting false when the key
+// is over its rate limit.
+func (s *APIKeyStore) allow(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.keys[id]
+	if !ok {
+		return false
+	}
+	limit := record.key.RateLimit
+	if limit.PerSecond <= 0 {
+		return true
+	}
+	now := s.now()
+	elapsed := now.Sub(record.filled).Seconds()
+	record.tokens = min(float64(limit.Burst), record.tokens+elapsed*limit.PerSecond)
+	record.filled = now
+	if record.tokens < 1 {
+		return false
+	}
+	record.tokens--
+	return true
+}
+
+func (s *APIKeyStore) audit(key APIKey, method, toolID string, err error) {
+	if s.onAudit == nil {
+		return
+	}
+	event := AuditEvent{
+		Time:    s.now(),
+		KeyID:   key.ID,
+		KeyName: key.Name,
+		Role:    key.Role,
+		Method:  method,
+		ToolID:  toolID,
+		Allowed: err == nil,
+	}
+	if err != nil {
+		event.Reason = err.Error()
+	}
+	s.onAudit(event)
+}
+
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+	return encode(b), nil
+}
+
+type apiKeyContextKey struct{}
+
+type apiKeyAuth struct {
+	key   APIKey
+	store *APIKeyStore
+}
+
+// APIKeyFromContext returns the API key that authenticated the request in
+// progress, for attributing tool calls in Middleware. It reports false for
+// requests not served through RequireAPIKey.
+func APIKeyFromContext(ctx context.Context) (APIKey, bool) {
+	auth, ok := ctx.Value(apiKeyContextKey{}).(apiKeyAuth)
+	return auth.key, ok
+}
+
+// authorizeRequest checks that the API key on ctx, if any, may call the
+// request's method, and records the attempt. Requests without a key are
+// allowed, so stdio and unauthenticated transports are unaffected.
+func authorizeRequest(ctx context.Context, req MCPRequest) error {
+	auth, ok := ctx.Value(apiKeyContextKey{}).(apiKeyAuth)
+	if !ok {
+		return nil
+	}
+	var toolID string
+	if req.Method == "tools/call" {
+		var params toolsCallParams
+		_ = json.Unmarshal(req.Params, &params)
+		toolID = params.Name
+	}
+	var err error
+	if required := methodRole(req.Method); !auth.key.Role.Allows(required) {
+		err = fmt.Errorf("%w: %s requires role %s", ErrForbidden, req.Method, required)
+	}
+	auth.store.audit(auth.key, req.Method, toolID, err)
+	return err
+}
+
+// RequireAPIKey authenticates requests to next with an API key from store,
+// sent as "Authorization: Bearer <key>" or in the X-API-Key header. Missing,
+// unknown, and revoked keys get 401 and keys over their rate limit get 429.
+// Registry.HandleRequest then enforces the key's role per MCP method.
+func RequireAPIKey(store *APIKeyStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		secret := req.Header.Get("X-API-Key")
+		if auth := req.Header.Get("Authorization"); secret == "" && strings.HasPrefix(auth, "Bearer ") {
+			secret = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "API key required", http.StatusUnauthorized)
+			return
+		}
+		key, err := store.Authenticate(secret)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if !store.allow(key.ID) {
+			store.audit(key, req.Method, "", ErrRateLimited)
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		ctx := context.WithValue(req.Context(), apiKeyContextKey{}, apiKeyAuth{key: key, store: store})
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// RequireRole returns an authorization check for handlers mounted behind
+// RequireAPIKey, such as discovery.ServeAdmin. It fails with ErrForbidden
+// unless the request's API key has at least role, and records the attempt.
+func RequireRole(role Role) func(*http.Request) error {
+	return func(req *http.Request) error {
+		auth, ok := req.Context().Value(apiKeyContextKey{}).(apiKeyAuth)
+		if !ok {
+			return fmt.Errorf("%w: API key required", ErrForbidden)
+		}
+		var err error
+		if !auth.key.Role.Allows(role) {
+			err = fmt.Errorf("%w: requires role %s", ErrForbidden, role)
+		}
+		auth.store.audit(auth.key, req.Method+" "+req.URL.Path, "", err)
+		return err
+	}
+}
+
+type createAPIKeyRequest struct {
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	RateLimit RateLimit `json:"rateLimit"`
+}
+
+type createAPIKeyResponse struct {
+	APIKey
+	Secret string `json:"secret"`
+}
+
+// ServeAPIKeys returns an http.Handler for managing store's keys, for
+// mounting behind RequireAPIKey. It requires an admin key:
+//
+//	GET                       lists keys
+//	POST {name, role, rateLimit} creates a key and returns it with its secret
+//	DELETE ?id=<id>           revokes a key
+func ServeAPIKeys(store *APIKeyStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key, ok := APIKeyFromContext(req.Context())
+		if !ok {
+			http.Error(w, "API key required", http.StatusUnauthorized)
+			return
+		}
+		if !key.Role.Allows(RoleAdmin) {
+			err := fmt.Errorf("%w: managing API keys requires role %s", ErrForbidden, RoleAdmin)
+			store.audit(key, req.Method, "", err)
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		store.audit(key, req.Method, "", nil)
+
+		switch req.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, store.List())
+		case http.MethodPost:
+			var body createAPIKeyRequest
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			created, secret, err := store.Create(body.Name, body.Role, body.RateLimit)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusCreated, createAPIKeyResponse{APIKey: created, Secret: secret})
+		case http.MethodDelete:
+			if err := store.Revoke(req.URL.Query().Get("id")); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
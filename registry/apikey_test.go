@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyStore_CreateAuthenticateRevoke(t *testing.T) {
+	store := NewAPIKeyStore()
+	key, secret, err := store.Create("ci", RoleExecute, RateLimit{})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasPrefix(secret, apiKeyPrefix) || key.ID == "" || key.CreatedAt.IsZero() {
+		t.Fatalf("created key = %+v, secret = %q", key, secret)
+	}
+	got, err := store.Authenticate(secret)
+	if err != nil || got.ID != key.ID || got.Role != RoleExecute {
+		t.Fatalf("Authenticate = %+v, %v", got, err)
+	}
+	if _, err := store.Authenticate("tdk_wrong"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("unknown secret err = %v", err)
+	}
+
+	if err := store.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := store.Authenticate(secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked secret err = %v", err)
+	}
+	if keys := store.List(); len(keys) != 1 || !keys[0].Revoked() {
+		t.Errorf("List = %+v, want one revoked key", keys)
+	}
+	if err := store.Revoke("missing"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Revoke(missing) err = %v", err)
+	}
+	if _, _, err := store.Create("bad", Role("owner"), RateLimit{}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("unknown role err = %v", err)
+	}
+}
+
+func TestRole_Allows(t *testing.T) {
+	if !RoleAdmin.Allows(RoleExecute) || !RoleExecute.Allows(RoleReadOnly) {
+		t.Error("higher roles should include lower ones")
+	}
+	if RoleReadOnly.Allows(RoleExecute) || Role("").Allows(RoleReadOnly) {
+		t.Error("lower or unknown roles should not be allowed")
+	}
+}
+
+func apiKeyTestServer(t *testing.T, store *APIKeyStore) *httptest.Server {
+	t.Helper()
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"},
+		func(ctx context.Context, args map[string]any) (any, error) {
+			key, _ := APIKeyFromContext(ctx)
+			return key.Name, nil
+		})
+	server := httptest.NewServer(newServeMux(reg, ServeOptions{APIKeys: store}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func postMCP(t *testing.T, url, secret, body string) (int, MCPResponse) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url+DefaultHTTPPath, strings.NewReader(body))
+	if secret != "" {
+		req.Header.Set("Authorization", "Bearer "+secret)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var out MCPResponse
+	if resp.StatusCode == http.StatusOK {
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+	}
+	return resp.StatusCode, out
+}
+
+func TestRequireAPIKey_Roles(t *testing.T) {
+	var events []AuditEvent
+	store := NewAPIKeyStore(APIKeyStoreOptions{OnAudit: func(e AuditEvent) { events = append(events, e) }})
+	_, reader, _ := store.Create("dashboard", RoleReadOnly, RateLimit{})
+	_, runner, _ := store.Create("agent", RoleExecute, RateLimit{})
+	server := apiKeyTestServer(t, store)
+
+	list := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{}}}`
+
+	if status, _ := postMCP(t, server.URL, "", list); status != http.StatusUnauthorized {
+		t.Errorf("missing key status = %d, want 401", status)
+	}
+	if status, _ := postMCP(t, server.URL, "tdk_bogus", list); status != http.StatusUnauthorized {
+		t.Errorf("bad key status = %d, want 401", status)
+	}
+	if _, resp := postMCP(t, server.URL, reader, list); resp.Error != nil {
+		t.Errorf("read-only tools/list error = %+v", resp.Error)
+	}
+	if _, resp := postMCP(t, server.URL, reader, call); resp.Error == nil || resp.Error.Code != ErrCodeForbidden {
+		t.Errorf("read-only tools/call = %+v, want forbidden", resp.Error)
+	}
+	_, resp := postMCP(t, server.URL, runner, call)
+	if resp.Error != nil {
+		t.Fatalf("execute tools/call error = %+v", resp.Error)
+	}
+	// Tool middleware and handlers see the calling key.
+	if result, _ := json.Marshal(resp.Result); !strings.Contains(string(result), "agent") {
+		t.Errorf("tools/call result = %s, want key name", result)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("audit events = %+v, want 3", events)
+	}
+	if e := events[1]; e.KeyName != "dashboard" || e.Allowed || e.ToolID != "echo" || e.Method != "tools/call" {
+		t.Errorf("denied audit event = %+v", e)
+	}
+	if e := events[2]; e.KeyName != "agent" || !e.Allowed {
+		t.Errorf("allowed audit event = %+v", e)
+	}
+}
+
+func TestRequireAPIKey_RateLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewAPIKeyStore(APIKeyStoreOptions{Now: func() time.Time { return now }})
+	_, secret, _ := store.Create("burst", RoleReadOnly, RateLimit{PerSecond: 1, Burst: 2})
+	server := apiKeyTestServer(t, store)
+	list := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+
+	for i := range 2 {
+		if status, _ := postMCP(t, server.URL, secret, list); status != http.StatusOK {
+			t.Fatalf("request %d status = %d", i, status)
+		}
+	}
+	if status, _ := postMCP(t, server.URL, secret, list); status != http.StatusTooManyRequests {
+		t.Errorf("over-limit status = %d, want 429", status)
+	}
+	now = now.Add(time.Second)
+	if status, _ := postMCP(t, server.URL, secret, list); status != http.StatusOK {
+		t.Errorf("status after refill = %d, want 200", status)
+	}
+}
+
+func TestServeAPIKeys(t *testing.T) {
+	store := NewAPIKeyStore()
+	_, admin, _ := store.Create("ops", RoleAdmin, RateLimit{})
+	_, reader, _ := store.Create("dashboard", RoleReadOnly, RateLimit{})
+	server := apiKeyTestServer(t, store)
+
+	do := func(method, path, secret, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", secret)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	if resp := do(http.MethodGet, DefaultAPIKeysPath, reader, ""); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("read-only admin status = %d, want 403", resp.StatusCode)
+	}
+	resp := do(http.MethodPost, DefaultAPIKeysPath, admin, `{"name":"new","role":"execute","rateLimit":{"perSecond":5}}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d", resp.StatusCode)
+	}
+	var created createAPIKeyResponse
+	_ = json.NewDecoder(resp.Body).Decode(&created)
+	if created.Secret == "" || created.Role != RoleExecute || created.RateLimit.Burst != 1 {
+		t.Errorf("created = %+v", created)
+	}
+
+	var keys []APIKey
+	_ = json.NewDecoder(do(http.MethodGet, DefaultAPIKeysPath, admin, "").Body).Decode(&keys)
+	if len(keys) != 3 {
+		t.Errorf("listed %d keys, want 3", len(keys))
+	}
+	if resp := do(http.MethodDelete, DefaultAPIKeysPath+"?id="+created.ID, admin, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("revoke status = %d", resp.StatusCode)
+	}
+	if _, err := store.Authenticate(created.Secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked key still authenticates: %v", err)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	var events []AuditEvent
+	store := NewAPIKeyStore(APIKeyStoreOptions{OnAudit: func(e AuditEvent) { events = append(events, e) }})
+	_, admin, _ := store.Create("ops", RoleAdmin, RateLimit{})
+	_, reader, _ := store.Create("dashboard", RoleReadOnly, RateLimit{})
+	check := RequireRole(RoleAdmin)
+	handler := RequireAPIKey(store, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := check(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+		}
+	}))
+
+	for secret, want := range map[string]int{admin: http.StatusOK, reader: http.StatusForbidden} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/admin/tools/x", nil)
+		req.Header.Set("X-API-Key", secret)
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("status = %d, want %d", rec.Code, want)
+		}
+	}
+	if len(events) != 2 || events[0].Method != "PUT /admin/tools/x" {
+		t.Errorf("audit events = %+v", events)
+	}
+	if err := check(httptest.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrForbidden) {
+		t.Errorf("request without key err = %v, want ErrForbidden", err)
+	}
+}
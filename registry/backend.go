@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolfoundation/model"
 )
 
@@ -19,14 +21,46 @@ import (
 type BackendConfig struct {
 	// Name is a unique identifier for the backend.
 	Name string
-	// URL is the MCP server URL (http(s)://, sse://, stdio://).
+	// URL is the MCP server URL (http(s)://, sse://, unix://, stdio://).
+	// unix:///path/to.sock connects over a Unix domain socket using streamable
+	// HTTP; the HTTP path defaults to /mcp and may be set with ?path=/custom.
 	URL string
 	// Headers are optional HTTP headers for authenticated backends.
 	Headers map[string]string
 	// MaxRetries controls reconnect attempts for streamable HTTP transport.
 	MaxRetries int
-	// RetryInterval is reserved for future use.
+	// RefreshInterval, when positive, re-lists the backend's tools on this
+	// interval after Start and reconciles them into the index, and reconnects
+	// the backend when its session drops. Zero disables the refresh loop.
+	RefreshInterval time.Duration
+	// RetryInterval is the first reconnect delay of the refresh loop; it
+	// doubles after each failed attempt up to MaxRetryInterval. Zero uses
+	// DefaultRetryInterval.
 	RetryInterval time.Duration
+	// MaxRetryInterval caps the reconnect delay. Zero uses
+	// DefaultMaxRetryInterval.
+	MaxRetryInterval time.Duration
+	// TLS configures client certificates (mTLS), CA bundle, and SNI for
+	// http(s):// and sse:// backends. Nil uses system defaults.
+	TLS *TLSConfig
+	// RegisterChunkSize, when positive, registers the backend's tools with
+	// the index in chunks of this size instead of one pass, so backends
+	// advertising tens of thousands of tools don't hold the index lock for
+	// the whole catalog.
+	RegisterChunkSize int
+	// RegisterChunkInterval is the pause between chunks, limiting the rate
+	// at which tools are registered. Zero registers chunks back to back.
+	RegisterChunkInterval time.Duration
+	// RegisterInBackground, with RegisterChunkSize, registers only the first
+	// chunk before Start (or RegisterMCP after Start) returns. The remaining
+	// chunks stream in afterwards while search serves the tools already
+	// registered; Health reports them as PendingTools. The refresh loop
+	// starts once registration completes.
+	RegisterInBackground bool
+	// OnRegisterProgress, if set, is called after each registered chunk and
+	// when registration stops early. It runs on the registering goroutine
+	// and should return quickly.
+	OnRegisterProgress func(RegisterProgress)
 	// Transport overrides URL handling when provided (useful for tests).
 	Transport mcp.Transport
 }
@@ -38,6 +72,14 @@ type mcpBackend struct {
 	tools     []model.Tool
 	mu        sync.RWMutex
 	connected bool
+	done      chan struct{} // closed when session ends
+	lastErr   error         // last refresh loop error; see Health
+
+	pending     []model.Tool // tools left for background registration
+	pendingFrom int          // index of the first unregistered tool in pending
+
+	removed  chan struct{} // closed by UnregisterMCP
+	loopDone chan struct{} // closed when the refresh loop exits; nil if none
 }
 
 // RegisterMCP registers an MCP server as a backend.
@@ -53,19 +95,21 @@ func (r *Registry) RegisterMCP(cfg BackendConfig) error {
 		return fmt.Errorf("backend %s already registered", cfg.Name)
 	}
 
-	backend := &mcpBackend{config: cfg}
+	backend := &mcpBackend{config: cfg, removed: make(chan struct{})}
 	r.backends[cfg.Name] = backend
 	started := r.started
+	stop := r.stopCh
 	r.mu.Unlock()
 
 	if started {
 		if err := backend.connect(context.Background()); err != nil {
 			return fmt.Errorf("failed to connect backend %s: %w", cfg.Name, err)
 		}
-		if err := r.index.RegisterToolsFromMCP(cfg.Name, backend.toolsSnapshot()); err != nil {
+		if err := r.registerBackendTools(context.Background(), cfg.Name, backend); err != nil {
 			_ = backend.disconnect()
 			return fmt.Errorf("failed to register backend %s tools: %w", cfg.Name, err)
 		}
+		r.superviseBackend(stop, cfg.Name, backend)
 	}
 
 	return nil
@@ -82,6 +126,9 @@ func (r *Registry) UnregisterMCP(name string) error {
 	delete(r.backends, name)
 	r.mu.Unlock()
 
+	close(backend.removed)
+	backend.waitLoop()
+
 	tools := backend.toolsSnapshot()
 	for _, tool := range tools {
 		_ = r.index.UnregisterBackend(tool.ToolID(), model.BackendKindMCP, name)
@@ -96,6 +143,79 @@ func (r *Registry) UnregisterMCP(name string) error {
 	return nil
 }
 
+// ResyncBackends re-lists tools from every MCP backend and reconciles the
+// index: new and changed tools are registered and tools a backend no longer
+// reports are removed. Backends that are not connected are reconnected.
+// Indexes implementing index.MCPReplacer reconcile each backend atomically,
+// emitting change events only for tools that changed.
+//
+// It has the scheduler.JobFunc signature so it can run as a periodic job.
+// Errors from individual backends are joined; healthy backends are still synced.
+func (r *Registry) ResyncBackends(ctx context.Context) error {
+	r.mu.RLock()
+	if !r.started {
+		r.mu.RUnlock()
+		return ErrNotStarted
+	}
+	backends := make(map[string]*mcpBackend, len(r.backends))
+	for name, backend := range r.backends {
+		backends[name] = backend
+	}
+	r.mu.RUnlock()
+
+	var errs []error
+	for name, backend := range backends {
+		if err := r.resyncBackend(ctx, name, backend); err != nil {
+			errs = append(errs, fmt.Errorf("resync backend %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) resyncBackend(ctx context.Context, name string, backend *mcpBackend) error {
+	backend.mu.RLock()
+	connected := backend.connected
+	backend.mu.RUnlock()
+
+	var previous, current []model.Tool
+	if !connected {
+		// The tools listed before the session dropped are still the ones
+		// registered, so tools gone after reconnecting are removed.
+		previous = backend.toolsSnapshot()
+		if err := backend.connect(ctx); err != nil {
+			return err
+		}
+		current = backend.toolsSnapshot()
+	} else {
+		var err error
+		previous, current, err = backend.refreshTools(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if replacer, ok := r.index.(index.MCPReplacer); ok {
+		return replacer.ReplaceToolsFromMCP(name, current)
+	}
+	if err := r.index.RegisterToolsFromMCP(name, current); err != nil {
+		return err
+	}
+
+	keep := make(map[string]struct{}, len(current))
+	for _, tool := range current {
+		keep[tool.ToolID()] = struct{}{}
+	}
+	for _, tool := range previous {
+		if _, ok := keep[tool.ToolID()]; ok {
+			continue
+		}
+		if err := r.index.UnregisterBackend(tool.ToolID(), model.BackendKindMCP, name); err != nil && !errors.Is(err, index.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *mcpBackend) connect(ctx context.Context) error {
 	b.mu.Lock()
 	if b.connected {
@@ -115,29 +235,77 @@ func (b *mcpBackend) connect(ctx context.Context) error {
 		return err
 	}
 
-	res, err := session.ListTools(ctx, nil)
+	tools, err := listSessionTools(ctx, session)
 	if err != nil {
 		_ = session.Close()
 		return err
 	}
 
-	tools := make([]model.Tool, 0, len(res.Tools))
-	for _, tool := range res.Tools {
-		if tool == nil {
-			continue
-		}
-		tools = append(tools, model.Tool{Tool: *tool})
-	}
-
+	done := make(chan struct{})
 	b.mu.Lock()
 	b.client = client
 	b.session = session
 	b.tools = tools
 	b.connected = true
+	b.done = done
 	b.mu.Unlock()
+
+	go b.watchSession(session, done)
 	return nil
 }
 
+// watchSession marks the backend disconnected when session ends without
+// disconnect being called, so that resyncs reconnect it, then closes done.
+func (b *mcpBackend) watchSession(session *mcp.ClientSession, done chan struct{}) {
+	_ = session.Wait()
+	b.mu.Lock()
+	if b.session == session {
+		b.client = nil
+		b.session = nil
+		b.connected = false
+	}
+	b.mu.Unlock()
+	close(done)
+}
+
+// refreshTools re-lists tools from a connected backend and returns the
+// previous and current tool sets.
+func (b *mcpBackend) refreshTools(ctx context.Context) (previous, current []model.Tool, err error) {
+	b.mu.RLock()
+	session := b.session
+	b.mu.RUnlock()
+	if session == nil {
+		return nil, nil, fmt.Errorf("backend %s not connected", b.config.Name)
+	}
+
+	tools, err := listSessionTools(ctx, session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b.mu.Lock()
+	previous = b.tools
+	b.tools = tools
+	b.mu.Unlock()
+	return previous, tools, nil
+}
+
+func listSessionTools(ctx context.Context, session *mcp.ClientSession) ([]model.Tool, error) {
+	res, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]model.Tool, 0, len(res.Tools))
+	for _, tool := range res.Tools {
+		if tool == nil {
+			continue
+		}
+		tools = append(tools, model.Tool{Tool: *tool})
+	}
+	return tools, nil
+}
+
 func (b *mcpBackend) disconnect() error {
 	b.mu.Lock()
 	if !b.connected {
@@ -156,7 +324,23 @@ func (b *mcpBackend) disconnect() error {
 	return nil
 }
 
-func (b *mcpBackend) callTool(ctx context.Context, name string, args map[string]any) (any, error) {
+func (b *mcpBackend) callTool(ctx context.Context, name string, args map[string]any, decode BinaryDecodeOptions) (any, error) {
+	result, err := b.callToolResult(ctx, name, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("%w: %s", ErrExecutionFailed, toolResultError(result))
+	}
+	return toolResultValue(result, decode), nil
+}
+
+// callToolResult calls a tool and returns the backend's result as is,
+// including results with IsError set.
+func (b *mcpBackend) callToolResult(ctx context.Context, name string, args map[string]any) (*mcp.CallToolResult, error) {
 	b.mu.RLock()
 	session := b.session
 	connected := b.connected
@@ -173,13 +357,7 @@ func (b *mcpBackend) callTool(ctx context.Context, name string, args map[string]
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrExecutionFailed, err)
 	}
-	if result == nil {
-		return nil, nil
-	}
-	if result.IsError {
-		return nil, fmt.Errorf("%w: %s", ErrExecutionFailed, toolResultError(result))
-	}
-	return toolResultValue(result), nil
+	return result, nil
 }
 
 func (b *mcpBackend) toolsSnapshot() []model.Tool {
@@ -206,7 +384,18 @@ func (b *mcpBackend) transport() (mcp.Transport, error) {
 		return nil, fmt.Errorf("invalid backend URL: %w", err)
 	}
 
-	httpClient := httpClientWithHeaders(b.config.Headers)
+	socketPath := ""
+	if parsed.Scheme == "unix" {
+		socketPath = parsed.Path
+		if socketPath == "" {
+			return nil, errors.New("unix backend URL requires a socket path")
+		}
+	}
+
+	httpClient, err := httpClientFor(b.config.Headers, b.config.TLS, socketPath)
+	if err != nil {
+		return nil, err
+	}
 
 	switch parsed.Scheme {
 	case "http", "https":
@@ -221,6 +410,16 @@ func (b *mcpBackend) transport() (mcp.Transport, error) {
 			Endpoint:   parsed.String(),
 			HTTPClient: httpClient,
 		}, nil
+	case "unix":
+		path := parsed.Query().Get("path")
+		if path == "" {
+			path = DefaultHTTPPath
+		}
+		return &mcp.StreamableClientTransport{
+			Endpoint:   "http://unix" + path,
+			HTTPClient: httpClient,
+			MaxRetries: b.config.MaxRetries,
+		}, nil
 	case "stdio":
 		return &mcp.StdioTransport{}, nil
 	default:
@@ -228,7 +427,38 @@ func (b *mcpBackend) transport() (mcp.Transport, error) {
 	}
 }
 
-func httpClientWithHeaders(headers map[string]string) *http.Client {
+// httpClientFor builds an HTTP client applying headers, TLS settings, and an
+// optional Unix socket dialer. It returns nil when none are configured so the
+// SDK default is used.
+func httpClientFor(headers map[string]string, tlsCfg *TLSConfig, socketPath string) (*http.Client, error) {
+	var base http.RoundTripper = http.DefaultTransport
+	custom := tlsCfg != nil || socketPath != ""
+	if custom {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if tlsCfg != nil {
+			clientTLS, err := tlsCfg.ClientConfig()
+			if err != nil {
+				return nil, err
+			}
+			transport.TLSClientConfig = clientTLS
+		}
+		if socketPath != "" {
+			transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			}
+		}
+		base = transport
+	}
+
+	client := httpClientWithHeaders(base, headers)
+	if client == nil && custom {
+		client = &http.Client{Transport: base}
+	}
+	return client, nil
+}
+
+func httpClientWithHeaders(base http.RoundTripper, headers map[string]string) *http.Client {
 	if len(headers) == 0 {
 		return nil
 	}
@@ -244,7 +474,7 @@ func httpClientWithHeaders(headers map[string]string) *http.Client {
 	}
 	return &http.Client{
 		Transport: &headerRoundTripper{
-			base:    http.DefaultTransport,
+			base:    base,
 			headers: clone,
 		},
 	}
@@ -268,7 +498,7 @@ func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error
 	return base.RoundTrip(req)
 }
 
-func toolResultValue(result *mcp.CallToolResult) any {
+func toolResultValue(result *mcp.CallToolResult, decode BinaryDecodeOptions) any {
 	if result == nil {
 		return nil
 	}
@@ -279,10 +509,35 @@ func toolResultValue(result *mcp.CallToolResult) any {
 		if text, ok := result.Content[0].(*mcp.TextContent); ok {
 			return text.Text
 		}
+		if bin, ok := DecodeBinaryContent(result.Content[0], decode); ok {
+			return bin
+		}
+	}
+	if len(result.Content) > 1 {
+		return contentValues(result.Content, decode)
 	}
 	return result.Content
 }
 
+// contentValues maps the blocks of a multi-content result to text strings
+// and BinaryContent values. Other blocks, such as resource links and text
+// resources, are kept as sent.
+func contentValues(contents []mcp.Content, decode BinaryDecodeOptions) []any {
+	out := make([]any, len(contents))
+	for i, content := range contents {
+		if text, ok := content.(*mcp.TextContent); ok {
+			out[i] = text.Text
+			continue
+		}
+		if bin, ok := DecodeBinaryContent(content, decode); ok {
+			out[i] = bin
+			continue
+		}
+		out[i] = content
+	}
+	return out
+}
+
 func toolResultError(result *mcp.CallToolResult) string {
 	if result == nil {
 		return "tool execution failed"
@@ -0,0 +1,161 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Binary content kinds reported by BinaryContent.Kind.
+const (
+	BinaryKindImage = "image"
+	BinaryKindAudio = "audio"
+	BinaryKindBlob  = "blob"
+)
+
+// BinaryContent is the normalized form of image, audio, and embedded blob
+// content returned by MCP backends.
+//
+// Data holds the decoded bytes. When the registry spills binary content
+// (Config.SpillBinaryContent), Data is nil and Handle can be passed to
+// Registry.FetchResult.
+type BinaryContent struct {
+	Kind     string `json:"kind"`
+	MIMEType string `json:"mimeType,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Size     int    `json:"size"`
+	Data     []byte `json:"data,omitempty"`
+	Handle   string `json:"handle,omitempty"`
+}
+
+// BinaryDecodeOptions configures DecodeBinaryContent.
+type BinaryDecodeOptions struct {
+	// DoubleEncodedBase64 decodes payloads that are themselves base64 text,
+	// as sent by servers that encode binary content twice. Raw payloads made
+	// only of base64 characters are then decoded too, so enable it only for
+	// backends known to double-encode. Default: false.
+	DoubleEncodedBase64 bool
+}
+
+// DecodeBinaryContent converts image, audio, and blob resource content to
+// BinaryContent. It reports false for text, links, and text resources.
+//
+// A missing MIME type is sniffed from the data. Double-encoded payloads are
+// decoded only with BinaryDecodeOptions.DoubleEncodedBase64.
+func DecodeBinaryContent(content mcp.Content, opts ...BinaryDecodeOptions) (BinaryContent, bool) {
+	var opt BinaryDecodeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	var out BinaryContent
+	switch c := content.(type) {
+	case *mcp.ImageContent:
+		out = BinaryContent{Kind: BinaryKindImage, MIMEType: c.MIMEType, Data: c.Data}
+	case *mcp.AudioContent:
+		out = BinaryContent{Kind: BinaryKindAudio, MIMEType: c.MIMEType, Data: c.Data}
+	case *mcp.EmbeddedResource:
+		if c.Resource == nil || len(c.Resource.Blob) == 0 {
+			return BinaryContent{}, false
+		}
+		out = BinaryContent{
+			Kind:     BinaryKindBlob,
+			MIMEType: c.Resource.MIMEType,
+			URI:      c.Resource.URI,
+			Data:     c.Resource.Blob,
+		}
+	default:
+		return BinaryContent{}, false
+	}
+
+	out.Data = decodeBase64Payload(out.Data, opt.DoubleEncodedBase64)
+	if out.MIMEType == "" && len(out.Data) > 0 {
+		out.MIMEType = http.DetectContentType(out.Data)
+	}
+	out.Size = len(out.Data)
+	return out, true
+}
+
+// decodeBase64Payload returns the decoded bytes when decode is set and data
+// is entirely standard base64 text; otherwise it returns a copy of data
+// unchanged.
+func decodeBase64Payload(data []byte, decode bool) []byte {
+	if decode && len(data) > 0 && len(data)%4 == 0 && isBase64Text(data) {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		if n, err := base64.StdEncoding.Decode(decoded, data); err == nil {
+			return decoded[:n]
+		}
+	}
+	out := make([]byte, len(data))
+	copy(out, data)
+	return out
+}
+
+func isBase64Text(data []byte) bool {
+	for i, b := range data {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9', b == '+', b == '/':
+		case b == '=' && i >= len(data)-2:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SetBinaryDecodeOptions sets how BinaryContent results of the given tool
+// ID, served by an MCP backend, are decoded. The zero value restores the
+// default.
+func (r *Registry) SetBinaryDecodeOptions(toolID string, opts BinaryDecodeOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts == (BinaryDecodeOptions{}) {
+		delete(r.binaryDecode, toolID)
+		return
+	}
+	r.binaryDecode[toolID] = opts
+}
+
+// spillBinaryResult moves BinaryContent payloads into the ResultStore when
+// Config.SpillBinaryContent is enabled. Both a single BinaryContent result
+// and BinaryContent items of a multi-content []any result are spilled.
+func (r *Registry) spillBinaryResult(ctx context.Context, result any) (any, error) {
+	if !r.config.SpillBinaryContent || r.config.ResultStore == nil {
+		return result, nil
+	}
+	switch v := result.(type) {
+	case BinaryContent:
+		return r.spillBinary(ctx, v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			bin, ok := item.(BinaryContent)
+			if !ok {
+				out[i] = item
+				continue
+			}
+			spilled, err := r.spillBinary(ctx, bin)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = spilled
+		}
+		return out, nil
+	default:
+		return result, nil
+	}
+}
+
+func (r *Registry) spillBinary(ctx context.Context, bin BinaryContent) (BinaryContent, error) {
+	if bin.Data == nil {
+		return bin, nil
+	}
+	handle, err := r.config.ResultStore.Put(ctx, bin.Data, bin.MIMEType)
+	if err != nil {
+		return BinaryContent{}, err
+	}
+	bin.Handle = handle
+	bin.Data = nil
+	return bin, nil
+}
@@ -0,0 +1,240 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestDecodeBinaryContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  mcp.Content
+		wantOK   bool
+		wantKind string
+		wantMIME string
+	}{
+		{"image", &mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"}, true, BinaryKindImage, "image/png"},
+		{"audio", &mcp.AudioContent{Data: []byte{1, 2, 3}, MIMEType: "audio/wav"}, true, BinaryKindAudio, "audio/wav"},
+		{"blob", &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file://x", Blob: pngHeader}}, true, BinaryKindBlob, "image/png"},
+		{"text resource", &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file://x", Text: "t"}}, false, "", ""},
+		{"text", &mcp.TextContent{Text: "hi"}, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DecodeBinaryContent(tt.content)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.Kind != tt.wantKind {
+				t.Errorf("Kind = %s, want %s", got.Kind, tt.wantKind)
+			}
+			if got.MIMEType != tt.wantMIME {
+				t.Errorf("MIMEType = %s, want %s", got.MIMEType, tt.wantMIME)
+			}
+			if got.Size != len(got.Data) {
+				t.Errorf("Size = %d, want %d", got.Size, len(got.Data))
+			}
+		})
+	}
+}
+
+func TestDecodeBinaryContent_DoubleEncoded(t *testing.T) {
+	encoded := []byte(base64.StdEncoding.EncodeToString(pngHeader))
+	got, ok := DecodeBinaryContent(&mcp.ImageContent{Data: encoded}, BinaryDecodeOptions{DoubleEncodedBase64: true})
+	if !ok {
+		t.Fatal("expected binary content")
+	}
+	if !bytes.Equal(got.Data, pngHeader) {
+		t.Errorf("expected decoded payload, got %q", got.Data)
+	}
+	if got.MIMEType != "image/png" {
+		t.Errorf("MIMEType = %s, want sniffed image/png", got.MIMEType)
+	}
+}
+
+func TestDecodeBinaryContent_Base64TextKeptByDefault(t *testing.T) {
+	raw := []byte("abcd1234")
+	got, ok := DecodeBinaryContent(&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file://x", MIMEType: "text/plain", Blob: raw}})
+	if !ok {
+		t.Fatal("expected binary content")
+	}
+	if !bytes.Equal(got.Data, raw) {
+		t.Errorf("Data = %q, want raw payload %q", got.Data, raw)
+	}
+}
+
+func TestToolResultValue_Image(t *testing.T) {
+	result := toolResultValue(&mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"}},
+	}, BinaryDecodeOptions{})
+	bin, ok := result.(BinaryContent)
+	if !ok {
+		t.Fatalf("expected BinaryContent, got %T", result)
+	}
+	if bin.Kind != BinaryKindImage {
+		t.Errorf("Kind = %s, want image", bin.Kind)
+	}
+}
+
+func TestToolResultValue_MixedContent(t *testing.T) {
+	result := toolResultValue(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "caption"},
+			&mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"},
+			&mcp.ResourceLink{URI: "file://full.png", Name: "full"},
+		},
+	}, BinaryDecodeOptions{})
+	items, ok := result.([]any)
+	if !ok || len(items) != 3 {
+		t.Fatalf("expected 3 items, got %T %v", result, result)
+	}
+	if items[0] != "caption" {
+		t.Errorf("items[0] = %v, want caption", items[0])
+	}
+	bin, ok := items[1].(BinaryContent)
+	if !ok || bin.Kind != BinaryKindImage || !bytes.Equal(bin.Data, pngHeader) {
+		t.Errorf("items[1] = %+v, want decoded image", items[1])
+	}
+	if _, ok := items[2].(*mcp.ResourceLink); !ok {
+		t.Errorf("items[2] = %T, want *mcp.ResourceLink", items[2])
+	}
+}
+
+func TestExecute_SpillsMixedContent(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "snap", Description: "Screenshot"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{
+				&mcp.TextContent{Text: "caption"},
+				&mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"},
+			}}, nil, nil
+		})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{
+		ResultStore:        NewInMemoryResultStore(),
+		SpillBinaryContent: true,
+	})
+	if err := reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	result, err := reg.Execute(ctx, "snap", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	items, ok := result.([]any)
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 items, got %T %v", result, result)
+	}
+	if items[0] != "caption" {
+		t.Errorf("items[0] = %v, want caption", items[0])
+	}
+	bin, ok := items[1].(BinaryContent)
+	if !ok || bin.Data != nil || bin.Handle == "" {
+		t.Fatalf("items[1] = %+v, want spilled image", items[1])
+	}
+	if bin.Kind != BinaryKindImage || bin.Size != len(pngHeader) {
+		t.Errorf("spilled metadata = %+v", bin)
+	}
+
+	data, contentType, err := reg.FetchResult(ctx, bin.Handle)
+	if err != nil {
+		t.Fatalf("FetchResult failed: %v", err)
+	}
+	if !bytes.Equal(data, pngHeader) || contentType != "image/png" {
+		t.Errorf("fetched %q (%s)", data, contentType)
+	}
+}
+
+func TestExecute_SpillsBinaryContent(t *testing.T) {
+	store := NewInMemoryResultStore()
+	reg := New(Config{
+		ResultStore:        store,
+		SpillBinaryContent: true,
+	})
+	_ = reg.RegisterLocalFunc("snap", "Screenshot", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) {
+			bin, _ := DecodeBinaryContent(&mcp.ImageContent{Data: pngHeader, MIMEType: "image/png"})
+			return bin, nil
+		})
+
+	ctx := context.Background()
+	result, err := reg.Execute(ctx, "snap", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	bin, ok := result.(BinaryContent)
+	if !ok {
+		t.Fatalf("expected BinaryContent, got %T", result)
+	}
+	if bin.Data != nil || bin.Handle == "" {
+		t.Fatalf("expected spilled content, got %+v", bin)
+	}
+
+	data, contentType, err := reg.FetchResult(ctx, bin.Handle)
+	if err != nil {
+		t.Fatalf("FetchResult failed: %v", err)
+	}
+	if !bytes.Equal(data, pngHeader) || contentType != "image/png" {
+		t.Errorf("fetched %q (%s)", data, contentType)
+	}
+}
+
+func TestExecute_DecodesDoubleEncodedForConfiguredTools(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	encoded := []byte(base64.StdEncoding.EncodeToString(pngHeader))
+	for _, name := range []string{"snap", "raw"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name, Description: "Screenshot"},
+			func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.ImageContent{Data: encoded, MIMEType: "image/png"}}}, nil, nil
+			})
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+	reg.SetBinaryDecodeOptions("snap", BinaryDecodeOptions{DoubleEncodedBase64: true})
+
+	for name, want := range map[string][]byte{"snap": pngHeader, "raw": encoded} {
+		result, err := reg.Execute(ctx, name, nil)
+		if err != nil {
+			t.Fatalf("Execute(%s) failed: %v", name, err)
+		}
+		if bin, ok := result.(BinaryContent); !ok || !bytes.Equal(bin.Data, want) {
+			t.Errorf("Execute(%s) = %+v, want data %q", name, result, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// RegisterProgress reports the registration of a backend's tools with the
+// index. It is passed to BackendConfig.OnRegisterProgress after every chunk.
+type RegisterProgress struct {
+	// Backend is the backend name.
+	Backend string
+
+	// Registered is the number of the backend's tools registered so far.
+	Registered int
+
+	// Total is the number of tools the backend advertised.
+	Total int
+
+	// Err is set when registration stopped early, because the index
+	// rejected a chunk or the registry was stopped.
+	Err error
+}
+
+// Done reports whether registration finished or stopped.
+func (p RegisterProgress) Done() bool {
+	return p.Err != nil || p.Registered >= p.Total
+}
+
+// registerBackendTools registers the tools of a newly connected backend.
+// With RegisterChunkSize set, tools are registered in chunks; with
+// RegisterInBackground, only the first chunk is registered here and the
+// rest is left to registerPending.
+func (r *Registry) registerBackendTools(ctx context.Context, name string, backend *mcpBackend) error {
+	tools := backend.toolsSnapshot()
+	to := len(tools)
+	if size := backend.config.RegisterChunkSize; backend.config.RegisterInBackground && size > 0 && size < to {
+		to = size
+	}
+	if err := r.registerChunks(ctx, name, backend, tools, 0, to); err != nil {
+		return err
+	}
+	if to < len(tools) {
+		backend.mu.Lock()
+		backend.pending = tools
+		backend.pendingFrom = to
+		backend.mu.Unlock()
+	}
+	return nil
+}
+
+// registerPending registers the tools left by registerBackendTools, if any.
+// Failures are recorded for Health.
+func (r *Registry) registerPending(ctx context.Context, name string, backend *mcpBackend) {
+	backend.mu.RLock()
+	tools, from := backend.pending, backend.pendingFrom
+	backend.mu.RUnlock()
+	if tools == nil {
+		return
+	}
+	err := r.registerChunks(ctx, name, backend, tools, from, len(tools))
+	backend.mu.Lock()
+	backend.pending = nil
+	if err != nil && ctx.Err() == nil {
+		backend.lastErr = err
+	}
+	backend.mu.Unlock()
+}
+
+// registerChunks registers tools[from:to] in chunks of RegisterChunkSize,
+// waiting RegisterChunkInterval between chunks, and reports progress
+// against all of tools.
+func (r *Registry) registerChunks(ctx context.Context, name string, backend *mcpBackend, tools []model.Tool, from, to int) error {
+	cfg := backend.config
+	report := func(registered int, err error) {
+		if cfg.OnRegisterProgress != nil {
+			cfg.OnRegisterProgress(RegisterProgress{Backend: name, Registered: registered, Total: len(tools), Err: err})
+		}
+	}
+	if len(tools) == 0 {
+		report(0, nil)
+		return nil
+	}
+	size := cfg.RegisterChunkSize
+	if size <= 0 {
+		size = len(tools)
+	}
+	for i := from; i < to; i += size {
+		if i > 0 && cfg.RegisterChunkInterval > 0 {
+			timer := time.NewTimer(cfg.RegisterChunkInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			report(i, err)
+			return err
+		}
+		end := min(i+size, to)
+		if err := r.index.RegisterToolsFromMCP(name, tools[i:end]); err != nil {
+			report(i, err)
+			return err
+		}
+		backend.mu.Lock()
+		backend.pendingFrom = end
+		backend.mu.Unlock()
+		report(end, nil)
+	}
+	return nil
+}
+
+// pendingTools returns the number of tools waiting for background
+// registration.
+func (b *mcpBackend) pendingTools() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.pending == nil {
+		return 0
+	}
+	return len(b.pending) - b.pendingFrom
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func startBulkServer(t *testing.T, tools int) mcp.Transport {
+	t.Helper()
+	server := mcp.NewServer(&mcp.Implementation{Name: "bulk-server"}, nil)
+	for i := range tools {
+		mcp.AddTool(server, &mcp.Tool{Name: fmt.Sprintf("tool_%03d", i), Description: "Bulk tool"},
+			func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+				return nil, nil, nil
+			})
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+	return clientTransport
+}
+
+func TestRegisterChunks(t *testing.T) {
+	var mu sync.Mutex
+	var progress []RegisterProgress
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{
+		Name:              "bulk",
+		Transport:         startBulkServer(t, 25),
+		RegisterChunkSize: 10,
+		OnRegisterProgress: func(p RegisterProgress) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+	}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	var registered []int
+	for _, p := range progress {
+		if p.Backend != "bulk" || p.Total != 25 || p.Err != nil {
+			t.Errorf("progress = %+v", p)
+		}
+		registered = append(registered, p.Registered)
+	}
+	if fmt.Sprint(registered) != "[10 20 25]" || !progress[len(progress)-1].Done() {
+		t.Errorf("registered = %v, want [10 20 25] ending done", registered)
+	}
+	if got := reg.Health().Index.Tools; got != 25 {
+		t.Errorf("indexed tools = %d, want 25", got)
+	}
+}
+
+func TestRegisterInBackground(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan struct{})
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{
+		Name:                  "bulk",
+		Transport:             startBulkServer(t, 25),
+		RegisterChunkSize:     10,
+		RegisterChunkInterval: time.Millisecond,
+		RegisterInBackground:  true,
+		OnRegisterProgress: func(p RegisterProgress) {
+			switch {
+			case p.Registered == 20:
+				<-release
+			case p.Done():
+				close(done)
+			}
+		},
+	}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	// Start returns after the first chunk; search serves it immediately.
+	report := reg.Health()
+	if report.Index.Tools < 10 || report.Index.Tools > 20 {
+		t.Errorf("indexed tools after Start = %d, want 10-20", report.Index.Tools)
+	}
+	if report.Status != HealthOK {
+		t.Errorf("status = %s, want ok while registering", report.Status)
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("background registration did not finish")
+	}
+	report = reg.Health()
+	if report.Index.Tools != 25 || report.Backends[0].PendingTools != 0 {
+		t.Errorf("after registration: tools=%d pending=%d, want 25 and 0", report.Index.Tools, report.Backends[0].PendingTools)
+	}
+}
+
+func TestRegisterInBackground_StopCancels(t *testing.T) {
+	var mu sync.Mutex
+	var last RegisterProgress
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{
+		Name:                  "bulk",
+		Transport:             startBulkServer(t, 30),
+		RegisterChunkSize:     10,
+		RegisterChunkInterval: time.Hour,
+		RegisterInBackground:  true,
+		OnRegisterProgress: func(p RegisterProgress) {
+			mu.Lock()
+			last = p
+			mu.Unlock()
+		},
+	}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if pending := reg.Health().Backends[0].PendingTools; pending != 20 {
+		t.Errorf("PendingTools = %d, want 20", pending)
+	}
+	if err := reg.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last.Err == nil || last.Registered != 10 || !last.Done() {
+		t.Errorf("last progress = %+v, want canceled after 10", last)
+	}
+}
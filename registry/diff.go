@@ -0,0 +1,258 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// DiffKind classifies a ToolDiff.
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// ToolDiff is one difference between the tools a backend lists now and
+// the tools registered from it.
+type ToolDiff struct {
+	Kind   DiffKind `json:"kind"`
+	ToolID string   `json:"toolId"`
+
+	// Fields names the changed fields of a DiffChanged tool: "title",
+	// "description", "inputSchema", "outputSchema", "annotations", or
+	// "meta".
+	Fields []string `json:"fields,omitempty"`
+
+	// Registered is the registered tool; nil for DiffAdded.
+	Registered *model.Tool `json:"registered,omitempty"`
+
+	// Live is the tool the backend lists now; nil for DiffRemoved.
+	Live *model.Tool `json:"live,omitempty"`
+}
+
+// BackendDiff is the drift between a backend and the registry, computed by
+// DiffBackend and committed with ApplyDiff.
+type BackendDiff struct {
+	Backend string `json:"backend"`
+
+	// Changes are ordered by tool ID.
+	Changes []ToolDiff `json:"changes"`
+
+	base string // fingerprint of the registered tools the diff was computed against
+}
+
+// Empty reports whether the backend has not drifted.
+func (d *BackendDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// DiffBackend re-lists the tools of a connected MCP backend and reports
+// the tools added, removed, or changed since they were last registered,
+// without applying anything, so operators can review drift before the
+// refresh loop or ResyncBackends syncs it.
+func (r *Registry) DiffBackend(ctx context.Context, name string) (*BackendDiff, error) {
+	r.mu.RLock()
+	backend, ok := r.backends[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBackendNotFound, name)
+	}
+	backend.mu.RLock()
+	session := backend.session
+	backend.mu.RUnlock()
+	if session == nil {
+		return nil, fmt.Errorf("%w: backend %s not connected", ErrBackendNotFound, name)
+	}
+
+	live, err := listSessionTools(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list tools: %v", ErrExecutionFailed, err)
+	}
+	registered := backend.toolsSnapshot()
+	base, err := toolsFingerprint(registered)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &BackendDiff{Backend: name, Changes: []ToolDiff{}, base: base}
+	before := make(map[string]*model.Tool, len(registered))
+	for i := range registered {
+		before[registered[i].ToolID()] = &registered[i]
+	}
+	seen := make(map[string]struct{}, len(live))
+	for i := range live {
+		tool := &live[i]
+		id := tool.ToolID()
+		seen[id] = struct{}{}
+		prev, ok := before[id]
+		if !ok {
+			diff.Changes = append(diff.Changes, ToolDiff{Kind: DiffAdded, ToolID: id, Live: tool})
+			continue
+		}
+		if fields := changedFields(*prev, *tool); len(fields) > 0 {
+			diff.Changes = append(diff.Changes, ToolDiff{Kind: DiffChanged, ToolID: id, Fields: fields, Registered: prev, Live: tool})
+		}
+	}
+	for id, prev := range before {
+		if _, ok := seen[id]; !ok {
+			diff.Changes = append(diff.Changes, ToolDiff{Kind: DiffRemoved, ToolID: id, Registered: prev})
+		}
+	}
+	slices.SortFunc(diff.Changes, func(a, b ToolDiff) int { return strings.Compare(a.ToolID, b.ToolID) })
+	return diff, nil
+}
+
+// ApplyDiff commits the changes of diff for the given tool IDs, or every
+// change when none are given: added and changed tools are registered and
+// removed tools are unregistered from the backend. It fails with
+// ErrInvalidRequest, applying nothing, when a tool ID is not in the diff or
+// the backend's registered tools changed since DiffBackend, for example
+// because the refresh loop synced it; compute a new diff and retry. When
+// the index rejects part of the change, the backend's registered tools are
+// left as they were, so the remaining drift shows in the next diff.
+func (r *Registry) ApplyDiff(diff *BackendDiff, toolIDs ...string) error {
+	if diff == nil {
+		return fmt.Errorf("%w: diff is required", ErrInvalidRequest)
+	}
+	r.mu.RLock()
+	backend, ok := r.backends[diff.Backend]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBackendNotFound, diff.Backend)
+	}
+
+	selected := diff.Changes
+	if len(toolIDs) > 0 {
+		byID := make(map[string]ToolDiff, len(diff.Changes))
+		for _, change := range diff.Changes {
+			byID[change.ToolID] = change
+		}
+		selected = make([]ToolDiff, 0, len(toolIDs))
+		for _, id := range toolIDs {
+			change, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("%w: tool %s is not in the diff", ErrInvalidRequest, id)
+			}
+			selected = append(selected, change)
+		}
+	}
+
+	// Hold the backend lock across the index update so a concurrent resync
+	// cannot interleave, and record the new tool set only once it applied.
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	base, err := toolsFingerprint(backend.tools)
+	if err != nil {
+		return err
+	}
+	if base != diff.base {
+		return fmt.Errorf("%w: backend %s changed since the diff", ErrInvalidRequest, diff.Backend)
+	}
+	tools := slices.Clone(backend.tools)
+	for _, change := range selected {
+		i := slices.IndexFunc(tools, func(t model.Tool) bool { return t.ToolID() == change.ToolID })
+		switch {
+		case change.Kind != DiffRemoved && i >= 0:
+			tools[i] = *change.Live
+		case change.Kind != DiffRemoved:
+			tools = append(tools, *change.Live)
+		case i >= 0:
+			tools = slices.Delete(tools, i, i+1)
+		}
+	}
+
+	if err := r.applyDiffToIndex(diff.Backend, selected, tools); err != nil {
+		return err
+	}
+	backend.tools = tools
+	return nil
+}
+
+// applyDiffToIndex applies the selected changes of a backend's diff to the
+// index, where tools is the backend's tool set after them. Indexes
+// implementing index.MCPReplacer replace the set atomically, updating
+// changed tools in place so their metadata, rollout, and disabled state
+// survive; others get the changes one by one.
+func (r *Registry) applyDiffToIndex(name string, selected []ToolDiff, tools []model.Tool) error {
+	if replacer, ok := r.index.(index.MCPReplacer); ok {
+		return replacer.ReplaceToolsFromMCP(name, tools)
+	}
+
+	var register []model.Tool
+	var errs []error
+	for _, change := range selected {
+		if change.Kind == DiffAdded {
+			register = append(register, *change.Live)
+			continue
+		}
+		// Changed tools are unregistered first: the index rejects a
+		// registration whose MCP fields differ from the existing one, and
+		// applying the diff is the operator's approval of the change.
+		err := r.index.UnregisterBackend(change.ToolID, model.BackendKindMCP, name)
+		if err != nil && !errors.Is(err, index.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("unregister %s: %w", change.ToolID, err))
+			continue
+		}
+		if change.Kind == DiffChanged {
+			register = append(register, *change.Live)
+		}
+	}
+	if len(register) > 0 {
+		if err := r.index.RegisterToolsFromMCP(name, register); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// changedFields names the fields that differ between two versions of a
+// tool.
+func changedFields(a, b model.Tool) []string {
+	fields := []struct {
+		name string
+		a, b any
+	}{
+		{"title", a.Title, b.Title},
+		{"description", a.Description, b.Description},
+		{"inputSchema", a.InputSchema, b.InputSchema},
+		{"outputSchema", a.OutputSchema, b.OutputSchema},
+		{"annotations", a.Annotations, b.Annotations},
+		{"meta", a.Meta, b.Meta},
+	}
+	var changed []string
+	for _, f := range fields {
+		if !jsonEqual(f.a, f.b) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+// jsonEqual compares values by their JSON encoding, so schemas decoded into
+// different Go types compare by content.
+func jsonEqual(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func toolsFingerprint(tools []model.Tool) (string, error) {
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return "", fmt.Errorf("fingerprint tools: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+func TestDiffBackend(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	noop := func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "edit", Description: "Edit a file"}, noop)
+	mcp.AddTool(server, &mcp.Tool{Name: "old", Description: "Old tool"}, noop)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{})
+	_ = reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport})
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	if _, err := reg.DiffBackend(ctx, "missing"); !errors.Is(err, ErrBackendNotFound) {
+		t.Fatalf("DiffBackend(missing) err = %v, want ErrBackendNotFound", err)
+	}
+	diff, err := reg.DiffBackend(ctx, "remote")
+	if err != nil {
+		t.Fatalf("DiffBackend failed: %v", err)
+	}
+	if !diff.Empty() {
+		t.Fatalf("diff before drift = %+v, want empty", diff.Changes)
+	}
+
+	mcp.AddTool(server, &mcp.Tool{Name: "edit", Description: "Edit a file in place"}, noop)
+	mcp.AddTool(server, &mcp.Tool{Name: "new", Description: "New tool"}, noop)
+	server.RemoveTools("old")
+
+	diff, err = reg.DiffBackend(ctx, "remote")
+	if err != nil {
+		t.Fatalf("DiffBackend failed: %v", err)
+	}
+	var got []string
+	for _, change := range diff.Changes {
+		got = append(got, string(change.Kind)+":"+change.ToolID)
+	}
+	if !slices.Equal(got, []string{"changed:edit", "added:new", "removed:old"}) {
+		t.Fatalf("changes = %v", got)
+	}
+	if fields := diff.Changes[0].Fields; !slices.Equal(fields, []string{"description"}) {
+		t.Errorf("changed fields = %v, want [description]", fields)
+	}
+
+	// Nothing is applied until ApplyDiff.
+	if _, err := reg.GetTool(ctx, "new"); err == nil {
+		t.Fatal("DiffBackend registered the added tool")
+	}
+
+	if err := reg.ApplyDiff(diff, "missing"); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("ApplyDiff(missing) err = %v, want ErrInvalidRequest", err)
+	}
+	if err := reg.ApplyDiff(diff, "new"); err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "new"); err != nil {
+		t.Errorf("added tool not registered: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "old"); err != nil {
+		t.Errorf("unselected removal was applied: %v", err)
+	}
+
+	// The first apply changed the baseline, so the diff is stale.
+	if err := reg.ApplyDiff(diff); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("ApplyDiff(stale) err = %v, want ErrInvalidRequest", err)
+	}
+
+	idx := reg.index.(*index.InMemoryIndex)
+	edit, backend, err := idx.GetTool("edit")
+	if err != nil {
+		t.Fatalf("GetTool(edit) failed: %v", err)
+	}
+	if err := idx.RegisterToolWithMetadata(edit, backend, map[string]string{"team": "editors"}); err != nil {
+		t.Fatalf("RegisterToolWithMetadata failed: %v", err)
+	}
+
+	diff, err = reg.DiffBackend(ctx, "remote")
+	if err != nil {
+		t.Fatalf("DiffBackend failed: %v", err)
+	}
+	if len(diff.Changes) != 2 {
+		t.Fatalf("remaining changes = %+v, want 2", diff.Changes)
+	}
+	if err := reg.ApplyDiff(diff); err != nil {
+		t.Fatalf("ApplyDiff failed: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "old"); err == nil {
+		t.Error("removed tool still registered")
+	}
+	tool, err := reg.GetTool(ctx, "edit")
+	if err != nil || tool.Description != "Edit a file in place" {
+		t.Errorf("edit = %+v, %v; want updated description", tool, err)
+	}
+	// The changed tool was updated in place, keeping its metadata.
+	if md, err := idx.GetMetadata("edit"); err != nil || md["team"] != "editors" {
+		t.Errorf("edit metadata = %v, %v; want team=editors", md, err)
+	}
+	if diff, err := reg.DiffBackend(ctx, "remote"); err != nil || !diff.Empty() {
+		t.Errorf("diff after apply = %+v, %v; want empty", diff, err)
+	}
+}
@@ -6,10 +6,20 @@
 //
 // Features:
 //   - Local tool registration with handlers
+//   - Execution middleware (Use) for logging, auth, and rate limiting
+//   - Optional argument validation against tool input schemas
+//   - Structured MCP tool results for hosts (ExecuteDetailed)
 //   - MCP backend connections (streamable HTTP, SSE, stdio)
+//   - Periodic backend tool refresh with reconnect backoff (RefreshInterval)
+//   - Catalog drift review before syncing (DiffBackend, ApplyDiff)
+//   - Backend selection explanations for debugging (ExplainBackend)
 //   - BM25-based tool search
 //   - MCP protocol handlers (initialize, tools/list, tools/call)
+//   - tools/list cursor pagination and list_changed notifications
 //   - Multiple transports (stdio, HTTP, SSE)
+//   - API keys with roles, rate limits, and audit events (RequireAPIKey)
+//   - Embedded catalog browser with faceted search (ServeUI)
+//   - OpenTelemetry spans for Execute and backend call duration metrics
 //
 // Example usage:
 //
@@ -1,16 +1,25 @@
 package registry
 
-import "errors"
+import "github.com/jonwraymond/tooldiscovery/errcode"
 
 // Sentinel errors for consistent error handling.
 var (
-	ErrNotStarted      = errors.New("registry not started")
-	ErrAlreadyStarted  = errors.New("registry already started")
-	ErrToolNotFound    = errors.New("tool not found")
-	ErrBackendNotFound = errors.New("backend not found")
-	ErrHandlerNotFound = errors.New("handler not found")
-	ErrExecutionFailed = errors.New("tool execution failed")
-	ErrInvalidRequest  = errors.New("invalid request")
+	ErrNotStarted       = errcode.New(errcode.NotStarted, "registry not started")
+	ErrAlreadyStarted   = errcode.New(errcode.AlreadyStarted, "registry already started")
+	ErrToolNotFound     = errcode.New(errcode.ToolNotFound, "tool not found")
+	ErrBackendNotFound  = errcode.New(errcode.BackendNotFound, "backend not found")
+	ErrHandlerNotFound  = errcode.New(errcode.HandlerNotFound, "handler not found")
+	ErrExecutionFailed  = errcode.New(errcode.ExecutionFailed, "tool execution failed")
+	ErrInvalidRequest   = errcode.New(errcode.InvalidArgument, "invalid request")
+	ErrResultNotFound   = errcode.New(errcode.ResultNotFound, "result not found")
+	ErrInvalidTLSConfig = errcode.New(errcode.InvalidArgument, "invalid TLS config")
+	ErrUnderMaintenance = errcode.New(errcode.UnderMaintenance, "tool under maintenance")
+	ErrInvalidArgs      = errcode.New(errcode.InvalidArgs, "invalid arguments")
+	ErrInvalidAPIKey    = errcode.New(errcode.Unauthenticated, "invalid API key")
+	ErrForbidden        = errcode.New(errcode.PermissionDenied, "forbidden")
+	ErrRateLimited      = errcode.New(errcode.RateLimited, "rate limit exceeded")
+	ErrOverloaded       = errcode.New(errcode.Overloaded, "too many concurrent calls")
+	ErrCallTimeout      = errcode.New(errcode.DeadlineExceeded, "tool call timed out")
 )
 
 // MCP JSON-RPC 2.0 error codes as per the spec.
@@ -22,4 +31,7 @@ const (
 	ErrCodeInternal       = -32603
 	ErrCodeToolNotFound   = -32001
 	ErrCodeToolExecFailed = -32002
+	ErrCodeForbidden      = -32003
+	ErrCodeOverloaded     = -32004
+	ErrCodeCallTimeout    = -32005
 )
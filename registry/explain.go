@@ -0,0 +1,65 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// ExplainBackend reports which backends GetTool and Execute consider for a
+// tool, with their priority and health, and why the one they use was
+// chosen. It returns ErrToolNotFound, wrapping the index error, for unknown
+// tools, ErrInvalidRequest when the index does not implement
+// index.BackendExplainer, other index errors as they are, and ctx's error
+// when it is done.
+func (r *Registry) ExplainBackend(ctx context.Context, id string) (index.BackendDecision, error) {
+	if err := ctx.Err(); err != nil {
+		return index.BackendDecision{}, err
+	}
+	explainer, ok := r.index.(index.BackendExplainer)
+	if !ok {
+		return index.BackendDecision{}, fmt.Errorf("%w: index does not explain backend selection", ErrInvalidRequest)
+	}
+	decision, err := explainer.ExplainBackend(id)
+	if errors.Is(err, index.ErrNotFound) {
+		return index.BackendDecision{}, fmt.Errorf("%w: %w", ErrToolNotFound, err)
+	}
+	if err != nil {
+		return index.BackendDecision{}, err
+	}
+	return decision, nil
+}
+
+// LastBackendDecision returns the backend decision recorded by the most
+// recent Execute of a tool. It reports false unless Config.ExplainBackends
+// is set and the tool has been executed.
+func (r *Registry) LastBackendDecision(id string) (index.BackendDecision, bool) {
+	r.decisionsMu.Lock()
+	defer r.decisionsMu.Unlock()
+	decision, ok := r.decisions[id]
+	return decision, ok
+}
+
+// recordBackendDecision stores the backend decision for a tool about to
+// run when Config.ExplainBackends is set.
+func (r *Registry) recordBackendDecision(id string) {
+	if !r.config.ExplainBackends {
+		return
+	}
+	explainer, ok := r.index.(index.BackendExplainer)
+	if !ok {
+		return
+	}
+	decision, err := explainer.ExplainBackend(id)
+	if err != nil {
+		return
+	}
+	r.decisionsMu.Lock()
+	if r.decisions == nil {
+		r.decisions = make(map[string]index.BackendDecision)
+	}
+	r.decisions[id] = decision
+	r.decisionsMu.Unlock()
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func TestExplainBackend(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	reg := New(Config{Index: idx, ExplainBackends: true})
+	err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	tool, _, err := idx.GetTool("ping")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if err := idx.RegisterTool(tool, model.NewMCPBackend("remote")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := idx.SetBackendHealth("ping", "ping", index.HealthStatus{State: index.HealthDegraded}); err != nil {
+		t.Fatalf("SetBackendHealth failed: %v", err)
+	}
+
+	if _, ok := reg.LastBackendDecision("ping"); ok {
+		t.Error("decision recorded before Execute")
+	}
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	decision, ok := reg.LastBackendDecision("ping")
+	if !ok {
+		t.Fatal("no decision recorded by Execute")
+	}
+	if decision.Selected.Kind != model.BackendKindLocal || len(decision.Candidates) != 2 {
+		t.Fatalf("decision = %+v, want local chosen of 2 candidates", decision)
+	}
+	if !decision.Candidates[0].Selected || decision.Candidates[1].Priority <= decision.Candidates[0].Priority {
+		t.Errorf("candidates = %+v", decision.Candidates)
+	}
+	if decision.Candidates[0].Health.State != index.HealthDegraded {
+		t.Errorf("local health = %+v, want degraded", decision.Candidates[0].Health)
+	}
+	if !strings.Contains(decision.Reason, `"local"`) || !strings.Contains(decision.Reason, "ignores health") {
+		t.Errorf("reason = %q", decision.Reason)
+	}
+
+	explained, err := reg.ExplainBackend(context.Background(), "ping")
+	if err != nil || explained.Reason != decision.Reason {
+		t.Errorf("ExplainBackend = %+v, %v", explained, err)
+	}
+	if _, err := reg.ExplainBackend(context.Background(), "missing"); !errors.Is(err, ErrToolNotFound) || !errors.Is(err, index.ErrNotFound) {
+		t.Errorf("ExplainBackend(missing) err = %v, want ErrToolNotFound wrapping index.ErrNotFound", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := reg.ExplainBackend(ctx, "ping"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExplainBackend(cancelled) err = %v, want context.Canceled", err)
+	}
+}
+
+func TestExplainBackend_Disabled(t *testing.T) {
+	reg := New(Config{})
+	err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := reg.LastBackendDecision("ping"); ok {
+		t.Error("decision recorded without ExplainBackends")
+	}
+	decision, err := reg.ExplainBackend(context.Background(), "ping")
+	if err != nil || decision.Reason != "only registered backend" {
+		t.Errorf("ExplainBackend = %+v, %v", decision, err)
+	}
+}
@@ -2,9 +2,11 @@ package registry
 
 import (
 	"context"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolfoundation/model"
 )
 
@@ -17,9 +19,11 @@ type ToolHandler func(ctx context.Context, args map[string]any) (any, error)
 type LocalToolOption func(*localToolConfig)
 
 type localToolConfig struct {
-	namespace string
-	tags      []string
-	version   string
+	namespace   string
+	tags        []string
+	version     string
+	transformer ResultTransformer
+	timeout     time.Duration
 }
 
 // WithNamespace sets the namespace for a local tool.
@@ -29,7 +33,8 @@ func WithNamespace(ns string) LocalToolOption {
 	}
 }
 
-// WithTags sets the tags for a local tool.
+// WithTags sets the tags for a local tool, normalized with the tag policy of
+// the registry's index.
 func WithTags(tags ...string) LocalToolOption {
 	return func(c *localToolConfig) {
 		c.tags = tags
@@ -43,6 +48,14 @@ func WithVersion(v string) LocalToolOption {
 	}
 }
 
+// WithResultTransformer sets a per-tool transformer applied to the handler's
+// result before the registry-wide Config.ResultTransformer.
+func WithResultTransformer(t ResultTransformer) LocalToolOption {
+	return func(c *localToolConfig) {
+		c.transformer = t
+	}
+}
+
 func applyLocalToolOptions(opts []LocalToolOption) localToolConfig {
 	cfg := localToolConfig{}
 	for _, opt := range opts {
@@ -51,7 +64,9 @@ func applyLocalToolOptions(opts []LocalToolOption) localToolConfig {
 	return cfg
 }
 
-func buildLocalTool(name, description string, inputSchema map[string]any, cfg localToolConfig) model.Tool {
+// buildLocalTool builds the tool registered by RegisterLocalFunc, normalizing
+// its tags with the tag policy of idx.
+func buildLocalTool(idx index.Index, name, description string, inputSchema map[string]any, cfg localToolConfig) model.Tool {
 	tool := model.Tool{
 		Tool: mcp.Tool{
 			Name:        name,
@@ -60,7 +75,7 @@ func buildLocalTool(name, description string, inputSchema map[string]any, cfg lo
 		},
 		Namespace: cfg.namespace,
 		Version:   cfg.version,
-		Tags:      model.NormalizeTags(cfg.tags),
+		Tags:      index.NormalizeTags(idx, cfg.tags),
 	}
 	return tool
 }
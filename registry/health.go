@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Default health endpoint paths mounted by Serve.
+const (
+	DefaultHealthzPath = "/healthz"
+	DefaultReadyzPath  = "/readyz"
+)
+
+// HealthStatus is the overall result of a health check.
+type HealthStatus string
+
+const (
+	HealthOK          HealthStatus = "ok"
+	HealthUnavailable HealthStatus = "unavailable"
+)
+
+// HealthReport describes registry readiness.
+type HealthReport struct {
+	Status HealthStatus `json:"status"`
+
+	// Started reports whether Start has been called and Stop has not.
+	Started bool `json:"started"`
+
+	// WarmedUp reports whether Start finished connecting backends and
+	// refreshing the index's cached search docs.
+	WarmedUp bool `json:"warmedUp"`
+
+	Index    IndexHealth     `json:"index"`
+	Backends []BackendHealth `json:"backends,omitempty"`
+}
+
+// IndexHealth describes the tool index.
+type IndexHealth struct {
+	// Tools is the number of registered tools. Indexes that list ToolIDs,
+	// such as index.InMemoryIndex, count hidden tools too; others are
+	// counted by an empty search.
+	Tools   int    `json:"tools"`
+	Version uint64 `json:"version"`
+}
+
+// BackendHealth describes the connectivity of an MCP backend.
+type BackendHealth struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	// LastError is the most recent refresh loop failure, cleared by the next
+	// successful refresh. See BackendConfig.RefreshInterval.
+	LastError string `json:"lastError,omitempty"`
+	// PendingTools is the number of tools still waiting for background
+	// registration. See BackendConfig.RegisterInBackground.
+	PendingTools int `json:"pendingTools,omitempty"`
+}
+
+// Health reports whether the registry is ready to serve traffic: it must be
+// started, warmed up, and connected to every registered MCP backend.
+func (r *Registry) Health() HealthReport {
+	r.mu.RLock()
+	report := HealthReport{
+		Started:  r.started,
+		WarmedUp: r.started && r.warm,
+	}
+	backends := make([]BackendHealth, 0, len(r.backends))
+	for name, backend := range r.backends {
+		backend.mu.RLock()
+		health := BackendHealth{Name: name, Connected: backend.connected}
+		if backend.lastErr != nil {
+			health.LastError = backend.lastErr.Error()
+		}
+		if backend.pending != nil {
+			health.PendingTools = len(backend.pending) - backend.pendingFrom
+		}
+		backends = append(backends, health)
+		backend.mu.RUnlock()
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+	report.Backends = backends
+	report.Index = IndexHealth{Version: index.Version(r.index)}
+	report.Index.Tools = toolCount(r.index)
+
+	report.Status = HealthOK
+	if !report.WarmedUp {
+		report.Status = HealthUnavailable
+	}
+	for _, b := range backends {
+		if !b.Connected {
+			report.Status = HealthUnavailable
+		}
+	}
+	return report
+}
+
+// toolCount returns the number of tools in idx, or 0 when an empty search
+// fails.
+func toolCount(idx index.Index) int {
+	if l, ok := idx.(interface{ ToolIDs() []string }); ok {
+		return len(l.ToolIDs())
+	}
+	tools, err := idx.Search("", 1<<30)
+	if err != nil {
+		return 0
+	}
+	return len(tools)
+}
+
+// ServeLiveness returns an http.Handler for liveness probes. It responds
+// 200 whenever the process can serve HTTP; readiness is reported separately
+// so a slow backend does not get the process restarted.
+func ServeLiveness(_ *Registry) http.Handler {
+	return healthHandler(func() (int, any) {
+		return http.StatusOK, map[string]HealthStatus{"status": HealthOK}
+	})
+}
+
+// ServeReadiness returns an http.Handler for readiness probes. It responds
+// 200 with a HealthReport when the registry is ready and 503 otherwise.
+func ServeReadiness(r *Registry) http.Handler {
+	return healthHandler(func() (int, any) {
+		report := r.Health()
+		if report.Status != HealthOK {
+			return http.StatusServiceUnavailable, report
+		}
+		return http.StatusOK, report
+	})
+}
+
+func healthHandler(check func() (int, any)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		code, body := check()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		if req.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(body)
+		}
+	})
+}
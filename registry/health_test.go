@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+func getHealth(t *testing.T, h http.Handler, path string) (int, HealthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var report HealthReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	return rec.Code, report
+}
+
+func TestHealth_ReadinessFollowsLifecycle(t *testing.T) {
+	reg := New(Config{})
+	if err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	}); err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	mux := newServeMux(reg, ServeOptions{})
+
+	if code, report := getHealth(t, mux, DefaultReadyzPath); code != http.StatusServiceUnavailable || report.WarmedUp {
+		t.Fatalf("before Start: code=%d report=%+v, want 503 not warmed up", code, report)
+	}
+	if code, report := getHealth(t, mux, DefaultHealthzPath); code != http.StatusOK || report.Status != HealthOK {
+		t.Fatalf("liveness before Start: code=%d report=%+v, want 200 ok", code, report)
+	}
+
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	code, report := getHealth(t, mux, DefaultReadyzPath)
+	if code != http.StatusOK || report.Status != HealthOK || !report.Started || !report.WarmedUp {
+		t.Fatalf("after Start: code=%d report=%+v, want 200 ready", code, report)
+	}
+	if report.Index.Tools != 1 || report.Index.Version == 0 {
+		t.Errorf("index health = %+v, want 1 tool and a non-zero version", report.Index)
+	}
+
+	_ = reg.Stop()
+	if code, _ := getHealth(t, mux, DefaultReadyzPath); code != http.StatusServiceUnavailable {
+		t.Fatalf("after Stop: code=%d, want 503", code)
+	}
+}
+
+func TestHealth_CountsAllTools(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	reg := New(Config{Index: idx})
+	handler := func(ctx context.Context, args map[string]any) (any, error) { return nil, nil }
+	for _, name := range []string{"ping", "pong"} {
+		if err := reg.RegisterLocalFunc(name, name, map[string]any{"type": "object"}, handler); err != nil {
+			t.Fatalf("RegisterLocalFunc failed: %v", err)
+		}
+	}
+	if err := idx.DisableTool("pong"); err != nil {
+		t.Fatalf("DisableTool failed: %v", err)
+	}
+	if got := reg.Health().Index.Tools; got != 2 {
+		t.Errorf("Index.Tools = %d, want 2 including the hidden tool", got)
+	}
+}
+
+func TestHealth_DisconnectedBackendNotReady(t *testing.T) {
+	reg := New(Config{})
+	clientTransport, _ := mcp.NewInMemoryTransports()
+	if err := reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	// Simulate a backend that dropped after a successful Start.
+	reg.mu.Lock()
+	reg.started, reg.warm = true, true
+	reg.mu.Unlock()
+
+	code, report := getHealth(t, ServeReadiness(reg), DefaultReadyzPath)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("code = %d, want 503", code)
+	}
+	if len(report.Backends) != 1 || report.Backends[0].Name != "remote" || report.Backends[0].Connected {
+		t.Fatalf("backends = %+v, want disconnected remote", report.Backends)
+	}
+}
+
+func TestHealth_MethodAndCustomPaths(t *testing.T) {
+	reg := New(Config{})
+	mux := newServeMux(reg, ServeOptions{HealthzPath: "/live", ReadyzPath: "/ready"})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/live", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /live = %d, want 405", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.Len() != 0 {
+		t.Errorf("HEAD /ready = %d with %d body bytes, want 503 and empty body", rec.Code, rec.Body.Len())
+	}
+
+	mux = newServeMux(reg, ServeOptions{DisableHealth: true})
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultHealthzPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("disabled health = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithTimeout bounds each call of a local tool. The handler's context is
+// cancelled after d, and the call fails with ErrCallTimeout. Zero or
+// negative values disable the timeout.
+func WithTimeout(d time.Duration) LocalToolOption {
+	return func(c *localToolConfig) {
+		c.timeout = d
+	}
+}
+
+// SetCallTimeout sets the per-call timeout for the given tool ID, including
+// tools served by MCP backends. Zero or negative values remove it.
+func (r *Registry) SetCallTimeout(toolID string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d <= 0 {
+		delete(r.timeouts, toolID)
+		return
+	}
+	r.timeouts[toolID] = d
+}
+
+// acquireCall reserves one of Config.MaxConcurrentCalls slots, failing fast
+// with ErrOverloaded when none is free.
+func (r *Registry) acquireCall() (release func(), err error) {
+	if r.calls == nil {
+		return func() {}, nil
+	}
+	select {
+	case r.calls <- struct{}{}:
+		return func() { <-r.calls }, nil
+	default:
+		return nil, fmt.Errorf("%w: %d calls in flight", ErrOverloaded, cap(r.calls))
+	}
+}
+
+// runWithTimeout runs handler under the tool's call timeout, if any.
+func (r *Registry) runWithTimeout(ctx context.Context, toolID string, handler ToolHandler, args map[string]any) (any, error) {
+	r.mu.RLock()
+	timeout := r.timeouts[toolID]
+	r.mu.RUnlock()
+	if timeout <= 0 {
+		return handler(ctx, args)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := handler(callCtx, args)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %s after %s", ErrCallTimeout, toolID, timeout)
+	}
+	return result, err
+}
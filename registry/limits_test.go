@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecute_MaxConcurrentCalls(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}, MaxConcurrentCalls: 1})
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	_ = reg.RegisterLocalFunc("slow", "Slow", map[string]any{"type": "object"},
+		func(ctx context.Context, args map[string]any) (any, error) {
+			close(entered)
+			<-unblock
+			return "done", nil
+		})
+	_ = reg.RegisterLocalFunc("fast", "Fast", map[string]any{"type": "object"},
+		func(ctx context.Context, args map[string]any) (any, error) { return "ok", nil })
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reg.Execute(context.Background(), "slow", nil)
+		done <- err
+	}()
+	<-entered
+
+	if _, err := reg.Execute(context.Background(), "fast", nil); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("Execute while full err = %v, want ErrOverloaded", err)
+	}
+	params, _ := json.Marshal(map[string]any{"name": "fast", "arguments": map[string]any{}})
+	resp := reg.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != ErrCodeOverloaded {
+		t.Errorf("tools/call while full error = %+v, want ErrCodeOverloaded", resp.Error)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("slow call failed: %v", err)
+	}
+	if _, err := reg.Execute(context.Background(), "fast", nil); err != nil {
+		t.Errorf("Execute after slot freed: %v", err)
+	}
+}
+
+func TestExecute_WithTimeout(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	_ = reg.RegisterLocalFunc("hang", "Hangs until cancelled", map[string]any{"type": "object"},
+		func(ctx context.Context, args map[string]any) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, WithTimeout(10*time.Millisecond))
+
+	if _, err := reg.Execute(context.Background(), "hang", nil); !errors.Is(err, ErrCallTimeout) {
+		t.Fatalf("Execute err = %v, want ErrCallTimeout", err)
+	}
+	params, _ := json.Marshal(map[string]any{"name": "hang", "arguments": map[string]any{}})
+	resp := reg.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != ErrCodeCallTimeout {
+		t.Errorf("tools/call error = %+v, want ErrCodeCallTimeout", resp.Error)
+	}
+
+	// Cancellation by the caller is not reported as a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := reg.Execute(ctx, "hang", nil); errors.Is(err, ErrCallTimeout) || !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Execute err = %v, want context.Canceled", err)
+	}
+
+	reg.SetCallTimeout("hang", 0)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := reg.Execute(ctx, "hang", nil); !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrCallTimeout) {
+		t.Errorf("Execute without tool timeout err = %v, want caller deadline", err)
+	}
+}
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// MaintenancePolicy controls how Execute treats tools under maintenance.
+type MaintenancePolicy string
+
+// Maintenance policies.
+const (
+	// MaintenanceAllow executes tools normally; Summary.Degraded is still set.
+	MaintenanceAllow MaintenancePolicy = ""
+	// MaintenanceWarn executes tools and reports the window to Config.OnMaintenance.
+	MaintenanceWarn MaintenancePolicy = "warn"
+	// MaintenanceBlock rejects execution with ErrUnderMaintenance.
+	MaintenanceBlock MaintenancePolicy = "block"
+)
+
+// MaintenanceHook is called when Execute runs into an active maintenance window
+// under the warn or block policies.
+type MaintenanceHook func(ctx context.Context, toolID string, window index.MaintenanceWindow)
+
+// AddMaintenanceWindow registers a maintenance window for a namespace or
+// backend. Affected tools are reported as degraded in search summaries and
+// handled by Config.MaintenancePolicy on Execute. It returns
+// ErrInvalidRequest when the index does not implement
+// index.MaintenanceScheduler.
+func (r *Registry) AddMaintenanceWindow(w index.MaintenanceWindow) error {
+	scheduler, ok := r.index.(index.MaintenanceScheduler)
+	if !ok {
+		return fmt.Errorf("%w: index does not support maintenance windows", ErrInvalidRequest)
+	}
+	return scheduler.AddMaintenanceWindow(w)
+}
+
+// RemoveMaintenanceWindow deletes a maintenance window by ID.
+func (r *Registry) RemoveMaintenanceWindow(id string) error {
+	scheduler, ok := r.index.(index.MaintenanceScheduler)
+	if !ok {
+		return fmt.Errorf("%w: index does not support maintenance windows", ErrInvalidRequest)
+	}
+	return scheduler.RemoveMaintenanceWindow(id)
+}
+
+// MaintenanceWindows returns all registered maintenance windows, or nil when
+// the index does not support them.
+func (r *Registry) MaintenanceWindows() []index.MaintenanceWindow {
+	scheduler, ok := r.index.(index.MaintenanceScheduler)
+	if !ok {
+		return nil
+	}
+	return scheduler.MaintenanceWindows()
+}
+
+// checkMaintenance applies the maintenance policy for a tool about to run.
+func (r *Registry) checkMaintenance(ctx context.Context, toolID string) error {
+	policy := r.config.MaintenancePolicy
+	if policy == MaintenanceAllow {
+		return nil
+	}
+	scheduler, ok := r.index.(index.MaintenanceScheduler)
+	if !ok {
+		return nil
+	}
+	window, active := scheduler.ActiveMaintenance(toolID)
+	if !active {
+		return nil
+	}
+	if r.config.OnMaintenance != nil {
+		r.config.OnMaintenance(ctx, toolID, window)
+	}
+	if policy == MaintenanceBlock {
+		if window.Message != "" {
+			return fmt.Errorf("%w: %s: %s", ErrUnderMaintenance, toolID, window.Message)
+		}
+		return fmt.Errorf("%w: %s", ErrUnderMaintenance, toolID)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+func newMaintenanceRegistry(t *testing.T, policy MaintenancePolicy, hook MaintenanceHook) *Registry {
+	t.Helper()
+	reg := New(Config{MaintenancePolicy: policy, OnMaintenance: hook})
+	err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	if err := reg.AddMaintenanceWindow(index.MaintenanceWindow{
+		ID:      "upgrade",
+		Backend: "ping",
+		Start:   time.Now().Add(-time.Minute),
+		End:     time.Now().Add(time.Hour),
+		Message: "database upgrade",
+	}); err != nil {
+		t.Fatalf("AddMaintenanceWindow failed: %v", err)
+	}
+	return reg
+}
+
+func TestMaintenance_Block(t *testing.T) {
+	var hooked string
+	reg := newMaintenanceRegistry(t, MaintenanceBlock, func(_ context.Context, toolID string, _ index.MaintenanceWindow) {
+		hooked = toolID
+	})
+
+	_, err := reg.Execute(context.Background(), "ping", nil)
+	if !errors.Is(err, ErrUnderMaintenance) {
+		t.Fatalf("expected ErrUnderMaintenance, got %v", err)
+	}
+	if hooked != "ping" {
+		t.Errorf("expected hook for ping, got %q", hooked)
+	}
+
+	summaries, _ := reg.SearchSummaries(context.Background(), "ping", 10)
+	if len(summaries) != 1 || !summaries[0].Degraded || summaries[0].DegradedReason != "database upgrade" {
+		t.Fatalf("expected degraded summary, got %+v", summaries)
+	}
+
+	_ = reg.RemoveMaintenanceWindow("upgrade")
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("expected execution after window removed, got %v", err)
+	}
+}
+
+func TestMaintenance_WarnAndAllow(t *testing.T) {
+	warned := 0
+	reg := newMaintenanceRegistry(t, MaintenanceWarn, func(context.Context, string, index.MaintenanceWindow) { warned++ })
+	if result, err := reg.Execute(context.Background(), "ping", nil); err != nil || result != "pong" {
+		t.Fatalf("warn policy should execute, got %v, %v", result, err)
+	}
+	if warned != 1 {
+		t.Errorf("expected 1 warning, got %d", warned)
+	}
+
+	allowed := 0
+	reg = newMaintenanceRegistry(t, MaintenanceAllow, func(context.Context, string, index.MaintenanceWindow) { allowed++ })
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("allow policy should execute, got %v", err)
+	}
+	if allowed != 0 {
+		t.Errorf("allow policy should not call the hook, got %d", allowed)
+	}
+}
+
+func TestMaintenance_InjectedClock(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(-time.Minute)
+	reg := New(Config{MaintenancePolicy: MaintenanceBlock, Now: func() time.Time { return now }})
+	_ = reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(context.Context, map[string]any) (any, error) {
+		return "pong", nil
+	})
+	_ = reg.AddMaintenanceWindow(index.MaintenanceWindow{ID: "upgrade", Backend: "ping", Start: start, End: start.Add(time.Hour)})
+
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("before window: %v", err)
+	}
+	now = start.Add(time.Minute)
+	if _, err := reg.Execute(context.Background(), "ping", nil); !errors.Is(err, ErrUnderMaintenance) {
+		t.Fatalf("during window err = %v, want ErrUnderMaintenance", err)
+	}
+	now = start.Add(2 * time.Hour)
+	if _, err := reg.Execute(context.Background(), "ping", nil); err != nil {
+		t.Fatalf("after window: %v", err)
+	}
+}
+
+// plainIndex hides the optional capabilities of the wrapped index.
+type plainIndex struct{ index.Index }
+
+func TestRegistry_IndexWithoutOptionalCapabilities(t *testing.T) {
+	reg := New(Config{Index: plainIndex{index.NewInMemoryIndex()}, MaintenancePolicy: MaintenanceBlock})
+	err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+
+	err = reg.AddMaintenanceWindow(index.MaintenanceWindow{ID: "w", Backend: "ping", Start: time.Now()})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("AddMaintenanceWindow err = %v, want ErrInvalidRequest", err)
+	}
+	if windows := reg.MaintenanceWindows(); windows != nil {
+		t.Fatalf("MaintenanceWindows = %v, want nil", windows)
+	}
+	if result, err := reg.Execute(context.Background(), "ping", nil); err != nil || result != "pong" {
+		t.Fatalf("Execute = %v, %v", result, err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+	if v := reg.Stats().IndexVersion; v != 0 {
+		t.Fatalf("IndexVersion = %d, want 0", v)
+	}
+	if v := reg.Refresh(); v != 0 {
+		t.Fatalf("Refresh = %d, want 0", v)
+	}
+}
+
+func TestRegistry_CustomIndex(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	reg := New(Config{Index: idx})
+	err := reg.RegisterLocalFunc("ping", "Ping", map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	if _, _, err := idx.GetTool("ping"); err != nil {
+		t.Fatalf("tool not registered in supplied index: %v", err)
+	}
+	if got := reg.Stats().IndexVersion; got != idx.Version() {
+		t.Fatalf("IndexVersion = %d, want %d", got, idx.Version())
+	}
+}
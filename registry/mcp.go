@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolfoundation/model"
 )
 
@@ -33,7 +34,16 @@ type MCPError struct {
 }
 
 // HandleRequest processes an MCP request and returns a response.
+// Requests authenticated by RequireAPIKey are rejected with ErrCodeForbidden
+// when the key's role does not allow the method.
 func (r *Registry) HandleRequest(ctx context.Context, req MCPRequest) MCPResponse {
+	if err := authorizeRequest(ctx, req); err != nil {
+		return MCPResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error:   &MCPError{Code: ErrCodeForbidden, Message: err.Error()},
+		}
+	}
 	switch req.Method {
 	case "initialize":
 		return r.handleInitialize(ctx, req.ID, req.Params)
@@ -57,7 +67,7 @@ func (r *Registry) handleInitialize(_ context.Context, id any, _ json.RawMessage
 	result := map[string]any{
 		"protocolVersion": model.MCPVersion,
 		"capabilities": map[string]any{
-			"tools": map[string]any{},
+			"tools": map[string]any{"listChanged": true},
 		},
 		"serverInfo": map[string]any{
 			"name":    r.config.ServerInfo.Name,
@@ -72,14 +82,48 @@ func (r *Registry) handleInitialize(_ context.Context, id any, _ json.RawMessage
 	}
 }
 
-func (r *Registry) handleToolsList(ctx context.Context, id any, _ json.RawMessage) MCPResponse {
-	tools, err := r.ListAll(ctx)
+type toolsListParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+func (r *Registry) handleToolsList(ctx context.Context, id any, params json.RawMessage) MCPResponse {
+	var listParams toolsListParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &listParams); err != nil {
+			return MCPResponse{
+				JSONRPC: "2.0",
+				ID:      id,
+				Error: &MCPError{
+					Code:    ErrCodeInvalidParams,
+					Message: err.Error(),
+				},
+			}
+		}
+	}
+
+	var (
+		tools      []model.Tool
+		nextCursor string
+		err        error
+	)
+	switch {
+	case r.config.ToolsListPageSize > 0:
+		tools, nextCursor, err = r.ListPage(ctx, r.config.ToolsListPageSize, listParams.Cursor)
+	case listParams.Cursor != "":
+		err = fmt.Errorf("%w: pagination is disabled", index.ErrInvalidCursor)
+	default:
+		tools, err = r.ListAll(ctx)
+	}
 	if err != nil {
+		code := ErrCodeInternal
+		if errors.Is(err, index.ErrInvalidCursor) {
+			code = ErrCodeInvalidParams
+		}
 		return MCPResponse{
 			JSONRPC: "2.0",
 			ID:      id,
 			Error: &MCPError{
-				Code:    ErrCodeInternal,
+				Code:    code,
 				Message: err.Error(),
 			},
 		}
@@ -90,9 +134,16 @@ func (r *Registry) handleToolsList(ctx context.Context, id any, _ json.RawMessag
 		mcpTools = append(mcpTools, toMCPTool(tool))
 	}
 
+	mcpTools, truncated := fitToolsList(mcpTools, r.config.MaxResponseBytes)
 	result := map[string]any{
 		"tools": mcpTools,
 	}
+	if truncated {
+		result["truncated"] = true
+	}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
 
 	return MCPResponse{
 		JSONRPC: "2.0",
@@ -121,9 +172,26 @@ func (r *Registry) handleToolsCall(ctx context.Context, id any, params json.RawM
 
 	result, err := r.Execute(ctx, callParams.Name, callParams.Arguments)
 	if err != nil {
+		var argsErr *ArgsError
+		if errors.As(err, &argsErr) {
+			return MCPResponse{
+				JSONRPC: "2.0",
+				ID:      id,
+				Error: &MCPError{
+					Code:    ErrCodeInvalidParams,
+					Message: err.Error(),
+					Data:    argsErr,
+				},
+			}
+		}
 		code := ErrCodeToolExecFailed
-		if errors.Is(err, ErrToolNotFound) {
+		switch {
+		case errors.Is(err, ErrToolNotFound):
 			code = ErrCodeToolNotFound
+		case errors.Is(err, ErrOverloaded):
+			code = ErrCodeOverloaded
+		case errors.Is(err, ErrCallTimeout):
+			code = ErrCodeCallTimeout
 		}
 		return MCPResponse{
 			JSONRPC: "2.0",
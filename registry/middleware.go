@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// Middleware wraps tool execution with cross-cutting behavior such as
+// logging, auth, rate limiting, or argument validation. It receives the next
+// handler in the chain and returns a handler that may inspect or rewrite
+// args, short-circuit with an error, or post-process the result.
+// CallInfoFromContext identifies the tool being called.
+type Middleware func(next ToolHandler) ToolHandler
+
+// CallInfo describes the tool call a Middleware is wrapping.
+type CallInfo struct {
+	// ToolID is the canonical tool ID.
+	ToolID string
+	// Tool is the resolved tool definition.
+	Tool model.Tool
+	// Backend is the backend selected to serve the call.
+	Backend model.ToolBackend
+}
+
+type callInfoKey struct{}
+
+// CallInfoFromContext returns the CallInfo for the tool call in progress.
+// It reports false outside of Execute.
+func CallInfoFromContext(ctx context.Context) (CallInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(CallInfo)
+	return info, ok
+}
+
+// Use appends middleware to the execution chain. The first middleware
+// registered is outermost. Middleware wraps both local handlers and MCP
+// backend calls; it runs after tool lookup and maintenance checks and before
+// result transformers and spilling. Nil middleware is ignored.
+func (r *Registry) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, mw := range middleware {
+		if mw != nil {
+			r.middleware = append(r.middleware, mw)
+		}
+	}
+}
+
+// wrapMiddleware applies the registered middleware to handler.
+func (r *Registry) wrapMiddleware(handler ToolHandler) ToolHandler {
+	r.mu.RLock()
+	middleware := r.middleware
+	r.mu.RUnlock()
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func TestUse_OrderAndCallInfo(t *testing.T) {
+	var order []string
+	reg := New(Config{})
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"},
+		func(_ context.Context, args map[string]any) (any, error) {
+			order = append(order, "handler")
+			return args["message"], nil
+		}, WithNamespace("util"))
+
+	named := func(name string) Middleware {
+		return func(next ToolHandler) ToolHandler {
+			return func(ctx context.Context, args map[string]any) (any, error) {
+				info, ok := CallInfoFromContext(ctx)
+				if !ok || info.ToolID != "util:echo" || info.Backend.Kind != model.BackendKindLocal {
+					t.Errorf("%s: CallInfo = %+v (%v)", name, info, ok)
+				}
+				order = append(order, name)
+				return next(ctx, args)
+			}
+		}
+	}
+	rewrite := func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (any, error) {
+			return next(ctx, map[string]any{"message": "rewritten"})
+		}
+	}
+	reg.Use(named("outer"), nil, named("inner"))
+	reg.Use(rewrite)
+
+	result, err := reg.Execute(context.Background(), "util:echo", map[string]any{"message": "hi"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "rewritten" {
+		t.Errorf("result = %v, want rewritten", result)
+	}
+	if len(order) != 3 || order[0] != "outer" || order[1] != "inner" || order[2] != "handler" {
+		t.Errorf("order = %v, want outer, inner, handler", order)
+	}
+	if _, ok := CallInfoFromContext(context.Background()); ok {
+		t.Error("CallInfoFromContext outside Execute should report false")
+	}
+}
+
+func TestUse_ShortCircuit(t *testing.T) {
+	reg := New(Config{})
+	called := false
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) {
+			called = true
+			return "ok", nil
+		})
+	errDenied := errors.New("denied")
+	reg.Use(func(ToolHandler) ToolHandler {
+		return func(context.Context, map[string]any) (any, error) {
+			return nil, errDenied
+		}
+	})
+
+	if _, err := reg.Execute(context.Background(), "echo", nil); !errors.Is(err, errDenied) {
+		t.Fatalf("Execute err = %v, want errDenied", err)
+	}
+	if called {
+		t.Error("handler ran despite middleware rejecting the call")
+	}
+}
+
+func TestUse_WrapsMCPBackend(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo", Description: "Echo tool"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return nil, map[string]any{"ok": true}, nil
+		})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	var seen CallInfo
+	reg.Use(func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (any, error) {
+			seen, _ = CallInfoFromContext(ctx)
+			return next(ctx, args)
+		}
+	})
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	if _, err := reg.Execute(ctx, "echo", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if seen.Backend.Kind != model.BackendKindMCP || seen.Backend.MCP == nil || seen.Backend.MCP.ServerName != "remote" {
+		t.Fatalf("CallInfo = %+v, want MCP backend remote", seen)
+	}
+}
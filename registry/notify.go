@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"sync/atomic"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// MethodToolsListChanged is the MCP notification sent when the set of tools
+// returned by tools/list changes.
+const MethodToolsListChanged = "notifications/tools/list_changed"
+
+// MCPNotification is a JSON-RPC notification sent by the server. Unlike an
+// MCPResponse it carries no ID and expects no reply.
+type MCPNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+var toolsListChanged = MCPNotification{JSONRPC: "2.0", Method: MethodToolsListChanged}
+
+// OnToolsListChanged calls fn each time the index version advances, as
+// reported by the index's change events (registration, removal, and
+// refresh). Indexes that do not implement index.ChangeNotifier never call
+// fn. fn runs on the mutating goroutine and must not block.
+func (r *Registry) OnToolsListChanged(fn func()) (unsubscribe func()) {
+	var last atomic.Uint64
+	last.Store(index.Version(r.index))
+	return index.OnChange(r.index, func(ev index.ChangeEvent) {
+		for {
+			seen := last.Load()
+			if ev.Version != 0 && ev.Version <= seen {
+				return
+			}
+			if last.CompareAndSwap(seen, ev.Version) {
+				fn()
+				return
+			}
+		}
+	})
+}
+
+// listChangedSignal returns a channel that receives a value when the tool
+// list changes. Changes arriving before the previous one is consumed
+// coalesce, so a burst of registrations yields one notification.
+func (r *Registry) listChangedSignal() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	unsubscribe := r.OnToolsListChanged(func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	})
+	return ch, unsubscribe
+}
+
+// openStream registers a long-lived notification stream. done is closed by
+// closeStreams, which Serve calls on shutdown because http.Server.Shutdown
+// waits for active requests to finish on their own.
+func (r *Registry) openStream() (done <-chan struct{}, release func()) {
+	ch := make(chan struct{})
+	r.mu.Lock()
+	if r.streams == nil {
+		r.streams = make(map[chan struct{}]struct{})
+	}
+	r.streams[ch] = struct{}{}
+	r.mu.Unlock()
+	return ch, func() {
+		r.mu.Lock()
+		if _, ok := r.streams[ch]; ok {
+			delete(r.streams, ch)
+			close(ch)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// closeStreams ends every open notification stream.
+func (r *Registry) closeStreams() {
+	r.mu.Lock()
+	for ch := range r.streams {
+		delete(r.streams, ch)
+		close(ch)
+	}
+	r.mu.Unlock()
+}
@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func noopHandler(ctx context.Context, args map[string]any) (any, error) { return nil, nil }
+
+func TestOnToolsListChanged(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	calls := 0
+	unsubscribe := reg.OnToolsListChanged(func() { calls++ })
+
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"}, noopHandler)
+	if calls == 0 {
+		t.Fatal("expected a notification after registering a tool")
+	}
+	unsubscribe()
+	before := calls
+	_ = reg.RegisterLocalFunc("other", "Other", map[string]any{"type": "object"}, noopHandler)
+	if calls != before {
+		t.Errorf("notified after unsubscribe: %d calls, want %d", calls, before)
+	}
+}
+
+func TestServeStdio_ListChangedNotification(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serveStdio(context.Background(), reg, inR, outW)
+		_ = outW.Close()
+	}()
+	lines := bufio.NewScanner(outR)
+
+	// A tools/list round trip guarantees the server is subscribed, and the
+	// notification is the only message written after it.
+	go func() { _, _ = io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`+"\n") }()
+	if !lines.Scan() {
+		t.Fatalf("no response: %v", lines.Err())
+	}
+	var resp MCPResponse
+	if err := json.Unmarshal(lines.Bytes(), &resp); err != nil || resp.Error != nil {
+		t.Fatalf("response = %s, %v", lines.Bytes(), err)
+	}
+
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"}, noopHandler)
+	if !lines.Scan() {
+		t.Fatalf("no notification: %v", lines.Err())
+	}
+	var note MCPNotification
+	if err := json.Unmarshal(lines.Bytes(), &note); err != nil || note.Method != MethodToolsListChanged {
+		t.Fatalf("notification = %s, %v", lines.Bytes(), err)
+	}
+	if strings.Contains(lines.Text(), `"id"`) {
+		t.Errorf("notification carries an id: %s", lines.Text())
+	}
+
+	_ = inW.Close()
+	go func() { _, _ = io.Copy(io.Discard, outR) }()
+	if err := <-errCh; err != nil {
+		t.Fatalf("serveStdio returned %v", err)
+	}
+}
+
+func TestServeSSE_ListChangedStream(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	srv := httptest.NewServer(ServeSSE(reg))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"}, noopHandler)
+
+	data := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				data <- line
+				return
+			}
+		}
+	}()
+	select {
+	case line := <-data:
+		var note MCPNotification
+		if err := json.Unmarshal([]byte(line), &note); err != nil || note.Method != MethodToolsListChanged {
+			t.Fatalf("event data = %s, %v", line, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for list_changed event")
+	}
+
+	// closeStreams (run by Serve on shutdown) ends open streams.
+	reg.closeStreams()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Fatalf("stream did not end cleanly: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"time"
+)
+
+// Reconnect backoff defaults for backends with a RefreshInterval.
+const (
+	DefaultRetryInterval    = time.Second
+	DefaultMaxRetryInterval = time.Minute
+)
+
+// superviseBackend finishes background registration of backend's tools
+// and then starts the refresh loop when its RefreshInterval is positive. It
+// runs until stop is closed or the backend is unregistered; Stop and
+// UnregisterMCP wait for it via waitLoop.
+func (r *Registry) superviseBackend(stop <-chan struct{}, name string, backend *mcpBackend) {
+	if backend.config.RefreshInterval <= 0 && backend.pendingTools() == 0 {
+		return
+	}
+	loopDone := make(chan struct{})
+	backend.mu.Lock()
+	backend.loopDone = loopDone
+	backend.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+		case <-backend.removed:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	go func() {
+		defer close(loopDone)
+		defer cancel()
+		r.registerPending(ctx, name, backend)
+		if backend.config.RefreshInterval > 0 {
+			r.refreshLoop(ctx, name, backend)
+		}
+	}()
+}
+
+// refreshLoop resyncs backend every RefreshInterval and reconnects it when
+// its session drops. Failed refreshes are retried on the next tick; tools
+// stay indexed while the backend is unreachable.
+func (r *Registry) refreshLoop(ctx context.Context, name string, backend *mcpBackend) {
+	ticker := time.NewTicker(backend.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-backend.sessionDone():
+			r.reconnectBackend(ctx, name, backend)
+		case <-ticker.C:
+			_ = r.refreshBackend(ctx, name, backend)
+		}
+	}
+}
+
+// reconnectBackend resyncs a disconnected backend, doubling the delay
+// between failed attempts from RetryInterval up to MaxRetryInterval, until
+// it connects or ctx is done.
+func (r *Registry) reconnectBackend(ctx context.Context, name string, backend *mcpBackend) {
+	delay := backend.config.RetryInterval
+	if delay <= 0 {
+		delay = DefaultRetryInterval
+	}
+	maxDelay := backend.config.MaxRetryInterval
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRetryInterval
+	}
+	for ctx.Err() == nil {
+		if err := r.refreshBackend(ctx, name, backend); err == nil || backend.isConnected() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// refreshBackend runs one resync bounded by RefreshInterval and records its
+// result for Health.
+func (r *Registry) refreshBackend(ctx context.Context, name string, backend *mcpBackend) error {
+	ctx, cancel := context.WithTimeout(ctx, backend.config.RefreshInterval)
+	defer cancel()
+	err := r.resyncBackend(ctx, name, backend)
+	backend.mu.Lock()
+	backend.lastErr = err
+	backend.mu.Unlock()
+	return err
+}
+
+// sessionDone returns a channel closed when the current session ends, or
+// nil when the backend has never connected.
+func (b *mcpBackend) sessionDone() <-chan struct{} {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.done
+}
+
+func (b *mcpBackend) isConnected() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.connected
+}
+
+// waitLoop blocks until the backend's refresh loop, if any, has exited.
+func (b *mcpBackend) waitLoop() {
+	b.mu.RLock()
+	loopDone := b.loopDone
+	b.mu.RUnlock()
+	if loopDone != nil {
+		<-loopDone
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/search"
@@ -15,6 +19,59 @@ type Config struct {
 	SearchConfig    *search.BM25Config
 	ServerInfo      ServerInfo
 	BackendSelector index.BackendSelector
+	// Index stores registered tools. Default: an index.InMemoryIndex built
+	// from SearchConfig, BackendSelector, and Now, which are ignored when
+	// Index is set. Versions, refresh, and maintenance windows are used when
+	// the index implements the matching optional interfaces.
+	Index index.Index
+	// ResultTransformer is applied to every tool result after execution,
+	// after any per-tool transformer. Nil leaves results unchanged.
+	ResultTransformer ResultTransformer
+	// LargeResultThreshold is the serialized size in bytes above which results
+	// are spilled to ResultStore and replaced with a ResultReference.
+	// Zero disables spilling.
+	LargeResultThreshold int
+	// ResultStore holds spilled results. Required for spilling.
+	ResultStore ResultStore
+	// SpillBinaryContent moves BinaryContent payloads into ResultStore,
+	// leaving only metadata and a retrieval handle in the result.
+	SpillBinaryContent bool
+	// MaintenancePolicy decides whether tools under an active maintenance
+	// window run normally, run with a warning, or are blocked.
+	MaintenancePolicy MaintenancePolicy
+	// OnMaintenance is notified when Execute hits an active maintenance window
+	// under the warn or block policies.
+	OnMaintenance MaintenanceHook
+	// MaxResponseBytes caps the encoded size of tools/list results. Oversized
+	// results have descriptions trimmed, then trailing tools dropped, and are
+	// marked with "truncated": true. Zero disables the guard.
+	MaxResponseBytes int
+	// ToolsListPageSize, when positive, makes tools/list return pages of at
+	// most this many tools with a "nextCursor" for the next page. Zero
+	// returns every tool in one response.
+	ToolsListPageSize int
+	// MaxConcurrentCalls caps the number of Execute calls running at once.
+	// Calls beyond the cap fail immediately with ErrOverloaded, which
+	// tools/call reports as ErrCodeOverloaded. Zero means no limit.
+	MaxConcurrentCalls int
+	// ValidateArgs validates call arguments against the tool's InputSchema
+	// before middleware and execution. Invalid arguments fail with an
+	// *ArgsError listing the failing paths, which tools/call reports as
+	// ErrCodeInvalidParams.
+	ValidateArgs bool
+	// TracerProvider traces Execute, including tools/call, with a
+	// registry.Execute span. Default: no tracing.
+	TracerProvider trace.TracerProvider
+	// MeterProvider records the registry.backend.call.duration histogram
+	// for calls on local handlers and MCP backends. Default: no metrics.
+	MeterProvider metric.MeterProvider
+	// ExplainBackends records, for every Execute, which backends were
+	// considered and why one was chosen, for LastBackendDecision. Requires
+	// an index implementing index.BackendExplainer. Default: off.
+	ExplainBackends bool
+	// Now returns the current time for maintenance window checks.
+	// Default: time.Now.
+	Now func() time.Time
 }
 
 // ServerInfo describes this MCP server for initialize response.
@@ -27,20 +84,35 @@ type ServerInfo struct {
 // local tool registration, and MCP backend connection.
 type Registry struct {
 	mu       sync.RWMutex
-	index    *index.InMemoryIndex
+	index    index.Index
 	searcher *search.BM25Searcher
 	config   Config
 
-	handlers map[string]ToolHandler
-	backends map[string]*mcpBackend
+	handlers     map[string]ToolHandler
+	backends     map[string]*mcpBackend
+	transformers map[string]ResultTransformer
+	timeouts     map[string]time.Duration
+	binaryDecode map[string]BinaryDecodeOptions
+	calls        chan struct{} // Execute slots; nil when unlimited
+	middleware   []Middleware
+	validator    model.SchemaValidator
+	telemetry    *telemetry
 
 	started bool
+	warm    bool // Start completed; see Health
 	stopCh  chan struct{}
+
+	streams map[chan struct{}]struct{} // open notification streams; see openStream
+
+	// decisionsMu guards decisions apart from mu, so recording a decision on
+	// every Execute does not contend with registration.
+	decisionsMu sync.Mutex
+	decisions   map[string]index.BackendDecision // by tool ID; see ExplainBackends
 }
 
 // New creates a new Registry with the given config.
 func New(cfg Config) *Registry {
-	indexOpts := index.IndexOptions{}
+	indexOpts := index.IndexOptions{Now: cfg.Now}
 	if cfg.BackendSelector != nil {
 		indexOpts.BackendSelector = cfg.BackendSelector
 	}
@@ -51,15 +123,29 @@ func New(cfg Config) *Registry {
 	}
 	indexOpts.Searcher = searcher
 
-	idx := index.NewInMemoryIndex(indexOpts)
+	var idx index.Index = cfg.Index
+	if idx == nil {
+		idx = index.NewInMemoryIndex(indexOpts)
+	}
+
+	var calls chan struct{}
+	if cfg.MaxConcurrentCalls > 0 {
+		calls = make(chan struct{}, cfg.MaxConcurrentCalls)
+	}
 
 	return &Registry{
-		index:    idx,
-		searcher: searcher,
-		config:   cfg,
-		handlers: make(map[string]ToolHandler),
-		backends: make(map[string]*mcpBackend),
-		stopCh:   make(chan struct{}),
+		index:        idx,
+		searcher:     searcher,
+		config:       cfg,
+		handlers:     make(map[string]ToolHandler),
+		backends:     make(map[string]*mcpBackend),
+		transformers: make(map[string]ResultTransformer),
+		timeouts:     make(map[string]time.Duration),
+		binaryDecode: make(map[string]BinaryDecodeOptions),
+		calls:        calls,
+		validator:    model.NewDefaultValidator(),
+		telemetry:    newTelemetry(cfg.TracerProvider, cfg.MeterProvider),
+		stopCh:       make(chan struct{}),
 	}
 }
 
@@ -89,8 +175,17 @@ func (r *Registry) RegisterLocalFunc(
 	opts ...LocalToolOption,
 ) error {
 	cfg := applyLocalToolOptions(opts)
-	tool := buildLocalTool(name, description, inputSchema, cfg)
-	return r.RegisterLocal(tool, handler)
+	tool := buildLocalTool(r.index, name, description, inputSchema, cfg)
+	if err := r.RegisterLocal(tool, handler); err != nil {
+		return err
+	}
+	if cfg.transformer != nil {
+		r.SetResultTransformer(tool.ToolID(), cfg.transformer)
+	}
+	if cfg.timeout > 0 {
+		r.SetCallTimeout(tool.ToolID(), cfg.timeout)
+	}
+	return nil
 }
 
 // Search performs a BM25 search and returns ranked tools.
@@ -134,6 +229,26 @@ func (r *Registry) ListAll(ctx context.Context) ([]model.Tool, error) {
 	return tools, nil
 }
 
+// ListPage returns up to limit registered tools starting at cursor, and the
+// cursor for the following page ("" on the last page). Cursors are
+// invalidated by index changes and fail with index.ErrInvalidCursor.
+func (r *Registry) ListPage(_ context.Context, limit int, cursor string) ([]model.Tool, string, error) {
+	summaries, next, err := r.index.SearchPage("", limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tools := make([]model.Tool, 0, len(summaries))
+	for _, summary := range summaries {
+		tool, _, err := r.index.GetTool(summary.ID)
+		if err != nil {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools, next, nil
+}
+
 // ListNamespaces returns all tool namespaces.
 func (r *Registry) ListNamespaces(ctx context.Context) ([]string, error) {
 	return r.index.ListNamespaces()
@@ -149,12 +264,67 @@ func (r *Registry) GetTool(ctx context.Context, id string) (model.Tool, error) {
 }
 
 // Execute runs a tool by name with the given arguments.
-func (r *Registry) Execute(ctx context.Context, name string, args map[string]any) (any, error) {
+// Configured result transformers are applied to successful results, and
+// results above LargeResultThreshold are returned as a ResultReference.
+func (r *Registry) Execute(ctx context.Context, name string, args map[string]any) (result any, err error) {
+	ctx, span := r.telemetry.tracer.Start(ctx, "registry.Execute", trace.WithAttributes(AttrToolID.String(name)))
+	defer func() { endSpan(span, err) }()
+
+	tool, result, err := r.execute(ctx, name, args, false)
+	if err != nil {
+		return nil, err
+	}
+	result, err = r.applyResultTransformers(ctx, tool.ToolID(), result)
+	if err != nil {
+		return nil, fmt.Errorf("%w: transform result: %v", ErrExecutionFailed, err)
+	}
+	result, err = r.spillBinaryResult(ctx, result)
+	if err != nil {
+		return nil, fmt.Errorf("%w: spill binary content: %v", ErrExecutionFailed, err)
+	}
+	result, err = r.spillLargeResult(ctx, result)
+	if err != nil {
+		return nil, fmt.Errorf("%w: spill result: %v", ErrExecutionFailed, err)
+	}
+	return result, nil
+}
+
+// execute runs a tool without applying result transformers or spilling.
+// With detailed set, MCP backends return their *mcp.CallToolResult
+// unflattened.
+func (r *Registry) execute(ctx context.Context, name string, args map[string]any, detailed bool) (model.Tool, any, error) {
 	tool, backend, err := r.index.GetTool(name)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+		return model.Tool{}, nil, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+	r.recordBackendDecision(tool.ToolID())
+	if err := r.checkMaintenance(ctx, tool.ToolID()); err != nil {
+		return tool, nil, err
+	}
+	if r.config.ValidateArgs {
+		if err := r.validateArgs(tool, args); err != nil {
+			return tool, nil, err
+		}
+	}
+
+	handler, err := r.dispatchHandler(tool, backend, detailed)
+	if err != nil {
+		return tool, nil, err
+	}
+	trace.SpanFromContext(ctx).SetAttributes(backendAttributes(backend)...)
+	handler = r.telemetry.instrumentHandler(handler, backend)
+	release, err := r.acquireCall()
+	if err != nil {
+		return tool, nil, err
 	}
+	defer release()
+	ctx = context.WithValue(ctx, callInfoKey{}, CallInfo{ToolID: tool.ToolID(), Tool: tool, Backend: backend})
+	result, err := r.runWithTimeout(ctx, tool.ToolID(), r.wrapMiddleware(handler), args)
+	return tool, result, err
+}
 
+// dispatchHandler returns the handler that runs tool on backend.
+func (r *Registry) dispatchHandler(tool model.Tool, backend model.ToolBackend, detailed bool) (ToolHandler, error) {
 	switch backend.Kind {
 	case model.BackendKindLocal:
 		r.mu.RLock()
@@ -163,7 +333,7 @@ func (r *Registry) Execute(ctx context.Context, name string, args map[string]any
 		if !ok {
 			return nil, fmt.Errorf("%w: %s", ErrHandlerNotFound, tool.ToolID())
 		}
-		return handler(ctx, args)
+		return handler, nil
 
 	case model.BackendKindMCP:
 		if backend.MCP == nil {
@@ -171,11 +341,17 @@ func (r *Registry) Execute(ctx context.Context, name string, args map[string]any
 		}
 		r.mu.RLock()
 		mcpBackend, ok := r.backends[backend.MCP.ServerName]
+		decode := r.binaryDecode[tool.ToolID()]
 		r.mu.RUnlock()
 		if !ok {
 			return nil, fmt.Errorf("%w: %s", ErrBackendNotFound, backend.MCP.ServerName)
 		}
-		return mcpBackend.callTool(ctx, tool.Name, args)
+		return func(ctx context.Context, args map[string]any) (any, error) {
+			if detailed {
+				return mcpBackend.callToolResult(ctx, tool.Name, args)
+			}
+			return mcpBackend.callTool(ctx, tool.Name, args, decode)
+		}, nil
 
 	default:
 		return nil, fmt.Errorf("%w: backend kind %s not supported", ErrInvalidRequest, backend.Kind)
@@ -191,6 +367,7 @@ func (r *Registry) Start(ctx context.Context) error {
 	}
 	r.started = true
 	r.stopCh = make(chan struct{})
+	stop := r.stopCh
 	backends := make(map[string]*mcpBackend, len(r.backends))
 	for name, backend := range r.backends {
 		backends[name] = backend
@@ -209,7 +386,7 @@ func (r *Registry) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to connect backend %s: %w", name, err)
 		}
 		connected = append(connected, name)
-		if err := r.index.RegisterToolsFromMCP(name, backend.toolsSnapshot()); err != nil {
+		if err := r.registerBackendTools(ctx, name, backend); err != nil {
 			for _, connectedName := range connected {
 				_ = backends[connectedName].disconnect()
 			}
@@ -220,6 +397,17 @@ func (r *Registry) Start(ctx context.Context) error {
 		}
 	}
 
+	// Rebuild the index's cached search docs before reporting ready; the
+	// searcher indexes them on the first query.
+	index.Refresh(r.index)
+	r.mu.Lock()
+	r.warm = r.started
+	r.mu.Unlock()
+
+	for name, backend := range backends {
+		r.superviseBackend(stop, name, backend)
+	}
+
 	return nil
 }
 
@@ -231,6 +419,7 @@ func (r *Registry) Stop() error {
 		return nil
 	}
 	r.started = false
+	r.warm = false
 	close(r.stopCh)
 	backends := make(map[string]*mcpBackend, len(r.backends))
 	for name, backend := range r.backends {
@@ -238,6 +427,9 @@ func (r *Registry) Stop() error {
 	}
 	r.mu.Unlock()
 
+	for _, backend := range backends {
+		backend.waitLoop()
+	}
 	for name, backend := range backends {
 		if err := backend.disconnect(); err != nil {
 			return fmt.Errorf("failed to disconnect backend %s: %w", name, err)
@@ -283,7 +475,7 @@ func (r *Registry) Stats() RegistryStats {
 		LocalTools:   localCount,
 		MCPTools:     mcpCount,
 		Backends:     len(r.backends),
-		IndexVersion: r.index.Version(),
+		IndexVersion: index.Version(r.index),
 	}
 }
 
@@ -305,7 +497,7 @@ func (r *Registry) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// Refresh triggers a refresh of search indexes.
+// Refresh triggers a refresh of search indexes and returns the index version.
 func (r *Registry) Refresh() uint64 {
-	return r.index.Refresh()
+	return index.Refresh(r.index)
 }
@@ -5,13 +5,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/tooldiscovery/search"
 	"github.com/jonwraymond/toolfoundation/model"
 )
@@ -183,6 +186,57 @@ func TestHandleRequest_ToolsList(t *testing.T) {
 	}
 }
 
+func TestHandleRequest_ToolsListPagination(t *testing.T) {
+	reg := New(Config{
+		ServerInfo:        ServerInfo{Name: "test", Version: "1.0.0"},
+		ToolsListPageSize: 2,
+	})
+	handler := func(ctx context.Context, args map[string]any) (any, error) { return nil, nil }
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		_ = reg.RegisterLocalFunc(name, "Tool "+name, map[string]any{"type": "object"}, handler)
+	}
+
+	list := func(cursor string) MCPResponse {
+		params, _ := json.Marshal(map[string]any{"cursor": cursor})
+		return reg.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list", Params: params})
+	}
+
+	var names []string
+	cursor := ""
+	for page := 0; ; page++ {
+		resp := list(cursor)
+		if resp.Error != nil {
+			t.Fatalf("page %d error: %v", page, resp.Error)
+		}
+		result := resp.Result.(map[string]any)
+		tools := result["tools"].([]map[string]any)
+		if len(tools) > 2 {
+			t.Fatalf("page %d has %d tools, want at most 2", page, len(tools))
+		}
+		for _, tool := range tools {
+			names = append(names, tool["name"].(string))
+		}
+		next, _ := result["nextCursor"].(string)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if strings.Join(names, ",") != "alpha,beta,gamma" {
+		t.Errorf("paged names = %v", names)
+	}
+
+	if resp := list("not-a-cursor"); resp.Error == nil || resp.Error.Code != ErrCodeInvalidParams {
+		t.Errorf("invalid cursor error = %+v, want invalid params", resp.Error)
+	}
+
+	unpaged := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	params, _ := json.Marshal(map[string]any{"cursor": cursor})
+	if resp := unpaged.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list", Params: params}); resp.Error == nil || resp.Error.Code != ErrCodeInvalidParams {
+		t.Errorf("cursor without pagination error = %+v, want invalid params", resp.Error)
+	}
+}
+
 func TestHandleRequest_ToolsCall(t *testing.T) {
 	reg := New(Config{
 		ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"},
@@ -465,6 +519,30 @@ func TestWithVersion(t *testing.T) {
 	}
 }
 
+func TestWithTags_IndexTagPolicy(t *testing.T) {
+	reg := New(Config{
+		ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"},
+		Index:      index.NewInMemoryIndex(index.IndexOptions{TagPolicy: index.TagPolicy{PreserveCase: true}}),
+	})
+
+	handler := func(ctx context.Context, args map[string]any) (any, error) {
+		return nil, nil
+	}
+
+	err := reg.RegisterLocalFunc("deploy", "Deploy", map[string]any{"type": "object"}, handler, WithTags("AWS", "K8s"))
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+
+	tool, err := reg.GetTool(context.Background(), "deploy")
+	if err != nil {
+		t.Fatalf("GetTool failed: %v", err)
+	}
+	if want := []string{"AWS", "K8s"}; !slices.Equal(tool.Tags, want) {
+		t.Errorf("Tags = %v, want %v", tool.Tags, want)
+	}
+}
+
 func TestGetTool(t *testing.T) {
 	reg := New(Config{
 		ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"},
@@ -744,3 +822,94 @@ func TestServeHTTP_InvalidJSON(t *testing.T) {
 		t.Errorf("expected ErrCodeParseError, got %d", mcpResp.Error.Code)
 	}
 }
+
+func TestResyncBackends(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	noop := func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "old"}, noop)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{})
+	if err := reg.ResyncBackends(ctx); !errors.Is(err, ErrNotStarted) {
+		t.Fatalf("expected ErrNotStarted, got %v", err)
+	}
+	_ = reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport})
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	mcp.AddTool(server, &mcp.Tool{Name: "new"}, noop)
+	server.RemoveTools("old")
+
+	if err := reg.ResyncBackends(ctx); err != nil {
+		t.Fatalf("ResyncBackends failed: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "new"); err != nil {
+		t.Errorf("expected new tool after resync: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "old"); err == nil {
+		t.Error("expected removed tool to be unregistered")
+	}
+}
+
+// serverTransport connects each client to a new session of server, so a
+// backend using it can reconnect.
+type serverTransport struct {
+	server   *mcp.Server
+	sessions []*mcp.ServerSession
+}
+
+func (s *serverTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	serverSide, clientSide := mcp.NewInMemoryTransports()
+	session, err := s.server.Connect(ctx, serverSide, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sessions = append(s.sessions, session)
+	return clientSide.Connect(ctx)
+}
+
+func TestResyncBackends_Reconnect(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	noop := func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "old"}, noop)
+	transport := &serverTransport{server: server}
+
+	ctx := context.Background()
+	// Hide index.MCPReplacer so the registry reconciles removals itself.
+	reg := New(Config{Index: struct{ index.Index }{index.NewInMemoryIndex()}})
+	_ = reg.RegisterMCP(BackendConfig{Name: "remote", Transport: transport})
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	// Drop the session, then change the tools while disconnected.
+	backend := reg.backends["remote"]
+	_ = transport.sessions[0].Close()
+	<-backend.done
+	mcp.AddTool(server, &mcp.Tool{Name: "new"}, noop)
+	server.RemoveTools("old")
+
+	if err := reg.ResyncBackends(ctx); err != nil {
+		t.Fatalf("ResyncBackends failed: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "new"); err != nil {
+		t.Errorf("expected new tool after reconnect: %v", err)
+	}
+	if _, err := reg.GetTool(ctx, "old"); err == nil {
+		t.Error("expected tool removed while disconnected to be unregistered")
+	}
+}
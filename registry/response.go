@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"encoding/json"
+	"maps"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// fitToolsList trims a tools/list result until its JSON encoding is at most
+// maxBytes. Truncation tiers are tried in order, stopping at the first that
+// fits: descriptions shortened to index.MaxShortDescriptionLen, descriptions
+// removed, then trailing tools dropped. Input schemas are never trimmed since
+// clients need them to call the tool. Reports whether anything was trimmed.
+func fitToolsList(tools []map[string]any, maxBytes int) ([]map[string]any, bool) {
+	if maxBytes <= 0 || toolsListSize(tools, false) <= maxBytes {
+		return tools, false
+	}
+
+	for _, maxDesc := range []int{index.MaxShortDescriptionLen, 0} {
+		trimmed := make([]map[string]any, len(tools))
+		for i, tool := range tools {
+			t := maps.Clone(tool)
+			if desc, ok := t["description"].(string); ok {
+				t["description"] = truncateText(desc, maxDesc)
+			}
+			trimmed[i] = t
+		}
+		tools = trimmed
+		if toolsListSize(tools, true) <= maxBytes {
+			return tools, true
+		}
+	}
+
+	// Drop trailing tools. Each element adds its encoding plus a separator.
+	size := toolsListSize(nil, true)
+	for i, tool := range tools {
+		encoded, _ := json.Marshal(tool)
+		size += len(encoded)
+		if i > 0 {
+			size++
+		}
+		if size > maxBytes {
+			return tools[:i], true
+		}
+	}
+	return tools, true
+}
+
+// toolsListSize returns the encoded size of a tools/list result, optionally
+// including the truncation flag so a trimmed result still fits.
+func toolsListSize(tools []map[string]any, truncated bool) int {
+	if tools == nil {
+		tools = []map[string]any{}
+	}
+	result := map[string]any{"tools": tools}
+	if truncated {
+		result["truncated"] = true
+	}
+	encoded, _ := json.Marshal(result)
+	return len(encoded)
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func listToolsWithLimit(t *testing.T, maxBytes, count int, descLen int) (map[string]any, int) {
+	t.Helper()
+	reg := New(Config{MaxResponseBytes: maxBytes})
+	for i := range count {
+		name := fmt.Sprintf("tool%02d", i)
+		err := reg.RegisterLocalFunc(name, strings.Repeat("d", descLen), map[string]any{"type": "object"}, func(ctx context.Context, args map[string]any) (any, error) {
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("RegisterLocalFunc failed: %v", err)
+		}
+	}
+	resp := reg.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+	if resp.Error != nil {
+		t.Fatalf("tools/list error: %v", resp.Error)
+	}
+	encoded, err := json.Marshal(resp.Result)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	return resp.Result.(map[string]any), len(encoded)
+}
+
+func TestToolsList_MaxResponseBytes(t *testing.T) {
+	_, fullSize := listToolsWithLimit(t, 0, 10, 500)
+
+	tests := []struct {
+		name      string
+		maxBytes  int
+		wantTools int
+		wantDesc  int
+	}{
+		{name: "fits", maxBytes: fullSize, wantTools: 10, wantDesc: 500},
+		{name: "shortened descriptions", maxBytes: fullSize / 2, wantTools: 10, wantDesc: 120},
+		{name: "no descriptions", maxBytes: 900, wantTools: 10, wantDesc: 0},
+		{name: "dropped tools", maxBytes: 400, wantDesc: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, size := listToolsWithLimit(t, tt.maxBytes, 10, 500)
+			if size > tt.maxBytes {
+				t.Fatalf("result size %d exceeds limit %d", size, tt.maxBytes)
+			}
+			tools := result["tools"].([]map[string]any)
+			if tt.wantTools > 0 && len(tools) != tt.wantTools {
+				t.Fatalf("got %d tools, want %d", len(tools), tt.wantTools)
+			}
+			if tt.wantTools == 0 && (len(tools) == 0 || len(tools) >= 10) {
+				t.Fatalf("got %d tools, want some dropped", len(tools))
+			}
+			if got := len(tools[0]["description"].(string)); got != tt.wantDesc {
+				t.Errorf("description length = %d, want %d", got, tt.wantDesc)
+			}
+			if _, truncated := result["truncated"]; truncated != (tt.maxBytes < fullSize) {
+				t.Errorf("truncated flag = %v, want %v", truncated, tt.maxBytes < fullSize)
+			}
+			if tools[0]["inputSchema"] == nil {
+				t.Error("input schema must never be trimmed")
+			}
+		})
+	}
+}
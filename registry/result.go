@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExecuteDetailed executes a tool like Execute but returns the MCP result
+// without flattening it: content blocks (text, images, audio, resource
+// links, and embedded resources), structured content, the isError flag,
+// and meta. Hosts relaying results to their own clients should prefer it
+// over Execute.
+//
+// A tool that reports failure through isError returns its result with
+// IsError set and a nil error, so the tool's error content reaches the
+// caller; errors are reserved for lookup, validation, transport, and
+// timeout failures. Local handler results are converted with ToolResult.
+//
+// Middleware sees the *mcp.CallToolResult for calls to MCP backends.
+// Result transformers and spilling are not applied.
+func (r *Registry) ExecuteDetailed(ctx context.Context, name string, args map[string]any) (result *mcp.CallToolResult, err error) {
+	ctx, span := r.telemetry.tracer.Start(ctx, "registry.ExecuteDetailed", trace.WithAttributes(AttrToolID.String(name)))
+	defer func() { endSpan(span, err) }()
+
+	_, value, err := r.execute(ctx, name, args, true)
+	if err != nil {
+		return nil, err
+	}
+	result, err = ToolResult(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionFailed, err)
+	}
+	return result, nil
+}
+
+// ToolResult converts a handler result to an MCP tool result:
+//   - a *mcp.CallToolResult is returned as is,
+//   - a string becomes a text block,
+//   - mcp.Content and []mcp.Content become the result's content,
+//   - BinaryContent becomes an image, audio, or embedded resource block, or
+//     a resource link when its data was spilled,
+//   - any other value is encoded as a JSON text block and, when it encodes
+//     to a JSON object, also set as StructuredContent.
+//
+// A nil value yields a result with no content.
+func ToolResult(value any) (*mcp.CallToolResult, error) {
+	switch v := value.(type) {
+	case nil:
+		return &mcp.CallToolResult{Content: []mcp.Content{}}, nil
+	case *mcp.CallToolResult:
+		if v == nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{}}, nil
+		}
+		if v.Content == nil {
+			v.Content = []mcp.Content{}
+		}
+		return v, nil
+	case string:
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: v}}}, nil
+	case mcp.Content:
+		return &mcp.CallToolResult{Content: []mcp.Content{v}}, nil
+	case []mcp.Content:
+		return &mcp.CallToolResult{Content: v}, nil
+	case BinaryContent:
+		return &mcp.CallToolResult{Content: []mcp.Content{binaryToContent(v)}}, nil
+	case *BinaryContent:
+		if v == nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{}}, nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{binaryToContent(*v)}}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encode result: %w", err)
+	}
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}
+	if len(data) > 0 && data[0] == '{' {
+		result.StructuredContent = value
+	}
+	return result, nil
+}
+
+// binaryToContent converts BinaryContent back to an MCP content block.
+func binaryToContent(b BinaryContent) mcp.Content {
+	if len(b.Data) == 0 && b.URI != "" {
+		return &mcp.ResourceLink{URI: b.URI, Name: b.URI, MIMEType: b.MIMEType}
+	}
+	switch b.Kind {
+	case BinaryKindImage:
+		return &mcp.ImageContent{Data: b.Data, MIMEType: b.MIMEType}
+	case BinaryKindAudio:
+		return &mcp.AudioContent{Data: b.Data, MIMEType: b.MIMEType}
+	default:
+		return &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: b.URI, MIMEType: b.MIMEType, Blob: b.Data}}
+	}
+}
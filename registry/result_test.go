@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestExecuteDetailed_MCP(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "backend-server"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "snapshot", Description: "Take a snapshot"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Meta: mcp.Meta{"trace": "abc"},
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "captured"},
+					&mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+					&mcp.ResourceLink{URI: "file:///snap.png", Name: "snap.png"},
+				},
+			}, nil, nil
+		})
+	mcp.AddTool(server, &mcp.Tool{Name: "fail", Description: "Always fails"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "disk full"}},
+			}, nil, nil
+		})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer func() { _ = serverSession.Close() }()
+
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{Name: "remote", Transport: clientTransport}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	result, err := reg.ExecuteDetailed(ctx, "snapshot", nil)
+	if err != nil {
+		t.Fatalf("ExecuteDetailed failed: %v", err)
+	}
+	if result.IsError || result.Meta["trace"] != "abc" {
+		t.Errorf("result = %+v, want meta trace=abc and no error", result)
+	}
+	if len(result.Content) != 3 {
+		t.Fatalf("len(Content) = %d, want 3", len(result.Content))
+	}
+	if _, ok := result.Content[0].(*mcp.TextContent); !ok {
+		t.Errorf("Content[0] = %T, want *mcp.TextContent", result.Content[0])
+	}
+	if img, ok := result.Content[1].(*mcp.ImageContent); !ok || img.MIMEType != "image/png" {
+		t.Errorf("Content[1] = %#v, want image/png", result.Content[1])
+	}
+	if link, ok := result.Content[2].(*mcp.ResourceLink); !ok || link.URI != "file:///snap.png" {
+		t.Errorf("Content[2] = %#v, want resource link", result.Content[2])
+	}
+
+	result, err = reg.ExecuteDetailed(ctx, "fail", nil)
+	if err != nil {
+		t.Fatalf("ExecuteDetailed(fail) error = %v, want IsError result", err)
+	}
+	if !result.IsError || len(result.Content) != 1 {
+		t.Errorf("result = %+v, want IsError with content", result)
+	}
+
+	if _, err := reg.Execute(ctx, "fail", nil); err == nil {
+		t.Error("Execute(fail) should still return an error")
+	}
+
+	// A nested Execute from a handler run by ExecuteDetailed is flattened.
+	var nested any
+	_ = reg.RegisterLocalFunc("wrap", "Wrap snapshot", map[string]any{"type": "object"},
+		func(ctx context.Context, _ map[string]any) (any, error) {
+			var err error
+			nested, err = reg.Execute(ctx, "snapshot", nil)
+			return "ok", err
+		})
+	if _, err := reg.ExecuteDetailed(ctx, "wrap", nil); err != nil {
+		t.Fatalf("ExecuteDetailed(wrap) failed: %v", err)
+	}
+	if _, ok := nested.(*mcp.CallToolResult); ok || nested == nil {
+		t.Errorf("nested Execute result = %#v, want flattened result", nested)
+	}
+}
+
+func TestExecuteDetailed_Local(t *testing.T) {
+	reg := New(Config{})
+	_ = reg.RegisterLocalFunc("status", "Status", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) {
+			return map[string]any{"ok": true}, nil
+		})
+
+	result, err := reg.ExecuteDetailed(context.Background(), "status", nil)
+	if err != nil {
+		t.Fatalf("ExecuteDetailed failed: %v", err)
+	}
+	if result.StructuredContent == nil {
+		t.Error("StructuredContent should be set for object results")
+	}
+	if text, ok := result.Content[0].(*mcp.TextContent); !ok || text.Text != `{"ok":true}` {
+		t.Errorf("Content[0] = %#v, want JSON text", result.Content[0])
+	}
+
+	if _, err := reg.ExecuteDetailed(context.Background(), "missing", nil); err == nil {
+		t.Error("ExecuteDetailed(missing) should fail")
+	}
+}
+
+func TestToolResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		want       string
+		structured bool
+	}{
+		{"nil", nil, "", false},
+		{"nil call result", (*mcp.CallToolResult)(nil), "", false},
+		{"string", "hello", "*mcp.TextContent", false},
+		{"number", 42, "*mcp.TextContent", false},
+		{"object", map[string]any{"a": 1}, "*mcp.TextContent", true},
+		{"image", BinaryContent{Kind: BinaryKindImage, Data: []byte("x")}, "*mcp.ImageContent", false},
+		{"audio", BinaryContent{Kind: BinaryKindAudio, Data: []byte("x")}, "*mcp.AudioContent", false},
+		{"blob", BinaryContent{Kind: BinaryKindBlob, URI: "file:///a", Data: []byte("x")}, "*mcp.EmbeddedResource", false},
+		{"link", BinaryContent{Kind: BinaryKindBlob, URI: "file:///a"}, "*mcp.ResourceLink", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToolResult(tt.value)
+			if err != nil {
+				t.Fatalf("ToolResult failed: %v", err)
+			}
+			if tt.want == "" {
+				if len(result.Content) != 0 {
+					t.Errorf("Content = %v, want empty", result.Content)
+				}
+				return
+			}
+			if len(result.Content) != 1 {
+				t.Fatalf("len(Content) = %d, want 1", len(result.Content))
+			}
+			if got := fmt.Sprintf("%T", result.Content[0]); got != tt.want {
+				t.Errorf("Content[0] = %s, want %s", got, tt.want)
+			}
+			if (result.StructuredContent != nil) != tt.structured {
+				t.Errorf("StructuredContent = %v, want set=%v", result.StructuredContent, tt.structured)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Default values for ServeOptions.
+const (
+	DefaultHTTPPath        = "/mcp"
+	DefaultShutdownTimeout = 5 * time.Second
+)
+
+// ServeOptions configures Serve.
+type ServeOptions struct {
+	// Stdio serves MCP over stdin/stdout.
+	Stdio bool
+	// Stdin and Stdout override the stdio streams (useful for tests).
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	// HTTPAddr is the listen address for the HTTP server (e.g. ":8080").
+	// The HTTP server is started when HTTPAddr, HTTPListener, or UnixSocket is set.
+	HTTPAddr string
+	// HTTPListener overrides HTTPAddr when provided (useful for tests).
+	HTTPListener net.Listener
+	// UnixSocket serves the HTTP handlers on a Unix domain socket at this path,
+	// alongside or instead of TCP. The socket file is removed on shutdown.
+	UnixSocket string
+	// HTTPPath is the streamable HTTP endpoint. Default: DefaultHTTPPath.
+	HTTPPath string
+	// SSEPath mounts the SSE transport on the HTTP server when set.
+	SSEPath string
+	// HealthzPath and ReadyzPath mount the liveness and readiness probes.
+	// Defaults: DefaultHealthzPath and DefaultReadyzPath.
+	HealthzPath string
+	ReadyzPath  string
+	// DisableHealth skips mounting the health endpoints.
+	DisableHealth bool
+	// TLS serves HTTP and SSE over TLS on the TCP listener. Set a CA bundle
+	// and RequireClientCert for mTLS. The Unix socket is served in plaintext.
+	TLS *TLSConfig
+
+	// APIKeys requires an API key (see RequireAPIKey) on the HTTP and SSE
+	// endpoints and mounts ServeAPIKeys at APIKeysPath. Health endpoints
+	// stay open for probes.
+	APIKeys *APIKeyStore
+	// APIKeysPath is the API key admin endpoint. Default: DefaultAPIKeysPath.
+	APIKeysPath string
+
+	// UI mounts the catalog browser (see ServeUI) at UIPath when set. It is
+	// protected by APIKeys like the MCP endpoints.
+	UI *UIOptions
+	// UIPath is the catalog browser prefix. Default: DefaultUIPath.
+	UIPath string
+
+	// ShutdownTimeout bounds graceful HTTP shutdown. Default: DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// Signals trigger graceful shutdown. Default: SIGINT and SIGTERM.
+	Signals []os.Signal
+	// DisableSignals turns off signal handling entirely.
+	DisableSignals bool
+}
+
+// Serve runs the requested transports with a shared lifecycle.
+//
+// It starts the registry (if not already started), serves every configured
+// transport, and blocks until the context is cancelled, a shutdown signal is
+// received, or a transport exits. All transports are then shut down, and the
+// registry is stopped if Serve started it. Serve returns nil on graceful
+// shutdown and the first transport error otherwise.
+//
+// A blocked stdio read cannot be interrupted; on shutdown Serve does not wait
+// for the stdio loop to observe end of input.
+func Serve(ctx context.Context, r *Registry, opts ServeOptions) error {
+	httpEnabled := opts.HTTPAddr != "" || opts.HTTPListener != nil || opts.UnixSocket != ""
+	if !opts.Stdio && !httpEnabled {
+		return fmt.Errorf("%w: no transports configured", ErrInvalidRequest)
+	}
+
+	if !opts.DisableSignals {
+		signals := opts.Signals
+		if len(signals) == 0 {
+			signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+		}
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, signals...)
+		defer stop()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Stop the registry on return only if this call started it; a caller
+	// that started it keeps managing its lifecycle.
+	switch err := r.Start(ctx); {
+	case err == nil:
+		defer func() { _ = r.Stop() }()
+	case !errors.Is(err, ErrAlreadyStarted):
+		return err
+	}
+
+	listeners, err := serveListeners(opts)
+	if err != nil {
+		return err
+	}
+	errCh := make(chan error, len(listeners)+1)
+
+	var server *http.Server
+	if len(listeners) > 0 {
+		server = &http.Server{
+			Handler:           newServeMux(r, opts),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		// Notification streams never finish on their own; end them so
+		// Shutdown can drain the remaining requests.
+		server.RegisterOnShutdown(r.closeStreams)
+		for _, listener := range listeners {
+			go func(l net.Listener) {
+				err := server.Serve(l)
+				if errors.Is(err, http.ErrServerClosed) {
+					err = nil
+				}
+				errCh <- err
+			}(listener)
+		}
+	}
+
+	if opts.Stdio {
+		in := opts.Stdin
+		if in == nil {
+			in = os.Stdin
+		}
+		out := opts.Stdout
+		if out == nil {
+			out = os.Stdout
+		}
+		go func() {
+			errCh <- serveStdio(ctx, r, in, out)
+		}()
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-errCh:
+		if errors.Is(serveErr, context.Canceled) {
+			serveErr = nil
+		}
+	}
+	cancel()
+
+	if server != nil {
+		timeout := opts.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = DefaultShutdownTimeout
+		}
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), timeout)
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			_ = server.Close()
+			if serveErr == nil {
+				serveErr = fmt.Errorf("http shutdown: %w", err)
+			}
+		}
+	}
+
+	return serveErr
+}
+
+// serveListeners opens the TCP and Unix socket listeners requested by opts.
+func serveListeners(opts ServeOptions) ([]net.Listener, error) {
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}
+
+	tcp := opts.HTTPListener
+	if tcp == nil && opts.HTTPAddr != "" {
+		var err error
+		tcp, err = net.Listen("tcp", opts.HTTPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("listen %s: %w", opts.HTTPAddr, err)
+		}
+	}
+	if tcp != nil {
+		if opts.TLS != nil {
+			tlsCfg, err := opts.TLS.ServerConfig()
+			if err != nil {
+				_ = tcp.Close()
+				return nil, err
+			}
+			tcp = tls.NewListener(tcp, tlsCfg)
+		}
+		listeners = append(listeners, tcp)
+	}
+
+	if opts.UnixSocket != "" {
+		unix, err := net.Listen("unix", opts.UnixSocket)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("listen unix %s: %w", opts.UnixSocket, err)
+		}
+		listeners = append(listeners, unix)
+	}
+
+	return listeners, nil
+}
+
+func newServeMux(r *Registry, opts ServeOptions) *http.ServeMux {
+	path := opts.HTTPPath
+	if path == "" {
+		path = DefaultHTTPPath
+	}
+	protect := func(h http.Handler) http.Handler { return h }
+	if opts.APIKeys != nil {
+		protect = func(h http.Handler) http.Handler { return RequireAPIKey(opts.APIKeys, h) }
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, protect(ServeHTTP(r)))
+	if opts.SSEPath != "" {
+		mux.Handle(opts.SSEPath, protect(ServeSSE(r)))
+	}
+	if opts.APIKeys != nil {
+		keysPath := opts.APIKeysPath
+		if keysPath == "" {
+			keysPath = DefaultAPIKeysPath
+		}
+		mux.Handle(keysPath, protect(ServeAPIKeys(opts.APIKeys)))
+	}
+	if opts.UI != nil {
+		uiPath := strings.TrimSuffix(opts.UIPath, "/")
+		if uiPath == "" {
+			uiPath = DefaultUIPath
+		}
+		mux.Handle(uiPath+"/", http.StripPrefix(uiPath, protect(ServeUI(r, *opts.UI))))
+	}
+	if !opts.DisableHealth {
+		healthz, readyz := opts.HealthzPath, opts.ReadyzPath
+		if healthz == "" {
+			healthz = DefaultHealthzPath
+		}
+		if readyz == "" {
+			readyz = DefaultReadyzPath
+		}
+		mux.Handle(healthz, ServeLiveness(r))
+		mux.Handle(readyz, ServeReadiness(r))
+	}
+	return mux
+}
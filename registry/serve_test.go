@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe_NoTransports(t *testing.T) {
+	reg := New(Config{})
+	err := Serve(context.Background(), reg, ServeOptions{DisableSignals: true})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestServe_StdioExitsOnEOF(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	var out bytes.Buffer
+
+	err := Serve(context.Background(), reg, ServeOptions{
+		Stdio:          true,
+		Stdin:          strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}` + "\n"),
+		Stdout:         &out,
+		DisableSignals: true,
+	})
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var resp MCPResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %v", resp.Error)
+	}
+}
+
+func TestServe_StopsOnlyRegistryItStarted(t *testing.T) {
+	serve := func(reg *Registry) {
+		t.Helper()
+		err := Serve(context.Background(), reg, ServeOptions{
+			Stdio:          true,
+			Stdin:          strings.NewReader(""),
+			Stdout:         io.Discard,
+			DisableSignals: true,
+		})
+		if err != nil {
+			t.Fatalf("Serve failed: %v", err)
+		}
+	}
+
+	started := New(Config{})
+	if err := started.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = started.Stop() }()
+	serve(started)
+	if !started.Health().Started {
+		t.Error("Serve stopped a registry started by the caller")
+	}
+
+	fresh := New(Config{})
+	serve(fresh)
+	if fresh.Health().Started {
+		t.Error("Serve left the registry it started running")
+	}
+}
+
+func TestServe_HTTPAndSSEShareLifecycle(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, reg, ServeOptions{
+			HTTPListener:   listener,
+			SSEPath:        "/sse",
+			DisableSignals: true,
+		})
+	}()
+
+	base := "http://" + listener.Addr().String()
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	resp := postWithRetry(t, base+DefaultHTTPPath, body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("HTTP status = %d", resp.StatusCode)
+	}
+	_ = resp.Body.Close()
+
+	resp = postWithRetry(t, base+"/sse", body)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("SSE content type = %q", ct)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if err := reg.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("registry should be started while serving: %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve returned error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve did not shut down")
+	}
+
+	if err := reg.HealthCheck(context.Background()); !errors.Is(err, ErrNotStarted) {
+		t.Fatalf("registry should be stopped after Serve, got %v", err)
+	}
+}
+
+func postWithRetry(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	var lastErr error
+	for range 50 {
+		resp, err := client.Post(url, "application/json", strings.NewReader(body))
+		if err == nil {
+			return resp
+		}
+		lastErr = err
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("POST %s failed: %v", url, lastErr)
+	return nil
+}
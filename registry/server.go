@@ -5,15 +5,52 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"sync"
 )
 
 // ServeStdio runs the registry as an MCP server over stdio.
 // Blocks until stdin is closed or context is cancelled.
+// A notifications/tools/list_changed message is written between responses
+// whenever the tool list changes.
 func ServeStdio(ctx context.Context, r *Registry) error {
-	scanner := bufio.NewScanner(os.Stdin)
-	encoder := json.NewEncoder(os.Stdout)
+	return serveStdio(ctx, r, os.Stdin, os.Stdout)
+}
+
+func serveStdio(ctx context.Context, r *Registry, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	encoder := json.NewEncoder(out)
+
+	// Responses and notifications share out; encode one message at a time.
+	var writeMu sync.Mutex
+	encode := func(v any) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return encoder.Encode(v)
+	}
+
+	changed, unsubscribe := r.listChangedSignal()
+	defer unsubscribe()
+	done := make(chan struct{})
+	var notifier sync.WaitGroup
+	notifier.Add(1)
+	go func() {
+		defer notifier.Done()
+		for {
+			select {
+			case <-changed:
+				_ = encode(toolsListChanged)
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer notifier.Wait()
+	defer close(done)
 
 	for scanner.Scan() {
 		select {
@@ -28,14 +65,14 @@ func ServeStdio(ctx context.Context, r *Registry) error {
 				JSONRPC: "2.0",
 				Error:   &MCPError{Code: ErrCodeParseError, Message: err.Error()},
 			}
-			if err := encoder.Encode(resp); err != nil {
+			if err := encode(resp); err != nil {
 				return fmt.Errorf("failed to encode error response: %w", err)
 			}
 			continue
 		}
 
 		resp := r.HandleRequest(ctx, req)
-		if err := encoder.Encode(resp); err != nil {
+		if err := encode(resp); err != nil {
 			return fmt.Errorf("failed to encode response: %w", err)
 		}
 	}
@@ -74,6 +111,9 @@ func ServeHTTP(r *Registry) http.Handler {
 
 // ServeSSE returns an http.Handler for Server-Sent Events transport.
 // Clients POST to establish connection, receive events via SSE stream.
+// A GET opens a notification stream that receives a
+// notifications/tools/list_changed message whenever the tool list changes,
+// until the client disconnects or Serve shuts down.
 func ServeSSE(r *Registry) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "text/event-stream")
@@ -86,6 +126,11 @@ func ServeSSE(r *Registry) http.Handler {
 			return
 		}
 
+		if req.Method == http.MethodGet {
+			streamNotifications(r, w, req, flusher)
+			return
+		}
+
 		var mcpReq MCPRequest
 		if err := json.NewDecoder(req.Body).Decode(&mcpReq); err != nil {
 			writeSSEEvent(w, flusher, "error", MCPResponse{
@@ -100,6 +145,28 @@ func ServeSSE(r *Registry) http.Handler {
 	})
 }
 
+// streamNotifications writes server notifications to an SSE stream until the
+// request ends or the registry closes its streams.
+func streamNotifications(r *Registry, w http.ResponseWriter, req *http.Request, flusher http.Flusher) {
+	changed, unsubscribe := r.listChangedSignal()
+	defer unsubscribe()
+	done, release := r.openStream()
+	defer release()
+
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-changed:
+			writeSSEEvent(w, flusher, "message", toolsListChanged)
+		case <-req.Context().Done():
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
 func writeSSEEvent(w http.ResponseWriter, f http.Flusher, event string, data any) {
 	jsonData, _ := json.Marshal(data)
 	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, jsonData); err != nil {
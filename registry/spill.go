@@ -0,0 +1,343 @@
+package registry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+// Content types recorded for spilled results.
+const (
+	ContentTypeText = "text/plain; charset=utf-8"
+	ContentTypeJSON = "application/json"
+)
+
+// ResultReference is returned in place of a tool result whose serialized
+// size exceeds Config.LargeResultThreshold. The full payload can be
+// retrieved with Registry.FetchResult using Handle.
+type ResultReference struct {
+	Handle      string `json:"handle"`
+	Size        int    `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
+// ResultStore stores spilled tool results.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Errors: Get and Delete return ErrResultNotFound for unknown handles.
+//   - Ownership: returned byte slices are caller-owned.
+type ResultStore interface {
+	Put(ctx context.Context, data []byte, contentType string) (handle string, err error)
+	Get(ctx context.Context, handle string) (data []byte, contentType string, err error)
+	Delete(ctx context.Context, handle string) error
+}
+
+// HandleFunc generates result store handles. Handles must be unique and,
+// for FileResultStore, valid file names.
+type HandleFunc func() (string, error)
+
+// ResultStoreOptions configures result stores.
+type ResultStoreOptions struct {
+	// NewHandle generates handles for stored results.
+	// Default: 32 random hex characters.
+	NewHandle HandleFunc
+
+	// TTL expires results held by a CacheResultStore. Default: 0 (no
+	// expiry).
+	TTL time.Duration
+}
+
+func (o ResultStoreOptions) withDefaults() ResultStoreOptions {
+	if o.NewHandle == nil {
+		o.NewHandle = newResultHandle
+	}
+	return o
+}
+
+type storedResult struct {
+	data        []byte
+	contentType string
+}
+
+// InMemoryResultStore keeps spilled results in memory.
+type InMemoryResultStore struct {
+	mu        sync.RWMutex
+	results   map[string]storedResult
+	newHandle HandleFunc
+}
+
+// NewInMemoryResultStore creates an empty in-memory result store.
+func NewInMemoryResultStore(opts ...ResultStoreOptions) *InMemoryResultStore {
+	var opt ResultStoreOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &InMemoryResultStore{
+		results:   make(map[string]storedResult),
+		newHandle: opt.withDefaults().NewHandle,
+	}
+}
+
+// Put stores a copy of data and returns a new handle.
+func (s *InMemoryResultStore) Put(_ context.Context, data []byte, contentType string) (string, error) {
+	handle, err := s.newHandle()
+	if err != nil {
+		return "", err
+	}
+	clone := make([]byte, len(data))
+	copy(clone, data)
+
+	s.mu.Lock()
+	s.results[handle] = storedResult{data: clone, contentType: contentType}
+	s.mu.Unlock()
+	return handle, nil
+}
+
+// Get returns a copy of the stored payload.
+func (s *InMemoryResultStore) Get(_ context.Context, handle string) ([]byte, string, error) {
+	s.mu.RLock()
+	stored, ok := s.results[handle]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	out := make([]byte, len(stored.data))
+	copy(out, stored.data)
+	return out, stored.contentType, nil
+}
+
+// Delete removes a stored payload.
+func (s *InMemoryResultStore) Delete(_ context.Context, handle string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.results[handle]; !ok {
+		return fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	delete(s.results, handle)
+	return nil
+}
+
+// FileResultStore writes spilled results to files in a directory.
+// Content types are tracked in memory for the lifetime of the store.
+type FileResultStore struct {
+	dir string
+
+	mu           sync.RWMutex
+	contentTypes map[string]string
+	newHandle    HandleFunc
+}
+
+// NewFileResultStore creates a file-backed result store rooted at dir.
+// If dir is empty, a new temporary directory is created.
+func NewFileResultStore(dir string, opts ...ResultStoreOptions) (*FileResultStore, error) {
+	var opt ResultStoreOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "tooldiscovery-results-")
+		if err != nil {
+			return nil, err
+		}
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileResultStore{
+		dir:          dir,
+		contentTypes: make(map[string]string),
+		newHandle:    opt.withDefaults().NewHandle,
+	}, nil
+}
+
+// Dir returns the directory holding spilled results.
+func (s *FileResultStore) Dir() string {
+	return s.dir
+}
+
+// Put writes data to a new file and returns its handle.
+func (s *FileResultStore) Put(_ context.Context, data []byte, contentType string) (string, error) {
+	handle, err := s.newHandle()
+	if err != nil {
+		return "", err
+	}
+	if handle == "" || handle != filepath.Base(handle) || handle == "." || handle == ".." {
+		return "", fmt.Errorf("invalid result handle %q", handle)
+	}
+	if err := os.WriteFile(s.path(handle), data, 0o600); err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	s.contentTypes[handle] = contentType
+	s.mu.Unlock()
+	return handle, nil
+}
+
+// Get reads a spilled payload from disk.
+func (s *FileResultStore) Get(_ context.Context, handle string) ([]byte, string, error) {
+	s.mu.RLock()
+	contentType, ok := s.contentTypes[handle]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	data, err := os.ReadFile(s.path(handle))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+// Delete removes a spilled payload from disk.
+func (s *FileResultStore) Delete(_ context.Context, handle string) error {
+	s.mu.Lock()
+	_, ok := s.contentTypes[handle]
+	delete(s.contentTypes, handle)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	return os.Remove(s.path(handle))
+}
+
+func (s *FileResultStore) path(handle string) string {
+	return filepath.Join(s.dir, handle)
+}
+
+// CacheResultStore keeps spilled results in a cache.Cache, so every replica
+// pointed at a shared cache can resolve a ResultReference handed out by any
+// of them. Entries expire after ResultStoreOptions.TTL.
+type CacheResultStore struct {
+	cache     cache.Cache
+	ttl       time.Duration
+	newHandle HandleFunc
+}
+
+// NewCacheResultStore creates a result store backed by c.
+// It returns cache.ErrInvalidCache when c is nil.
+func NewCacheResultStore(c cache.Cache, opts ...ResultStoreOptions) (*CacheResultStore, error) {
+	if c == nil {
+		return nil, cache.ErrInvalidCache
+	}
+	var opt ResultStoreOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &CacheResultStore{
+		cache:     c,
+		ttl:       opt.TTL,
+		newHandle: opt.withDefaults().NewHandle,
+	}, nil
+}
+
+// Put stores data under a new handle.
+func (s *CacheResultStore) Put(ctx context.Context, data []byte, contentType string) (string, error) {
+	handle, err := s.newHandle()
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(contentType, "\n") {
+		return "", fmt.Errorf("invalid content type %q", contentType)
+	}
+	value := make([]byte, 0, len(contentType)+1+len(data))
+	value = append(value, contentType...)
+	value = append(value, '\n')
+	value = append(value, data...)
+	if err := s.cache.Set(ctx, resultCacheKey(handle), value, s.ttl); err != nil {
+		return "", err
+	}
+	return handle, nil
+}
+
+// Get returns a copy of the stored payload. Expired results are not found.
+func (s *CacheResultStore) Get(ctx context.Context, handle string) ([]byte, string, error) {
+	value, ok, err := s.cache.Get(ctx, resultCacheKey(handle))
+	if err != nil {
+		return nil, "", err
+	}
+	contentType, data, found := strings.Cut(string(value), "\n")
+	if !ok || !found {
+		return nil, "", fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	return []byte(data), contentType, nil
+}
+
+// Delete removes a stored payload.
+func (s *CacheResultStore) Delete(ctx context.Context, handle string) error {
+	if _, _, err := s.Get(ctx, handle); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, resultCacheKey(handle))
+}
+
+// resultCacheKey returns the cache key of the result stored under handle.
+func resultCacheKey(handle string) string {
+	return "result:" + handle
+}
+
+func newResultHandle() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// encodeResult serializes a result for size checks and spilling.
+// Strings are stored as-is; all other values are JSON-encoded.
+func encodeResult(result any) ([]byte, string, error) {
+	if s, ok := result.(string); ok {
+		return []byte(s), ContentTypeText, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, ContentTypeJSON, nil
+}
+
+// spillLargeResult replaces results larger than the configured threshold
+// with a ResultReference backed by the configured ResultStore.
+func (r *Registry) spillLargeResult(ctx context.Context, result any) (any, error) {
+	threshold := r.config.LargeResultThreshold
+	store := r.config.ResultStore
+	if threshold <= 0 || store == nil || result == nil {
+		return result, nil
+	}
+
+	data, contentType, err := encodeResult(result)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= threshold {
+		return result, nil
+	}
+
+	handle, err := store.Put(ctx, data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return ResultReference{
+		Handle:      handle,
+		Size:        len(data),
+		ContentType: contentType,
+	}, nil
+}
+
+// FetchResult retrieves the full payload for a spilled result reference.
+func (r *Registry) FetchResult(ctx context.Context, handle string) ([]byte, string, error) {
+	if r.config.ResultStore == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrResultNotFound, handle)
+	}
+	return r.config.ResultStore.Get(ctx, handle)
+}
@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+func TestExecute_SpillsLargeResult(t *testing.T) {
+	store := NewInMemoryResultStore()
+	reg := New(Config{
+		LargeResultThreshold: 16,
+		ResultStore:          store,
+	})
+	payload := strings.Repeat("x", 64)
+	_ = reg.RegisterLocalFunc("big", "Big output", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) { return payload, nil })
+	_ = reg.RegisterLocalFunc("small", "Small output", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) { return "tiny", nil })
+
+	ctx := context.Background()
+	result, err := reg.Execute(ctx, "big", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ref, ok := result.(ResultReference)
+	if !ok {
+		t.Fatalf("expected ResultReference, got %T", result)
+	}
+	if ref.Size != len(payload) || ref.ContentType != ContentTypeText {
+		t.Errorf("unexpected reference: %+v", ref)
+	}
+
+	data, contentType, err := reg.FetchResult(ctx, ref.Handle)
+	if err != nil {
+		t.Fatalf("FetchResult failed: %v", err)
+	}
+	if string(data) != payload || contentType != ContentTypeText {
+		t.Errorf("fetched %q (%s), want payload", data, contentType)
+	}
+
+	result, err = reg.Execute(ctx, "small", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "tiny" {
+		t.Errorf("small result should not be spilled, got %v", result)
+	}
+}
+
+func TestExecute_SpillsJSONResult(t *testing.T) {
+	reg := New(Config{
+		LargeResultThreshold: 8,
+		ResultStore:          NewInMemoryResultStore(),
+	})
+	_ = reg.RegisterLocalFunc("obj", "Object output", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) {
+			return map[string]any{"items": []any{"a", "b", "c"}}, nil
+		})
+
+	result, err := reg.Execute(context.Background(), "obj", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ref, ok := result.(ResultReference)
+	if !ok {
+		t.Fatalf("expected ResultReference, got %T", result)
+	}
+	if ref.ContentType != ContentTypeJSON {
+		t.Errorf("content type = %s, want %s", ref.ContentType, ContentTypeJSON)
+	}
+}
+
+func TestFetchResult_NotFound(t *testing.T) {
+	reg := New(Config{})
+	if _, _, err := reg.FetchResult(context.Background(), "missing"); !errors.Is(err, ErrResultNotFound) {
+		t.Fatalf("expected ErrResultNotFound, got %v", err)
+	}
+
+	reg = New(Config{ResultStore: NewInMemoryResultStore()})
+	if _, _, err := reg.FetchResult(context.Background(), "missing"); !errors.Is(err, ErrResultNotFound) {
+		t.Fatalf("expected ErrResultNotFound, got %v", err)
+	}
+}
+
+func TestFileResultStore(t *testing.T) {
+	store, err := NewFileResultStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileResultStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	handle, err := store.Put(ctx, []byte(`{"a":1}`), ContentTypeJSON)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	data, contentType, err := store.Get(ctx, handle)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(data) != `{"a":1}` || contentType != ContentTypeJSON {
+		t.Errorf("Get = %q (%s)", data, contentType)
+	}
+
+	if err := store.Delete(ctx, handle); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, _, err := store.Get(ctx, handle); !errors.Is(err, ErrResultNotFound) {
+		t.Fatalf("expected ErrResultNotFound after delete, got %v", err)
+	}
+}
+
+func TestCacheResultStore(t *testing.T) {
+	if _, err := NewCacheResultStore(nil); !errors.Is(err, cache.ErrInvalidCache) {
+		t.Fatalf("NewCacheResultStore(nil) error = %v, want ErrInvalidCache", err)
+	}
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	shared := cache.NewLRU(cache.LRUOptions{Now: func() time.Time { return now }})
+	store, err := NewCacheResultStore(shared, ResultStoreOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewCacheResultStore failed: %v", err)
+	}
+
+	// A registry on another replica resolves the reference from the
+	// shared cache.
+	reg := New(Config{LargeResultThreshold: 4, ResultStore: store})
+	_ = reg.RegisterLocalFunc("dump", "Dump logs", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) { return "a long log line", nil })
+	result, err := reg.Execute(ctx, "dump", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	ref, ok := result.(ResultReference)
+	if !ok {
+		t.Fatalf("expected ResultReference, got %T", result)
+	}
+	other, _ := NewCacheResultStore(shared)
+	replica := New(Config{ResultStore: other})
+	data, contentType, err := replica.FetchResult(ctx, ref.Handle)
+	if err != nil || string(data) != "a long log line" || contentType != ContentTypeText {
+		t.Fatalf("FetchResult = %q (%s), %v", data, contentType, err)
+	}
+
+	if err := other.Delete(ctx, ref.Handle); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete(ctx, ref.Handle); !errors.Is(err, ErrResultNotFound) {
+		t.Fatalf("expected ErrResultNotFound after delete, got %v", err)
+	}
+
+	handle, _ := store.Put(ctx, []byte("{}"), ContentTypeJSON)
+	now = now.Add(2 * time.Minute)
+	if _, _, err := store.Get(ctx, handle); !errors.Is(err, ErrResultNotFound) {
+		t.Fatalf("expected ErrResultNotFound after TTL, got %v", err)
+	}
+}
+
+func TestResultStore_InjectedHandles(t *testing.T) {
+	ctx := context.Background()
+	n := 0
+	opts := ResultStoreOptions{NewHandle: func() (string, error) {
+		n++
+		return fmt.Sprintf("result-%d", n), nil
+	}}
+
+	mem := NewInMemoryResultStore(opts)
+	if handle, err := mem.Put(ctx, []byte("a"), ContentTypeText); err != nil || handle != "result-1" {
+		t.Fatalf("in-memory Put = %q, %v; want result-1", handle, err)
+	}
+
+	files, err := NewFileResultStore(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("NewFileResultStore failed: %v", err)
+	}
+	if handle, err := files.Put(ctx, []byte("b"), ContentTypeText); err != nil || handle != "result-2" {
+		t.Fatalf("file Put = %q, %v; want result-2", handle, err)
+	}
+
+	unsafe, _ := NewFileResultStore(t.TempDir(), ResultStoreOptions{NewHandle: func() (string, error) { return "../escape", nil }})
+	if _, err := unsafe.Put(ctx, []byte("c"), ContentTypeText); err == nil {
+		t.Fatal("Put accepted a handle that escapes the store directory")
+	}
+}
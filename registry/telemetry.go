@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// InstrumentationName is the OpenTelemetry tracer and meter name used by
+// Registry.
+const InstrumentationName = "github.com/jonwraymond/tooldiscovery/registry"
+
+// Span and metric attribute keys recorded by Registry.
+const (
+	AttrToolID      = attribute.Key("registry.tool_id")
+	AttrBackendKind = attribute.Key("registry.backend.kind")
+	AttrBackendName = attribute.Key("registry.backend.name")
+	AttrError       = attribute.Key("error")
+)
+
+// telemetry holds the OpenTelemetry instruments. Nil providers record
+// nothing.
+type telemetry struct {
+	tracer          trace.Tracer
+	backendDuration metric.Float64Histogram
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	t := &telemetry{tracer: tp.Tracer(InstrumentationName)}
+	histogram, err := mp.Meter(InstrumentationName).Float64Histogram("registry.backend.call.duration",
+		metric.WithUnit("s"), metric.WithDescription("Duration of tool calls on local handlers and MCP backends."))
+	if err != nil {
+		// Instrument creation only fails for invalid names; fall back to
+		// recording nothing rather than failing New.
+		histogram, _ = metricnoop.Meter{}.Float64Histogram("registry.backend.call.duration")
+	}
+	t.backendDuration = histogram
+	return t
+}
+
+// backendAttributes identifies the backend a tool runs on.
+func backendAttributes(backend model.ToolBackend) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{AttrBackendKind.String(string(backend.Kind))}
+	switch {
+	case backend.MCP != nil:
+		attrs = append(attrs, AttrBackendName.String(backend.MCP.ServerName))
+	case backend.Provider != nil:
+		attrs = append(attrs, AttrBackendName.String(backend.Provider.ProviderID))
+	case backend.Local != nil:
+		attrs = append(attrs, AttrBackendName.String(backend.Local.Name))
+	}
+	return attrs
+}
+
+// instrumentHandler records the duration of each call to handler on
+// backend.
+func (t *telemetry) instrumentHandler(handler ToolHandler, backend model.ToolBackend) ToolHandler {
+	attrs := backendAttributes(backend)
+	ok := metric.WithAttributes(append(attrs, AttrError.Bool(false))...)
+	failed := metric.WithAttributes(append(attrs, AttrError.Bool(true))...)
+	return func(ctx context.Context, args map[string]any) (any, error) {
+		start := time.Now()
+		result, err := handler(ctx, args)
+		outcome := ok
+		if err != nil {
+			outcome = failed
+		}
+		t.backendDuration.Record(ctx, time.Since(start).Seconds(), outcome)
+		return result, err
+	}
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry_Execute(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	reg := New(Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	schema := map[string]any{"type": "object"}
+	_ = reg.RegisterLocalFunc("echo", "Echoes input", schema, func(_ context.Context, args map[string]any) (any, error) {
+		return args, nil
+	})
+	_ = reg.RegisterLocalFunc("fail", "Always fails", schema, func(context.Context, map[string]any) (any, error) {
+		return nil, errors.New("boom")
+	})
+	ctx := context.Background()
+
+	if _, err := reg.Execute(ctx, "echo", nil); err != nil {
+		t.Fatalf("Execute(echo) failed: %v", err)
+	}
+	if _, err := reg.Execute(ctx, "fail", nil); err == nil {
+		t.Fatal("Execute(fail) succeeded")
+	}
+	if _, err := reg.Execute(ctx, "missing", nil); err == nil {
+		t.Fatal("Execute(missing) succeeded")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("len(spans) = %d, want 3", len(ended))
+	}
+	for i, want := range []codes.Code{codes.Unset, codes.Error, codes.Error} {
+		if ended[i].Name() != "registry.Execute" || ended[i].Status().Code != want {
+			t.Errorf("span %d = %s %v, want registry.Execute %v", i, ended[i].Name(), ended[i].Status(), want)
+		}
+	}
+	if !hasAttribute(ended[0].Attributes(), AttrBackendKind.String("local")) {
+		t.Errorf("echo span attributes = %v, want backend kind", ended[0].Attributes())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var calls, failed uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "registry.backend.call.duration" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				calls += dp.Count
+				if v, _ := dp.Attributes.Value(AttrError); v.AsBool() {
+					failed += dp.Count
+				}
+			}
+		}
+	}
+	// The missing tool never reaches a backend.
+	if calls != 2 || failed != 1 {
+		t.Errorf("backend calls = %d (%d failed), want 2 (1 failed)", calls, failed)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig describes TLS material for serving or for connecting to backends.
+//
+// Certificates and CA bundles may be given as file paths or as PEM bytes;
+// PEM bytes take precedence when both are set.
+//
+// When used by Serve, the certificate is the server certificate and the CA
+// bundle verifies client certificates (mTLS). When used by BackendConfig, the
+// certificate is the client certificate and the CA bundle verifies the backend.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+
+	// CAFile or CAPEM is a PEM bundle of trusted certificate authorities.
+	CAFile string
+	CAPEM  []byte
+
+	// ServerName overrides the SNI/verification host name for backend connections.
+	ServerName string
+
+	// RequireClientCert enforces mTLS when serving. Requires a CA bundle.
+	RequireClientCert bool
+
+	// InsecureSkipVerify disables backend certificate verification. Tests only.
+	InsecureSkipVerify bool
+
+	// MinVersion is the minimum TLS version. Default: TLS 1.2.
+	MinVersion uint16
+}
+
+// ServerConfig builds a *tls.Config for serving.
+func (c TLSConfig) ServerConfig() (*tls.Config, error) {
+	cert, ok, err := c.certificate()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: server certificate is required", ErrInvalidTLSConfig)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   c.minVersion(),
+	}
+
+	pool, err := c.caPool()
+	if err != nil {
+		return nil, err
+	}
+	if pool != nil {
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	if c.RequireClientCert {
+		if pool == nil {
+			return nil, fmt.Errorf("%w: client certificate verification requires a CA bundle", ErrInvalidTLSConfig)
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientConfig builds a *tls.Config for connecting to a backend.
+func (c TLSConfig) ClientConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, // #nosec G402 -- opt-in for tests
+		MinVersion:         c.minVersion(),
+	}
+
+	cert, ok, err := c.certificate()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	pool, err := c.caPool()
+	if err != nil {
+		return nil, err
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+func (c TLSConfig) minVersion() uint16 {
+	if c.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return c.MinVersion
+}
+
+func (c TLSConfig) certificate() (tls.Certificate, bool, error) {
+	certPEM, keyPEM := c.CertPEM, c.KeyPEM
+	if len(certPEM) == 0 && c.CertFile != "" {
+		data, err := os.ReadFile(c.CertFile)
+		if err != nil {
+			return tls.Certificate{}, false, fmt.Errorf("%w: read cert: %v", ErrInvalidTLSConfig, err)
+		}
+		certPEM = data
+	}
+	if len(keyPEM) == 0 && c.KeyFile != "" {
+		data, err := os.ReadFile(c.KeyFile)
+		if err != nil {
+			return tls.Certificate{}, false, fmt.Errorf("%w: read key: %v", ErrInvalidTLSConfig, err)
+		}
+		keyPEM = data
+	}
+	if len(certPEM) == 0 && len(keyPEM) == 0 {
+		return tls.Certificate{}, false, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err)
+	}
+	return cert, true, nil
+}
+
+func (c TLSConfig) caPool() (*x509.CertPool, error) {
+	caPEM := c.CAPEM
+	if len(caPEM) == 0 && c.CAFile != "" {
+		data, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: read CA bundle: %v", ErrInvalidTLSConfig, err)
+		}
+		caPEM = data
+	}
+	if len(caPEM) == 0 {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("%w: CA bundle contains no certificates", ErrInvalidTLSConfig)
+	}
+	return pool, nil
+}
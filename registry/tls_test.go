@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPKI struct {
+	caPEM             []byte
+	serverCert        []byte
+	serverKey         []byte
+	clientCert        []byte
+	clientKey         []byte
+	caCert            *x509.Certificate
+	caKey             *ecdsa.PrivateKey
+	serialNumberCount int64
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	p := &testPKI{
+		caPEM:             pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		caCert:            caCert,
+		caKey:             caKey,
+		serialNumberCount: 1,
+	}
+	p.serverCert, p.serverKey = p.issue(t, "tools.internal", x509.ExtKeyUsageServerAuth)
+	p.clientCert, p.clientKey = p.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	return p
+}
+
+func (p *testPKI) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	p.serialNumberCount++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(p.serialNumberCount),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.caCert, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestTLSConfig_ServerConfigRequiresCert(t *testing.T) {
+	_, err := TLSConfig{}.ServerConfig()
+	if !errors.Is(err, ErrInvalidTLSConfig) {
+		t.Fatalf("expected ErrInvalidTLSConfig, got %v", err)
+	}
+}
+
+func TestTLSConfig_RequireClientCertNeedsCA(t *testing.T) {
+	pki := newTestPKI(t)
+	_, err := TLSConfig{
+		CertPEM:           pki.serverCert,
+		KeyPEM:            pki.serverKey,
+		RequireClientCert: true,
+	}.ServerConfig()
+	if !errors.Is(err, ErrInvalidTLSConfig) {
+		t.Fatalf("expected ErrInvalidTLSConfig, got %v", err)
+	}
+}
+
+func TestTLSConfig_ClientConfig(t *testing.T) {
+	pki := newTestPKI(t)
+	cfg, err := TLSConfig{
+		CertPEM:    pki.clientCert,
+		KeyPEM:     pki.clientKey,
+		CAPEM:      pki.caPEM,
+		ServerName: "tools.internal",
+	}.ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig failed: %v", err)
+	}
+	if cfg.ServerName != "tools.internal" {
+		t.Errorf("ServerName = %q", cfg.ServerName)
+	}
+	if len(cfg.Certificates) != 1 || cfg.RootCAs == nil {
+		t.Errorf("expected client cert and root CAs")
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+	}
+}
+
+func TestTLSConfig_InvalidCABundle(t *testing.T) {
+	_, err := TLSConfig{CAPEM: []byte("not a cert")}.ClientConfig()
+	if !errors.Is(err, ErrInvalidTLSConfig) {
+		t.Fatalf("expected ErrInvalidTLSConfig, got %v", err)
+	}
+}
+
+func TestServe_MutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, reg, ServeOptions{
+			HTTPListener:   listener,
+			DisableSignals: true,
+			TLS: &TLSConfig{
+				CertPEM:           pki.serverCert,
+				KeyPEM:            pki.serverKey,
+				CAPEM:             pki.caPEM,
+				RequireClientCert: true,
+			},
+		})
+	}()
+
+	url := "https://" + listener.Addr().String() + DefaultHTTPPath
+	body := `{"jsonrpc":"2.0","id":1,"method":"initialize"}`
+
+	withCert, err := httpClientFor(nil, &TLSConfig{
+		CertPEM:    pki.clientCert,
+		KeyPEM:     pki.clientKey,
+		CAPEM:      pki.caPEM,
+		ServerName: "tools.internal",
+	}, "")
+	if err != nil {
+		t.Fatalf("client config: %v", err)
+	}
+	withCert.Transport.(*http.Transport).DisableKeepAlives = true
+
+	var resp *http.Response
+	for range 50 {
+		resp, err = withCert.Post(url, "application/json", strings.NewReader(body))
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	withoutCert, _ := httpClientFor(nil, &TLSConfig{CAPEM: pki.caPEM, ServerName: "tools.internal"}, "")
+	withoutCert.Transport.(*http.Transport).DisableKeepAlives = true
+	if resp, err := withoutCert.Post(url, "application/json", strings.NewReader(body)); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected handshake failure without client certificate")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve returned error: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResultTransformer rewrites a tool result after execution.
+// It receives the canonical tool ID and the raw result returned by the
+// local handler or MCP backend, and returns the value passed to callers.
+// Returning an error fails the call with ErrExecutionFailed.
+type ResultTransformer func(ctx context.Context, toolID string, result any) (any, error)
+
+// ChainResultTransformers composes transformers, applying them in order.
+// Nil transformers are skipped.
+func ChainResultTransformers(transformers ...ResultTransformer) ResultTransformer {
+	return func(ctx context.Context, toolID string, result any) (any, error) {
+		var err error
+		for _, t := range transformers {
+			if t == nil {
+				continue
+			}
+			result, err = t(ctx, toolID, result)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+}
+
+// TruncateTextTransformer returns a transformer that truncates string results,
+// text content blocks, and the text items of multi-content results to at most
+// maxLen bytes, cutting on a rune boundary. A non-positive maxLen disables
+// truncation.
+func TruncateTextTransformer(maxLen int) ResultTransformer {
+	return func(_ context.Context, _ string, result any) (any, error) {
+		if maxLen <= 0 {
+			return result, nil
+		}
+		switch v := result.(type) {
+		case string:
+			return truncateText(v, maxLen), nil
+		case []mcp.Content:
+			out := make([]mcp.Content, len(v))
+			for i, content := range v {
+				if text, ok := content.(*mcp.TextContent); ok && len(text.Text) > maxLen {
+					clone := *text
+					clone.Text = truncateText(text.Text, maxLen)
+					out[i] = &clone
+					continue
+				}
+				out[i] = content
+			}
+			return out, nil
+		case []any:
+			out := make([]any, len(v))
+			for i, item := range v {
+				switch c := item.(type) {
+				case string:
+					out[i] = truncateText(c, maxLen)
+				case *mcp.TextContent:
+					clone := *c
+					clone.Text = truncateText(c.Text, maxLen)
+					out[i] = &clone
+				default:
+					out[i] = item
+				}
+			}
+			return out, nil
+		default:
+			return result, nil
+		}
+	}
+}
+
+// StripBinaryTransformer returns a transformer that removes image, audio, and
+// embedded blob content from content-slice and multi-content results, keeping
+// text and links.
+// BinaryContent results keep their metadata but lose their payload.
+func StripBinaryTransformer() ResultTransformer {
+	return func(_ context.Context, _ string, result any) (any, error) {
+		if bin, ok := result.(BinaryContent); ok {
+			bin.Data = nil
+			return bin, nil
+		}
+		switch v := result.(type) {
+		case []mcp.Content:
+			out := make([]mcp.Content, 0, len(v))
+			for _, content := range v {
+				if !isBinaryBlock(content) {
+					out = append(out, content)
+				}
+			}
+			return out, nil
+		case []any:
+			out := make([]any, 0, len(v))
+			for _, item := range v {
+				if _, ok := item.(BinaryContent); ok {
+					continue
+				}
+				if content, ok := item.(mcp.Content); ok && isBinaryBlock(content) {
+					continue
+				}
+				out = append(out, item)
+			}
+			return out, nil
+		default:
+			return result, nil
+		}
+	}
+}
+
+func isBinaryBlock(content mcp.Content) bool {
+	switch c := content.(type) {
+	case *mcp.ImageContent, *mcp.AudioContent:
+		return true
+	case *mcp.EmbeddedResource:
+		return c.Resource != nil && len(c.Resource.Blob) > 0
+	}
+	return false
+}
+
+func truncateText(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	// Back off to a rune boundary so the result stays valid UTF-8.
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// applyResultTransformers runs the per-tool transformer followed by the
+// global transformer configured on the registry.
+func (r *Registry) applyResultTransformers(ctx context.Context, toolID string, result any) (any, error) {
+	r.mu.RLock()
+	perTool := r.transformers[toolID]
+	r.mu.RUnlock()
+
+	var err error
+	if perTool != nil {
+		result, err = perTool(ctx, toolID, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.config.ResultTransformer != nil {
+		result, err = r.config.ResultTransformer(ctx, toolID, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// SetResultTransformer installs a per-tool transformer for the given tool ID.
+// Passing nil removes any existing transformer.
+func (r *Registry) SetResultTransformer(toolID string, transformer ResultTransformer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if transformer == nil {
+		delete(r.transformers, toolID)
+		return
+	}
+	r.transformers[toolID] = transformer
+}
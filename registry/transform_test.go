@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestResultTransformer_PerToolThenGlobal(t *testing.T) {
+	var order []string
+	reg := New(Config{
+		ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"},
+		ResultTransformer: func(_ context.Context, toolID string, result any) (any, error) {
+			order = append(order, "global:"+toolID)
+			return result.(string) + "-global", nil
+		},
+	})
+
+	err := reg.RegisterLocalFunc(
+		"echo",
+		"Echoes input",
+		map[string]any{"type": "object"},
+		func(_ context.Context, args map[string]any) (any, error) {
+			return args["message"], nil
+		},
+		WithNamespace("util"),
+		WithResultTransformer(func(_ context.Context, toolID string, result any) (any, error) {
+			order = append(order, "tool:"+toolID)
+			return result.(string) + "-tool", nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+
+	result, err := reg.Execute(context.Background(), "util:echo", map[string]any{"message": "hi"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "hi-tool-global" {
+		t.Errorf("result = %v, want hi-tool-global", result)
+	}
+	if len(order) != 2 || order[0] != "tool:util:echo" || order[1] != "global:util:echo" {
+		t.Errorf("unexpected transformer order: %v", order)
+	}
+}
+
+func TestResultTransformer_Error(t *testing.T) {
+	reg := New(Config{
+		ResultTransformer: func(context.Context, string, any) (any, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) { return "ok", nil })
+
+	_, err := reg.Execute(context.Background(), "echo", nil)
+	if !errors.Is(err, ErrExecutionFailed) {
+		t.Fatalf("expected ErrExecutionFailed, got %v", err)
+	}
+}
+
+func TestSetResultTransformer_Remove(t *testing.T) {
+	reg := New(Config{})
+	_ = reg.RegisterLocalFunc("echo", "Echo", map[string]any{"type": "object"},
+		func(context.Context, map[string]any) (any, error) { return "ok", nil })
+
+	reg.SetResultTransformer("echo", func(context.Context, string, any) (any, error) {
+		return "changed", nil
+	})
+	reg.SetResultTransformer("echo", nil)
+
+	result, err := reg.Execute(context.Background(), "echo", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "ok" {
+		t.Errorf("result = %v, want ok", result)
+	}
+}
+
+func TestTruncateTextTransformer(t *testing.T) {
+	ctx := context.Background()
+	truncate := TruncateTextTransformer(5)
+
+	got, err := truncate(ctx, "t", strings.Repeat("x", 10))
+	if err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	if got != "xxxxx" {
+		t.Errorf("got %q, want xxxxx", got)
+	}
+
+	original := &mcp.TextContent{Text: "abcdefgh"}
+	got, err = truncate(ctx, "t", []mcp.Content{original})
+	if err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	contents := got.([]mcp.Content)
+	if text := contents[0].(*mcp.TextContent).Text; text != "abcde" {
+		t.Errorf("got %q, want abcde", text)
+	}
+	if original.Text != "abcdefgh" {
+		t.Errorf("original content was mutated: %q", original.Text)
+	}
+
+	got, _ = TruncateTextTransformer(5)(ctx, "t", "abcdé")
+	if got != "abcd" {
+		t.Errorf("got %q, want abcd (no split rune)", got)
+	}
+	if !utf8.ValidString(got.(string)) {
+		t.Errorf("truncated text is not valid UTF-8: %q", got)
+	}
+
+	got, _ = TruncateTextTransformer(0)(ctx, "t", "unchanged")
+	if got != "unchanged" {
+		t.Errorf("maxLen 0 should disable truncation, got %v", got)
+	}
+}
+
+func TestStripBinaryTransformer(t *testing.T) {
+	contents := []mcp.Content{
+		&mcp.TextContent{Text: "keep"},
+		&mcp.ImageContent{Data: []byte("img"), MIMEType: "image/png"},
+		&mcp.AudioContent{Data: []byte("aud"), MIMEType: "audio/wav"},
+		&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file://a", Blob: []byte("b")}},
+		&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file://b", Text: "t"}},
+	}
+
+	got, err := StripBinaryTransformer()(context.Background(), "t", contents)
+	if err != nil {
+		t.Fatalf("strip failed: %v", err)
+	}
+	out := got.([]mcp.Content)
+	if len(out) != 2 {
+		t.Fatalf("expected 2 contents, got %d", len(out))
+	}
+}
+
+func TestTextAndBinaryTransformers_MixedContent(t *testing.T) {
+	ctx := context.Background()
+	items := []any{"abcdefgh", BinaryContent{Kind: BinaryKindImage, Data: []byte("img")}}
+
+	got, err := TruncateTextTransformer(3)(ctx, "t", items)
+	if err != nil {
+		t.Fatalf("truncate failed: %v", err)
+	}
+	if truncated := got.([]any); truncated[0] != "abc" || len(truncated) != 2 {
+		t.Errorf("truncate = %v, want [abc <image>]", truncated)
+	}
+
+	got, err = StripBinaryTransformer()(ctx, "t", items)
+	if err != nil {
+		t.Fatalf("strip failed: %v", err)
+	}
+	if stripped := got.([]any); len(stripped) != 1 || stripped[0] != "abcdefgh" {
+		t.Errorf("strip = %v, want [abcdefgh]", stripped)
+	}
+}
+
+func TestChainResultTransformers(t *testing.T) {
+	appendTo := func(suffix string) ResultTransformer {
+		return func(_ context.Context, _ string, result any) (any, error) {
+			return result.(string) + suffix, nil
+		}
+	}
+	chain := ChainResultTransformers(appendTo("a"), nil, appendTo("b"))
+	got, err := chain(context.Background(), "t", "")
+	if err != nil {
+		t.Fatalf("chain failed: %v", err)
+	}
+	if got != "ab" {
+		t.Errorf("got %v, want ab", got)
+	}
+}
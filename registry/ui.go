@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"cmp"
+	"embed"
+	"errors"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/tooldoc"
+)
+
+// Default values for UIOptions and ServeOptions.UIPath.
+const (
+	DefaultUIPath        = "/ui"
+	DefaultUISearchLimit = 200
+)
+
+//go:embed ui
+var uiAssets embed.FS
+
+// UIOptions configures ServeUI.
+type UIOptions struct {
+	// Docs backs the tool detail pages. Default: a tooldoc.InMemoryStore over
+	// the registry index, which renders the summary and schema tiers.
+	Docs tooldoc.Store
+	// SearchLimit caps the tools returned per search.
+	// Default: DefaultUISearchLimit.
+	SearchLimit int
+}
+
+// UISearchResponse is returned by the UI search endpoint.
+type UISearchResponse struct {
+	Results []index.Summary `json:"results"`
+	Facets  UIFacets        `json:"facets"`
+}
+
+// UIFacets counts the tools matching a search query by namespace, tag, and
+// category, before facet filters are applied.
+type UIFacets struct {
+	Namespaces []UIFacetCount `json:"namespaces"`
+	Tags       []UIFacetCount `json:"tags"`
+	Categories []UIFacetCount `json:"categories"`
+}
+
+// UIFacetCount is one facet value and the number of matching tools.
+type UIFacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ServeUI returns an http.Handler for a read-only catalog browser: tool
+// search with namespace, tag, and category facets, detail pages for each
+// tooldoc tier, and backend health. The page is embedded in the binary and
+// calls JSON endpoints relative to its own URL:
+//
+//   - GET api/search?q=&namespace=&tag=&category= returns a UISearchResponse
+//   - GET api/tools/{id}?detail=summary|schema|full&profile= returns a
+//     tooldoc.ToolDoc, trimmed by the named tooldoc truncation profile
+//   - GET api/health returns the registry HealthReport
+//
+// Mount it under a prefix with http.StripPrefix; Serve does this at
+// ServeOptions.UIPath.
+func ServeUI(r *Registry, opts ...UIOptions) http.Handler {
+	var opt UIOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Docs == nil {
+		opt.Docs = tooldoc.NewInMemoryStore(tooldoc.StoreOptions{Index: r.index})
+	}
+	if opt.SearchLimit <= 0 {
+		opt.SearchLimit = DefaultUISearchLimit
+	}
+
+	assets, _ := fs.Sub(uiAssets, "ui")
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(assets))
+	mux.HandleFunc("GET /api/search", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		limit := opt.SearchLimit
+		if n, err := strconv.Atoi(query.Get("limit")); err == nil && n > 0 && n < limit {
+			limit = n
+		}
+		summaries, err := r.index.Search(query.Get("q"), limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, UISearchResponse{
+			Results: filterSummaries(summaries, query.Get("namespace"), query.Get("tag"), query.Get("category")),
+			Facets:  summaryFacets(summaries),
+		})
+	})
+	mux.HandleFunc("GET /api/tools/{id...}", func(w http.ResponseWriter, req *http.Request) {
+		level := tooldoc.DetailLevel(req.URL.Query().Get("detail"))
+		if level == "" {
+			level = tooldoc.DetailSummary
+		}
+		doc, err := tooldoc.DescribeWithProfile(opt.Docs, req.PathValue("id"), level, req.URL.Query().Get("profile"))
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, doc)
+		case errors.Is(err, tooldoc.ErrNotFound), errors.Is(err, index.ErrNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, r.Health())
+	})
+	return mux
+}
+
+// filterSummaries keeps the summaries matching every non-empty facet value.
+func filterSummaries(summaries []index.Summary, namespace, tag, category string) []index.Summary {
+	out := make([]index.Summary, 0, len(summaries))
+	for _, s := range summaries {
+		if namespace != "" && s.Namespace != namespace {
+			continue
+		}
+		if tag != "" && !slices.Contains(s.Tags, tag) {
+			continue
+		}
+		if category != "" && s.Category != category {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func summaryFacets(summaries []index.Summary) UIFacets {
+	namespaces := map[string]int{}
+	tags := map[string]int{}
+	categories := map[string]int{}
+	for _, s := range summaries {
+		if s.Namespace != "" {
+			namespaces[s.Namespace]++
+		}
+		for _, tag := range s.Tags {
+			tags[tag]++
+		}
+		if s.Category != "" {
+			categories[s.Category]++
+		}
+	}
+	return UIFacets{
+		Namespaces: facetCounts(namespaces),
+		Tags:       facetCounts(tags),
+		Categories: facetCounts(categories),
+	}
+}
+
+// facetCounts orders facet values by descending count, then by value.
+func facetCounts(counts map[string]int) []UIFacetCount {
+	out := make([]UIFacetCount, 0, len(counts))
+	for value, count := range counts {
+		out = append(out, UIFacetCount{Value: value, Count: count})
+	}
+	slices.SortFunc(out, func(a, b UIFacetCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value))
+	})
+	return out
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func TestServeUI(t *testing.T) {
+	reg := New(Config{ServerInfo: ServerInfo{Name: "test", Version: "1.0.0"}})
+	handler := func(ctx context.Context, args map[string]any) (any, error) { return nil, nil }
+	for _, tool := range []model.Tool{
+		{Tool: mcp.Tool{Name: "create_issue", Description: "Create an issue", InputSchema: map[string]any{"type": "object"}}, Namespace: "github", Tags: []string{"issues", "write"}},
+		{Tool: mcp.Tool{Name: "list_issues", Description: "List issues", InputSchema: map[string]any{"type": "object"}}, Namespace: "github", Tags: []string{"issues"}},
+		{Tool: mcp.Tool{Name: "send_message", Description: "Send a message", InputSchema: map[string]any{"type": "object"}}, Namespace: "slack"},
+	} {
+		if err := reg.RegisterLocal(tool, handler); err != nil {
+			t.Fatalf("RegisterLocal failed: %v", err)
+		}
+	}
+	server := httptest.NewServer(newServeMux(reg, ServeOptions{UI: &UIOptions{}}))
+	defer server.Close()
+
+	get := func(path string) (int, []byte) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, body
+	}
+
+	if status, body := get(DefaultUIPath + "/"); status != http.StatusOK || !strings.Contains(string(body), "Tool Catalog") {
+		t.Fatalf("index page status = %d", status)
+	}
+
+	status, body := get(DefaultUIPath + "/api/search?tag=issues")
+	if status != http.StatusOK {
+		t.Fatalf("search status = %d: %s", status, body)
+	}
+	var search UISearchResponse
+	if err := json.Unmarshal(body, &search); err != nil {
+		t.Fatalf("decode search: %v", err)
+	}
+	if len(search.Results) != 2 {
+		t.Errorf("tag=issues results = %+v, want 2", search.Results)
+	}
+	// Facets count every match, so other values stay selectable.
+	if got := search.Facets.Namespaces; len(got) != 2 || got[0] != (UIFacetCount{Value: "github", Count: 2}) {
+		t.Errorf("namespace facets = %+v", got)
+	}
+	if got := search.Facets.Tags; len(got) != 2 || got[0] != (UIFacetCount{Value: "issues", Count: 2}) {
+		t.Errorf("tag facets = %+v", got)
+	}
+
+	status, body = get(DefaultUIPath + "/api/tools/github:create_issue?detail=schema")
+	if status != http.StatusOK || !strings.Contains(string(body), `"inputSchema"`) {
+		t.Errorf("schema detail status = %d: %s", status, body)
+	}
+	if status, _ := get(DefaultUIPath + "/api/tools/missing"); status != http.StatusNotFound {
+		t.Errorf("missing tool status = %d, want 404", status)
+	}
+	if status, _ := get(DefaultUIPath + "/api/tools/github:create_issue?detail=bogus"); status != http.StatusBadRequest {
+		t.Errorf("invalid detail status = %d, want 400", status)
+	}
+	if status, _ := get(DefaultUIPath + "/api/tools/github:create_issue?profile=bogus"); status != http.StatusBadRequest {
+		t.Errorf("invalid profile status = %d, want 400", status)
+	}
+
+	status, body = get(DefaultUIPath + "/api/health")
+	var report HealthReport
+	if status != http.StatusOK || json.Unmarshal(body, &report) != nil || report.Index.Tools != 3 {
+		t.Errorf("health status = %d: %s", status, body)
+	}
+}
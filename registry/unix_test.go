@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shortSocketPath returns a socket path short enough for sun_path limits.
+func shortSocketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "td")
+	if err != nil {
+		t.Fatalf("mkdir temp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+func TestServe_UnixSocket(t *testing.T) {
+	socket := shortSocketPath(t)
+	reg := New(Config{ServerInfo: ServerInfo{Name: "unix", Version: "1.0.0"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, reg, ServeOptions{UnixSocket: socket, DisableSignals: true})
+	}()
+
+	client, err := httpClientFor(nil, nil, socket)
+	if err != nil {
+		t.Fatalf("client: %v", err)
+	}
+	client.Transport.(*http.Transport).DisableKeepAlives = true
+
+	var resp *http.Response
+	for range 50 {
+		resp, err = client.Post("http://unix"+DefaultHTTPPath, "application/json",
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize"}`))
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("request over unix socket failed: %v", err)
+	}
+	var mcpResp MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&mcpResp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	_ = resp.Body.Close()
+	if mcpResp.Error != nil {
+		t.Fatalf("unexpected error: %v", mcpResp.Error)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve returned error: %v", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed, stat err = %v", err)
+	}
+}
+
+func TestRegisterMCP_UnixSocketBackend(t *testing.T) {
+	socket := shortSocketPath(t)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "unix-backend"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "ping", Description: "Ping"},
+		func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return nil, map[string]any{"pong": true}, nil
+		})
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	mux := http.NewServeMux()
+	mux.Handle("/custom", handler)
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: time.Second}
+	go func() { _ = httpServer.Serve(listener) }()
+	defer func() { _ = httpServer.Close() }()
+
+	reg := New(Config{})
+	if err := reg.RegisterMCP(BackendConfig{Name: "local", URL: "unix://" + socket + "?path=/custom"}); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	ctx := context.Background()
+	if err := reg.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+
+	result, err := reg.Execute(ctx, "ping", nil)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if m, ok := result.(map[string]any); !ok || m["pong"] != true {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
+
+func TestBackendTransport_UnixRequiresPath(t *testing.T) {
+	b := &mcpBackend{config: BackendConfig{Name: "x", URL: "unix://"}}
+	if _, err := b.transport(); err == nil {
+		t.Fatal("expected error for unix URL without socket path")
+	}
+}
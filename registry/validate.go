@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ArgIssue is one argument that failed InputSchema validation.
+type ArgIssue struct {
+	// Path is the JSON Pointer (RFC 6901) to the failing argument, such as
+	// "/options/timeout" or "/labels/0". The empty path is the arguments
+	// object itself.
+	Path string `json:"path"`
+	// Message describes the failure.
+	Message string `json:"message"`
+}
+
+// ArgsError reports tool arguments rejected by InputSchema validation
+// (Config.ValidateArgs). It matches ErrInvalidArgs with errors.Is, and
+// tools/call responds with ErrCodeInvalidParams and the error as data.
+type ArgsError struct {
+	ToolID string     `json:"toolId"`
+	Issues []ArgIssue `json:"issues"`
+}
+
+func (e *ArgsError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		path := issue.Path
+		if path == "" {
+			path = "/"
+		}
+		parts[i] = path + ": " + issue.Message
+	}
+	return fmt.Sprintf("%s for %s: %s", ErrInvalidArgs, e.ToolID, strings.Join(parts, "; "))
+}
+
+// Unwrap returns ErrInvalidArgs.
+func (e *ArgsError) Unwrap() error {
+	return ErrInvalidArgs
+}
+
+// validateArgs checks args against the tool's InputSchema. Tools without an
+// input schema accept any arguments.
+func (r *Registry) validateArgs(tool model.Tool, args map[string]any) error {
+	if tool.InputSchema == nil {
+		return nil
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	if err := r.validator.ValidateInput(&tool, args); err != nil {
+		schema, ok := schemaAsMap(tool.InputSchema)
+		if !ok || !strings.HasPrefix(err.Error(), "validation failed") {
+			// The schema itself is unusable; that is not the caller's fault.
+			return fmt.Errorf("validate arguments for %s: %w", tool.ToolID(), err)
+		}
+		return &ArgsError{ToolID: tool.ToolID(), Issues: r.collectArgIssues(schema, args, "", err)}
+	}
+	return nil
+}
+
+// collectArgIssues locates the arguments that make instance fail schema.
+// It descends into properties and array items whose subschemas can be
+// validated on their own (no $ref), so each failure is reported at the
+// deepest path that explains it; failures it cannot attribute to a child
+// are reported at path with the validator's message.
+func (r *Registry) collectArgIssues(schema map[string]any, instance any, path string, err error) []ArgIssue {
+	var issues []ArgIssue
+	switch value := instance.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range requiredNames(schema["required"]) {
+			if _, ok := value[name]; !ok {
+				issues = append(issues, ArgIssue{Path: path + "/" + escapePointer(name), Message: "required property is missing"})
+			}
+		}
+		if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+			for _, name := range sortedKeys(value) {
+				if _, ok := props[name]; !ok {
+					issues = append(issues, ArgIssue{Path: path + "/" + escapePointer(name), Message: "property is not allowed"})
+				}
+			}
+		}
+		for _, name := range sortedKeys(props) {
+			sub, ok := props[name].(map[string]any)
+			child, present := value[name]
+			if !ok || !present || hasRef(sub) {
+				continue
+			}
+			if childErr := r.validator.Validate(sub, child); childErr != nil {
+				issues = append(issues, r.collectArgIssues(sub, child, path+"/"+escapePointer(name), childErr)...)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok && !hasRef(items) {
+			for i, item := range value {
+				if itemErr := r.validator.Validate(items, item); itemErr != nil {
+					issues = append(issues, r.collectArgIssues(items, item, path+"/"+strconv.Itoa(i), itemErr)...)
+				}
+			}
+		}
+	}
+	if len(issues) == 0 {
+		issues = append(issues, ArgIssue{Path: path, Message: validationMessage(err)})
+	}
+	return issues
+}
+
+// validationMessage strips the validator's schema-location prefixes,
+// keeping the final reason such as "type: 5 has type \"integer\", want
+// \"string\"".
+func validationMessage(err error) string {
+	msg := err.Error()
+	if i := strings.LastIndex(msg, ": validating "); i >= 0 {
+		if j := strings.Index(msg[i+len(": validating "):], ": "); j >= 0 {
+			msg = msg[i+len(": validating ")+j+2:]
+		}
+	}
+	return strings.TrimPrefix(msg, "validation failed: ")
+}
+
+// hasRef reports whether schema contains a $ref anywhere, which cannot be
+// resolved outside the root schema.
+func hasRef(schema any) bool {
+	switch s := schema.(type) {
+	case map[string]any:
+		if _, ok := s["$ref"]; ok {
+			return true
+		}
+		if _, ok := s["$dynamicRef"]; ok {
+			return true
+		}
+		for _, v := range s {
+			if hasRef(v) {
+				return true
+			}
+		}
+	case []any:
+		return slices.ContainsFunc(s, hasRef)
+	}
+	return false
+}
+
+// schemaAsMap converts a schema to its generic JSON form.
+func schemaAsMap(schema any) (map[string]any, bool) {
+	if m, ok := schema.(map[string]any); ok {
+		return m, true
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+func requiredNames(v any) []string {
+	switch names := v.(type) {
+	case []string:
+		return names
+	case []any:
+		out := make([]string, 0, len(names))
+		for _, n := range names {
+			if s, ok := n.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// escapePointer escapes a JSON Pointer reference token.
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var validateTestSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"query": map[string]any{"type": "string"},
+		"limit": map[string]any{"type": "integer", "minimum": 1},
+		"options": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"timeout": map[string]any{"type": "number"},
+			},
+			"additionalProperties": false,
+		},
+		"labels": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required": []any{"query"},
+}
+
+func newValidatingRegistry(t *testing.T, validate bool) (*Registry, *int) {
+	t.Helper()
+	calls := 0
+	reg := New(Config{ValidateArgs: validate})
+	err := reg.RegisterLocalFunc("search", "Search", validateTestSchema,
+		func(_ context.Context, args map[string]any) (any, error) {
+			calls++
+			return args, nil
+		})
+	if err != nil {
+		t.Fatalf("RegisterLocalFunc failed: %v", err)
+	}
+	return reg, &calls
+}
+
+func TestValidateArgs(t *testing.T) {
+	reg, calls := newValidatingRegistry(t, true)
+
+	if _, err := reg.Execute(context.Background(), "search", map[string]any{"query": "go", "limit": 5.0}); err != nil {
+		t.Fatalf("valid args rejected: %v", err)
+	}
+
+	_, err := reg.Execute(context.Background(), "search", map[string]any{
+		"limit":   0.0,
+		"options": map[string]any{"timeout": "soon", "retries": 3.0},
+		"labels":  []any{"ok", 7.0},
+	})
+	var argsErr *ArgsError
+	if !errors.As(err, &argsErr) || !errors.Is(err, ErrInvalidArgs) {
+		t.Fatalf("expected *ArgsError, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+
+	want := []string{"/query", "/labels/1", "/limit", "/options/retries", "/options/timeout"}
+	if len(argsErr.Issues) != len(want) {
+		t.Fatalf("issues = %+v, want paths %v", argsErr.Issues, want)
+	}
+	for i, path := range want {
+		if argsErr.Issues[i].Path != path || argsErr.Issues[i].Message == "" {
+			t.Errorf("issue %d = %+v, want path %s", i, argsErr.Issues[i], path)
+		}
+	}
+}
+
+func TestValidateArgs_Disabled(t *testing.T) {
+	reg, calls := newValidatingRegistry(t, false)
+	if _, err := reg.Execute(context.Background(), "search", map[string]any{"limit": "many"}); err != nil {
+		t.Fatalf("expected no validation by default, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
+
+func TestValidateArgs_ToolsCallInvalidParams(t *testing.T) {
+	reg, _ := newValidatingRegistry(t, true)
+	params, _ := json.Marshal(map[string]any{"name": "search", "arguments": map[string]any{"query": 42}})
+
+	resp := reg.HandleRequest(context.Background(), MCPRequest{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	if resp.Error == nil || resp.Error.Code != ErrCodeInvalidParams {
+		t.Fatalf("expected InvalidParams error, got %+v", resp.Error)
+	}
+	data, err := json.Marshal(resp.Error.Data)
+	if err != nil {
+		t.Fatalf("marshal data: %v", err)
+	}
+	var decoded ArgsError
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal data: %v", err)
+	}
+	if decoded.ToolID != "search" || len(decoded.Issues) != 1 || decoded.Issues[0].Path != "/query" {
+		t.Errorf("data = %s", data)
+	}
+}
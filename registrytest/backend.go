@@ -0,0 +1,328 @@
+package registrytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jonwraymond/tooldiscovery/registry"
+)
+
+// HandlerFunc computes a tool result from call arguments. A returned error is
+// reported to the caller as a tool error (IsError) with the error text.
+type HandlerFunc func(ctx context.Context, args map[string]any) (any, error)
+
+// Call records one tool invocation received by a Backend.
+type Call struct {
+	Tool string
+	Args map[string]any
+}
+
+// Backend is a scriptable in-memory MCP server. Plug it into a registry with
+// Config, or use Transport directly with any MCP client.
+//
+// Every connection is served by the same underlying server, so tools added
+// or removed after the registry starts are visible to ResyncBackends.
+//
+// A Backend is safe for concurrent use.
+type Backend struct {
+	name   string
+	server *mcp.Server
+
+	mu         sync.Mutex
+	tools      map[string]*ToolScript
+	calls      []Call
+	connectErr error
+	connects   int
+}
+
+// NewBackend creates a backend with no tools.
+func NewBackend(name string) *Backend {
+	return &Backend{
+		name:   name,
+		server: mcp.NewServer(&mcp.Implementation{Name: name, Version: "test"}, nil),
+		tools:  make(map[string]*ToolScript),
+	}
+}
+
+// Name returns the backend name.
+func (b *Backend) Name() string {
+	return b.name
+}
+
+// Config returns a registry.BackendConfig that connects to this backend.
+func (b *Backend) Config() registry.BackendConfig {
+	return registry.BackendConfig{Name: b.name, Transport: b.Transport()}
+}
+
+// Transport returns an mcp.Transport connected to this backend. Unlike the
+// SDK's in-memory transports it may be connected repeatedly, so registry
+// reconnects work.
+func (b *Backend) Transport() mcp.Transport {
+	return backendTransport{b: b}
+}
+
+// AddTool registers a tool with an empty object input schema that returns
+// nil until scripted. Adding an existing name replaces its script.
+func (b *Backend) AddTool(name, description string) *ToolScript {
+	script := &ToolScript{
+		b: b,
+		tool: mcp.Tool{
+			Name:        name,
+			Description: description,
+			InputSchema: map[string]any{"type": "object"},
+		},
+	}
+	b.mu.Lock()
+	b.tools[name] = script
+	b.mu.Unlock()
+	script.publish()
+	return script
+}
+
+// RemoveTool unregisters a tool.
+func (b *Backend) RemoveTool(name string) {
+	b.mu.Lock()
+	delete(b.tools, name)
+	b.mu.Unlock()
+	b.server.RemoveTools(name)
+}
+
+// FailConnect makes subsequent connection attempts fail with err.
+// A nil err restores normal connections.
+func (b *Backend) FailConnect(err error) {
+	b.mu.Lock()
+	b.connectErr = err
+	b.mu.Unlock()
+}
+
+// Disconnect closes every open session, simulating a backend that went away.
+// Clients may reconnect unless FailConnect is set.
+func (b *Backend) Disconnect() error {
+	var errs []error
+	for session := range b.server.Sessions() {
+		if err := session.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Connects returns the number of successful connections.
+func (b *Backend) Connects() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.connects
+}
+
+// Calls returns the tool calls received, in order.
+func (b *Backend) Calls() []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]Call, len(b.calls))
+	copy(out, b.calls)
+	return out
+}
+
+// CallCount returns the number of calls received for tool.
+func (b *Backend) CallCount(tool string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, call := range b.calls {
+		if call.Tool == tool {
+			n++
+		}
+	}
+	return n
+}
+
+// ToolNames returns the names of the registered tools, sorted.
+func (b *Backend) ToolNames() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	names := make([]string, 0, len(b.tools))
+	for name := range b.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type backendTransport struct {
+	b *Backend
+}
+
+func (t backendTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	t.b.mu.Lock()
+	err := t.b.connectErr
+	t.b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := t.b.server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, err
+	}
+	conn, err := clientTransport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.b.mu.Lock()
+	t.b.connects++
+	t.b.mu.Unlock()
+	return conn, nil
+}
+
+// ToolScript scripts the behavior of one fake tool. Methods return the
+// script so they can be chained, and may be called while clients are
+// connected.
+type ToolScript struct {
+	b    *Backend
+	tool mcp.Tool
+
+	// Guarded by b.mu.
+	result   any
+	errMsg   string
+	failures []string
+	latency  time.Duration
+	handler  HandlerFunc
+}
+
+// WithInputSchema replaces the tool's input schema. It must have type
+// "object".
+func (s *ToolScript) WithInputSchema(schema map[string]any) *ToolScript {
+	s.b.mu.Lock()
+	s.tool.InputSchema = schema
+	s.b.mu.Unlock()
+	s.publish()
+	return s
+}
+
+// Returns makes the tool succeed with value. Strings are returned as text
+// content; other values as structured content.
+func (s *ToolScript) Returns(value any) *ToolScript {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.result, s.errMsg, s.handler = value, "", nil
+	return s
+}
+
+// ReturnsError makes every call fail with a tool error carrying msg.
+func (s *ToolScript) ReturnsError(msg string) *ToolScript {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.errMsg = msg
+	return s
+}
+
+// FailNext makes the next n calls fail with a tool error carrying msg,
+// after which the scripted behavior resumes.
+func (s *ToolScript) FailNext(n int, msg string) *ToolScript {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	for range n {
+		s.failures = append(s.failures, msg)
+	}
+	return s
+}
+
+// WithLatency delays every call by d. A call whose context ends first
+// returns the context error as a protocol error.
+func (s *ToolScript) WithLatency(d time.Duration) *ToolScript {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.latency = d
+	return s
+}
+
+// Handle computes results with fn instead of a canned value.
+func (s *ToolScript) Handle(fn HandlerFunc) *ToolScript {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	s.handler, s.result, s.errMsg = fn, nil, ""
+	return s
+}
+
+func (s *ToolScript) publish() {
+	s.b.mu.Lock()
+	tool := s.tool
+	s.b.mu.Unlock()
+	s.b.server.AddTool(&tool, s.serve)
+}
+
+func (s *ToolScript) serve(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args map[string]any
+	if raw := req.Params.Arguments; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, err
+		}
+	}
+
+	b := s.b
+	b.mu.Lock()
+	b.calls = append(b.calls, Call{Tool: s.tool.Name, Args: maps.Clone(args)})
+	latency := s.latency
+	var failure string
+	if len(s.failures) > 0 {
+		failure, s.failures = s.failures[0], s.failures[1:]
+	}
+	result, errMsg, handler := s.result, s.errMsg, s.handler
+	b.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	switch {
+	case failure != "":
+		return toolError(failure), nil
+	case errMsg != "":
+		return toolError(errMsg), nil
+	case handler != nil:
+		value, err := handler(ctx, args)
+		if err != nil {
+			return toolError(err.Error()), nil
+		}
+		return toolResult(value)
+	default:
+		return toolResult(result)
+	}
+}
+
+func toolError(msg string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: msg}},
+	}
+}
+
+func toolResult(value any) (*mcp.CallToolResult, error) {
+	switch v := value.(type) {
+	case nil:
+		return &mcp.CallToolResult{Content: []mcp.Content{}}, nil
+	case string:
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: v}}}, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(encoded)}},
+		StructuredContent: value,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package registrytest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/registry"
+)
+
+func startRegistry(t *testing.T, backends ...*Backend) *registry.Registry {
+	t.Helper()
+	reg := registry.New(registry.Config{})
+	for _, b := range backends {
+		if err := reg.RegisterMCP(b.Config()); err != nil {
+			t.Fatalf("RegisterMCP failed: %v", err)
+		}
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() { _ = reg.Stop() })
+	return reg
+}
+
+func TestBackend_ToolsAndResults(t *testing.T) {
+	backend := NewBackend("fake")
+	backend.AddTool("create_issue", "Create an issue").Returns(map[string]any{"number": 42})
+	backend.AddTool("greet", "Greet").Returns("hello")
+	backend.AddTool("echo", "Echo").Handle(func(_ context.Context, args map[string]any) (any, error) {
+		return map[string]any{"echo": args["msg"]}, nil
+	})
+
+	reg := startRegistry(t, backend)
+	ctx := context.Background()
+
+	result, err := reg.Execute(ctx, "create_issue", map[string]any{"title": "bug"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]any{"number": float64(42)}) {
+		t.Fatalf("result = %#v", result)
+	}
+	if result, err := reg.Execute(ctx, "greet", nil); err != nil || result != "hello" {
+		t.Fatalf("greet = %v, %v", result, err)
+	}
+	if result, err := reg.Execute(ctx, "echo", map[string]any{"msg": "hi"}); err != nil || !reflect.DeepEqual(result, map[string]any{"echo": "hi"}) {
+		t.Fatalf("echo = %v, %v", result, err)
+	}
+
+	calls := backend.Calls()
+	if len(calls) != 3 || calls[0].Tool != "create_issue" || calls[0].Args["title"] != "bug" {
+		t.Fatalf("calls = %+v", calls)
+	}
+	if backend.CallCount("greet") != 1 {
+		t.Fatalf("CallCount(greet) = %d, want 1", backend.CallCount("greet"))
+	}
+}
+
+func TestBackend_InducedFailures(t *testing.T) {
+	backend := NewBackend("fake")
+	flaky := backend.AddTool("flaky", "Flaky").Returns("ok").FailNext(2, "rate limited")
+	backend.AddTool("broken", "Broken").ReturnsError("boom")
+	backend.AddTool("slow", "Slow").Returns("done").WithLatency(time.Second)
+
+	reg := startRegistry(t, backend)
+	ctx := context.Background()
+
+	for i := range 2 {
+		_, err := reg.Execute(ctx, "flaky", nil)
+		if !errors.Is(err, registry.ErrExecutionFailed) || !strings.Contains(err.Error(), "rate limited") {
+			t.Fatalf("flaky call %d err = %v, want rate limited", i, err)
+		}
+	}
+	if result, err := reg.Execute(ctx, "flaky", nil); err != nil || result != "ok" {
+		t.Fatalf("flaky recovered = %v, %v", result, err)
+	}
+	flaky.Returns("ok again")
+	if result, _ := reg.Execute(ctx, "flaky", nil); result != "ok again" {
+		t.Fatalf("rescripted result = %v", result)
+	}
+
+	if _, err := reg.Execute(ctx, "broken", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("broken err = %v, want boom", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := reg.Execute(timeoutCtx, "slow", nil); err == nil {
+		t.Fatal("slow call succeeded, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("slow call took %v, want it to honor the deadline", elapsed)
+	}
+}
+
+func TestBackend_ConnectFailureAndResync(t *testing.T) {
+	backend := NewBackend("fake")
+	backend.AddTool("a", "Tool A")
+
+	refused := errors.New("connection refused")
+	backend.FailConnect(refused)
+	reg := registry.New(registry.Config{})
+	if err := reg.RegisterMCP(backend.Config()); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); !errors.Is(err, refused) {
+		t.Fatalf("Start err = %v, want connection refused", err)
+	}
+
+	backend.FailConnect(nil)
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+	if backend.Connects() != 1 {
+		t.Fatalf("Connects() = %d, want 1", backend.Connects())
+	}
+
+	backend.AddTool("b", "Tool B")
+	backend.RemoveTool("a")
+	if err := reg.ResyncBackends(context.Background()); err != nil {
+		t.Fatalf("ResyncBackends failed: %v", err)
+	}
+	if _, err := reg.GetTool(context.Background(), "b"); err != nil {
+		t.Fatalf("GetTool(b) after resync: %v", err)
+	}
+	if _, err := reg.GetTool(context.Background(), "a"); err == nil {
+		t.Fatal("GetTool(a) succeeded after the backend removed it")
+	}
+	if got := backend.ToolNames(); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("ToolNames() = %v, want [b]", got)
+	}
+
+	if err := backend.Disconnect(); err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	if _, err := reg.Execute(context.Background(), "b", nil); err == nil {
+		t.Fatal("Execute succeeded after Disconnect")
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBackend_RefreshInterval(t *testing.T) {
+	backend := NewBackend("fake")
+	backend.AddTool("a", "Tool A").Returns("a")
+
+	cfg := backend.Config()
+	cfg.RefreshInterval = 20 * time.Millisecond
+	cfg.RetryInterval = 5 * time.Millisecond
+	cfg.MaxRetryInterval = 20 * time.Millisecond
+	reg := registry.New(registry.Config{})
+	if err := reg.RegisterMCP(cfg); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = reg.Stop() }()
+	ctx := context.Background()
+
+	backend.AddTool("b", "Tool B").Returns("b")
+	backend.RemoveTool("a")
+	waitFor(t, "refresh", func() bool {
+		_, errB := reg.GetTool(ctx, "b")
+		_, errA := reg.GetTool(ctx, "a")
+		return errB == nil && errA != nil
+	})
+
+	refused := errors.New("connection refused")
+	backend.FailConnect(refused)
+	if err := backend.Disconnect(); err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	waitFor(t, "failed reconnect", func() bool {
+		h := reg.Health().Backends[0]
+		return !h.Connected && strings.Contains(h.LastError, "connection refused")
+	})
+	if _, err := reg.GetTool(ctx, "b"); err != nil {
+		t.Fatalf("GetTool(b) while disconnected: %v", err)
+	}
+
+	backend.FailConnect(nil)
+	waitFor(t, "reconnect", func() bool {
+		h := reg.Health().Backends[0]
+		return h.Connected && h.LastError == ""
+	})
+	if result, err := reg.Execute(ctx, "b", nil); err != nil || result != "b" {
+		t.Fatalf("Execute after reconnect = %v, %v", result, err)
+	}
+	if backend.Connects() != 2 {
+		t.Fatalf("Connects() = %d, want 2", backend.Connects())
+	}
+}
+
+func TestBackend_RefreshLoopStops(t *testing.T) {
+	backend := NewBackend("fake")
+	backend.AddTool("a", "Tool A")
+
+	cfg := backend.Config()
+	cfg.RefreshInterval = 10 * time.Millisecond
+	cfg.RetryInterval = time.Millisecond
+	reg := registry.New(registry.Config{})
+	if err := reg.RegisterMCP(cfg); err != nil {
+		t.Fatalf("RegisterMCP failed: %v", err)
+	}
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := reg.UnregisterMCP("fake"); err != nil {
+		t.Fatalf("UnregisterMCP failed: %v", err)
+	}
+	if err := reg.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	connects := backend.Connects()
+	time.Sleep(50 * time.Millisecond)
+	if backend.Connects() != connects {
+		t.Fatalf("backend reconnected after UnregisterMCP and Stop")
+	}
+}
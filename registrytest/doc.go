@@ -0,0 +1,30 @@
+// Package registrytest provides a scriptable in-memory MCP backend for
+// integration tests of registry wiring.
+//
+// # Usage
+//
+// Define tools and their behavior, then register the fake like any other
+// backend:
+//
+//	backend := registrytest.NewBackend("github")
+//	backend.AddTool("create_issue", "Create an issue").
+//	    Returns(map[string]any{"number": 42})
+//	backend.AddTool("search", "Search code").
+//	    FailNext(2, "rate limited").
+//	    WithLatency(50 * time.Millisecond)
+//
+//	reg := registry.New(registry.Config{})
+//	_ = reg.RegisterMCP(backend.Config())
+//	_ = reg.Start(ctx)
+//
+//	result, err := reg.Execute(ctx, "create_issue", map[string]any{"title": "bug"})
+//	calls := backend.Calls() // [{create_issue map[title:bug]}]
+//
+// # Induced Failures
+//
+// Tools can fail every call (ReturnsError), fail a fixed number of calls
+// before recovering (FailNext), or respond slowly (WithLatency). The backend
+// can refuse connections (FailConnect) or drop open sessions (Disconnect).
+// Tools added or removed at runtime are picked up by
+// registry.ResyncBackends.
+package registrytest
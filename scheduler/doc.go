@@ -0,0 +1,41 @@
+// Package scheduler runs periodic catalog maintenance jobs.
+//
+// Long-running deployments drift: MCP backends add and remove tools,
+// documentation outlives the tools it describes, and examples stop matching
+// their schemas. The scheduler runs reconciliation work on cron-style
+// schedules, records per-job metrics, and exposes manual triggers.
+//
+// # Jobs
+//
+// A [Job] pairs a name and schedule with a [JobFunc]. Registry and Discovery
+// expose maintenance operations with matching signatures:
+//
+//	s := scheduler.New(scheduler.Options{})
+//	_ = s.Add(scheduler.Job{Name: "resync-backends", Spec: "*/5 * * * *", Run: reg.ResyncBackends})
+//	_ = s.Add(scheduler.Job{Name: "prune-docs", Spec: "@hourly", Run: disc.PruneOrphanDocs})
+//	_ = s.Add(scheduler.Job{Name: "verify-examples", Spec: "@daily", Run: disc.VerifyExamples})
+//	_ = s.Add(scheduler.Job{Name: "compact-index", Spec: "@every 10m", Run: func(context.Context) error {
+//	    reg.Refresh()
+//	    return nil
+//	}})
+//	if err := s.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer s.Stop()
+//
+// # Schedules
+//
+// [Parse] accepts five-field cron expressions (minute hour day-of-month month
+// day-of-week), descriptors such as @hourly and @daily, and "@every <duration>".
+// Custom [Schedule] implementations can be supplied through Job.Schedule.
+//
+// # Triggers and Metrics
+//
+// [Scheduler.Trigger] runs a job immediately and returns its error.
+// [Scheduler.Stats] reports run, failure, and skip counts, the last error and
+// duration, and the next scheduled run for every job.
+//
+// # Thread Safety
+//
+// Scheduler is safe for concurrent use. Runs of the same job never overlap.
+package scheduler
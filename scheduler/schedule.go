@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the next activation time for a job.
+type Schedule interface {
+	// Next returns the first activation time strictly after t.
+	// A zero time means the schedule never fires again.
+	Next(t time.Time) time.Time
+}
+
+// Every returns a Schedule that fires at a fixed interval.
+func Every(d time.Duration) Schedule {
+	return intervalSchedule{interval: d}
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	if s.interval <= 0 {
+		return time.Time{}
+	}
+	return t.Add(s.interval)
+}
+
+// Parse parses a cron-style schedule specification.
+//
+// Supported forms:
+//   - Five space-separated fields: minute hour day-of-month month day-of-week.
+//     Each field accepts "*", values, ranges ("1-5"), lists ("1,15"), and
+//     steps ("*/15", "0-30/10"). Day-of-week uses 0-6 with 0 as Sunday
+//     (7 is also accepted for Sunday).
+//   - Descriptors: @yearly (@annually), @monthly, @weekly, @daily (@midnight),
+//     and @hourly.
+//   - Intervals: "@every <duration>", where duration uses time.ParseDuration.
+//
+// Returns ErrInvalidSchedule for malformed specifications.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("%w: empty spec", ErrInvalidSchedule)
+	}
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: invalid interval %q", ErrInvalidSchedule, rest)
+		}
+		return Every(d), nil
+	}
+
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var cs cronSchedule
+	var err error
+	if cs.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if cs.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if cs.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if cs.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if cs.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+	cs.domAny = fields[2] == "*"
+	cs.dowAny = fields[4] == "*"
+	return cs, nil
+}
+
+// MustParse is like Parse but panics on error.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// cronSchedule stores each field as a bitmask of allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// maxSearchYears bounds Next for schedules that can never match (e.g. Feb 30).
+const maxSearchYears = 5
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies standard cron semantics: when both day-of-month and
+// day-of-week are restricted, a day matching either field is accepted.
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// parseField parses a single cron field into a bitmask.
+func parseField(field string, minVal, maxVal int) (uint64, error) {
+	var mask uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: invalid step %q", ErrInvalidSchedule, part)
+			}
+			step = n
+		}
+
+		lo, hi := minVal, maxVal
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("%w: invalid range %q", ErrInvalidSchedule, part)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%w: invalid value %q", ErrInvalidSchedule, part)
+			}
+			lo = n
+			if !hasStep {
+				hi = n
+			}
+		}
+
+		if lo < minVal || hi > maxVal || lo > hi {
+			return 0, fmt.Errorf("%w: %q out of range %d-%d", ErrInvalidSchedule, part, minVal, maxVal)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse_Next(t *testing.T) {
+	base := time.Date(2026, time.March, 10, 14, 7, 30, 0, time.UTC) // Tuesday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, time.March, 10, 14, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2026, time.March, 11, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 9 1,15 * *", time.Date(2026, time.March, 15, 9, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_DayOfMonthOrWeekday(t *testing.T) {
+	// Day 13 or any Friday: from Tue Mar 10 the first match is Fri Mar 13.
+	s := MustParse("0 0 13 * 5")
+	base := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2026, time.March, 13, 0, 0, 0, 0, time.UTC)
+	if got := s.Next(base); !got.Equal(want) {
+		t.Fatalf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParse_Impossible(t *testing.T) {
+	s := MustParse("0 0 30 2 *")
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Fatalf("expected zero time for impossible schedule, got %v", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every nope", "@every -1s"} {
+		if _, err := Parse(spec); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidSchedule", spec, err)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Error values for scheduler operations.
+var (
+	ErrInvalidSchedule = errors.New("scheduler: invalid schedule")
+	ErrInvalidJob      = errors.New("scheduler: job requires a name and run function")
+	ErrJobExists       = errors.New("scheduler: job already registered")
+	ErrJobNotFound     = errors.New("scheduler: job not found")
+	ErrJobRunning      = errors.New("scheduler: job already running")
+	ErrAlreadyStarted  = errors.New("scheduler: already started")
+)
+
+// JobFunc performs one run of a job.
+type JobFunc func(ctx context.Context) error
+
+// Job is a named unit of periodic work.
+type Job struct {
+	// Name uniquely identifies the job.
+	Name string
+
+	// Spec is a cron-style specification parsed with Parse.
+	// Ignored when Schedule is set. When both are empty the job only runs
+	// through Trigger.
+	Spec string
+
+	// Schedule overrides Spec with a custom schedule.
+	Schedule Schedule
+
+	// Run performs the work.
+	Run JobFunc
+
+	// Timeout bounds each run. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// JobStats reports per-job metrics.
+type JobStats struct {
+	Name         string        `json:"name"`
+	Spec         string        `json:"spec,omitempty"`
+	Runs         uint64        `json:"runs"`
+	Failures     uint64        `json:"failures"`
+	Skipped      uint64        `json:"skipped"`
+	Triggered    uint64        `json:"triggered"`
+	Running      bool          `json:"running"`
+	LastRun      time.Time     `json:"lastRun,omitzero"`
+	LastDuration time.Duration `json:"lastDuration"`
+	LastError    string        `json:"lastError,omitempty"`
+	NextRun      time.Time     `json:"nextRun,omitzero"`
+}
+
+// Options configures a Scheduler.
+type Options struct {
+	// Now returns the current time used for schedule calculations.
+	// Default: time.Now.
+	Now func() time.Time
+
+	// OnError is called when a run returns an error or panics.
+	OnError func(job string, err error)
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	stats    JobStats
+	stop     chan struct{}
+}
+
+// Scheduler runs jobs on cron-style schedules and on demand.
+//
+// Scheduled runs of a job never overlap: a tick that fires while the previous
+// run is still in progress is skipped and counted in JobStats.Skipped.
+type Scheduler struct {
+	opts Options
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	loops sync.WaitGroup
+	runs  sync.WaitGroup
+}
+
+// New creates a Scheduler. Jobs run once Start is called.
+func New(opts Options) *Scheduler {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Scheduler{
+		opts: opts,
+		jobs: make(map[string]*entry),
+	}
+}
+
+// Add registers a job. If the scheduler is running, the job is scheduled
+// immediately.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return ErrInvalidJob
+	}
+	sched := job.Schedule
+	if sched == nil && job.Spec != "" {
+		parsed, err := Parse(job.Spec)
+		if err != nil {
+			return err
+		}
+		sched = parsed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+	}
+	e := &entry{
+		job:      job,
+		schedule: sched,
+		stats:    JobStats{Name: job.Name, Spec: job.Spec},
+	}
+	s.jobs[job.Name] = e
+	if s.started {
+		s.startLoopLocked(e)
+	}
+	return nil
+}
+
+// Remove unregisters a job. A run already in progress is allowed to finish.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if e.stop != nil {
+		close(e.stop)
+	}
+	delete(s.jobs, name)
+	return nil
+}
+
+// Start begins running scheduled jobs. Runs are cancelled when ctx is done
+// or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrAlreadyStarted
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.jobs {
+		s.startLoopLocked(e)
+	}
+	return nil
+}
+
+// Stop cancels scheduled runs and waits for in-progress runs to return.
+// It is safe to call multiple times.
+func (s *Scheduler) Stop() error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.started = false
+	s.cancel()
+	for _, e := range s.jobs {
+		e.stop = nil
+		e.stats.NextRun = time.Time{}
+	}
+	s.mu.Unlock()
+
+	s.loops.Wait()
+	s.runs.Wait()
+	return nil
+}
+
+// Trigger runs a job immediately and returns its error. It runs whether or
+// not the scheduler is started.
+//
+// Returns ErrJobNotFound for unknown jobs and ErrJobRunning if the job is
+// already in progress.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if e.stats.Running {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrJobRunning, name)
+	}
+	e.stats.Running = true
+	e.stats.Triggered++
+	s.runs.Add(1)
+	s.mu.Unlock()
+
+	return s.run(ctx, e)
+}
+
+// Stats returns metrics for every job, sorted by name.
+func (s *Scheduler) Stats() []JobStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobStats, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		out = append(out, e.stats)
+	}
+	slices.SortFunc(out, func(a, b JobStats) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
+
+// JobStats returns metrics for a single job.
+func (s *Scheduler) JobStats(name string) (JobStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.jobs[name]
+	if !ok {
+		return JobStats{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	return e.stats, nil
+}
+
+// startLoopLocked launches the timer loop for a scheduled job.
+// Caller must hold s.mu.
+func (s *Scheduler) startLoopLocked(e *entry) {
+	if e.schedule == nil {
+		return
+	}
+	e.stop = make(chan struct{})
+	s.loops.Add(1)
+	go s.loop(s.ctx, e, e.stop)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry, stop <-chan struct{}) {
+	defer s.loops.Done()
+	for {
+		now := s.opts.Now()
+		next := e.schedule.Next(now)
+		s.mu.Lock()
+		if ctx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		e.stats.NextRun = next
+		s.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		if e.stats.Running {
+			e.stats.Skipped++
+			s.mu.Unlock()
+			continue
+		}
+		e.stats.Running = true
+		s.runs.Add(1)
+		s.mu.Unlock()
+
+		// Run in the background so ticks keep firing, and are skipped,
+		// while a slow run is in progress.
+		go func() { _ = s.run(ctx, e) }()
+	}
+}
+
+// run executes one job run and records its metrics. The caller must have
+// marked the job running and added to s.runs.
+func (s *Scheduler) run(ctx context.Context, e *entry) error {
+	defer s.runs.Done()
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	start := s.opts.Now()
+	err := safeRun(ctx, e.job.Run)
+	elapsed := s.opts.Now().Sub(start)
+
+	s.mu.Lock()
+	e.stats.Running = false
+	e.stats.Runs++
+	e.stats.LastRun = start
+	e.stats.LastDuration = elapsed
+	e.stats.LastError = ""
+	if err != nil {
+		e.stats.Failures++
+		e.stats.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil && s.opts.OnError != nil {
+		s.opts.OnError(e.job.Name, err)
+	}
+	return err
+}
+
+// safeRun calls fn and converts a panic into an error.
+func safeRun(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("scheduler: job panicked: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
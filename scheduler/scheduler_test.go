@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
+
+func TestScheduler_RunsOnSchedule(t *testing.T) {
+	s := New(Options{})
+	var runs atomic.Int32
+	if err := s.Add(Job{Name: "tick", Spec: "@every 10ms", Run: func(context.Context) error {
+		runs.Add(1)
+		return nil
+	}}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := s.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Fatalf("expected ErrAlreadyStarted, got %v", err)
+	}
+	waitFor(t, func() bool { return runs.Load() >= 3 })
+	_ = s.Stop()
+
+	stats, err := s.JobStats("tick")
+	if err != nil {
+		t.Fatalf("JobStats failed: %v", err)
+	}
+	if stats.Runs < 3 || stats.Failures != 0 || stats.LastRun.IsZero() {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !stats.NextRun.IsZero() {
+		t.Errorf("expected no next run after Stop, got %v", stats.NextRun)
+	}
+
+	after := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != after {
+		t.Error("job ran after Stop")
+	}
+}
+
+func TestScheduler_TriggerAndFailures(t *testing.T) {
+	var reported atomic.Int32
+	s := New(Options{OnError: func(string, error) { reported.Add(1) }})
+	boom := errors.New("boom")
+	_ = s.Add(Job{Name: "manual", Run: func(context.Context) error { return boom }})
+	_ = s.Add(Job{Name: "panics", Run: func(context.Context) error { panic("bad") }})
+
+	if err := s.Trigger(context.Background(), "manual"); !errors.Is(err, boom) {
+		t.Fatalf("Trigger error = %v, want boom", err)
+	}
+	if err := s.Trigger(context.Background(), "panics"); err == nil {
+		t.Fatal("expected panic to surface as error")
+	}
+	if err := s.Trigger(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+
+	stats := s.Stats()
+	if len(stats) != 2 || stats[0].Name != "manual" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats[0].Runs != 1 || stats[0].Failures != 1 || stats[0].Triggered != 1 || stats[0].LastError != "boom" {
+		t.Errorf("unexpected manual stats: %+v", stats[0])
+	}
+	if reported.Load() != 2 {
+		t.Errorf("OnError called %d times, want 2", reported.Load())
+	}
+}
+
+func TestScheduler_NoOverlap(t *testing.T) {
+	s := New(Options{})
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	_ = s.Add(Job{Name: "slow", Run: func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}})
+
+	go func() { _ = s.Trigger(context.Background(), "slow") }()
+	<-started
+	if err := s.Trigger(context.Background(), "slow"); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("expected ErrJobRunning, got %v", err)
+	}
+	close(release)
+	waitFor(t, func() bool {
+		st, _ := s.JobStats("slow")
+		return !st.Running && st.Runs == 1
+	})
+}
+
+func TestScheduler_SkipsOverlappingTicks(t *testing.T) {
+	s := New(Options{})
+	release := make(chan struct{})
+	var runs atomic.Int32
+	_ = s.Add(Job{Name: "slow", Spec: "@every 5ms", Run: func(ctx context.Context) error {
+		runs.Add(1)
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}})
+	_ = s.Start(context.Background())
+	waitFor(t, func() bool {
+		st, _ := s.JobStats("slow")
+		return st.Skipped >= 2
+	})
+	if runs.Load() != 1 {
+		t.Errorf("runs = %d, want 1 while the first run was in progress", runs.Load())
+	}
+	close(release)
+	_ = s.Stop()
+}
+
+func TestScheduler_AddRemove(t *testing.T) {
+	s := New(Options{})
+	if err := s.Add(Job{Name: "x"}); !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected ErrInvalidJob, got %v", err)
+	}
+	noop := func(context.Context) error { return nil }
+	if err := s.Add(Job{Name: "x", Spec: "bogus", Run: noop}); !errors.Is(err, ErrInvalidSchedule) {
+		t.Fatalf("expected ErrInvalidSchedule, got %v", err)
+	}
+	_ = s.Add(Job{Name: "x", Run: noop})
+	if err := s.Add(Job{Name: "x", Run: noop}); !errors.Is(err, ErrJobExists) {
+		t.Fatalf("expected ErrJobExists, got %v", err)
+	}
+	if err := s.Remove("x"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := s.Remove("x"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestScheduler_TimeoutCancelsRun(t *testing.T) {
+	s := New(Options{})
+	_ = s.Add(Job{Name: "bounded", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	if err := s.Trigger(context.Background(), "bounded"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+}
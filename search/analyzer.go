@@ -0,0 +1,151 @@
+package search
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/v2/mapping"
+	blevesearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// Analyzers for BM25Config.Analyzer and NamespaceAnalyzers. Any analyzer
+// registered with Bleve may also be named.
+const (
+	// AnalyzerStandard segments Unicode words, lowercases, and drops
+	// English stop words. It is Bleve's default.
+	AnalyzerStandard = "standard"
+
+	// AnalyzerEnglish is AnalyzerStandard plus possessive removal and
+	// stemming, so "issues" matches "issue". It suits prose descriptions.
+	AnalyzerEnglish = "en"
+
+	// AnalyzerCode splits on every character that is not a letter or digit,
+	// so "create_issue", "kube-ctl", and "git.push" yield separate words,
+	// and lowercases without dropping stop words or stemming. It suits
+	// CLI-style tool names.
+	AnalyzerCode = "code"
+)
+
+// codeTokenizer backs AnalyzerCode.
+const codeTokenizer = "code"
+
+// analyzedField is the sub-document holding the weighted text of documents
+// whose namespace has an analyzer override, with one field per analyzer.
+const analyzedField = "analyzed"
+
+// analyzedContent is a content field analyzed with a namespace override.
+type analyzedContent struct {
+	key      string // field name within analyzedField
+	analyzer string
+}
+
+func (c analyzedContent) path() string {
+	return analyzedField + "." + c.key
+}
+
+// newAnalyzedContents returns a content field for each distinct override
+// analyzer that differs from the default, ordered by analyzer name.
+func newAnalyzedContents(cfg BM25Config) map[string]analyzedContent {
+	defaultAnalyzer := cmp.Or(cfg.Analyzer, AnalyzerStandard)
+	var names []string
+	for _, analyzer := range cfg.NamespaceAnalyzers {
+		if analyzer != "" && analyzer != defaultAnalyzer && !slices.Contains(names, analyzer) {
+			names = append(names, analyzer)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	slices.Sort(names)
+	contents := make(map[string]analyzedContent, len(names))
+	for i, analyzer := range names {
+		contents[analyzer] = analyzedContent{key: "f" + strconv.Itoa(i), analyzer: analyzer}
+	}
+	return contents
+}
+
+// contentFor returns the analyzed content field for documents in namespace,
+// reporting false when they use the default content field.
+func (s *BM25Searcher) contentFor(namespace string) (analyzedContent, bool) {
+	if len(s.analyzed) == 0 {
+		return analyzedContent{}, false
+	}
+	c, ok := s.analyzed[s.cfg.NamespaceAnalyzers[namespace]]
+	return c, ok
+}
+
+// indexMapping returns the Bleve mapping for the configured analyzers.
+func (s *BM25Searcher) indexMapping() (mapping.IndexMapping, error) {
+	m := bleve.NewIndexMapping()
+	if err := m.AddCustomTokenizer(codeTokenizer, map[string]any{
+		"type":   regexp.Name,
+		"regexp": `[\p{L}\p{N}]+`,
+	}); err != nil {
+		return nil, err
+	}
+	if err := m.AddCustomAnalyzer(AnalyzerCode, map[string]any{
+		"type":          custom.Name,
+		"tokenizer":     codeTokenizer,
+		"token_filters": []any{lowercase.Name},
+	}); err != nil {
+		return nil, err
+	}
+	if s.cfg.Analyzer != "" {
+		m.DefaultAnalyzer = s.cfg.Analyzer
+	}
+	if len(s.analyzed) > 0 {
+		sub := bleve.NewDocumentMapping()
+		for _, c := range s.analyzed {
+			field := bleve.NewTextFieldMapping()
+			field.Analyzer = c.analyzer
+			field.IncludeTermVectors = true
+			sub.AddFieldMappingsAt(c.key, field)
+		}
+		m.DefaultMapping.AddSubDocumentMapping(analyzedField, sub)
+	}
+	return m, nil
+}
+
+// contentQuery matches text against the content fields, analyzing it with
+// each field's analyzer.
+func (s *BM25Searcher) contentQuery(text string) query.Query {
+	mq := bleve.NewMatchQuery(text)
+	mq.SetField(contentField)
+	if len(s.analyzed) == 0 {
+		return mq
+	}
+	queries := []query.Query{mq}
+	for _, analyzer := range slices.Sorted(maps.Keys(s.analyzed)) {
+		c := s.analyzed[analyzer]
+		q := bleve.NewMatchQuery(text)
+		q.SetField(c.path())
+		q.Analyzer = c.analyzer
+		queries = append(queries, q)
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// contentLocations merges the term locations recorded in every content
+// field of a hit.
+func (s *BM25Searcher) contentLocations(locations blevesearch.FieldTermLocationMap) blevesearch.TermLocationMap {
+	if len(s.analyzed) == 0 {
+		return locations[contentField]
+	}
+	merged := maps.Clone(locations[contentField])
+	for _, c := range s.analyzed {
+		for term, locs := range locations[c.path()] {
+			if merged == nil {
+				merged = make(blevesearch.TermLocationMap)
+			}
+			merged[term] = append(merged[term], locs...)
+		}
+	}
+	return merged
+}
@@ -3,6 +3,7 @@ package search
 
 import (
 	"fmt"
+	"maps"
 	"sort"
 	"strings"
 	"sync"
@@ -22,22 +23,62 @@ type BM25Config struct {
 	// Safety / performance controls.
 	MaxDocs       int // 0 = unlimited
 	MaxDocTextLen int // 0 = unlimited
+
+	// Synonyms expands query terms before ranking, so abbreviations such as
+	// "k8s" also match "kubernetes". Copied at construction.
+	Synonyms Synonyms
+
+	// FuzzyFallback ranks documents by fuzzy and prefix matches of the query
+	// against tool names and namespaces when BM25 finds nothing, so queries
+	// with small typos ("kubctl get pods") still return candidates.
+	// Fallback results have ExplainedResult.Fuzzy set.
+	FuzzyFallback bool
+
+	// Incremental applies document changes to the existing Bleve index as
+	// per-document adds, updates, and deletes instead of rebuilding it, so
+	// large catalogs pay only for the documents that changed. The index is
+	// still rebuilt when it does not exist yet or when more than half of the
+	// documents changed.
+	Incremental bool
+
+	// Analyzer names the Bleve analyzer applied to the ranked text of
+	// documents, such as AnalyzerEnglish or AnalyzerCode.
+	// Default: AnalyzerStandard.
+	Analyzer string
+
+	// NamespaceAnalyzers overrides Analyzer for documents in the named
+	// namespaces, for example AnalyzerCode for "github" and "git", whose
+	// tools have CLI-style names. Queries are analyzed once per analyzer in
+	// use. Unknown analyzer names make Search fail. Copied at construction.
+	NamespaceAnalyzers map[string]string
+
+	// BooleanQueries parses queries with semantic.ParseQuery, so AND, OR,
+	// NOT, quoted phrases, and field prefixes filter results and the
+	// remaining words are ranked. Invalid queries return
+	// semantic.ErrInvalidQuery.
+	BooleanQueries bool
 }
 
 // BM25Searcher implements index.Searcher using BM25 ranking.
 type BM25Searcher struct {
-	cfg BM25Config
+	cfg      BM25Config
+	analyzed map[string]analyzedContent // by analyzer; nil without overrides
 
 	mu              sync.RWMutex
 	index           bleve.Index
 	idToSummary     map[string]index.Summary
 	lastFingerprint string
 	indexBuildCount int
+
+	// Incremental mode only.
+	docFingerprints  map[string]string
+	indexUpdateCount int
 }
 
 // Ensure interface compliance at compile time.
 var _ index.Searcher = (*BM25Searcher)(nil)
 var _ index.DeterministicSearcher = (*BM25Searcher)(nil)
+var _ index.ScoredSearcher = (*BM25Searcher)(nil)
 
 // NewBM25Searcher creates a new BM25-based searcher with the given config.
 // Zero values in config are replaced with sensible defaults.
@@ -52,12 +93,24 @@ func NewBM25Searcher(cfg BM25Config) *BM25Searcher {
 	if cfg.TagsBoost == 0 {
 		cfg.TagsBoost = 2
 	}
+	cfg.Synonyms = cfg.Synonyms.Normalized()
+	cfg.NamespaceAnalyzers = maps.Clone(cfg.NamespaceAnalyzers)
 
 	return &BM25Searcher{
-		cfg: cfg,
+		cfg:      cfg,
+		analyzed: newAnalyzedContents(cfg),
 	}
 }
 
+// Config returns the configuration of the searcher, with defaults applied.
+// NewBM25Searcher(s.Config()) ranks like s with empty caches.
+func (s *BM25Searcher) Config() BM25Config {
+	cfg := s.cfg
+	cfg.Synonyms = cfg.Synonyms.Normalized()
+	cfg.NamespaceAnalyzers = maps.Clone(cfg.NamespaceAnalyzers)
+	return cfg
+}
+
 // Deterministic reports whether this searcher returns stable ordering.
 func (s *BM25Searcher) Deterministic() bool {
 	return true
@@ -71,6 +124,14 @@ func (s *BM25Searcher) IndexBuildCount() int {
 	return s.indexBuildCount
 }
 
+// IndexUpdateCount returns the number of incremental index updates applied
+// (see BM25Config.Incremental). This is useful for testing cache behavior.
+func (s *BM25Searcher) IndexUpdateCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.indexUpdateCount
+}
+
 // buildWeightedDoc creates a weighted document text for BM25 indexing.
 // It duplicates high-signal tokens according to their boost values to
 // bias ranking toward name, namespace, and tags.
@@ -115,13 +176,41 @@ func buildWeightedDoc(cfg BM25Config, doc index.SearchDoc) string {
 	return strings.Join(parts, " ")
 }
 
-// indexedDoc is the document structure indexed by Bleve.
+// contentField is the weighted field used for ranking.
+const contentField = "content"
+
+// indexedDoc is the document structure indexed by Bleve. Content carries the
+// boosted text used for ranking, or Analyzed does for namespaces with an
+// analyzer override.
 type indexedDoc struct {
-	Content string `json:"content"`
+	Content  string            `json:"content,omitempty"`
+	Analyzed map[string]string `json:"analyzed,omitempty"`
 }
 
 // Search performs a BM25-ranked search over the provided documents.
 func (s *BM25Searcher) Search(query string, limit int, docs []index.SearchDoc) ([]index.Summary, error) {
+	ranked, err := s.search(query, limit, docs, false)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]index.Summary, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.Summary
+	}
+	return results, nil
+}
+
+// search ranks docs for query. With explain set it also collects matched
+// terms and per-field highlight fragments for each result.
+func (s *BM25Searcher) search(query string, limit int, docs []index.SearchDoc, explain bool) ([]ExplainedResult, error) {
+	if s.cfg.BooleanQueries {
+		return s.searchBoolean(query, limit, docs, explain)
+	}
+	return s.rank(query, limit, docs, explain)
+}
+
+// rank implements search for a plain query.
+func (s *BM25Searcher) rank(query string, limit int, docs []index.SearchDoc, explain bool) ([]ExplainedResult, error) {
 	query = strings.TrimSpace(query)
 
 	// 1. Sort docs by ID FIRST for determinism (before any other operations)
@@ -138,19 +227,22 @@ func (s *BM25Searcher) Search(query string, limit int, docs []index.SearchDoc) (
 		if n > len(sortedDocs) {
 			n = len(sortedDocs)
 		}
-		results := make([]index.Summary, n)
+		if n < 0 {
+			n = 0
+		}
+		results := make([]ExplainedResult, n)
 		for i := range n {
-			results[i] = sortedDocs[i].Summary
+			results[i] = ExplainedResult{Summary: sortedDocs[i].Summary}
 		}
 		return results, nil
 	}
 
 	// 4. No docs means no results
 	if len(sortedDocs) == 0 {
-		return []index.Summary{}, nil
+		return []ExplainedResult{}, nil
 	}
 	if limit <= 0 {
-		return []index.Summary{}, nil
+		return []ExplainedResult{}, nil
 	}
 
 	// 5. Compute fingerprint from sortedDocs (already sorted)
@@ -161,9 +253,13 @@ func (s *BM25Searcher) Search(query string, limit int, docs []index.SearchDoc) (
 	needsRebuild := s.index == nil || s.lastFingerprint != fingerprint
 	s.mu.RUnlock()
 
-	// 7. Rebuild uses sortedDocs
+	// 7. Rebuild or update uses sortedDocs
 	if needsRebuild {
-		if err := s.rebuildIndex(sortedDocs, fingerprint); err != nil {
+		update := s.rebuildIndex
+		if s.cfg.Incremental {
+			update = s.updateIndex
+		}
+		if err := update(sortedDocs, fingerprint); err != nil {
 			return nil, err
 		}
 	}
@@ -172,60 +268,79 @@ func (s *BM25Searcher) Search(query string, limit int, docs []index.SearchDoc) (
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Normalize query
-	query = strings.ToLower(query)
+	// Normalize and expand query
+	query = ExpandQuery(strings.ToLower(query), s.cfg.Synonyms)
 
-	// 8. Search uses a plain match query to avoid query syntax injection.
-	matchQuery := bleve.NewMatchQuery(query)
-	matchQuery.SetField("content")
-	searchRequest := bleve.NewSearchRequest(matchQuery)
+	// 8. Search uses plain match queries to avoid query syntax injection.
+	searchRequest := bleve.NewSearchRequest(s.contentQuery(query))
 	if limit > len(sortedDocs) {
 		limit = len(sortedDocs)
 	}
 	searchRequest.Size = limit
 	searchRequest.SortBy([]string{"-_score", "_id"})
+	searchRequest.IncludeLocations = explain
 	searchResult, err := s.index.Search(searchRequest)
 	if err != nil {
 		return nil, err
 	}
 
 	// Collect hits with scores for deterministic tie-breaking
-	type scoredHit struct {
-		id    string
-		score float64
-	}
-	hits := make([]scoredHit, 0, len(searchResult.Hits))
+	hits := make([]ExplainedResult, 0, len(searchResult.Hits))
 	for _, hit := range searchResult.Hits {
-		if _, ok := s.idToSummary[hit.ID]; ok {
-			hits = append(hits, scoredHit{id: hit.ID, score: hit.Score})
+		summary, ok := s.idToSummary[hit.ID]
+		if !ok {
+			continue
+		}
+		result := ExplainedResult{Summary: summary, Score: hit.Score}
+		if explain {
+			result.MatchedTerms = matchedTerms(s.contentLocations(hit.Locations))
 		}
+		hits = append(hits, result)
 	}
 
 	// Sort: score DESC, then ID ASC for tie-breaking
 	sort.Slice(hits, func(i, j int) bool {
-		if hits[i].score != hits[j].score {
-			return hits[i].score > hits[j].score
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
 		}
-		return hits[i].id < hits[j].id
+		return hits[i].Summary.ID < hits[j].Summary.ID
 	})
 
-	// Apply limit and map to summaries
+	// Apply limit
 	if len(hits) > limit {
 		hits = hits[:limit]
 	}
-	results := make([]index.Summary, len(hits))
-	for i, hit := range hits {
-		results[i] = s.idToSummary[hit.id]
+	if len(hits) == 0 && s.cfg.FuzzyFallback {
+		return fuzzySearch(query, limit, sortedDocs), nil
+	}
+	if explain {
+		if err := s.highlight(query, hits, sortedDocs); err != nil {
+			return nil, err
+		}
 	}
 
-	return results, nil
+	return hits, nil
 }
 
 // rebuildIndex creates a new Bleve index from the given documents.
 func (s *BM25Searcher) rebuildIndex(docs []index.SearchDoc, fingerprint string) error {
+	var prints map[string]string
+	if s.cfg.Incremental {
+		prints = docFingerprints(docs)
+	}
+	return s.rebuildIndexWith(docs, fingerprint, prints)
+}
+
+// rebuildIndexWith rebuilds the index and records per-document fingerprints
+// for incremental updates; prints is nil outside incremental mode.
+func (s *BM25Searcher) rebuildIndexWith(docs []index.SearchDoc, fingerprint string, prints map[string]string) error {
 	// Build ID to Summary map and create in-memory Bleve index
 	idToSummary := make(map[string]index.Summary, len(docs))
-	index, err := bleve.NewMemOnly(bleve.NewIndexMapping())
+	indexMapping, err := s.indexMapping()
+	if err != nil {
+		return err
+	}
+	index, err := bleve.NewMemOnly(indexMapping)
 	if err != nil {
 		return err
 	}
@@ -234,8 +349,7 @@ func (s *BM25Searcher) rebuildIndex(docs []index.SearchDoc, fingerprint string)
 	batch := index.NewBatch()
 	for _, doc := range docs {
 		idToSummary[doc.ID] = doc.Summary
-		weightedText := buildWeightedDoc(s.cfg, doc)
-		if err := batch.Index(doc.ID, indexedDoc{Content: weightedText}); err != nil {
+		if err := batch.Index(doc.ID, s.newIndexedDoc(doc)); err != nil {
 			if cerr := index.Close(); cerr != nil {
 				return fmt.Errorf("%w; close index: %v", err, cerr)
 			}
@@ -274,11 +388,89 @@ func (s *BM25Searcher) rebuildIndex(docs []index.SearchDoc, fingerprint string)
 	s.index = index
 	s.idToSummary = idToSummary
 	s.lastFingerprint = fingerprint
+	s.docFingerprints = prints
 	s.indexBuildCount++
 
 	return nil
 }
 
+// updateIndex applies the differences between docs and the indexed
+// documents as one Bleve batch of per-document mutations, falling back to
+// a rebuild when there is no index yet or most documents changed.
+func (s *BM25Searcher) updateIndex(docs []index.SearchDoc, fingerprint string) error {
+	prints := docFingerprints(docs)
+
+	s.mu.Lock()
+	if s.lastFingerprint == fingerprint {
+		s.mu.Unlock()
+		return nil
+	}
+	var changed []index.SearchDoc
+	var removed []string
+	if s.index != nil && s.docFingerprints != nil {
+		for _, doc := range docs {
+			if s.docFingerprints[doc.ID] != prints[doc.ID] {
+				changed = append(changed, doc)
+			}
+		}
+		for id := range s.docFingerprints {
+			if _, ok := prints[id]; !ok {
+				removed = append(removed, id)
+			}
+		}
+	}
+	if s.index == nil || s.docFingerprints == nil || 2*(len(changed)+len(removed)) > len(docs) {
+		s.mu.Unlock()
+		return s.rebuildIndexWith(docs, fingerprint, prints)
+	}
+	defer s.mu.Unlock()
+
+	batch := s.index.NewBatch()
+	for _, doc := range changed {
+		if err := batch.Index(doc.ID, s.newIndexedDoc(doc)); err != nil {
+			return err
+		}
+	}
+	for _, id := range removed {
+		batch.Delete(id)
+	}
+	if err := s.index.Batch(batch); err != nil {
+		return err
+	}
+
+	for _, doc := range changed {
+		s.idToSummary[doc.ID] = doc.Summary
+	}
+	for _, id := range removed {
+		delete(s.idToSummary, id)
+	}
+	s.docFingerprints = prints
+	s.lastFingerprint = fingerprint
+	s.indexUpdateCount++
+	return nil
+}
+
+// docFingerprints returns the fingerprint of each document by ID.
+func docFingerprints(docs []index.SearchDoc) map[string]string {
+	prints := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		prints[doc.ID] = docFingerprint(doc)
+	}
+	return prints
+}
+
+// newIndexedDoc builds the Bleve document for doc.
+func (s *BM25Searcher) newIndexedDoc(doc index.SearchDoc) indexedDoc {
+	var indexed indexedDoc
+	content := buildWeightedDoc(s.cfg, doc)
+	if c, ok := s.contentFor(doc.Summary.Namespace); ok {
+		indexed.Analyzed = map[string]string{c.key: content}
+	} else {
+		indexed.Content = content
+	}
+	return indexed
+}
+
 // sortDocsByID returns a copy of docs sorted by ID for deterministic fingerprinting.
 func sortDocsByID(docs []index.SearchDoc) []index.SearchDoc {
 	sorted := make([]index.SearchDoc, len(docs))
@@ -299,6 +491,7 @@ func (s *BM25Searcher) Close() error {
 		s.index = nil
 		s.idToSummary = nil
 		s.lastFingerprint = ""
+		s.docFingerprints = nil
 		return err
 	}
 	return nil
@@ -104,3 +104,29 @@ func BenchmarkSearch_VaryingCatalogSize(b *testing.B) {
 		})
 	}
 }
+
+// benchmarkSingleChange searches after changing one document per iteration.
+func benchmarkSingleChange(b *testing.B, cfg BM25Config) {
+	s := NewBM25Searcher(cfg)
+	docs := makeBenchDocs(1000)
+	if _, err := s.Search("git", 10, docs); err != nil {
+		b.Fatalf("warmup search failed: %v", err)
+	}
+
+	i := 0
+	for b.Loop() {
+		docs[i%len(docs)].DocText = fmt.Sprintf("changed description %d", i)
+		i++
+		if _, err := s.Search("kubernetes", 10, docs); err != nil {
+			b.Fatalf("search failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkSearch_SingleChangeRebuild(b *testing.B) {
+	benchmarkSingleChange(b, BM25Config{})
+}
+
+func BenchmarkSearch_SingleChangeIncremental(b *testing.B) {
+	benchmarkSingleChange(b, BM25Config{Incremental: true})
+}
@@ -1,12 +1,16 @@
 package search
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/semantic"
 )
 
 // Cycle 1: Interface Compliance & Basic Structure
@@ -66,6 +70,18 @@ func TestBM25Config_CustomValues(t *testing.T) {
 	}
 }
 
+func TestBM25Searcher_Config(t *testing.T) {
+	s := NewBM25Searcher(BM25Config{NameBoost: 5, Synonyms: Synonyms{"K8s": {"Kubernetes"}}})
+	cfg := s.Config()
+	if cfg.NameBoost != 5 || cfg.NamespaceBoost != 2 || !slices.Equal(cfg.Synonyms["k8s"], []string{"kubernetes"}) {
+		t.Errorf("Config() = %+v, want custom values with defaults and normalized synonyms", cfg)
+	}
+	cfg.Synonyms["k8s"] = nil
+	if got := s.Config().Synonyms["k8s"]; len(got) != 1 {
+		t.Errorf("modifying Config() changed the searcher: synonyms = %v", got)
+	}
+}
+
 // Cycle 2: Empty Query Behavior
 
 func makeTestDocs(n int) []index.SearchDoc {
@@ -651,6 +667,61 @@ func TestSearch_IndexCaching_DocsChangeRebuild(t *testing.T) {
 	}
 }
 
+func TestSearch_IncrementalUpdates(t *testing.T) {
+	incremental := NewBM25Searcher(BM25Config{Incremental: true})
+	docs := makeTestDocs(20)
+
+	if _, err := incremental.Search("tool", 5, docs); err != nil {
+		t.Fatalf("first search error: %v", err)
+	}
+	if incremental.IndexBuildCount() != 1 || incremental.IndexUpdateCount() != 0 {
+		t.Fatalf("builds = %d, updates = %d after first search", incremental.IndexBuildCount(), incremental.IndexUpdateCount())
+	}
+
+	// Update one document, remove one, and add one.
+	docs[3].DocText = "deploy kubernetes cluster"
+	docs[3].Summary.ShortDescription = "Deploy a cluster"
+	docs = append(docs[:7], docs[8:]...)
+	docs = append(docs, index.SearchDoc{
+		ID:      "helm",
+		DocText: "install kubernetes charts",
+		Summary: index.Summary{ID: "helm", Name: "helm", Namespace: "k8s"},
+	})
+
+	for _, query := range []string{"kubernetes", "tool 7", "tool description"} {
+		got, err := incremental.Search(query, 10, docs)
+		if err != nil {
+			t.Fatalf("%q: search error: %v", query, err)
+		}
+		want, _ := NewBM25Searcher(BM25Config{}).Search(query, 10, docs)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: incremental = %v, rebuilt = %v", query, summaryIDs(got), summaryIDs(want))
+		}
+	}
+	if incremental.IndexBuildCount() != 1 || incremental.IndexUpdateCount() != 1 {
+		t.Errorf("builds = %d, updates = %d, want 1 build and 1 update", incremental.IndexBuildCount(), incremental.IndexUpdateCount())
+	}
+	if got, _ := incremental.Search("tool 7", 10, docs); slices.Contains(summaryIDs(got), "tool-7") {
+		t.Error("removed document still returned")
+	}
+
+	// Replacing most documents rebuilds instead.
+	if _, err := incremental.Search("tool", 5, makeTestDocs(3)); err != nil {
+		t.Fatalf("search error: %v", err)
+	}
+	if incremental.IndexBuildCount() != 2 {
+		t.Errorf("builds = %d after replacing most documents, want 2", incremental.IndexBuildCount())
+	}
+}
+
+func summaryIDs(summaries []index.Summary) []string {
+	ids := make([]string, len(summaries))
+	for i, s := range summaries {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
 func TestSearch_ConcurrentAccess(t *testing.T) {
 	s := NewBM25Searcher(BM25Config{})
 	docs := makeTestDocs(100)
@@ -1185,3 +1256,372 @@ func TestBM25Searcher_FingerprintSameAfterClose(t *testing.T) {
 		t.Errorf("expected rebuild after close, got count %d (was %d)", count, initialCount)
 	}
 }
+
+func TestBM25Searcher_FingerprintCoversMetadataAndHints(t *testing.T) {
+	s := NewBM25Searcher(BM25Config{})
+	doc := index.SearchDoc{
+		ID:      "k8s:pods",
+		DocText: "list pods",
+		Summary: index.Summary{ID: "k8s:pods", Name: "pods", Namespace: "k8s", Metadata: map[string]string{"team": "a"}},
+	}
+	if _, err := s.Search("pods", 1, []index.SearchDoc{doc}); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	doc.Summary.Metadata = map[string]string{"team": "b"}
+	results, err := s.Search("pods", 1, []index.SearchDoc{doc})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Metadata["team"] != "b" {
+		t.Errorf("results = %+v, want updated metadata", results)
+	}
+
+	doc.Summary.Hints = &index.ToolHints{ReadOnly: true, Idempotent: true, OpenWorld: true}
+	results, err = s.Search("pods", 1, []index.SearchDoc{doc})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Hints == nil || !results[0].Hints.ReadOnly {
+		t.Errorf("results = %+v, want updated hints", results)
+	}
+}
+
+func TestSearchExplained_MatchesSearchRanking(t *testing.T) {
+	s := NewBM25Searcher(BM25Config{})
+	docs := []index.SearchDoc{
+		{
+			ID:      "git:commit",
+			DocText: "commit create a commit in git",
+			Summary: index.Summary{ID: "git:commit", Name: "commit", Namespace: "git", ShortDescription: "Create a Commit", Tags: []string{"vcs"}},
+		},
+		{
+			ID:      "git:push",
+			DocText: "push to remote",
+			Summary: index.Summary{ID: "git:push", Name: "push", Namespace: "git"},
+		},
+		{
+			ID:      "docker:run",
+			DocText: "run a container",
+			Summary: index.Summary{ID: "docker:run", Name: "run", Namespace: "docker"},
+		},
+	}
+
+	plain, err := s.Search("commit git", 10, docs)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	explained, err := s.SearchExplained("commit git", 10, docs)
+	if err != nil {
+		t.Fatalf("SearchExplained error: %v", err)
+	}
+	if len(explained) != len(plain) || len(explained) != 2 {
+		t.Fatalf("got %d explained and %d plain results, want 2 each", len(explained), len(plain))
+	}
+	for i := range plain {
+		if explained[i].Summary.ID != plain[i].ID {
+			t.Fatalf("result %d = %s, want %s", i, explained[i].Summary.ID, plain[i].ID)
+		}
+	}
+
+	top := explained[0]
+	if top.Score <= explained[1].Score {
+		t.Errorf("scores = %v, %v; want descending", top.Score, explained[1].Score)
+	}
+	if len(top.MatchedTerms) != 2 || top.MatchedTerms[0] != "commit" || top.MatchedTerms[1] != "git" {
+		t.Errorf("MatchedTerms = %v, want [commit git]", top.MatchedTerms)
+	}
+	if got := top.Fragments[FieldDescription]; len(got) != 1 || got[0] != "Create a <mark>Commit</mark>" {
+		t.Errorf("description fragments = %v", got)
+	}
+	if _, ok := top.Fragments[FieldTags]; ok {
+		t.Errorf("unmatched tags field should be omitted, got %v", top.Fragments[FieldTags])
+	}
+
+	second := explained[1]
+	if len(second.MatchedTerms) != 1 || second.MatchedTerms[0] != "git" {
+		t.Errorf("MatchedTerms = %v, want [git]", second.MatchedTerms)
+	}
+	if len(second.Fragments) != 1 || second.Fragments[FieldNamespace][0] != "<mark>git</mark>" {
+		t.Errorf("Fragments = %v, want only namespace", second.Fragments)
+	}
+
+	scored, err := s.SearchScored("commit git", 10, docs)
+	if err != nil {
+		t.Fatalf("SearchScored error: %v", err)
+	}
+	for i := range explained {
+		if scored[i].Summary.ID != explained[i].Summary.ID || scored[i].Score != explained[i].Score {
+			t.Errorf("scored result %d = %s %v, want %s %v", i, scored[i].Summary.ID, scored[i].Score, explained[i].Summary.ID, explained[i].Score)
+		}
+	}
+}
+
+func TestSearchExplained_EmptyQuery(t *testing.T) {
+	s := NewBM25Searcher(BM25Config{})
+	docs := makeTestDocs(3)
+
+	results, err := s.SearchExplained("  ", 2, docs)
+	if err != nil {
+		t.Fatalf("SearchExplained error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.Score != 0 || r.MatchedTerms != nil || r.Fragments != nil {
+			t.Errorf("empty query result should carry no explanation, got %+v", r)
+		}
+	}
+}
+
+func TestSearch_RankingIndexOmitsHighlightFields(t *testing.T) {
+	s := NewBM25Searcher(BM25Config{})
+	docs := makeTestDocs(3)
+	if _, err := s.SearchExplained("tool", 3, docs); err != nil {
+		t.Fatalf("SearchExplained error: %v", err)
+	}
+
+	s.mu.RLock()
+	fields, err := s.index.Fields()
+	s.mu.RUnlock()
+	if err != nil {
+		t.Fatalf("Fields error: %v", err)
+	}
+	for _, field := range fields {
+		if slices.Contains(highlightFields, field) {
+			t.Errorf("ranking index holds highlight field %q", field)
+		}
+	}
+}
+
+func TestExpandQuery(t *testing.T) {
+	synonyms := Synonyms{
+		"K8s":        {"Kubernetes"},
+		"repo":       {"repository", "Repository", ""},
+		"pull req":   {"pull request"},
+		"":           {"ignored"},
+		"repository": {"repository"},
+	}.Normalized()
+	if len(synonyms["k8s"]) != 1 || len(synonyms["repo"]) != 1 || len(synonyms["repository"]) != 0 {
+		t.Fatalf("Normalized = %v", synonyms)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"deploy k8s", "deploy k8s kubernetes"},
+		{"K8S, repo!", "K8S, repo! kubernetes repository"},
+		{"open pull req", "open pull req pull request"},
+		{"repo repository", "repo repository"},
+		{"report", "report"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ExpandQuery(tt.query, synonyms); got != tt.want {
+			t.Errorf("ExpandQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestBM25Searcher_Synonyms(t *testing.T) {
+	docs := []index.SearchDoc{
+		{ID: "k8s:deploy", DocText: "deploy a workload to kubernetes", Summary: index.Summary{ID: "k8s:deploy", Name: "deploy"}},
+		{ID: "git:clone", DocText: "clone a repository", Summary: index.Summary{ID: "git:clone", Name: "clone"}},
+	}
+
+	plain := NewBM25Searcher(BM25Config{})
+	if results, _ := plain.Search("repo", 10, docs); len(results) != 0 {
+		t.Fatalf("expected no results without synonyms, got %v", results)
+	}
+
+	synonyms := Synonyms{"repo": {"repository"}}
+	s := NewBM25Searcher(BM25Config{Synonyms: synonyms})
+	synonyms["repo"] = []string{"mutated"}
+
+	results, err := s.Search("Repo", 10, docs)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "git:clone" {
+		t.Fatalf("results = %v, want [git:clone]", results)
+	}
+}
+
+func TestBM25Searcher_FuzzyFallback(t *testing.T) {
+	docs := []index.SearchDoc{
+		{ID: "k8s:kubectl_get", DocText: "fetch resources", Summary: index.Summary{ID: "k8s:kubectl_get", Name: "kubectl_get", Namespace: "k8s"}},
+		{ID: "k8s:kubectl_apply", DocText: "apply manifests", Summary: index.Summary{ID: "k8s:kubectl_apply", Name: "kubectl_apply", Namespace: "k8s"}},
+		{ID: "github:create_issue", DocText: "open an issue", Summary: index.Summary{ID: "github:create_issue", Name: "create_issue", Namespace: "github"}},
+	}
+
+	plain := NewBM25Searcher(BM25Config{})
+	if results, _ := plain.Search("kubctl get", 10, docs); len(results) != 0 {
+		t.Fatalf("expected no results without fallback, got %v", results)
+	}
+
+	s := NewBM25Searcher(BM25Config{FuzzyFallback: true})
+	results, err := s.SearchExplained("kubctl get", 10, docs)
+	if err != nil {
+		t.Fatalf("SearchExplained error: %v", err)
+	}
+	if len(results) != 2 || results[0].Summary.ID != "k8s:kubectl_get" || results[1].Summary.ID != "k8s:kubectl_apply" {
+		t.Fatalf("results = %v, want kubectl_get then kubectl_apply", results)
+	}
+	if !results[0].Fuzzy || !slices.Equal(results[0].MatchedTerms, []string{"get", "kubectl"}) {
+		t.Errorf("explained fallback = %+v", results[0])
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"githb", []string{"github:create_issue"}}, // typo in namespace
+		{"isue", []string{"github:create_issue"}},  // deletion
+		{"crea", []string{"github:create_issue"}},  // prefix
+		{"kubectl aplpy", []string{"k8s:kubectl_apply", "k8s:kubectl_get"}},
+		{"zz", nil}, // short tokens match exactly only
+		{"terraform", nil},
+	}
+	for _, tt := range tests {
+		results, err := s.Search(tt.query, 10, docs)
+		if err != nil {
+			t.Fatalf("Search(%q) error: %v", tt.query, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// BM25 hits are returned unchanged when there are any.
+	if results, _ := s.SearchExplained("issue", 10, docs); len(results) != 1 || results[0].Fuzzy {
+		t.Errorf("expected a BM25 result, got %+v", results)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"kubctl", "kubectl", 2, 1},
+		{"aplpy", "apply", 1, 1},
+		{"abc", "abc", 1, 0},
+		{"abc", "xyz", 2, -1},
+		{"ab", "abcd", 1, -1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSearch_NamespaceAnalyzers(t *testing.T) {
+	docs := []index.SearchDoc{
+		{
+			ID:      "github:create_issue",
+			DocText: "create_issue github open a ticket",
+			Summary: index.Summary{ID: "github:create_issue", Name: "create_issue", Namespace: "github"},
+		},
+		{
+			ID:      "docs:issues_guide",
+			DocText: "issues_guide docs how teams triage reported issues",
+			Summary: index.Summary{ID: "docs:issues_guide", Name: "issues_guide", Namespace: "docs"},
+		},
+	}
+	search := func(t *testing.T, s *BM25Searcher, query string) []string {
+		t.Helper()
+		results, err := s.Search(query, 10, docs)
+		if err != nil {
+			t.Fatalf("Search(%q) error: %v", query, err)
+		}
+		return summaryIDs(results)
+	}
+
+	// The standard analyzer keeps create_issue as one token and does not
+	// stem, so neither tool matches "issue".
+	if got := search(t, NewBM25Searcher(BM25Config{}), "issue"); len(got) != 0 {
+		t.Errorf("standard analyzer results = %v, want none", got)
+	}
+
+	s := NewBM25Searcher(BM25Config{
+		Analyzer:           AnalyzerEnglish,
+		NamespaceAnalyzers: map[string]string{"github": AnalyzerCode},
+	})
+	if got := search(t, s, "issue"); len(got) != 2 {
+		t.Errorf("per-namespace analyzer results = %v, want both tools", got)
+	}
+	if got := search(t, s, "create"); !slices.Equal(got, []string{"github:create_issue"}) {
+		t.Errorf("code analyzer results = %v, want github:create_issue", got)
+	}
+	// Other namespaces keep the default English analyzer.
+	if got := search(t, s, "triage"); !slices.Equal(got, []string{"docs:issues_guide"}) {
+		t.Errorf("english analyzer results = %v, want docs:issues_guide", got)
+	}
+
+	explained, err := s.SearchExplained("create issue", 10, docs)
+	if err != nil {
+		t.Fatalf("SearchExplained error: %v", err)
+	}
+	if len(explained) == 0 || explained[0].Summary.ID != "github:create_issue" ||
+		!slices.Equal(explained[0].MatchedTerms, []string{"create", "issue"}) {
+		t.Errorf("SearchExplained = %+v, want github:create_issue matching create and issue", explained)
+	}
+
+	bad := NewBM25Searcher(BM25Config{NamespaceAnalyzers: map[string]string{"github": "no-such-analyzer"}})
+	if _, err := bad.Search("issue", 10, docs); err == nil {
+		t.Error("Search with unknown analyzer succeeded, want error")
+	}
+}
+
+func TestSearch_BooleanQueries(t *testing.T) {
+	doc := func(id, namespace, text string, tags ...string) index.SearchDoc {
+		return index.SearchDoc{ID: id, DocText: text, Summary: index.Summary{ID: id, Name: id, Namespace: namespace, Tags: tags}}
+	}
+	docs := []index.SearchDoc{
+		doc("git:commit", "git", "record changes to the repository", "vcs"),
+		doc("git:legacy_commit", "git", "old commit helper", "vcs", "deprecated"),
+		doc("git:status", "git", "show the working tree status", "vcs"),
+		doc("svn:commit", "svn", "send changes to the repository", "vcs"),
+	}
+	s := NewBM25Searcher(BM25Config{BooleanQueries: true})
+
+	results, err := s.Search("namespace:git NOT tag:deprecated commit", 10, docs)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	// Bare words only rank, so git:status follows the commit match.
+	if got := idsOf(results); !slices.Equal(got, []string{"git:commit", "git:status"}) {
+		t.Errorf("results = %v, want git:commit then git:status", got)
+	}
+
+	results, _ = s.Search(`namespace:svn OR "working tree"`, 10, docs)
+	if got := idsOf(results); !slices.Equal(got, []string{"git:status", "svn:commit"}) {
+		t.Errorf("filter-only results = %v, want git:status and svn:commit in ID order", got)
+	}
+	if results, _ := s.Search("tag:vcs", 1, docs); len(results) != 1 {
+		t.Errorf("limit 1 returned %d results", len(results))
+	}
+
+	if _, err := s.Search(`"unterminated`, 10, docs); !errors.Is(err, semantic.ErrInvalidQuery) {
+		t.Errorf("Search(invalid) error = %v, want ErrInvalidQuery", err)
+	}
+	if _, err := NewBM25Searcher(BM25Config{}).Search(`"unterminated`, 10, docs); err != nil {
+		t.Errorf("plain Search error = %v, want the quote searched as text", err)
+	}
+}
+
+func idsOf(results []index.Summary) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
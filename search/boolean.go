@@ -0,0 +1,50 @@
+package search
+
+import (
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/tooldiscovery/semantic"
+)
+
+// searchBoolean implements search for BM25Config.BooleanQueries. The words
+// of the query are ranked over every document and the query's filters are
+// applied to the hits, so the Bleve index is shared by all filters instead
+// of rebuilt for each filtered document set. As the words only rank,
+// matching documents they miss follow the hits in ID order.
+func (s *BM25Searcher) searchBoolean(query string, limit int, docs []index.SearchDoc, explain bool) ([]ExplainedResult, error) {
+	parsed, err := semantic.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if !parsed.HasFilters() || limit <= 0 {
+		return s.rank(parsed.Text(), limit, docs, explain)
+	}
+	ranked, err := s.rank(parsed.Text(), len(docs), docs, explain)
+	if err != nil {
+		return nil, err
+	}
+	sorted := sortDocsByID(docs)
+	if s.cfg.MaxDocs > 0 && len(sorted) > s.cfg.MaxDocs {
+		sorted = sorted[:s.cfg.MaxDocs]
+	}
+	matched := make(map[string]bool, len(sorted))
+	for _, doc := range sorted {
+		matched[doc.ID] = parsed.Match(semantic.DocumentFromSearchDoc(doc))
+	}
+	out := make([]ExplainedResult, 0, min(limit, len(sorted)))
+	seen := make(map[string]bool, len(ranked))
+	for _, r := range ranked {
+		seen[r.Summary.ID] = true
+		if len(out) < limit && matched[r.Summary.ID] {
+			out = append(out, r)
+		}
+	}
+	for _, doc := range sorted {
+		if len(out) >= limit {
+			break
+		}
+		if matched[doc.ID] && !seen[doc.ID] {
+			out = append(out, ExplainedResult{Summary: doc.Summary})
+		}
+	}
+	return out, nil
+}
@@ -25,11 +25,84 @@
 //	    MaxDocTextLen:  5000, // Truncate long descriptions (0 = unlimited)
 //	}
 //
+// # Synonyms
+//
+// BM25Config.Synonyms expands query terms before ranking, so abbreviations
+// common in LLM queries still match spelled-out descriptions. Keys may be
+// phrases and match on word boundaries; [ExpandQuery] applies a map to any
+// query:
+//
+//	cfg := search.BM25Config{Synonyms: search.Synonyms{
+//	    "k8s":  {"kubernetes"},
+//	    "repo": {"repository"},
+//	}}
+//
+// # Analyzers
+//
+// BM25Config.Analyzer selects the Bleve analyzer for ranked text, and
+// NamespaceAnalyzers overrides it per namespace, since one analyzer cannot
+// serve both CLI-style names and prose. [AnalyzerCode] splits
+// "create_issue" into "create" and "issue"; [AnalyzerEnglish] stems prose
+// so "issues" matches "issue":
+//
+//	cfg := search.BM25Config{
+//	    Analyzer: search.AnalyzerEnglish,
+//	    NamespaceAnalyzers: map[string]string{
+//	        "github": search.AnalyzerCode,
+//	        "git":    search.AnalyzerCode,
+//	    },
+//	}
+//
+// Each namespace's documents are indexed in a field with its analyzer, and
+// queries are matched against every field with that field's analyzer.
+//
+// # Fuzzy Fallback
+//
+// With BM25Config.FuzzyFallback set, a query that BM25 matches nowhere is
+// retried against tool names and namespaces, matching each query word
+// exactly, as a prefix (three or more characters), or within one edit (two
+// for words longer than five characters). "kubctl get pods" then still finds
+// kubectl_get. Fallback results are never mixed with BM25 hits;
+// [ExplainedResult].Fuzzy marks them.
+//
+// # Boolean Queries
+//
+// With BM25Config.BooleanQueries set, queries are parsed with
+// semantic.ParseQuery: AND, OR, NOT, "quoted phrases", and namespace:, tag:,
+// category:, name:, and id: prefixes filter results, and the remaining words
+// are ranked with BM25. The words only rank, so matching documents they miss
+// follow the hits in ID order:
+//
+//	s := search.NewBM25Searcher(search.BM25Config{BooleanQueries: true})
+//	results, err := s.Search("namespace:git NOT tag:deprecated commit", 10, docs)
+//
+// # Incremental Updates
+//
+// By default the Bleve index is rebuilt whenever the document fingerprint
+// changes. With BM25Config.Incremental set, the searcher keeps a fingerprint
+// per document and applies only the added, changed, and removed documents to
+// the existing index in one batch, which keeps a registration cheap in
+// catalogs with thousands of tools. It still rebuilds when more than half of
+// the documents changed. [BM25Searcher.IndexUpdateCount] counts incremental
+// updates alongside IndexBuildCount.
+//
+// # Explaining Results
+//
+// [BM25Searcher.SearchExplained] returns the same ranking as Search, with each
+// result's raw Bleve score, the matched query terms, and highlighted
+// fragments per field (name, namespace, description, tags, text):
+//
+//	results, _ := searcher.SearchExplained("create issue", 5, docs)
+//	for _, r := range results {
+//	    fmt.Println(r.Summary.ID, r.Score, r.MatchedTerms, r.Fragments[search.FieldDescription])
+//	}
+//
 // # Thread Safety
 //
 // BM25Searcher is safe for concurrent use. It uses an internal RWMutex to
 // protect index state and efficiently caches the Bleve index based on document
-// fingerprints, only rebuilding when the document set changes.
+// fingerprints, only rebuilding (or, in incremental mode, updating) when the
+// document set changes.
 //
 // # Behavior
 //
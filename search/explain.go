@@ -0,0 +1,179 @@
+package search
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	blevesearch "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Fields reported in ExplainedResult.Fragments.
+const (
+	FieldName        = "name"
+	FieldNamespace   = "namespace"
+	FieldDescription = "description"
+	FieldTags        = "tags"
+	FieldText        = "text"
+)
+
+// highlightMark opens a highlighted match in Bleve's default HTML style.
+const highlightMark = "<mark>"
+
+var highlightFields = []string{FieldName, FieldNamespace, FieldDescription, FieldTags, FieldText}
+
+// ExplainedResult is a search result annotated with why it matched.
+type ExplainedResult struct {
+	Summary index.Summary
+
+	// Score is the raw Bleve score used for ranking. It is zero for empty
+	// queries, which return documents in ID order without scoring.
+	Score float64
+
+	// MatchedTerms lists the analyzed query terms found in the document,
+	// sorted.
+	MatchedTerms []string
+
+	// Fragments maps a field (FieldName, FieldNamespace, ...) to highlighted
+	// snippets with matches wrapped in <mark></mark>. Fields without matches
+	// are omitted.
+	Fragments map[string][]string
+
+	// Fuzzy reports a BM25Config.FuzzyFallback result. Its Score is the
+	// fallback match score, MatchedTerms lists the name and namespace tokens
+	// matched, and Fragments is empty.
+	Fuzzy bool
+}
+
+// SearchExplained ranks docs exactly like Search and explains each result
+// with its raw score, matched terms, and per-field highlight fragments.
+// It is intended for debugging rankings and rendering snippets; each call
+// indexes its results into a temporary index to highlight them.
+func (s *BM25Searcher) SearchExplained(query string, limit int, docs []index.SearchDoc) ([]ExplainedResult, error) {
+	return s.search(query, limit, docs, true)
+}
+
+// SearchScored ranks docs exactly like Search and returns each result with
+// its raw score, implementing index.ScoredSearcher. Unlike SearchExplained
+// it does not collect matched terms or highlight fragments, so it costs the
+// same as Search.
+func (s *BM25Searcher) SearchScored(query string, limit int, docs []index.SearchDoc) ([]index.ScoredSummary, error) {
+	results, err := s.search(query, limit, docs, false)
+	if err != nil {
+		return nil, err
+	}
+	scored := make([]index.ScoredSummary, len(results))
+	for i, r := range results {
+		scored[i] = index.ScoredSummary{Summary: r.Summary, Score: r.Score}
+	}
+	return scored, nil
+}
+
+// highlightDoc holds the fields of a result document that SearchExplained
+// highlights.
+type highlightDoc struct {
+	Name        string   `json:"name,omitempty"`
+	Namespace   string   `json:"namespace,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Text        string   `json:"text,omitempty"`
+}
+
+// highlight fills in Fragments for results. The ranking index holds only
+// the weighted content, so the result documents are indexed with their
+// fields into a temporary index sized to the results; searches that are not
+// explained pay nothing for highlighting. docs must be sorted by ID. The
+// caller must hold s.mu.
+func (s *BM25Searcher) highlight(queryText string, results []ExplainedResult, docs []index.SearchDoc) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	hlIndex, err := bleve.NewMemOnly(bleve.NewIndexMapping())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = hlIndex.Close() }()
+	batch := hlIndex.NewBatch()
+	for _, r := range results {
+		i, ok := slices.BinarySearchFunc(docs, r.Summary.ID, func(doc index.SearchDoc, id string) int {
+			return strings.Compare(doc.ID, id)
+		})
+		if !ok {
+			continue
+		}
+		if err := batch.Index(r.Summary.ID, s.newHighlightDoc(docs[i])); err != nil {
+			return err
+		}
+	}
+	if err := hlIndex.Batch(batch); err != nil {
+		return err
+	}
+
+	fieldQueries := make([]query.Query, len(highlightFields))
+	for i, field := range highlightFields {
+		mq := bleve.NewMatchQuery(queryText)
+		mq.SetField(field)
+		fieldQueries[i] = mq
+	}
+	request := bleve.NewSearchRequest(bleve.NewDisjunctionQuery(fieldQueries...))
+	request.Size = len(results)
+	request.Highlight = bleve.NewHighlight()
+	request.Highlight.Fields = highlightFields
+
+	searchResult, err := hlIndex.Search(request)
+	if err != nil {
+		return err
+	}
+	fragments := make(map[string]map[string][]string, len(searchResult.Hits))
+	for _, hit := range searchResult.Hits {
+		// Bleve also returns unmarked snippets for fields that did not match.
+		for field, snippets := range hit.Fragments {
+			marked := slices.DeleteFunc(snippets, func(f string) bool {
+				return !strings.Contains(f, highlightMark)
+			})
+			if len(marked) == 0 {
+				continue
+			}
+			if fragments[hit.ID] == nil {
+				fragments[hit.ID] = make(map[string][]string)
+			}
+			fragments[hit.ID][field] = marked
+		}
+	}
+	for i := range results {
+		results[i].Fragments = fragments[results[i].Summary.ID]
+	}
+	return nil
+}
+
+// newHighlightDoc builds the highlight document for doc.
+func (s *BM25Searcher) newHighlightDoc(doc index.SearchDoc) highlightDoc {
+	text := doc.DocText
+	if s.cfg.MaxDocTextLen > 0 && len(text) > s.cfg.MaxDocTextLen {
+		text = text[:s.cfg.MaxDocTextLen]
+	}
+	return highlightDoc{
+		Name:        doc.Summary.Name,
+		Namespace:   doc.Summary.Namespace,
+		Description: doc.Summary.ShortDescription,
+		Tags:        doc.Summary.Tags,
+		Text:        text,
+	}
+}
+
+// matchedTerms returns the sorted terms with recorded locations.
+func matchedTerms(locations blevesearch.TermLocationMap) []string {
+	if len(locations) == 0 {
+		return nil
+	}
+	terms := make([]string, 0, len(locations))
+	for term := range locations {
+		terms = append(terms, term)
+	}
+	slices.Sort(terms)
+	return terms
+}
@@ -3,7 +3,10 @@ package search
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"hash"
+	"maps"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/jonwraymond/tooldiscovery/index"
@@ -14,42 +17,70 @@ import (
 // efficient cache invalidation for the BM25 index.
 func computeFingerprint(docs []index.SearchDoc) string {
 	h := sha256.New()
-
 	for _, doc := range docs {
-		// Write ID
-		h.Write([]byte(doc.ID))
-		h.Write([]byte{0}) // separator
-
-		// Write DocText
-		h.Write([]byte(doc.DocText))
-		h.Write([]byte{0})
-
-		// Write Summary fields
-		h.Write([]byte(doc.Summary.ID))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.Name))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.Namespace))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.ShortDescription))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.Summary))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.Category))
-		h.Write([]byte{0})
-		h.Write([]byte(strings.Join(doc.Summary.InputModes, "\x01")))
-		h.Write([]byte{0})
-		h.Write([]byte(strings.Join(doc.Summary.OutputModes, "\x01")))
-		h.Write([]byte{0})
-		h.Write([]byte(doc.Summary.SecuritySummary))
-		h.Write([]byte{0})
-
-		// Write Tags (sorted for order-independence, then joined with separator)
-		sortedTags := slices.Clone(doc.Summary.Tags)
-		slices.Sort(sortedTags)
-		h.Write([]byte(strings.Join(sortedTags, "\x01")))
-		h.Write([]byte{0})
+		writeDocFields(h, doc)
 	}
+	return hex.EncodeToString(h.Sum(nil))
+}
 
+// docFingerprint returns a stable hash of a single document, used by
+// incremental updates to find changed documents.
+func docFingerprint(doc index.SearchDoc) string {
+	h := sha256.New()
+	writeDocFields(h, doc)
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// writeDocFields writes the fingerprinted fields of doc to h.
+func writeDocFields(h hash.Hash, doc index.SearchDoc) {
+	// Write ID
+	h.Write([]byte(doc.ID))
+	h.Write([]byte{0}) // separator
+
+	// Write DocText
+	h.Write([]byte(doc.DocText))
+	h.Write([]byte{0})
+
+	// Write Summary fields
+	h.Write([]byte(doc.Summary.ID))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.Namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.ShortDescription))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.Summary))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.Category))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(doc.Summary.InputModes, "\x01")))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(doc.Summary.OutputModes, "\x01")))
+	h.Write([]byte{0})
+	h.Write([]byte(doc.Summary.SecuritySummary))
+	h.Write([]byte{0})
+
+	// Write Tags (sorted for order-independence, then joined with separator)
+	sortedTags := slices.Clone(doc.Summary.Tags)
+	slices.Sort(sortedTags)
+	h.Write([]byte(strings.Join(sortedTags, "\x01")))
+	h.Write([]byte{0})
+
+	// Write Metadata pairs in key order and Hints, which are returned in
+	// the stored Summary
+	for _, k := range slices.Sorted(maps.Keys(doc.Summary.Metadata)) {
+		h.Write([]byte(k))
+		h.Write([]byte{1})
+		h.Write([]byte(doc.Summary.Metadata[k]))
+		h.Write([]byte{1})
+	}
+	h.Write([]byte{0})
+	if hints := doc.Summary.Hints; hints != nil {
+		for _, v := range []bool{hints.ReadOnly, hints.Destructive, hints.Idempotent, hints.OpenWorld} {
+			h.Write([]byte(strconv.FormatBool(v)))
+			h.Write([]byte{1})
+		}
+	}
+	h.Write([]byte{0})
+}
@@ -0,0 +1,148 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Fuzzy fallback scores. A query token contributes the best of these over a
+// document's name and namespace tokens.
+const (
+	fuzzyExactScore  = 1.0
+	fuzzyPrefixScore = 0.75
+	fuzzyEditScore   = 0.5 // divided by the edit distance
+)
+
+// fuzzyMinTokenLen is the shortest query token matched by prefix or edit
+// distance. Shorter tokens only match exactly, since nearly every short word
+// is one edit away from some tool name.
+const fuzzyMinTokenLen = 3
+
+// fuzzySearch ranks docs whose name or namespace tokens match the query
+// tokens exactly, by prefix, or within a small edit distance, for queries
+// with typos such as "kubctl" that BM25 cannot match. Results are ordered by
+// score descending, then ID ascending. Tokens split at any non-alphanumeric
+// character, so "kubectl_get" matches "kubctl get".
+func fuzzySearch(query string, limit int, docs []index.SearchDoc) []ExplainedResult {
+	queryTerms := fuzzyTokens(query)
+	if len(queryTerms) == 0 {
+		return []ExplainedResult{}
+	}
+
+	var results []ExplainedResult
+	for _, doc := range docs {
+		docTerms := append(fuzzyTokens(doc.Summary.Name), fuzzyTokens(doc.Summary.Namespace)...)
+		var (
+			score   float64
+			matched []string
+		)
+		for _, q := range queryTerms {
+			best, term := 0.0, ""
+			for _, t := range docTerms {
+				if s := fuzzyTermScore(q, t); s > best {
+					best, term = s, t
+				}
+			}
+			if best > 0 {
+				score += best
+				if !slices.Contains(matched, term) {
+					matched = append(matched, term)
+				}
+			}
+		}
+		if score > 0 {
+			slices.Sort(matched)
+			results = append(results, ExplainedResult{Summary: doc.Summary, Score: score, MatchedTerms: matched, Fuzzy: true})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Summary.ID < results[j].Summary.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []ExplainedResult{}
+	}
+	return results
+}
+
+// fuzzyTermScore scores how well query token q matches document token t.
+func fuzzyTermScore(q, t string) float64 {
+	if q == t {
+		return fuzzyExactScore
+	}
+	if len(q) < fuzzyMinTokenLen {
+		return 0
+	}
+	if strings.HasPrefix(t, q) {
+		return fuzzyPrefixScore
+	}
+	if d := editDistance(q, t, maxFuzzyEdits(q)); d > 0 {
+		return fuzzyEditScore / float64(d)
+	}
+	return 0
+}
+
+// maxFuzzyEdits allows one edit for tokens up to five characters and two
+// for longer tokens.
+func maxFuzzyEdits(q string) int {
+	if len([]rune(q)) <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b (insertions, deletions, substitutions, and adjacent transpositions), or
+// -1 when it exceeds limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return -1
+	}
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return -1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	if d := prev[len(rb)]; d <= limit {
+		return d
+	}
+	return -1
+}
+
+// fuzzyTokens lowercases s and splits it into alphanumeric words.
+func fuzzyTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Synonyms maps a query term or phrase to terms that should also match it,
+// such as "k8s" to "kubernetes" or "repo" to "repository". Keys and
+// expansions are matched case-insensitively on word boundaries; a key may
+// span several words. Expansion is one-directional: list both directions
+// for a symmetric pair.
+type Synonyms map[string][]string
+
+// Normalized returns a copy with lowercased keys and expansions, dropping
+// empty entries and merging keys that differ only in case.
+func (s Synonyms) Normalized() Synonyms {
+	if len(s) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	out := make(Synonyms, len(s))
+	for _, raw := range keys {
+		expansions := s[raw]
+		key := strings.Join(queryTokens(raw), " ")
+		if key == "" {
+			continue
+		}
+		for _, exp := range expansions {
+			exp = strings.Join(queryTokens(exp), " ")
+			if exp != "" && exp != key && !slices.Contains(out[key], exp) {
+				out[key] = append(out[key], exp)
+			}
+		}
+	}
+	return out
+}
+
+// ExpandQuery appends the expansions of every key found in query, so an
+// abbreviation also matches documents that spell the term out. The original
+// query text is kept first; expansions already present in the query are not
+// repeated. Synonyms should be normalized (see Synonyms.Normalized).
+func ExpandQuery(query string, synonyms Synonyms) string {
+	if len(synonyms) == 0 {
+		return query
+	}
+	tokens := queryTokens(query)
+	if len(tokens) == 0 {
+		return query
+	}
+	padded := " " + strings.Join(tokens, " ") + " "
+
+	keys := make([]string, 0, len(synonyms))
+	for key := range synonyms {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var added []string
+	for _, key := range keys {
+		if !strings.Contains(padded, " "+key+" ") {
+			continue
+		}
+		for _, exp := range synonyms[key] {
+			if !strings.Contains(padded, " "+exp+" ") && !slices.Contains(added, exp) {
+				added = append(added, exp)
+			}
+		}
+	}
+	if len(added) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(added, " ")
+}
+
+// queryTokens lowercases s and splits it into words. Underscores stay
+// inside words to match how tool names are indexed.
+func queryTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
@@ -0,0 +1,148 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBatchResult is returned when a BatchEmbedder returns a different
+// number of vectors than texts.
+var ErrInvalidBatchResult = errors.New("semantic: batch embedding returned wrong number of vectors")
+
+// BatchEmbedder is an optional interface for embedders with a bulk endpoint.
+// Strategies, VectorIndex, and discovery.HybridSearcher use it when the
+// configured Embedder implements it.
+//
+// Contract:
+// - Concurrency: implementations must be safe for concurrent use.
+// - Context: must honor cancellation/deadlines.
+// - Ordering: the result has one vector per text, in input order.
+// - Nil/zero: an empty input returns an empty result with nil error.
+type BatchEmbedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// BatchScorer is an optional Strategy extension for scoring many documents
+// against one query, amortizing per-query work such as embedding the query.
+//
+// Contract:
+// - ScoreBatch returns one score per document, in input order.
+// - Scores must be consistent with Score for the same documents.
+type BatchScorer interface {
+	ScoreBatch(ctx context.Context, query string, docs []Document) ([]float64, error)
+}
+
+// EmbedTexts embeds texts with embedder, using a single EmbedBatch call when
+// embedder implements BatchEmbedder and one Embed call per text otherwise.
+func EmbedTexts(ctx context.Context, embedder Embedder, texts []string) ([][]float32, error) {
+	if embedder == nil {
+		return nil, ErrInvalidEmbedder
+	}
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+	if batch, ok := embedder.(BatchEmbedder); ok {
+		vectors, err := batch.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(texts) {
+			return nil, fmt.Errorf("%w: got %d for %d texts", ErrInvalidBatchResult, len(vectors), len(texts))
+		}
+		return vectors, nil
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec, err := embedder.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+// ScoreDocuments scores docs against query with strategy, in input order.
+// It uses ScoreBatch when strategy implements BatchScorer and one Score call
+// per document otherwise.
+func ScoreDocuments(ctx context.Context, strategy Strategy, query string, docs []Document) ([]float64, error) {
+	if batch, ok := strategy.(BatchScorer); ok {
+		return batch.ScoreBatch(ctx, query, docs)
+	}
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		score, err := strategy.Score(ctx, query, doc)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
+
+// ScoreBatch embeds the query and all documents together, in one EmbedBatch
+// call when the embedder supports it. A query embedding carried by ctx (see
+// WithQueryEmbedding) is used instead of embedding the query.
+func (s embeddingStrategy) ScoreBatch(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	if s.embedder == nil {
+		return nil, ErrInvalidEmbedder
+	}
+	if len(docs) == 0 {
+		return []float64{}, nil
+	}
+	qVec, precomputed := precomputedQuery(ctx, s.embedder, query)
+	texts := make([]string, 0, len(docs)+1)
+	if !precomputed {
+		texts = append(texts, query)
+	}
+	for _, doc := range docs {
+		texts = append(texts, documentText(doc))
+	}
+	vectors, err := EmbedTexts(ctx, s.embedder, texts)
+	if err != nil {
+		return nil, err
+	}
+	if !precomputed {
+		qVec, vectors = vectors[0], vectors[1:]
+	}
+	scores := make([]float64, len(docs))
+	for i := range docs {
+		scores[i] = s.metric.Similarity(qVec, vectors[i])
+	}
+	return scores, nil
+}
+
+// ScoreBatch combines per-document BM25 scores with batched embedding scores.
+func (s hybridStrategy) ScoreBatch(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	bm25, err := ScoreDocuments(ctx, s.bm25, query, docs)
+	if err != nil {
+		return nil, err
+	}
+	embedding, err := ScoreDocuments(ctx, s.embedding, query, docs)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(docs))
+	for i := range docs {
+		scores[i] = s.alpha*bm25[i] + (1-s.alpha)*embedding[i]
+	}
+	return scores, nil
+}
+
+// ScoreBatch scores docs against stored vectors; see VectorIndex.Similarities.
+func (s vectorStrategy) ScoreBatch(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	if s.index == nil {
+		return nil, ErrInvalidEmbedder
+	}
+	return s.index.Similarities(ctx, query, docs)
+}
+
+var (
+	_ BatchScorer = embeddingStrategy{}
+	_ BatchScorer = hybridStrategy{}
+	_ BatchScorer = vectorStrategy{}
+)
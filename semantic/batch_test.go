@@ -0,0 +1,198 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// batchEmbedder embeds text as {1, len(text)} and records calls.
+type batchEmbedder struct {
+	mu      sync.Mutex
+	single  int
+	batches [][]string
+	short   bool // return one vector too few
+}
+
+func (e *batchEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.mu.Lock()
+	e.single++
+	e.mu.Unlock()
+	return []float32{1, float32(len(text))}, nil
+}
+
+func (e *batchEmbedder) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.batches = append(e.batches, append([]string(nil), texts...))
+	e.mu.Unlock()
+	out := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		out = append(out, []float32{1, float32(len(text))})
+	}
+	if e.short {
+		out = out[:len(out)-1]
+	}
+	return out, nil
+}
+
+func TestEmbedTexts(t *testing.T) {
+	ctx := context.Background()
+	batch := &batchEmbedder{}
+	vecs, err := EmbedTexts(ctx, batch, []string{"a", "bb"})
+	if err != nil {
+		t.Fatalf("EmbedTexts failed: %v", err)
+	}
+	if len(vecs) != 2 || vecs[1][1] != 2 {
+		t.Fatalf("vectors = %v", vecs)
+	}
+	if len(batch.batches) != 1 || batch.single != 0 {
+		t.Fatalf("batches = %d, single = %d; want 1 batch call", len(batch.batches), batch.single)
+	}
+
+	single := &modelEmbedder{vec: []float32{1, 0}}
+	if _, err := EmbedTexts(ctx, single, []string{"a", "b", "c"}); err != nil {
+		t.Fatalf("EmbedTexts failed: %v", err)
+	}
+	if calls := single.calls.Load(); calls != 3 {
+		t.Fatalf("Embed calls = %d, want 3", calls)
+	}
+
+	if _, err := EmbedTexts(ctx, &batchEmbedder{short: true}, []string{"a", "b"}); !errors.Is(err, ErrInvalidBatchResult) {
+		t.Fatalf("expected ErrInvalidBatchResult, got %v", err)
+	}
+	if _, err := EmbedTexts(ctx, nil, []string{"a"}); !errors.Is(err, ErrInvalidEmbedder) {
+		t.Fatalf("expected ErrInvalidEmbedder, got %v", err)
+	}
+}
+
+func TestEmbeddingStrategy_ScoreBatchMatchesScore(t *testing.T) {
+	ctx := context.Background()
+	emb := &batchEmbedder{}
+	strategy := NewEmbeddingStrategy(emb)
+	docs := []Document{{ID: "a", Name: "read"}, {ID: "b", Name: "write file"}}
+
+	scores, err := ScoreDocuments(ctx, strategy, "query", docs)
+	if err != nil {
+		t.Fatalf("ScoreDocuments failed: %v", err)
+	}
+	if len(emb.batches) != 1 || len(emb.batches[0]) != 3 {
+		t.Fatalf("batches = %v, want one batch with query and 2 docs", emb.batches)
+	}
+	for i, doc := range docs {
+		want, _ := strategy.Score(ctx, "query", doc)
+		if scores[i] != want {
+			t.Errorf("doc %s: batch score %v, Score %v", doc.ID, scores[i], want)
+		}
+	}
+}
+
+func TestWithQueryEmbedding(t *testing.T) {
+	emb := &batchEmbedder{}
+	strategy := NewEmbeddingStrategy(emb)
+	docs := []Document{{ID: "a", Name: "read"}, {ID: "b", Name: "write file"}}
+	want, err := ScoreDocuments(context.Background(), strategy, "query", docs)
+	if err != nil {
+		t.Fatalf("ScoreDocuments failed: %v", err)
+	}
+
+	ctx := WithQueryEmbedding(context.Background(), emb, "query", []float32{1, 5})
+	emb.batches = nil
+	scores, err := ScoreDocuments(ctx, strategy, "query", docs)
+	if err != nil {
+		t.Fatalf("ScoreDocuments failed: %v", err)
+	}
+	if len(emb.batches) != 1 || len(emb.batches[0]) != 2 || !slices.Equal(scores, want) {
+		t.Errorf("batches = %v, scores = %v; want the 2 docs embedded and scores %v", emb.batches, scores, want)
+	}
+
+	// Another query, or another embedder, embeds the query as usual.
+	emb.batches = nil
+	_, _ = ScoreDocuments(ctx, strategy, "other", docs)
+	_, _ = ScoreDocuments(WithQueryEmbedding(context.Background(), &batchEmbedder{}, "query", []float32{1, 5}), strategy, "query", docs)
+	if len(emb.batches) != 2 || len(emb.batches[0]) != 3 || len(emb.batches[1]) != 3 {
+		t.Errorf("batches = %v, want the query embedded in both", emb.batches)
+	}
+}
+
+func TestSearcher_UsesBatchEmbedder(t *testing.T) {
+	ctx := context.Background()
+	idx := NewInMemoryIndex()
+	for _, id := range []string{"a", "b", "c"} {
+		_ = idx.Add(ctx, Document{ID: id, Name: id})
+	}
+	emb := &batchEmbedder{}
+	hybrid, _ := NewHybridStrategy(NewBM25Strategy(nil), NewEmbeddingStrategy(emb), 0.5)
+
+	results, err := NewSearcher(idx, hybrid).Search(ctx, "a")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	if len(emb.batches) != 1 || emb.single != 0 {
+		t.Fatalf("batches = %d, single = %d; want 1 batch call", len(emb.batches), emb.single)
+	}
+}
+
+func TestVectorIndex_UpsertBatchChunks(t *testing.T) {
+	ctx := context.Background()
+	emb := &batchEmbedder{}
+	vi, err := NewVectorIndex(emb, "v1", VectorIndexOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	docs := []Document{{ID: "a", Text: "a"}, {ID: "b", Text: "bb"}, {ID: "c", Text: "ccc"}, {ID: "d", Text: "dddd"}, {ID: "e", Text: "eeeee"}}
+	if err := vi.UpsertBatch(ctx, docs); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+	if len(emb.batches) != 3 || len(emb.batches[0]) != 2 || len(emb.batches[2]) != 1 {
+		t.Fatalf("batches = %v, want chunks of 2, 2, and 1", emb.batches)
+	}
+	for i, doc := range docs {
+		sv, ok := vi.Vector(doc.ID)
+		if !ok || sv.Vector[1] != float32(i+1) {
+			t.Errorf("Vector(%s) = %v, %v; want length %d", doc.ID, sv.Vector, ok, i+1)
+		}
+	}
+
+	if _, err := NewVectorIndex(emb, "v1", VectorIndexOptions{BatchSize: -1}); !errors.Is(err, ErrInvalidBatchSize) {
+		t.Errorf("negative BatchSize: err = %v, want ErrInvalidBatchSize", err)
+	}
+}
+
+func TestVectorIndex_UpsertBatchUsesBatchEmbedder(t *testing.T) {
+	ctx := context.Background()
+	emb := &batchEmbedder{}
+	vi, err := NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	docs := []Document{{ID: "a", Text: "a"}, {ID: "b", Text: "bb"}}
+	if err := vi.UpsertBatch(ctx, docs); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+	if len(emb.batches) != 1 || emb.single != 0 {
+		t.Fatalf("batches = %d, single = %d; want 1 batch call", len(emb.batches), emb.single)
+	}
+	if err := vi.UpsertBatch(ctx, docs); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+	if len(emb.batches) != 1 {
+		t.Fatalf("unchanged documents were re-embedded: %v", emb.batches)
+	}
+	if err := vi.UpsertBatch(ctx, []Document{{ID: "c"}, {}}); !errors.Is(err, ErrInvalidDocumentID) {
+		t.Fatalf("expected ErrInvalidDocumentID, got %v", err)
+	}
+
+	next := &batchEmbedder{}
+	stats, err := vi.ReembedAll(ctx, ReembedOptions{Embedder: next, Model: "v2", BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	if stats.Batches != 1 || len(next.batches) != 1 || next.single != 0 {
+		t.Fatalf("campaign batches = %d, embed batches = %d, single = %d", stats.Batches, len(next.batches), next.single)
+	}
+}
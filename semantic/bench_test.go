@@ -3,6 +3,7 @@ package semantic
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"testing"
 )
 
@@ -259,3 +260,19 @@ func BenchmarkConcurrent_IndexAndSearch(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkMetric_Similarity1536(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	x, y := randomVector(r, 1536), randomVector(r, 1536)
+	for b.Loop() {
+		_ = MetricCosine.Similarity(x, y)
+	}
+}
+
+func BenchmarkMetric_QuantizedSimilarity1536(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	x, y := Quantize(randomVector(r, 1536)), Quantize(randomVector(r, 1536))
+	for b.Loop() {
+		_ = MetricCosine.QuantizedSimilarity(x, y)
+	}
+}
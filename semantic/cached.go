@@ -0,0 +1,136 @@
+package semantic
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+// CachedEmbedderOptions configures a CachedEmbedder.
+type CachedEmbedderOptions struct {
+	// Model names the embedding model and is part of every cache key, so
+	// vectors from different models sharing one cache never mix. Required.
+	Model string
+
+	// TTL expires cached vectors. Zero keeps them until evicted.
+	TTL time.Duration
+
+	// OnError is called when the cache fails. Cache failures never fail an
+	// Embed call; the embedder is used instead. Optional.
+	OnError func(error)
+}
+
+// CachedEmbedder wraps an Embedder with an EmbeddingCache, so replicas
+// sharing a cache embed each text once. It implements BatchEmbedder,
+// embedding only the texts missing from the cache.
+//
+// CachedEmbedder is safe for concurrent use when its embedder and cache are.
+type CachedEmbedder struct {
+	embedder Embedder
+	cache    EmbeddingCache
+	opts     CachedEmbedderOptions
+}
+
+// NewCachedEmbedder wraps embedder with c.
+func NewCachedEmbedder(embedder Embedder, c cache.Cache, opts CachedEmbedderOptions) (*CachedEmbedder, error) {
+	if c == nil {
+		return nil, cache.ErrInvalidCache
+	}
+	return NewEmbeddingCachedEmbedder(embedder, NewEmbeddingCache(c), opts)
+}
+
+// NewEmbeddingCachedEmbedder wraps embedder with an EmbeddingCache, such as
+// a FileEmbeddingCache.
+func NewEmbeddingCachedEmbedder(embedder Embedder, c EmbeddingCache, opts CachedEmbedderOptions) (*CachedEmbedder, error) {
+	if embedder == nil {
+		return nil, ErrInvalidEmbedder
+	}
+	if c == nil {
+		return nil, cache.ErrInvalidCache
+	}
+	if opts.Model == "" {
+		return nil, ErrInvalidModel
+	}
+	return &CachedEmbedder{embedder: embedder, cache: c, opts: opts}, nil
+}
+
+// Embed returns the cached vector for text, embedding and caching it on a
+// miss.
+func (e *CachedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	key := NewEmbeddingKey(e.opts.Model, text)
+	if vec, ok := e.lookup(ctx, key); ok {
+		return vec, nil
+	}
+	vec, err := e.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	e.store(ctx, key, vec)
+	return vec, nil
+}
+
+// EmbedBatch returns cached vectors and embeds the misses in one call to the
+// wrapped embedder (see EmbedTexts).
+func (e *CachedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	keys := make([]EmbeddingKey, len(texts))
+	var (
+		missing   []string
+		positions []int
+	)
+	for i, text := range texts {
+		keys[i] = NewEmbeddingKey(e.opts.Model, text)
+		if vec, ok := e.lookup(ctx, keys[i]); ok {
+			vectors[i] = vec
+			continue
+		}
+		missing = append(missing, text)
+		positions = append(positions, i)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+	embedded, err := EmbedTexts(ctx, e.embedder, missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, vec := range embedded {
+		i := positions[j]
+		vectors[i] = vec
+		e.store(ctx, keys[i], vec)
+	}
+	return vectors, nil
+}
+
+// Invalidate drops the cached vector for text, so the next Embed calls the
+// wrapped embedder.
+func (e *CachedEmbedder) Invalidate(ctx context.Context, text string) error {
+	return e.cache.Delete(ctx, NewEmbeddingKey(e.opts.Model, text))
+}
+
+func (e *CachedEmbedder) lookup(ctx context.Context, key EmbeddingKey) ([]float32, bool) {
+	vec, ok, err := e.cache.Get(ctx, key)
+	if err != nil {
+		e.report(err)
+		return nil, false
+	}
+	return vec, ok
+}
+
+func (e *CachedEmbedder) store(ctx context.Context, key EmbeddingKey, vec []float32) {
+	if err := e.cache.Set(ctx, key, vec, e.opts.TTL); err != nil {
+		e.report(err)
+	}
+}
+
+func (e *CachedEmbedder) report(err error) {
+	if e.opts.OnError != nil {
+		e.opts.OnError(err)
+	}
+}
+
+var (
+	_ Embedder      = (*CachedEmbedder)(nil)
+	_ BatchEmbedder = (*CachedEmbedder)(nil)
+)
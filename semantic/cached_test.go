@@ -0,0 +1,87 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+// failingCache fails every operation.
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("cache down")
+}
+func (failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("cache down")
+}
+func (failingCache) Delete(context.Context, string) error { return errors.New("cache down") }
+
+func TestCachedEmbedder(t *testing.T) {
+	ctx := context.Background()
+	shared := cache.NewLRU()
+	inner := &batchEmbedder{}
+	embedder, err := NewCachedEmbedder(inner, shared, CachedEmbedderOptions{Model: "m1"})
+	if err != nil {
+		t.Fatalf("NewCachedEmbedder error: %v", err)
+	}
+
+	first, _ := embedder.Embed(ctx, "hello")
+	second, _ := embedder.Embed(ctx, "hello")
+	if !slices.Equal(first, second) || inner.single != 1 {
+		t.Fatalf("expected one embed call and equal vectors, got %d calls, %v vs %v", inner.single, first, second)
+	}
+
+	// A second replica sharing the cache reuses the vector; the batch path
+	// embeds only the misses.
+	replica, _ := NewCachedEmbedder(inner, shared, CachedEmbedderOptions{Model: "m1"})
+	vectors, err := replica.EmbedBatch(ctx, []string{"hello", "abc", "hello"})
+	if err != nil {
+		t.Fatalf("EmbedBatch error: %v", err)
+	}
+	if len(inner.batches) != 1 || !slices.Equal(inner.batches[0], []string{"abc"}) {
+		t.Errorf("batches = %v, want [[abc]]", inner.batches)
+	}
+	if !slices.Equal(vectors[1], []float32{1, 3}) || !slices.Equal(vectors[2], first) {
+		t.Errorf("vectors = %v", vectors)
+	}
+
+	// Other models do not read m1 vectors.
+	other, _ := NewCachedEmbedder(inner, shared, CachedEmbedderOptions{Model: "m2"})
+	_, _ = other.Embed(ctx, "hello")
+	if inner.single != 2 {
+		t.Errorf("expected a fresh embed for another model, got %d calls", inner.single)
+	}
+}
+
+func TestCachedEmbedder_CacheFailure(t *testing.T) {
+	var reported int
+	embedder, _ := NewCachedEmbedder(&batchEmbedder{}, failingCache{}, CachedEmbedderOptions{
+		Model:   "m",
+		OnError: func(error) { reported++ },
+	})
+	vec, err := embedder.Embed(context.Background(), "hi")
+	if err != nil || !slices.Equal(vec, []float32{1, 2}) {
+		t.Fatalf("Embed = %v, %v; want fallback to the embedder", vec, err)
+	}
+	if reported != 2 {
+		t.Errorf("reported %d cache errors, want 2", reported)
+	}
+}
+
+func TestNewCachedEmbedder_Validation(t *testing.T) {
+	c := cache.NewLRU()
+	if _, err := NewCachedEmbedder(nil, c, CachedEmbedderOptions{Model: "m"}); !errors.Is(err, ErrInvalidEmbedder) {
+		t.Errorf("nil embedder error = %v", err)
+	}
+	if _, err := NewCachedEmbedder(&batchEmbedder{}, nil, CachedEmbedderOptions{Model: "m"}); !errors.Is(err, cache.ErrInvalidCache) {
+		t.Errorf("nil cache error = %v", err)
+	}
+	if _, err := NewCachedEmbedder(&batchEmbedder{}, c, CachedEmbedderOptions{}); !errors.Is(err, ErrInvalidModel) {
+		t.Errorf("missing model error = %v", err)
+	}
+}
@@ -27,6 +27,30 @@
 //	emb := semantic.NewEmbeddingStrategy(embedder)  // requires Embedder impl
 //	hybrid, _ := semantic.NewHybridStrategy(bm25, emb, 0.7)  // 70% BM25
 //
+// The default BM25 scorer splits text on whitespace, so "createIssue" does
+// not match "create_issue". Pass a [Tokenizer] to split identifiers, drop
+// stopwords, and stem; [NewAnalyzer] combines [IdentifierTokenizer],
+// [NewStopwordFilter], and [NewStemmingFilter]:
+//
+//	bm25 := semantic.NewBM25Strategy(nil, semantic.BM25Options{
+//		Tokenizer: semantic.NewAnalyzer(),
+//	})
+//
+// Embedding strategies compare vectors with cosine similarity by default.
+// Models trained for dot-product retrieval rank better with [MetricDot];
+// [MetricEuclidean] and [MetricAngular] are also available, for both
+// NewEmbeddingStrategyWithMetric and VectorIndexOptions.Metric:
+//
+//	emb, _ := semantic.NewEmbeddingStrategyWithMetric(embedder, semantic.MetricDot)
+//
+// Embedders with a bulk endpoint can also implement [BatchEmbedder]. The
+// built-in embedding strategies implement [BatchScorer], so
+// [InMemorySearcher], [VectorIndex], and discovery.HybridSearcher embed the
+// query and documents in one EmbedBatch call instead of one call per
+// document. [EmbedTexts] and [ScoreDocuments] apply the same detection for
+// custom code. A query already embedded elsewhere can be handed to them with
+// [WithQueryEmbedding], so scoring with the same embedder skips the query.
+//
 // # Document Model
 //
 // [Document] represents a tool for semantic indexing:
@@ -87,6 +111,30 @@
 //	gitDocs := semantic.FilterByNamespace(docs, "git")
 //	vcsDocs := semantic.FilterByTags(docs, []string{"vcs"})
 //
+// Or let the searcher filter before scoring, threshold, and page results:
+//
+//	opts := semantic.SearchOptions{Limit: 20, MinScore: 0.3, Namespaces: []string{"git"}}
+//	page, next, err := searcher.SearchPage(ctx, "commit changes", opts)
+//	// pass next as opts.Cursor for the following page
+//
+// Cursors become invalid ([ErrInvalidCursor]) when the query, filters, or
+// index contents change.
+//
+// # Boolean Queries
+//
+// [ParseQuery] parses a small query language for precise retrieval: AND, OR,
+// and NOT, "quoted phrases", parentheses, and namespace:, tag:, category:,
+// name:, and id: field prefixes. It compiles into a filter ([Query.Match])
+// and a scoring query ([Query.Text]) for any strategy. Top-level bare words
+// only rank results, so the query below keeps git tools tagged vcs and ranks
+// them by "commit":
+//
+//	opts := semantic.SearchOptions{Boolean: true}
+//	results, err := searcher.SearchWithOptions(ctx, "namespace:git tag:vcs NOT tag:deprecated commit", opts)
+//
+// search.BM25Config.BooleanQueries and discovery.HybridOptions.BooleanQueries
+// apply the same language to the BM25 and hybrid searchers.
+//
 // # Integration with index Package
 //
 // The [adapter.go] file provides conversion between index.SearchDoc and
@@ -98,10 +146,90 @@
 //	// Convert back after processing
 //	searchDocs := semantic.SearchDocsFromDocuments(semDocs)
 //
+// # Stored Vectors and Re-embedding
+//
+// [VectorIndex] stores document embeddings tagged with the model version
+// that produced them, and [NewVectorStrategy] scores against those vectors
+// instead of embedding every document per query. When the embedding model
+// changes, [VectorIndex.StaleIDs] reports drift and [VectorIndex.ReembedAll]
+// rebuilds vectors in rate-limited batches while searches keep using the old
+// ones until a single cutover:
+//
+//	vi, _ := semantic.NewVectorIndex(oldEmbedder, "text-embed-v1")
+//	strategy := semantic.NewVectorStrategy(vi)
+//
+//	stats, err := vi.ReembedAll(ctx, semantic.ReembedOptions{
+//	    Embedder:  newEmbedder,
+//	    Model:     "text-embed-v2",
+//	    BatchSize: 100,
+//	    Interval:  time.Second,
+//	})
+//
+// Vectors are keyed by a fingerprint of the document text: [VectorIndex.Upsert]
+// skips documents whose text is unchanged, and [VectorIndex.Similarities]
+// embeds the query once and only embeds new or changed documents.
+// [VectorIndex.Nearest] performs an exact nearest-neighbor scan over the
+// stored vectors.
+//
+// With [VectorIndexOptions].Quantize set, stored vectors are kept as int8
+// [QuantizedVector] values, a quarter of the float32 memory, and scored with
+// [Metric.QuantizedSimilarity] at roughly twice the speed for approximate
+// scores. [VectorIndex.Vector] and snapshots return dequantized vectors.
+//
+// [NewCachedEmbedder] wraps any Embedder with a [cache.Cache], so replicas
+// sharing a Redis cache embed each text once per model:
+//
+//	embedder, err := semantic.NewCachedEmbedder(remote, redisCache, semantic.CachedEmbedderOptions{
+//	    Model: "text-embed-v1",
+//	    TTL:   24 * time.Hour,
+//	})
+//
+// # Embedding Cache
+//
+// [EmbeddingCache] stores vectors keyed by model and text hash
+// ([EmbeddingKey]) with an optional TTL. [NewLRUEmbeddingCache] keeps them in
+// memory, [NewEmbeddingCache] adapts any [cache.Cache], and
+// [FileEmbeddingCache] persists them on disk across restarts, with
+// [FileEmbeddingCache.InvalidateModel] dropping a retired model's vectors.
+// [NewEmbeddingCachedEmbedder] wraps an Embedder with one, and
+// [NewCachedEmbeddingStrategy] builds an embedding strategy that consults the
+// cache before embedding queries and tool texts:
+//
+//	vectors, err := semantic.NewFileEmbeddingCache("/var/cache/tools/embeddings")
+//	strategy, err := semantic.NewCachedEmbeddingStrategy(remote, vectors, semantic.CachedEmbedderOptions{
+//	    Model: "text-embed-v1",
+//	    TTL:   7 * 24 * time.Hour,
+//	})
+//
+// [CachedEmbedder.Invalidate] drops one text's vector after a manual fix.
+//
+// # Keeping the Index in Sync
+//
+// [InMemoryIndex.Upsert] inserts or replaces a document and reports whether it
+// was new. Mutations bump [InMemoryIndex.Version] and are delivered to
+// listeners registered with [InMemoryIndex.OnChange], mirroring the index
+// package; identical upserts and removals of missing documents emit nothing:
+//
+//	unsubscribe := idx.OnChange(func(ev semantic.ChangeEvent) {
+//	    log.Printf("%s %s (v%d)", ev.Type, ev.DocumentID, ev.Version)
+//	})
+//	defer unsubscribe()
+//
+// # Persistence
+//
+// [InMemoryIndex.Save] and [InMemoryIndex.Load] write and read a versioned
+// binary snapshot so the semantic layer can be used standalone with durable
+// state. Pass SnapshotOptions.Vectors to persist embeddings as well:
+//
+//	err := idx.Save(f, semantic.SnapshotOptions{Vectors: vectors})
+//	// later
+//	err = idx.Load(f, semantic.SnapshotOptions{Vectors: vectors})
+//
 // # Thread Safety
 //
 // All types in this package are safe for concurrent use:
 //   - [InMemoryIndex] uses sync.RWMutex for thread-safe document storage
+//   - [VectorIndex] guards vectors and campaigns with sync.RWMutex
 //   - [InMemorySearcher] is stateless and safe for concurrent Search calls
 //   - All Strategy implementations are safe for concurrent Score calls
 //
@@ -112,6 +240,15 @@
 //   - [ErrInvalidDocumentID]: Document ID is empty
 //   - [ErrInvalidEmbedder]: Embedder is nil when required
 //   - [ErrInvalidHybridConfig]: Invalid hybrid strategy configuration
+//   - [ErrInvalidModel]: Model version label is empty
+//   - [ErrInvalidCacheDir]: File embedding cache directory is empty
+//   - [ErrCampaignRunning]: A re-embedding campaign is already in progress
+//   - [ErrInvalidBatchSize]: Negative re-embedding or upsert batch size
+//   - [ErrInvalidMetric]: Unknown similarity metric
+//   - [ErrInvalidSnapshot]: Corrupt or unsupported snapshot
+//   - [ErrInvalidCursor]: Malformed or stale pagination cursor
+//   - [ErrInvalidLimit]: Non-positive page size
+//   - [ErrInvalidBatchResult]: BatchEmbedder returned the wrong number of vectors
 //
 // Use errors.Is for error checking:
 //
@@ -0,0 +1,274 @@
+package semantic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+// ErrInvalidCacheDir is returned by NewFileEmbeddingCache when no directory
+// is given.
+var ErrInvalidCacheDir = errors.New("semantic: embedding cache directory is required")
+
+// ErrInvalidEmbeddingKey is returned by FileEmbeddingCache for a key that
+// cannot name a file: a model of "", ".", or "..", or a text hash that is
+// not hex.
+var ErrInvalidEmbeddingKey = errors.New("semantic: invalid embedding key")
+
+// EmbeddingKey identifies a cached vector by embedding model and text hash,
+// so vectors from different models never mix and texts are not stored in
+// keys.
+type EmbeddingKey struct {
+	Model    string
+	TextHash string // hex SHA-256 of the embedded text
+}
+
+// NewEmbeddingKey returns the key for text embedded by model.
+func NewEmbeddingKey(model, text string) EmbeddingKey {
+	sum := sha256.Sum256([]byte(text))
+	return EmbeddingKey{Model: model, TextHash: hex.EncodeToString(sum[:])}
+}
+
+// String returns the key as a flat cache key.
+func (k EmbeddingKey) String() string {
+	return "embedding:" + k.Model + ":" + k.TextHash
+}
+
+// EmbeddingCache stores embedding vectors consulted before calling an
+// Embedder.
+//
+// Contract:
+//   - Concurrency: implementations must be safe for concurrent use.
+//   - Context: must honor cancellation/deadlines.
+//   - Misses: Get reports a missing or expired key as (nil, false, nil);
+//     errors are reserved for backend failures.
+//   - TTL: a ttl of zero or less stores the vector without expiry.
+//   - Ownership: implementations must not retain vec after Set returns, and
+//     must return a vector the caller may modify.
+type EmbeddingCache interface {
+	Get(ctx context.Context, key EmbeddingKey) ([]float32, bool, error)
+	Set(ctx context.Context, key EmbeddingKey, vec []float32, ttl time.Duration) error
+	Delete(ctx context.Context, key EmbeddingKey) error
+}
+
+// NewEmbeddingCache stores vectors in c, such as a shared cache.Redis.
+func NewEmbeddingCache(c cache.Cache) EmbeddingCache {
+	return byteEmbeddingCache{cache: c}
+}
+
+// NewLRUEmbeddingCache returns an in-memory EmbeddingCache with
+// least-recently-used eviction.
+func NewLRUEmbeddingCache(opts ...cache.LRUOptions) EmbeddingCache {
+	return NewEmbeddingCache(cache.NewLRU(opts...))
+}
+
+type byteEmbeddingCache struct {
+	cache cache.Cache
+}
+
+func (c byteEmbeddingCache) Get(ctx context.Context, key EmbeddingKey) ([]float32, bool, error) {
+	data, ok, err := c.cache.Get(ctx, key.String())
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	vec, ok := decodeVector(data)
+	return vec, ok, nil
+}
+
+func (c byteEmbeddingCache) Set(ctx context.Context, key EmbeddingKey, vec []float32, ttl time.Duration) error {
+	return c.cache.Set(ctx, key.String(), encodeVector(nil, vec), ttl)
+}
+
+func (c byteEmbeddingCache) Delete(ctx context.Context, key EmbeddingKey) error {
+	return c.cache.Delete(ctx, key.String())
+}
+
+// FileEmbeddingCacheOptions configures a FileEmbeddingCache.
+type FileEmbeddingCacheOptions struct {
+	// Now returns the current time for TTL expiry. Default: time.Now.
+	Now func() time.Time
+}
+
+// FileEmbeddingCache is an EmbeddingCache that persists vectors under a
+// directory, one file per model and text hash, so embeddings survive
+// restarts. Expired entries are removed when read.
+//
+// FileEmbeddingCache is safe for concurrent use, including by several
+// processes sharing the directory: writes replace files atomically.
+type FileEmbeddingCache struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileEmbeddingCache creates the cache directory if needed and returns a
+// cache stored in it.
+func NewFileEmbeddingCache(dir string, opts ...FileEmbeddingCacheOptions) (*FileEmbeddingCache, error) {
+	if dir == "" {
+		return nil, ErrInvalidCacheDir
+	}
+	var opt FileEmbeddingCacheOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Now == nil {
+		opt.Now = time.Now
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create embedding cache dir: %w", err)
+	}
+	return &FileEmbeddingCache{dir: dir, now: opt.Now}, nil
+}
+
+// Get reads the vector stored for key.
+func (c *FileEmbeddingCache) Get(ctx context.Context, key EmbeddingKey) ([]float32, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	path, err := c.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) < 8 {
+		return nil, false, nil
+	}
+	if expires := int64(binary.LittleEndian.Uint64(data)); expires != 0 && c.now().UnixNano() >= expires {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	vec, ok := decodeVector(data[8:])
+	return vec, ok, nil
+}
+
+// Set writes vec for key, replacing any previous vector.
+func (c *FileEmbeddingCache) Set(ctx context.Context, key EmbeddingKey, vec []float32, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var expires int64
+	if ttl > 0 {
+		expires = c.now().Add(ttl).UnixNano()
+	}
+	data := binary.LittleEndian.AppendUint64(make([]byte, 0, 8+4*len(vec)), uint64(expires))
+	data = encodeVector(data, vec)
+
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes the vector stored for key.
+func (c *FileEmbeddingCache) Delete(ctx context.Context, key EmbeddingKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// InvalidateModel removes every vector cached for model, for example after
+// the model is retired or its output changes.
+func (c *FileEmbeddingCache) InvalidateModel(model string) error {
+	dir, err := c.modelDir(model)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// modelDir returns the directory holding the vectors of model. Models that
+// would escape to the cache directory itself or its parent are rejected.
+func (c *FileEmbeddingCache) modelDir(model string) (string, error) {
+	name := url.PathEscape(model)
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("%w: model %q", ErrInvalidEmbeddingKey, model)
+	}
+	return filepath.Join(c.dir, name), nil
+}
+
+func (c *FileEmbeddingCache) path(key EmbeddingKey) (string, error) {
+	dir, err := c.modelDir(key.Model)
+	if err != nil {
+		return "", err
+	}
+	if _, err := hex.DecodeString(key.TextHash); err != nil || key.TextHash == "" {
+		return "", fmt.Errorf("%w: text hash %q", ErrInvalidEmbeddingKey, key.TextHash)
+	}
+	return filepath.Join(dir, key.TextHash+".vec"), nil
+}
+
+func encodeVector(dst []byte, vec []float32) []byte {
+	for _, v := range vec {
+		dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(v))
+	}
+	return dst
+}
+
+func decodeVector(data []byte) ([]float32, bool) {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil, false
+	}
+	vec := make([]float32, len(data)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vec, true
+}
+
+// NewCachedEmbeddingStrategy creates an embedding-only strategy that
+// consults c before calling embedder, so identical queries and tool texts
+// are embedded once per opts.Model. Use NewEmbeddingStrategyWithMetric with
+// a CachedEmbedder for other metrics.
+func NewCachedEmbeddingStrategy(embedder Embedder, c EmbeddingCache, opts CachedEmbedderOptions) (Strategy, error) {
+	cached, err := NewEmbeddingCachedEmbedder(embedder, c, opts)
+	if err != nil {
+		return nil, err
+	}
+	return embeddingStrategy{embedder: cached}, nil
+}
+
+var (
+	_ EmbeddingCache = byteEmbeddingCache{}
+	_ EmbeddingCache = (*FileEmbeddingCache)(nil)
+)
@@ -0,0 +1,131 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jonwraymond/tooldiscovery/cache"
+)
+
+func TestEmbeddingCaches(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+	file, err := NewFileEmbeddingCache(t.TempDir(), FileEmbeddingCacheOptions{Now: clock})
+	if err != nil {
+		t.Fatalf("NewFileEmbeddingCache error: %v", err)
+	}
+	caches := map[string]EmbeddingCache{
+		"lru":  NewLRUEmbeddingCache(cache.LRUOptions{Now: clock}),
+		"file": file,
+	}
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := NewEmbeddingKey("org/model-v1", "create an issue")
+			if _, ok, err := c.Get(ctx, key); ok || err != nil {
+				t.Fatalf("Get on empty cache = %v, %v", ok, err)
+			}
+			if err := c.Set(ctx, key, []float32{0.5, -1, 3}, time.Minute); err != nil {
+				t.Fatalf("Set error: %v", err)
+			}
+			vec, ok, err := c.Get(ctx, key)
+			if err != nil || !ok || !slices.Equal(vec, []float32{0.5, -1, 3}) {
+				t.Fatalf("Get = %v, %v, %v", vec, ok, err)
+			}
+			if _, ok, _ := c.Get(ctx, NewEmbeddingKey("org/model-v2", "create an issue")); ok {
+				t.Error("vector leaked across models")
+			}
+
+			now = now.Add(2 * time.Minute)
+			if _, ok, _ := c.Get(ctx, key); ok {
+				t.Error("expected entry to expire after TTL")
+			}
+
+			_ = c.Set(ctx, key, []float32{1}, 0)
+			if err := c.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete error: %v", err)
+			}
+			if _, ok, _ := c.Get(ctx, key); ok {
+				t.Error("expected entry to be deleted")
+			}
+		})
+	}
+}
+
+func TestFileEmbeddingCache_Persists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	first, _ := NewFileEmbeddingCache(dir)
+	key := NewEmbeddingKey("m1", "hello")
+	_ = first.Set(ctx, key, []float32{1, 2}, 0)
+
+	second, _ := NewFileEmbeddingCache(dir)
+	if vec, ok, _ := second.Get(ctx, key); !ok || !slices.Equal(vec, []float32{1, 2}) {
+		t.Fatalf("reopened cache Get = %v, %v", vec, ok)
+	}
+	if err := second.InvalidateModel("m1"); err != nil {
+		t.Fatalf("InvalidateModel error: %v", err)
+	}
+	if _, ok, _ := first.Get(ctx, key); ok {
+		t.Error("expected model vectors to be invalidated")
+	}
+	if _, err := NewFileEmbeddingCache(""); !errors.Is(err, ErrInvalidCacheDir) {
+		t.Errorf("empty dir err = %v", err)
+	}
+
+	other := NewEmbeddingKey("m2", "hello")
+	_ = first.Set(ctx, other, []float32{3}, 0)
+	for _, model := range []string{"", ".", ".."} {
+		if err := first.InvalidateModel(model); !errors.Is(err, ErrInvalidEmbeddingKey) {
+			t.Errorf("InvalidateModel(%q) error = %v, want ErrInvalidEmbeddingKey", model, err)
+		}
+		if err := first.Set(ctx, NewEmbeddingKey(model, "hello"), []float32{1}, 0); !errors.Is(err, ErrInvalidEmbeddingKey) {
+			t.Errorf("Set(model %q) error = %v, want ErrInvalidEmbeddingKey", model, err)
+		}
+	}
+	if err := first.Set(ctx, EmbeddingKey{Model: "m2", TextHash: "../escape"}, []float32{1}, 0); !errors.Is(err, ErrInvalidEmbeddingKey) {
+		t.Errorf("Set(bad hash) error = %v, want ErrInvalidEmbeddingKey", err)
+	}
+	if vec, ok, _ := first.Get(ctx, other); !ok || !slices.Equal(vec, []float32{3}) {
+		t.Errorf("other model Get = %v, %v; want it kept", vec, ok)
+	}
+}
+
+func TestCachedEmbeddingStrategy(t *testing.T) {
+	ctx := context.Background()
+	inner := &batchEmbedder{}
+	strategy, err := NewCachedEmbeddingStrategy(inner, NewLRUEmbeddingCache(), CachedEmbedderOptions{Model: "m1"})
+	if err != nil {
+		t.Fatalf("NewCachedEmbeddingStrategy error: %v", err)
+	}
+	doc := Document{ID: "a", Text: "create issue"}
+	for range 3 {
+		if _, err := strategy.Score(ctx, "issue", doc); err != nil {
+			t.Fatalf("Score error: %v", err)
+		}
+	}
+	// One embed for the query and one for the document.
+	if inner.single != 2 {
+		t.Errorf("embed calls = %d, want 2", inner.single)
+	}
+	if _, err := NewCachedEmbeddingStrategy(inner, nil, CachedEmbedderOptions{Model: "m1"}); !errors.Is(err, cache.ErrInvalidCache) {
+		t.Errorf("nil cache err = %v", err)
+	}
+}
+
+func TestCachedEmbedder_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	inner := &batchEmbedder{}
+	embedder, _ := NewEmbeddingCachedEmbedder(inner, NewLRUEmbeddingCache(), CachedEmbedderOptions{Model: "m1"})
+	_, _ = embedder.Embed(ctx, "hello")
+	if err := embedder.Invalidate(ctx, "hello"); err != nil {
+		t.Fatalf("Invalidate error: %v", err)
+	}
+	_, _ = embedder.Embed(ctx, "hello")
+	if inner.single != 2 {
+		t.Errorf("embed calls = %d, want 2 after invalidation", inner.single)
+	}
+}
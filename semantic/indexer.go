@@ -3,6 +3,7 @@ package semantic
 import (
 	"context"
 	"errors"
+	"reflect"
 	"sort"
 	"sync"
 )
@@ -24,10 +25,41 @@ type Indexer interface {
 	List(ctx context.Context) []Document
 }
 
+// ChangeType describes a semantic index mutation.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeUpdated ChangeType = "updated"
+	ChangeRemoved ChangeType = "removed"
+	ChangeLoaded  ChangeType = "loaded"
+)
+
+// ChangeEvent describes a semantic index mutation. DocumentID is empty for
+// ChangeLoaded, which replaces the whole index.
+type ChangeEvent struct {
+	Type       ChangeType
+	DocumentID string
+	Version    uint64
+}
+
+// ChangeListener receives semantic index change events.
+// Listeners are called synchronously after the mutation completes and must
+// not block.
+type ChangeListener func(ChangeEvent)
+
 // InMemoryIndex is a thread-safe in-memory document index.
 type InMemoryIndex struct {
-	mu   sync.RWMutex
-	docs map[string]Document
+	mu             sync.RWMutex
+	docs           map[string]Document
+	version        uint64
+	listeners      []listenerEntry
+	nextListenerID uint64
+}
+
+type listenerEntry struct {
+	id uint64
+	fn ChangeListener
 }
 
 // NewInMemoryIndex creates a new in-memory index.
@@ -36,34 +68,115 @@ func NewInMemoryIndex() *InMemoryIndex {
 }
 
 // Add inserts or updates a document in the index.
-func (i *InMemoryIndex) Add(_ context.Context, doc Document) error {
+func (i *InMemoryIndex) Add(ctx context.Context, doc Document) error {
+	_, err := i.Upsert(ctx, doc)
+	return err
+}
+
+// Update updates a document by ID. If it doesn't exist, it is inserted.
+func (i *InMemoryIndex) Update(ctx context.Context, doc Document) error {
+	_, err := i.Upsert(ctx, doc)
+	return err
+}
+
+// Upsert inserts a document or replaces the document with the same ID, and
+// reports whether it was inserted. Listeners receive ChangeAdded or
+// ChangeUpdated; replacing a document with an identical one is a no-op and
+// emits nothing.
+func (i *InMemoryIndex) Upsert(_ context.Context, doc Document) (bool, error) {
 	if doc.ID == "" {
-		return ErrInvalidDocumentID
+		return false, ErrInvalidDocumentID
 	}
 
 	norm := doc.Normalized()
 	i.mu.Lock()
+	prev, exists := i.docs[doc.ID]
+	if exists && reflect.DeepEqual(prev, norm) {
+		i.mu.Unlock()
+		return false, nil
+	}
 	i.docs[doc.ID] = norm
+	i.version++
+	event := ChangeEvent{Type: ChangeAdded, DocumentID: doc.ID, Version: i.version}
+	if exists {
+		event.Type = ChangeUpdated
+	}
+	listeners := i.snapshotListenersLocked()
 	i.mu.Unlock()
-	return nil
-}
 
-// Update updates a document by ID. If it doesn't exist, it is inserted.
-func (i *InMemoryIndex) Update(ctx context.Context, doc Document) error {
-	return i.Add(ctx, doc)
+	notifyListeners(listeners, event)
+	return !exists, nil
 }
 
-// Remove deletes a document by ID.
+// Remove deletes a document by ID. Removing a missing document is a no-op
+// and emits nothing.
 func (i *InMemoryIndex) Remove(_ context.Context, id string) error {
 	if id == "" {
 		return ErrInvalidDocumentID
 	}
 	i.mu.Lock()
+	if _, ok := i.docs[id]; !ok {
+		i.mu.Unlock()
+		return nil
+	}
 	delete(i.docs, id)
+	i.version++
+	event := ChangeEvent{Type: ChangeRemoved, DocumentID: id, Version: i.version}
+	listeners := i.snapshotListenersLocked()
 	i.mu.Unlock()
+
+	notifyListeners(listeners, event)
 	return nil
 }
 
+// Version returns the index version, incremented on every change.
+func (i *InMemoryIndex) Version() uint64 {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.version
+}
+
+// OnChange registers a listener for index mutations.
+// Returns an unsubscribe function.
+func (i *InMemoryIndex) OnChange(listener ChangeListener) func() {
+	if listener == nil {
+		return func() {}
+	}
+	i.mu.Lock()
+	i.nextListenerID++
+	entry := listenerEntry{id: i.nextListenerID, fn: listener}
+	i.listeners = append(i.listeners, entry)
+	i.mu.Unlock()
+
+	return func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for n, e := range i.listeners {
+			if e.id == entry.id {
+				i.listeners = append(i.listeners[:n], i.listeners[n+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (i *InMemoryIndex) snapshotListenersLocked() []ChangeListener {
+	if len(i.listeners) == 0 {
+		return nil
+	}
+	out := make([]ChangeListener, len(i.listeners))
+	for n, entry := range i.listeners {
+		out[n] = entry.fn
+	}
+	return out
+}
+
+func notifyListeners(listeners []ChangeListener, event ChangeEvent) {
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
 // Get retrieves a document by ID.
 func (i *InMemoryIndex) Get(_ context.Context, id string) (Document, bool) {
 	i.mu.RLock()
@@ -1,7 +1,9 @@
 package semantic
 
 import (
+	"bytes"
 	"context"
+	"reflect"
 	"testing"
 )
 
@@ -70,3 +72,60 @@ func TestIndexerContract_InvalidID(t *testing.T) {
 		t.Fatalf("expected error for empty ID on remove")
 	}
 }
+
+func TestInMemoryIndex_UpsertChangeEvents(t *testing.T) {
+	idx := NewInMemoryIndex()
+	ctx := context.Background()
+
+	var events []ChangeEvent
+	unsubscribe := idx.OnChange(func(ev ChangeEvent) { events = append(events, ev) })
+
+	created, err := idx.Upsert(ctx, Document{ID: "tool-1", Name: "Search"})
+	if err != nil || !created {
+		t.Fatalf("Upsert(new) = %v, %v; want created", created, err)
+	}
+	created, err = idx.Upsert(ctx, Document{ID: "tool-1", Name: "Search"})
+	if err != nil || created {
+		t.Fatalf("Upsert(identical) = %v, %v; want no-op", created, err)
+	}
+	if created, _ = idx.Upsert(ctx, Document{ID: "tool-1", Name: "SearchV2"}); created {
+		t.Fatal("Upsert(changed) reported created")
+	}
+	_ = idx.Remove(ctx, "tool-1")
+	_ = idx.Remove(ctx, "tool-1")
+
+	want := []ChangeEvent{
+		{Type: ChangeAdded, DocumentID: "tool-1", Version: 1},
+		{Type: ChangeUpdated, DocumentID: "tool-1", Version: 2},
+		{Type: ChangeRemoved, DocumentID: "tool-1", Version: 3},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	if idx.Version() != 3 {
+		t.Errorf("Version() = %d, want 3", idx.Version())
+	}
+
+	unsubscribe()
+	_ = idx.Add(ctx, Document{ID: "tool-2", Name: "Other"})
+	if len(events) != 3 {
+		t.Errorf("listener called after unsubscribe: %+v", events[3:])
+	}
+}
+
+func TestInMemoryIndex_LoadEmitsChange(t *testing.T) {
+	src := NewInMemoryIndex()
+	_ = src.Add(context.Background(), Document{ID: "a", Name: "a"})
+	var buf bytes.Buffer
+	_ = src.Save(&buf)
+
+	dst := NewInMemoryIndex()
+	var got []ChangeEvent
+	dst.OnChange(func(ev ChangeEvent) { got = append(got, ev) })
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Type != ChangeLoaded || got[0].Version != 1 {
+		t.Fatalf("events = %+v, want one ChangeLoaded", got)
+	}
+}
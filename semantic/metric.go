@@ -0,0 +1,144 @@
+package semantic
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidMetric is returned for unknown similarity metrics.
+var ErrInvalidMetric = errors.New("semantic: unknown similarity metric")
+
+// Metric selects how two embeddings are compared. All metrics return a
+// similarity where higher is better. The zero value is MetricCosine.
+type Metric string
+
+const (
+	// MetricCosine is the cosine of the angle between vectors, in [-1, 1].
+	MetricCosine Metric = "cosine"
+
+	// MetricDot is the raw dot product. Use it for models trained for
+	// maximum inner product retrieval, where vector magnitude carries signal.
+	MetricDot Metric = "dot"
+
+	// MetricEuclidean maps Euclidean distance d to 1/(1+d), in (0, 1].
+	MetricEuclidean Metric = "euclidean"
+
+	// MetricAngular maps the angle θ between vectors to 1-θ/π, in [0, 1].
+	// It ranks like cosine but is a proper metric with linear spacing.
+	MetricAngular Metric = "angular"
+)
+
+// Valid reports whether m is a known metric or the zero value.
+func (m Metric) Valid() bool {
+	switch m {
+	case "", MetricCosine, MetricDot, MetricEuclidean, MetricAngular:
+		return true
+	}
+	return false
+}
+
+// Similarity compares a and b. Vectors of different or zero length score 0.
+func (m Metric) Similarity(a, b []float32) float64 {
+	if len(a) == 0 || len(b) == 0 || len(a) != len(b) {
+		return 0
+	}
+	switch m {
+	case MetricDot:
+		return dotProduct(a, b)
+	case MetricEuclidean:
+		return 1 / (1 + euclideanDistance(a, b))
+	case MetricAngular:
+		cos := cosineSimilarity(a, b)
+		if cos == 0 && (isZero(a) || isZero(b)) {
+			return 0
+		}
+		return 1 - math.Acos(max(-1, min(1, cos)))/math.Pi
+	default:
+		return cosineSimilarity(a, b)
+	}
+}
+
+// NewEmbeddingStrategyWithMetric creates an embedding-only strategy that
+// compares vectors with metric.
+func NewEmbeddingStrategyWithMetric(embedder Embedder, metric Metric) (Strategy, error) {
+	if !metric.Valid() {
+		return nil, ErrInvalidMetric
+	}
+	return embeddingStrategy{embedder: embedder, metric: metric}, nil
+}
+
+// The similarity kernels below process vectors in small chunks with
+// independent accumulators, after reslicing so the compiler can drop bounds
+// checks. This breaks the dependency chain of a single running sum, which
+// lets the loops pipeline and auto-vectorize. Callers ensure equal lengths.
+
+func dotProduct(a, b []float32) float64 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += float64(a[i]) * float64(b[i])
+		s1 += float64(a[i+1]) * float64(b[i+1])
+		s2 += float64(a[i+2]) * float64(b[i+2])
+		s3 += float64(a[i+3]) * float64(b[i+3])
+	}
+	for ; i < len(a); i++ {
+		s0 += float64(a[i]) * float64(b[i])
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func euclideanDistance(a, b []float32) float64 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0 := float64(a[i]) - float64(b[i])
+		d1 := float64(a[i+1]) - float64(b[i+1])
+		d2 := float64(a[i+2]) - float64(b[i+2])
+		d3 := float64(a[i+3]) - float64(b[i+3])
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := float64(a[i]) - float64(b[i])
+		s0 += d * d
+	}
+	return math.Sqrt((s0 + s1) + (s2 + s3))
+}
+
+// dotAndNorms returns the dot product of a and b and their squared norms
+// in one pass.
+func dotAndNorms(a, b []float32) (dot, normA, normB float64) {
+	b = b[:len(a)]
+	var d0, d1, a0, a1, b0, b1 float64
+	i := 0
+	for ; i+2 <= len(a); i += 2 {
+		x0, x1 := float64(a[i]), float64(a[i+1])
+		y0, y1 := float64(b[i]), float64(b[i+1])
+		d0 += x0 * y0
+		d1 += x1 * y1
+		a0 += x0 * x0
+		a1 += x1 * x1
+		b0 += y0 * y0
+		b1 += y1 * y1
+	}
+	if i < len(a) {
+		x, y := float64(a[i]), float64(b[i])
+		d0 += x * y
+		a0 += x * x
+		b0 += y * y
+	}
+	return d0 + d1, a0 + a1, b0 + b1
+}
+
+func isZero(v []float32) bool {
+	for _, x := range v {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
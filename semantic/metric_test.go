@@ -0,0 +1,95 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMetric_Similarity(t *testing.T) {
+	a := []float32{1, 0}
+	b := []float32{0, 2}
+	c := []float32{3, 0}
+	tests := []struct {
+		metric Metric
+		x, y   []float32
+		want   float64
+	}{
+		{MetricCosine, a, c, 1},
+		{"", a, b, 0},
+		{MetricDot, a, c, 3},
+		{MetricDot, a, b, 0},
+		{MetricEuclidean, a, a, 1},
+		{MetricEuclidean, a, c, 1.0 / 3},
+		{MetricAngular, a, c, 1},
+		{MetricAngular, a, b, 0.5},
+		{MetricAngular, a, []float32{-1, 0}, 0},
+		{MetricAngular, a, []float32{0, 0}, 0},
+		{MetricDot, a, []float32{1, 0, 0}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.metric.Similarity(tt.x, tt.y); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q.Similarity(%v, %v) = %v, want %v", tt.metric, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestEmbeddingStrategyWithMetric_DotRanksByMagnitude(t *testing.T) {
+	ctx := context.Background()
+	query := []float32{1, 1}
+	small := stubEmbedder{queryVec: query, docVec: []float32{1, 1}}
+	large := stubEmbedder{queryVec: query, docVec: []float32{4, 4}}
+
+	score := func(metric Metric, emb Embedder) float64 {
+		t.Helper()
+		s, err := NewEmbeddingStrategyWithMetric(emb, metric)
+		if err != nil {
+			t.Fatalf("NewEmbeddingStrategyWithMetric failed: %v", err)
+		}
+		v, err := s.Score(ctx, "query", Document{ID: "d", Text: "doc"})
+		if err != nil {
+			t.Fatalf("Score failed: %v", err)
+		}
+		return v
+	}
+	if score(MetricCosine, small) != score(MetricCosine, large) {
+		t.Error("cosine should ignore magnitude")
+	}
+	if score(MetricDot, large) <= score(MetricDot, small) {
+		t.Error("dot product should rank the larger vector higher")
+	}
+
+	if _, err := NewEmbeddingStrategyWithMetric(small, "manhattan"); !errors.Is(err, ErrInvalidMetric) {
+		t.Errorf("expected ErrInvalidMetric, got %v", err)
+	}
+}
+
+func TestVectorIndex_Metric(t *testing.T) {
+	emb := &modelEmbedder{vec: []float32{2, 0}}
+	if _, err := NewVectorIndex(emb, "v1", VectorIndexOptions{Metric: "bogus"}); !errors.Is(err, ErrInvalidMetric) {
+		t.Fatalf("expected ErrInvalidMetric, got %v", err)
+	}
+	idx, err := NewVectorIndex(emb, "v1", VectorIndexOptions{Metric: MetricDot})
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	_ = idx.Upsert(context.Background(), Document{ID: "a", Name: "a"})
+
+	score, _ := NewVectorStrategy(idx).Score(context.Background(), "query", Document{ID: "a"})
+	if score != 4 {
+		t.Errorf("dot score = %v, want 4", score)
+	}
+
+	// A campaign can switch the metric along with the model.
+	if _, err := idx.ReembedAll(context.Background(), ReembedOptions{Embedder: emb, Model: "v2", Metric: MetricEuclidean}); err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	if idx.Metric() != MetricEuclidean {
+		t.Errorf("Metric() = %q, want euclidean", idx.Metric())
+	}
+	score, _ = NewVectorStrategy(idx).Score(context.Background(), "query", Document{ID: "a"})
+	if score != 1 {
+		t.Errorf("euclidean score = %v, want 1", score)
+	}
+}
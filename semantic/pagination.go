@@ -0,0 +1,205 @@
+package semantic
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("semantic: invalid cursor")
+	ErrInvalidLimit  = errors.New("semantic: limit must be positive")
+)
+
+// SearchOptions narrows and bounds a search.
+type SearchOptions struct {
+	// Limit caps the number of results. Zero means no limit for Search and is
+	// invalid for SearchPage.
+	Limit int
+
+	// MinScore drops results scoring below it. Zero disables the threshold.
+	MinScore float64
+
+	// Namespaces restricts results to documents in any of these namespaces.
+	Namespaces []string
+
+	// Tags restricts results to documents carrying any of these tags
+	// (case-insensitive).
+	Tags []string
+
+	// Boolean parses the query with ParseQuery, so AND, OR, NOT, quoted
+	// phrases, and field prefixes filter results and the remaining words
+	// rank them. Invalid queries return ErrInvalidQuery.
+	Boolean bool
+
+	// Cursor resumes a SearchPage from a previous page's next cursor.
+	Cursor string
+}
+
+// versioned is implemented by indexers that track a change version, such as
+// InMemoryIndex. Cursors are bound to the version so changes invalidate them.
+type versioned interface {
+	Version() uint64
+}
+
+// SearchWithOptions scores the documents that pass the namespace and tag
+// filters, and the query's own filters with opts.Boolean, and returns results ordered by score desc, ID asc, with MinScore
+// and Limit applied. opts.Cursor is ignored.
+func (s *InMemorySearcher) SearchWithOptions(ctx context.Context, query string, opts SearchOptions) ([]Result, error) {
+	if opts.Limit < 0 {
+		return nil, ErrInvalidLimit
+	}
+	results, err := s.searchFiltered(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// SearchPage returns one page of SearchWithOptions results and the cursor for
+// the next page, or "" on the last page. Cursors are opaque and become invalid
+// (ErrInvalidCursor) when the query, filters, or index contents change.
+func (s *InMemorySearcher) SearchPage(ctx context.Context, query string, opts SearchOptions) ([]Result, string, error) {
+	if opts.Limit <= 0 {
+		return nil, "", ErrInvalidLimit
+	}
+	checksum := s.cursorChecksum(query, opts)
+	offset, err := decodeCursor(opts.Cursor, checksum)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results, err := s.searchFiltered(ctx, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(results) {
+		return []Result{}, "", nil
+	}
+	end := min(offset+opts.Limit, len(results))
+	next := ""
+	if end < len(results) {
+		next = encodeCursor(end, checksum)
+	}
+	return results[offset:end], next, nil
+}
+
+func (s *InMemorySearcher) searchFiltered(ctx context.Context, query string, opts SearchOptions) ([]Result, error) {
+	if s.index == nil || s.strategy == nil {
+		return nil, ErrInvalidSearcher
+	}
+
+	docs := s.index.List(ctx)
+	if len(opts.Namespaces) > 0 {
+		want := make(map[string]struct{}, len(opts.Namespaces))
+		for _, ns := range opts.Namespaces {
+			want[ns] = struct{}{}
+		}
+		filtered := docs[:0]
+		for _, doc := range docs {
+			if _, ok := want[doc.Namespace]; ok {
+				filtered = append(filtered, doc)
+			}
+		}
+		docs = filtered
+	}
+	if len(opts.Tags) > 0 {
+		docs = FilterByTags(docs, opts.Tags)
+	}
+	if opts.Boolean {
+		parsed, err := ParseQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		docs = parsed.Filter(docs)
+		query = parsed.Text()
+	}
+
+	scores := make([]float64, len(docs))
+	if !opts.Boolean || query != "" {
+		var err error
+		if scores, err = ScoreDocuments(ctx, s.strategy, query, docs); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]Result, 0, len(docs))
+	for i, doc := range docs {
+		score := scores[i]
+		if opts.MinScore != 0 && score < opts.MinScore {
+			continue
+		}
+		results = append(results, Result{Document: doc, Score: score})
+	}
+	sortResults(results)
+	return results, nil
+}
+
+// cursorChecksum binds a cursor to the query, filters, and index version.
+func (s *InMemorySearcher) cursorChecksum(query string, opts SearchOptions) uint64 {
+	h := fnv.New64a()
+	write := func(v string) {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	write(query)
+	write(strings.Join(opts.Namespaces, "\x00"))
+	write(strings.Join(opts.Tags, "\x00"))
+	write(strconv.FormatBool(opts.Boolean))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(opts.MinScore))
+	_, _ = h.Write(buf[:])
+	if v, ok := s.index.(versioned); ok {
+		binary.BigEndian.PutUint64(buf[:], v.Version())
+		_, _ = h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+type cursorToken struct {
+	Offset   int    `json:"offset"`
+	Checksum uint64 `json:"checksum"`
+}
+
+func encodeCursor(offset int, checksum uint64) string {
+	payload, _ := json.Marshal(cursorToken{Offset: offset, Checksum: checksum})
+	return base64.StdEncoding.EncodeToString(payload)
+}
+
+func decodeCursor(cursor string, checksum uint64) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var token cursorToken
+	if err := json.Unmarshal(decoded, &token); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if token.Offset < 0 || token.Checksum != checksum {
+		return 0, ErrInvalidCursor
+	}
+	return token.Offset, nil
+}
+
+// sortResults orders results by score desc, then ID asc.
+func sortResults(results []Result) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Document.ID < results[j].Document.ID
+		}
+		return results[i].Score > results[j].Score
+	})
+}
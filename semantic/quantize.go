@@ -0,0 +1,106 @@
+package semantic
+
+import "math"
+
+// QuantizedVector is an int8 embedding with a per-vector scale: component i
+// approximates Values[i] * Scale. It takes a quarter of the memory of the
+// float32 vector it came from.
+type QuantizedVector struct {
+	Values []int8
+	Scale  float32
+
+	sumSquares int64 // cached by Quantize; 0 means not computed
+}
+
+// Quantize maps vec to int8 with symmetric scaling, so the component with
+// the largest magnitude becomes ±127. Each component is off by at most
+// Scale/2.
+func Quantize(vec []float32) QuantizedVector {
+	var maxAbs float32
+	for _, x := range vec {
+		maxAbs = max(maxAbs, float32(math.Abs(float64(x))))
+	}
+	q := QuantizedVector{Values: make([]int8, len(vec))}
+	if maxAbs == 0 {
+		return q
+	}
+	q.Scale = maxAbs / 127
+	inv := 127 / maxAbs
+	for i, x := range vec {
+		q.Values[i] = int8(math.Round(float64(x * inv)))
+	}
+	q.sumSquares = dotInt8(q.Values, q.Values)
+	return q
+}
+
+// Dequantize returns the float32 approximation of q.
+func (q QuantizedVector) Dequantize() []float32 {
+	out := make([]float32, len(q.Values))
+	for i, x := range q.Values {
+		out[i] = float32(x) * q.Scale
+	}
+	return out
+}
+
+// QuantizedSimilarity compares quantized vectors like Similarity compares
+// their float32 originals, computing on the int8 values directly. Results
+// are approximate; cosine is typically within 0.01 of the exact score.
+func (m Metric) QuantizedSimilarity(a, b QuantizedVector) float64 {
+	if len(a.Values) == 0 || len(b.Values) == 0 || len(a.Values) != len(b.Values) {
+		return 0
+	}
+	dot := float64(dotInt8(a.Values, b.Values))
+	sa, sb := float64(a.Scale), float64(b.Scale)
+	if m == MetricDot {
+		return dot * sa * sb
+	}
+	normA, normB := float64(a.squares()), float64(b.squares())
+	if m == MetricEuclidean {
+		// |a-b|² = |a|² + |b|² - 2a·b, clamped against rounding.
+		sq := sa*sa*normA + sb*sb*normB - 2*sa*sb*dot
+		return 1 / (1 + math.Sqrt(max(sq, 0)))
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	// Scales cancel out of the cosine.
+	cos := dot / (math.Sqrt(normA) * math.Sqrt(normB))
+	if m == MetricAngular {
+		return 1 - math.Acos(max(-1, min(1, cos)))/math.Pi
+	}
+	return cos
+}
+
+// squares returns the sum of squared values, computing it for vectors not
+// built by Quantize.
+func (q QuantizedVector) squares() int64 {
+	if q.sumSquares != 0 {
+		return q.sumSquares
+	}
+	return dotInt8(q.Values, q.Values)
+}
+
+// dotInt8 returns the dot product of int8 vectors of equal length. Like the
+// float kernels it sums in independent lanes over fixed-size chunks; int32
+// lanes cannot overflow for vectors under a million components.
+func dotInt8(a, b []int8) int64 {
+	b = b[:len(a)]
+	var d0, d1, d2, d3, d4, d5, d6, d7 int32
+	i := 0
+	for ; i+8 <= len(a); i += 8 {
+		x := a[i : i+8 : i+8]
+		y := b[i : i+8 : i+8]
+		d0 += int32(x[0]) * int32(y[0])
+		d1 += int32(x[1]) * int32(y[1])
+		d2 += int32(x[2]) * int32(y[2])
+		d3 += int32(x[3]) * int32(y[3])
+		d4 += int32(x[4]) * int32(y[4])
+		d5 += int32(x[5]) * int32(y[5])
+		d6 += int32(x[6]) * int32(y[6])
+		d7 += int32(x[7]) * int32(y[7])
+	}
+	for ; i < len(a); i++ {
+		d0 += int32(a[i]) * int32(b[i])
+	}
+	return int64(d0) + int64(d1) + int64(d2) + int64(d3) + int64(d4) + int64(d5) + int64(d6) + int64(d7)
+}
@@ -0,0 +1,109 @@
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func randomVector(r *rand.Rand, dim int) []float32 {
+	vec := make([]float32, dim)
+	for i := range vec {
+		vec[i] = float32(r.NormFloat64())
+	}
+	return vec
+}
+
+func TestQuantize(t *testing.T) {
+	vec := []float32{0.5, -1, 0.25, 0}
+	q := Quantize(vec)
+	if q.Values[1] != -127 || q.Values[3] != 0 {
+		t.Fatalf("Values = %v, want the largest magnitude at ±127", q.Values)
+	}
+	for i, x := range q.Dequantize() {
+		if math.Abs(float64(x-vec[i])) > float64(q.Scale)/2+1e-6 {
+			t.Errorf("component %d = %v, want %v within half a step", i, x, vec[i])
+		}
+	}
+	if zero := Quantize([]float32{0, 0}); zero.Scale != 0 || len(zero.Dequantize()) != 2 {
+		t.Errorf("zero vector = %+v", zero)
+	}
+}
+
+func TestMetric_QuantizedSimilarity(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for _, dim := range []int{3, 1536} {
+		a, b := randomVector(r, dim), randomVector(r, dim)
+		qa, qb := Quantize(a), Quantize(b)
+		for _, m := range []Metric{MetricCosine, MetricDot, MetricEuclidean, MetricAngular} {
+			exact := m.Similarity(a, b)
+			got := m.QuantizedSimilarity(qa, qb)
+			tol := 0.01
+			if m == MetricDot {
+				tol = 0.01 * math.Max(1, math.Abs(exact)) * math.Sqrt(float64(dim))
+			}
+			if math.Abs(got-exact) > tol {
+				t.Errorf("dim %d %q: quantized %v, exact %v", dim, m, got, exact)
+			}
+		}
+	}
+	if got := MetricCosine.QuantizedSimilarity(Quantize([]float32{1}), Quantize([]float32{1, 2})); got != 0 {
+		t.Errorf("mismatched lengths = %v, want 0", got)
+	}
+}
+
+func TestVectorIndex_Quantize(t *testing.T) {
+	emb := &textEmbedder{vectors: map[string][]float32{
+		"query": {1, 0},
+		"a":     {1, 0},
+		"b":     {1, 1},
+		"c":     {0, 1},
+	}}
+	idx, err := NewVectorIndex(emb, "v1", VectorIndexOptions{Quantize: true})
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	docs := []Document{{ID: "a", Text: "a"}, {ID: "b", Text: "b"}, {ID: "c", Text: "c"}}
+	if err := idx.UpsertBatch(context.Background(), docs); err != nil {
+		t.Fatalf("UpsertBatch failed: %v", err)
+	}
+
+	matches, err := idx.Nearest(context.Background(), "query", 3)
+	if err != nil {
+		t.Fatalf("Nearest failed: %v", err)
+	}
+	if len(matches) != 3 || matches[0].ID != "a" || matches[1].ID != "b" || matches[2].ID != "c" {
+		t.Fatalf("matches = %+v, want a, b, c", matches)
+	}
+	if math.Abs(matches[1].Score-math.Sqrt2/2) > 0.01 {
+		t.Errorf("b score = %v, want about 0.707", matches[1].Score)
+	}
+	scores, err := idx.Similarities(context.Background(), "query", docs)
+	if err != nil || math.Abs(scores[0]-1) > 0.01 {
+		t.Errorf("Similarities = %v, %v", scores, err)
+	}
+
+	sv, ok := idx.Vector("b")
+	if !ok || len(sv.Vector) != 2 || math.Abs(float64(sv.Vector[0]-1)) > 0.01 {
+		t.Errorf("Vector = %+v, want dequantized [1 1]", sv)
+	}
+
+	// Snapshots hold float vectors, so a quantized index restores them.
+	var buf bytes.Buffer
+	text := NewInMemoryIndex()
+	for _, doc := range docs {
+		_ = text.Add(context.Background(), doc)
+	}
+	if err := text.Save(&buf, SnapshotOptions{Vectors: idx}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	restored, _ := NewVectorIndex(emb, "v1", VectorIndexOptions{Quantize: true})
+	if err := NewInMemoryIndex().Load(&buf, SnapshotOptions{Vectors: restored}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got, _ := restored.Nearest(context.Background(), "query", 1); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("restored Nearest = %+v", got)
+	}
+}
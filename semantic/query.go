@@ -0,0 +1,402 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// ErrInvalidQuery is returned by ParseQuery, and by searches that parse
+// boolean queries, for malformed query syntax.
+var ErrInvalidQuery = errors.New("semantic: invalid query")
+
+// Query field prefixes recognized by ParseQuery. Any other "prefix:value"
+// term is an ordinary word, so tool IDs such as "github:create_issue" can
+// still be searched.
+const (
+	FieldNamespace = "namespace"
+	FieldTag       = "tag"
+	FieldCategory  = "category"
+	FieldName      = "name"
+	FieldID        = "id"
+)
+
+// Query is a parsed boolean query. It compiles into a filter, applied by
+// Match, and a scoring query, returned by Text, so any Strategy can rank the
+// documents that match.
+//
+// The syntax is:
+//
+//   - Words and "quoted phrases" match document text, tokenized with
+//     IdentifierTokenizer; a phrase matches consecutive words.
+//   - namespace:, tag:, category:, name:, and id: prefixes match document
+//     fields, with quoted values allowed (tag:"issue tracking"). Category
+//     matches include subcategories.
+//   - AND, OR, and NOT (uppercase) combine terms, with NOT binding tightest
+//     and OR loosest; parentheses group. Within parentheses, adjacent terms
+//     are joined with AND.
+//
+// At the top level, bare words only rank results, as in a plain search,
+// while phrases, field terms, and operator expressions filter: in
+// `namespace:git tag:vcs commit`, documents must be in the git namespace and
+// tagged vcs, and are ranked by "commit".
+type Query struct {
+	filters []queryNode
+	text    []string
+}
+
+// ParseQuery parses a boolean query. It returns ErrInvalidQuery for
+// unbalanced parentheses, unterminated quotes, dangling operators, and empty
+// field values.
+func ParseQuery(query string) (*Query, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	q := &Query{}
+	for !p.done() {
+		if p.peek().kind == tokRParen {
+			return nil, fmt.Errorf("%w: unexpected )", ErrInvalidQuery)
+		}
+		start := p.pos
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if term, ok := node.(*termNode); ok && p.pos == start+1 && term.field == "" && !term.phrase {
+			q.text = append(q.text, term.value)
+			continue
+		}
+		q.filters = append(q.filters, node)
+		node.terms(&q.text)
+	}
+	return q, nil
+}
+
+// Match reports whether doc satisfies the query's filters.
+func (q *Query) Match(doc Document) bool {
+	if len(q.filters) == 0 {
+		return true
+	}
+	text := doc.Text
+	if text == "" {
+		text = doc.Normalized().Text
+	}
+	d := &queryDoc{doc: doc, tokens: IdentifierTokenizer{}.Tokenize(text)}
+	for _, f := range q.filters {
+		if !f.match(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasFilters reports whether the query filters documents, as opposed to
+// only ranking them.
+func (q *Query) HasFilters() bool {
+	return len(q.filters) > 0
+}
+
+// Text returns the scoring query: the query's words and phrases, excluding
+// those under NOT and field terms. It is empty for a query of filters only.
+func (q *Query) Text() string {
+	return strings.Join(q.text, " ")
+}
+
+// Filter returns the documents that match the query.
+func (q *Query) Filter(docs []Document) []Document {
+	out := make([]Document, 0, len(docs))
+	for _, doc := range docs {
+		if q.Match(doc) {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+type queryDoc struct {
+	doc    Document
+	tokens []string
+}
+
+type queryNode interface {
+	match(d *queryDoc) bool
+	// terms appends the node's positive scoring terms.
+	terms(out *[]string)
+}
+
+type termNode struct {
+	field  string
+	value  string
+	phrase bool
+	words  []string
+}
+
+func (n *termNode) match(d *queryDoc) bool {
+	switch n.field {
+	case FieldNamespace:
+		return strings.EqualFold(d.doc.Namespace, n.value)
+	case FieldName:
+		return strings.EqualFold(d.doc.Name, n.value)
+	case FieldID:
+		return d.doc.ID == n.value
+	case FieldCategory:
+		return index.InCategory(d.doc.Category, n.value)
+	case FieldTag:
+		want := strings.ToLower(strings.TrimSpace(n.value))
+		return slices.ContainsFunc(d.doc.Tags, func(tag string) bool {
+			return strings.ToLower(strings.TrimSpace(tag)) == want
+		})
+	}
+	if len(n.words) == 0 {
+		return false
+	}
+	for i := 0; i+len(n.words) <= len(d.tokens); i++ {
+		if slices.Equal(d.tokens[i:i+len(n.words)], n.words) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *termNode) terms(out *[]string) {
+	if n.field == "" {
+		*out = append(*out, n.value)
+	}
+}
+
+type andNode []queryNode
+
+func (n andNode) match(d *queryDoc) bool {
+	for _, c := range n {
+		if !c.match(d) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) terms(out *[]string) {
+	for _, c := range n {
+		c.terms(out)
+	}
+}
+
+type orNode []queryNode
+
+func (n orNode) match(d *queryDoc) bool {
+	for _, c := range n {
+		if c.match(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) terms(out *[]string) {
+	for _, c := range n {
+		c.terms(out)
+	}
+}
+
+type notNode struct {
+	child queryNode
+}
+
+func (n notNode) match(d *queryDoc) bool { return !n.child.match(d) }
+
+func (notNode) terms(*[]string) {}
+
+type queryTokenKind int
+
+const (
+	tokTerm queryTokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	term *termNode
+}
+
+// lexQuery splits a query into operators, parentheses, and terms.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen})
+			i++
+			continue
+		}
+
+		start := i
+		var field, value string
+		phrase := false
+		for i < len(runes) && !strings.ContainsRune(" \t\n\r()\"", runes[i]) {
+			i++
+		}
+		word := string(runes[start:i])
+		if i < len(runes) && runes[i] == '"' {
+			// A phrase, possibly prefixed: "..." or field:"...".
+			if word != "" && !strings.HasSuffix(word, ":") {
+				return nil, fmt.Errorf("%w: unexpected quote after %q", ErrInvalidQuery, word)
+			}
+			end := slices.Index(runes[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+			}
+			field = strings.TrimSuffix(word, ":")
+			value = string(runes[i+1 : i+1+end])
+			phrase = true
+			i += end + 2
+		} else {
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: tokAnd})
+				continue
+			case "OR":
+				tokens = append(tokens, queryToken{kind: tokOr})
+				continue
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: tokNot})
+				continue
+			}
+			value = word
+			if prefix, rest, ok := strings.Cut(word, ":"); ok {
+				field, value = prefix, rest
+			}
+		}
+
+		field = strings.ToLower(field)
+		switch field {
+		case FieldNamespace, FieldTag, FieldCategory, FieldName, FieldID:
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%w: empty value for %s:", ErrInvalidQuery, field)
+			}
+		case "":
+		default:
+			// Not a known field: the whole term is an ordinary word.
+			if phrase {
+				return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field)
+			}
+			field, value = "", word
+		}
+		term := &termNode{field: field, value: value, phrase: phrase}
+		if field == "" {
+			term.words = IdentifierTokenizer{}.Tokenize(value)
+		}
+		tokens = append(tokens, queryToken{kind: tokTerm, term: term})
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *queryParser) peek() queryToken { return p.tokens[p.pos] }
+
+// parseOr parses and-expressions separated by OR.
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := orNode{node}
+	for !p.done() && p.peek().kind == tokOr {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, next)
+	}
+	if len(or) == 1 {
+		return node, nil
+	}
+	return or, nil
+}
+
+// parseAnd parses unary expressions separated by AND.
+func (p *queryParser) parseAnd() (queryNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := andNode{node}
+	for !p.done() && p.peek().kind == tokAnd {
+		p.pos++
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, next)
+	}
+	if len(and) == 1 {
+		return node, nil
+	}
+	return and, nil
+}
+
+// parseUnary parses a term, a NOT expression, or a parenthesized group,
+// whose adjacent expressions are joined with AND.
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("%w: expected a term", ErrInvalidQuery)
+	}
+	tok := p.peek()
+	p.pos++
+	switch tok.kind {
+	case tokTerm:
+		return tok.term, nil
+	case tokNot:
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child: child}, nil
+	case tokLParen:
+		var group andNode
+		for !p.done() && p.peek().kind != tokRParen {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, node)
+		}
+		if p.done() {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidQuery)
+		}
+		p.pos++
+		if len(group) == 0 {
+			return nil, fmt.Errorf("%w: empty group", ErrInvalidQuery)
+		}
+		if len(group) == 1 {
+			return group[0], nil
+		}
+		return group, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected operator", ErrInvalidQuery)
+	}
+}
@@ -0,0 +1,110 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func queryTestDocs() []Document {
+	return []Document{
+		{ID: "git:commit", Namespace: "git", Name: "commit", Description: "Record changes to the repository", Tags: []string{"vcs"}, Category: "devops/vcs"},
+		{ID: "git:push", Namespace: "git", Name: "push", Description: "Upload local commits to a remote", Tags: []string{"vcs", "network"}, Category: "devops/vcs"},
+		{ID: "git:legacy_commit", Namespace: "git", Name: "legacy_commit", Description: "Deprecated commit helper", Tags: []string{"deprecated"}},
+		{ID: "fs:write", Namespace: "fs", Name: "write", Description: "Write changes to a file", Tags: []string{"files"}, Category: "devops"},
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		match []string
+		text  string
+	}{
+		{query: "commit changes", match: []string{"git:commit", "git:push", "git:legacy_commit", "fs:write"}, text: "commit changes"},
+		{query: "namespace:git tag:vcs commit", match: []string{"git:commit", "git:push"}, text: "commit"},
+		{query: `"record changes"`, match: []string{"git:commit"}, text: "record changes"},
+		{query: "commit AND NOT tag:deprecated", match: []string{"git:commit"}, text: "commit"},
+		{query: "tag:network OR tag:files", match: []string{"git:push", "fs:write"}, text: ""},
+		{query: "category:devops NOT (namespace:git tag:network)", match: []string{"git:commit", "fs:write"}, text: ""},
+		{query: `name:"legacy_commit"`, match: []string{"git:legacy_commit"}, text: ""},
+		{query: "id:fs:write", match: []string{"fs:write"}, text: ""},
+		{query: "NOT legacyCommit", match: []string{"git:commit", "git:push", "fs:write"}, text: ""},
+		{query: "git:commit", match: []string{"git:commit", "git:push", "git:legacy_commit", "fs:write"}, text: "git:commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery failed: %v", err)
+			}
+			var got []string
+			for _, doc := range q.Filter(queryTestDocs()) {
+				got = append(got, doc.ID)
+			}
+			if !equalStrings(got, tt.match) {
+				t.Errorf("matches = %v, want %v", got, tt.match)
+			}
+			if q.Text() != tt.text {
+				t.Errorf("Text() = %q, want %q", q.Text(), tt.text)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, query := range []string{
+		"(commit",
+		"commit)",
+		`"unterminated`,
+		"commit AND",
+		"OR commit",
+		"NOT",
+		"tag:",
+		"()",
+		`owner:"platform"`,
+	} {
+		if _, err := ParseQuery(query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ParseQuery(%q) err = %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestSearcher_BooleanQuery(t *testing.T) {
+	ctx := context.Background()
+	idx := NewInMemoryIndex()
+	for _, doc := range queryTestDocs() {
+		_ = idx.Add(ctx, doc)
+	}
+	bm25 := NewBM25Strategy(nil, BM25Options{Tokenizer: NewAnalyzer()})
+	hybrid, err := NewHybridStrategy(bm25, NewEmbeddingStrategy(stubEmbedder{queryVec: []float32{1, 0}, docVec: []float32{1, 0}}), 0.5)
+	if err != nil {
+		t.Fatalf("NewHybridStrategy failed: %v", err)
+	}
+
+	for name, strategy := range map[string]Strategy{"bm25": bm25, "hybrid": hybrid} {
+		t.Run(name, func(t *testing.T) {
+			searcher := NewSearcher(idx, strategy)
+			results, err := searcher.SearchWithOptions(ctx, "namespace:git NOT tag:deprecated commit", SearchOptions{Boolean: true})
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if got := resultIDs(results); !equalStrings(got, []string{"git:commit", "git:push"}) {
+				t.Fatalf("results = %v, want [git:commit git:push]", got)
+			}
+			if results[0].Score <= 0 {
+				t.Errorf("top score = %v, want ranked by commit", results[0].Score)
+			}
+		})
+	}
+
+	searcher := NewSearcher(idx, bm25)
+	if _, err := searcher.SearchWithOptions(ctx, "(commit", SearchOptions{Boolean: true}); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("invalid query err = %v, want ErrInvalidQuery", err)
+	}
+	// Without Boolean the query is bag-of-words.
+	results, err := searcher.SearchWithOptions(ctx, "NOT tag:deprecated", SearchOptions{})
+	if err != nil || len(results) != len(queryTestDocs()) {
+		t.Fatalf("plain search = %d results, %v; want all", len(results), err)
+	}
+}
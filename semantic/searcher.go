@@ -3,7 +3,6 @@ package semantic
 import (
 	"context"
 	"errors"
-	"sort"
 )
 
 var ErrInvalidSearcher = errors.New("semantic: searcher requires index and strategy")
@@ -45,29 +44,25 @@ func NewSearcher(index Indexer, strategy Strategy) *InMemorySearcher {
 	return &InMemorySearcher{index: index, strategy: strategy}
 }
 
-// Search scores all documents and returns results ordered by score desc, ID asc.
+// Search scores all documents, in one batch when the strategy implements
+// BatchScorer, and returns results ordered by score desc, ID asc.
+// Use SearchWithOptions or SearchPage to filter, threshold, and page results.
 func (s *InMemorySearcher) Search(ctx context.Context, query string) ([]Result, error) {
 	if s.index == nil || s.strategy == nil {
 		return nil, ErrInvalidSearcher
 	}
 
 	docs := s.index.List(ctx)
+	scores, err := ScoreDocuments(ctx, s.strategy, query, docs)
+	if err != nil {
+		return nil, err
+	}
 	results := make([]Result, 0, len(docs))
-	for _, doc := range docs {
-		score, err := s.strategy.Score(ctx, query, doc)
-		if err != nil {
-			return nil, err
-		}
-		results = append(results, Result{Document: doc, Score: score})
+	for i, doc := range docs {
+		results = append(results, Result{Document: doc, Score: scores[i]})
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Score == results[j].Score {
-			return results[i].Document.ID < results[j].Document.ID
-		}
-		return results[i].Score > results[j].Score
-	})
-
+	sortResults(results)
 	return results, nil
 }
 
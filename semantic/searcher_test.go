@@ -2,6 +2,7 @@ package semantic
 
 import (
 	"context"
+	"errors"
 	"testing"
 )
 
@@ -81,3 +82,110 @@ func TestSearcher_TieBreakByID(t *testing.T) {
 		}
 	}
 }
+
+func TestSearcher_SearchWithOptions(t *testing.T) {
+	idx := NewInMemoryIndex()
+	ctx := context.Background()
+
+	_ = idx.Add(ctx, Document{ID: "git:a", Namespace: "git", Tags: []string{"VCS"}})
+	_ = idx.Add(ctx, Document{ID: "git:b", Namespace: "git"})
+	_ = idx.Add(ctx, Document{ID: "fs:c", Namespace: "fs", Tags: []string{"vcs"}})
+	_ = idx.Add(ctx, Document{ID: "fs:d", Namespace: "fs"})
+
+	searcher := NewSearcher(idx, scoreByIDStrategy{scores: map[string]float64{
+		"git:a": 4, "git:b": 3, "fs:c": 2, "fs:d": 1,
+	}})
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []string
+	}{
+		{name: "no options", opts: SearchOptions{}, want: []string{"git:a", "git:b", "fs:c", "fs:d"}},
+		{name: "limit", opts: SearchOptions{Limit: 2}, want: []string{"git:a", "git:b"}},
+		{name: "min score", opts: SearchOptions{MinScore: 2.5}, want: []string{"git:a", "git:b"}},
+		{name: "namespace", opts: SearchOptions{Namespaces: []string{"fs"}}, want: []string{"fs:c", "fs:d"}},
+		{name: "tags", opts: SearchOptions{Tags: []string{"vcs"}}, want: []string{"git:a", "fs:c"}},
+		{name: "combined", opts: SearchOptions{Namespaces: []string{"fs"}, Tags: []string{"vcs"}, Limit: 5}, want: []string{"fs:c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := searcher.SearchWithOptions(ctx, "q", tt.opts)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if got := resultIDs(results); !equalStrings(got, tt.want) {
+				t.Fatalf("results = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := searcher.SearchWithOptions(ctx, "q", SearchOptions{Limit: -1}); !errors.Is(err, ErrInvalidLimit) {
+		t.Fatalf("negative limit err = %v, want ErrInvalidLimit", err)
+	}
+}
+
+func TestSearcher_SearchPage(t *testing.T) {
+	idx := NewInMemoryIndex()
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		_ = idx.Add(ctx, Document{ID: id})
+	}
+	searcher := NewSearcher(idx, constStrategy{score: 1})
+
+	var got []string
+	opts := SearchOptions{Limit: 2}
+	for {
+		page, next, err := searcher.SearchPage(ctx, "q", opts)
+		if err != nil {
+			t.Fatalf("page failed: %v", err)
+		}
+		got = append(got, resultIDs(page)...)
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !equalStrings(got, want) {
+		t.Fatalf("paged results = %v, want %v", got, want)
+	}
+
+	if _, _, err := searcher.SearchPage(ctx, "q", SearchOptions{}); !errors.Is(err, ErrInvalidLimit) {
+		t.Fatalf("zero limit err = %v, want ErrInvalidLimit", err)
+	}
+	if _, _, err := searcher.SearchPage(ctx, "q", SearchOptions{Limit: 2, Cursor: "!!"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("garbage cursor err = %v, want ErrInvalidCursor", err)
+	}
+
+	_, next, err := searcher.SearchPage(ctx, "q", SearchOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("page failed: %v", err)
+	}
+	if _, _, err := searcher.SearchPage(ctx, "other", SearchOptions{Limit: 2, Cursor: next}); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("cursor reused with new query err = %v, want ErrInvalidCursor", err)
+	}
+	_ = idx.Add(ctx, Document{ID: "f"})
+	if _, _, err := searcher.SearchPage(ctx, "q", SearchOptions{Limit: 2, Cursor: next}); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("cursor after index change err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func resultIDs(results []Result) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Document.ID
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package semantic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ErrInvalidSnapshot is returned when a snapshot is corrupt or was written in
+// an unsupported format version.
+var ErrInvalidSnapshot = errors.New("semantic: invalid snapshot")
+
+// SnapshotVersion is the format version written by Save.
+const SnapshotVersion uint16 = 1
+
+// snapshotMagic identifies semantic index snapshots.
+var snapshotMagic = [4]byte{'T', 'D', 'S', 'I'}
+
+// SnapshotOptions configures Save and Load.
+type SnapshotOptions struct {
+	// Vectors, when set, is saved alongside the documents and restored on
+	// Load, so embeddings need not be recomputed between runs. Restored
+	// vectors keep their model tag; if it differs from the serving model
+	// they are reported by StaleIDs.
+	Vectors *VectorIndex
+}
+
+// snapshot is the gob-encoded payload following the header.
+type snapshot struct {
+	Docs    []Document
+	Vectors []snapshotVector
+}
+
+type snapshotVector struct {
+	ID         string
+	Vector     []float32
+	Model      string
+	EmbeddedAt time.Time
+}
+
+// Save writes the documents in the index to w. The format is a 4-byte magic,
+// a big-endian uint16 version, and a gob-encoded payload.
+func (i *InMemoryIndex) Save(w io.Writer, opts ...SnapshotOptions) error {
+	var opt SnapshotOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	snap := snapshot{Docs: i.List(context.Background())}
+	if opt.Vectors != nil {
+		snap.Vectors = opt.Vectors.snapshotVectors()
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.BigEndian, SnapshotVersion); err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(bw).Encode(snap); err != nil {
+		return fmt.Errorf("semantic: encode snapshot: %w", err)
+	}
+	return bw.Flush()
+}
+
+// Load replaces the contents of the index with a snapshot written by Save.
+// If opts.Vectors is set, its documents and vectors are replaced as well.
+// On error the index is left unchanged.
+func (i *InMemoryIndex) Load(r io.Reader, opts ...SnapshotOptions) error {
+	var opt SnapshotOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	br := bufio.NewReader(r)
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil || !bytes.Equal(magic[:], snapshotMagic[:]) {
+		return fmt.Errorf("%w: missing header", ErrInvalidSnapshot)
+	}
+	var version uint16
+	if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+		return fmt.Errorf("%w: missing version", ErrInvalidSnapshot)
+	}
+	if version != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	var snap snapshot
+	if err := gob.NewDecoder(br).Decode(&snap); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	docs := make(map[string]Document, len(snap.Docs))
+	for _, doc := range snap.Docs {
+		if doc.ID == "" {
+			return fmt.Errorf("%w: %v", ErrInvalidSnapshot, ErrInvalidDocumentID)
+		}
+		docs[doc.ID] = doc.Normalized()
+	}
+
+	if opt.Vectors != nil {
+		if err := opt.Vectors.restore(docs, snap.Vectors); err != nil {
+			return err
+		}
+	}
+
+	i.mu.Lock()
+	i.docs = docs
+	i.version++
+	event := ChangeEvent{Type: ChangeLoaded, Version: i.version}
+	listeners := i.snapshotListenersLocked()
+	i.mu.Unlock()
+
+	notifyListeners(listeners, event)
+	return nil
+}
+
+// snapshotVectors returns the serving vectors sorted by ID.
+func (v *VectorIndex) snapshotVectors() []snapshotVector {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	out := make([]snapshotVector, 0, len(v.vectors))
+	for id, sv := range v.vectors {
+		sv = sv.float()
+		out = append(out, snapshotVector{ID: id, Vector: sv.Vector, Model: sv.Model, EmbeddedAt: sv.EmbeddedAt})
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].ID < out[b].ID })
+	return out
+}
+
+// restore replaces the vector index contents with docs and their saved
+// vectors. Vectors for documents not in docs are dropped.
+func (v *VectorIndex) restore(docs map[string]Document, vectors []snapshotVector) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.next != nil {
+		return ErrCampaignRunning
+	}
+	v.revision++
+	v.docs = make(map[string]vectorDoc, len(docs))
+	for id, doc := range docs {
+		v.docs[id] = vectorDoc{text: documentText(doc), revision: v.revision}
+	}
+	v.vectors = make(map[string]StoredVector, len(vectors))
+	for _, sv := range vectors {
+		if _, ok := docs[sv.ID]; !ok {
+			continue
+		}
+		v.vectors[sv.ID] = v.stored(sv.Vector, sv.Model, sv.EmbeddedAt)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInMemoryIndex_SaveLoad(t *testing.T) {
+	ctx := context.Background()
+	src := NewInMemoryIndex()
+	_ = src.Add(ctx, Document{ID: "git:commit", Namespace: "git", Name: "commit", Description: "Record changes", Tags: []string{"VCS"}})
+	_ = src.Add(ctx, Document{ID: "fs:read", Namespace: "fs", Name: "read", Description: "Read a file"})
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	dst := NewInMemoryIndex()
+	_ = dst.Add(ctx, Document{ID: "old:doc", Name: "old"})
+	if err := dst.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got, want := dst.List(ctx), src.List(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded docs = %+v, want %+v", got, want)
+	}
+}
+
+func TestInMemoryIndex_SaveLoadVectors(t *testing.T) {
+	ctx := context.Background()
+	src := NewInMemoryIndex()
+	vectors, emb := newTestVectorIndex(t, "a", "b")
+	for _, id := range []string{"a", "b"} {
+		_ = src.Add(ctx, Document{ID: id, Name: id})
+	}
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf, SnapshotOptions{Vectors: vectors}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	restored, err := NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	dst := NewInMemoryIndex()
+	before := emb.calls.Load()
+	if err := dst.Load(&buf, SnapshotOptions{Vectors: restored}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if calls := emb.calls.Load() - before; calls != 0 {
+		t.Errorf("Load re-embedded %d documents", calls)
+	}
+	for _, id := range []string{"a", "b"} {
+		want, _ := vectors.Vector(id)
+		got, ok := restored.Vector(id)
+		if !ok || !reflect.DeepEqual(got.Vector, want.Vector) || got.Model != "v1" || !got.EmbeddedAt.Equal(want.EmbeddedAt) {
+			t.Errorf("restored vector %s = %+v, want %+v", id, got, want)
+		}
+	}
+	if stale := restored.StaleIDs("v1"); len(stale) != 0 {
+		t.Errorf("StaleIDs = %v, want none", stale)
+	}
+}
+
+func TestInMemoryIndex_LoadRejectsInvalidSnapshots(t *testing.T) {
+	ctx := context.Background()
+	idx := NewInMemoryIndex()
+	_ = idx.Add(ctx, Document{ID: "keep", Name: "keep"})
+
+	var good bytes.Buffer
+	_ = NewInMemoryIndex().Save(&good)
+	future := append([]byte(nil), good.Bytes()...)
+	future[5] = 99
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"bad magic": []byte("NOPE\x00\x01"),
+		"version":   future,
+		"truncated": good.Bytes()[:8],
+	} {
+		if err := idx.Load(bytes.NewReader(data)); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("%s: expected ErrInvalidSnapshot, got %v", name, err)
+		}
+	}
+	if _, ok := idx.Get(ctx, "keep"); !ok {
+		t.Error("failed Load modified the index")
+	}
+}
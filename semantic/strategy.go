@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"math"
-	"strings"
+	"reflect"
 )
 
 var (
@@ -31,16 +31,74 @@ type Embedder interface {
 	Embed(ctx context.Context, text string) ([]float32, error)
 }
 
+// queryEmbeddingKey is the context key of a precomputed query embedding.
+type queryEmbeddingKey struct{}
+
+type queryEmbedding struct {
+	embedder Embedder
+	text     string
+	vector   []float32
+}
+
+// WithQueryEmbedding returns a context carrying vec, the embedding of the
+// query text by embedder. Strategies and vector indexes scoring text with
+// that same embedder use vec instead of embedding the query again.
+func WithQueryEmbedding(ctx context.Context, embedder Embedder, text string, vec []float32) context.Context {
+	return context.WithValue(ctx, queryEmbeddingKey{}, queryEmbedding{embedder: embedder, text: text, vector: vec})
+}
+
+// precomputedQuery returns the embedding of query by embedder carried by
+// ctx, if any.
+func precomputedQuery(ctx context.Context, embedder Embedder, query string) ([]float32, bool) {
+	qe, ok := ctx.Value(queryEmbeddingKey{}).(queryEmbedding)
+	if !ok || qe.text != query || !sameEmbedder(qe.embedder, embedder) {
+		return nil, false
+	}
+	return qe.vector, true
+}
+
+// embedQuery embeds query with embedder, reusing an embedding carried by
+// ctx.
+func embedQuery(ctx context.Context, embedder Embedder, query string) ([]float32, error) {
+	if vec, ok := precomputedQuery(ctx, embedder, query); ok {
+		return vec, nil
+	}
+	return embedder.Embed(ctx, query)
+}
+
+// sameEmbedder reports whether a and b are the same embedder. Embedders of
+// non-comparable types are never the same.
+func sameEmbedder(a, b Embedder) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// BM25Options configures NewBM25Strategy.
+type BM25Options struct {
+	// Tokenizer splits queries and documents for the default token-overlap
+	// scorer; it is ignored when a custom scorer is given. Use NewAnalyzer
+	// so "createIssue" matches "create_issue". Default: WhitespaceTokenizer.
+	Tokenizer Tokenizer
+}
+
 // NewBM25Strategy creates a BM25-only strategy. If scorer is nil, a default
-// token-overlap scorer is used.
-func NewBM25Strategy(scorer BM25Scorer) Strategy {
+// token-overlap scorer is used, splitting text with opts.Tokenizer.
+func NewBM25Strategy(scorer BM25Scorer, opts ...BM25Options) Strategy {
 	if scorer == nil {
-		scorer = defaultBM25Scorer{}
+		var opt BM25Options
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		scorer = defaultBM25Scorer{tokenizer: opt.Tokenizer}
 	}
 	return bm25Strategy{scorer: scorer}
 }
 
-// NewEmbeddingStrategy creates an embedding-only strategy.
+// NewEmbeddingStrategy creates an embedding-only strategy using cosine
+// similarity. Use NewEmbeddingStrategyWithMetric for other metrics.
 func NewEmbeddingStrategy(embedder Embedder) Strategy {
 	return embeddingStrategy{embedder: embedder}
 }
@@ -67,6 +125,7 @@ func (s bm25Strategy) Score(_ context.Context, query string, doc Document) (floa
 
 type embeddingStrategy struct {
 	embedder Embedder
+	metric   Metric
 }
 
 func (s embeddingStrategy) Score(ctx context.Context, query string, doc Document) (float64, error) {
@@ -74,7 +133,7 @@ func (s embeddingStrategy) Score(ctx context.Context, query string, doc Document
 		return 0, ErrInvalidEmbedder
 	}
 
-	qVec, err := s.embedder.Embed(ctx, query)
+	qVec, err := embedQuery(ctx, s.embedder, query)
 	if err != nil {
 		return 0, err
 	}
@@ -88,7 +147,7 @@ func (s embeddingStrategy) Score(ctx context.Context, query string, doc Document
 		return 0, err
 	}
 
-	return cosineSimilarity(qVec, dVec), nil
+	return s.metric.Similarity(qVec, dVec), nil
 }
 
 type hybridStrategy struct {
@@ -110,14 +169,20 @@ func (s hybridStrategy) Score(ctx context.Context, query string, doc Document) (
 }
 
 // defaultBM25Scorer is a simple token-overlap scorer.
-type defaultBM25Scorer struct{}
+type defaultBM25Scorer struct {
+	tokenizer Tokenizer
+}
 
-func (defaultBM25Scorer) Score(query string, doc Document) float64 {
-	qTokens := tokenize(query)
+func (s defaultBM25Scorer) Score(query string, doc Document) float64 {
+	tokenizer := s.tokenizer
+	if tokenizer == nil {
+		tokenizer = WhitespaceTokenizer{}
+	}
+	qTokens := tokenizer.Tokenize(query)
 	if len(qTokens) == 0 {
 		return 0
 	}
-	dTokens := tokenize(doc.Text)
+	dTokens := tokenizer.Tokenize(doc.Text)
 	if len(dTokens) == 0 {
 		return 0
 	}
@@ -137,29 +202,11 @@ func (defaultBM25Scorer) Score(query string, doc Document) float64 {
 	return float64(matches)
 }
 
-func tokenize(s string) []string {
-	fields := strings.Fields(strings.ToLower(s))
-	out := make([]string, 0, len(fields))
-	for _, f := range fields {
-		if f != "" {
-			out = append(out, f)
-		}
-	}
-	return out
-}
-
 func cosineSimilarity(a, b []float32) float64 {
 	if len(a) == 0 || len(b) == 0 || len(a) != len(b) {
 		return 0
 	}
-	var dot, normA, normB float64
-	for i := range a {
-		av := float64(a[i])
-		bv := float64(b[i])
-		dot += av * bv
-		normA += av * av
-		normB += bv * bv
-	}
+	dot, normA, normB := dotAndNorms(a, b)
 	if normA == 0 || normB == 0 {
 		return 0
 	}
@@ -0,0 +1,179 @@
+package semantic
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tokenizer splits text into the terms compared by the default BM25 scorer.
+//
+// Contract:
+// - Concurrency: implementations must be safe for concurrent use.
+// - Determinism: identical inputs must yield identical tokens.
+// - Normalization: queries and documents pass through the same Tokenizer,
+// so tokens need only be consistent, not dictionary words.
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) []string
+
+// Tokenize calls f(text).
+func (f TokenizerFunc) Tokenize(text string) []string {
+	return f(text)
+}
+
+// DefaultStopwords are common English words that carry little meaning in
+// tool queries, used by NewStopwordFilter when no words are given.
+var DefaultStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in",
+	"into", "is", "it", "of", "on", "or", "that", "the", "this", "to",
+	"with",
+}
+
+// WhitespaceTokenizer lowercases text and splits it on whitespace. It is
+// the default tokenizer of NewBM25Strategy.
+type WhitespaceTokenizer struct{}
+
+// Tokenize returns the lowercased whitespace-separated fields of text.
+func (WhitespaceTokenizer) Tokenize(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// IdentifierTokenizer lowercases text and splits it into words at
+// whitespace, punctuation, and identifier boundaries, so "createIssue",
+// "create_issue", "create-issue", and "create.issue" all yield "create" and
+// "issue". A run of capitals is kept as one word: "HTTPServer" yields
+// "http" and "server".
+type IdentifierTokenizer struct{}
+
+// Tokenize returns the lowercased words of text.
+func (IdentifierTokenizer) Tokenize(text string) []string {
+	var out []string
+	runes := []rune(text)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			out = append(out, strings.ToLower(string(runes[start:end])))
+			start = -1
+		}
+	}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	flush(len(runes))
+	return out
+}
+
+// NewStopwordFilter returns a Tokenizer that drops stopwords from the tokens
+// of next. Words are matched case-insensitively; with no words,
+// DefaultStopwords is used. A nil next uses WhitespaceTokenizer.
+func NewStopwordFilter(next Tokenizer, words ...string) Tokenizer {
+	if next == nil {
+		next = WhitespaceTokenizer{}
+	}
+	if len(words) == 0 {
+		words = DefaultStopwords
+	}
+	stop := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		stop[strings.ToLower(w)] = struct{}{}
+	}
+	return TokenizerFunc(func(text string) []string {
+		tokens := next.Tokenize(text)
+		out := tokens[:0]
+		for _, t := range tokens {
+			if _, ok := stop[t]; !ok {
+				out = append(out, t)
+			}
+		}
+		return out
+	})
+}
+
+// NewStemmingFilter returns a Tokenizer that reduces the tokens of next to
+// their stems with Stem, so "issues", "listing", and "listed" match "issue"
+// and "list". A nil next uses WhitespaceTokenizer.
+func NewStemmingFilter(next Tokenizer) Tokenizer {
+	if next == nil {
+		next = WhitespaceTokenizer{}
+	}
+	return TokenizerFunc(func(text string) []string {
+		tokens := next.Tokenize(text)
+		for i, t := range tokens {
+			tokens[i] = Stem(t)
+		}
+		return tokens
+	})
+}
+
+// NewAnalyzer returns the recommended tokenizer for tool catalogs:
+// identifier splitting, DefaultStopwords removal, and stemming.
+func NewAnalyzer() Tokenizer {
+	return NewStemmingFilter(NewStopwordFilter(IdentifierTokenizer{}))
+}
+
+// Stem strips common English inflectional suffixes from a lowercase word:
+// plural "s", "es", and "ies", then "ing" and "ed" (undoubling a final
+// consonant), then a trailing "e". Stems are not always words ("create"
+// becomes "creat"), but every inflection of a word shares one stem. Words
+// of three letters or fewer are returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+	for _, suffix := range []string{"ing", "ed"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 && hasVowel(word[:len(word)-len(suffix)]) {
+			word = undouble(word[:len(word)-len(suffix)])
+			break
+		}
+	}
+	if strings.HasSuffix(word, "e") && len(word) > 3 {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+// undouble shortens a stem ending in a doubled consonant, as in "runn" from
+// "running", keeping "ll", "ss", and "zz", which English doubles in the base
+// word.
+func undouble(s string) string {
+	n := len(s)
+	if n < 2 || s[n-1] != s[n-2] || strings.ContainsRune("aeiouylsz", rune(s[n-1])) {
+		return s
+	}
+	return s[:n-1]
+}
+
+var (
+	_ Tokenizer = WhitespaceTokenizer{}
+	_ Tokenizer = IdentifierTokenizer{}
+	_ Tokenizer = TokenizerFunc(nil)
+)
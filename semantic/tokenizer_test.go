@@ -0,0 +1,93 @@
+package semantic
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestIdentifierTokenizer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"createIssue", []string{"create", "issue"}},
+		{"create_issue", []string{"create", "issue"}},
+		{"create-issue list.repos", []string{"create", "issue", "list", "repos"}},
+		{"HTTPServer", []string{"http", "server"}},
+		{"getV2Token", []string{"get", "v2", "token"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := (IdentifierTokenizer{}).Tokenize(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewStopwordFilter(t *testing.T) {
+	got := NewStopwordFilter(nil).Tokenize("Create an issue in the repo")
+	if want := []string{"create", "issue", "repo"}; !slices.Equal(got, want) {
+		t.Errorf("default stopwords = %q, want %q", got, want)
+	}
+
+	got = NewStopwordFilter(nil, "Repo").Tokenize("create the repo")
+	if want := []string{"create", "the"}; !slices.Equal(got, want) {
+		t.Errorf("custom stopwords = %q, want %q", got, want)
+	}
+}
+
+func TestStem(t *testing.T) {
+	groups := [][]string{
+		{"issue", "issues", "issued"},
+		{"create", "creates", "created", "creating"},
+		{"list", "lists", "listed", "listing"},
+		{"query", "queries"},
+		{"run", "running", "runs"},
+	}
+	for _, group := range groups {
+		stem := Stem(group[0])
+		for _, word := range group[1:] {
+			if got := Stem(word); got != stem {
+				t.Errorf("Stem(%q) = %q, want %q (stem of %q)", word, got, stem, group[0])
+			}
+		}
+	}
+	for _, word := range []string{"status", "analysis", "class", "string"} {
+		if got := Stem(word); got != word {
+			t.Errorf("Stem(%q) = %q, want unchanged", word, got)
+		}
+	}
+}
+
+func TestNewBM25Strategy_Tokenizer(t *testing.T) {
+	doc := Document{ID: "github:create_issue", Text: "create_issue Creates issues in a repository"}
+
+	score, err := NewBM25Strategy(nil).Score(context.Background(), "createIssue", doc)
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if score != 0 {
+		t.Errorf("default tokenizer score = %v, want 0", score)
+	}
+
+	strategy := NewBM25Strategy(nil, BM25Options{Tokenizer: NewAnalyzer()})
+	score, err = strategy.Score(context.Background(), "createIssue", doc)
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if score != 2 {
+		t.Errorf("analyzer score = %v, want 2", score)
+	}
+}
+
+func TestNewBM25Strategy_TokenizerIgnoredWithScorer(t *testing.T) {
+	strategy := NewBM25Strategy(stubBM25Scorer{score: 0.5}, BM25Options{Tokenizer: NewAnalyzer()})
+	score, err := strategy.Score(context.Background(), "q", Document{ID: "a", Text: "q"})
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if score != 0.5 {
+		t.Errorf("score = %v, want 0.5", score)
+	}
+}
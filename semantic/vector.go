@@ -0,0 +1,718 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidModel     = errors.New("semantic: model version is required")
+	ErrCampaignRunning  = errors.New("semantic: re-embedding campaign already running")
+	ErrInvalidBatchSize = errors.New("semantic: batch size must be positive")
+)
+
+// DefaultReembedBatchSize is the batch size used when ReembedOptions.BatchSize is zero.
+const DefaultReembedBatchSize = 64
+
+// StoredVector is an embedding tagged with the model version that produced it.
+type StoredVector struct {
+	Vector     []float32
+	Model      string
+	EmbeddedAt time.Time
+
+	// quantized holds the vector instead of Vector when the index
+	// quantizes; see VectorIndexOptions.Quantize.
+	quantized *QuantizedVector
+}
+
+// float returns the vector as float32, dequantizing it when needed.
+func (sv StoredVector) float() StoredVector {
+	if sv.quantized != nil {
+		sv.Vector = sv.quantized.Dequantize()
+		sv.quantized = nil
+	}
+	return sv
+}
+
+// VectorIndexOptions configures a VectorIndex.
+type VectorIndexOptions struct {
+	// Metric compares query and document vectors. Default: MetricCosine.
+	Metric Metric
+
+	// Quantize stores document vectors as int8 (see Quantize), cutting
+	// their memory about fourfold. Queries are quantized too and compared
+	// with Metric.QuantizedSimilarity, so scores are approximate. Vector
+	// and snapshots return dequantized vectors.
+	Quantize bool
+
+	// BatchSize is the most documents UpsertBatch embeds per call, so bulk
+	// upserts stay within provider request limits. Each chunk is a single
+	// EmbedBatch call when the embedder implements BatchEmbedder.
+	// Default: DefaultReembedBatchSize.
+	BatchSize int
+
+	// Now returns the current time for StoredVector.EmbeddedAt.
+	// Default: time.Now.
+	Now func() time.Time
+}
+
+// ReembedOptions configures a re-embedding campaign.
+type ReembedOptions struct {
+	// Embedder produces the new vectors. Required.
+	Embedder Embedder
+
+	// Model is the version label of Embedder. Required.
+	Model string
+
+	// Metric replaces the index metric at cutover, for models trained for a
+	// different similarity. Empty keeps the current metric.
+	Metric Metric
+
+	// BatchSize is the number of documents embedded per batch. Each batch is
+	// a single EmbedBatch call when Embedder implements BatchEmbedder.
+	// Default: DefaultReembedBatchSize.
+	BatchSize int
+
+	// Interval is the pause between batches, used to rate-limit calls to the
+	// embedding provider. Zero means no pause.
+	Interval time.Duration
+
+	// OnProgress is called after each batch with the number of documents
+	// embedded so far and the campaign total.
+	OnProgress func(done, total int)
+}
+
+// ReembedStats summarizes a completed campaign.
+type ReembedStats struct {
+	Total     int
+	Embedded  int
+	Batches   int
+	FromModel string
+	ToModel   string
+	Duration  time.Duration
+}
+
+// VectorMatch is a nearest-neighbor hit from VectorIndex.Nearest.
+type VectorMatch struct {
+	ID    string
+	Score float64
+}
+
+type vectorDoc struct {
+	text        string
+	fingerprint uint64
+	revision    uint64
+}
+
+// campaign holds the vectors being built for the next model.
+type campaign struct {
+	embedder Embedder
+	model    string
+	metric   Metric
+	vectors  map[string]StoredVector
+}
+
+// VectorIndex stores document embeddings along with the model version that
+// produced them. Searches use the serving generation while a re-embedding
+// campaign builds the next one; ReembedAll swaps generations atomically on
+// completion.
+//
+// VectorIndex is safe for concurrent use.
+type VectorIndex struct {
+	mu        sync.RWMutex
+	embedder  Embedder
+	model     string
+	metric    Metric
+	docs      map[string]vectorDoc
+	vectors   map[string]StoredVector
+	revision  uint64
+	next      *campaign
+	now       func() time.Time
+	quantize  bool
+	batchSize int
+}
+
+// NewVectorIndex creates a vector index that embeds documents with embedder,
+// tagging vectors with model.
+func NewVectorIndex(embedder Embedder, model string, opts ...VectorIndexOptions) (*VectorIndex, error) {
+	if embedder == nil {
+		return nil, ErrInvalidEmbedder
+	}
+	if model == "" {
+		return nil, ErrInvalidModel
+	}
+	var opt VectorIndexOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	metric := opt.Metric
+	if !metric.Valid() {
+		return nil, ErrInvalidMetric
+	}
+	if opt.BatchSize < 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	batchSize := opt.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultReembedBatchSize
+	}
+	now := opt.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &VectorIndex{
+		embedder:  embedder,
+		model:     model,
+		metric:    metric,
+		docs:      make(map[string]vectorDoc),
+		vectors:   make(map[string]StoredVector),
+		now:       now,
+		quantize:  opt.Quantize,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Model returns the serving model version.
+func (v *VectorIndex) Model() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.model
+}
+
+// Embedder returns the serving embedder.
+func (v *VectorIndex) Embedder() Embedder {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.embedder
+}
+
+// Metric returns the serving similarity metric.
+func (v *VectorIndex) Metric() Metric {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if v.metric == "" {
+		return MetricCosine
+	}
+	return v.metric
+}
+
+// Upsert embeds doc with the serving embedder and stores the vector. While a
+// campaign is running the document is also embedded with the campaign's
+// embedder so the cutover never serves a stale vector.
+//
+// Vectors are keyed by a fingerprint of the document text, so upserting an
+// unchanged document that already has current vectors makes no Embed calls.
+func (v *VectorIndex) Upsert(ctx context.Context, doc Document) error {
+	return v.UpsertBatch(ctx, []Document{doc})
+}
+
+// UpsertBatch is Upsert for many documents. Documents needing new vectors are
+// embedded together in chunks of VectorIndexOptions.BatchSize, one EmbedBatch
+// call per chunk and generation when the embedder implements BatchEmbedder.
+func (v *VectorIndex) UpsertBatch(ctx context.Context, docs []Document) error {
+	for _, doc := range docs {
+		if doc.ID == "" {
+			return ErrInvalidDocumentID
+		}
+	}
+
+	v.mu.RLock()
+	embedder, model, next := v.embedder, v.model, v.next
+	pending := make([]vectorDoc, 0, len(docs))
+	ids := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		text := documentText(doc)
+		fp := fingerprint(text)
+		if v.currentLocked(doc.ID, fp) {
+			continue
+		}
+		pending = append(pending, vectorDoc{text: text, fingerprint: fp})
+		ids = append(ids, doc.ID)
+	}
+	v.mu.RUnlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(pending))
+	for i, doc := range pending {
+		texts[i] = doc.text
+	}
+	vecs, err := embedChunks(ctx, embedder, texts, v.batchSize)
+	if err != nil {
+		return err
+	}
+	var nextVecs [][]float32
+	if next != nil {
+		if nextVecs, err = embedChunks(ctx, next.embedder, texts, v.batchSize); err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	at := v.now()
+	for i, id := range ids {
+		var nextVec []float32
+		if next != nil {
+			nextVec = nextVecs[i]
+		}
+		v.revision++
+		doc := pending[i]
+		doc.revision = v.revision
+		v.docs[id] = doc
+		switch {
+		case v.model == model:
+			v.vectors[id] = v.stored(vecs[i], model, at)
+		case next != nil && v.model == next.model:
+			// The campaign cut over while embedding; nextVec is now serving.
+			v.vectors[id] = v.stored(nextVec, next.model, at)
+		default:
+			delete(v.vectors, id)
+		}
+		switch {
+		case v.next != nil && v.next == next:
+			v.next.vectors[id] = v.stored(nextVec, next.model, at)
+		case v.next != nil:
+			// A campaign started while embedding; drop any vector it built
+			// from the old text so ReembedAll embeds this one before cutover.
+			delete(v.next.vectors, id)
+		}
+	}
+	return nil
+}
+
+// embedChunks embeds texts with EmbedTexts, at most size texts per call.
+func embedChunks(ctx context.Context, embedder Embedder, texts []string, size int) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for chunk := range slices.Chunk(texts, size) {
+		vecs, err := EmbedTexts(ctx, embedder, chunk)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vecs...)
+	}
+	return vectors, nil
+}
+
+// currentLocked reports whether id is stored with fingerprint fp and has a
+// serving vector and, during a campaign, a next-generation vector.
+// Caller must hold v.mu.
+func (v *VectorIndex) currentLocked(id string, fp uint64) bool {
+	doc, ok := v.docs[id]
+	if !ok || doc.fingerprint != fp {
+		return false
+	}
+	if sv, ok := v.vectors[id]; !ok || sv.Model != v.model {
+		return false
+	}
+	if v.next != nil {
+		if _, ok := v.next.vectors[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove deletes a document and its vectors.
+func (v *VectorIndex) Remove(id string) error {
+	if id == "" {
+		return ErrInvalidDocumentID
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.revision++
+	delete(v.docs, id)
+	delete(v.vectors, id)
+	if v.next != nil {
+		delete(v.next.vectors, id)
+	}
+	return nil
+}
+
+// Vector returns the serving vector for a document.
+func (v *VectorIndex) Vector(id string) (StoredVector, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	sv, ok := v.vectors[id]
+	return sv.float(), ok
+}
+
+// stored wraps vec for storage, quantizing it when the index quantizes.
+func (v *VectorIndex) stored(vec []float32, model string, at time.Time) StoredVector {
+	if !v.quantize || vec == nil {
+		return StoredVector{Vector: vec, Model: model, EmbeddedAt: at}
+	}
+	q := Quantize(vec)
+	return StoredVector{Model: model, EmbeddedAt: at, quantized: &q}
+}
+
+// StaleIDs returns, in sorted order, the documents whose serving vector was
+// not produced by model (including documents with no vector).
+func (v *VectorIndex) StaleIDs(model string) []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var ids []string
+	for id := range v.docs {
+		if sv, ok := v.vectors[id]; !ok || sv.Model != model {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ReembedAll rebuilds every vector with opts.Embedder in rate-limited batches.
+// Searches keep using the current vectors until all documents are embedded,
+// then the index cuts over to opts.Model in a single step. Documents stored
+// during the campaign without a next-generation vector are embedded before
+// the cutover. If ctx is cancelled or embedding fails, the campaign is
+// abandoned and the serving vectors are left untouched.
+//
+// Returns ErrCampaignRunning if another campaign is in progress.
+func (v *VectorIndex) ReembedAll(ctx context.Context, opts ReembedOptions) (ReembedStats, error) {
+	if opts.Embedder == nil {
+		return ReembedStats{}, ErrInvalidEmbedder
+	}
+	if opts.Model == "" {
+		return ReembedStats{}, ErrInvalidModel
+	}
+	if opts.BatchSize < 0 {
+		return ReembedStats{}, ErrInvalidBatchSize
+	}
+	if !opts.Metric.Valid() {
+		return ReembedStats{}, ErrInvalidMetric
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultReembedBatchSize
+	}
+
+	v.mu.Lock()
+	if v.next != nil {
+		v.mu.Unlock()
+		return ReembedStats{}, ErrCampaignRunning
+	}
+	metric := opts.Metric
+	if metric == "" {
+		metric = v.metric
+	}
+	next := &campaign{
+		embedder: opts.Embedder,
+		model:    opts.Model,
+		metric:   metric,
+		vectors:  make(map[string]StoredVector, len(v.docs)),
+	}
+	v.next = next
+	snapshot := make(map[string]vectorDoc, len(v.docs))
+	ids := make([]string, 0, len(v.docs))
+	for id, doc := range v.docs {
+		snapshot[id] = doc
+		ids = append(ids, id)
+	}
+	stats := ReembedStats{Total: len(ids), FromModel: v.model, ToModel: opts.Model}
+	v.mu.Unlock()
+	sort.Strings(ids)
+
+	start := v.now()
+	abandon := func(err error) (ReembedStats, error) {
+		v.mu.Lock()
+		if v.next == next {
+			v.next = nil
+		}
+		v.mu.Unlock()
+		return stats, err
+	}
+
+	for offset := 0; offset < len(ids); offset += opts.BatchSize {
+		if offset > 0 && opts.Interval > 0 {
+			timer := time.NewTimer(opts.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return abandon(ctx.Err())
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return abandon(err)
+		}
+
+		end := min(offset+opts.BatchSize, len(ids))
+		texts := make([]string, 0, end-offset)
+		for _, id := range ids[offset:end] {
+			texts = append(texts, snapshot[id].text)
+		}
+		vecs, err := EmbedTexts(ctx, opts.Embedder, texts)
+		if err != nil {
+			return abandon(err)
+		}
+		batch := make(map[string][]float32, end-offset)
+		for i, id := range ids[offset:end] {
+			batch[id] = vecs[i]
+		}
+
+		v.mu.Lock()
+		at := v.now()
+		for id, vec := range batch {
+			// Skip documents changed since the snapshot; Upsert already
+			// dual-wrote their new vector, and Remove deleted them.
+			if cur, ok := v.docs[id]; !ok || cur.revision != snapshot[id].revision {
+				continue
+			}
+			next.vectors[id] = v.stored(vec, opts.Model, at)
+		}
+		v.mu.Unlock()
+
+		stats.Embedded += len(batch)
+		stats.Batches++
+		if opts.OnProgress != nil {
+			opts.OnProgress(stats.Embedded, stats.Total)
+		}
+	}
+
+	// An upsert that embedded before the campaign started but stored its
+	// document after the snapshot left no next-generation vector. Embed
+	// such documents before cutting over so none loses its serving vector.
+	for {
+		if err := ctx.Err(); err != nil {
+			return abandon(err)
+		}
+		v.mu.Lock()
+		missing := v.missingLocked(next)
+		if len(missing) == 0 {
+			v.embedder = next.embedder
+			v.model = next.model
+			v.metric = next.metric
+			v.vectors = next.vectors
+			v.next = nil
+			v.mu.Unlock()
+			break
+		}
+		v.mu.Unlock()
+
+		late := slices.Sorted(maps.Keys(missing))
+		texts := make([]string, len(late))
+		for i, id := range late {
+			texts[i] = missing[id].text
+		}
+		vecs, err := embedChunks(ctx, opts.Embedder, texts, opts.BatchSize)
+		if err != nil {
+			return abandon(err)
+		}
+
+		v.mu.Lock()
+		at := v.now()
+		for i, id := range late {
+			if cur, ok := v.docs[id]; ok && cur.revision == missing[id].revision {
+				next.vectors[id] = v.stored(vecs[i], opts.Model, at)
+			}
+		}
+		v.mu.Unlock()
+
+		stats.Total += len(late)
+		stats.Embedded += len(late)
+		stats.Batches += (len(late) + opts.BatchSize - 1) / opts.BatchSize
+		if opts.OnProgress != nil {
+			opts.OnProgress(stats.Embedded, stats.Total)
+		}
+	}
+
+	stats.Duration = v.now().Sub(start)
+	return stats, nil
+}
+
+// missingLocked returns the documents that have no vector in campaign next.
+// Caller must hold v.mu.
+func (v *VectorIndex) missingLocked(next *campaign) map[string]vectorDoc {
+	var missing map[string]vectorDoc
+	for id, doc := range v.docs {
+		if _, ok := next.vectors[id]; ok {
+			continue
+		}
+		if missing == nil {
+			missing = make(map[string]vectorDoc)
+		}
+		missing[id] = doc
+	}
+	return missing
+}
+
+// Similarities embeds query once and returns its similarity to each of docs,
+// in order. Documents missing from the index or whose text changed since
+// they were stored are upserted first, in one batch, so repeated searches
+// over the same documents cost a single Embed call.
+func (v *VectorIndex) Similarities(ctx context.Context, query string, docs []Document) ([]float64, error) {
+	if err := v.UpsertBatch(ctx, docs); err != nil {
+		return nil, err
+	}
+	qVec, model, metric, err := v.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	q := v.newQueryVector(qVec)
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		sv, err := v.vectorFor(ctx, doc, model)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = q.similarity(metric, sv)
+	}
+	return scores, nil
+}
+
+// Nearest returns the k stored documents most similar to query, ordered by
+// score descending, then ID ascending. The scan is exact: every serving
+// vector is compared against the query.
+func (v *VectorIndex) Nearest(ctx context.Context, query string, k int) ([]VectorMatch, error) {
+	if k <= 0 {
+		return []VectorMatch{}, nil
+	}
+	qVec, model, metric, err := v.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	q := v.newQueryVector(qVec)
+	v.mu.RLock()
+	matches := make([]VectorMatch, 0, len(v.vectors))
+	for id, sv := range v.vectors {
+		if sv.Model != model {
+			continue
+		}
+		matches = append(matches, VectorMatch{ID: id, Score: q.similarity(metric, sv)})
+	}
+	v.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// vectorFor returns the vector of doc produced by model, upserting doc when
+// its stored vector is missing or stale. If the index cut over to another
+// model meanwhile, doc is embedded on the fly without being stored.
+func (v *VectorIndex) vectorFor(ctx context.Context, doc Document, model string) (StoredVector, error) {
+	text := documentText(doc)
+	if sv, ok := v.cached(doc.ID, text); ok && sv.Model == model {
+		return sv, nil
+	}
+	if err := v.Upsert(ctx, doc); err != nil {
+		return StoredVector{}, err
+	}
+	if sv, ok := v.cached(doc.ID, text); ok && sv.Model == model {
+		return sv, nil
+	}
+	vec, _, _, err := v.query(ctx, text)
+	return StoredVector{Vector: vec, Model: model}, err
+}
+
+// cached returns the serving vector for id if it was embedded from text.
+func (v *VectorIndex) cached(id, text string) (StoredVector, bool) {
+	fp := fingerprint(text)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if doc, ok := v.docs[id]; !ok || doc.fingerprint != fp {
+		return StoredVector{}, false
+	}
+	sv, ok := v.vectors[id]
+	return sv, ok
+}
+
+// query embeds text with the serving embedder, reusing an embedding carried
+// by ctx (see WithQueryEmbedding), and returns the vector along
+// with the model version that produced it and the metric to compare with.
+func (v *VectorIndex) query(ctx context.Context, text string) ([]float32, string, Metric, error) {
+	v.mu.RLock()
+	embedder, model, metric := v.embedder, v.model, v.metric
+	v.mu.RUnlock()
+	vec, err := embedQuery(ctx, embedder, text)
+	return vec, model, metric, err
+}
+
+// NewVectorStrategy creates an embedding strategy that scores against vectors
+// stored in idx instead of embedding every document per query. Documents
+// without a vector from the serving model are embedded on the fly.
+func NewVectorStrategy(idx *VectorIndex) Strategy {
+	return vectorStrategy{index: idx}
+}
+
+type vectorStrategy struct {
+	index *VectorIndex
+}
+
+func (s vectorStrategy) Score(ctx context.Context, query string, doc Document) (float64, error) {
+	if s.index == nil {
+		return 0, ErrInvalidEmbedder
+	}
+	qVec, model, metric, err := s.index.query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	s.index.mu.RLock()
+	sv, ok := s.index.vectors[doc.ID]
+	s.index.mu.RUnlock()
+	if ok && sv.Model == model {
+		return s.index.newQueryVector(qVec).similarity(metric, sv), nil
+	}
+	dVec, _, _, err := s.index.query(ctx, documentText(doc))
+	if err != nil {
+		return 0, err
+	}
+	return metric.Similarity(qVec, dVec), nil
+}
+
+// queryVector is a query embedding prepared for comparison with stored
+// vectors, quantized once when the index quantizes.
+type queryVector struct {
+	vec       []float32
+	quantized *QuantizedVector
+}
+
+func (v *VectorIndex) newQueryVector(vec []float32) queryVector {
+	q := queryVector{vec: vec}
+	if v.quantize {
+		qv := Quantize(vec)
+		q.quantized = &qv
+	}
+	return q
+}
+
+// similarity compares the query with sv, on int8 values when both are
+// quantized.
+func (q queryVector) similarity(metric Metric, sv StoredVector) float64 {
+	if sv.quantized != nil {
+		if q.quantized != nil {
+			return metric.QuantizedSimilarity(*q.quantized, *sv.quantized)
+		}
+		sv = sv.float()
+	}
+	return metric.Similarity(q.vec, sv.Vector)
+}
+
+func documentText(doc Document) string {
+	if doc.Text != "" {
+		return doc.Text
+	}
+	return doc.Normalized().Text
+}
+
+// fingerprint identifies document text for embedding cache lookups.
+func fingerprint(text string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(text))
+	return h.Sum64()
+}
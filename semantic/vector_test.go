@@ -0,0 +1,328 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// modelEmbedder returns a fixed vector per model and counts calls.
+type modelEmbedder struct {
+	vec   []float32
+	calls atomic.Int32
+	fail  bool
+}
+
+func (e *modelEmbedder) Embed(_ context.Context, _ string) ([]float32, error) {
+	e.calls.Add(1)
+	if e.fail {
+		return nil, errors.New("provider down")
+	}
+	return e.vec, nil
+}
+
+func newTestVectorIndex(t *testing.T, ids ...string) (*VectorIndex, *modelEmbedder) {
+	t.Helper()
+	emb := &modelEmbedder{vec: []float32{1, 0}}
+	idx, err := NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	for _, id := range ids {
+		if err := idx.Upsert(context.Background(), Document{ID: id, Name: id}); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+	}
+	return idx, emb
+}
+
+func TestVectorIndex_Validation(t *testing.T) {
+	if _, err := NewVectorIndex(nil, "v1"); !errors.Is(err, ErrInvalidEmbedder) {
+		t.Fatalf("expected ErrInvalidEmbedder, got %v", err)
+	}
+	if _, err := NewVectorIndex(&modelEmbedder{}, ""); !errors.Is(err, ErrInvalidModel) {
+		t.Fatalf("expected ErrInvalidModel, got %v", err)
+	}
+	idx, _ := newTestVectorIndex(t)
+	if err := idx.Upsert(context.Background(), Document{}); !errors.Is(err, ErrInvalidDocumentID) {
+		t.Fatalf("expected ErrInvalidDocumentID, got %v", err)
+	}
+}
+
+func TestVectorIndex_ReembedAllCutsOver(t *testing.T) {
+	idx, _ := newTestVectorIndex(t, "a", "b", "c")
+	if stale := idx.StaleIDs("v2"); len(stale) != 3 {
+		t.Fatalf("expected 3 stale docs before campaign, got %v", stale)
+	}
+
+	next := &modelEmbedder{vec: []float32{0, 1}}
+	var progress []int
+	stats, err := idx.ReembedAll(context.Background(), ReembedOptions{
+		Embedder:   next,
+		Model:      "v2",
+		BatchSize:  2,
+		OnProgress: func(done, _ int) { progress = append(progress, done) },
+	})
+	if err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	if stats.Total != 3 || stats.Embedded != 3 || stats.Batches != 2 || stats.FromModel != "v1" || stats.ToModel != "v2" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(progress) != 2 || progress[1] != 3 {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+	if idx.Model() != "v2" {
+		t.Errorf("Model = %s, want v2", idx.Model())
+	}
+	if stale := idx.StaleIDs("v2"); len(stale) != 0 {
+		t.Errorf("expected no stale docs after cutover, got %v", stale)
+	}
+	if sv, _ := idx.Vector("a"); sv.Model != "v2" || sv.Vector[1] != 1 {
+		t.Errorf("unexpected vector after cutover: %+v", sv)
+	}
+}
+
+func TestVectorIndex_ServesOldVectorsUntilCutover(t *testing.T) {
+	idx, _ := newTestVectorIndex(t, "a", "b")
+	next := &modelEmbedder{vec: []float32{0, 1}}
+
+	_, err := idx.ReembedAll(context.Background(), ReembedOptions{
+		Embedder:  next,
+		Model:     "v2",
+		BatchSize: 1,
+		OnProgress: func(done, _ int) {
+			if done == 1 {
+				if idx.Model() != "v1" {
+					t.Error("model switched before campaign finished")
+				}
+				if sv, _ := idx.Vector("a"); sv.Model != "v1" {
+					t.Errorf("serving vector changed mid-campaign: %+v", sv)
+				}
+				if err := idx.Upsert(context.Background(), Document{ID: "late", Name: "late"}); err != nil {
+					t.Errorf("Upsert during campaign failed: %v", err)
+				}
+				if _, err := idx.ReembedAll(context.Background(), ReembedOptions{Embedder: next, Model: "v3"}); !errors.Is(err, ErrCampaignRunning) {
+					t.Errorf("expected ErrCampaignRunning, got %v", err)
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	if sv, ok := idx.Vector("late"); !ok || sv.Model != "v2" {
+		t.Errorf("document added mid-campaign should be dual-written, got %+v", sv)
+	}
+}
+
+// gatedEmbedder blocks Embed calls while armed until release is closed.
+type gatedEmbedder struct {
+	modelEmbedder
+	armed   atomic.Bool
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (e *gatedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.armed.Load() {
+		e.once.Do(func() { close(e.entered) })
+		<-e.release
+	}
+	return e.modelEmbedder.Embed(ctx, text)
+}
+
+func TestVectorIndex_UpsertRacingCampaignStart(t *testing.T) {
+	emb := &gatedEmbedder{
+		modelEmbedder: modelEmbedder{vec: []float32{1, 0}},
+		entered:       make(chan struct{}),
+		release:       make(chan struct{}),
+	}
+	idx, err := NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		if err := idx.Upsert(ctx, Document{ID: id, Name: id}); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+	}
+
+	// The upsert embeds with the serving model before the campaign starts
+	// and stores its documents after the campaign has taken its snapshot.
+	emb.armed.Store(true)
+	upserted := make(chan error, 1)
+	go func() {
+		upserted <- idx.UpsertBatch(ctx, []Document{{ID: "a", Name: "renamed"}, {ID: "late", Name: "late"}})
+	}()
+	<-emb.entered
+
+	next := &batchEmbedder{}
+	_, err = idx.ReembedAll(ctx, ReembedOptions{
+		Embedder:  next,
+		Model:     "v2",
+		BatchSize: 1,
+		OnProgress: func(done, _ int) {
+			if done == 1 {
+				close(emb.release)
+				if err := <-upserted; err != nil {
+					t.Errorf("UpsertBatch failed: %v", err)
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	for _, id := range []string{"a", "b", "late"} {
+		if sv, ok := idx.Vector(id); !ok || sv.Model != "v2" {
+			t.Errorf("Vector(%s) after cutover = %+v, %v; want v2 vector", id, sv, ok)
+		}
+	}
+	want := float32(len(documentText(Document{ID: "a", Name: "renamed"})))
+	if sv, _ := idx.Vector("a"); len(sv.Vector) != 2 || sv.Vector[1] != want {
+		t.Errorf("Vector(a) = %v, want the new text embedded by the campaign", sv.Vector)
+	}
+	matches, err := idx.Nearest(ctx, "query", 10)
+	if err != nil {
+		t.Fatalf("Nearest failed: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Errorf("Nearest returned %d matches, want 3", len(matches))
+	}
+}
+
+func TestVectorIndex_FailedCampaignKeepsServing(t *testing.T) {
+	idx, _ := newTestVectorIndex(t, "a")
+	_, err := idx.ReembedAll(context.Background(), ReembedOptions{
+		Embedder: &modelEmbedder{fail: true},
+		Model:    "v2",
+	})
+	if err == nil {
+		t.Fatal("expected campaign error")
+	}
+	if idx.Model() != "v1" {
+		t.Errorf("Model = %s, want v1 after failed campaign", idx.Model())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := idx.ReembedAll(ctx, ReembedOptions{Embedder: &modelEmbedder{}, Model: "v2"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVectorStrategy_UsesStoredVectors(t *testing.T) {
+	idx, emb := newTestVectorIndex(t, "a")
+	strategy := NewVectorStrategy(idx)
+
+	before := emb.calls.Load()
+	score, err := strategy.Score(context.Background(), "query", Document{ID: "a"})
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if score < 0.999 {
+		t.Errorf("score = %v, want 1", score)
+	}
+	if calls := emb.calls.Load() - before; calls != 1 {
+		t.Errorf("expected only the query to be embedded, got %d calls", calls)
+	}
+}
+
+func TestVectorIndex_InjectedClock(t *testing.T) {
+	fixed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	idx, err := NewVectorIndex(&modelEmbedder{vec: []float32{1, 0}}, "v1", VectorIndexOptions{Now: func() time.Time { return fixed }})
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	if err := idx.Upsert(context.Background(), Document{ID: "a"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if sv, _ := idx.Vector("a"); !sv.EmbeddedAt.Equal(fixed) {
+		t.Fatalf("EmbeddedAt = %v, want %v", sv.EmbeddedAt, fixed)
+	}
+}
+
+func TestVectorIndex_UpsertSkipsUnchangedDocuments(t *testing.T) {
+	idx, emb := newTestVectorIndex(t, "a")
+	before := emb.calls.Load()
+
+	if err := idx.Upsert(context.Background(), Document{ID: "a", Name: "a"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if calls := emb.calls.Load() - before; calls != 0 {
+		t.Fatalf("unchanged upsert made %d Embed calls, want 0", calls)
+	}
+	if err := idx.Upsert(context.Background(), Document{ID: "a", Name: "renamed"}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if calls := emb.calls.Load() - before; calls != 1 {
+		t.Fatalf("changed upsert made %d Embed calls, want 1", calls)
+	}
+}
+
+func TestVectorIndex_SimilaritiesEmbedsQueryOnce(t *testing.T) {
+	idx, emb := newTestVectorIndex(t)
+	docs := []Document{{ID: "a", Name: "a"}, {ID: "b", Name: "b"}, {ID: "c", Name: "c"}}
+
+	scores, err := idx.Similarities(context.Background(), "query", docs)
+	if err != nil {
+		t.Fatalf("Similarities failed: %v", err)
+	}
+	if len(scores) != 3 || scores[0] < 0.999 {
+		t.Fatalf("scores = %v", scores)
+	}
+	if calls := emb.calls.Load(); calls != 4 {
+		t.Fatalf("first search made %d Embed calls, want 4 (query + 3 docs)", calls)
+	}
+
+	if _, err := idx.Similarities(context.Background(), "query", docs); err != nil {
+		t.Fatalf("Similarities failed: %v", err)
+	}
+	if calls := emb.calls.Load(); calls != 5 {
+		t.Fatalf("second search made %d Embed calls, want 1 more (query only)", calls-4)
+	}
+}
+
+func TestVectorIndex_Nearest(t *testing.T) {
+	emb := &textEmbedder{vectors: map[string][]float32{
+		"query": {1, 0},
+		"a":     {1, 0},
+		"b":     {1, 1},
+		"c":     {0, 1},
+	}}
+	idx, err := NewVectorIndex(emb, "v1")
+	if err != nil {
+		t.Fatalf("NewVectorIndex failed: %v", err)
+	}
+	for _, id := range []string{"c", "b", "a"} {
+		if err := idx.Upsert(context.Background(), Document{ID: id, Text: id}); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+	}
+
+	matches, err := idx.Nearest(context.Background(), "query", 2)
+	if err != nil {
+		t.Fatalf("Nearest failed: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != "a" || matches[1].ID != "b" {
+		t.Fatalf("matches = %+v, want a then b", matches)
+	}
+	if matches, _ := idx.Nearest(context.Background(), "query", 0); len(matches) != 0 {
+		t.Fatalf("k=0 matches = %+v, want none", matches)
+	}
+}
+
+// textEmbedder returns a fixed vector per input text.
+type textEmbedder struct {
+	vectors map[string][]float32
+}
+
+func (e *textEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	return e.vectors[text], nil
+}
@@ -0,0 +1,177 @@
+package tooldoc
+
+import (
+	"encoding/json"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Omission describes content ApplyBudget trimmed from a ToolDoc.
+type Omission struct {
+	// Field is the JSON name of the trimmed ToolDoc field, such as
+	// "examples", "notes", or "tool.outputSchema".
+	Field string `json:"field"`
+
+	// IDs names the removed examples (by ID, or by position as "#<n>" when
+	// the example has none) and related tools.
+	IDs []string `json:"ids,omitempty"`
+
+	// Count is the number of items removed from a list field.
+	Count int `json:"count,omitempty"`
+
+	// Bytes is how much the trim shrank the JSON encoding of the doc.
+	Bytes int `json:"bytes"`
+
+	// Partial reports that text was shortened rather than removed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// TrimReport describes how ApplyBudget fitted a ToolDoc to its budget, so
+// clients can fetch the omitted content explicitly, for example with
+// ListExamples or an unbudgeted DescribeTool.
+type TrimReport struct {
+	// MaxBytes is the budget.
+	MaxBytes int `json:"maxBytes"`
+
+	// OriginalBytes and Bytes are the JSON size of the doc before and
+	// after trimming. Bytes exceeds MaxBytes when the content ApplyBudget
+	// keeps is larger than the budget on its own.
+	OriginalBytes int `json:"originalBytes"`
+	Bytes         int `json:"bytes"`
+
+	// Omitted lists the trims in the order they were applied.
+	Omitted []Omission `json:"omitted,omitempty"`
+}
+
+// Trimmed reports whether anything was removed.
+func (r TrimReport) Trimmed() bool {
+	return len(r.Omitted) > 0
+}
+
+// ApplyBudget trims doc until its JSON encoding is at most maxBytes,
+// removing the lowest-priority content first and stopping as soon as it
+// fits:
+//
+//  1. examples beyond the first, lowest priority first
+//  2. SeeAlso, then ExternalRefs
+//  3. Notes, shortened as little as possible before being removed
+//  4. the last example
+//  5. Owner, then Tool.OutputSchema
+//
+// The summary, input schema, and parameters are never removed. doc is not
+// modified. A maxBytes of zero or less disables the budget.
+func ApplyBudget(doc ToolDoc, maxBytes int) (ToolDoc, TrimReport) {
+	size := encodedDocSize(doc)
+	report := TrimReport{MaxBytes: maxBytes, OriginalBytes: size, Bytes: size}
+	if maxBytes <= 0 || size <= maxBytes {
+		return doc, report
+	}
+
+	// omit applies trim and records it when it changed anything.
+	omit := func(o Omission, trim func()) bool {
+		trim()
+		next := encodedDocSize(doc)
+		if next < size {
+			o.Bytes = size - next
+			report.Omitted = append(report.Omitted, o)
+			size = next
+			report.Bytes = size
+		}
+		return size <= maxBytes
+	}
+
+	if n := len(doc.Examples); n > 1 {
+		o := Omission{Field: "examples"}
+		fits := false
+		for n > 1 && !fits {
+			n--
+			o.IDs = append(o.IDs, exampleRef(doc.Examples[n], n))
+			o.Count++
+			doc.Examples = doc.Examples[:n]
+			fits = encodedDocSize(doc) <= maxBytes
+		}
+		if omit(o, func() {}) {
+			return doc, report
+		}
+	}
+
+	if len(doc.SeeAlso) > 0 {
+		o := Omission{Field: "seeAlso", Count: len(doc.SeeAlso)}
+		for _, rel := range doc.SeeAlso {
+			o.IDs = append(o.IDs, rel.ID)
+		}
+		if omit(o, func() { doc.SeeAlso = nil }) {
+			return doc, report
+		}
+	}
+	if len(doc.ExternalRefs) > 0 {
+		o := Omission{Field: "externalRefs", Count: len(doc.ExternalRefs)}
+		if omit(o, func() { doc.ExternalRefs = nil }) {
+			return doc, report
+		}
+	}
+
+	if doc.Notes != "" {
+		// Shorten by the overshoot; JSON escaping can make a cut worth less
+		// than its length, so repeat until the doc fits or notes are gone.
+		full := doc.Notes
+		for doc.Notes != "" && encodedDocSize(doc) > maxBytes {
+			keep := max(len(doc.Notes)-max(encodedDocSize(doc)-maxBytes, 1), 0)
+			for keep > 0 && !utf8.RuneStart(full[keep]) {
+				keep--
+			}
+			doc.Notes = full[:keep]
+		}
+		if omit(Omission{Field: "notes", Partial: doc.Notes != ""}, func() {}) {
+			return doc, report
+		}
+	}
+
+	if len(doc.Examples) > 0 {
+		o := Omission{Field: "examples", IDs: []string{exampleRef(doc.Examples[0], 0)}, Count: 1}
+		if omit(o, func() { doc.Examples = nil }) {
+			return doc, report
+		}
+	}
+
+	if doc.Owner != nil {
+		if omit(Omission{Field: "owner"}, func() { doc.Owner = nil }) {
+			return doc, report
+		}
+	}
+	if doc.Tool != nil && doc.Tool.OutputSchema != nil {
+		omit(Omission{Field: "tool.outputSchema"}, func() {
+			tool := *doc.Tool
+			tool.OutputSchema = nil
+			doc.Tool = &tool
+		})
+	}
+	return doc, report
+}
+
+// exampleRef names the example at position i for an Omission.
+func exampleRef(ex ToolExample, i int) string {
+	if ex.ID != "" {
+		return ex.ID
+	}
+	return "#" + strconv.Itoa(i)
+}
+
+func encodedDocSize(doc ToolDoc) int {
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
+
+// DescribeWithBudget describes a tool with s at the given detail level and
+// trims the result with ApplyBudget to at most maxBytes of JSON.
+func DescribeWithBudget(s Store, id string, level DetailLevel, maxBytes int) (ToolDoc, TrimReport, error) {
+	doc, err := s.DescribeTool(id, level)
+	if err != nil {
+		return ToolDoc{}, TrimReport{}, err
+	}
+	doc, report := ApplyBudget(doc, maxBytes)
+	return doc, report, nil
+}
@@ -0,0 +1,79 @@
+package tooldoc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyBudget(t *testing.T) {
+	store := profileStore(t)
+	full, err := store.DescribeTool("tickets:create", DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	fullSize := encodedDocSize(full)
+
+	doc, report := ApplyBudget(full, 0)
+	if report.Trimmed() || len(doc.Examples) != len(full.Examples) {
+		t.Errorf("zero budget trimmed: %+v", report)
+	}
+
+	doc, report = ApplyBudget(full, fullSize-1)
+	if len(report.Omitted) != 1 || report.Omitted[0].Field != "examples" || report.Omitted[0].Count != 1 {
+		t.Fatalf("report = %+v, want one example omitted", report)
+	}
+	if len(doc.Examples) != len(full.Examples)-1 || doc.Notes != full.Notes {
+		t.Errorf("got %d examples, notes %d; want only the last example dropped", len(doc.Examples), len(doc.Notes))
+	}
+	if len(full.Examples) != 4 {
+		t.Errorf("input modified: %d examples", len(full.Examples))
+	}
+
+	// A budget that leaves room for part of the notes.
+	budget := fullSize - 3*300 - 600
+	doc, report = ApplyBudget(full, budget)
+	if report.Bytes > budget || report.Bytes != encodedDocSize(doc) || report.OriginalBytes != fullSize {
+		t.Fatalf("report sizes = %+v, encoded %d", report, encodedDocSize(doc))
+	}
+	var notes *Omission
+	for i, o := range report.Omitted {
+		if o.Field == "notes" {
+			notes = &report.Omitted[i]
+		}
+	}
+	if notes == nil || !notes.Partial || doc.Notes == "" || len(doc.Notes) >= len(full.Notes) {
+		t.Errorf("notes omission = %+v, notes %d; want partially trimmed", notes, len(doc.Notes))
+	}
+	if len(doc.Examples) != 1 || doc.ExternalRefs != nil {
+		t.Errorf("got %d examples, refs %v; want one example and no refs", len(doc.Examples), doc.ExternalRefs)
+	}
+
+	// A tiny budget keeps the summary and input schema.
+	doc, report = ApplyBudget(full, 10)
+	if doc.Tool == nil || doc.Tool.InputSchema == nil || doc.Tool.OutputSchema != nil || doc.Notes != "" || len(doc.Examples) != 0 {
+		t.Errorf("doc = %+v, want schema-level content only", doc)
+	}
+	if report.Bytes <= report.MaxBytes {
+		t.Errorf("report = %+v, want over budget", report)
+	}
+	if last := report.Omitted[len(report.Omitted)-1]; last.Field != "tool.outputSchema" {
+		t.Errorf("last omission = %+v", last)
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("report not encodable: %v", err)
+	}
+}
+
+func TestDescribeWithBudget(t *testing.T) {
+	store := profileStore(t)
+	doc, report, err := DescribeWithBudget(store, "tickets:create", DetailFull, 1500)
+	if err != nil {
+		t.Fatalf("DescribeWithBudget failed: %v", err)
+	}
+	if !report.Trimmed() || report.Bytes > 1500 || encodedDocSize(doc) != report.Bytes {
+		t.Errorf("report = %+v", report)
+	}
+	if _, _, err := DescribeWithBudget(store, "tickets:missing", DetailFull, 1500); err == nil {
+		t.Error("expected error for unknown tool")
+	}
+}
@@ -0,0 +1,170 @@
+package tooldoc
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// ChangeType describes a mutation event in the documentation store.
+type ChangeType string
+
+const (
+	ChangeRegistered ChangeType = "registered"
+	ChangeUpdated    ChangeType = "updated"
+	ChangeRemoved    ChangeType = "removed"
+)
+
+// DocField names a documentation field reported in ChangeEvent.Fields.
+type DocField string
+
+const (
+	FieldSummary      DocField = "summary"
+	FieldNotes        DocField = "notes"
+	FieldExamples     DocField = "examples"
+	FieldExternalRefs DocField = "externalRefs"
+	FieldSeeAlso      DocField = "seeAlso"
+	FieldOwner        DocField = "owner"
+)
+
+// ChangeEvent captures a documentation mutation for reactive integration.
+type ChangeEvent struct {
+	Type   ChangeType
+	ToolID string
+
+	// Fields lists the fields whose values changed, in declaration order.
+	// For ChangeRegistered it lists the fields that were set; for
+	// ChangeRemoved it is nil.
+	Fields []DocField
+}
+
+// ChangeListener receives change events from an InMemoryStore.
+type ChangeListener func(ChangeEvent)
+
+type listenerEntry struct {
+	id uint64
+	fn ChangeListener
+}
+
+// OnChange registers a listener for documentation mutations and returns an
+// unsubscribe function that is safe to call multiple times.
+//
+// Listeners run synchronously after the store lock is released, in
+// registration order. Updates that leave every field unchanged emit no
+// event. Namespace owners are not per-tool documentation and emit no events.
+func (s *InMemoryStore) OnChange(listener ChangeListener) func() {
+	if listener == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	s.nextListenerID++
+	entry := listenerEntry{id: s.nextListenerID, fn: listener}
+	s.listeners = append(s.listeners, entry)
+	s.mu.Unlock()
+
+	return func() {
+		s.removeListener(entry.id)
+	}
+}
+
+func (s *InMemoryStore) removeListener(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range s.listeners {
+		if entry.id == id {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *InMemoryStore) snapshotListenersLocked() []ChangeListener {
+	if len(s.listeners) == 0 {
+		return nil
+	}
+	out := make([]ChangeListener, len(s.listeners))
+	for i, entry := range s.listeners {
+		out[i] = entry.fn
+	}
+	return out
+}
+
+func notifyListeners(listeners []ChangeListener, event ChangeEvent) {
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// mutate applies fn to the record for id under the store lock and notifies
+// listeners of the resulting change. A missing record is created when create
+// is true and reported as ErrNotFound otherwise. If fn fails, a newly created
+// record is discarded; fn must not modify the record before failing. A
+// result larger than the store's MaxDocBytes is rolled back.
+func (s *InMemoryStore) mutate(id string, create bool, fn func(record *docRecord) error) error {
+	s.mu.Lock()
+	record, exists := s.docs[id]
+	if !exists {
+		if !create {
+			s.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		record = &docRecord{}
+	}
+	before := *record
+	if err := fn(record); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if err := s.checkDocSizeLocked(id, record); err != nil {
+		*record = before
+		s.mu.Unlock()
+		return err
+	}
+	s.internExamplesLocked(record.examples)
+	s.releaseExamplesLocked(before.examples)
+	s.docs[id] = record
+
+	event := ChangeEvent{Type: ChangeUpdated, ToolID: id, Fields: changedFields(before, *record)}
+	if !exists {
+		event.Type = ChangeRegistered
+	}
+	var listeners []ChangeListener
+	if !exists || len(event.Fields) > 0 {
+		listeners = s.snapshotListenersLocked()
+	}
+	s.mu.Unlock()
+
+	notifyListeners(listeners, event)
+	return nil
+}
+
+// changedFields reports which fields differ between two records.
+func changedFields(before, after docRecord) []DocField {
+	var fields []DocField
+	if before.summary != after.summary {
+		fields = append(fields, FieldSummary)
+	}
+	if before.notes != after.notes {
+		fields = append(fields, FieldNotes)
+	}
+	if !reflect.DeepEqual(before.examples, after.examples) && (len(before.examples) > 0 || len(after.examples) > 0) {
+		fields = append(fields, FieldExamples)
+	}
+	if !slices.Equal(before.externalRefs, after.externalRefs) {
+		fields = append(fields, FieldExternalRefs)
+	}
+	if !slices.Equal(before.seeAlso, after.seeAlso) {
+		fields = append(fields, FieldSeeAlso)
+	}
+	if !ownersEqual(before.owner, after.owner) {
+		fields = append(fields, FieldOwner)
+	}
+	return fields
+}
+
+func ownersEqual(a, b *Owner) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
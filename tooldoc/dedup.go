@@ -0,0 +1,152 @@
+package tooldoc
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Example Args are content-addressed: identical Args registered for any
+// number of examples or tools share one stored copy. Catalogs generated from
+// templates register thousands of near-identical examples, and without
+// sharing each would hold its own deep copy.
+//
+// Shared Args are never modified in place. Mutations replace whole examples,
+// and every read returns a deep copy, so callers cannot observe or change
+// the shared value.
+
+// argsEntry is one shared Args value and the number of stored examples
+// referencing it.
+type argsEntry struct {
+	args map[string]any
+	refs int
+}
+
+// ArgsPoolStats reports example Args deduplication.
+type ArgsPoolStats struct {
+	// Unique is the number of distinct Args values stored.
+	Unique int `json:"unique"`
+	// References is the number of stored examples pointing at a shared
+	// value. References - Unique copies were avoided.
+	References int `json:"references"`
+}
+
+// ArgsPoolStats returns example Args deduplication counters.
+func (s *InMemoryStore) ArgsPoolStats() ArgsPoolStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats := ArgsPoolStats{Unique: len(s.argsPool)}
+	for _, entry := range s.argsPool {
+		stats.References += entry.refs
+	}
+	return stats
+}
+
+// internExamplesLocked replaces the Args of examples with their shared copy
+// and takes a reference for each. Args that cannot be hashed are kept as-is.
+func (s *InMemoryStore) internExamplesLocked(examples []ToolExample) {
+	for i := range examples {
+		key, ok := argsKey(examples[i].Args)
+		if !ok {
+			continue
+		}
+		entry, exists := s.argsPool[key]
+		if !exists {
+			entry = &argsEntry{args: examples[i].Args}
+			s.argsPool[key] = entry
+		}
+		entry.refs++
+		examples[i].Args = entry.args
+	}
+}
+
+// releaseExamplesLocked drops the references held by examples, freeing
+// shared Args no longer referenced.
+func (s *InMemoryStore) releaseExamplesLocked(examples []ToolExample) {
+	for _, ex := range examples {
+		key, ok := argsKey(ex.Args)
+		if !ok {
+			continue
+		}
+		if entry, exists := s.argsPool[key]; exists {
+			entry.refs--
+			if entry.refs <= 0 {
+				delete(s.argsPool, key)
+			}
+		}
+	}
+}
+
+// argsKey returns the content hash of args. It reports false for empty
+// Args, which are not worth sharing, and for values of types it cannot
+// hash canonically.
+func argsKey(args map[string]any) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	h := sha256.New()
+	if !hashValue(h, args) {
+		return "", false
+	}
+	return string(h.Sum(nil)), true
+}
+
+// hashValue writes a type-tagged canonical encoding of v, so values that
+// marshal alike but differ in Go type (int 1 and float64 1) stay distinct.
+func hashValue(h hash.Hash, v any) bool {
+	var buf [8]byte
+	writeString := func(tag byte, s string) {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(s)))
+		h.Write([]byte{tag})
+		h.Write(buf[:])
+		h.Write([]byte(s))
+	}
+	switch val := v.(type) {
+	case nil:
+		h.Write([]byte{'n'})
+	case bool:
+		writeString('b', strconv.FormatBool(val))
+	case string:
+		writeString('s', val)
+	case float64:
+		binary.BigEndian.PutUint64(buf[:], math.Float64bits(val))
+		h.Write([]byte{'f'})
+		h.Write(buf[:])
+	case float32:
+		writeString('g', strconv.FormatFloat(float64(val), 'g', -1, 32))
+	case int:
+		writeString('i', strconv.Itoa(val))
+	case int64:
+		writeString('I', strconv.FormatInt(val, 10))
+	case int32:
+		writeString('j', strconv.FormatInt(int64(val), 10))
+	case uint64:
+		writeString('u', strconv.FormatUint(val, 10))
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		writeString('m', strconv.Itoa(len(keys)))
+		for _, k := range keys {
+			writeString('k', k)
+			if !hashValue(h, val[k]) {
+				return false
+			}
+		}
+	case []any:
+		writeString('a', strconv.Itoa(len(val)))
+		for _, item := range val {
+			if !hashValue(h, item) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
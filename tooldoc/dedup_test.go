@@ -0,0 +1,75 @@
+package tooldoc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestArgsPool_SharesIdenticalArgs(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	args := func() map[string]any {
+		return map[string]any{"query": "go", "filters": map[string]any{"lang": []any{"en"}}}
+	}
+	for i := range 100 {
+		mustRegisterExamples(t, store, fmt.Sprintf("ns:tool%d", i), []ToolExample{
+			{ID: "a", Title: "A", Args: args()},
+			{ID: "b", Title: "B", Args: map[string]any{"limit": i}},
+		})
+	}
+
+	stats := store.ArgsPoolStats()
+	if stats.Unique != 101 || stats.References != 200 {
+		t.Fatalf("stats = %+v, want 101 unique / 200 references", stats)
+	}
+
+	store.mu.RLock()
+	first, last := store.docs["ns:tool0"].examples[0].Args, store.docs["ns:tool99"].examples[0].Args
+	store.mu.RUnlock()
+	if fmt.Sprintf("%p", first) != fmt.Sprintf("%p", last) {
+		t.Error("identical Args should share storage")
+	}
+
+	// Reads are copies: mutating one tool's example leaves the others intact.
+	examples, _ := store.ListExamples("ns:tool0", 10)
+	examples[0].Args["query"] = "mutated"
+	examples[0].Args["filters"].(map[string]any)["lang"] = "xx"
+	other, _ := store.ListExamples("ns:tool1", 10)
+	if other[0].Args["query"] != "go" || other[0].Args["filters"].(map[string]any)["lang"].([]any)[0] != "en" {
+		t.Errorf("shared Args were mutated through a read: %v", other[0].Args)
+	}
+}
+
+func TestArgsPool_ReleasesReferences(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	shared := map[string]any{"q": "x"}
+	mustRegisterExamples(t, store, "ns:a", []ToolExample{{ID: "1", Title: "1", Args: shared}})
+	mustRegisterExamples(t, store, "ns:b", []ToolExample{{ID: "1", Title: "1", Args: shared}})
+
+	if err := store.UpdateExample("ns:a", "1", ToolExample{Title: "1", Args: map[string]any{"q": "y"}}); err != nil {
+		t.Fatalf("UpdateExample failed: %v", err)
+	}
+	if stats := store.ArgsPoolStats(); stats.Unique != 2 || stats.References != 2 {
+		t.Fatalf("after update: %+v", stats)
+	}
+	if err := store.RemoveDoc("ns:a"); err != nil {
+		t.Fatalf("RemoveDoc failed: %v", err)
+	}
+	if err := store.DeleteExample("ns:b", "1"); err != nil {
+		t.Fatalf("DeleteExample failed: %v", err)
+	}
+	if stats := store.ArgsPoolStats(); stats.Unique != 0 || stats.References != 0 {
+		t.Fatalf("expected empty pool, got %+v", stats)
+	}
+}
+
+func TestArgsKey_DistinguishesTypes(t *testing.T) {
+	intKey, _ := argsKey(map[string]any{"n": 1})
+	floatKey, _ := argsKey(map[string]any{"n": 1.0})
+	strKey, _ := argsKey(map[string]any{"n": "1"})
+	if intKey == floatKey || floatKey == strKey || intKey == strKey {
+		t.Error("Args differing only in value type must not share storage")
+	}
+	if _, ok := argsKey(map[string]any{"ch": make(chan int)}); ok {
+		t.Error("expected unhashable Args to be kept unshared")
+	}
+}
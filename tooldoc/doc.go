@@ -11,22 +11,95 @@
 // docs-only registration (no tool in index required).
 //
 // Schema: Includes the full model.Tool (InputSchema/OutputSchema/Annotations).
-// Adds derived schema info (required fields, defaults, allowed types) when
-// available (best-effort). Requires tool to be resolved via index or
-// StoreOptions.ToolResolver.
+// Adds derived schema info (required fields, defaults, allowed types, enums,
+// and descriptions, keyed by paths such as "config.retries" that reach into
+// nested objects and array items) and a flattened parameter table (see
+// ParameterTable) when available (best-effort). Requires tool to be resolved via index or
+// StoreOptions.ToolResolver. RenderParameterTable formats the table as
+// Markdown for human-readable docs.
 //
 // Full: Includes everything in Schema plus human-authored Notes (constraints,
 // pagination/auth hints, error semantics), optional small set of examples (1-3),
-// and external references (URLs or resource IDs). Requires tool via index
-// or StoreOptions.ToolResolver.
+// external references (URLs or resource IDs), the owner, and related tools.
+// Requires tool via index or StoreOptions.ToolResolver.
+//
+// DocEntry.SeeAlso names related tool IDs, validated at registration
+// (ErrInvalidReference for unknown IDs). At full detail they are resolved to
+// RelatedTool summaries for "related tools" navigation.
+//
+// # Truncation Profiles
+//
+// Consumers with different context budgets select a TruncationProfile per
+// call instead of trimming docs themselves. LookupProfile returns the
+// built-in "compact", "standard", and "verbose" profiles, which cap Notes,
+// examples, and parameter descriptions (compact also drops the output
+// schema and references); TruncationProfile.Apply trims any ToolDoc:
+//
+//	doc, err := tooldoc.DescribeWithProfile(store, "github:create_issue",
+//		tooldoc.DetailFull, tooldoc.ProfileCompact)
+//
+// # Size Budgets
+//
+// ApplyBudget fits a ToolDoc to a byte budget instead, measured as JSON. It
+// trims the lowest-priority content first (extra examples, references, then
+// notes, shortened before they are removed) and returns a TrimReport listing
+// each Omission, so clients know what to fetch explicitly, for example with
+// ListExamples:
+//
+//	doc, report, err := tooldoc.DescribeWithBudget(store, "github:create_issue",
+//		tooldoc.DetailFull, 4096)
+//	for _, o := range report.Omitted {
+//		log.Printf("omitted %s (%d bytes)", o.Field, o.Bytes)
+//	}
+//
+// # Ownership
+//
+// Owners (team, contact, escalation URL) can be set per tool with
+// DocEntry.Owner or per namespace with RegisterNamespaceOwner. WhoOwns
+// resolves the tool owner first, then the namespace owner:
+//
+//	_ = store.RegisterNamespaceOwner("billing", tooldoc.Owner{
+//		Team:          "payments",
+//		Contact:       "#payments-oncall",
+//		EscalationURL: "https://runbooks.example.com/payments",
+//	})
+//	owner, err := store.WhoOwns("billing:refund")
+//
+// # Loading From Files
+//
+// LoadFromFS registers docs kept in version control, one YAML file or
+// Markdown file with YAML front matter per tool, from any fs.FS such as an
+// embed.FS:
+//
+//	//go:embed docs
+//	var docsFS embed.FS
+//
+//	ids, err := tooldoc.LoadFromFS(store, docsFS, tooldoc.LoadOptions{Dir: "docs"})
+//
+// A Markdown doc such as docs/github/create_issue.md:
+//
+//	---
+//	summary: Create a GitHub issue
+//	see_also: [github:close_issue]
+//	examples:
+//	  - title: Basic issue
+//	    description: Creates an issue with a title.
+//	    args: {repo: acme/app, title: Crash on start}
+//	---
+//	Requires a token with repo scope.
 //
 // # Error Handling
 //
-// The package defines four error values:
+// The package defines these error values:
 //   - ErrNotFound: Tool ID not found in index or docs
 //   - ErrNoTool: Schema/full requested but tool not in index (docs may exist)
 //   - ErrInvalidDetail: Invalid DetailLevel value
 //   - ErrArgsTooLarge: Example Args exceeds depth (MaxArgsDepth) or size (MaxArgsKeys) caps
+//   - ErrNoOwner: Neither the tool nor its namespace has an owner
+//   - ErrInvalidNamespace: Namespace owner registered without a namespace
+//   - ErrInvalidReference: SeeAlso reference is empty, self, or unknown
+//   - ErrInvalidProfile: Unknown truncation profile name
+//   - ErrInvalidDocFile: Malformed or duplicate file passed to LoadFromFS
 //
 // Use errors.Is() to check error types.
 //
@@ -38,7 +111,43 @@
 //   - MaxArgsKeys (50): Maximum total size (map keys + slice items) across all levels
 //
 // RegisterDoc and RegisterExamples return ErrArgsTooLarge if any example
-// violates these caps.
+// violates these caps. Stores whose tools need larger payloads, such as
+// infrastructure-as-code appliers, can raise them per store with
+// StoreOptions.MaxArgsDepth and MaxArgsKeys, bounded by ArgsDepthLimit (16)
+// and ArgsKeysLimit (500).
+//
+// Identical Args are stored once, however many examples or tools register
+// them, so catalogs generated from templates do not hold thousands of
+// copies. Reads still return deep copies. ArgsPoolStats reports how many
+// distinct values are shared.
+//
+// StoreOptions.MaxDocBytes caps the total size of a tool's documentation.
+// Writes that would exceed it fail with an *index.LimitError for
+// index.LimitDocBytes and leave the documentation unchanged;
+// LimitRejections counts them.
+//
+// RemoveDoc deletes a tool's documentation and RemoveExamples deletes
+// examples by ID; both return ErrNotFound when no documentation exists.
+// UpdateExample and DeleteExample curate a single example by its stable ID
+// and return ErrExampleNotFound for unknown IDs. Examples are ordered by
+// ToolExample.Priority, highest first, then registration order.
+//
+// PatchDoc updates only the fields set in a DocPatch (summary, notes,
+// appended examples, added refs, owner) under the store lock, so independent
+// doc-authoring services need not read, modify, and re-register a DocEntry.
+//
+// # Change Notifications
+//
+// InMemoryStore.OnChange delivers a ChangeEvent for every documentation
+// mutation, naming the tool and the fields that changed, so caches and
+// webhooks can react to doc edits the same way they react to index changes:
+//
+//	unsubscribe := store.OnChange(func(e tooldoc.ChangeEvent) {
+//	    if slices.Contains(e.Fields, tooldoc.FieldSummary) {
+//	        invalidate(e.ToolID)
+//	    }
+//	})
+//	defer unsubscribe()
 //
 // # Usage
 //
@@ -0,0 +1,60 @@
+package tooldoc
+
+import (
+	"encoding/json"
+	"maps"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// LimitRejections returns the number of documentation writes rejected by
+// StoreOptions.MaxDocBytes since the store was created, keyed by
+// index.LimitDocBytes.
+func (s *InMemoryStore) LimitRejections() map[string]uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := maps.Clone(s.rejections)
+	if out == nil {
+		out = make(map[string]uint64)
+	}
+	return out
+}
+
+var _ index.LimitReporter = (*InMemoryStore)(nil)
+
+// checkDocSizeLocked rejects record when it exceeds the store's
+// MaxDocBytes. Must be called with s.mu held.
+func (s *InMemoryStore) checkDocSizeLocked(id string, record *docRecord) error {
+	if s.maxDocBytes <= 0 || docSize(record) <= s.maxDocBytes {
+		return nil
+	}
+	if s.rejections == nil {
+		s.rejections = make(map[string]uint64)
+	}
+	s.rejections[index.LimitDocBytes]++
+	return &index.LimitError{Limit: index.LimitDocBytes, Max: s.maxDocBytes, ToolID: id}
+}
+
+// docSize approximates the stored size of record in bytes: the length of
+// every text field plus the JSON encoding of example Args.
+func docSize(record *docRecord) int {
+	size := len(record.summary) + len(record.notes)
+	for _, ex := range record.examples {
+		size += len(ex.ID) + len(ex.Title) + len(ex.Description) + len(ex.ResultHint)
+		if len(ex.Args) > 0 {
+			// Args were validated as JSON-compatible on registration.
+			data, _ := json.Marshal(ex.Args)
+			size += len(data)
+		}
+	}
+	for _, ref := range record.externalRefs {
+		size += len(ref)
+	}
+	for _, ref := range record.seeAlso {
+		size += len(ref)
+	}
+	if record.owner != nil {
+		size += len(record.owner.Team) + len(record.owner.Contact) + len(record.owner.EscalationURL)
+	}
+	return size
+}
@@ -0,0 +1,237 @@
+package tooldoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidDocFile is returned by LoadFromFS, wrapped with the file path,
+// for a doc file that cannot be parsed or that documents a tool already
+// documented by another file.
+var ErrInvalidDocFile = errcode.New(errcode.InvalidArgument, "invalid doc file")
+
+// ErrNoFrontMatter is returned by ParseMarkdownDoc for markdown that does
+// not start with a front-matter block. LoadFromFS skips such files.
+var ErrNoFrontMatter = errcode.New(errcode.InvalidArgument, "no front matter")
+
+// DocRegistrar receives documentation loaded by LoadFromFS.
+// *InMemoryStore, pgstore.DocStore, and discovery.Discovery implement it.
+type DocRegistrar interface {
+	RegisterDoc(id string, entry DocEntry) error
+}
+
+// LoadOptions configures LoadFromFS.
+type LoadOptions struct {
+	// Dir is the directory to load, relative to the root of the file
+	// system. Subdirectories are included.
+	// Default: "."
+	Dir string
+}
+
+// docFile is the on-disk form of a DocEntry.
+type docFile struct {
+	Tool               string        `yaml:"tool"`
+	Summary            string        `yaml:"summary"`
+	Notes              string        `yaml:"notes"`
+	Examples           []exampleFile `yaml:"examples"`
+	ExternalRefs       []string      `yaml:"external_refs"`
+	SeeAlso            []string      `yaml:"see_also"`
+	Owner              string        `yaml:"owner"`
+	OwnerContact       string        `yaml:"owner_contact"`
+	OwnerEscalationURL string        `yaml:"owner_escalation_url"`
+}
+
+type exampleFile struct {
+	ID          string         `yaml:"id"`
+	Title       string         `yaml:"title"`
+	Description string         `yaml:"description"`
+	Args        map[string]any `yaml:"args"`
+	ResultHint  string         `yaml:"result_hint"`
+	Priority    int            `yaml:"priority"`
+}
+
+// LoadFromFS registers the documentation files in a directory of fsys with
+// store, so docs can live in version control or an embed.FS instead of
+// being registered in code. It returns the IDs of the documented tools,
+// sorted.
+//
+// Each file documents one tool:
+//
+//   - .yaml and .yml files hold a YAML document.
+//   - .md and .markdown files hold YAML front matter between "---" lines;
+//     the body after it becomes DocEntry.Notes. Markdown files without
+//     front matter, such as a README, are skipped.
+//
+// Recognized keys are tool, summary, notes, external_refs, see_also,
+// owner, owner_contact, owner_escalation_url, and examples, a list of
+// objects with id, title, description, args, result_hint, and priority.
+// Other keys are ignored. Without a tool key, the ID is derived from the
+// file's path below Dir: github/create_issue.md documents
+// "github:create_issue". Other files are ignored.
+//
+// Every file is parsed before any is registered, so a malformed file
+// (ErrInvalidDocFile) registers nothing. Registration errors, such as
+// ErrInvalidReference for an unknown see_also tool, are joined and do not
+// stop the remaining files from loading; a file whose see_also fails is
+// still loaded without its references.
+func LoadFromFS(store DocRegistrar, fsys fs.FS, opts LoadOptions) ([]string, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	entries := make(map[string]DocEntry)
+	sources := make(map[string]string)
+	var parseErrs []error
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := path.Ext(p)
+		var markdown bool
+		switch ext {
+		case ".yaml", ".yml":
+		case ".md", ".markdown":
+			markdown = true
+		default:
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		var id string
+		var entry DocEntry
+		if markdown {
+			id, entry, err = ParseMarkdownDoc(data)
+		} else {
+			id, entry, err = parseDocFile(data)
+		}
+		if errors.Is(err, ErrNoFrontMatter) {
+			return nil
+		}
+		if err != nil {
+			parseErrs = append(parseErrs, fmt.Errorf("%w: %s: %v", ErrInvalidDocFile, p, err))
+			return nil
+		}
+		if id == "" {
+			rel := strings.TrimPrefix(strings.TrimSuffix(p, ext), dir+"/")
+			id = strings.ReplaceAll(rel, "/", ":")
+		}
+		if prev, dup := sources[id]; dup {
+			parseErrs = append(parseErrs, fmt.Errorf("%w: %s: tool %s is also documented by %s", ErrInvalidDocFile, p, id, prev))
+			return nil
+		}
+		sources[id] = p
+		entries[id] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(parseErrs) > 0 {
+		return nil, errors.Join(parseErrs...)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	// Register see-also references in a second pass so they may name tools
+	// documented by later files.
+	loaded := make([]string, 0, len(ids))
+	var errs []error
+	var linked []string
+	for _, id := range ids {
+		entry := entries[id]
+		if len(entry.SeeAlso) > 0 {
+			entry.SeeAlso = nil
+			linked = append(linked, id)
+		}
+		if err := store.RegisterDoc(id, entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sources[id], err))
+			continue
+		}
+		loaded = append(loaded, id)
+	}
+	for _, id := range linked {
+		if !slices.Contains(loaded, id) {
+			continue
+		}
+		if err := store.RegisterDoc(id, entries[id]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sources[id], err))
+		}
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// ParseMarkdownDoc parses a markdown doc file as LoadFromFS reads it: YAML
+// front matter between "---" lines, with the keys LoadFromFS recognizes,
+// and a body that becomes DocEntry.Notes. It returns the tool key, which is
+// empty when the front matter has none, and ErrNoFrontMatter when data does
+// not start with front matter.
+func ParseMarkdownDoc(data []byte) (string, DocEntry, error) {
+	text := strings.ReplaceAll(string(bytes.TrimPrefix(data, []byte("\ufeff"))), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return "", DocEntry{}, ErrNoFrontMatter
+	}
+	var header, body string
+	if after, ok := strings.CutPrefix(rest, "---"); ok && (after == "" || after[0] == '\n') {
+		body = after
+	} else if header, body, ok = strings.Cut(rest, "\n---"); !ok || (body != "" && body[0] != '\n') {
+		return "", DocEntry{}, errors.New("unterminated front matter")
+	}
+	id, entry, err := parseDocFile([]byte(header))
+	if err != nil {
+		return "", DocEntry{}, err
+	}
+	if notes := strings.TrimSpace(body); notes != "" {
+		entry.Notes = notes
+	}
+	return id, entry, nil
+}
+
+// parseDocFile decodes a YAML doc file or front-matter block.
+func parseDocFile(data []byte) (string, DocEntry, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var file docFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return "", DocEntry{}, err
+	}
+	entry := DocEntry{
+		Summary:      file.Summary,
+		Notes:        file.Notes,
+		ExternalRefs: file.ExternalRefs,
+		SeeAlso:      file.SeeAlso,
+	}
+	for _, ex := range file.Examples {
+		entry.Examples = append(entry.Examples, ToolExample{
+			ID:          ex.ID,
+			Title:       ex.Title,
+			Description: ex.Description,
+			Args:        ex.Args,
+			ResultHint:  ex.ResultHint,
+			Priority:    ex.Priority,
+		})
+	}
+	owner := Owner{Team: file.Owner, Contact: file.OwnerContact, EscalationURL: file.OwnerEscalationURL}
+	if !owner.IsZero() {
+		entry.Owner = &owner
+	}
+	return strings.TrimSpace(file.Tool), entry, nil
+}
@@ -0,0 +1,133 @@
+package tooldoc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/README.md": {Data: []byte("# Tool docs\n")},
+		"docs/github/create_issue.md": {Data: []byte(`---
+summary: Create a GitHub issue
+see_also: [github:close_issue]
+owner: platform
+owner_contact: "#platform"
+examples:
+  - id: basic
+    title: Basic issue
+    description: Creates an issue with a title.
+    args:
+      repo: acme/app
+      labels: [bug]
+    result_hint: The new issue number
+    priority: 2
+---
+
+Requires a token with repo scope.
+`)},
+		"docs/close.yaml": {Data: []byte(`tool: github:close_issue
+summary: Close a GitHub issue
+notes: Closed issues can be reopened.
+external_refs:
+  - https://docs.github.com/rest/issues
+`)},
+		"docs/ignored.txt": {Data: []byte("not a doc")},
+	}
+
+	store := NewInMemoryStore(StoreOptions{})
+	ids, err := LoadFromFS(store, fsys, LoadOptions{Dir: "docs"})
+	if err != nil {
+		t.Fatalf("LoadFromFS failed: %v", err)
+	}
+	if !slices.Equal(ids, []string{"github:close_issue", "github:create_issue"}) {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	entry, err := store.GetDoc("github:create_issue")
+	if err != nil {
+		t.Fatalf("GetDoc failed: %v", err)
+	}
+	if entry.Summary != "Create a GitHub issue" || entry.Notes != "Requires a token with repo scope." {
+		t.Errorf("entry = %+v", entry)
+	}
+	if !slices.Equal(entry.SeeAlso, []string{"github:close_issue"}) {
+		t.Errorf("SeeAlso = %v", entry.SeeAlso)
+	}
+	if entry.Owner == nil || entry.Owner.Team != "platform" || entry.Owner.Contact != "#platform" {
+		t.Errorf("Owner = %+v", entry.Owner)
+	}
+	if len(entry.Examples) != 1 {
+		t.Fatalf("Examples = %+v, want 1", entry.Examples)
+	}
+	ex := entry.Examples[0]
+	if ex.ID != "basic" || ex.ResultHint != "The new issue number" || ex.Priority != 2 || ex.Args["repo"] != "acme/app" {
+		t.Errorf("example = %+v", ex)
+	}
+
+	closeDoc, err := store.GetDoc("github:close_issue")
+	if err != nil {
+		t.Fatalf("GetDoc failed: %v", err)
+	}
+	if closeDoc.Notes != "Closed issues can be reopened." || len(closeDoc.ExternalRefs) != 1 {
+		t.Errorf("close doc = %+v", closeDoc)
+	}
+}
+
+func TestLoadFromFS_Errors(t *testing.T) {
+	t.Run("malformed file registers nothing", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"a.yaml": {Data: []byte("tool: ns:a\nsummary: A\n")},
+			"b.md":   {Data: []byte("---\nsummary: [unclosed\n---\n")},
+		}
+		store := NewInMemoryStore(StoreOptions{})
+		if _, err := LoadFromFS(store, fsys, LoadOptions{}); !errors.Is(err, ErrInvalidDocFile) {
+			t.Fatalf("err = %v, want ErrInvalidDocFile", err)
+		}
+		if _, err := store.GetDoc("ns:a"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetDoc(ns:a) err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("duplicate tool", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"a.yaml": {Data: []byte("tool: ns:a\n")},
+			"b.yaml": {Data: []byte("tool: ns:a\n")},
+		}
+		if _, err := LoadFromFS(NewInMemoryStore(StoreOptions{}), fsys, LoadOptions{}); !errors.Is(err, ErrInvalidDocFile) {
+			t.Fatalf("err = %v, want ErrInvalidDocFile", err)
+		}
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"ns/a.yaml": {Data: []byte("summary: A\nsee_also: [ns:missing]\n")},
+		}
+		store := NewInMemoryStore(StoreOptions{})
+		ids, err := LoadFromFS(store, fsys, LoadOptions{})
+		if !errors.Is(err, ErrInvalidReference) {
+			t.Fatalf("err = %v, want ErrInvalidReference", err)
+		}
+		if !slices.Equal(ids, []string{"ns:a"}) {
+			t.Errorf("ids = %v, want [ns:a]", ids)
+		}
+	})
+}
+
+func TestParseMarkdownDoc(t *testing.T) {
+	id, entry, err := ParseMarkdownDoc([]byte("---\ntool: ns:a\nexamples:\n  - id: basic\n    title: Basic\n---\nBody notes.\n"))
+	if err != nil {
+		t.Fatalf("ParseMarkdownDoc failed: %v", err)
+	}
+	if id != "ns:a" || entry.Notes != "Body notes." || len(entry.Examples) != 1 || entry.Examples[0].ID != "basic" {
+		t.Errorf("ParseMarkdownDoc = %q, %+v", id, entry)
+	}
+	if _, _, err := ParseMarkdownDoc([]byte("# README\n")); !errors.Is(err, ErrNoFrontMatter) {
+		t.Errorf("no front matter error = %v, want ErrNoFrontMatter", err)
+	}
+	if _, _, err := ParseMarkdownDoc([]byte("---\ntool: ns:a\n")); err == nil || errors.Is(err, ErrNoFrontMatter) {
+		t.Errorf("unterminated error = %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package tooldoc
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+// ExampleError reports an example whose Args do not satisfy its tool's
+// InputSchema.
+type ExampleError struct {
+	ToolID    string
+	ExampleID string
+	Title     string
+	Err       error
+}
+
+// Error implements the error interface.
+func (e ExampleError) Error() string {
+	label := e.ExampleID
+	if label == "" {
+		label = e.Title
+	}
+	return fmt.Sprintf("tooldoc: example %q for %s: %v", label, e.ToolID, e.Err)
+}
+
+// Unwrap returns the underlying validation error.
+func (e ExampleError) Unwrap() error {
+	return e.Err
+}
+
+// PruneOrphans removes documentation for tools that can no longer be
+// resolved from the index or ToolResolver and returns the removed IDs in
+// sorted order.
+//
+// Stores with neither an index nor a resolver cannot detect orphans and
+// remove nothing.
+func (s *InMemoryStore) PruneOrphans() []string {
+	if s.index == nil && s.toolResolver == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.docs))
+	for id := range s.docs {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+
+	var orphans []string
+	for _, id := range ids {
+		if tool, _ := s.resolveTool(id); tool == nil {
+			orphans = append(orphans, id)
+		}
+	}
+
+	slices.Sort(orphans)
+	s.mu.Lock()
+	removed := orphans[:0]
+	for _, id := range orphans {
+		if record, ok := s.docs[id]; ok {
+			s.releaseExamplesLocked(record.examples)
+			delete(s.docs, id)
+			removed = append(removed, id)
+		}
+	}
+	listeners := s.snapshotListenersLocked()
+	s.mu.Unlock()
+
+	for _, id := range removed {
+		notifyListeners(listeners, ChangeEvent{Type: ChangeRemoved, ToolID: id})
+	}
+	return removed
+}
+
+// VerifyExamples validates every stored example's Args against its tool's
+// InputSchema and returns one ExampleError per failing example, ordered by
+// tool ID. Tools that cannot be resolved or have no InputSchema are skipped.
+func (s *InMemoryStore) VerifyExamples() []ExampleError {
+	s.mu.RLock()
+	byTool := make(map[string][]ToolExample, len(s.docs))
+	ids := make([]string, 0, len(s.docs))
+	for id, rec := range s.docs {
+		if len(rec.examples) == 0 {
+			continue
+		}
+		byTool[id] = copyExamples(rec.examples)
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	slices.Sort(ids)
+
+	validator := model.NewDefaultValidator()
+	var failures []ExampleError
+	for _, id := range ids {
+		tool, _ := s.resolveTool(id)
+		if tool == nil || tool.InputSchema == nil {
+			continue
+		}
+		for _, ex := range byTool[id] {
+			var args any = ex.Args
+			if ex.Args == nil {
+				args = map[string]any{}
+			}
+			if err := validator.ValidateInput(tool, args); err != nil {
+				failures = append(failures, ExampleError{
+					ToolID:    id,
+					ExampleID: ex.ID,
+					Title:     ex.Title,
+					Err:       err,
+				})
+			}
+		}
+	}
+	return failures
+}
+
+// resolveTool looks up a tool from the index, then the ToolResolver.
+func (s *InMemoryStore) resolveTool(id string) (*model.Tool, error) {
+	if s.index != nil {
+		if t, _, err := s.index.GetTool(id); err == nil {
+			return &t, nil
+		}
+	}
+	if s.toolResolver != nil {
+		return s.toolResolver(id)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
@@ -0,0 +1,76 @@
+package tooldoc
+
+import (
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func TestPruneOrphans(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	tool := makeToolWithSchema("live", "ns", "Live tool", map[string]any{"type": "object"})
+	if err := idx.RegisterTool(tool, model.NewLocalBackend("h")); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+	mustRegisterDoc(t, store, "ns:live", DocEntry{Summary: "live"})
+	mustRegisterDoc(t, store, "ns:gone", DocEntry{Summary: "gone"})
+
+	removed := store.PruneOrphans()
+	if len(removed) != 1 || removed[0] != "ns:gone" {
+		t.Fatalf("PruneOrphans = %v, want [ns:gone]", removed)
+	}
+	if _, err := store.ListExamples("ns:gone", 1); err == nil {
+		t.Error("expected orphaned doc to be removed")
+	}
+	if _, err := store.DescribeTool("ns:live", DetailSummary); err != nil {
+		t.Errorf("expected live doc to remain: %v", err)
+	}
+}
+
+func TestPruneOrphans_NotifiesRemoval(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{Index: index.NewInMemoryIndex()})
+	mustRegisterDoc(t, store, "ns:gone", DocEntry{Summary: "gone"})
+
+	var events []ChangeEvent
+	store.OnChange(func(e ChangeEvent) { events = append(events, e) })
+	store.PruneOrphans()
+
+	if len(events) != 1 || events[0].Type != ChangeRemoved || events[0].ToolID != "ns:gone" {
+		t.Fatalf("events = %+v, want one ChangeRemoved for ns:gone", events)
+	}
+}
+
+func TestPruneOrphans_NoLookup(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterDoc(t, store, "ns:any", DocEntry{Summary: "any"})
+	if removed := store.PruneOrphans(); len(removed) != 0 {
+		t.Fatalf("expected nothing pruned without index or resolver, got %v", removed)
+	}
+}
+
+func TestVerifyExamples(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	tool := makeToolWithSchema("search", "ns", "Search", map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"query": map[string]any{"type": "string"}},
+		"required":   []any{"query"},
+	})
+	_ = idx.RegisterTool(tool, model.NewLocalBackend("h"))
+
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+	mustRegisterExamples(t, store, "ns:search", []ToolExample{
+		{ID: "ok", Title: "Valid", Args: map[string]any{"query": "go"}},
+		{ID: "bad", Title: "Missing query", Args: map[string]any{}},
+	})
+
+	failures := store.VerifyExamples()
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failing example, got %d: %v", len(failures), failures)
+	}
+	if failures[0].ToolID != "ns:search" || failures[0].ExampleID != "bad" || failures[0].Err == nil {
+		t.Errorf("unexpected failure: %+v", failures[0])
+	}
+}
@@ -0,0 +1,83 @@
+package tooldoc
+
+import (
+	"fmt"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+	"github.com/jonwraymond/tooldiscovery/index"
+)
+
+// Error values for ownership lookups.
+var (
+	// ErrNoOwner is returned when neither a tool nor its namespace has an owner.
+	ErrNoOwner = errcode.New(errcode.NotFound, "no owner registered")
+
+	// ErrInvalidNamespace is returned when a namespace owner is registered
+	// without a namespace.
+	ErrInvalidNamespace = errcode.New(errcode.InvalidArgument, "namespace is required")
+)
+
+// Owner identifies the humans responsible for a tool or namespace, so agents
+// and operators can route failures.
+type Owner struct {
+	// Team is the owning team name.
+	Team string `json:"team,omitempty"`
+
+	// Contact is an email address, chat channel, or pager handle.
+	Contact string `json:"contact,omitempty"`
+
+	// EscalationURL links to a runbook or on-call escalation page.
+	EscalationURL string `json:"escalationUrl,omitempty"`
+}
+
+// IsZero reports whether no owner fields are set.
+func (o Owner) IsZero() bool {
+	return o == Owner{}
+}
+
+// RegisterNamespaceOwner sets the default owner for every tool in namespace.
+// Tool-level owners from DocEntry.Owner take precedence. A zero Owner removes
+// the namespace owner.
+func (s *InMemoryStore) RegisterNamespaceOwner(namespace string, owner Owner) error {
+	if namespace == "" {
+		return ErrInvalidNamespace
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if owner.IsZero() {
+		delete(s.owners, namespace)
+		return nil
+	}
+	s.owners[namespace] = owner
+	return nil
+}
+
+// WhoOwns returns the owner of a tool: the tool-level owner when registered,
+// otherwise the owner of the tool's namespace.
+//
+// Returns ErrNoOwner if neither is registered.
+func (s *InMemoryStore) WhoOwns(id string) (Owner, error) {
+	s.mu.RLock()
+	owner := s.ownerLocked(id)
+	s.mu.RUnlock()
+	if owner == nil {
+		return Owner{}, fmt.Errorf("%w: %s", ErrNoOwner, id)
+	}
+	return *owner, nil
+}
+
+// ownerLocked resolves the owner for a tool ID. Caller must hold s.mu.
+func (s *InMemoryStore) ownerLocked(id string) *Owner {
+	if rec := s.docs[id]; rec != nil && rec.owner != nil && !rec.owner.IsZero() {
+		owner := *rec.owner
+		return &owner
+	}
+	toolID, err := index.ParseToolID(id)
+	if err != nil || toolID.Namespace == "" {
+		return nil
+	}
+	if owner, ok := s.owners[toolID.Namespace]; ok {
+		return &owner
+	}
+	return nil
+}
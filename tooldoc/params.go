@@ -0,0 +1,232 @@
+package tooldoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxParameterDepth bounds nested object flattening in ParameterTable.
+const maxParameterDepth = 8
+
+// Parameter is one row of a tool's parameter table, derived from its input
+// schema.
+type Parameter struct {
+	// Name is the dotted path of the parameter. Nested object properties
+	// are joined with ".", and properties of array items use "[]", as in
+	// "options.timeout" or "labels[].name".
+	Name string `json:"name"`
+
+	// Type is the JSON Schema type. Unions are joined with "|" and arrays of
+	// primitives include their item type, as in "array<string>".
+	Type string `json:"type,omitempty"`
+
+	// Required reports whether the parameter is required within its parent
+	// object.
+	Required bool `json:"required"`
+
+	// Default is the schema default, if any.
+	Default any `json:"default,omitempty"`
+
+	// Description is the schema description.
+	Description string `json:"description,omitempty"`
+
+	// Enum lists the allowed values, if the schema or, for arrays, its
+	// items declare an enum, so argument forms can offer a choice.
+	Enum []any `json:"enum,omitempty"`
+
+	// Constraints lists validation keywords as "keyword: value", as in
+	// "enum: \"open\", \"closed\"" or "minimum: 1".
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// constraintKeywords are reported in Parameter.Constraints, in this order.
+var constraintKeywords = []string{
+	"enum", "const", "format", "pattern",
+	"minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "multipleOf",
+	"minLength", "maxLength", "minItems", "maxItems", "uniqueItems",
+}
+
+// ParameterTable flattens an input schema into a parameter table. Properties
+// are sorted by name at each level, with nested properties following their
+// parent. It returns nil if the schema has no properties or cannot be read.
+func ParameterTable(schema any) []Parameter {
+	schemaMap, ok := schemaAsMap(schema)
+	if !ok {
+		return nil
+	}
+	var params []Parameter
+	walkProperties(schemaMap, func(path string, _ int, prop map[string]any, required bool) {
+		param := Parameter{
+			Name:        path,
+			Type:        schemaType(prop),
+			Required:    required,
+			Enum:        schemaEnum(prop),
+			Constraints: schemaConstraints(prop),
+		}
+		if def, ok := prop["default"]; ok {
+			param.Default = normalizeNumeric(def)
+		}
+		param.Description, _ = prop["description"].(string)
+		params = append(params, param)
+	})
+	return params
+}
+
+// walkProperties calls visit for every property of schema, depth-first with
+// properties sorted by name at each level. Nested object properties are
+// visited with dotted paths and properties of array items with "[]", as in
+// "options.timeout" or "labels[].name". depth is 0 for top-level properties;
+// required reports whether the property is required within its parent.
+func walkProperties(schema map[string]any, visit func(path string, depth int, prop map[string]any, required bool)) {
+	walkPropertiesAt(schema, "", 0, visit)
+}
+
+func walkPropertiesAt(schema map[string]any, prefix string, depth int, visit func(string, int, map[string]any, bool)) {
+	if depth >= maxParameterDepth {
+		return
+	}
+	props, _ := schema["properties"].(map[string]any)
+	required := toStringSlice(schema["required"])
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		prop, ok := props[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		path := prefix + name
+		visit(path, depth, prop, slices.Contains(required, name))
+
+		walkPropertiesAt(prop, path+".", depth+1, visit)
+		if items, ok := prop["items"].(map[string]any); ok {
+			walkPropertiesAt(items, path+"[].", depth+1, visit)
+		}
+	}
+}
+
+// schemaEnum returns the enum values of a property schema, normalized like
+// defaults. Enums on array items describe the array's elements.
+func schemaEnum(prop map[string]any) []any {
+	values, ok := prop["enum"].([]any)
+	if !ok {
+		if items, isMap := prop["items"].(map[string]any); isMap {
+			values, ok = items["enum"].([]any)
+		}
+	}
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = normalizeNumeric(v)
+	}
+	return out
+}
+
+// schemaType describes the type of a property schema.
+func schemaType(prop map[string]any) string {
+	var types []string
+	switch t := prop["type"].(type) {
+	case string:
+		types = []string{t}
+	default:
+		types = toStringSlice(t)
+	}
+	if len(types) == 0 {
+		for _, key := range []string{"anyOf", "oneOf"} {
+			variants, _ := prop[key].([]any)
+			for _, v := range variants {
+				if vm, ok := v.(map[string]any); ok {
+					if vt := schemaType(vm); vt != "" && !slices.Contains(types, vt) {
+						types = append(types, vt)
+					}
+				}
+			}
+		}
+	}
+	for i, t := range types {
+		if t != "array" {
+			continue
+		}
+		if items, ok := prop["items"].(map[string]any); ok {
+			if it := schemaType(items); it != "" && it != "object" {
+				types[i] = "array<" + it + ">"
+			}
+		}
+	}
+	return strings.Join(types, "|")
+}
+
+// schemaConstraints formats the validation keywords of a property schema.
+func schemaConstraints(prop map[string]any) []string {
+	var constraints []string
+	for _, keyword := range constraintKeywords {
+		value, ok := prop[keyword]
+		if !ok {
+			continue
+		}
+		var formatted string
+		if values, ok := value.([]any); ok && keyword == "enum" {
+			parts := make([]string, len(values))
+			for i, v := range values {
+				parts[i] = formatSchemaValue(v)
+			}
+			formatted = strings.Join(parts, ", ")
+		} else {
+			formatted = formatSchemaValue(value)
+		}
+		constraints = append(constraints, keyword+": "+formatted)
+	}
+	return constraints
+}
+
+func formatSchemaValue(v any) string {
+	data, err := json.Marshal(normalizeNumeric(v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// RenderParameterTable renders params as a Markdown table with name, type,
+// required, default, description, and constraints columns. It returns an
+// empty string when params is empty.
+func RenderParameterTable(params []Parameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("| Name | Type | Required | Default | Description | Constraints |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, p := range params {
+		required := "no"
+		if p.Required {
+			required = "yes"
+		}
+		def := ""
+		if p.Default != nil {
+			def = formatSchemaValue(p.Default)
+		}
+		cells := []string{
+			"`" + p.Name + "`", p.Type, required, def, p.Description, strings.Join(p.Constraints, "; "),
+		}
+		for i, cell := range cells {
+			cells[i] = markdownCell(cell)
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return b.String()
+}
+
+// markdownCell escapes pipes and flattens newlines in a table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,125 @@
+package tooldoc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+var paramsTestSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"repo", "title"},
+	"properties": map[string]any{
+		"title": map[string]any{"type": "string", "description": "Issue title", "maxLength": 256},
+		"repo":  map[string]any{"type": "string", "pattern": "^[^/]+/[^/]+$"},
+		"state": map[string]any{"type": "string", "enum": []any{"open", "closed"}, "default": "open"},
+		"options": map[string]any{
+			"type":     "object",
+			"required": []any{"timeout"},
+			"properties": map[string]any{
+				"timeout": map[string]any{"type": "integer", "minimum": 1, "default": 30},
+			},
+		},
+		"labels": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+			},
+		},
+		"assignees": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 10},
+		"milestone": map[string]any{"anyOf": []any{map[string]any{"type": "integer"}, map[string]any{"type": "null"}}},
+	},
+}
+
+func TestParameterTable(t *testing.T) {
+	got := ParameterTable(paramsTestSchema)
+	want := []Parameter{
+		{Name: "assignees", Type: "array<string>", Constraints: []string{"maxItems: 10"}},
+		{Name: "labels", Type: "array"},
+		{Name: "labels[].name", Type: "string"},
+		{Name: "milestone", Type: "integer|null"},
+		{Name: "options", Type: "object"},
+		{Name: "options.timeout", Type: "integer", Required: true, Default: float64(30), Constraints: []string{"minimum: 1"}},
+		{Name: "repo", Type: "string", Required: true, Constraints: []string{`pattern: "^[^/]+/[^/]+$"`}},
+		{Name: "state", Type: "string", Default: "open", Enum: []any{"open", "closed"}, Constraints: []string{`enum: "open", "closed"`}},
+		{Name: "title", Type: "string", Required: true, Description: "Issue title", Constraints: []string{"maxLength: 256"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParameterTable =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if params := ParameterTable(`{"type":"object"}`); params != nil {
+		t.Errorf("schema without properties = %+v, want nil", params)
+	}
+	if params := ParameterTable(nil); params != nil {
+		t.Errorf("nil schema = %+v, want nil", params)
+	}
+	raw := []byte(`{"type":"object","properties":{"q":{"type":"string"}}}`)
+	if params := ParameterTable(raw); len(params) != 1 || params[0].Name != "q" {
+		t.Errorf("raw JSON schema = %+v, want q", params)
+	}
+}
+
+func TestRenderParameterTable(t *testing.T) {
+	out := RenderParameterTable([]Parameter{
+		{Name: "state", Type: "string", Default: "open", Description: "Issue state\n(open|closed)", Constraints: []string{`enum: "open", "closed"`}},
+		{Name: "title", Type: "string", Required: true},
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("rendered %d lines, want 4:\n%s", len(lines), out)
+	}
+	if want := "| `state` | string | no | \"open\" | Issue state (open\\|closed) | enum: \"open\", \"closed\" |"; lines[2] != want {
+		t.Errorf("row = %s\nwant  %s", lines[2], want)
+	}
+	if want := "| `title` | string | yes |  |  |  |"; lines[3] != want {
+		t.Errorf("row = %s\nwant  %s", lines[3], want)
+	}
+	if RenderParameterTable(nil) != "" {
+		t.Error("empty table should render as empty string")
+	}
+}
+
+func TestDeriveSchemaInfo_Nested(t *testing.T) {
+	info := deriveSchemaInfo(paramsTestSchema)
+	if info == nil {
+		t.Fatal("expected non-nil SchemaInfo")
+	}
+	if want := []string{"repo", "title", "options.timeout"}; !reflect.DeepEqual(info.Required, want) {
+		t.Errorf("Required = %v, want %v", info.Required, want)
+	}
+	if got := info.Types["labels[].name"]; !reflect.DeepEqual(got, []string{"string"}) {
+		t.Errorf("Types[labels[].name] = %v", got)
+	}
+	if got := info.Defaults["options.timeout"]; got != 30.0 {
+		t.Errorf("Defaults[options.timeout] = %v, want 30", got)
+	}
+	if got := info.Enums["state"]; !reflect.DeepEqual(got, []any{"open", "closed"}) {
+		t.Errorf("Enums[state] = %v", got)
+	}
+	if got := info.Descriptions["title"]; got != "Issue title" {
+		t.Errorf("Descriptions[title] = %q", got)
+	}
+}
+
+func TestDescribeTool_Parameters(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	_ = idx.RegisterTool(makeToolWithSchema("create_issue", "github", "Create", paramsTestSchema), model.NewLocalBackend("h"))
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+
+	doc, err := store.DescribeTool("github:create_issue", DetailSchema)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	if len(doc.Parameters) != 9 || doc.Parameters[0].Name != "assignees" {
+		t.Fatalf("Parameters = %+v, want 9 rows", doc.Parameters)
+	}
+	summary, _ := store.DescribeTool("github:create_issue", DetailSummary)
+	if summary.Parameters != nil {
+		t.Errorf("summary level should omit parameters, got %+v", summary.Parameters)
+	}
+}
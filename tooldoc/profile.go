@@ -0,0 +1,173 @@
+package tooldoc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jonwraymond/tooldiscovery/errcode"
+)
+
+// ErrInvalidProfile is returned when a truncation profile name is unknown.
+var ErrInvalidProfile = errcode.New(errcode.InvalidArgument, "invalid truncation profile")
+
+// Truncation profile names accepted by LookupProfile.
+const (
+	ProfileCompact  = "compact"
+	ProfileStandard = "standard"
+	ProfileVerbose  = "verbose"
+)
+
+// TruncationProfile trims a ToolDoc to suit a context budget. Length and
+// count fields keep everything when zero and remove the content entirely
+// when negative.
+type TruncationProfile struct {
+	// Name identifies the profile.
+	Name string
+
+	// NotesLen caps the length of Notes.
+	NotesLen int
+
+	// Examples caps the number of examples, keeping the highest-priority
+	// ones.
+	Examples int
+
+	// ExampleTextLen caps each example's Description and ResultHint.
+	ExampleTextLen int
+
+	// ParameterDescriptionLen caps parameter descriptions in Parameters and
+	// SchemaInfo.Descriptions.
+	ParameterDescriptionLen int
+
+	// OmitOutputSchema removes Tool.OutputSchema.
+	OmitOutputSchema bool
+
+	// OmitReferences removes ExternalRefs and SeeAlso.
+	OmitReferences bool
+}
+
+var profiles = map[string]TruncationProfile{
+	ProfileCompact: {
+		Name:                    ProfileCompact,
+		NotesLen:                200,
+		Examples:                1,
+		ExampleTextLen:          80,
+		ParameterDescriptionLen: 80,
+		OmitOutputSchema:        true,
+		OmitReferences:          true,
+	},
+	ProfileStandard: {
+		Name:                    ProfileStandard,
+		NotesLen:                800,
+		Examples:                3,
+		ExampleTextLen:          200,
+		ParameterDescriptionLen: 200,
+	},
+	ProfileVerbose: {
+		Name: ProfileVerbose,
+	},
+}
+
+// LookupProfile returns the built-in profile with the given name:
+//
+//   - compact: short notes, one example, no output schema or references
+//   - standard: moderate notes and up to three examples
+//   - verbose: no trimming
+//
+// An empty name returns the verbose profile. Unknown names return
+// ErrInvalidProfile.
+func LookupProfile(name string) (TruncationProfile, error) {
+	if name == "" {
+		name = ProfileVerbose
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return TruncationProfile{}, fmt.Errorf("%w: %s", ErrInvalidProfile, name)
+	}
+	return p, nil
+}
+
+// ProfileNames returns the built-in profile names in sorted order.
+func ProfileNames() []string {
+	return slices.Sorted(maps.Keys(profiles))
+}
+
+// Apply returns doc trimmed by the profile. doc is not modified; the
+// returned ToolDoc copies every field it trims.
+func (p TruncationProfile) Apply(doc ToolDoc) ToolDoc {
+	doc.Notes = capString(doc.Notes, p.NotesLen)
+
+	if p.Examples < 0 {
+		doc.Examples = nil
+	} else if p.Examples > 0 && len(doc.Examples) > p.Examples {
+		doc.Examples = doc.Examples[:p.Examples]
+	}
+	if p.ExampleTextLen != 0 && len(doc.Examples) > 0 {
+		examples := slices.Clone(doc.Examples)
+		for i := range examples {
+			examples[i].Description = capString(examples[i].Description, p.ExampleTextLen)
+			examples[i].ResultHint = capString(examples[i].ResultHint, p.ExampleTextLen)
+		}
+		doc.Examples = examples
+	}
+
+	if p.ParameterDescriptionLen != 0 {
+		if len(doc.Parameters) > 0 {
+			params := slices.Clone(doc.Parameters)
+			for i := range params {
+				params[i].Description = capString(params[i].Description, p.ParameterDescriptionLen)
+			}
+			doc.Parameters = params
+		}
+		if doc.SchemaInfo != nil && len(doc.SchemaInfo.Descriptions) > 0 {
+			info := *doc.SchemaInfo
+			info.Descriptions = make(map[string]string, len(doc.SchemaInfo.Descriptions))
+			for path, desc := range doc.SchemaInfo.Descriptions {
+				if desc = capString(desc, p.ParameterDescriptionLen); desc != "" {
+					info.Descriptions[path] = desc
+				}
+			}
+			doc.SchemaInfo = &info
+		}
+	}
+
+	if p.OmitOutputSchema && doc.Tool != nil && doc.Tool.OutputSchema != nil {
+		tool := *doc.Tool
+		tool.OutputSchema = nil
+		doc.Tool = &tool
+	}
+	if p.OmitReferences {
+		doc.ExternalRefs = nil
+		doc.SeeAlso = nil
+	}
+	return doc
+}
+
+// capString truncates s to n bytes, keeps it when n is zero, and clears it
+// when n is negative.
+func capString(s string, n int) string {
+	switch {
+	case n < 0:
+		return ""
+	case n == 0:
+		return s
+	default:
+		return truncateString(s, n)
+	}
+}
+
+// DescribeWithProfile describes a tool with s at the given detail level and
+// trims the result with the named profile (see LookupProfile), so callers
+// with different context budgets share one set of docs. It returns
+// ErrInvalidProfile before querying s when profile is unknown.
+func DescribeWithProfile(s Store, id string, level DetailLevel, profile string) (ToolDoc, error) {
+	p, err := LookupProfile(profile)
+	if err != nil {
+		return ToolDoc{}, err
+	}
+	doc, err := s.DescribeTool(id, level)
+	if err != nil {
+		return ToolDoc{}, err
+	}
+	return p.Apply(doc), nil
+}
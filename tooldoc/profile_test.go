@@ -0,0 +1,115 @@
+package tooldoc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jonwraymond/tooldiscovery/index"
+	"github.com/jonwraymond/toolfoundation/model"
+)
+
+func profileStore(t *testing.T) *InMemoryStore {
+	t.Helper()
+	idx := index.NewInMemoryIndex()
+	tool := makeToolWithSchema("create", "tickets", "Create a ticket", map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"title": map[string]any{"type": "string", "description": strings.Repeat("t", 150)},
+		},
+	})
+	tool.OutputSchema = map[string]any{"type": "object"}
+	backend := model.ToolBackend{Kind: model.BackendKindLocal, Local: &model.LocalBackend{Name: "handler"}}
+	if err := idx.RegisterTool(tool, backend); err != nil {
+		t.Fatalf("failed to register tool: %v", err)
+	}
+
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+	examples := make([]ToolExample, 4)
+	for i := range examples {
+		examples[i] = ToolExample{Title: "example", Description: strings.Repeat("d", 250), Args: map[string]any{"title": "x"}}
+	}
+	mustRegisterDoc(t, store, "tickets:create", DocEntry{
+		Notes:        strings.Repeat("n", 1000),
+		ExternalRefs: []string{"https://docs.example.com/tickets"},
+		Examples:     examples,
+	})
+	return store
+}
+
+func TestDescribeWithProfile(t *testing.T) {
+	store := profileStore(t)
+
+	tests := []struct {
+		profile     string
+		notes       int
+		examples    int
+		exampleText int
+		paramDesc   int
+		output      bool
+		refs        int
+	}{
+		{ProfileCompact, 200, 1, 80, 80, false, 0},
+		{ProfileStandard, 800, 3, 200, 150, true, 1},
+		{ProfileVerbose, 1000, 4, 250, 150, true, 1},
+		{"", 1000, 4, 250, 150, true, 1},
+	}
+	for _, tt := range tests {
+		doc, err := DescribeWithProfile(store, "tickets:create", DetailFull, tt.profile)
+		if err != nil {
+			t.Fatalf("%q: DescribeWithProfile failed: %v", tt.profile, err)
+		}
+		if len(doc.Notes) != tt.notes {
+			t.Errorf("%q: len(Notes) = %d, want %d", tt.profile, len(doc.Notes), tt.notes)
+		}
+		if len(doc.Examples) != tt.examples {
+			t.Fatalf("%q: len(Examples) = %d, want %d", tt.profile, len(doc.Examples), tt.examples)
+		}
+		if got := len(doc.Examples[0].Description); got != tt.exampleText {
+			t.Errorf("%q: example description len = %d, want %d", tt.profile, got, tt.exampleText)
+		}
+		if got := len(doc.Parameters[0].Description); got != tt.paramDesc {
+			t.Errorf("%q: parameter description len = %d, want %d", tt.profile, got, tt.paramDesc)
+		}
+		if got := len(doc.SchemaInfo.Descriptions["title"]); got != tt.paramDesc {
+			t.Errorf("%q: schema description len = %d, want %d", tt.profile, got, tt.paramDesc)
+		}
+		if got := doc.Tool.OutputSchema != nil; got != tt.output {
+			t.Errorf("%q: has output schema = %v, want %v", tt.profile, got, tt.output)
+		}
+		if len(doc.ExternalRefs) != tt.refs {
+			t.Errorf("%q: len(ExternalRefs) = %d, want %d", tt.profile, len(doc.ExternalRefs), tt.refs)
+		}
+	}
+
+	if _, err := DescribeWithProfile(store, "tickets:create", DetailFull, "tiny"); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("unknown profile error = %v, want ErrInvalidProfile", err)
+	}
+}
+
+func TestTruncationProfile_ApplyDoesNotModifyInput(t *testing.T) {
+	doc, err := profileStore(t).DescribeTool("tickets:create", DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	p := TruncationProfile{NotesLen: -1, Examples: 2, ExampleTextLen: 10, ParameterDescriptionLen: -1, OmitOutputSchema: true}
+	trimmed := p.Apply(doc)
+
+	if trimmed.Notes != "" || len(trimmed.Examples) != 2 || trimmed.Parameters[0].Description != "" {
+		t.Errorf("trimmed = %+v", trimmed)
+	}
+	if _, ok := trimmed.SchemaInfo.Descriptions["title"]; ok {
+		t.Error("removed schema description should be absent")
+	}
+	if len(doc.Examples[0].Description) != 250 || doc.Parameters[0].Description == "" ||
+		doc.SchemaInfo.Descriptions["title"] == "" || doc.Tool.OutputSchema == nil {
+		t.Error("Apply modified its input")
+	}
+}
+
+func TestProfileNames(t *testing.T) {
+	got := strings.Join(ProfileNames(), ",")
+	if want := "compact,standard,verbose"; got != want {
+		t.Errorf("ProfileNames() = %s, want %s", got, want)
+	}
+}
@@ -2,10 +2,11 @@ package tooldoc
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
+	"github.com/jonwraymond/tooldiscovery/errcode"
 	"github.com/jonwraymond/tooldiscovery/index"
 	"github.com/jonwraymond/toolfoundation/model"
 )
@@ -14,20 +15,28 @@ import (
 var (
 	// ErrNotFound is returned when a tool ID is not found in either the
 	// index or the documentation store.
-	ErrNotFound = errors.New("tool not found")
+	ErrNotFound = errcode.New(errcode.ToolNotFound, "tool not found")
 
 	// ErrInvalidDetail is returned when an invalid DetailLevel is provided.
-	ErrInvalidDetail = errors.New("invalid detail level")
+	ErrInvalidDetail = errcode.New(errcode.InvalidArgument, "invalid detail level")
 
 	// ErrNoTool is returned when schema/full detail is requested but the
 	// Tool object is not available from the index or ToolResolver. This can
 	// happen when documentation exists but the tool hasn't been registered
 	// with index and no resolver is configured.
-	ErrNoTool = errors.New("tool required for schema/full level")
+	ErrNoTool = errcode.New(errcode.ToolNotFound, "tool required for schema/full level")
 
 	// ErrArgsTooLarge is returned when an example's Args exceeds depth or size caps.
 	// The error message includes which example and what limits were exceeded.
-	ErrArgsTooLarge = errors.New("args exceeds caps")
+	ErrArgsTooLarge = errcode.New(errcode.LimitExceeded, "args exceeds caps")
+
+	// ErrExampleNotFound is returned when an example ID is not found in a
+	// tool's documentation.
+	ErrExampleNotFound = errcode.New(errcode.NotFound, "example not found")
+
+	// ErrInvalidReference is returned when a SeeAlso reference is empty,
+	// points at the documented tool itself, or names an unknown tool.
+	ErrInvalidReference = errcode.New(errcode.InvalidArgument, "invalid see-also reference")
 )
 
 // Store defines the interface for tool documentation storage.
@@ -72,6 +81,19 @@ type StoreOptions struct {
 	// MaxExamples is the default maximum number of examples to return.
 	// Zero means no limit (use ListExamples max parameter).
 	MaxExamples int
+
+	// MaxArgsDepth and MaxArgsKeys override the example Args caps for this
+	// store. Zero or negative values use the package defaults (MaxArgsDepth,
+	// MaxArgsKeys); larger values are clamped to ArgsDepthLimit and
+	// ArgsKeysLimit.
+	MaxArgsDepth int
+	MaxArgsKeys  int
+
+	// MaxDocBytes caps the size of a tool's documentation: the combined
+	// length of its text fields plus the JSON encoding of example Args.
+	// Writes that would exceed it fail with an *index.LimitError and leave
+	// the documentation unchanged. 0 = unlimited.
+	MaxDocBytes int
 }
 
 // docRecord holds registered documentation for a tool.
@@ -80,6 +102,8 @@ type docRecord struct {
 	notes        string
 	examples     []ToolExample
 	externalRefs []string
+	seeAlso      []string
+	owner        *Owner
 }
 
 // InMemoryStore is an in-memory implementation of Store.
@@ -89,6 +113,15 @@ type InMemoryStore struct {
 	toolResolver func(id string) (*model.Tool, error)
 	docs         map[string]*docRecord
 	maxExamples  int
+	maxArgsDepth int
+	maxArgsKeys  int
+	owners       map[string]Owner      // namespace owners
+	argsPool     map[string]*argsEntry // shared example Args by content hash
+	maxDocBytes  int
+	rejections   map[string]uint64 // writes rejected per limit
+
+	listeners      []listenerEntry
+	nextListenerID uint64
 }
 
 // NewInMemoryStore creates a new in-memory documentation store.
@@ -98,7 +131,20 @@ func NewInMemoryStore(opts StoreOptions) *InMemoryStore {
 		toolResolver: opts.ToolResolver,
 		docs:         make(map[string]*docRecord),
 		maxExamples:  opts.MaxExamples,
+		maxArgsDepth: argsCap(opts.MaxArgsDepth, MaxArgsDepth, ArgsDepthLimit),
+		maxArgsKeys:  argsCap(opts.MaxArgsKeys, MaxArgsKeys, ArgsKeysLimit),
+		owners:       make(map[string]Owner),
+		argsPool:     make(map[string]*argsEntry),
+		maxDocBytes:  opts.MaxDocBytes,
+	}
+}
+
+// argsCap resolves a configured Args cap against its default and hard limit.
+func argsCap(configured, def, limit int) int {
+	if configured <= 0 {
+		return def
 	}
+	return min(configured, limit)
 }
 
 // RegisterDoc registers documentation for a tool.
@@ -106,98 +152,285 @@ func NewInMemoryStore(opts StoreOptions) *InMemoryStore {
 // If the tool has no existing doc record, one is created.
 // Args in examples are deep-copied to prevent external mutation.
 //
-// Returns ErrArgsTooLarge if any example's Args exceeds MaxArgsDepth or MaxArgsKeys.
+// Returns ErrArgsTooLarge if any example's Args exceeds the store's Args caps
+// and ErrInvalidReference if a SeeAlso reference does not resolve.
 func (s *InMemoryStore) RegisterDoc(id string, entry DocEntry) error {
 	entry = entry.ValidateAndTruncate()
 
 	// Deep copy examples with their Args and validate caps
 	examples := make([]ToolExample, len(entry.Examples))
 	for i, ex := range entry.Examples {
-		// Deep copy first (normalizes types to map[string]any)
-		argsCopy := deepCopyArgs(ex.Args)
-
-		// Validate caps on normalized copy
-		stats, valid := ValidateArgs(argsCopy)
-		if !valid {
-			return fmt.Errorf("%w: example %d (%s) has depth=%d (max %d), keys=%d (max %d)",
-				ErrArgsTooLarge, i, ex.Title, stats.Depth, MaxArgsDepth, stats.Keys, MaxArgsKeys)
-		}
-
-		examples[i] = ToolExample{
-			ID:          ex.ID,
-			Title:       ex.Title,
-			Description: ex.Description,
-			Args:        argsCopy,
-			ResultHint:  ex.ResultHint,
+		prepared, err := s.prepareExample(i, ex)
+		if err != nil {
+			return err
 		}
+		examples[i] = prepared
 	}
+	sortExamples(examples)
 
 	// Copy external refs
 	externalRefs := make([]string, len(entry.ExternalRefs))
 	copy(externalRefs, entry.ExternalRefs)
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	record, exists := s.docs[id]
-	if !exists {
-		record = &docRecord{}
-		s.docs[id] = record
+	seeAlso, err := s.validateSeeAlso(id, entry.SeeAlso)
+	if err != nil {
+		return err
 	}
 
-	record.summary = entry.Summary
-	record.notes = entry.Notes
-	record.examples = examples
-	record.externalRefs = externalRefs
-
-	return nil
+	return s.mutate(id, true, func(record *docRecord) error {
+		record.summary = entry.Summary
+		record.notes = entry.Notes
+		record.examples = examples
+		record.externalRefs = externalRefs
+		record.seeAlso = seeAlso
+		record.owner = entry.Owner
+		return nil
+	})
 }
 
 // RegisterExamples adds or replaces examples for a tool.
 // Examples are validated and truncated to fit within caps.
 // Args are deep-copied to prevent external mutation.
 //
-// Returns ErrArgsTooLarge if any example's Args exceeds MaxArgsDepth or MaxArgsKeys.
+// Returns ErrArgsTooLarge if any example's Args exceeds the store's Args caps.
 func (s *InMemoryStore) RegisterExamples(id string, examples []ToolExample) error {
-	limit := len(examples)
+	ordered := make([]ToolExample, len(examples))
+	copy(ordered, examples)
+	sortExamples(ordered)
+
+	limit := len(ordered)
 	if s.maxExamples > 0 && limit > s.maxExamples {
 		limit = s.maxExamples
 	}
 
 	truncated := make([]ToolExample, limit)
 	for i := 0; i < limit; i++ {
-		ex := examples[i]
-		// Deep copy first (normalizes types to map[string]any)
-		argsCopy := deepCopyArgs(ex.Args)
+		ex, err := s.prepareExample(i, ordered[i])
+		if err != nil {
+			return err
+		}
+		truncated[i] = ex
+	}
 
-		// Validate caps on normalized copy
-		stats, valid := ValidateArgs(argsCopy)
-		if !valid {
-			return fmt.Errorf("%w: example %d (%s) has depth=%d (max %d), keys=%d (max %d)",
-				ErrArgsTooLarge, i, ex.Title, stats.Depth, MaxArgsDepth, stats.Keys, MaxArgsKeys)
+	return s.mutate(id, true, func(record *docRecord) error {
+		record.examples = truncated
+		return nil
+	})
+}
+
+// PatchDoc applies a partial update to a tool's documentation in a single
+// critical section, creating the record if needed. Concurrent patches touching
+// different fields never overwrite each other. Fields are truncated and
+// appended examples are copied and validated like RegisterDoc, and with
+// StoreOptions.MaxExamples set the lowest-priority examples beyond the cap
+// are dropped.
+//
+// Returns ErrArgsTooLarge if any appended example's Args exceeds the caps;
+// the documentation is left unchanged in that case.
+func (s *InMemoryStore) PatchDoc(id string, patch DocPatch) error {
+	appended := make([]ToolExample, len(patch.AppendExamples))
+	for i, ex := range patch.AppendExamples {
+		prepared, err := s.prepareExample(i, ex)
+		if err != nil {
+			return err
 		}
+		appended[i] = prepared
+	}
+	seeAlso, err := s.validateSeeAlso(id, patch.AddSeeAlso)
+	if err != nil {
+		return err
+	}
 
-		truncated[i] = ToolExample{
-			ID:          ex.ID,
-			Title:       ex.Title,
-			Description: truncateString(ex.Description, MaxDescriptionLen),
-			Args:        argsCopy,
-			ResultHint:  truncateString(ex.ResultHint, MaxResultHintLen),
+	return s.mutate(id, true, func(record *docRecord) error {
+		if patch.Summary != nil {
+			record.summary = truncateString(*patch.Summary, MaxSummaryLen)
+		}
+		if patch.Notes != nil {
+			record.notes = truncateString(*patch.Notes, MaxNotesLen)
+		}
+		if len(appended) > 0 {
+			examples := append(slices.Clone(record.examples), appended...)
+			sortExamples(examples)
+			if s.maxExamples > 0 && len(examples) > s.maxExamples {
+				examples = examples[:s.maxExamples]
+			}
+			record.examples = examples
+		}
+		for _, ref := range patch.AddExternalRefs {
+			if !slices.Contains(record.externalRefs, ref) {
+				record.externalRefs = append(slices.Clip(record.externalRefs), ref)
+			}
+		}
+		for _, ref := range seeAlso {
+			if !slices.Contains(record.seeAlso, ref) {
+				record.seeAlso = append(slices.Clip(record.seeAlso), ref)
+			}
 		}
+		if patch.Owner != nil {
+			owner := *patch.Owner
+			record.owner = &owner
+		}
+		return nil
+	})
+}
+
+// validateSeeAlso checks SeeAlso references for id and returns them without
+// duplicates. A reference is valid if it resolves via the index, the
+// ToolResolver, or registered documentation.
+func (s *InMemoryStore) validateSeeAlso(id string, refs []string) ([]string, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref == "" || ref == id {
+			return nil, fmt.Errorf("%w: %q from %s", ErrInvalidReference, ref, id)
+		}
+		if slices.Contains(out, ref) {
+			continue
+		}
+		if !s.toolExists(ref) {
+			return nil, fmt.Errorf("%w: %s references unknown tool %s", ErrInvalidReference, id, ref)
+		}
+		out = append(out, ref)
 	}
+	return out, nil
+}
 
+// toolExists reports whether id names an indexed, resolvable, or documented
+// tool.
+func (s *InMemoryStore) toolExists(id string) bool {
+	if t, err := s.resolveTool(id); err == nil && t != nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.docs[id]
+	return ok
+}
+
+// RemoveDoc deletes all documentation registered for a tool, including
+// examples and owner. The tool itself is unaffected.
+//
+// Returns ErrNotFound if no documentation is registered for id.
+func (s *InMemoryStore) RemoveDoc(id string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	record, ok := s.docs[id]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	s.releaseExamplesLocked(record.examples)
+	delete(s.docs, id)
+	listeners := s.snapshotListenersLocked()
+	s.mu.Unlock()
 
-	record, exists := s.docs[id]
-	if !exists {
-		record = &docRecord{}
-		s.docs[id] = record
+	notifyListeners(listeners, ChangeEvent{Type: ChangeRemoved, ToolID: id})
+	return nil
+}
+
+// RemoveExamples deletes the examples with the given IDs from a tool's
+// documentation. Unknown example IDs are ignored; examples without an ID
+// cannot be removed individually.
+//
+// Returns ErrNotFound if no documentation is registered for id.
+func (s *InMemoryStore) RemoveExamples(id string, exampleIDs []string) error {
+	remove := make(map[string]struct{}, len(exampleIDs))
+	for _, exampleID := range exampleIDs {
+		if exampleID != "" {
+			remove[exampleID] = struct{}{}
+		}
 	}
 
-	record.examples = truncated
+	return s.mutate(id, false, func(record *docRecord) error {
+		kept := make([]ToolExample, 0, len(record.examples))
+		for _, ex := range record.examples {
+			if _, drop := remove[ex.ID]; !drop || ex.ID == "" {
+				kept = append(kept, ex)
+			}
+		}
+		record.examples = kept
+		return nil
+	})
+}
+
+// UpdateExample replaces the example with exampleID, keeping its ID stable
+// regardless of example.ID. The example is validated and truncated like
+// RegisterExamples, and examples are re-ordered if its Priority changed.
+//
+// Returns ErrNotFound if no documentation is registered for id,
+// ErrExampleNotFound if the example does not exist, and ErrArgsTooLarge if
+// Args exceed the caps.
+func (s *InMemoryStore) UpdateExample(id, exampleID string, example ToolExample) error {
+	if exampleID == "" {
+		return fmt.Errorf("%w: example id is required", ErrExampleNotFound)
+	}
+	example.ID = exampleID
+
+	return s.mutate(id, false, func(record *docRecord) error {
+		pos := slices.IndexFunc(record.examples, func(ex ToolExample) bool { return ex.ID == exampleID })
+		if pos < 0 {
+			return fmt.Errorf("%w: %s/%s", ErrExampleNotFound, id, exampleID)
+		}
+		prepared, err := s.prepareExample(pos, example)
+		if err != nil {
+			return err
+		}
+		examples := slices.Clone(record.examples)
+		examples[pos] = prepared
+		sortExamples(examples)
+		record.examples = examples
+		return nil
+	})
+}
 
-	return nil
+// DeleteExample removes the example with exampleID from a tool's
+// documentation.
+//
+// Returns ErrNotFound if no documentation is registered for id and
+// ErrExampleNotFound if the example does not exist.
+func (s *InMemoryStore) DeleteExample(id, exampleID string) error {
+	return s.mutate(id, false, func(record *docRecord) error {
+		pos := slices.IndexFunc(record.examples, func(ex ToolExample) bool {
+			return exampleID != "" && ex.ID == exampleID
+		})
+		if pos < 0 {
+			return fmt.Errorf("%w: %s/%s", ErrExampleNotFound, id, exampleID)
+		}
+		record.examples = slices.Delete(slices.Clone(record.examples), pos, pos+1)
+		return nil
+	})
+}
+
+// prepareExample deep-copies, validates, and truncates an example for
+// storage. i identifies the example in error messages.
+func (s *InMemoryStore) prepareExample(i int, ex ToolExample) (ToolExample, error) {
+	// Deep copy first (normalizes types to map[string]any)
+	argsCopy := deepCopyArgs(ex.Args)
+
+	// Validate caps on normalized copy
+	stats, valid := validateArgs(argsCopy, s.maxArgsDepth, s.maxArgsKeys)
+	if !valid {
+		return ToolExample{}, fmt.Errorf("%w: example %d (%s) has depth=%d (max %d), keys=%d (max %d)",
+			ErrArgsTooLarge, i, ex.Title, stats.Depth, s.maxArgsDepth, stats.Keys, s.maxArgsKeys)
+	}
+
+	return ToolExample{
+		ID:          ex.ID,
+		Title:       ex.Title,
+		Description: truncateString(ex.Description, MaxDescriptionLen),
+		Args:        argsCopy,
+		ResultHint:  truncateString(ex.ResultHint, MaxResultHintLen),
+		Priority:    ex.Priority,
+	}, nil
+}
+
+// sortExamples orders examples by Priority descending, keeping the relative
+// order of equal priorities.
+func sortExamples(examples []ToolExample) {
+	slices.SortStableFunc(examples, func(a, b ToolExample) int {
+		return b.Priority - a.Priority
+	})
 }
 
 // DescribeTool returns documentation for a tool at the specified detail level.
@@ -215,9 +448,12 @@ func (s *InMemoryStore) DescribeTool(id string, level DetailLevel) (ToolDoc, err
 	var summary, notes string
 	var examples []ToolExample
 	var externalRefs []string
+	var seeAlso []string
+	var owner *Owner
 	var hasDoc bool
 
 	s.mu.RLock()
+	owner = s.ownerLocked(id)
 	if docRec := s.docs[id]; docRec != nil {
 		hasDoc = true
 		summary = docRec.summary
@@ -227,6 +463,7 @@ func (s *InMemoryStore) DescribeTool(id string, level DetailLevel) (ToolDoc, err
 		// Copy external refs
 		externalRefs = make([]string, len(docRec.externalRefs))
 		copy(externalRefs, docRec.externalRefs)
+		seeAlso = slices.Clone(docRec.seeAlso)
 	}
 	maxExamples := s.maxExamples
 	s.mu.RUnlock()
@@ -299,8 +536,10 @@ func (s *InMemoryStore) DescribeTool(id string, level DetailLevel) (ToolDoc, err
 
 	// Build schema info from tool's InputSchema
 	var schemaInfo *SchemaInfo
+	var parameters []Parameter
 	if tool != nil {
 		schemaInfo = deriveSchemaInfo(tool.InputSchema)
+		parameters = ParameterTable(tool.InputSchema)
 	}
 
 	// Build result based on level
@@ -312,11 +551,14 @@ func (s *InMemoryStore) DescribeTool(id string, level DetailLevel) (ToolDoc, err
 		SecuritySummary: securitySummary,
 		Annotations:     annotations,
 		SchemaInfo:      schemaInfo,
+		Parameters:      parameters,
 	}
 
 	if level == DetailFull {
 		result.Notes = notes
 		result.ExternalRefs = externalRefs
+		result.Owner = owner
+		result.SeeAlso = s.relatedTools(seeAlso)
 		// Apply MaxExamples cap
 		if maxExamples > 0 && len(examples) > maxExamples {
 			examples = examples[:maxExamples]
@@ -327,6 +569,64 @@ func (s *InMemoryStore) DescribeTool(id string, level DetailLevel) (ToolDoc, err
 	return result, nil
 }
 
+// relatedTools resolves SeeAlso IDs to summaries, skipping tools that can no
+// longer be described.
+func (s *InMemoryStore) relatedTools(ids []string) []RelatedTool {
+	if len(ids) == 0 {
+		return nil
+	}
+	related := make([]RelatedTool, 0, len(ids))
+	for _, id := range ids {
+		doc, err := s.DescribeTool(id, DetailSummary)
+		if err != nil {
+			continue
+		}
+		related = append(related, RelatedTool{ID: id, Summary: doc.Summary})
+	}
+	if len(related) == 0 {
+		return nil
+	}
+	return related
+}
+
+// GetDoc returns a copy of the documentation registered for id, as stored
+// after validation and truncation. Unlike DescribeTool it does not consult
+// the index or fall back to the tool's description.
+//
+// Returns ErrNotFound if no documentation is registered for id.
+func (s *InMemoryStore) GetDoc(id string) (DocEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.docs[id]
+	if !ok {
+		return DocEntry{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	entry := DocEntry{
+		Summary:      record.summary,
+		Notes:        record.notes,
+		Examples:     copyExamples(record.examples),
+		ExternalRefs: slices.Clone(record.externalRefs),
+		SeeAlso:      slices.Clone(record.seeAlso),
+	}
+	if record.owner != nil {
+		owner := *record.owner
+		entry.Owner = &owner
+	}
+	return entry, nil
+}
+
+// DocIDs returns the IDs of tools with registered documentation, sorted.
+func (s *InMemoryStore) DocIDs() []string {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.docs))
+	for id := range s.docs {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	slices.Sort(ids)
+	return ids
+}
+
 // ListExamples returns up to maxExamples for a tool.
 // The effective limit is min(maxExamples, MaxExamples) when both are set.
 func (s *InMemoryStore) ListExamples(id string, maxExamples int) ([]ToolExample, error) {
@@ -533,6 +833,7 @@ func copyExamples(examples []ToolExample) []ToolExample {
 			Description: ex.Description,
 			Args:        deepCopyArgs(ex.Args),
 			ResultHint:  ex.ResultHint,
+			Priority:    ex.Priority,
 		}
 	}
 	return result
@@ -569,90 +870,101 @@ func normalizeNumeric(v any) any {
 	}
 }
 
-// deriveSchemaInfo extracts schema information from an InputSchema.
-// Returns nil if derivation is not possible.
-// Numeric default values are normalized to float64.
-func deriveSchemaInfo(schema any) *SchemaInfo {
+// schemaAsMap converts an InputSchema to a generic map, decoding JSON bytes
+// and round-tripping typed schemas through JSON.
+func schemaAsMap(schema any) (map[string]any, bool) {
 	if schema == nil {
-		return nil
+		return nil, false
 	}
 
-	// Try to get schema as map
 	var schemaMap map[string]any
-
 	switch s := schema.(type) {
 	case map[string]any:
-		schemaMap = s
+		return s, true
 	case json.RawMessage:
 		if err := json.Unmarshal(s, &schemaMap); err != nil {
-			return nil
+			return nil, false
 		}
 	case []byte:
 		if err := json.Unmarshal(s, &schemaMap); err != nil {
-			return nil
+			return nil, false
 		}
 	default:
 		// Try JSON round-trip
 		data, err := json.Marshal(schema)
 		if err != nil {
-			return nil
+			return nil, false
 		}
 		if err := json.Unmarshal(data, &schemaMap); err != nil {
-			return nil
-		}
-	}
-
-	info := &SchemaInfo{}
-	hasData := false
-
-	// Extract required fields (handle both []any and []string)
-	if req, ok := schemaMap["required"]; ok {
-		info.Required = toStringSlice(req)
-		if len(info.Required) > 0 {
-			hasData = true
-		}
-	}
-
-	// Extract properties for types and defaults
-	if props, ok := schemaMap["properties"]; ok {
-		if propsMap, ok := props.(map[string]any); ok {
-			info.Types = make(map[string][]string)
-			info.Defaults = make(map[string]any)
-
-			for name, prop := range propsMap {
-				if propMap, ok := prop.(map[string]any); ok {
-					// Extract type (handle string, []any, and []string)
-					if t, ok := propMap["type"]; ok {
-						if tv, ok := t.(string); ok {
-							info.Types[name] = []string{tv}
-							hasData = true
-						} else if types := toStringSlice(t); len(types) > 0 {
-							info.Types[name] = types
-							hasData = true
-						}
-					}
-
-					// Extract default (normalize numeric values to float64)
-					if def, ok := propMap["default"]; ok {
-						info.Defaults[name] = normalizeNumeric(def)
-						hasData = true
-					}
-				}
-			}
-
-			// Clean up empty maps
-			if len(info.Types) == 0 {
-				info.Types = nil
-			}
-			if len(info.Defaults) == 0 {
-				info.Defaults = nil
-			}
+			return nil, false
 		}
 	}
+	return schemaMap, schemaMap != nil
+}
 
-	if !hasData {
+// deriveSchemaInfo extracts schema information from an InputSchema, walking
+// nested objects and array items like ParameterTable.
+// Returns nil if derivation is not possible.
+// Numeric default and enum values are normalized to float64.
+func deriveSchemaInfo(schema any) *SchemaInfo {
+	if schema == nil {
 		return nil
 	}
 
+	schemaMap, ok := schemaAsMap(schema)
+	if !ok {
+		return nil
+	}
+
+	info := &SchemaInfo{
+		// Top-level required names keep the schema's order.
+		Required:     toStringSlice(schemaMap["required"]),
+		Types:        make(map[string][]string),
+		Defaults:     make(map[string]any),
+		Enums:        make(map[string][]any),
+		Descriptions: make(map[string]string),
+	}
+	walkProperties(schemaMap, func(path string, depth int, prop map[string]any, required bool) {
+		if required && depth > 0 {
+			info.Required = append(info.Required, path)
+		}
+		// Extract type (handle string, []any, and []string)
+		if tv, ok := prop["type"].(string); ok {
+			info.Types[path] = []string{tv}
+		} else if types := toStringSlice(prop["type"]); len(types) > 0 {
+			info.Types[path] = types
+		}
+		// Extract default (normalize numeric values to float64)
+		if def, ok := prop["default"]; ok {
+			info.Defaults[path] = normalizeNumeric(def)
+		}
+		if enum := schemaEnum(prop); enum != nil {
+			info.Enums[path] = enum
+		}
+		if desc, _ := prop["description"].(string); desc != "" {
+			info.Descriptions[path] = desc
+		}
+	})
+
+	// Clean up empty fields
+	if len(info.Required) == 0 {
+		info.Required = nil
+	}
+	if len(info.Types) == 0 {
+		info.Types = nil
+	}
+	if len(info.Defaults) == 0 {
+		info.Defaults = nil
+	}
+	if len(info.Enums) == 0 {
+		info.Enums = nil
+	}
+	if len(info.Descriptions) == 0 {
+		info.Descriptions = nil
+	}
+	if info.Required == nil && info.Types == nil && info.Defaults == nil &&
+		info.Enums == nil && info.Descriptions == nil {
+		return nil
+	}
 	return info
 }
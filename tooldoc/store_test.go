@@ -3,7 +3,9 @@ package tooldoc
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/jonwraymond/tooldiscovery/index"
@@ -1637,3 +1639,480 @@ func TestDeriveSchemaInfo_InvalidByteSlice(t *testing.T) {
 		t.Errorf("expected nil for invalid bytes, got %v", result)
 	}
 }
+
+func TestWhoOwns(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	if err := store.RegisterNamespaceOwner("billing", Owner{Team: "payments", Contact: "#payments-oncall"}); err != nil {
+		t.Fatalf("RegisterNamespaceOwner failed: %v", err)
+	}
+	mustRegisterDoc(t, store, "billing:refund", DocEntry{
+		Summary: "Refund",
+		Owner:   &Owner{Team: "refunds", EscalationURL: "https://runbooks/refunds"},
+	})
+
+	owner, err := store.WhoOwns("billing:refund")
+	if err != nil || owner.Team != "refunds" {
+		t.Fatalf("expected tool-level owner, got %+v (%v)", owner, err)
+	}
+	owner, err = store.WhoOwns("billing:charge")
+	if err != nil || owner.Team != "payments" {
+		t.Fatalf("expected namespace owner fallback, got %+v (%v)", owner, err)
+	}
+	if _, err := store.WhoOwns("other:tool"); !errors.Is(err, ErrNoOwner) {
+		t.Fatalf("expected ErrNoOwner, got %v", err)
+	}
+	if err := store.RegisterNamespaceOwner("", Owner{Team: "x"}); !errors.Is(err, ErrInvalidNamespace) {
+		t.Fatalf("expected ErrInvalidNamespace, got %v", err)
+	}
+
+	_ = store.RegisterNamespaceOwner("billing", Owner{})
+	if _, err := store.WhoOwns("billing:charge"); !errors.Is(err, ErrNoOwner) {
+		t.Fatalf("expected zero owner to clear namespace owner, got %v", err)
+	}
+}
+
+func TestDescribeTool_FullIncludesOwner(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	tool := makeToolWithSchema("refund", "billing", "Refund", map[string]any{"type": "object"})
+	_ = idx.RegisterTool(tool, model.NewLocalBackend("h"))
+
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+	_ = store.RegisterNamespaceOwner("billing", Owner{Team: "payments"})
+
+	full, err := store.DescribeTool("billing:refund", DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	if full.Owner == nil || full.Owner.Team != "payments" {
+		t.Fatalf("expected owner at full level, got %+v", full.Owner)
+	}
+
+	schema, _ := store.DescribeTool("billing:refund", DetailSchema)
+	if schema.Owner != nil {
+		t.Errorf("owner should be omitted below full level, got %+v", schema.Owner)
+	}
+}
+
+func TestRemoveDoc(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "A tool", Examples: []ToolExample{{ID: "ex1", Title: "One"}}})
+
+	if err := store.RemoveDoc("ns:tool"); err != nil {
+		t.Fatalf("RemoveDoc failed: %v", err)
+	}
+	if _, err := store.DescribeTool("ns:tool", DetailSummary); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("DescribeTool after RemoveDoc err = %v, want ErrNotFound", err)
+	}
+	if err := store.RemoveDoc("ns:tool"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second RemoveDoc err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRemoveExamples(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterExamples(t, store, "ns:tool", []ToolExample{
+		{ID: "ex1", Title: "One"},
+		{ID: "ex2", Title: "Two"},
+		{Title: "Unnamed"},
+	})
+
+	if err := store.RemoveExamples("ns:tool", []string{"ex1", "missing", ""}); err != nil {
+		t.Fatalf("RemoveExamples failed: %v", err)
+	}
+	examples, err := store.ListExamples("ns:tool", 10)
+	if err != nil {
+		t.Fatalf("ListExamples failed: %v", err)
+	}
+	if len(examples) != 2 || examples[0].ID != "ex2" || examples[1].Title != "Unnamed" {
+		t.Fatalf("examples = %+v, want ex2 and the unnamed example", examples)
+	}
+	if err := store.RemoveExamples("ns:other", []string{"ex1"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("RemoveExamples for unknown tool err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateExample(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterExamples(t, store, "ns:tool", []ToolExample{
+		{ID: "ex1", Title: "One", Args: map[string]any{"n": 1}},
+		{ID: "ex2", Title: "Two"},
+	})
+
+	err := store.UpdateExample("ns:tool", "ex1", ToolExample{ID: "renamed", Title: "One v2", Args: map[string]any{"n": []int{1, 2}}})
+	if err != nil {
+		t.Fatalf("UpdateExample failed: %v", err)
+	}
+	examples, _ := store.ListExamples("ns:tool", 10)
+	if len(examples) != 2 || examples[0].ID != "ex1" || examples[0].Title != "One v2" {
+		t.Fatalf("examples = %+v, want ex1 updated in place", examples)
+	}
+	if _, ok := examples[0].Args["n"].([]any); !ok {
+		t.Fatalf("Args not normalized: %T", examples[0].Args["n"])
+	}
+
+	if err := store.UpdateExample("ns:tool", "missing", ToolExample{}); !errors.Is(err, ErrExampleNotFound) {
+		t.Fatalf("missing example err = %v, want ErrExampleNotFound", err)
+	}
+	if err := store.UpdateExample("ns:other", "ex1", ToolExample{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing tool err = %v, want ErrNotFound", err)
+	}
+	manyKeys := make(map[string]any, MaxArgsKeys+1)
+	for i := 0; i <= MaxArgsKeys; i++ {
+		manyKeys[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+	}
+	if err := store.UpdateExample("ns:tool", "ex2", ToolExample{Args: manyKeys}); !errors.Is(err, ErrArgsTooLarge) {
+		t.Fatalf("oversized args err = %v, want ErrArgsTooLarge", err)
+	}
+}
+
+func TestDeleteExample(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterExamples(t, store, "ns:tool", []ToolExample{{ID: "ex1"}, {ID: "ex2"}})
+
+	if err := store.DeleteExample("ns:tool", "ex1"); err != nil {
+		t.Fatalf("DeleteExample failed: %v", err)
+	}
+	examples, _ := store.ListExamples("ns:tool", 10)
+	if len(examples) != 1 || examples[0].ID != "ex2" {
+		t.Fatalf("examples = %+v, want only ex2", examples)
+	}
+	if err := store.DeleteExample("ns:tool", "ex1"); !errors.Is(err, ErrExampleNotFound) {
+		t.Fatalf("second DeleteExample err = %v, want ErrExampleNotFound", err)
+	}
+	if err := store.DeleteExample("ns:tool", ""); !errors.Is(err, ErrExampleNotFound) {
+		t.Fatalf("empty ID err = %v, want ErrExampleNotFound", err)
+	}
+}
+
+func TestExamplePriorityOrdering(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{MaxExamples: 2})
+	mustRegisterExamples(t, store, "ns:tool", []ToolExample{
+		{ID: "low", Priority: -1},
+		{ID: "first"},
+		{ID: "top", Priority: 5},
+		{ID: "second"},
+	})
+	examples, _ := store.ListExamples("ns:tool", 10)
+	if len(examples) != 2 || examples[0].ID != "top" || examples[1].ID != "first" {
+		t.Fatalf("examples = %+v, want top then first", examples)
+	}
+
+	if err := store.UpdateExample("ns:tool", "first", ToolExample{Priority: 10}); err != nil {
+		t.Fatalf("UpdateExample failed: %v", err)
+	}
+	examples, _ = store.ListExamples("ns:tool", 10)
+	if examples[0].ID != "first" || examples[0].Priority != 10 {
+		t.Fatalf("examples = %+v, want first promoted", examples)
+	}
+
+	mustRegisterDoc(t, store, "ns:doc", DocEntry{Examples: []ToolExample{{ID: "a"}, {ID: "b", Priority: 1}}})
+	examples, _ = store.ListExamples("ns:doc", 10)
+	if examples[0].ID != "b" {
+		t.Fatalf("RegisterDoc examples = %+v, want b first", examples)
+	}
+}
+
+func newPatchTestStore(maxExamples int) *InMemoryStore {
+	idx := index.NewInMemoryIndex()
+	_ = idx.RegisterTool(makeToolWithSchema("tool", "ns", "A tool", map[string]any{"type": "object"}), model.NewLocalBackend("h"))
+	return NewInMemoryStore(StoreOptions{Index: idx, MaxExamples: maxExamples})
+}
+
+func TestPatchDoc(t *testing.T) {
+	store := newPatchTestStore(2)
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{
+		Summary:      "Original",
+		Notes:        "Keep me",
+		Examples:     []ToolExample{{ID: "ex1"}},
+		ExternalRefs: []string{"https://a"},
+	})
+
+	summary := "Patched"
+	err := store.PatchDoc("ns:tool", DocPatch{
+		Summary:         &summary,
+		AppendExamples:  []ToolExample{{ID: "ex2", Priority: 1}, {ID: "ex3", Priority: -1}},
+		AddExternalRefs: []string{"https://a", "https://b"},
+	})
+	if err != nil {
+		t.Fatalf("PatchDoc failed: %v", err)
+	}
+
+	doc, err := store.DescribeTool("ns:tool", DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	if doc.Summary != "Patched" || doc.Notes != "Keep me" {
+		t.Fatalf("summary/notes = %q/%q, want Patched/Keep me", doc.Summary, doc.Notes)
+	}
+	if len(doc.ExternalRefs) != 2 || doc.ExternalRefs[1] != "https://b" {
+		t.Fatalf("externalRefs = %v, want a and b", doc.ExternalRefs)
+	}
+	examples, _ := store.ListExamples("ns:tool", 10)
+	if len(examples) != 2 || examples[0].ID != "ex2" || examples[1].ID != "ex1" {
+		t.Fatalf("examples = %+v, want ex2 then ex1", examples)
+	}
+
+	long := strings.Repeat("x", MaxSummaryLen+10)
+	if err := store.PatchDoc("ns:tool", DocPatch{Summary: &long}); err != nil {
+		t.Fatalf("PatchDoc failed: %v", err)
+	}
+	if doc, _ := store.DescribeTool("ns:tool", DetailSummary); len(doc.Summary) != MaxSummaryLen {
+		t.Fatalf("patched summary length = %d, want %d", len(doc.Summary), MaxSummaryLen)
+	}
+	_ = store.PatchDoc("ns:tool", DocPatch{Summary: &summary})
+
+	empty := ""
+	if err := store.PatchDoc("ns:new", DocPatch{Notes: &empty, Owner: &Owner{Team: "infra"}}); err != nil {
+		t.Fatalf("PatchDoc on new tool failed: %v", err)
+	}
+	if _, err := store.ListExamples("ns:new", 10); err != nil {
+		t.Fatalf("PatchDoc did not create documentation: %v", err)
+	}
+
+	manyKeys := make(map[string]any, MaxArgsKeys+1)
+	for i := 0; i <= MaxArgsKeys; i++ {
+		manyKeys[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+	}
+	err = store.PatchDoc("ns:tool", DocPatch{Summary: &empty, AppendExamples: []ToolExample{{Args: manyKeys}}})
+	if !errors.Is(err, ErrArgsTooLarge) {
+		t.Fatalf("oversized args err = %v, want ErrArgsTooLarge", err)
+	}
+	if doc, _ := store.DescribeTool("ns:tool", DetailSummary); doc.Summary != "Patched" {
+		t.Fatalf("failed patch changed summary to %q", doc.Summary)
+	}
+}
+
+func TestPatchDoc_ConcurrentFields(t *testing.T) {
+	store := newPatchTestStore(0)
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "Tool"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = store.PatchDoc("ns:tool", DocPatch{AppendExamples: []ToolExample{{Title: "ex"}}})
+		}()
+		go func() {
+			defer wg.Done()
+			notes := "notes"
+			_ = store.PatchDoc("ns:tool", DocPatch{Notes: &notes})
+		}()
+	}
+	wg.Wait()
+
+	doc, _ := store.DescribeTool("ns:tool", DetailFull)
+	if doc.Summary != "Tool" || doc.Notes != "notes" {
+		t.Fatalf("summary/notes = %q/%q, want Tool/notes", doc.Summary, doc.Notes)
+	}
+	if examples, _ := store.ListExamples("ns:tool", 100); len(examples) != 20 {
+		t.Fatalf("examples = %d, want 20", len(examples))
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	var events []ChangeEvent
+	unsubscribe := store.OnChange(func(e ChangeEvent) { events = append(events, e) })
+
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "Tool", ExternalRefs: []string{"https://a"}})
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "Tool", ExternalRefs: []string{"https://a"}})
+	notes := "Notes"
+	_ = store.PatchDoc("ns:tool", DocPatch{Notes: &notes, AppendExamples: []ToolExample{{ID: "ex1"}}})
+	_ = store.DeleteExample("ns:tool", "missing")
+	_ = store.DeleteExample("ns:tool", "ex1")
+	_ = store.RemoveDoc("ns:tool")
+
+	want := []ChangeEvent{
+		{Type: ChangeRegistered, ToolID: "ns:tool", Fields: []DocField{FieldSummary, FieldExternalRefs}},
+		{Type: ChangeUpdated, ToolID: "ns:tool", Fields: []DocField{FieldNotes, FieldExamples}},
+		{Type: ChangeUpdated, ToolID: "ns:tool", Fields: []DocField{FieldExamples}},
+		{Type: ChangeRemoved, ToolID: "ns:tool"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i].Type != want[i].Type || events[i].ToolID != want[i].ToolID || !slices.Equal(events[i].Fields, want[i].Fields) {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+
+	unsubscribe()
+	unsubscribe()
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "Again"})
+	if len(events) != len(want) {
+		t.Fatalf("listener called after unsubscribe: %+v", events[len(want):])
+	}
+	if noop := store.OnChange(nil); noop == nil {
+		t.Fatal("OnChange(nil) returned nil unsubscribe")
+	}
+}
+
+func TestOnChange_ListenerCanReadStore(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	var summary string
+	store.OnChange(func(e ChangeEvent) {
+		doc, _ := store.DescribeTool(e.ToolID, DetailSummary)
+		summary = doc.Summary
+	})
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{Summary: "Tool"})
+	if summary != "Tool" {
+		t.Fatalf("summary seen by listener = %q, want Tool", summary)
+	}
+}
+
+func nestedArgs(depth int) map[string]any {
+	args := map[string]any{"leaf": true}
+	for i := 1; i < depth; i++ {
+		args = map[string]any{"next": args}
+	}
+	return args
+}
+
+func TestStoreOptions_ArgsCaps(t *testing.T) {
+	deep := []ToolExample{{ID: "apply", Args: nestedArgs(8)}}
+
+	if err := NewInMemoryStore(StoreOptions{}).RegisterExamples("iac:apply", deep); !errors.Is(err, ErrArgsTooLarge) {
+		t.Fatalf("default caps err = %v, want ErrArgsTooLarge", err)
+	}
+
+	store := NewInMemoryStore(StoreOptions{MaxArgsDepth: 10})
+	if err := store.RegisterExamples("iac:apply", deep); err != nil {
+		t.Fatalf("raised depth cap rejected example: %v", err)
+	}
+	if err := store.RegisterDoc("iac:plan", DocEntry{Examples: deep}); err != nil {
+		t.Fatalf("RegisterDoc with raised depth cap failed: %v", err)
+	}
+	manyKeys := make(map[string]any, MaxArgsKeys+1)
+	for i := 0; i <= MaxArgsKeys; i++ {
+		manyKeys[string(rune('a'+i%26))+string(rune('0'+i/26))] = i
+	}
+	if err := store.RegisterExamples("iac:apply", []ToolExample{{Args: manyKeys}}); !errors.Is(err, ErrArgsTooLarge) {
+		t.Fatalf("default key cap err = %v, want ErrArgsTooLarge", err)
+	}
+
+	clamped := NewInMemoryStore(StoreOptions{MaxArgsDepth: 1000})
+	err := clamped.RegisterExamples("iac:apply", []ToolExample{{Args: nestedArgs(ArgsDepthLimit + 1)}})
+	if !errors.Is(err, ErrArgsTooLarge) {
+		t.Fatalf("depth above ArgsDepthLimit err = %v, want ErrArgsTooLarge", err)
+	}
+	if err := clamped.RegisterExamples("iac:apply", []ToolExample{{Args: nestedArgs(ArgsDepthLimit)}}); err != nil {
+		t.Fatalf("depth at ArgsDepthLimit rejected: %v", err)
+	}
+}
+
+func TestSeeAlso(t *testing.T) {
+	idx := index.NewInMemoryIndex()
+	backend := model.NewLocalBackend("h")
+	_ = idx.RegisterTool(makeToolWithSchema("create_issue", "github", "Create an issue", map[string]any{"type": "object"}), backend)
+	_ = idx.RegisterTool(makeToolWithSchema("close_issue", "github", "Close an issue", map[string]any{"type": "object"}), backend)
+	store := NewInMemoryStore(StoreOptions{Index: idx})
+	mustRegisterDoc(t, store, "github:list_issues", DocEntry{Summary: "List issues"})
+
+	err := store.RegisterDoc("github:create_issue", DocEntry{
+		SeeAlso: []string{"github:close_issue", "github:list_issues", "github:close_issue"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterDoc failed: %v", err)
+	}
+	doc, err := store.DescribeTool("github:create_issue", DetailFull)
+	if err != nil {
+		t.Fatalf("DescribeTool failed: %v", err)
+	}
+	want := []RelatedTool{
+		{ID: "github:close_issue", Summary: "Close an issue"},
+		{ID: "github:list_issues", Summary: "List issues"},
+	}
+	if !slices.Equal(doc.SeeAlso, want) {
+		t.Fatalf("SeeAlso = %+v, want %+v", doc.SeeAlso, want)
+	}
+	if schema, _ := store.DescribeTool("github:create_issue", DetailSchema); schema.SeeAlso != nil {
+		t.Errorf("SeeAlso should be omitted below full level, got %+v", schema.SeeAlso)
+	}
+
+	for _, refs := range [][]string{{"github:missing"}, {"github:create_issue"}, {""}} {
+		if err := store.RegisterDoc("github:create_issue", DocEntry{SeeAlso: refs}); !errors.Is(err, ErrInvalidReference) {
+			t.Errorf("RegisterDoc SeeAlso %q err = %v, want ErrInvalidReference", refs, err)
+		}
+	}
+	if err := store.PatchDoc("github:create_issue", DocPatch{AddSeeAlso: []string{"github:missing"}}); !errors.Is(err, ErrInvalidReference) {
+		t.Errorf("PatchDoc err = %v, want ErrInvalidReference", err)
+	}
+
+	_ = store.RemoveDoc("github:list_issues")
+	doc, _ = store.DescribeTool("github:create_issue", DetailFull)
+	if len(doc.SeeAlso) != 1 || doc.SeeAlso[0].ID != "github:close_issue" {
+		t.Fatalf("SeeAlso after removal = %+v, want only close_issue", doc.SeeAlso)
+	}
+}
+
+func TestGetDoc(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	if _, err := store.GetDoc("ns:missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	mustRegisterDoc(t, store, "ns:tool", DocEntry{
+		Summary:      "Summary",
+		Notes:        "Notes",
+		Examples:     []ToolExample{{ID: "ex", Title: "Example", Args: map[string]any{"q": "go"}}},
+		ExternalRefs: []string{"https://example.com"},
+		Owner:        &Owner{Team: "infra"},
+	})
+
+	entry, err := store.GetDoc("ns:tool")
+	if err != nil {
+		t.Fatalf("GetDoc failed: %v", err)
+	}
+	if entry.Summary != "Summary" || entry.Notes != "Notes" || len(entry.Examples) != 1 ||
+		len(entry.ExternalRefs) != 1 || entry.Owner == nil || entry.Owner.Team != "infra" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	entry.Examples[0].Args["q"] = "mutated"
+	entry.Owner.Team = "mutated"
+	again, _ := store.GetDoc("ns:tool")
+	if again.Examples[0].Args["q"] != "go" || again.Owner.Team != "infra" {
+		t.Error("GetDoc returned shared state")
+	}
+}
+
+func TestDocIDs(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{})
+	mustRegisterDoc(t, store, "ns:b", DocEntry{Summary: "B"})
+	mustRegisterDoc(t, store, "ns:a", DocEntry{Summary: "A"})
+
+	if ids := store.DocIDs(); !slices.Equal(ids, []string{"ns:a", "ns:b"}) {
+		t.Errorf("DocIDs() = %v, want [ns:a ns:b]", ids)
+	}
+}
+
+func TestMaxDocBytes(t *testing.T) {
+	store := NewInMemoryStore(StoreOptions{MaxDocBytes: 20})
+	mustRegisterDoc(t, store, "ns:a", DocEntry{Summary: "short"})
+
+	long := strings.Repeat("x", 30)
+	err := store.PatchDoc("ns:a", DocPatch{Notes: &long})
+	var limitErr *index.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != index.LimitDocBytes || limitErr.ToolID != "ns:a" {
+		t.Fatalf("PatchDoc() error = %v, want doc_bytes LimitError", err)
+	}
+	doc, err := store.GetDoc("ns:a")
+	if err != nil {
+		t.Fatalf("GetDoc failed: %v", err)
+	}
+	if doc.Notes != "" || doc.Summary != "short" {
+		t.Errorf("doc = %+v, want unchanged after rejected patch", doc)
+	}
+
+	if err := store.RegisterDoc("ns:b", DocEntry{Summary: long}); !errors.Is(err, index.ErrLimitExceeded) {
+		t.Fatalf("RegisterDoc() error = %v, want ErrLimitExceeded", err)
+	}
+	if ids := store.DocIDs(); !slices.Equal(ids, []string{"ns:a"}) {
+		t.Errorf("DocIDs() = %v, want [ns:a]", ids)
+	}
+	if got := store.LimitRejections()[index.LimitDocBytes]; got != 2 {
+		t.Errorf("LimitRejections()[doc_bytes] = %d, want 2", got)
+	}
+}
@@ -17,7 +17,7 @@ const (
 	DetailSchema DetailLevel = "schema"
 
 	// DetailFull returns everything: Tool, SchemaInfo, Notes with usage guidance,
-	// examples (capped by MaxExamples), and ExternalRefs.
+	// examples (capped by MaxExamples), ExternalRefs, and related tools (SeeAlso).
 	// Requires tool to be resolved via index or ToolResolver
 	// (returns ErrNoTool otherwise).
 	DetailFull DetailLevel = "full"
@@ -32,9 +32,13 @@ const (
 )
 
 // Args caps to prevent context pollution when examples are included in LLM context.
+// Stores may raise them via StoreOptions up to ArgsDepthLimit and ArgsKeysLimit.
 const (
 	MaxArgsDepth = 5  // Maximum nesting depth for Args maps/slices
 	MaxArgsKeys  = 50 // Maximum total size: map keys + slice items across all levels
+
+	ArgsDepthLimit = 16  // Upper bound for StoreOptions.MaxArgsDepth
+	ArgsKeysLimit  = 500 // Upper bound for StoreOptions.MaxArgsKeys
 )
 
 // ToolExample represents a usage example for a tool.
@@ -55,31 +59,49 @@ type ToolExample struct {
 	// Args are deep-copied and normalized to MCP-native shapes on
 	// registration and retrieval: typed slices become []any, typed
 	// maps become map[string]any. This ensures consistent type
-	// assertions in downstream code.
+	// assertions in downstream code. Identical Args share one stored copy.
 	//
 	// Args are validated at registration: maximum depth is MaxArgsDepth (5),
-	// maximum total size (map keys + slice items) is MaxArgsKeys (50).
-	// Examples with Args exceeding these caps are rejected by
-	// RegisterDoc/RegisterExamples.
+	// maximum total size (map keys + slice items) is MaxArgsKeys (50),
+	// unless overridden by StoreOptions. Examples with Args exceeding these
+	// caps are rejected by RegisterDoc/RegisterExamples.
 	Args map[string]any `json:"args"`
 
 	// ResultHint describes the expected shape/semantics of the result.
 	// Maximum length: MaxResultHintLen (200 chars).
 	ResultHint string `json:"resultHint,omitempty"`
+
+	// Priority orders examples: higher values are returned first, and ties
+	// keep registration order. When MaxExamples truncates a registration,
+	// the highest-priority examples are kept.
+	Priority int `json:"priority,omitempty"`
 }
 
 // SchemaInfo contains derived information about a tool's input schema.
 // This is best-effort only; fields may be nil if derivation is not possible.
+//
+// Parameters are keyed by path: top-level names, with nested object
+// properties joined by "." and array item properties by "[]", as in
+// "config.retries" or "labels[].name" (see ParameterTable).
 type SchemaInfo struct {
-	// Required lists the names of required input parameters.
+	// Required lists the paths of required input parameters: top-level
+	// names in schema order, then nested paths required within their
+	// parent object.
 	Required []string `json:"required,omitempty"`
 
-	// Defaults maps parameter names to their default values.
+	// Defaults maps parameter paths to their default values.
 	Defaults map[string]any `json:"defaults,omitempty"`
 
-	// Types maps parameter names to their allowed types.
-	// For example: {"limit": ["integer"], "query": ["string"]}
+	// Types maps parameter paths to their allowed types.
+	// For example: {"limit": ["integer"], "config.retries": ["integer"]}
 	Types map[string][]string `json:"types,omitempty"`
+
+	// Enums maps parameter paths to their allowed values. Enums declared on
+	// array items are reported for the array.
+	Enums map[string][]any `json:"enums,omitempty"`
+
+	// Descriptions maps parameter paths to their schema descriptions.
+	Descriptions map[string]string `json:"descriptions,omitempty"`
 }
 
 // ToolDoc represents documentation for a tool at varying levels of detail.
@@ -108,6 +130,10 @@ type ToolDoc struct {
 	// Optional; populated at schema/full levels when derivable.
 	SchemaInfo *SchemaInfo `json:"schemaInfo,omitempty"`
 
+	// Parameters is a flattened parameter table derived from the input
+	// schema. Optional; populated at schema/full levels. See ParameterTable.
+	Parameters []Parameter `json:"parameters,omitempty"`
+
 	// Notes contains human-authored usage guidance, constraints,
 	// pagination/auth hints, and error semantics.
 	// Full level only. Maximum length: MaxNotesLen (2000 chars).
@@ -120,6 +146,20 @@ type ToolDoc struct {
 	// ExternalRefs contains URLs or resource IDs for additional documentation.
 	// Full level only.
 	ExternalRefs []string `json:"externalRefs,omitempty"`
+
+	// Owner identifies who is responsible for the tool, falling back to the
+	// namespace owner. Full level only.
+	Owner *Owner `json:"owner,omitempty"`
+
+	// SeeAlso lists related tools resolved to their summaries. References
+	// to tools that no longer exist are omitted. Full level only.
+	SeeAlso []RelatedTool `json:"seeAlso,omitempty"`
+}
+
+// RelatedTool is a resolved SeeAlso reference.
+type RelatedTool struct {
+	ID      string `json:"id"`
+	Summary string `json:"summary,omitempty"`
 }
 
 // DocEntry is the input structure for registering documentation for a tool.
@@ -137,6 +177,38 @@ type DocEntry struct {
 
 	// ExternalRefs contains URLs or resource IDs.
 	ExternalRefs []string
+
+	// SeeAlso lists IDs of related tools. Each must resolve via the index,
+	// ToolResolver, or registered documentation.
+	SeeAlso []string
+
+	// Owner overrides the namespace owner for this tool.
+	Owner *Owner
+}
+
+// DocPatch describes a partial update to a tool's documentation. Nil and
+// empty fields are left unchanged, so independent authors can edit different
+// fields without a read-modify-write cycle.
+type DocPatch struct {
+	// Summary replaces the summary when non-nil. Point at "" to clear it.
+	Summary *string
+
+	// Notes replaces the notes when non-nil. Point at "" to clear them.
+	Notes *string
+
+	// AppendExamples are added after the existing examples, then all
+	// examples are re-ordered by Priority.
+	AppendExamples []ToolExample
+
+	// AddExternalRefs are appended, skipping refs already present.
+	AddExternalRefs []string
+
+	// AddSeeAlso are appended, skipping IDs already present. They are
+	// validated like DocEntry.SeeAlso.
+	AddSeeAlso []string
+
+	// Owner replaces the tool owner when non-nil.
+	Owner *Owner
 }
 
 // truncateString truncates s to maxLen characters.
@@ -153,15 +225,20 @@ type ArgsStats struct {
 	Keys  int // Total size: map keys + slice items across all levels
 }
 
-// ValidateArgs checks if Args respects depth and size caps.
+// ValidateArgs checks if Args respects the default depth and size caps.
 // Returns stats and true if valid, or stats and false if caps exceeded.
 func ValidateArgs(args map[string]any) (ArgsStats, bool) {
+	return validateArgs(args, MaxArgsDepth, MaxArgsKeys)
+}
+
+// validateArgs checks Args against explicit depth and size caps.
+func validateArgs(args map[string]any, maxDepth, maxKeys int) (ArgsStats, bool) {
 	stats := ArgsStats{}
 	if args == nil {
 		return stats, true
 	}
 	stats.Keys, stats.Depth = countArgsMetrics(args, 1)
-	return stats, stats.Depth <= MaxArgsDepth && stats.Keys <= MaxArgsKeys
+	return stats, stats.Depth <= maxDepth && stats.Keys <= maxKeys
 }
 
 // countArgsMetrics recursively counts keys and tracks depth.
@@ -211,6 +288,11 @@ func (e DocEntry) ValidateAndTruncate() DocEntry {
 		Summary:      truncateString(e.Summary, MaxSummaryLen),
 		Notes:        truncateString(e.Notes, MaxNotesLen),
 		ExternalRefs: e.ExternalRefs,
+		SeeAlso:      e.SeeAlso,
+	}
+	if e.Owner != nil {
+		owner := *e.Owner
+		result.Owner = &owner
 	}
 
 	// Truncate examples
@@ -222,6 +304,7 @@ func (e DocEntry) ValidateAndTruncate() DocEntry {
 			Description: truncateString(ex.Description, MaxDescriptionLen),
 			Args:        ex.Args,
 			ResultHint:  truncateString(ex.ResultHint, MaxResultHintLen),
+			Priority:    ex.Priority,
 		}
 	}
 